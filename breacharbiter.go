@@ -0,0 +1,545 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+const (
+	// justiceConfTarget is the number of blocks within which we'd like a
+	// justice transaction to confirm. The remote party is able to sweep
+	// their delayed output once its relative time-lock expires, so the
+	// target is kept aggressive.
+	justiceConfTarget = 2
+
+	// justiceTxBaseSize is the estimated size, in virtual bytes, of a
+	// justice transaction without any inputs. This includes the version,
+	// lock time, input and output counts, and the single p2wkh output.
+	justiceTxBaseSize = 4 + 4 + 1 + 1 + 31
+
+	// justiceInputSize is the estimated size, in virtual bytes, of each
+	// input of a justice transaction. This includes the outpoint,
+	// sequence, and empty sigScript, along with the discounted witness.
+	// The witness is sized after the largest of those we generate, that
+	// of a revoked HTLC output: a signature, the revocation pre-image,
+	// and the witness script.
+	justiceInputSize = 32 + 4 + 4 + 1 + (1+1+73+1+32+1+140)/4
+)
+
+// breachArbiter is a special subsystem which is responsible for watching and
+// acting on the detection of any attempted uncooperative channel breaches by
+// channel counter-parties. This file essentially acts as deterrence code for
+// those attempting to launch attacks against the daemon. In practice it's
+// expected that the logic in this file never gets executed, but it is
+// important to have it in place just in case we encounter cheating channel
+// counter-parties.
+// TODO: closures in config for subsystem pointers to decouple?
+type breachArbiter struct {
+	wallet     *lnwallet.LightningWallet
+	bio        lnwallet.BlockChainIO
+	db         *channeldb.DB
+	notifier   chainntnfs.ChainNotifier
+	htlcSwitch *htlcSwitch
+
+	// feeEstimator is used to determine the fee paid by each justice
+	// transaction.
+	feeEstimator lnwallet.FeeEstimator
+
+	// breachObservers is a map which tracks all the active breach
+	// observers we're currently managing. The key of the map is the
+	// funding outpoint of the channel, and the value is a channel which
+	// will be closed once we detect that the channel has been
+	// cooperatively closed, thereby killing the goroutine and freeing up
+	// resources.
+	breachObservers map[wire.OutPoint]chan struct{}
+
+	// breachedContracts is a channel which is used internally within the
+	// struct to send the necessary information required to punish a
+	// counterparty once a channel breach is detected. Breach observers
+	// use this to communicate with the main contractObserver goroutine.
+	breachedContracts chan *retributionInfo
+
+	// newContracts is a channel which is used by outside subsystems to
+	// notify the breachArbiter of a new contract (a channel) that should
+	// be watched.
+	newContracts chan *lnwallet.LightningChannel
+
+	// settledContracts is a channel used by outside subsystems to notify
+	// the breachArbiter that a channel has peacefully been closed. Once a
+	// channel has been closed the arbiter no longer needs to watch for
+	// breach closes.
+	settledContracts chan *wire.OutPoint
+
+	started uint32
+	stopped uint32
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newBreachArbiter creates a new instance of a breachArbiter initialized with
+// its dependent objects.
+func newBreachArbiter(wallet *lnwallet.LightningWallet, bio lnwallet.BlockChainIO,
+	db *channeldb.DB, notifier chainntnfs.ChainNotifier, h *htlcSwitch,
+	feeEstimator lnwallet.FeeEstimator) *breachArbiter {
+
+	return &breachArbiter{
+		wallet:       wallet,
+		bio:          bio,
+		db:           db,
+		notifier:     notifier,
+		htlcSwitch:   h,
+		feeEstimator: feeEstimator,
+
+		breachObservers:   make(map[wire.OutPoint]chan struct{}),
+		breachedContracts: make(chan *retributionInfo),
+		newContracts:      make(chan *lnwallet.LightningChannel),
+		settledContracts:  make(chan *wire.OutPoint),
+		quit:              make(chan struct{}),
+	}
+}
+
+// Start is an idempotent method that officially starts the breachArbiter
+// along with all other goroutines it needs to perform its functions.
+func (b *breachArbiter) Start() error {
+	if !atomic.CompareAndSwapUint32(&b.started, 0, 1) {
+		return nil
+	}
+
+	brarLog.Tracef("Starting breach aribter")
+
+	// First we need to query that database state for all currently active
+	// channels, each of these channels will need a goroutine assigned to
+	// it to watch for channel breaches.
+	activeChannels, err := b.db.FetchAllChannels()
+	if err != nil {
+		brarLog.Errorf("unable to fetch active channels: %v", err)
+		return err
+	}
+
+	if len(activeChannels) > 0 {
+		brarLog.Infof("Retrieved %v channels from database, watching "+
+			"with vigilance!", len(activeChannels))
+	}
+
+	// For each of the channels read from disk, we'll create a channel
+	// state machine in order to watch for any potential channel closures.
+	// Once a peer holding a channel connects, the live version of the
+	// channel will replace the one created here.
+	channelsToWatch := make([]*lnwallet.LightningChannel, 0,
+		len(activeChannels))
+	for _, chanState := range activeChannels {
+		channel, err := lnwallet.NewLightningChannel(b.wallet.Signer,
+			b.bio, b.notifier, chanState)
+		if err != nil {
+			brarLog.Errorf("unable to load channel from disk: %v",
+				err)
+			for _, channel := range channelsToWatch {
+				channel.Stop()
+			}
+			return err
+		}
+
+		channelsToWatch = append(channelsToWatch, channel)
+	}
+
+	b.wg.Add(1)
+	go b.contractObserver(channelsToWatch)
+
+	return nil
+}
+
+// Stop is an idempotent method that signals the breachArbiter to execute a
+// graceful shutdown. This function will block until all goroutines spawned
+// by the breachArbiter have gracefully exited.
+func (b *breachArbiter) Stop() error {
+	if !atomic.CompareAndSwapUint32(&b.stopped, 0, 1) {
+		return nil
+	}
+
+	brarLog.Infof("Breach arbiter shutting down")
+
+	close(b.quit)
+	b.wg.Wait()
+
+	return nil
+}
+
+// contractObserver is the primary goroutine for the breachArbiter. This
+// goroutine is responsible for managing goroutines that watch for breaches
+// for all current active and newly created channels. If a channel breach is
+// detected by a spawned child goroutine, then the contractObserver will
+// execute the retribution logic required to sweep ALL outputs from a
+// contested channel into the daemon's wallet.
+//
+// NOTE: This MUST be run as a goroutine.
+func (b *breachArbiter) contractObserver(activeChannels []*lnwallet.LightningChannel) {
+	defer b.wg.Done()
+
+	// diskChannels tracks the channels loaded from disk by the arbiter
+	// itself, rather than handed to it by a peer. The arbiter owns these
+	// channels, so it's responsible for stopping them once they're no
+	// longer watched.
+	diskChannels := make(map[wire.OutPoint]*lnwallet.LightningChannel)
+	stopDiskChannel := func(chanPoint *wire.OutPoint) {
+		if channel, ok := diskChannels[*chanPoint]; ok {
+			channel.Stop()
+			delete(diskChannels, *chanPoint)
+		}
+	}
+	defer func() {
+		for _, channel := range diskChannels {
+			channel.Stop()
+		}
+	}()
+
+	// For each active channel found within the database, we launch a
+	// dedicated breachObserver goroutine for that channel and also track
+	// the new goroutine within the breachObservers map so we can cancel it
+	// later if necessary.
+	for _, channel := range activeChannels {
+		settleSignal := make(chan struct{})
+		chanPoint := channel.ChannelPoint()
+		b.breachObservers[*chanPoint] = settleSignal
+		diskChannels[*chanPoint] = channel
+
+		b.wg.Add(1)
+		go b.breachObserver(channel, settleSignal)
+	}
+
+out:
+	for {
+		select {
+		case breachInfo := <-b.breachedContracts:
+			// A new channel contract has just been breached! We
+			// first register for a notification to be dispatched
+			// once the breach transaction (the revoked commitment
+			// transaction) has been confirmed in the chain to
+			// ensure we're not dealing with a moving target.
+			breachTXID := &breachInfo.commitHash
+			confChan, err := b.notifier.RegisterConfirmationsNtfn(breachTXID, 1)
+			if err != nil {
+				brarLog.Errorf("unable to register for conf for "+
+					"txid: %v", breachTXID)
+				continue
+			}
+
+			brarLog.Warnf("A channel has been breached with tx: %v. "+
+				"Waiting for confirmation, then justice will be served!",
+				breachTXID)
+
+			// With the notification registered, we launch a new
+			// goroutine which will finalize the channel
+			// retribution after the breach transaction has been
+			// confirmed.
+			b.wg.Add(1)
+			go b.exactRetribution(confChan, breachInfo)
+
+			delete(b.breachObservers, *breachInfo.chanPoint)
+			stopDiskChannel(breachInfo.chanPoint)
+		case contract := <-b.newContracts:
+			// A new channel has just been opened within the
+			// daemon, so we launch a new breachObserver to handle
+			// the detection of attempted contract breaches.
+			settleSignal := make(chan struct{})
+			chanPoint := contract.ChannelPoint()
+
+			// If the contract is already being watched, then an
+			// additional send indicates we have a stale version
+			// of the contract. So we'll cancel active watcher
+			// goroutine to create a new instance with the latest
+			// contract reference.
+			if oldSignal, ok := b.breachObservers[*chanPoint]; ok {
+				brarLog.Infof("ChannelPoint(%v) is now live, "+
+					"abandoning stale contract for live "+
+					"version", chanPoint)
+				close(oldSignal)
+				stopDiskChannel(chanPoint)
+			}
+
+			b.breachObservers[*chanPoint] = settleSignal
+
+			brarLog.Debugf("New contract detected, launching " +
+				"breachObserver")
+
+			b.wg.Add(1)
+			go b.breachObserver(contract, settleSignal)
+
+			// TODO: add doneChan to signal that observer is
+			// active
+		case chanPoint := <-b.settledContracts:
+			// A new channel has been closed either unilaterally or
+			// cooperatively, as a result we no longer need a
+			// breachObserver dedicated to the channel.
+			killSignal, ok := b.breachObservers[*chanPoint]
+			if !ok {
+				brarLog.Errorf("Unable to find contract: %v",
+					chanPoint)
+				continue
+			}
+
+			brarLog.Debugf("ChannelPoint(%v) has been settled, "+
+				"cancelling breachObserver", chanPoint)
+
+			// If we had a breachObserver active, then we signal it
+			// for exit and also delete its state from our tracking
+			// map.
+			close(killSignal)
+			delete(b.breachObservers, *chanPoint)
+			stopDiskChannel(chanPoint)
+		case <-b.quit:
+			break out
+		}
+	}
+}
+
+// exactRetribution is a goroutine which is executed once a contract breach
+// has been detected by a breachObserver. This function is responsible for
+// punishing a counterparty for violating the channel contract by sweeping
+// ALL the lingering funds within the channel into the daemon's wallet.
+//
+// NOTE: This MUST be run as a goroutine.
+func (b *breachArbiter) exactRetribution(confChan *chainntnfs.ConfirmationEvent,
+	breachInfo *retributionInfo) {
+
+	defer b.wg.Done()
+
+	// TODO: state needs to be checkpointed here
+
+	select {
+	case _, ok := <-confChan.Confirmed:
+		// If the second value is !ok, then the channel has been closed
+		// signifying a daemon shutdown, so we exit.
+		if !ok {
+			return
+		}
+
+		// Otherwise, if this is a real confirmation notification, then
+		// we fall through to complete our duty.
+	case <-b.quit:
+		return
+	}
+
+	brarLog.Debugf("Breach transaction %v has been confirmed, sweeping "+
+		"revoked funds", breachInfo.commitHash)
+
+	// With the breach transaction confirmed, we now create the justice tx
+	// which will claim ALL the funds within the channel.
+	justiceTx, err := b.createJusticeTx(breachInfo)
+	if err != nil {
+		brarLog.Errorf("unable to create justice tx: %v", err)
+		return
+	}
+
+	brarLog.Debugf("Broadcasting justice tx: %v", newLogClosure(func() string {
+		return spew.Sdump(justiceTx)
+	}))
+
+	// Finally, broadcast the transaction, finalizing the channels'
+	// retribution against the cheating counterparty.
+	if err := b.wallet.PublishTransaction(justiceTx); err != nil {
+		brarLog.Errorf("unable to broadcast "+
+			"justice tx: %v", err)
+		return
+	}
+
+	// As a conclusionary step, we register for a notification to be
+	// dispatched once the justice tx is confirmed. After confirmation we
+	// notify the caller that initiated the retribution workflow that the
+	// deed has been done.
+	justiceTXID := justiceTx.TxSha()
+	confChan, err = b.notifier.RegisterConfirmationsNtfn(&justiceTXID, 1)
+	if err != nil {
+		brarLog.Errorf("unable to register for conf for txid: %v",
+			justiceTXID)
+		return
+	}
+
+	select {
+	case height, ok := <-confChan.Confirmed:
+		if !ok {
+			return
+		}
+
+		// The justice transaction sweeps both commitment outputs,
+		// along with every HTLC output of the revoked commitment.
+		revokedFunds := breachInfo.retribution.LocalAmount +
+			breachInfo.retribution.RemoteAmount
+		for _, htlc := range breachInfo.retribution.HtlcRetributions {
+			revokedFunds += htlc.Amount
+		}
+
+		brarLog.Infof("Justice for ChannelPoint(%v) has "+
+			"been served, %v revoked funds (%v total) "+
+			"have been claimed at height %v", breachInfo.chanPoint,
+			revokedFunds, breachInfo.capacity, height)
+
+		// With the channel closed, and the funds swept, we can now
+		// safely remove the channel's state from the database.
+		if err := breachInfo.deleteState(); err != nil {
+			brarLog.Errorf("unable to delete state of "+
+				"ChannelPoint(%v): %v", breachInfo.chanPoint,
+				err)
+		}
+
+		return
+	case <-b.quit:
+		return
+	}
+}
+
+// breachObserver notifies the breachArbiter contract observer goroutine that a
+// channel's contract has been breached by the prior counterparty. Once
+// notified the breachArbiter will attempt to sweep ALL funds within the
+// channel using the information provided within the BreachRetribution
+// generated due to the breach of channel contract. The funds will be swept
+// only after the breaching transaction receives a necessary number of
+// confirmations.
+//
+// NOTE: This MUST be run as a goroutine.
+func (b *breachArbiter) breachObserver(contract *lnwallet.LightningChannel,
+	settleSignal chan struct{}) {
+
+	defer b.wg.Done()
+
+	chanPoint := contract.ChannelPoint()
+
+	brarLog.Debugf("Breach observer for ChannelPoint(%v) started",
+		chanPoint)
+
+	select {
+	// A read from this channel indicates that the contract has been
+	// settled cooperatively so we exit as our duties are no longer needed.
+	case <-settleSignal:
+		return
+
+	// The channel has been closed by a normal means: force closing with
+	// the latest commitment transaction.
+	case <-contract.UnilateralCloseSignal:
+		// TODO: need to send message to nursery to sweep the
+		// outputs of the unilateral close
+		return
+
+	// A read from this channel indicates that a channel breach has been
+	// detected! So we notify the main coordination goroutine with the
+	// information needed to bring the counterparty to justice.
+	case breachInfo := <-contract.ContractBreach:
+		brarLog.Warnf("REVOKED STATE #%v FOR ChannelPoint(%v) "+
+			"broadcast, REMOTE PEER IS DOING SOMETHING "+
+			"SKETCHY!!!", breachInfo.RevokedStateNum,
+			chanPoint)
+
+		// Immediately notify the HTLC switch that this link has been
+		// breached in order to ensure any incoming or outgoing
+		// multi-hop HTLCs aren't sent over this link, nor any other
+		// links associated with this peer. If the channel's peer
+		// isn't currently connected, then there's no link to tear
+		// down.
+		_, errChan := b.htlcSwitch.CloseLink(chanPoint, CloseBreach)
+		select {
+		case err := <-errChan:
+			if err != nil {
+				brarLog.Debugf("unable to close link for "+
+					"ChannelPoint(%v): %v", chanPoint, err)
+			}
+		case <-b.quit:
+			return
+		}
+
+		// Finally, we send the retribution information into the
+		// breachArbiter event loop to deal swift justice.
+		snapshot := contract.StateSnapshot()
+		retInfo := &retributionInfo{
+			commitHash:  breachInfo.BreachTransaction.TxSha(),
+			chanPoint:   chanPoint,
+			capacity:    snapshot.Capacity,
+			retribution: breachInfo,
+			deleteState: contract.DeleteState,
+		}
+
+		select {
+		case b.breachedContracts <- retInfo:
+		case <-b.quit:
+		}
+
+	case <-b.quit:
+		return
+	}
+}
+
+// retributionInfo encapsulates all the data needed to sweep all the contested
+// funds within a channel whose contract has been breached by the prior
+// counterparty. This struct is used to create the justice transaction which
+// spends all outputs of the commitment transaction into an output controlled
+// by the wallet.
+type retributionInfo struct {
+	commitHash wire.ShaHash
+	chanPoint  *wire.OutPoint
+	capacity   btcutil.Amount
+
+	retribution *lnwallet.BreachRetribution
+
+	// deleteState removes the breached channel's state from the
+	// database once the justice transaction has been confirmed.
+	deleteState func() error
+}
+
+// createJusticeTx creates a transaction which exacts "justice" by sweeping ALL
+// the funds within the channel which we are now entitled to due to a breach of
+// the channel's contract by the counterparty. This function returns a *fully*
+// signed transaction with the witness for each input fully in place.
+func (b *breachArbiter) createJusticeTx(r *retributionInfo) (*wire.MsgTx, error) {
+	// First, we obtain a new public key script from the wallet which we'll
+	// sweep the funds to.
+	// TODO: possibly create many outputs to minimize change in the
+	// future?
+	sweepAddr, err := b.wallet.NewAddress(lnwallet.WitnessPubKey, false)
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(sweepAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Next, we fetch the private key corresponding to our commitment key
+	// within the channel. The revocation private key required to claim
+	// the remote party's outputs is derived from this key.
+	commitKey := r.retribution.LocalCommitKey
+	commitAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(commitKey.SerializeCompressed()),
+		activeNetParams.Params)
+	if err != nil {
+		return nil, err
+	}
+	commitPriv, err := b.wallet.GetPrivKey(commitAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Finally, compute the fee required for the justice transaction to
+	// confirm in a timely manner, using its estimated size.
+	feePerByte, err := b.feeEstimator.EstimateFeePerByte(justiceConfTarget)
+	if err != nil {
+		return nil, err
+	}
+	numInputs := len(r.retribution.HtlcRetributions)
+	if r.retribution.LocalAmount != 0 {
+		numInputs++
+	}
+	if r.retribution.RemoteAmount != 0 {
+		numInputs++
+	}
+	txSize := justiceTxBaseSize + justiceInputSize*numInputs
+	txFee := feePerByte * btcutil.Amount(txSize)
+
+	return lnwallet.CreateJusticeTx(r.retribution, commitPriv, pkScript,
+		txFee)
+}
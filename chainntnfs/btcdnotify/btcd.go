@@ -18,14 +18,26 @@ const (
 	// notifierType uniquely identifies this concrete implementation of the
 	// ChainNotifier interface.
 	notifierType = "btcd"
+
+	// reorgSafetyLimit is the number of blocks a transaction must be
+	// buried under before we no longer consider it at risk of being
+	// re-org'd out of the main chain. Dispatched confirmation
+	// notifications are tracked until this depth is reached, so that
+	// clients can be notified if the transaction is later disconnected.
+	reorgSafetyLimit = 100
 )
 
 // chainUpdate encapsulates an update to the current main chain. This struct is
 // used as an element within an unbounded queue in order to avoid blocking the
-// main rpc dispatch rule.
+// main rpc dispatch rule. Block connections and disconnections share a single
+// queue so they're processed in the order they occurred.
 type chainUpdate struct {
 	blockHash   *wire.ShaHash
 	blockHeight int32
+
+	// disconnected indicates that the block was disconnected from the
+	// main chain, rather than connected to it.
+	disconnected bool
 }
 
 // txUpdate encapsulates a transaction related notification sent from btcd to
@@ -54,9 +66,14 @@ type BtcdNotifier struct {
 	confNotifications map[wire.ShaHash][]*confirmationsNotification
 	confHeap          *confirmationHeap
 
-	blockEpochClients []chan *chainntnfs.BlockEpoch
+	// confirmedNtfns houses all the confirmation notifications which have
+	// already been dispatched, but whose transaction isn't yet buried
+	// under reorgSafetyLimit blocks. If any of these transactions are
+	// re-org'd out of the main chain, then the client is notified via the
+	// negative confirmation channel.
+	confirmedNtfns []*confirmationsNotification
 
-	disconnectedBlockHashes chan *blockNtfn
+	blockEpochClients []chan *chainntnfs.BlockEpoch
 
 	chainUpdates      []*chainUpdate
 	chainUpdateSignal chan struct{}
@@ -84,8 +101,6 @@ func New(config *btcrpcclient.ConnConfig) (*BtcdNotifier, error) {
 		confNotifications:  make(map[wire.ShaHash][]*confirmationsNotification),
 		confHeap:           newConfirmationHeap(),
 
-		disconnectedBlockHashes: make(chan *blockNtfn, 20),
-
 		chainUpdateSignal: make(chan struct{}),
 		txUpdateSignal:    make(chan struct{}),
 
@@ -159,17 +174,18 @@ func (b *BtcdNotifier) Stop() error {
 			close(confClient.negativeConf)
 		}
 	}
+	for _, confEntry := range b.confHeap.items {
+		close(confEntry.finConf)
+		close(confEntry.negativeConf)
+	}
+	for _, confClient := range b.confirmedNtfns {
+		close(confClient.finConf)
+		close(confClient.negativeConf)
+	}
 
 	return nil
 }
 
-// blockNtfn packages a notification of a connected/disconnected block along
-// with its height at the time.
-type blockNtfn struct {
-	sha    *wire.ShaHash
-	height int32
-}
-
 // onBlockConnected implements on OnBlockConnected callback for btcrpcclient.
 // Ingesting a block updates the wallet's internal utxo state based on the
 // outputs created and destroyed within each block.
@@ -177,7 +193,7 @@ func (b *BtcdNotifier) onBlockConnected(hash *wire.ShaHash, height int32, t time
 	// Append this new chain update to the end of the queue of new chain
 	// updates.
 	b.chainUpdateMtx.Lock()
-	b.chainUpdates = append(b.chainUpdates, &chainUpdate{hash, height})
+	b.chainUpdates = append(b.chainUpdates, &chainUpdate{hash, height, false})
 	b.chainUpdateMtx.Unlock()
 
 	// Launch a goroutine to signal the notification dispatcher that a new
@@ -189,7 +205,16 @@ func (b *BtcdNotifier) onBlockConnected(hash *wire.ShaHash, height int32, t time
 }
 
 // onBlockDisconnected implements on OnBlockDisconnected callback for btcrpcclient.
+// The disconnected block is placed on the same queue as connected blocks, so
+// the dispatcher observes re-orgs in the order they occurred.
 func (b *BtcdNotifier) onBlockDisconnected(hash *wire.ShaHash, height int32, t time.Time) {
+	b.chainUpdateMtx.Lock()
+	b.chainUpdates = append(b.chainUpdates, &chainUpdate{hash, height, true})
+	b.chainUpdateMtx.Unlock()
+
+	go func() {
+		b.chainUpdateSignal <- struct{}{}
+	}()
 }
 
 // onRedeemingTx implements on OnRedeemingTx callback for btcrpcclient.
@@ -224,6 +249,15 @@ out:
 				chainntnfs.Log.Infof("New confirmations "+
 					"subscription: txid=%v, numconfs=%v",
 					*msg.txid, msg.numConfirmations)
+
+				// If the transaction has already been included
+				// within the chain, then we may be able to
+				// dispatch the notification immediately, or
+				// track it within the confirmation heap.
+				if b.attemptHistoricalDispatch(msg) {
+					continue
+				}
+
 				txid := *msg.txid
 				b.confNotifications[txid] = append(b.confNotifications[txid], msg)
 			case *blockEpochRegistration:
@@ -231,13 +265,6 @@ out:
 				b.blockEpochClients = append(b.blockEpochClients,
 					msg.epochChan)
 			}
-		case staleBlockHash := <-b.disconnectedBlockHashes:
-			// TODO(roasbeef): re-orgs
-			//  * second channel to notify of confirmation decrementing
-			//    re-org?
-			//  * notify of negative confirmations
-			chainntnfs.Log.Warnf("Block disconnected from main "+
-				"chain: %v", staleBlockHash)
 		case <-b.chainUpdateSignal:
 			// A new update is available, so pop the new chain
 			// update from the front of the update queue.
@@ -247,6 +274,18 @@ out:
 			b.chainUpdates = b.chainUpdates[1:]
 			b.chainUpdateMtx.Unlock()
 
+			// If the block was disconnected from the main chain,
+			// then any transactions it included have lost their
+			// confirmations.
+			if update.disconnected {
+				chainntnfs.Log.Warnf("Block disconnected from "+
+					"main chain: height=%v, sha=%v",
+					update.blockHeight, update.blockHash)
+
+				b.handleBlockDisconnected(update.blockHeight)
+				continue
+			}
+
 			newBlock, err := b.chainConn.GetBlock(update.blockHash)
 			if err != nil {
 				chainntnfs.Log.Errorf("Unable to get block: %v", err)
@@ -275,6 +314,11 @@ out:
 			// chain. Send out any N confirmation notifications
 			// which may have been triggered by this new block.
 			b.notifyConfs(newHeight)
+
+			// Finally, stop tracking any dispatched notifications
+			// which are now buried deep enough to be considered
+			// safe from re-orgs.
+			b.pruneConfirmedNtfns(newHeight)
 		case <-b.txUpdateSignal:
 			// A new update is available, so pop the new chain
 			// update from the front of the update queue.
@@ -320,6 +364,135 @@ out:
 	b.wg.Done()
 }
 
+// attemptHistoricalDispatch tries to use historical information to decide if
+// a notification can be dispatched immediately, or is partially confirmed and
+// can be placed directly on the confirmation heap. This allows confirmation
+// notifications to be registered for transactions which were included within
+// the chain while the caller was offline. True is returned if the
+// notification no longer needs to be tracked as unconfirmed.
+func (b *BtcdNotifier) attemptHistoricalDispatch(msg *confirmationsNotification) bool {
+	// Look up the transaction to determine if it's already been included
+	// within the chain. If the lookup fails, or the transaction is still
+	// within the mempool, then it'll be handled once it's mined.
+	tx, err := b.chainConn.GetRawTransactionVerbose(msg.txid)
+	if err != nil || tx == nil || tx.BlockHash == "" {
+		return false
+	}
+
+	blockHash, err := wire.NewShaHashFromStr(tx.BlockHash)
+	if err != nil {
+		chainntnfs.Log.Errorf("Unable to parse block hash: %v", err)
+		return false
+	}
+	block, err := b.chainConn.GetBlockVerbose(blockHash, false)
+	if err != nil {
+		chainntnfs.Log.Errorf("Unable to get block: %v", err)
+		return false
+	}
+
+	// If the transaction already has enough confirmations, then dispatch
+	// the notification immediately. Unless the transaction is already
+	// buried deeply enough, the notification is tracked in case it's
+	// later re-org'd out of the chain.
+	msg.initialConfirmHeight = uint32(block.Height)
+	if tx.Confirmations >= uint64(msg.numConfirmations) {
+		chainntnfs.Log.Infof("Dispatching historical conf "+
+			"notification, sha=%v, confs=%v", msg.txid,
+			tx.Confirmations)
+		msg.finConf <- int32(block.Height)
+
+		if tx.Confirmations < reorgSafetyLimit {
+			b.confirmedNtfns = append(b.confirmedNtfns, msg)
+		}
+		return true
+	}
+
+	// Otherwise, the transaction is only partially confirmed, so we place
+	// the notification on the confirmation heap to be triggered once the
+	// remaining confirmations are attained.
+	finalConfHeight := msg.initialConfirmHeight + msg.numConfirmations - 1
+	heap.Push(b.confHeap, &confEntry{
+		msg,
+		finalConfHeight,
+	})
+
+	return true
+}
+
+// handleBlockDisconnected re-evaluates all confirmation notifications in
+// response to the block at staleHeight being disconnected from the main chain.
+// Any notification whose transaction was included at, or above the stale
+// height has lost all of its confirmations, so the client is sent the depth of
+// the re-org on the negative confirmation channel. Once sent, the notification
+// is no longer tracked, so clients must re-register in order to be notified
+// once the transaction confirms within the new chain.
+//
+// TODO(roasbeef): dispatched spend notifications aren't revoked, as spends are
+// detected once they're seen within the mempool, and a spending transaction
+// re-org'd out of the chain is returned to the mempool.
+func (b *BtcdNotifier) handleBlockDisconnected(staleHeight int32) {
+	isStale := func(ntfn *confirmationsNotification) bool {
+		return ntfn.initialConfirmHeight >= uint32(staleHeight)
+	}
+
+	// First, filter out all partially confirmed notifications within the
+	// confirmation heap which have been invalidated.
+	var (
+		liveEntries  []*confEntry
+		staleClients []*confirmationsNotification
+	)
+	for _, entry := range b.confHeap.items {
+		if isStale(entry.confirmationsNotification) {
+			staleClients = append(staleClients,
+				entry.confirmationsNotification)
+			continue
+		}
+		liveEntries = append(liveEntries, entry)
+	}
+	b.confHeap.items = liveEntries
+	heap.Init(b.confHeap)
+
+	// Next, do the same for all notifications which have already been
+	// dispatched.
+	var liveNtfns []*confirmationsNotification
+	for _, confClient := range b.confirmedNtfns {
+		if isStale(confClient) {
+			staleClients = append(staleClients, confClient)
+			continue
+		}
+		liveNtfns = append(liveNtfns, confClient)
+	}
+	b.confirmedNtfns = liveNtfns
+
+	// Finally, notify each client of the depth of the re-org with
+	// respect to their transaction. As the channel is buffered, and
+	// written to at most once, this send will never block.
+	for _, confClient := range staleClients {
+		reorgDepth := uint32(staleHeight) - confClient.initialConfirmHeight + 1
+
+		chainntnfs.Log.Infof("Dispatching negative conf "+
+			"notification, sha=%v, depth=%v", confClient.txid,
+			reorgDepth)
+
+		confClient.negativeConf <- int32(reorgDepth)
+	}
+}
+
+// pruneConfirmedNtfns stops tracking all dispatched confirmation notifications
+// whose transaction is buried under at least reorgSafetyLimit blocks as of
+// the block at bestHeight.
+func (b *BtcdNotifier) pruneConfirmedNtfns(bestHeight int32) {
+	var liveNtfns []*confirmationsNotification
+	for _, confClient := range b.confirmedNtfns {
+		numConfs := uint32(bestHeight) - confClient.initialConfirmHeight + 1
+		if numConfs >= reorgSafetyLimit {
+			continue
+		}
+		liveNtfns = append(liveNtfns, confClient)
+	}
+	b.confirmedNtfns = liveNtfns
+}
+
 // notifyBlockEpochs notifies all registered block epoch clients of the newly
 // connected block to the main chain.
 func (b *BtcdNotifier) notifyBlockEpochs(newHeight int32, newSha *wire.ShaHash) {
@@ -357,6 +530,8 @@ func (b *BtcdNotifier) notifyConfs(newBlockHeight int32) {
 	nextConf := heap.Pop(b.confHeap).(*confEntry)
 	for nextConf.triggerHeight <= uint32(newBlockHeight) {
 		nextConf.finConf <- newBlockHeight
+		b.confirmedNtfns = append(b.confirmedNtfns,
+			nextConf.confirmationsNotification)
 
 		if b.confHeap.Len() == 0 {
 			return
@@ -394,7 +569,9 @@ func (b *BtcdNotifier) checkConfirmationTrigger(txSha *wire.ShaHash, blockHeight
 				chainntnfs.Log.Infof("Dispatching single conf "+
 					"notification, sha=%v, height=%v", txSha,
 					blockHeight)
+				confClient.initialConfirmHeight = uint32(blockHeight)
 				confClient.finConf <- blockHeight
+				b.confirmedNtfns = append(b.confirmedNtfns, confClient)
 				continue
 			}
 
@@ -451,7 +628,7 @@ type confirmationsNotification struct {
 	numConfirmations     uint32
 
 	finConf      chan int32
-	negativeConf chan int32 // TODO(roasbeef): re-org funny business
+	negativeConf chan int32
 }
 
 // RegisterConfirmationsNotification registers a notification with BtcdNotifier
@@ -63,13 +63,12 @@ type ChainNotifier interface {
 //
 // If the event that the original transaction becomes re-org'd out of the main
 // chain, the 'NegativeConf' will be sent upon with a value representing the
-// depth of the re-org.
+// depth of the re-org. This may occur either before, or after the 'Confirmed'
+// channel has been sent upon. Once a negative confirmation has been sent, the
+// notification is no longer active, so callers that wish to be notified once
+// the transaction is re-confirmed must register a new notification.
 type ConfirmationEvent struct {
 	Confirmed chan int32 // MUST be buffered.
-	// TODO(roasbeef): all goroutines on ln channel updates should also
-	// have a struct chan that's closed if funding gets re-org out. Need
-	// to sync, to request another confirmation event ntfn, then re-open
-	// channel after confs.
 
 	NegativeConf chan int32 // MUST be buffered.
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/roasbeef/btcd/rpctest"
 	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcrpcclient"
 	"github.com/roasbeef/btcutil"
 )
 
@@ -381,6 +382,79 @@ func testMultiClientConfirmationNotification(miner *rpctest.Harness,
 	}
 }
 
+func testReorgNegativeConfirmation(miner *rpctest.Harness,
+	notifier chainntnfs.ChainNotifier, t *testing.T) {
+
+	// We'd like to test that a client is sent a negative confirmation
+	// once the block including their transaction is re-org'd out of the
+	// main chain.
+	//
+	// To trigger a re-org, we'll create a second node which is initially
+	// synced with our miner, then disconnect it.
+	miner2, err := rpctest.New(netParams, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create mining node: %v", err)
+	}
+	defer miner2.TearDown()
+	if err := miner2.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to set up mining node: %v", err)
+	}
+
+	nodeSlice := []*rpctest.Harness{miner, miner2}
+	if err := rpctest.ConnectNode(miner2, miner); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+	if err := rpctest.JoinNodes(nodeSlice, rpctest.Blocks); err != nil {
+		t.Fatalf("unable to join nodes: %v", err)
+	}
+	err = miner2.Node.AddNode(miner.P2PAddress(), btcrpcclient.ANRemove)
+	if err != nil {
+		t.Fatalf("unable to disconnect nodes: %v", err)
+	}
+
+	// With the nodes disconnected, create a new transaction, and register
+	// for a notification once it has reached several confirmations.
+	txid, err := getTestTxId(miner)
+	if err != nil {
+		t.Fatalf("unable to create test tx: %v", err)
+	}
+	confIntent, err := notifier.RegisterConfirmationsNtfn(txid, 6)
+	if err != nil {
+		t.Fatalf("unable to register ntfn: %v", err)
+	}
+
+	// Include the transaction within a block on our miner's chain, while
+	// the second node mines a longer competing chain.
+	if _, err := miner.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate block: %v", err)
+	}
+	if _, err := miner2.Node.Generate(2); err != nil {
+		t.Fatalf("unable to generate blocks: %v", err)
+	}
+
+	// Once the nodes are reconnected, our miner will re-org onto the
+	// longer chain, disconnecting the block that included the
+	// transaction.
+	if err := rpctest.ConnectNode(miner2, miner); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+	if err := rpctest.JoinNodes(nodeSlice, rpctest.Blocks); err != nil {
+		t.Fatalf("unable to join nodes: %v", err)
+	}
+
+	select {
+	case depth := <-confIntent.NegativeConf:
+		if depth != 1 {
+			t.Fatalf("expected re-org depth of 1, instead got %v",
+				depth)
+		}
+	case <-confIntent.Confirmed:
+		t.Fatalf("confirmation notification sent for re-org'd tx")
+	case <-time.After(10 * time.Second):
+		t.Fatalf("negative confirmation notification never received")
+	}
+}
+
 var ntfnTests = []func(node *rpctest.Harness, notifier chainntnfs.ChainNotifier, t *testing.T){
 	testSingleConfirmationNotification,
 	testMultiConfirmationNotification,
@@ -388,6 +462,7 @@ var ntfnTests = []func(node *rpctest.Harness, notifier chainntnfs.ChainNotifier,
 	testMultiClientConfirmationNotification,
 	testSpendNotification,
 	testBlockEpochNotification,
+	testReorgNegativeConfirmation,
 }
 
 // TestInterfaces tests all registered interfaces with a unified set of tests
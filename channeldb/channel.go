@@ -9,6 +9,7 @@ import (
 
 	"github.com/boltdb/bolt"
 	"github.com/lightningnetwork/lnd/elkrem"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
@@ -90,6 +91,11 @@ var (
 	// deliveryScriptsKey stores the scripts for the final delivery in the
 	// case of a cooperative closure.
 	deliveryScriptsKey = []byte("dsk")
+
+	// commitDiffKey stores the latest commitment we've signed for the
+	// remote party which they haven't yet acknowledged by revoking their
+	// prior commitment, along with the updates first covered by it.
+	commitDiffKey = []byte("cdk")
 )
 
 // OpenChannel encapsulates the persistent and dynamic state of an open channel
@@ -133,6 +139,22 @@ type OpenChannel struct {
 	LocalCsvDelay  uint32
 	RemoteCsvDelay uint32
 
+	// The maximum number of HTLC's each side will accept within their
+	// commitment transaction at once. The local value bounds the HTLC's
+	// offered to us by the remote party, while the remote value bounds
+	// the HTLC's we offer to them.
+	LocalMaxAcceptedHTLCs  uint16
+	RemoteMaxAcceptedHTLCs uint16
+
+	// The maximum total value of outstanding HTLC's each side will accept
+	// within their commitment transaction at once.
+	LocalMaxValueInFlight  btcutil.Amount
+	RemoteMaxValueInFlight btcutil.Amount
+
+	// The smallest HTLC each side will accept.
+	LocalHtlcMinimum  btcutil.Amount
+	RemoteHtlcMinimum btcutil.Amount
+
 	// Current revocation for their commitment transaction. However, since
 	// this the derived public key, we don't yet have the pre-image so we
 	// aren't yet able to verify that it's actually in the hash chain.
@@ -384,6 +406,101 @@ func (c *OpenChannel) FindPreviousState(updateNum uint64) (*ChannelDelta, error)
 	return delta, nil
 }
 
+// CommitDiff is the latest commitment we've signed for the remote party which
+// has yet to be acknowledged by a revocation of their prior commitment. The
+// diff records the signature along with the update messages first covered by
+// the commitment, such that both can be retransmitted if they never reached
+// the remote party before the connection was lost.
+type CommitDiff struct {
+	// Height is the height of the signed commitment within the remote
+	// party's commitment chain.
+	Height uint64
+
+	// RevocationKey and RevocationHash are the revocation key+hash given
+	// to us by the remote party which were used within the commitment.
+	RevocationKey  *btcec.PublicKey
+	RevocationHash [32]byte
+
+	// CommitSig is the message carrying our signature for the commitment.
+	CommitSig *lnwire.CommitSignature
+
+	// Updates is the set of update messages which were sent to the remote
+	// party since our prior signature, in the order they were sent.
+	Updates []lnwire.Message
+}
+
+// PutCommitDiff writes the passed CommitDiff to disk, replacing any prior
+// diff recorded for the channel. This method is to be called each time we
+// sign a new commitment for the remote party, before the signature is sent.
+func (c *OpenChannel) PutCommitDiff(diff *CommitDiff) error {
+	return c.Db.store.Update(func(tx *bolt.Tx) error {
+		chanBucket, err := tx.CreateBucketIfNotExists(openChannelBucket)
+		if err != nil {
+			return err
+		}
+
+		id := c.TheirLNID[:]
+		nodeChanBucket, err := chanBucket.CreateBucketIfNotExists(id)
+		if err != nil {
+			return err
+		}
+
+		return putChanCommitDiff(nodeChanBucket, c.ChanID, diff)
+	})
+}
+
+// FetchCommitDiff returns the CommitDiff currently recorded for the channel.
+// If we haven't signed a commitment which is yet to be acknowledged by the
+// remote party, then ErrNoCommitDiff is returned.
+func (c *OpenChannel) FetchCommitDiff() (*CommitDiff, error) {
+	var diff *CommitDiff
+
+	err := c.Db.store.View(func(tx *bolt.Tx) error {
+		chanBucket := tx.Bucket(openChannelBucket)
+		if chanBucket == nil {
+			return ErrNoActiveChannels
+		}
+
+		nodeChanBucket := chanBucket.Bucket(c.TheirLNID[:])
+		if nodeChanBucket == nil {
+			return ErrNoActiveChannels
+		}
+
+		var err error
+		diff, err = fetchChanCommitDiff(nodeChanBucket, c.ChanID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// DeleteCommitDiff removes the CommitDiff recorded for the channel. This
+// method is to be called once the remote party has revoked the commitment
+// prior to the one recorded within the diff.
+func (c *OpenChannel) DeleteCommitDiff() error {
+	return c.Db.store.Update(func(tx *bolt.Tx) error {
+		chanBucket := tx.Bucket(openChannelBucket)
+		if chanBucket == nil {
+			return ErrNoActiveChannels
+		}
+
+		nodeChanBucket := chanBucket.Bucket(c.TheirLNID[:])
+		if nodeChanBucket == nil {
+			return ErrNoActiveChannels
+		}
+
+		var b bytes.Buffer
+		if err := writeOutpoint(&b, c.ChanID); err != nil {
+			return err
+		}
+
+		return deleteChanCommitDiff(nodeChanBucket, b.Bytes())
+	})
+}
+
 // CloseChannel closes a previously active lightning channel. Closing a channel
 // entails deleting all saved state within the database concerning this
 // channel, as well as created a small channel summary for record keeping
@@ -426,6 +543,14 @@ func (c *OpenChannel) CloseChannel() error {
 			return err
 		}
 
+		// The routing policy of the channel no longer applies, so
+		// it's purged as well.
+		if policies := tx.Bucket(channelPolicyBucket); policies != nil {
+			if err := policies.Delete(outPointBytes); err != nil {
+				return err
+			}
+		}
+
 		// Finally, create a summary of this channel in the closed
 		// channel bucket for this node.
 		return putClosedChannelSummary(tx, outPointBytes)
@@ -643,6 +768,9 @@ func deleteOpenChannel(openChanBucket *bolt.Bucket, nodeChanBucket *bolt.Bucket,
 	if err := deleteChanDeliveryScripts(nodeChanBucket, channelID); err != nil {
 		return err
 	}
+	if err := deleteChanCommitDiff(nodeChanBucket, channelID); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -754,7 +882,13 @@ func fetchChanMinFeePerKb(openChanBucket *bolt.Bucket, channel *OpenChannel) err
 	copy(keyPrefix, minFeePerKbPrefix)
 	copy(keyPrefix[3:], b.Bytes())
 
+	// Channels opened before a minimum fee rate was negotiated won't have
+	// one stored, in which case no minimum is enforced.
 	feeBytes := openChanBucket.Get(keyPrefix)
+	if feeBytes == nil {
+		channel.MinFeePerKb = 0
+		return nil
+	}
 	channel.MinFeePerKb = btcutil.Amount(byteOrder.Uint64(feeBytes))
 
 	return nil
@@ -1024,6 +1158,28 @@ func putChanCommitTxns(nodeChanBucket *bolt.Bucket, channel *OpenChannel) error
 		return err
 	}
 
+	var scratch16 [2]byte
+	byteOrder.PutUint16(scratch16[:], channel.LocalMaxAcceptedHTLCs)
+	if _, err := b.Write(scratch16[:]); err != nil {
+		return err
+	}
+	byteOrder.PutUint16(scratch16[:], channel.RemoteMaxAcceptedHTLCs)
+	if _, err := b.Write(scratch16[:]); err != nil {
+		return err
+	}
+
+	var scratch64 [8]byte
+	amts := []btcutil.Amount{
+		channel.LocalMaxValueInFlight, channel.RemoteMaxValueInFlight,
+		channel.LocalHtlcMinimum, channel.RemoteHtlcMinimum,
+	}
+	for _, amt := range amts {
+		byteOrder.PutUint64(scratch64[:], uint64(amt))
+		if _, err := b.Write(scratch64[:]); err != nil {
+			return err
+		}
+	}
+
 	return nodeChanBucket.Put(txnsKey, b.Bytes())
 }
 
@@ -1068,6 +1224,43 @@ func fetchChanCommitTxns(nodeChanBucket *bolt.Bucket, channel *OpenChannel) erro
 	}
 	channel.RemoteCsvDelay = byteOrder.Uint32(scratch)
 
+	// Channels opened before HTLC limits were negotiated won't have any
+	// stored, in which case the limits default to those implicitly
+	// enforced at the time: the maximum number of HTLC's permitted by the
+	// protocol, of any value.
+	var scratch16 [2]byte
+	_, err = io.ReadFull(txnBytes, scratch16[:])
+	switch {
+	case err == io.EOF:
+		channel.LocalMaxAcceptedHTLCs = lnwire.MaxHTLCNumber
+		channel.RemoteMaxAcceptedHTLCs = lnwire.MaxHTLCNumber
+		channel.LocalMaxValueInFlight = btcutil.MaxSatoshi
+		channel.RemoteMaxValueInFlight = btcutil.MaxSatoshi
+		channel.LocalHtlcMinimum = 0
+		channel.RemoteHtlcMinimum = 0
+		return nil
+	case err != nil:
+		return err
+	}
+	channel.LocalMaxAcceptedHTLCs = byteOrder.Uint16(scratch16[:])
+
+	if _, err := io.ReadFull(txnBytes, scratch16[:]); err != nil {
+		return err
+	}
+	channel.RemoteMaxAcceptedHTLCs = byteOrder.Uint16(scratch16[:])
+
+	var scratch64 [8]byte
+	amts := []*btcutil.Amount{
+		&channel.LocalMaxValueInFlight, &channel.RemoteMaxValueInFlight,
+		&channel.LocalHtlcMinimum, &channel.RemoteHtlcMinimum,
+	}
+	for _, amt := range amts {
+		if _, err := io.ReadFull(txnBytes, scratch64[:]); err != nil {
+			return err
+		}
+		*amt = btcutil.Amount(byteOrder.Uint64(scratch64[:]))
+	}
+
 	return nil
 }
 
@@ -1312,6 +1505,126 @@ func fetchChanDeliveryScripts(nodeChanBucket *bolt.Bucket, channel *OpenChannel)
 	return nil
 }
 
+// commitDiffNet is the network magic written within the header of each
+// message stored within a CommitDiff. The messages are never read off the
+// wire, so the value only needs to be consistent between writes and reads.
+const commitDiffNet = wire.BitcoinNet(0)
+
+func putChanCommitDiff(nodeChanBucket *bolt.Bucket, chanID *wire.OutPoint,
+	diff *CommitDiff) error {
+
+	var bc bytes.Buffer
+	if err := writeOutpoint(&bc, chanID); err != nil {
+		return err
+	}
+	diffKey := make([]byte, len(commitDiffKey)+bc.Len())
+	copy(diffKey[:3], commitDiffKey)
+	copy(diffKey[3:], bc.Bytes())
+
+	var b bytes.Buffer
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], diff.Height)
+	if _, err := b.Write(scratch[:]); err != nil {
+		return err
+	}
+	if _, err := b.Write(diff.RevocationKey.SerializeCompressed()); err != nil {
+		return err
+	}
+	if _, err := b.Write(diff.RevocationHash[:]); err != nil {
+		return err
+	}
+
+	_, err := lnwire.WriteMessage(&b, diff.CommitSig, 0, commitDiffNet)
+	if err != nil {
+		return err
+	}
+
+	byteOrder.PutUint16(scratch[:2], uint16(len(diff.Updates)))
+	if _, err := b.Write(scratch[:2]); err != nil {
+		return err
+	}
+	for _, msg := range diff.Updates {
+		_, err := lnwire.WriteMessage(&b, msg, 0, commitDiffNet)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nodeChanBucket.Put(diffKey, b.Bytes())
+}
+
+func deleteChanCommitDiff(nodeChanBucket *bolt.Bucket, chanID []byte) error {
+	diffKey := make([]byte, len(commitDiffKey)+len(chanID))
+	copy(diffKey[:3], commitDiffKey)
+	copy(diffKey[3:], chanID)
+	return nodeChanBucket.Delete(diffKey)
+}
+
+func fetchChanCommitDiff(nodeChanBucket *bolt.Bucket,
+	chanID *wire.OutPoint) (*CommitDiff, error) {
+
+	var bc bytes.Buffer
+	if err := writeOutpoint(&bc, chanID); err != nil {
+		return nil, err
+	}
+	diffKey := make([]byte, len(commitDiffKey)+bc.Len())
+	copy(diffKey[:3], commitDiffKey)
+	copy(diffKey[3:], bc.Bytes())
+
+	diffBytes := nodeChanBucket.Get(diffKey)
+	if diffBytes == nil {
+		return nil, ErrNoCommitDiff
+	}
+	r := bytes.NewReader(diffBytes)
+
+	diff := &CommitDiff{}
+	var scratch [33]byte
+	if _, err := io.ReadFull(r, scratch[:8]); err != nil {
+		return nil, err
+	}
+	diff.Height = byteOrder.Uint64(scratch[:8])
+
+	if _, err := io.ReadFull(r, scratch[:33]); err != nil {
+		return nil, err
+	}
+	revKey, err := btcec.ParsePubKey(scratch[:33], btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	diff.RevocationKey = revKey
+
+	if _, err := io.ReadFull(r, diff.RevocationHash[:]); err != nil {
+		return nil, err
+	}
+
+	_, msg, _, err := lnwire.ReadMessage(r, 0, commitDiffNet)
+	if err != nil {
+		return nil, err
+	}
+	commitSig, ok := msg.(*lnwire.CommitSignature)
+	if !ok {
+		return nil, fmt.Errorf("expected CommitSignature within "+
+			"commit diff, instead got %T", msg)
+	}
+	diff.CommitSig = commitSig
+
+	if _, err := io.ReadFull(r, scratch[:2]); err != nil {
+		return nil, err
+	}
+	numUpdates := byteOrder.Uint16(scratch[:2])
+
+	diff.Updates = make([]lnwire.Message, numUpdates)
+	for i := uint16(0); i < numUpdates; i++ {
+		_, diff.Updates[i], _, err = lnwire.ReadMessage(r, 0,
+			commitDiffNet)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return diff, nil
+}
+
 // htlcDiskSize represents the number of btyes a serialized HTLC takes up on
 // disk. The size of an HTLC on disk is 49 bytes total: incoming (1) + amt (8)
 // + rhash (32) + timeouts (8)
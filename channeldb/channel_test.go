@@ -8,8 +8,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/boltdb/bolt"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/elkrem"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/chaincfg"
 	"github.com/roasbeef/btcd/txscript"
@@ -155,6 +157,12 @@ func createTestChannelState(cdb *DB) (*OpenChannel, error) {
 		TheirDeliveryScript:        script,
 		LocalCsvDelay:              5,
 		RemoteCsvDelay:             9,
+		LocalMaxAcceptedHTLCs:      30,
+		RemoteMaxAcceptedHTLCs:     483,
+		LocalMaxValueInFlight:      btcutil.Amount(5e6),
+		RemoteMaxValueInFlight:     btcutil.Amount(6e6),
+		LocalHtlcMinimum:           btcutil.Amount(10),
+		RemoteHtlcMinimum:          btcutil.Amount(20),
 		NumUpdates:                 0,
 		TotalSatoshisSent:          8,
 		TotalSatoshisReceived:      2,
@@ -280,6 +288,26 @@ func TestOpenChannelPutGetDelete(t *testing.T) {
 		t.Fatalf("csv delay doesn't match: %v vs %v",
 			state.LocalCsvDelay, newState.LocalCsvDelay)
 	}
+	if state.LocalMaxAcceptedHTLCs != newState.LocalMaxAcceptedHTLCs ||
+		state.RemoteMaxAcceptedHTLCs != newState.RemoteMaxAcceptedHTLCs {
+		t.Fatalf("max accepted htlcs doesn't match: %v/%v vs %v/%v",
+			state.LocalMaxAcceptedHTLCs, state.RemoteMaxAcceptedHTLCs,
+			newState.LocalMaxAcceptedHTLCs,
+			newState.RemoteMaxAcceptedHTLCs)
+	}
+	if state.LocalMaxValueInFlight != newState.LocalMaxValueInFlight ||
+		state.RemoteMaxValueInFlight != newState.RemoteMaxValueInFlight {
+		t.Fatalf("max value in flight doesn't match: %v/%v vs %v/%v",
+			state.LocalMaxValueInFlight, state.RemoteMaxValueInFlight,
+			newState.LocalMaxValueInFlight,
+			newState.RemoteMaxValueInFlight)
+	}
+	if state.LocalHtlcMinimum != newState.LocalHtlcMinimum ||
+		state.RemoteHtlcMinimum != newState.RemoteHtlcMinimum {
+		t.Fatalf("htlc minimum doesn't match: %v/%v vs %v/%v",
+			state.LocalHtlcMinimum, state.RemoteHtlcMinimum,
+			newState.LocalHtlcMinimum, newState.RemoteHtlcMinimum)
+	}
 	if state.TotalSatoshisSent != newState.TotalSatoshisSent {
 		t.Fatalf("satoshis sent doesn't match: %v vs %v",
 			state.TotalSatoshisSent, newState.TotalSatoshisSent)
@@ -478,3 +506,169 @@ func TestChannelStateTransition(t *testing.T) {
 		t.Fatalf("revocation state wasn't synced!")
 	}
 }
+
+func TestFetchLegacyHTLCLimits(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	channel, err := createTestChannelState(cdb)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	if err := channel.FullSync(); err != nil {
+		t.Fatalf("unable to save and serialize channel state: %v", err)
+	}
+
+	// Strip the HTLC limits from the stored commitment record, leaving
+	// the record as it was written before the limits were negotiated.
+	const htlcLimitsSize = 2 + 2 + 8*4
+	err = cdb.store.Update(func(tx *bolt.Tx) error {
+		nodeChanBucket := tx.Bucket(openChannelBucket).Bucket(
+			channel.TheirLNID[:])
+
+		var b bytes.Buffer
+		if err := writeOutpoint(&b, channel.ChanID); err != nil {
+			return err
+		}
+		txnsKey := append(append([]byte(nil), commitTxnsKey...),
+			b.Bytes()...)
+
+		txnBytes := nodeChanBucket.Get(txnsKey)
+		legacyBytes := make([]byte, len(txnBytes)-htlcLimitsSize)
+		copy(legacyBytes, txnBytes)
+
+		return nodeChanBucket.Put(txnsKey, legacyBytes)
+	})
+	if err != nil {
+		t.Fatalf("unable to write legacy commitment record: %v", err)
+	}
+
+	// The channel should still be readable, with the limits defaulting
+	// to those enforced before they were negotiated.
+	nodeID := wire.ShaHash(channel.TheirLNID)
+	channels, err := cdb.FetchOpenChannels(&nodeID)
+	if err != nil {
+		t.Fatalf("unable to fetch open channels: %v", err)
+	}
+	if len(channels) != 1 {
+		t.Fatalf("expected 1 channel, instead have %v", len(channels))
+	}
+	dbChannel := channels[0]
+
+	if dbChannel.LocalMaxAcceptedHTLCs != lnwire.MaxHTLCNumber ||
+		dbChannel.RemoteMaxAcceptedHTLCs != lnwire.MaxHTLCNumber {
+		t.Fatalf("max accepted htlcs not defaulted: local=%v, "+
+			"remote=%v", dbChannel.LocalMaxAcceptedHTLCs,
+			dbChannel.RemoteMaxAcceptedHTLCs)
+	}
+	if dbChannel.LocalMaxValueInFlight != btcutil.MaxSatoshi ||
+		dbChannel.RemoteMaxValueInFlight != btcutil.MaxSatoshi {
+		t.Fatalf("max value in flight not defaulted: local=%v, "+
+			"remote=%v", dbChannel.LocalMaxValueInFlight,
+			dbChannel.RemoteMaxValueInFlight)
+	}
+	if dbChannel.LocalHtlcMinimum != 0 || dbChannel.RemoteHtlcMinimum != 0 {
+		t.Fatalf("htlc minimum not defaulted: local=%v, remote=%v",
+			dbChannel.LocalHtlcMinimum, dbChannel.RemoteHtlcMinimum)
+	}
+	if dbChannel.RemoteCsvDelay != channel.RemoteCsvDelay {
+		t.Fatalf("csv delay mismatch: expected %v, got %v",
+			channel.RemoteCsvDelay, dbChannel.RemoteCsvDelay)
+	}
+}
+
+func TestCommitDiffPutFetchDelete(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	channel, err := createTestChannelState(cdb)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	if err := channel.FullSync(); err != nil {
+		t.Fatalf("unable to save and serialize channel state: %v", err)
+	}
+
+	// No diff should be returned before one has been written.
+	if _, err := channel.FetchCommitDiff(); err != ErrNoCommitDiff {
+		t.Fatalf("expected ErrNoCommitDiff, instead got: %v", err)
+	}
+
+	sig, err := privKey.Sign(key[:])
+	if err != nil {
+		t.Fatalf("unable to generate signature: %v", err)
+	}
+	diff := &CommitDiff{
+		Height:         4,
+		RevocationKey:  pubKey,
+		RevocationHash: rev,
+		CommitSig: &lnwire.CommitSignature{
+			ChannelPoint: id,
+			LogIndex:     2,
+			CommitSig:    sig,
+		},
+		Updates: []lnwire.Message{
+			&lnwire.HTLCAddRequest{
+				ChannelPoint:     id,
+				Expiry:           144,
+				Amount:           lnwire.CreditsAmount(50000),
+				RedemptionHashes: [][32]byte{key},
+				OnionBlob:        bytes.Repeat([]byte{1}, 20),
+			},
+			&lnwire.HTLCSettleRequest{
+				ChannelPoint:     id,
+				HTLCKey:          lnwire.HTLCKey(1),
+				RedemptionProofs: [][32]byte{rev},
+			},
+			&lnwire.CancelHTLC{
+				ChannelPoint: id,
+				HTLCKey:      lnwire.HTLCKey(0),
+				Reason:       bytes.Repeat([]byte{2}, 64),
+			},
+		},
+	}
+	if err := channel.PutCommitDiff(diff); err != nil {
+		t.Fatalf("unable to write commit diff: %v", err)
+	}
+
+	// The diff read from disk should be identical to the one written.
+	diskDiff, err := channel.FetchCommitDiff()
+	if err != nil {
+		t.Fatalf("unable to fetch commit diff: %v", err)
+	}
+	if diskDiff.Height != diff.Height {
+		t.Fatalf("heights don't match: %v vs %v", diskDiff.Height,
+			diff.Height)
+	}
+	if !diskDiff.RevocationKey.IsEqual(diff.RevocationKey) {
+		t.Fatalf("revocation keys don't match")
+	}
+	if diskDiff.RevocationHash != diff.RevocationHash {
+		t.Fatalf("revocation hashes don't match")
+	}
+	if diskDiff.CommitSig.LogIndex != diff.CommitSig.LogIndex {
+		t.Fatalf("log indexes don't match: %v vs %v",
+			diskDiff.CommitSig.LogIndex, diff.CommitSig.LogIndex)
+	}
+	if !bytes.Equal(diskDiff.CommitSig.CommitSig.Serialize(), sig.Serialize()) {
+		t.Fatalf("signatures don't match")
+	}
+	if !reflect.DeepEqual(diskDiff.Updates, diff.Updates) {
+		t.Fatalf("updates don't match: %v vs %v",
+			spew.Sdump(diskDiff.Updates), spew.Sdump(diff.Updates))
+	}
+
+	// Once deleted, the diff should no longer be found.
+	if err := channel.DeleteCommitDiff(); err != nil {
+		t.Fatalf("unable to delete commit diff: %v", err)
+	}
+	if _, err := channel.FetchCommitDiff(); err != ErrNoCommitDiff {
+		t.Fatalf("expected ErrNoCommitDiff, instead got: %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package channeldb
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	// circuitBucket is the name of the top-level bucket which houses all
+	// the active payment circuits of the htlc switch. Each circuit is
+	// keyed by the payment hash of the HTLC which created it, followed by
+	// the circuit's ID. As the parts of a multi-part payment share a
+	// single payment hash, several circuits may exist for each hash.
+	circuitBucket = []byte("circuits")
+)
+
+// PaymentCircuit is the on-disk representation of an active Sphinx (onion
+// routing) circuit between two channels. A circuit is created once an HTLC is
+// forwarded from one channel to another, and is removed once the HTLC has
+// been either settled or cancelled. Persisting circuits allows a forwarding
+// node to propagate a settle or cancel back upstream after a restart.
+type PaymentCircuit struct {
+	// PaymentHash is the payment hash of the HTLC which created the
+	// circuit.
+	PaymentHash [32]byte
+
+	// ID distinguishes the circuit from any other circuits created by
+	// HTLC's sharing its payment hash. The ID is assigned once the
+	// circuit has been added to the database.
+	ID uint64
+
+	// ClearChanPoint is the channel point of the channel the HTLC was
+	// forwarded over.
+	ClearChanPoint wire.OutPoint
+
+	// SettleChanPoint is the channel point of the channel the HTLC was
+	// originally received over. A settle or cancel for the HTLC is
+	// forwarded back over this channel.
+	SettleChanPoint wire.OutPoint
+
+	// IncomingIndex is the index of the HTLC within the remote log of the
+	// settle channel, identifying the HTLC to settle or cancel.
+	IncomingIndex uint32
+
+	// IncomingAmt is the amount of the HTLC received over the settle
+	// channel.
+	IncomingAmt btcutil.Amount
+
+	// OutgoingAmt is the amount of the HTLC forwarded over the clear
+	// channel. The difference between the incoming and outgoing amounts
+	// is the fee earned by forwarding the HTLC.
+	OutgoingAmt btcutil.Amount
+}
+
+// AddPaymentCircuit writes the passed payment circuit to disk, assigning it
+// a new unique ID. Existing circuits with the same payment hash are left
+// untouched.
+func (d *DB) AddPaymentCircuit(circuit *PaymentCircuit) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		circuits, err := tx.CreateBucketIfNotExists(circuitBucket)
+		if err != nil {
+			return err
+		}
+
+		circuit.ID, err = circuits.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := serializePaymentCircuit(&b, circuit); err != nil {
+			return err
+		}
+
+		return circuits.Put(circuitKey(circuit), b.Bytes())
+	})
+}
+
+// DeletePaymentCircuit removes the passed payment circuit from disk. No error
+// is returned if the circuit doesn't exist.
+func (d *DB) DeletePaymentCircuit(circuit *PaymentCircuit) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		circuits := tx.Bucket(circuitBucket)
+		if circuits == nil {
+			return nil
+		}
+
+		return circuits.Delete(circuitKey(circuit))
+	})
+}
+
+// circuitKey returns the key the passed circuit is stored under within the
+// circuit bucket: the circuit's payment hash followed by its ID.
+func circuitKey(c *PaymentCircuit) []byte {
+	var k [40]byte
+	copy(k[:32], c.PaymentHash[:])
+	byteOrder.PutUint64(k[32:], c.ID)
+
+	return k[:]
+}
+
+// FetchAllPaymentCircuits returns all the payment circuits currently stored
+// on disk. In the case that no circuits exist, a zero-length slice is
+// returned.
+func (d *DB) FetchAllPaymentCircuits() ([]*PaymentCircuit, error) {
+	var circuits []*PaymentCircuit
+	err := d.store.View(func(tx *bolt.Tx) error {
+		circuitBkt := tx.Bucket(circuitBucket)
+		if circuitBkt == nil {
+			return nil
+		}
+
+		return circuitBkt.ForEach(func(k, v []byte) error {
+			circuit, err := deserializePaymentCircuit(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			circuits = append(circuits, circuit)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return circuits, nil
+}
+
+func serializePaymentCircuit(w io.Writer, c *PaymentCircuit) error {
+	if _, err := w.Write(c.PaymentHash[:]); err != nil {
+		return err
+	}
+
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], c.ID)
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	if err := writeOutpoint(w, &c.ClearChanPoint); err != nil {
+		return err
+	}
+
+	if err := writeOutpoint(w, &c.SettleChanPoint); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint32(scratch[:4], c.IncomingIndex)
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(c.IncomingAmt))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(c.OutgoingAmt))
+	_, err := w.Write(scratch[:])
+	return err
+}
+
+func deserializePaymentCircuit(r io.Reader) (*PaymentCircuit, error) {
+	c := &PaymentCircuit{}
+
+	if _, err := io.ReadFull(r, c.PaymentHash[:]); err != nil {
+		return nil, err
+	}
+
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	c.ID = byteOrder.Uint64(scratch[:])
+
+	if err := readOutpoint(r, &c.ClearChanPoint); err != nil {
+		return nil, err
+	}
+
+	if err := readOutpoint(r, &c.SettleChanPoint); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return nil, err
+	}
+	c.IncomingIndex = byteOrder.Uint32(scratch[:4])
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	c.IncomingAmt = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	c.OutgoingAmt = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	return c, nil
+}
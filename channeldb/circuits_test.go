@@ -0,0 +1,111 @@
+package channeldb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestPaymentCircuitWorkflow(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	circuit := &PaymentCircuit{
+		PaymentHash:     rev,
+		ClearChanPoint:  wire.OutPoint{Hash: key, Index: 0},
+		SettleChanPoint: *id,
+		IncomingIndex:   7,
+		IncomingAmt:     1001,
+		OutgoingAmt:     1000,
+	}
+
+	// Add the circuit to the database, it should then be returned when
+	// fetching all active circuits.
+	if err := db.AddPaymentCircuit(circuit); err != nil {
+		t.Fatalf("unable to add payment circuit: %v", err)
+	}
+	circuits, err := db.FetchAllPaymentCircuits()
+	if err != nil {
+		t.Fatalf("unable to fetch payment circuits: %v", err)
+	}
+	if len(circuits) != 1 {
+		t.Fatalf("expected 1 circuit, instead have %v", len(circuits))
+	}
+	if !reflect.DeepEqual(circuit, circuits[0]) {
+		t.Fatalf("circuits don't match: %v vs %v",
+			spew.Sdump(circuit), spew.Sdump(circuits[0]))
+	}
+
+	// Once the circuit is deleted, no circuits should remain.
+	if err := db.DeletePaymentCircuit(circuit); err != nil {
+		t.Fatalf("unable to delete payment circuit: %v", err)
+	}
+	circuits, err = db.FetchAllPaymentCircuits()
+	if err != nil {
+		t.Fatalf("unable to fetch payment circuits: %v", err)
+	}
+	if len(circuits) != 0 {
+		t.Fatalf("expected no circuits, instead have %v", len(circuits))
+	}
+}
+
+func TestPaymentCircuitSharedHash(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	// Add two circuits sharing the same payment hash, as would be created
+	// when forwarding two parts of a multi-part payment. Each should be
+	// assigned a distinct ID.
+	first := &PaymentCircuit{
+		PaymentHash:     rev,
+		ClearChanPoint:  wire.OutPoint{Hash: key, Index: 0},
+		SettleChanPoint: *id,
+	}
+	second := &PaymentCircuit{
+		PaymentHash:     rev,
+		ClearChanPoint:  wire.OutPoint{Hash: key, Index: 1},
+		SettleChanPoint: *id,
+	}
+	if err := db.AddPaymentCircuit(first); err != nil {
+		t.Fatalf("unable to add payment circuit: %v", err)
+	}
+	if err := db.AddPaymentCircuit(second); err != nil {
+		t.Fatalf("unable to add payment circuit: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("circuits sharing a payment hash were assigned the "+
+			"same ID: %v", first.ID)
+	}
+
+	circuits, err := db.FetchAllPaymentCircuits()
+	if err != nil {
+		t.Fatalf("unable to fetch payment circuits: %v", err)
+	}
+	if len(circuits) != 2 {
+		t.Fatalf("expected 2 circuits, instead have %v", len(circuits))
+	}
+
+	// Deleting the first circuit should leave the second intact.
+	if err := db.DeletePaymentCircuit(first); err != nil {
+		t.Fatalf("unable to delete payment circuit: %v", err)
+	}
+	circuits, err = db.FetchAllPaymentCircuits()
+	if err != nil {
+		t.Fatalf("unable to fetch payment circuits: %v", err)
+	}
+	if len(circuits) != 1 {
+		t.Fatalf("expected 1 circuit, instead have %v", len(circuits))
+	}
+	if !reflect.DeepEqual(second, circuits[0]) {
+		t.Fatalf("circuits don't match: %v vs %v",
+			spew.Sdump(second), spew.Sdump(circuits[0]))
+	}
+}
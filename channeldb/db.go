@@ -53,6 +53,13 @@ func Open(dbPath string, netParams *chaincfg.Params) (*DB, error) {
 		return nil, err
 	}
 
+	// Bring any invoices written using a prior encoding up to date before
+	// the database is used.
+	if err := bdb.Update(migrateInvoices); err != nil {
+		bdb.Close()
+		return nil, err
+	}
+
 	return &DB{store: bdb, netParams: netParams}, nil
 }
 
@@ -76,6 +83,41 @@ func (d *DB) Wipe() error {
 			return err
 		}
 
+		err = tx.DeleteBucket(nurseryBucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+
+		err = tx.DeleteBucket(circuitBucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+
+		err = tx.DeleteBucket(peerAddrBucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+
+		err = tx.DeleteBucket(pendingChanBucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+
+		err = tx.DeleteBucket(paymentBucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+
+		err = tx.DeleteBucket(channelPolicyBucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+
+		err = tx.DeleteBucket(forwardingLogBucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+
 		return nil
 	})
 }
@@ -119,6 +161,34 @@ func createChannelDB(dbPath string) error {
 			return err
 		}
 
+		if _, err := tx.CreateBucket(nurseryBucket); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucket(circuitBucket); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucket(peerAddrBucket); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucket(pendingChanBucket); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucket(paymentBucket); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucket(channelPolicyBucket); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucket(forwardingLogBucket); err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -160,39 +230,102 @@ func (d *DB) FetchOpenChannels(nodeID *wire.ShaHash) ([]*OpenChannel, error) {
 			return nil
 		}
 
-		// Once we have the node's channel bucket, iterate through each
-		// item in the inner chan ID bucket. This bucket acts as an
-		// index for all channels we currently have open with this node.
-		nodeChanIDBucket := nodeChanBucket.Bucket(chanIDBucket[:])
-		if nodeChanIDBucket == nil {
+		// Finally, with the node's channel bucket obtained, read out
+		// each of the channels we currently have open with this node.
+		nodeChannels, err := d.fetchNodeChannels(openChanBucket,
+			nodeChanBucket)
+		if err != nil {
+			return err
+		}
+
+		channels = nodeChannels
+		return nil
+	})
+
+	return channels, err
+}
+
+// fetchNodeChannels retrieves all active channels from the target
+// nodeChanBucket. This function is typically used to fetch all the active
+// channels related to a particular node.
+func (d *DB) fetchNodeChannels(openChanBucket,
+	nodeChanBucket *bolt.Bucket) ([]*OpenChannel, error) {
+
+	var channels []*OpenChannel
+
+	// Once we have the node's channel bucket, iterate through each item in
+	// the inner chan ID bucket. This bucket acts as an index for all
+	// channels we currently have open with this node.
+	nodeChanIDBucket := nodeChanBucket.Bucket(chanIDBucket[:])
+	if nodeChanIDBucket == nil {
+		return nil, nil
+	}
+	err := nodeChanIDBucket.ForEach(func(k, v []byte) error {
+		if k == nil {
 			return nil
 		}
-		err := nodeChanIDBucket.ForEach(func(k, v []byte) error {
-			if k == nil {
+
+		outBytes := bytes.NewReader(k)
+		chanID := &wire.OutPoint{}
+		if err := readOutpoint(outBytes, chanID); err != nil {
+			return err
+		}
+
+		oChannel, err := fetchOpenChannel(openChanBucket,
+			nodeChanBucket, chanID)
+		if err != nil {
+			return err
+		}
+		oChannel.Db = d
+
+		channels = append(channels, oChannel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// FetchAllChannels attempts to retrieve all open channels currently stored
+// within the database, across all nodes. In the case that no active channels
+// are known, then a zero-length slice is returned.
+func (d *DB) FetchAllChannels() ([]*OpenChannel, error) {
+	var channels []*OpenChannel
+
+	err := d.store.View(func(tx *bolt.Tx) error {
+		// Get the bucket dedicated to storing the meta-data for open
+		// channels.
+		openChanBucket := tx.Bucket(openChannelBucket)
+		if openChanBucket == nil {
+			return nil
+		}
+
+		// Next, fetch the bucket dedicated to storing the channels of
+		// each node. Each nested bucket within the open channel bucket
+		// is keyed by the ID of the remote node.
+		return openChanBucket.ForEach(func(nodeID, v []byte) error {
+			// If the value is non-nil, then this isn't a nested
+			// bucket, so we skip it.
+			if v != nil {
 				return nil
 			}
 
-			outBytes := bytes.NewReader(k)
-			chanID := &wire.OutPoint{}
-			if err := readOutpoint(outBytes, chanID); err != nil {
-				return err
+			nodeChanBucket := openChanBucket.Bucket(nodeID)
+			if nodeChanBucket == nil {
+				return nil
 			}
 
-			oChannel, err := fetchOpenChannel(openChanBucket,
-				nodeChanBucket, chanID)
+			nodeChannels, err := d.fetchNodeChannels(openChanBucket,
+				nodeChanBucket)
 			if err != nil {
 				return err
 			}
-			oChannel.Db = d
 
-			channels = append(channels, oChannel)
+			channels = append(channels, nodeChannels...)
 			return nil
 		})
-		if err != nil {
-			return err
-		}
-
-		return nil
 	})
 
 	return channels, err
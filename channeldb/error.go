@@ -8,8 +8,17 @@ var (
 	ErrNoActiveChannels = fmt.Errorf("no active channels exist")
 	ErrChannelNoExist   = fmt.Errorf("this channel does not exist")
 	ErrNoPastDeltas     = fmt.Errorf("channel has no recorded deltas")
+	ErrNoCommitDiff     = fmt.Errorf("channel has no unacked commitment")
 
 	ErrInvoiceNotFound   = fmt.Errorf("unable to locate invoice")
 	ErrNoInvoicesCreated = fmt.Errorf("there are no existing invoices")
 	ErrDuplicateInvoice  = fmt.Errorf("invoice with payment hash already exists")
+
+	ErrInvoiceAlreadySettled = fmt.Errorf("invoice has already been settled")
+	ErrInvoiceCancelled      = fmt.Errorf("invoice has been cancelled")
+	ErrInvoiceExpired        = fmt.Errorf("invoice has expired")
+
+	ErrPaymentNotFound = fmt.Errorf("unable to locate payment")
+
+	ErrChannelPolicyNotFound = fmt.Errorf("unable to locate channel policy")
 )
@@ -1 +1,185 @@
 package channeldb
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	// channelPolicyBucket is the name of the top-level bucket which
+	// stores the routing policy of each of our open channels. Each entry
+	// is keyed by the serialized channel point of the channel.
+	channelPolicyBucket = []byte("channel-policies")
+)
+
+// feeRateDenominator is the denominator of a channel's proportional fee
+// rate. Fee rates are expressed in millionths of the amount forwarded.
+const feeRateDenominator = 1000000
+
+// ChannelPolicy is the routing policy enforced by a node when forwarding HTLC's
+// over one of its channels. A forwarding node deducts its fee from the
+// amount of the incoming HTLC, forwarding the remainder to the next hop.
+// Likewise, the expiry of the outgoing HTLC is reduced by the time-lock delta
+// of the channel. The sender of a payment accounts for the policy of each hop
+// within the route when determining the amount and expiry of the HTLC it
+// sends to the first hop.
+type ChannelPolicy struct {
+	// BaseFee is the flat fee charged for each HTLC forwarded over the
+	// channel.
+	BaseFee btcutil.Amount
+
+	// FeeRate is the proportional fee charged for each HTLC forwarded
+	// over the channel, expressed in millionths of the amount forwarded.
+	FeeRate uint32
+
+	// MinHTLC is the smallest HTLC which will be forwarded over the
+	// channel.
+	MinHTLC btcutil.Amount
+
+	// TimeLockDelta is the number of blocks the expiry of an outgoing
+	// HTLC is reduced by, relative to the incoming HTLC. This is the
+	// time the forwarding node has to settle or cancel the incoming HTLC
+	// once the outgoing HTLC has been resolved.
+	TimeLockDelta uint32
+}
+
+// Fee returns the fee charged for forwarding an HTLC of the passed amount
+// over the channel.
+func (p *ChannelPolicy) Fee(amt btcutil.Amount) btcutil.Amount {
+	return p.BaseFee + amt*btcutil.Amount(p.FeeRate)/feeRateDenominator
+}
+
+// ForwardAmount returns the amount to be forwarded to the next hop for an
+// incoming HTLC of the passed amount. This is the largest amount which,
+// along with the fee charged for forwarding it, is covered by the incoming
+// HTLC. If the incoming HTLC doesn't cover the fee, then zero is returned.
+func (p *ChannelPolicy) ForwardAmount(incoming btcutil.Amount) btcutil.Amount {
+	if incoming <= p.BaseFee {
+		return 0
+	}
+
+	// First approximate the amount by inverting the fee function, then
+	// correct for any rounding so the result is exact.
+	amt := (incoming - p.BaseFee) * feeRateDenominator /
+		(feeRateDenominator + btcutil.Amount(p.FeeRate))
+	for amt+1+p.Fee(amt+1) <= incoming {
+		amt++
+	}
+	for amt > 0 && amt+p.Fee(amt) > incoming {
+		amt--
+	}
+
+	return amt
+}
+
+// PutChannelPolicy writes the routing policy of the channel identified by the
+// passed channel point to disk. If a policy for the channel already exists,
+// then it's overwritten.
+func (d *DB) PutChannelPolicy(chanPoint *wire.OutPoint,
+	policy *ChannelPolicy) error {
+
+	return d.store.Update(func(tx *bolt.Tx) error {
+		policies, err := tx.CreateBucketIfNotExists(channelPolicyBucket)
+		if err != nil {
+			return err
+		}
+
+		var k bytes.Buffer
+		if err := writeOutpoint(&k, chanPoint); err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := serializeChannelPolicy(&b, policy); err != nil {
+			return err
+		}
+
+		return policies.Put(k.Bytes(), b.Bytes())
+	})
+}
+
+// FetchChannelPolicy returns the routing policy of the channel identified by
+// the passed channel point. If the channel doesn't have a policy, then
+// ErrChannelPolicyNotFound is returned.
+func (d *DB) FetchChannelPolicy(chanPoint *wire.OutPoint) (*ChannelPolicy, error) {
+	var policy *ChannelPolicy
+	err := d.store.View(func(tx *bolt.Tx) error {
+		policies := tx.Bucket(channelPolicyBucket)
+		if policies == nil {
+			return ErrChannelPolicyNotFound
+		}
+
+		var k bytes.Buffer
+		if err := writeOutpoint(&k, chanPoint); err != nil {
+			return err
+		}
+
+		policyBytes := policies.Get(k.Bytes())
+		if policyBytes == nil {
+			return ErrChannelPolicyNotFound
+		}
+
+		var err error
+		policy, err = deserializeChannelPolicy(bytes.NewReader(policyBytes))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func serializeChannelPolicy(w io.Writer, p *ChannelPolicy) error {
+	var scratch [8]byte
+
+	byteOrder.PutUint64(scratch[:], uint64(p.BaseFee))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint32(scratch[:4], p.FeeRate)
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(p.MinHTLC))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint32(scratch[:4], p.TimeLockDelta)
+	_, err := w.Write(scratch[:4])
+	return err
+}
+
+func deserializeChannelPolicy(r io.Reader) (*ChannelPolicy, error) {
+	var scratch [8]byte
+	p := &ChannelPolicy{}
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	p.BaseFee = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return nil, err
+	}
+	p.FeeRate = byteOrder.Uint32(scratch[:4])
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	p.MinHTLC = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return nil, err
+	}
+	p.TimeLockDelta = byteOrder.Uint32(scratch[:4])
+
+	return p, nil
+}
@@ -0,0 +1,94 @@
+package channeldb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+func TestChannelPolicyWorkflow(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	chanPoint := &wire.OutPoint{
+		Hash:  key,
+		Index: 1,
+	}
+
+	// Before a policy has been written, attempting to fetch it should
+	// fail.
+	if _, err := db.FetchChannelPolicy(chanPoint); err != ErrChannelPolicyNotFound {
+		t.Fatalf("expected ErrChannelPolicyNotFound, instead got: %v", err)
+	}
+
+	policy := &ChannelPolicy{
+		BaseFee:       1000,
+		FeeRate:       250,
+		MinHTLC:       10,
+		TimeLockDelta: 144,
+	}
+	if err := db.PutChannelPolicy(chanPoint, policy); err != nil {
+		t.Fatalf("unable to put channel policy: %v", err)
+	}
+	dbPolicy, err := db.FetchChannelPolicy(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch channel policy: %v", err)
+	}
+	if !reflect.DeepEqual(policy, dbPolicy) {
+		t.Fatalf("policies don't match: %v vs %v", spew.Sdump(policy),
+			spew.Sdump(dbPolicy))
+	}
+
+	// Writing a new policy for the same channel should overwrite the
+	// existing policy.
+	policy.FeeRate = 500
+	if err := db.PutChannelPolicy(chanPoint, policy); err != nil {
+		t.Fatalf("unable to put channel policy: %v", err)
+	}
+	dbPolicy, err = db.FetchChannelPolicy(chanPoint)
+	if err != nil {
+		t.Fatalf("unable to fetch channel policy: %v", err)
+	}
+	if dbPolicy.FeeRate != policy.FeeRate {
+		t.Fatalf("fee rate wasn't updated: expected %v, got %v",
+			policy.FeeRate, dbPolicy.FeeRate)
+	}
+}
+
+func TestChannelPolicyForwardAmount(t *testing.T) {
+	policies := []*ChannelPolicy{
+		{BaseFee: 0, FeeRate: 0},
+		{BaseFee: 1, FeeRate: 1},
+		{BaseFee: 1000, FeeRate: 250},
+		{BaseFee: 7, FeeRate: 333333},
+	}
+	amounts := []btcutil.Amount{1, 999, 1000, 123457, 50000000}
+
+	// A sender pays each hop the amount to be forwarded along with the
+	// hop's fee, so the hop must recover exactly the amount the sender
+	// intended to be forwarded.
+	for _, policy := range policies {
+		for _, amt := range amounts {
+			incoming := amt + policy.Fee(amt)
+			if fwdAmt := policy.ForwardAmount(incoming); fwdAmt != amt {
+				t.Fatalf("policy %v: expected to forward %v of %v, "+
+					"instead forwarding %v", policy, amt,
+					incoming, fwdAmt)
+			}
+		}
+	}
+
+	// An incoming HTLC which doesn't cover the base fee can't be
+	// forwarded at all.
+	policy := &ChannelPolicy{BaseFee: 1000}
+	if fwdAmt := policy.ForwardAmount(1000); fwdAmt != 0 {
+		t.Fatalf("expected nothing to be forwarded, instead "+
+			"forwarding %v", fwdAmt)
+	}
+}
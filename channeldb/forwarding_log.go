@@ -0,0 +1,217 @@
+package channeldb
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	// forwardingLogBucket is the name of the top-level bucket which
+	// houses a record of each HTLC forwarded by the htlc switch. Each
+	// event is keyed by the time the forward was completed, in
+	// nanoseconds since the unix epoch, followed by a sequence number
+	// which distinguishes events completed at the same instant. As a
+	// result, iterating over the bucket yields the events in the order
+	// they were completed.
+	forwardingLogBucket = []byte("forwarding-log")
+)
+
+const (
+	// forwardingEventKeySize is the size of the key of each event within
+	// the forwarding log: an 8-byte timestamp followed by an 8-byte
+	// sequence number.
+	forwardingEventKeySize = 16
+)
+
+// ForwardingEvent is a record of an HTLC which was forwarded from one of our
+// channels to another, then settled by the remote peer. The difference
+// between the incoming and outgoing amounts is the fee we earned.
+type ForwardingEvent struct {
+	// Timestamp is the time the forward was completed.
+	Timestamp time.Time
+
+	// IncomingChanPoint is the channel point of the channel the HTLC was
+	// received over.
+	IncomingChanPoint wire.OutPoint
+
+	// OutgoingChanPoint is the channel point of the channel the HTLC was
+	// forwarded over.
+	OutgoingChanPoint wire.OutPoint
+
+	// AmtIn is the amount of the incoming HTLC.
+	AmtIn btcutil.Amount
+
+	// AmtOut is the amount of the outgoing HTLC.
+	AmtOut btcutil.Amount
+}
+
+// Fee returns the fee earned by forwarding the HTLC.
+func (f *ForwardingEvent) Fee() btcutil.Amount {
+	return f.AmtIn - f.AmtOut
+}
+
+// ForwardingEventQuery describes a query of the forwarding log. Only events
+// completed within the time range [StartTime, EndTime] are returned. As the
+// number of events within a time range may be large, queries are paginated:
+// IndexOffset events within the range are skipped, and at most NumMaxEvents
+// events are returned.
+type ForwardingEventQuery struct {
+	// StartTime is the beginning of the time range of the query.
+	StartTime time.Time
+
+	// EndTime is the end of the time range of the query.
+	EndTime time.Time
+
+	// IndexOffset is the number of events within the time range to skip.
+	IndexOffset uint32
+
+	// NumMaxEvents is the maximum number of events to return.
+	NumMaxEvents uint32
+}
+
+// ForwardingLogTimeSlice is the response to a query of the forwarding log.
+type ForwardingLogTimeSlice struct {
+	ForwardingEventQuery
+
+	// ForwardingEvents is the set of events matching the query, ordered
+	// by the time they were completed.
+	ForwardingEvents []*ForwardingEvent
+
+	// LastIndexOffset is the index offset of the event following the
+	// last event returned. This should be used as the IndexOffset of the
+	// query for the next page of events.
+	LastIndexOffset uint32
+}
+
+// AddForwardingEvent records the passed forwarding event within the
+// forwarding log.
+func (d *DB) AddForwardingEvent(event *ForwardingEvent) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		logBucket, err := tx.CreateBucketIfNotExists(forwardingLogBucket)
+		if err != nil {
+			return err
+		}
+
+		seqNum, err := logBucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		var k [forwardingEventKeySize]byte
+		byteOrder.PutUint64(k[:8], uint64(event.Timestamp.UnixNano()))
+		byteOrder.PutUint64(k[8:], seqNum)
+
+		var b bytes.Buffer
+		if err := serializeForwardingEvent(&b, event); err != nil {
+			return err
+		}
+
+		return logBucket.Put(k[:], b.Bytes())
+	})
+}
+
+// QueryForwardingLog returns the page of forwarding events described by the
+// passed query. In the case that no events match the query, a time slice with
+// no events is returned.
+func (d *DB) QueryForwardingLog(q ForwardingEventQuery) (*ForwardingLogTimeSlice,
+	error) {
+
+	resp := &ForwardingLogTimeSlice{
+		ForwardingEventQuery: q,
+		LastIndexOffset:      q.IndexOffset,
+	}
+
+	var startKey, endKey [8]byte
+	byteOrder.PutUint64(startKey[:], uint64(q.StartTime.UnixNano()))
+	byteOrder.PutUint64(endKey[:], uint64(q.EndTime.UnixNano()))
+
+	err := d.store.View(func(tx *bolt.Tx) error {
+		logBucket := tx.Bucket(forwardingLogBucket)
+		if logBucket == nil {
+			return nil
+		}
+
+		// Seek to the first event completed at, or after, the start
+		// of the time range, then scan forwards until we pass the end
+		// of the range, or have collected enough events.
+		var numSkipped uint32
+		c := logBucket.Cursor()
+		for k, v := c.Seek(startKey[:]); k != nil; k, v = c.Next() {
+			if bytes.Compare(k[:8], endKey[:]) > 0 {
+				break
+			}
+			if uint32(len(resp.ForwardingEvents)) >= q.NumMaxEvents {
+				break
+			}
+
+			if numSkipped < q.IndexOffset {
+				numSkipped++
+				continue
+			}
+
+			event, err := deserializeForwardingEvent(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			event.Timestamp = time.Unix(0, int64(byteOrder.Uint64(k[:8])))
+
+			resp.ForwardingEvents = append(resp.ForwardingEvents, event)
+			resp.LastIndexOffset++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func serializeForwardingEvent(w io.Writer, f *ForwardingEvent) error {
+	if err := writeOutpoint(w, &f.IncomingChanPoint); err != nil {
+		return err
+	}
+	if err := writeOutpoint(w, &f.OutgoingChanPoint); err != nil {
+		return err
+	}
+
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], uint64(f.AmtIn))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(f.AmtOut))
+	_, err := w.Write(scratch[:])
+	return err
+}
+
+func deserializeForwardingEvent(r io.Reader) (*ForwardingEvent, error) {
+	f := &ForwardingEvent{}
+
+	if err := readOutpoint(r, &f.IncomingChanPoint); err != nil {
+		return nil, err
+	}
+	if err := readOutpoint(r, &f.OutgoingChanPoint); err != nil {
+		return nil, err
+	}
+
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	f.AmtIn = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	f.AmtOut = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	return f, nil
+}
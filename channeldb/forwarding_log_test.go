@@ -0,0 +1,97 @@
+package channeldb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+func TestForwardingLogQuery(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	// Querying an empty log should return no events.
+	start := time.Unix(1000, 0)
+	query := ForwardingEventQuery{
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+		NumMaxEvents: 100,
+	}
+	timeSlice, err := db.QueryForwardingLog(query)
+	if err != nil {
+		t.Fatalf("unable to query forwarding log: %v", err)
+	}
+	if len(timeSlice.ForwardingEvents) != 0 {
+		t.Fatalf("expected no events, instead have %v",
+			len(timeSlice.ForwardingEvents))
+	}
+
+	// Add a series of events, one per minute, for an hour.
+	const numEvents = 60
+	events := make([]*ForwardingEvent, numEvents)
+	for i := 0; i < numEvents; i++ {
+		events[i] = &ForwardingEvent{
+			Timestamp:         start.Add(time.Duration(i) * time.Minute),
+			IncomingChanPoint: wire.OutPoint{Hash: key, Index: 0},
+			OutgoingChanPoint: *id,
+			AmtIn:             btcutil.Amount(1000 + i + 1),
+			AmtOut:            btcutil.Amount(1000),
+		}
+		if err := db.AddForwardingEvent(events[i]); err != nil {
+			t.Fatalf("unable to add forwarding event: %v", err)
+		}
+	}
+
+	// A query covering the first ten minutes should return only the
+	// events within that range, inclusive of the end of the range.
+	query.EndTime = start.Add(10 * time.Minute)
+	timeSlice, err = db.QueryForwardingLog(query)
+	if err != nil {
+		t.Fatalf("unable to query forwarding log: %v", err)
+	}
+	if !reflect.DeepEqual(events[:11], timeSlice.ForwardingEvents) {
+		t.Fatalf("events don't match: %v vs %v",
+			spew.Sdump(events[:11]),
+			spew.Sdump(timeSlice.ForwardingEvents))
+	}
+	if timeSlice.LastIndexOffset != 11 {
+		t.Fatalf("expected last index offset of 11, instead got %v",
+			timeSlice.LastIndexOffset)
+	}
+
+	// Paginating through the entire log, a page at a time, should return
+	// each event exactly once, in order.
+	query = ForwardingEventQuery{
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+		NumMaxEvents: 7,
+	}
+	var fetched []*ForwardingEvent
+	for {
+		timeSlice, err := db.QueryForwardingLog(query)
+		if err != nil {
+			t.Fatalf("unable to query forwarding log: %v", err)
+		}
+		if len(timeSlice.ForwardingEvents) == 0 {
+			break
+		}
+
+		fetched = append(fetched, timeSlice.ForwardingEvents...)
+		query.IndexOffset = timeSlice.LastIndexOffset
+	}
+	if !reflect.DeepEqual(events, fetched) {
+		t.Fatalf("events don't match: %v vs %v", spew.Sdump(events),
+			spew.Sdump(fetched))
+	}
+
+	if fee := fetched[9].Fee(); fee != 10 {
+		t.Fatalf("expected fee of 10, instead got %v", fee)
+	}
+}
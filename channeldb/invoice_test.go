@@ -1,13 +1,17 @@
 package channeldb
 
 import (
+	"bytes"
 	"crypto/rand"
+	"io"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/boltdb/bolt"
 	"github.com/btcsuite/fastsha256"
 	"github.com/davecgh/go-spew/spew"
+	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
 )
 
@@ -20,6 +24,7 @@ func randInvoice(value btcutil.Amount) (*Invoice, error) {
 
 	i := &Invoice{
 		CreationDate: time.Now(),
+		Expiry:       time.Hour,
 		Terms: ContractTerm{
 			PaymentPreimage: pre,
 			Value:           value,
@@ -42,6 +47,7 @@ func TestInvoiceWorkflow(t *testing.T) {
 	// below.
 	fakeInvoice := &Invoice{
 		CreationDate: time.Now(),
+		Expiry:       time.Hour,
 	}
 	fakeInvoice.Memo = []byte("memo")
 	fakeInvoice.Receipt = []byte("recipt")
@@ -70,16 +76,21 @@ func TestInvoiceWorkflow(t *testing.T) {
 
 	// Settle the invoice, the versin retreived from the database should
 	// now have the settled bit toggle to true.
-	if err := db.SettleInvoice(paymentHash); err != nil {
+	amtPaid := fakeInvoice.Terms.Value + 500
+	if err := db.SettleInvoice(paymentHash, amtPaid); err != nil {
 		t.Fatalf("unable to settle invoice: %v", err)
 	}
 	dbInvoice2, err := db.LookupInvoice(paymentHash)
 	if err != nil {
 		t.Fatalf("unable to fetch invoice: %v", err)
 	}
-	if !dbInvoice2.Terms.Settled {
+	if dbInvoice2.Terms.State != InvoiceSettled {
 		t.Fatalf("invoice should now be settled but isn't")
 	}
+	if dbInvoice2.AmtPaid != amtPaid {
+		t.Fatalf("expected amount paid of %v, instead got %v",
+			amtPaid, dbInvoice2.AmtPaid)
+	}
 
 	// Attempt to insert generated above again, this should fail as
 	// duplicates are rejected by the processing logic.
@@ -131,3 +142,446 @@ func TestInvoiceWorkflow(t *testing.T) {
 		}
 	}
 }
+
+func TestInvoiceStateTransitions(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	// Create three invoices: one which we'll cancel, one which we'll
+	// allow to expire, and one which will be settled.
+	amt := btcutil.Amount(1000)
+	var hashes [3][32]byte
+	for i := 0; i < len(hashes); i++ {
+		invoice, err := randInvoice(amt)
+		if err != nil {
+			t.Fatalf("unable to create invoice: %v", err)
+		}
+		if err := db.AddInvoice(invoice); err != nil {
+			t.Fatalf("unable to add invoice %v", err)
+		}
+
+		hashes[i] = fastsha256.Sum256(invoice.Terms.PaymentPreimage[:])
+	}
+	cancelHash, expireHash, settleHash := hashes[0], hashes[1], hashes[2]
+
+	assertState := func(hash [32]byte, state InvoiceState) {
+		invoice, err := db.LookupInvoice(hash)
+		if err != nil {
+			t.Fatalf("unable to find invoice: %v", err)
+		}
+		if invoice.Terms.State != state {
+			t.Fatalf("invoice %x should be %v, is instead %v",
+				hash[:], state, invoice.Terms.State)
+		}
+	}
+
+	// Cancel the first invoice, once cancelled it should no longer be
+	// possible to settle it.
+	if err := db.CancelInvoice(cancelHash); err != nil {
+		t.Fatalf("unable to cancel invoice: %v", err)
+	}
+	assertState(cancelHash, InvoiceCancelled)
+	if err := db.SettleInvoice(cancelHash, amt); err != ErrInvoiceCancelled {
+		t.Fatalf("settling a cancelled invoice should fail, "+
+			"instead: %v", err)
+	}
+
+	// Settle the last invoice, a settled invoice can't be cancelled.
+	if err := db.SettleInvoice(settleHash, amt); err != nil {
+		t.Fatalf("unable to settle invoice: %v", err)
+	}
+	if err := db.CancelInvoice(settleHash); err != ErrInvoiceAlreadySettled {
+		t.Fatalf("cancelling a settled invoice should fail, "+
+			"instead: %v", err)
+	}
+
+	// Expire all invoices as of two hours from now, only the single
+	// remaining open invoice should be expired.
+	expired, err := db.ExpireInvoices(time.Now().Add(time.Hour * 2))
+	if err != nil {
+		t.Fatalf("unable to expire invoices: %v", err)
+	}
+	if len(expired) != 1 || expired[0] != expireHash {
+		t.Fatalf("expected invoice %x to be expired, instead "+
+			"expired: %v", expireHash[:], spew.Sdump(expired))
+	}
+	assertState(expireHash, InvoiceExpired)
+	assertState(cancelHash, InvoiceCancelled)
+	assertState(settleHash, InvoiceSettled)
+
+	if err := db.SettleInvoice(expireHash, amt); err != ErrInvoiceExpired {
+		t.Fatalf("settling an expired invoice should fail, "+
+			"instead: %v", err)
+	}
+
+	// Only open invoices should be returned when fetching pending
+	// invoices, of which there are none left.
+	pending, err := db.FetchAllInvoices(true)
+	if err != nil {
+		t.Fatalf("unable to fetch invoices: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending invoices, instead have %v",
+			len(pending))
+	}
+}
+
+func TestInvoiceAddSettleIndex(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	// Add several invoices, each should be assigned the next add index in
+	// sequence.
+	const numInvoices = 5
+	var hashes [numInvoices][32]byte
+	for i := 0; i < numInvoices; i++ {
+		invoice, err := randInvoice(btcutil.Amount(1000))
+		if err != nil {
+			t.Fatalf("unable to create invoice: %v", err)
+		}
+		if err := db.AddInvoice(invoice); err != nil {
+			t.Fatalf("unable to add invoice %v", err)
+		}
+		if invoice.AddIndex != uint64(i+1) {
+			t.Fatalf("expected add index %v, instead got %v", i+1,
+				invoice.AddIndex)
+		}
+
+		hashes[i] = fastsha256.Sum256(invoice.Terms.PaymentPreimage[:])
+	}
+
+	// Only the invoices added after the cursor should be returned.
+	added, err := db.InvoicesAddedSince(2)
+	if err != nil {
+		t.Fatalf("unable to fetch added invoices: %v", err)
+	}
+	if len(added) != numInvoices-2 {
+		t.Fatalf("expected %v invoices, instead have %v",
+			numInvoices-2, len(added))
+	}
+	for i, invoice := range added {
+		if invoice.AddIndex != uint64(i+3) {
+			t.Fatalf("expected add index %v, instead got %v", i+3,
+				invoice.AddIndex)
+		}
+	}
+
+	// Settle the invoices in reverse order, the settle indexes should
+	// reflect the order of settlement rather than insertion.
+	for i := numInvoices - 1; i >= 0; i-- {
+		if err := db.SettleInvoice(hashes[i], 1000); err != nil {
+			t.Fatalf("unable to settle invoice: %v", err)
+		}
+	}
+	settled, err := db.InvoicesSettledSince(0)
+	if err != nil {
+		t.Fatalf("unable to fetch settled invoices: %v", err)
+	}
+	if len(settled) != numInvoices {
+		t.Fatalf("expected %v invoices, instead have %v",
+			numInvoices, len(settled))
+	}
+	for i, invoice := range settled {
+		if invoice.SettleIndex != uint64(i+1) {
+			t.Fatalf("expected settle index %v, instead got %v",
+				i+1, invoice.SettleIndex)
+		}
+		if invoice.AddIndex != uint64(numInvoices-i) {
+			t.Fatalf("expected add index %v, instead got %v",
+				numInvoices-i, invoice.AddIndex)
+		}
+	}
+
+	// Settling an invoice a second time shouldn't assign it a new settle
+	// index.
+	if err := db.SettleInvoice(hashes[0], 1000); err != nil {
+		t.Fatalf("unable to settle invoice: %v", err)
+	}
+	settled, err = db.InvoicesSettledSince(numInvoices)
+	if err != nil {
+		t.Fatalf("unable to fetch settled invoices: %v", err)
+	}
+	if len(settled) != 0 {
+		t.Fatalf("expected no new settled invoices, instead have %v",
+			len(settled))
+	}
+}
+
+func TestInvoiceRecordRejectedPayment(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	amt := btcutil.Amount(1000)
+	invoice, err := randInvoice(amt)
+	if err != nil {
+		t.Fatalf("unable to create invoice: %v", err)
+	}
+	if err := db.AddInvoice(invoice); err != nil {
+		t.Fatalf("unable to add invoice %v", err)
+	}
+	paymentHash := fastsha256.Sum256(invoice.Terms.PaymentPreimage[:])
+
+	// Record an underpayment against the invoice, the rejected amount
+	// should be stored while the invoice itself remains open.
+	if err := db.RecordRejectedPayment(paymentHash, amt-1); err != nil {
+		t.Fatalf("unable to record rejected payment: %v", err)
+	}
+	dbInvoice, err := db.LookupInvoice(paymentHash)
+	if err != nil {
+		t.Fatalf("unable to find invoice: %v", err)
+	}
+	if dbInvoice.AmtRejected != amt-1 {
+		t.Fatalf("expected rejected amount of %v, instead got %v",
+			amt-1, dbInvoice.AmtRejected)
+	}
+	if dbInvoice.Terms.State != InvoiceOpen {
+		t.Fatalf("invoice should be open, is instead %v",
+			dbInvoice.Terms.State)
+	}
+	if dbInvoice.AmtPaid != 0 {
+		t.Fatalf("unsettled invoice shouldn't have an amount paid, "+
+			"instead has %v", dbInvoice.AmtPaid)
+	}
+
+	// A subsequent payment of the full amount should still be able to
+	// settle the invoice, with the prior rejection left on record.
+	if err := db.SettleInvoice(paymentHash, amt); err != nil {
+		t.Fatalf("unable to settle invoice: %v", err)
+	}
+	dbInvoice, err = db.LookupInvoice(paymentHash)
+	if err != nil {
+		t.Fatalf("unable to find invoice: %v", err)
+	}
+	if dbInvoice.AmtPaid != amt {
+		t.Fatalf("expected amount paid of %v, instead got %v", amt,
+			dbInvoice.AmtPaid)
+	}
+	if dbInvoice.AmtRejected != amt-1 {
+		t.Fatalf("expected rejected amount of %v, instead got %v",
+			amt-1, dbInvoice.AmtRejected)
+	}
+
+	// Recording a payment against an unknown invoice should fail.
+	var unknownHash [32]byte
+	err = db.RecordRejectedPayment(unknownHash, amt)
+	if err != ErrInvoiceNotFound {
+		t.Fatalf("expected ErrInvoiceNotFound, instead got: %v", err)
+	}
+}
+
+// serializeLegacyInvoice encodes the passed invoice using the legacy invoice
+// encoding.
+func serializeLegacyInvoice(w io.Writer, i *Invoice) error {
+	if err := wire.WriteVarBytes(w, 0, i.Memo[:]); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, i.Receipt[:]); err != nil {
+		return err
+	}
+
+	birthBytes, err := i.CreationDate.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, birthBytes); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(i.Terms.PaymentPreimage[:]); err != nil {
+		return err
+	}
+
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], uint64(i.Terms.Value))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	var settleByte [1]byte
+	if i.Terms.State == InvoiceSettled {
+		settleByte[0] = 1
+	}
+	_, err = w.Write(settleByte[:])
+	return err
+}
+
+// putLegacyInvoices writes the passed invoices to the database as they would
+// have been written prior to the invoice encoding being versioned.
+func putLegacyInvoices(db *DB, legacyInvoices []*Invoice) error {
+	return db.store.Update(func(tx *bolt.Tx) error {
+		invoices, err := tx.CreateBucketIfNotExists(invoiceBucket)
+		if err != nil {
+			return err
+		}
+		invoiceIndex, err := invoices.CreateBucketIfNotExists(invoiceIndexBucket)
+		if err != nil {
+			return err
+		}
+
+		for i, invoice := range legacyInvoices {
+			var invoiceKey [4]byte
+			byteOrder.PutUint32(invoiceKey[:], uint32(i))
+
+			var buf bytes.Buffer
+			if err := serializeLegacyInvoice(&buf, invoice); err != nil {
+				return err
+			}
+			if err := invoices.Put(invoiceKey[:], buf.Bytes()); err != nil {
+				return err
+			}
+
+			preimage := invoice.Terms.PaymentPreimage[:]
+			paymentHash := fastsha256.Sum256(preimage)
+			err := invoiceIndex.Put(paymentHash[:], invoiceKey[:])
+			if err != nil {
+				return err
+			}
+		}
+
+		var scratch [4]byte
+		byteOrder.PutUint32(scratch[:], uint32(len(legacyInvoices)))
+		return invoiceIndex.Put(numInvoicesKey, scratch[:])
+	})
+}
+
+func TestMigrateLegacyInvoices(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	// Write several legacy invoices to the database, settling every
+	// other one.
+	const numInvoices = 4
+	legacyInvoices := make([]*Invoice, numInvoices)
+	for i := 0; i < numInvoices; i++ {
+		invoice, err := randInvoice(btcutil.Amount(1000 * (i + 1)))
+		if err != nil {
+			t.Fatalf("unable to create invoice: %v", err)
+		}
+		invoice.Expiry = 0
+		if i%2 == 1 {
+			invoice.Terms.State = InvoiceSettled
+		}
+
+		legacyInvoices[i] = invoice
+	}
+	if err := putLegacyInvoices(db, legacyInvoices); err != nil {
+		t.Fatalf("unable to write legacy invoices: %v", err)
+	}
+
+	// Migrate the legacy invoices, running the migration a second time
+	// should be a no-op.
+	for i := 0; i < 2; i++ {
+		if err := db.store.Update(migrateInvoices); err != nil {
+			t.Fatalf("unable to migrate invoices: %v", err)
+		}
+	}
+
+	// Each invoice should now be readable, retaining its legacy fields.
+	for i, legacyInvoice := range legacyInvoices {
+		preimage := legacyInvoice.Terms.PaymentPreimage[:]
+		dbInvoice, err := db.LookupInvoice(fastsha256.Sum256(preimage))
+		if err != nil {
+			t.Fatalf("unable to find invoice: %v", err)
+		}
+
+		if !bytes.Equal(dbInvoice.Memo, legacyInvoice.Memo) {
+			t.Fatalf("invoice #%v has incorrect memo: expected %s, "+
+				"got %s", i, legacyInvoice.Memo, dbInvoice.Memo)
+		}
+		if !dbInvoice.CreationDate.Equal(legacyInvoice.CreationDate) {
+			t.Fatalf("invoice #%v has incorrect creation date: "+
+				"expected %v, got %v", i,
+				legacyInvoice.CreationDate, dbInvoice.CreationDate)
+		}
+		if dbInvoice.Terms.Value != legacyInvoice.Terms.Value {
+			t.Fatalf("invoice #%v has incorrect value: expected %v, "+
+				"got %v", i, legacyInvoice.Terms.Value,
+				dbInvoice.Terms.Value)
+		}
+		if dbInvoice.Terms.State != legacyInvoice.Terms.State {
+			t.Fatalf("invoice #%v has incorrect state: expected %v, "+
+				"got %v", i, legacyInvoice.Terms.State,
+				dbInvoice.Terms.State)
+		}
+		if dbInvoice.Expiry != 0 {
+			t.Fatalf("invoice #%v shouldn't expire, instead has "+
+				"expiry %v", i, dbInvoice.Expiry)
+		}
+		if dbInvoice.AddIndex != uint64(i+1) {
+			t.Fatalf("invoice #%v has incorrect add index: "+
+				"expected %v, got %v", i, i+1, dbInvoice.AddIndex)
+		}
+
+		// Settled invoices should be considered paid in full, while
+		// no payments should have been rejected.
+		var amtPaid btcutil.Amount
+		if legacyInvoice.Terms.State == InvoiceSettled {
+			amtPaid = legacyInvoice.Terms.Value
+		}
+		if dbInvoice.AmtPaid != amtPaid {
+			t.Fatalf("invoice #%v has incorrect amount paid: "+
+				"expected %v, got %v", i, amtPaid,
+				dbInvoice.AmtPaid)
+		}
+		if dbInvoice.AmtRejected != 0 {
+			t.Fatalf("invoice #%v shouldn't have a rejected "+
+				"amount, instead has %v", i,
+				dbInvoice.AmtRejected)
+		}
+	}
+
+	// The settled invoices should have been assigned settle indexes in
+	// the order they were added.
+	settled, err := db.InvoicesSettledSince(0)
+	if err != nil {
+		t.Fatalf("unable to fetch settled invoices: %v", err)
+	}
+	if len(settled) != numInvoices/2 {
+		t.Fatalf("expected %v settled invoices, instead have %v",
+			numInvoices/2, len(settled))
+	}
+	for i, invoice := range settled {
+		if invoice.SettleIndex != uint64(i+1) {
+			t.Fatalf("expected settle index %v, instead got %v",
+				i+1, invoice.SettleIndex)
+		}
+		if invoice.AddIndex != uint64(2*i+2) {
+			t.Fatalf("expected add index %v, instead got %v",
+				2*i+2, invoice.AddIndex)
+		}
+	}
+
+	// Invoices added after the migration should be stored alongside the
+	// migrated invoices.
+	invoice, err := randInvoice(btcutil.Amount(1000))
+	if err != nil {
+		t.Fatalf("unable to create invoice: %v", err)
+	}
+	if err := db.AddInvoice(invoice); err != nil {
+		t.Fatalf("unable to add invoice %v", err)
+	}
+	if invoice.AddIndex != numInvoices+1 {
+		t.Fatalf("expected add index %v, instead got %v",
+			numInvoices+1, invoice.AddIndex)
+	}
+	dbInvoices, err := db.FetchAllInvoices(false)
+	if err != nil {
+		t.Fatalf("unable to fetch invoices: %v", err)
+	}
+	if len(dbInvoices) != numInvoices+1 {
+		t.Fatalf("expected %v invoices, instead have %v",
+			numInvoices+1, len(dbInvoices))
+	}
+}
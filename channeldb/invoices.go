@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/boltdb/bolt"
@@ -33,6 +34,23 @@ var (
 	// stored within the invoiceIndexBucket. Within the invoiceBucket
 	// invoices are uniquely identified by the invoice ID.
 	numInvoicesKey = []byte("nik")
+
+	// addIndexKey is the name of the key within the invoiceIndexBucket
+	// which houses the most recently assigned add index. Each invoice is
+	// assigned the next add index upon insertion.
+	addIndexKey = []byte("aik")
+
+	// settleIndexKey is the name of the key within the invoiceIndexBucket
+	// which houses the most recently assigned settle index. Each invoice
+	// is assigned the next settle index once it has been settled.
+	settleIndexKey = []byte("sik")
+
+	// invoiceVersionKey is the name of the key within the
+	// invoiceIndexBucket which houses the version of the encoding used
+	// for all invoices within the invoiceBucket. Invoices written before
+	// the encoding was versioned lack this key, and are migrated to the
+	// current version when the database is opened.
+	invoiceVersionKey = []byte("ivk")
 )
 
 const (
@@ -43,8 +61,54 @@ const (
 	// MaxReceiptSize is the maximum size of the payment receipt stored
 	// within the database along side incoming/outgoing invoices.
 	MaxReceiptSize = 1024
+
+	// legacyInvoiceVersion is the version of the original invoice
+	// encoding, which only records whether the invoice has been settled.
+	legacyInvoiceVersion = 0
+
+	// invoiceVersion is the version of the invoice encoding produced by
+	// serializeInvoice.
+	invoiceVersion = 1
 )
 
+// InvoiceState describes the current state of an invoice within its life
+// cycle. All invoices begin in the InvoiceOpen state, from which they may
+// either be settled, cancelled, or expire.
+type InvoiceState uint8
+
+const (
+	// InvoiceOpen is the state of an invoice which is still able to
+	// accept payment.
+	InvoiceOpen InvoiceState = 0
+
+	// InvoiceSettled is the state of an invoice which has been fully paid.
+	InvoiceSettled InvoiceState = 1
+
+	// InvoiceCancelled is the state of an invoice which has been
+	// cancelled by its creator, and will no longer accept payment.
+	InvoiceCancelled InvoiceState = 2
+
+	// InvoiceExpired is the state of an invoice which wasn't paid before
+	// its expiry, and will no longer accept payment.
+	InvoiceExpired InvoiceState = 3
+)
+
+// String returns a human readable version of the invoice state.
+func (s InvoiceState) String() string {
+	switch s {
+	case InvoiceOpen:
+		return "Open"
+	case InvoiceSettled:
+		return "Settled"
+	case InvoiceCancelled:
+		return "Cancelled"
+	case InvoiceExpired:
+		return "Expired"
+	default:
+		return "Unknown"
+	}
+}
+
 // ContractTerm is a companion struct to the Invoice struct. This struct houses
 // the necessary conditions required before the invoice can be considered fully
 // settled by the payee.
@@ -58,9 +122,9 @@ type ContractTerm struct {
 	// satisfied by the above preimage.
 	Value btcutil.Amount
 
-	// Settled indicates if this particular contract term has been fully
-	// settled by the payer.
-	Settled bool
+	// State is the current state of the invoice. Only invoices in the
+	// InvoiceOpen state are able to accept payment.
+	State InvoiceState
 }
 
 // Invoice is a payment invoice generated by a payee in order to request
@@ -89,15 +153,50 @@ type Invoice struct {
 	// CreationDate is the exact time the invoice was created.
 	CreationDate time.Time
 
+	// Expiry is the duration after the creation date, after which the
+	// invoice should no longer be paid. An expiry of zero indicates the
+	// invoice never expires.
+	Expiry time.Duration
+
+	// AddIndex is a monotonically increasing index assigned to the
+	// invoice upon insertion. Together with the SettleIndex, this allows
+	// clients to resume an invoice subscription from the last event
+	// they've seen.
+	AddIndex uint64
+
+	// SettleIndex is a monotonically increasing index assigned to the
+	// invoice once it has been settled. Unsettled invoices have a settle
+	// index of zero.
+	SettleIndex uint64
+
+	// AmtPaid is the total amount of the HTLC's which settled the
+	// invoice. As payments exceeding the invoice's value may be accepted,
+	// this may be greater than the value of the invoice.
+	AmtPaid btcutil.Amount
+
+	// AmtRejected is the amount of the most recent payment to the invoice
+	// which was rejected for failing to meet its terms, e.g. by paying
+	// less than the invoice's value.
+	AmtRejected btcutil.Amount
+
 	// Terms are the contractual payment terms of the invoice. Once
 	// all the terms have been satisfied by the payer, then the invoice can
-	// be considered fully fulfilled.
-	//
-	// TODO(roasbeef): later allow for multiple terms to fulfill the final
-	// invoice: payment fragmentation, etc.
+	// be considered fully fulfilled. The terms may be satisfied by a
+	// single HTLC, or by several HTLC's whose sum covers the value of the
+	// invoice.
 	Terms ContractTerm
 }
 
+// IsExpired returns true if the invoice's expiry has elapsed as of the passed
+// time.
+func (i *Invoice) IsExpired(now time.Time) bool {
+	if i.Expiry == 0 {
+		return false
+	}
+
+	return now.After(i.CreationDate.Add(i.Expiry))
+}
+
 // AddInvoice inserts the targeted invoice into the database. If the invoice
 // has *any* payment hashes which already exists within the database, then the
 // insertion will be aborted and rejected due to the strict policy banning any
@@ -119,7 +218,7 @@ func (d *DB) AddInvoice(i *Invoice) error {
 			return err
 		}
 
-		invoiceIndex, err := invoices.CreateBucketIfNotExists(invoiceIndexBucket)
+		invoiceIndex, err := createInvoiceIndex(invoices)
 		if err != nil {
 			return err
 		}
@@ -145,6 +244,13 @@ func (d *DB) AddInvoice(i *Invoice) error {
 			invoiceNum = byteOrder.Uint32(invoiceCounter)
 		}
 
+		// Assign the invoice the next add index so subscribers are
+		// able to resume from the last invoice they've seen.
+		i.AddIndex, err = nextInvoiceIndex(invoiceIndex, addIndexKey)
+		if err != nil {
+			return err
+		}
+
 		return putInvoice(invoices, invoiceIndex, i, invoiceNum)
 	})
 }
@@ -192,8 +298,8 @@ func (d *DB) LookupInvoice(paymentHash [32]byte) (*Invoice, error) {
 }
 
 // FetchAllInvoices returns all invoices currently stored within the database.
-// If the pendingOnly param is true, then only unsettled invoices will be
-// returned, skipping all invoices that are fully settled.
+// If the pendingOnly param is true, then only open invoices will be returned,
+// skipping all invoices that are settled, cancelled, or expired.
 func (d *DB) FetchAllInvoices(pendingOnly bool) ([]*Invoice, error) {
 	var invoices []*Invoice
 
@@ -217,7 +323,7 @@ func (d *DB) FetchAllInvoices(pendingOnly bool) ([]*Invoice, error) {
 				return err
 			}
 
-			if pendingOnly && invoice.Terms.Settled {
+			if pendingOnly && invoice.Terms.State != InvoiceOpen {
 				return nil
 			}
 
@@ -233,17 +339,200 @@ func (d *DB) FetchAllInvoices(pendingOnly bool) ([]*Invoice, error) {
 	return invoices, nil
 }
 
+// InvoicesAddedSince returns all invoices with an add index greater than the
+// passed index, in the order they were added.
+func (d *DB) InvoicesAddedSince(sinceAddIndex uint64) ([]*Invoice, error) {
+	return d.filterInvoices(func(i *Invoice) bool {
+		return i.AddIndex > sinceAddIndex
+	})
+}
+
+// InvoicesSettledSince returns all invoices with a settle index greater than
+// the passed index, in the order they were settled.
+func (d *DB) InvoicesSettledSince(sinceSettleIndex uint64) ([]*Invoice, error) {
+	settled, err := d.filterInvoices(func(i *Invoice) bool {
+		return i.SettleIndex > sinceSettleIndex
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(invoicesBySettleIndex(settled))
+
+	return settled, nil
+}
+
+// filterInvoices returns all invoices for which the passed predicate returns
+// true, in the order they were added.
+func (d *DB) filterInvoices(include func(*Invoice) bool) ([]*Invoice, error) {
+	var invoices []*Invoice
+	err := d.store.View(func(tx *bolt.Tx) error {
+		invoiceB := tx.Bucket(invoiceBucket)
+		if invoiceB == nil {
+			return nil
+		}
+
+		return invoiceB.ForEach(func(k, v []byte) error {
+			if v == nil {
+				return nil
+			}
+
+			invoice, err := deserializeInvoice(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			if include(invoice) {
+				invoices = append(invoices, invoice)
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// invoicesBySettleIndex implements sort.Interface, sorting invoices in
+// ascending order of their settle index.
+type invoicesBySettleIndex []*Invoice
+
+func (s invoicesBySettleIndex) Len() int      { return len(s) }
+func (s invoicesBySettleIndex) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s invoicesBySettleIndex) Less(i, j int) bool {
+	return s[i].SettleIndex < s[j].SettleIndex
+}
+
 // SettleInvoice attempts to mark an invoice corresponding to the passed
 // payment hash as fully settled. If an invoice matching the passed payment
 // hash doesn't existing within the database, then the action will fail with a
-// "not found" error.
-func (d *DB) SettleInvoice(paymentHash [32]byte) error {
+// "not found" error. Invoices which have been cancelled or have expired can't
+// be settled. The passed amount is recorded as the amount paid to the
+// invoice.
+func (d *DB) SettleInvoice(paymentHash [32]byte, amtPaid btcutil.Amount) error {
+	return d.updateInvoice(paymentHash, func(invoices,
+		invoiceIndex *bolt.Bucket, invoiceNum []byte) error {
+
+		return setInvoiceState(invoices, invoiceIndex, invoiceNum,
+			InvoiceSettled, amtPaid)
+	})
+}
+
+// CancelInvoice attempts to mark the invoice corresponding to the passed
+// payment hash as cancelled, after which it'll no longer accept payment. If
+// the invoice has already been settled, then ErrInvoiceAlreadySettled is
+// returned.
+func (d *DB) CancelInvoice(paymentHash [32]byte) error {
+	return d.updateInvoice(paymentHash, func(invoices,
+		invoiceIndex *bolt.Bucket, invoiceNum []byte) error {
+
+		return setInvoiceState(invoices, invoiceIndex, invoiceNum,
+			InvoiceCancelled, 0)
+	})
+}
+
+// RecordRejectedPayment records the amount of an HTLC paying to the invoice
+// identified by the passed payment hash which was rejected for failing to
+// meet the invoice's terms. The state of the invoice is left unchanged.
+func (d *DB) RecordRejectedPayment(paymentHash [32]byte,
+	amt btcutil.Amount) error {
+
+	return d.updateInvoice(paymentHash, func(invoices,
+		invoiceIndex *bolt.Bucket, invoiceNum []byte) error {
+
+		invoice, err := fetchInvoice(invoiceNum, invoices)
+		if err != nil {
+			return err
+		}
+		invoice.AmtRejected = amt
+
+		var buf bytes.Buffer
+		if err := serializeInvoice(&buf, invoice); err != nil {
+			return err
+		}
+
+		return invoices.Put(invoiceNum[:], buf.Bytes())
+	})
+}
+
+// ExpireInvoices transitions all open invoices whose expiry has elapsed as of
+// the passed time to the InvoiceExpired state. The payment hashes of all
+// newly expired invoices are returned.
+func (d *DB) ExpireInvoices(now time.Time) ([][32]byte, error) {
+	var expired [][32]byte
+	err := d.store.Update(func(tx *bolt.Tx) error {
+		invoices := tx.Bucket(invoiceBucket)
+		if invoices == nil {
+			return nil
+		}
+
+		// First gather all the invoices which have expired, as the
+		// bucket can't be modified while it's being iterated over.
+		expiredInvoices := make(map[uint32]*Invoice)
+		err := invoices.ForEach(func(k, v []byte) error {
+			if v == nil {
+				return nil
+			}
+
+			invoice, err := deserializeInvoice(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			if invoice.Terms.State == InvoiceOpen &&
+				invoice.IsExpired(now) {
+
+				expiredInvoices[byteOrder.Uint32(k)] = invoice
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for invoiceNum, invoice := range expiredInvoices {
+			invoice.Terms.State = InvoiceExpired
+
+			var invoiceKey [4]byte
+			byteOrder.PutUint32(invoiceKey[:], invoiceNum)
+
+			var buf bytes.Buffer
+			if err := serializeInvoice(&buf, invoice); err != nil {
+				return err
+			}
+			if err := invoices.Put(invoiceKey[:], buf.Bytes()); err != nil {
+				return err
+			}
+
+			preimage := invoice.Terms.PaymentPreimage[:]
+			expired = append(expired, fastsha256.Sum256(preimage))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return expired, nil
+}
+
+// updateInvoice locates the invoice identified by the passed payment hash,
+// then executes the passed update closure against it within a single
+// database transaction.
+func (d *DB) updateInvoice(paymentHash [32]byte, update func(invoices,
+	invoiceIndex *bolt.Bucket, invoiceNum []byte) error) error {
+
 	return d.store.Update(func(tx *bolt.Tx) error {
 		invoices, err := tx.CreateBucketIfNotExists(invoiceBucket)
 		if err != nil {
 			return err
 		}
-		invoiceIndex, err := invoices.CreateBucketIfNotExists(invoiceIndexBucket)
+		invoiceIndex, err := createInvoiceIndex(invoices)
 		if err != nil {
 			return err
 		}
@@ -255,8 +544,141 @@ func (d *DB) SettleInvoice(paymentHash [32]byte) error {
 			return ErrInvoiceNotFound
 		}
 
-		return settleInvoice(invoices, invoiceNum)
+		return update(invoices, invoiceIndex, invoiceNum)
+	})
+}
+
+// createInvoiceIndex returns the invoice index bucket nested within the passed
+// invoice bucket, creating it if it doesn't yet exist. A newly created index
+// is stamped with the current invoice encoding version.
+func createInvoiceIndex(invoices *bolt.Bucket) (*bolt.Bucket, error) {
+	if invoiceIndex := invoices.Bucket(invoiceIndexBucket); invoiceIndex != nil {
+		return invoiceIndex, nil
+	}
+
+	invoiceIndex, err := invoices.CreateBucket(invoiceIndexBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var version [2]byte
+	byteOrder.PutUint16(version[:], invoiceVersion)
+	if err := invoiceIndex.Put(invoiceVersionKey, version[:]); err != nil {
+		return nil, err
+	}
+
+	return invoiceIndex, nil
+}
+
+// migrateInvoices re-encodes all invoices written using a prior version of
+// the invoice encoding using the current version. Fields which weren't
+// recorded by the prior encoding are assigned their default values.
+func migrateInvoices(tx *bolt.Tx) error {
+	invoices := tx.Bucket(invoiceBucket)
+	if invoices == nil {
+		return nil
+	}
+
+	// If the invoice index doesn't yet exist, then no invoices have been
+	// written, and the index will be created with the current version.
+	invoiceIndex := invoices.Bucket(invoiceIndexBucket)
+	if invoiceIndex == nil {
+		return nil
+	}
+
+	version := uint16(legacyInvoiceVersion)
+	if versionBytes := invoiceIndex.Get(invoiceVersionKey); versionBytes != nil {
+		version = byteOrder.Uint16(versionBytes)
+	}
+	switch {
+	case version == invoiceVersion:
+		return nil
+	case version > invoiceVersion:
+		return fmt.Errorf("unknown invoice encoding version: %v",
+			version)
+	}
+
+	// First gather all the legacy invoices, as the bucket can't be
+	// modified while it's being iterated over. As the invoice keys are
+	// big-endian, the invoices are gathered in the order they were added.
+	var (
+		invoiceKeys    [][]byte
+		legacyInvoices []*Invoice
+	)
+	err := invoices.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return nil
+		}
+
+		invoice, err := deserializeLegacyInvoice(bytes.NewReader(v))
+		if err != nil {
+			return err
+		}
+
+		invoiceKeys = append(invoiceKeys, append([]byte(nil), k...))
+		legacyInvoices = append(legacyInvoices, invoice)
+
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	for i, invoice := range legacyInvoices {
+		// Legacy invoices weren't assigned add or settle indexes, so
+		// they're assigned now in the order they were added. The order
+		// in which the settled invoices were settled is unknown.
+		invoice.AddIndex, err = nextInvoiceIndex(invoiceIndex, addIndexKey)
+		if err != nil {
+			return err
+		}
+		if invoice.Terms.State == InvoiceSettled {
+			invoice.SettleIndex, err = nextInvoiceIndex(invoiceIndex,
+				settleIndexKey)
+			if err != nil {
+				return err
+			}
+
+			// The amount paid to settled legacy invoices wasn't
+			// recorded, though at least the invoice's value must
+			// have been paid.
+			invoice.AmtPaid = invoice.Terms.Value
+		}
+
+		var buf bytes.Buffer
+		if err := serializeInvoice(&buf, invoice); err != nil {
+			return err
+		}
+		if err := invoices.Put(invoiceKeys[i], buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Migrated %v invoices to encoding version %v",
+		len(legacyInvoices), invoiceVersion)
+
+	var versionBytes [2]byte
+	byteOrder.PutUint16(versionBytes[:], invoiceVersion)
+	return invoiceIndex.Put(invoiceVersionKey, versionBytes[:])
+}
+
+// nextInvoiceIndex increments the counter stored under the passed key within
+// the invoice index bucket, returning the new value. The first index assigned
+// is 1, leaving 0 free to denote an unassigned index.
+func nextInvoiceIndex(invoiceIndex *bolt.Bucket, key []byte) (uint64, error) {
+	var index uint64
+	if indexBytes := invoiceIndex.Get(key); indexBytes != nil {
+		index = byteOrder.Uint64(indexBytes)
+	}
+	index++
+
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], index)
+	if err := invoiceIndex.Put(key, scratch[:]); err != nil {
+		return 0, err
+	}
+
+	return index, nil
 }
 
 func putInvoice(invoices *bolt.Bucket, invoiceIndex *bolt.Bucket,
@@ -309,21 +731,41 @@ func serializeInvoice(w io.Writer, i *Invoice) error {
 		return err
 	}
 
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], uint64(i.Expiry))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
 	if _, err := w.Write(i.Terms.PaymentPreimage[:]); err != nil {
 		return err
 	}
 
-	var scratch [8]byte
 	byteOrder.PutUint64(scratch[:], uint64(i.Terms.Value))
 	if _, err := w.Write(scratch[:]); err != nil {
 		return err
 	}
 
-	var settleByte [1]byte
-	if i.Terms.Settled {
-		settleByte[0] = 1
+	stateByte := [1]byte{byte(i.Terms.State)}
+	if _, err := w.Write(stateByte[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], i.AddIndex)
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	byteOrder.PutUint64(scratch[:], i.SettleIndex)
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(i.AmtPaid))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
 	}
-	if _, err := w.Write(settleByte[:]); err != nil {
+	byteOrder.PutUint64(scratch[:], uint64(i.AmtRejected))
+	if _, err := w.Write(scratch[:]); err != nil {
 		return err
 	}
 
@@ -363,6 +805,71 @@ func deserializeInvoice(r io.Reader) (*Invoice, error) {
 		return nil, err
 	}
 
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	invoice.Expiry = time.Duration(byteOrder.Uint64(scratch[:]))
+
+	if _, err := io.ReadFull(r, invoice.Terms.PaymentPreimage[:]); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	invoice.Terms.Value = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	var stateByte [1]byte
+	if _, err := io.ReadFull(r, stateByte[:]); err != nil {
+		return nil, err
+	}
+	invoice.Terms.State = InvoiceState(stateByte[0])
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	invoice.AddIndex = byteOrder.Uint64(scratch[:])
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	invoice.SettleIndex = byteOrder.Uint64(scratch[:])
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	invoice.AmtPaid = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	invoice.AmtRejected = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	return invoice, nil
+}
+
+// deserializeLegacyInvoice decodes an invoice written using the legacy
+// invoice encoding. Legacy invoices never expire, and are either open or
+// settled.
+func deserializeLegacyInvoice(r io.Reader) (*Invoice, error) {
+	var err error
+	invoice := &Invoice{}
+
+	invoice.Memo, err = wire.ReadVarBytes(r, 0, MaxMemoSize, "")
+	if err != nil {
+		return nil, err
+	}
+	invoice.Receipt, err = wire.ReadVarBytes(r, 0, MaxReceiptSize, "")
+	if err != nil {
+		return nil, err
+	}
+
+	birthBytes, err := wire.ReadVarBytes(r, 0, 300, "birth")
+	if err != nil {
+		return nil, err
+	}
+	if err := invoice.CreationDate.UnmarshalBinary(birthBytes); err != nil {
+		return nil, err
+	}
+
 	if _, err := io.ReadFull(r, invoice.Terms.PaymentPreimage[:]); err != nil {
 		return nil, err
 	}
@@ -377,23 +884,51 @@ func deserializeInvoice(r io.Reader) (*Invoice, error) {
 		return nil, err
 	}
 	if settleByte[0] == 1 {
-		invoice.Terms.Settled = true
+		invoice.Terms.State = InvoiceSettled
 	}
 
 	return invoice, nil
 }
 
-func settleInvoice(invoices *bolt.Bucket, invoiceNum []byte) error {
+func setInvoiceState(invoices, invoiceIndex *bolt.Bucket, invoiceNum []byte,
+	newState InvoiceState, amtPaid btcutil.Amount) error {
+
 	invoice, err := fetchInvoice(invoiceNum, invoices)
 	if err != nil {
 		return err
 	}
 
-	invoice.Terms.Settled = true
+	// Settled invoices are final, and invoices which can no longer accept
+	// payment can't be settled. Repeating a transition is a no-op.
+	switch {
+	case invoice.Terms.State == newState:
+		return nil
+	case invoice.Terms.State == InvoiceSettled:
+		return ErrInvoiceAlreadySettled
+	case newState == InvoiceSettled &&
+		invoice.Terms.State == InvoiceCancelled:
+		return ErrInvoiceCancelled
+	case newState == InvoiceSettled &&
+		invoice.Terms.State == InvoiceExpired:
+		return ErrInvoiceExpired
+	}
+
+	invoice.Terms.State = newState
+
+	// Settled invoices are assigned the next settle index so subscribers
+	// are able to resume from the last settlement they've seen.
+	if newState == InvoiceSettled {
+		invoice.AmtPaid = amtPaid
+		invoice.SettleIndex, err = nextInvoiceIndex(invoiceIndex,
+			settleIndexKey)
+		if err != nil {
+			return err
+		}
+	}
 
 	var buf bytes.Buffer
 	if err := serializeInvoice(&buf, invoice); err != nil {
-		return nil
+		return err
 	}
 
 	return invoices.Put(invoiceNum[:], buf.Bytes())
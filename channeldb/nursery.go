@@ -0,0 +1,92 @@
+package channeldb
+
+import (
+	"bytes"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+)
+
+var (
+	// nurseryBucket is the name of the top-level bucket which houses all
+	// the time-locked outputs currently being incubated by the utxo
+	// nursery. Each output is keyed by its outpoint, and the value is an
+	// opaque blob which is serialized and deserialized by the nursery
+	// itself. Outputs are removed from this bucket once they've been swept
+	// back into the wallet.
+	nurseryBucket = []byte("utxn")
+)
+
+// PutNurseryOutput stores the serialized state of an output being incubated
+// within the utxo nursery, keyed by the output's outpoint. If an entry for
+// the outpoint already exists, then it's overwritten. This allows the nursery
+// to update the state of the output as it progresses towards maturity.
+func (d *DB) PutNurseryOutput(op *wire.OutPoint, output []byte) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		nursery, err := tx.CreateBucketIfNotExists(nurseryBucket)
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := writeOutpoint(&b, op); err != nil {
+			return err
+		}
+
+		return nursery.Put(b.Bytes(), output)
+	})
+}
+
+// DeleteNurseryOutputs removes the passed outputs from the set of outputs
+// being incubated within the utxo nursery. This method should be called once
+// the outputs have been swept back into the wallet. The deletion is done in a
+// single transaction, therefore this operation is fully atomic.
+func (d *DB) DeleteNurseryOutputs(ops []*wire.OutPoint) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		nursery := tx.Bucket(nurseryBucket)
+		if nursery == nil {
+			return nil
+		}
+
+		for _, op := range ops {
+			var b bytes.Buffer
+			if err := writeOutpoint(&b, op); err != nil {
+				return err
+			}
+
+			if err := nursery.Delete(b.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// FetchNurseryOutputs returns the serialized state of all the outputs
+// currently being incubated within the utxo nursery. In the case that the
+// nursery is empty, a zero-length slice is returned.
+func (d *DB) FetchNurseryOutputs() ([][]byte, error) {
+	var outputs [][]byte
+	err := d.store.View(func(tx *bolt.Tx) error {
+		nursery := tx.Bucket(nurseryBucket)
+		if nursery == nil {
+			return nil
+		}
+
+		return nursery.ForEach(func(k, v []byte) error {
+			// The value returned by bolt is only valid for the
+			// lifetime of the transaction, so we make a copy.
+			output := make([]byte, len(v))
+			copy(output, v)
+
+			outputs = append(outputs, output)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
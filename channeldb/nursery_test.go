@@ -0,0 +1,68 @@
+package channeldb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestNurseryOutputs(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	// The nursery should initially be empty.
+	outputs, err := db.FetchNurseryOutputs()
+	if err != nil {
+		t.Fatalf("unable to fetch nursery outputs: %v", err)
+	}
+	if len(outputs) != 0 {
+		t.Fatalf("nursery should be empty, instead has %v outputs",
+			len(outputs))
+	}
+
+	op1 := &wire.OutPoint{Hash: key, Index: 0}
+	op2 := &wire.OutPoint{Hash: key, Index: 1}
+
+	// Add two outputs to the nursery, then overwrite the state of the
+	// first output. Only two outputs should be returned, with the first
+	// reflecting the updated state.
+	if err := db.PutNurseryOutput(op1, []byte("early")); err != nil {
+		t.Fatalf("unable to add nursery output: %v", err)
+	}
+	if err := db.PutNurseryOutput(op2, []byte("other")); err != nil {
+		t.Fatalf("unable to add nursery output: %v", err)
+	}
+	if err := db.PutNurseryOutput(op1, []byte("mid")); err != nil {
+		t.Fatalf("unable to update nursery output: %v", err)
+	}
+
+	outputs, err = db.FetchNurseryOutputs()
+	if err != nil {
+		t.Fatalf("unable to fetch nursery outputs: %v", err)
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("nursery should have 2 outputs, instead has %v",
+			len(outputs))
+	}
+	if !bytes.Equal(outputs[0], []byte("mid")) {
+		t.Fatalf("output state not updated, got %s", outputs[0])
+	}
+
+	// Finally, once both outputs have been swept, the nursery should once
+	// again be empty.
+	if err := db.DeleteNurseryOutputs([]*wire.OutPoint{op1, op2}); err != nil {
+		t.Fatalf("unable to delete nursery outputs: %v", err)
+	}
+	outputs, err = db.FetchNurseryOutputs()
+	if err != nil {
+		t.Fatalf("unable to fetch nursery outputs: %v", err)
+	}
+	if len(outputs) != 0 {
+		t.Fatalf("nursery should be empty, instead has %v outputs",
+			len(outputs))
+	}
+}
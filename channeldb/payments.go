@@ -0,0 +1,303 @@
+package channeldb
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	// paymentBucket is the name of the top-level bucket which stores all
+	// outgoing payments sent by the daemon, no matter their final state.
+	// Each payment is keyed by its payment ID, which is a monotonically
+	// increasing uint64.
+	paymentBucket = []byte("outgoing-payments")
+)
+
+const (
+	// MaxFailureReasonSize is the maximum size of the failure reason
+	// stored along side a failed payment.
+	MaxFailureReasonSize = 1024
+)
+
+// PaymentStatus represents the current state of an outgoing payment.
+type PaymentStatus uint8
+
+const (
+	// StatusInFlight denotes a payment whose HTLC has been sent, but has
+	// yet to be either settled or cancelled.
+	StatusInFlight PaymentStatus = 0
+
+	// StatusSucceeded denotes a payment which has been settled by the
+	// destination, revealing the payment preimage.
+	StatusSucceeded PaymentStatus = 1
+
+	// StatusFailed denotes a payment which was cancelled, or couldn't be
+	// sent at all.
+	StatusFailed PaymentStatus = 2
+)
+
+// String returns a human readable representation of the payment status.
+func (s PaymentStatus) String() string {
+	switch s {
+	case StatusInFlight:
+		return "In Flight"
+	case StatusSucceeded:
+		return "Succeeded"
+	case StatusFailed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// OutgoingPayment is a record of a payment sent by the daemon. A payment is
+// added to the database as in-flight once its HTLC has been dispatched, and
+// is later marked as either succeeded or failed once the HTLC is resolved.
+// For record keeping purposes, payments are never deleted from the database.
+type OutgoingPayment struct {
+	// PaymentHash is the payment hash the HTLC of this payment pays to.
+	PaymentHash [32]byte
+
+	// Amount is the amount delivered to the destination of the payment.
+	Amount btcutil.Amount
+
+	// Fee is the total fee paid to the intermediate nodes in the route.
+	Fee btcutil.Amount
+
+	// Path is the route the payment was sent over, excluding ourselves.
+	// Each hop is identified by its serialized compressed identity public
+	// key, with the final hop being the destination of the payment.
+	Path [][33]byte
+
+	// Status is the current state of the payment.
+	Status PaymentStatus
+
+	// Preimage is the preimage revealed by the destination once the
+	// payment succeeded.
+	Preimage [32]byte
+
+	// FailureReason describes why the payment failed, if it has.
+	FailureReason string
+
+	// CreationDate is the time the payment was sent.
+	CreationDate time.Time
+}
+
+// AddPayment writes the passed outgoing payment to disk, returning the ID
+// assigned to the payment. The ID is used to later update the status of the
+// payment.
+func (d *DB) AddPayment(p *OutgoingPayment) (uint64, error) {
+	var paymentID uint64
+	err := d.store.Update(func(tx *bolt.Tx) error {
+		payments, err := tx.CreateBucketIfNotExists(paymentBucket)
+		if err != nil {
+			return err
+		}
+
+		paymentID, err = payments.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return putPayment(payments, paymentID, p)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return paymentID, nil
+}
+
+// SettlePayment marks the payment with the passed ID as succeeded, recording
+// the preimage revealed by the destination.
+func (d *DB) SettlePayment(paymentID uint64, preimage [32]byte) error {
+	return d.updatePayment(paymentID, func(p *OutgoingPayment) {
+		p.Status = StatusSucceeded
+		p.Preimage = preimage
+	})
+}
+
+// FailPayment marks the payment with the passed ID as failed, recording the
+// reason for the failure. Failure reasons exceeding MaxFailureReasonSize are
+// truncated.
+func (d *DB) FailPayment(paymentID uint64, reason string) error {
+	if len(reason) > MaxFailureReasonSize {
+		reason = reason[:MaxFailureReasonSize]
+	}
+
+	return d.updatePayment(paymentID, func(p *OutgoingPayment) {
+		p.Status = StatusFailed
+		p.FailureReason = reason
+	})
+}
+
+// updatePayment applies the passed modification to the payment with the
+// passed ID, then writes the payment back to disk.
+func (d *DB) updatePayment(paymentID uint64, modify func(*OutgoingPayment)) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		payments := tx.Bucket(paymentBucket)
+		if payments == nil {
+			return ErrPaymentNotFound
+		}
+
+		var paymentKey [8]byte
+		byteOrder.PutUint64(paymentKey[:], paymentID)
+
+		paymentBytes := payments.Get(paymentKey[:])
+		if paymentBytes == nil {
+			return ErrPaymentNotFound
+		}
+
+		payment, err := deserializePayment(bytes.NewReader(paymentBytes))
+		if err != nil {
+			return err
+		}
+
+		modify(payment)
+
+		return putPayment(payments, paymentID, payment)
+	})
+}
+
+// FetchAllPayments returns all outgoing payments stored within the database,
+// ordered by the time they were sent. In the case that no payments exist, a
+// zero-length slice is returned.
+func (d *DB) FetchAllPayments() ([]*OutgoingPayment, error) {
+	var payments []*OutgoingPayment
+	err := d.store.View(func(tx *bolt.Tx) error {
+		paymentB := tx.Bucket(paymentBucket)
+		if paymentB == nil {
+			return nil
+		}
+
+		return paymentB.ForEach(func(k, v []byte) error {
+			payment, err := deserializePayment(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			payments = append(payments, payment)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func putPayment(payments *bolt.Bucket, paymentID uint64, p *OutgoingPayment) error {
+	var b bytes.Buffer
+	if err := serializePayment(&b, p); err != nil {
+		return err
+	}
+
+	var paymentKey [8]byte
+	byteOrder.PutUint64(paymentKey[:], paymentID)
+
+	return payments.Put(paymentKey[:], b.Bytes())
+}
+
+func serializePayment(w io.Writer, p *OutgoingPayment) error {
+	if _, err := w.Write(p.PaymentHash[:]); err != nil {
+		return err
+	}
+
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], uint64(p.Amount))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	byteOrder.PutUint64(scratch[:], uint64(p.Fee))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	var numHops [2]byte
+	byteOrder.PutUint16(numHops[:], uint16(len(p.Path)))
+	if _, err := w.Write(numHops[:]); err != nil {
+		return err
+	}
+	for _, hop := range p.Path {
+		if _, err := w.Write(hop[:]); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.Write([]byte{byte(p.Status)}); err != nil {
+		return err
+	}
+	if _, err := w.Write(p.Preimage[:]); err != nil {
+		return err
+	}
+	if err := wire.WriteVarString(w, 0, p.FailureReason); err != nil {
+		return err
+	}
+
+	birthBytes, err := p.CreationDate.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return wire.WriteVarBytes(w, 0, birthBytes)
+}
+
+func deserializePayment(r io.Reader) (*OutgoingPayment, error) {
+	p := &OutgoingPayment{}
+
+	if _, err := io.ReadFull(r, p.PaymentHash[:]); err != nil {
+		return nil, err
+	}
+
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	p.Amount = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	p.Fee = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	var numHops [2]byte
+	if _, err := io.ReadFull(r, numHops[:]); err != nil {
+		return nil, err
+	}
+	p.Path = make([][33]byte, byteOrder.Uint16(numHops[:]))
+	for i := range p.Path {
+		if _, err := io.ReadFull(r, p.Path[i][:]); err != nil {
+			return nil, err
+		}
+	}
+
+	var status [1]byte
+	if _, err := io.ReadFull(r, status[:]); err != nil {
+		return nil, err
+	}
+	p.Status = PaymentStatus(status[0])
+
+	if _, err := io.ReadFull(r, p.Preimage[:]); err != nil {
+		return nil, err
+	}
+
+	reason, err := wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	p.FailureReason = reason
+
+	birthBytes, err := wire.ReadVarBytes(r, 0, 300, "birth")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CreationDate.UnmarshalBinary(birthBytes); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
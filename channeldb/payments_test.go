@@ -0,0 +1,99 @@
+package channeldb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/roasbeef/btcutil"
+)
+
+func TestPaymentWorkflow(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	var hop [33]byte
+	copy(hop[:], pubKey.SerializeCompressed())
+
+	payment := &OutgoingPayment{
+		PaymentHash:  key,
+		Amount:       btcutil.Amount(10000),
+		Fee:          btcutil.Amount(10),
+		Path:         [][33]byte{hop, hop},
+		Status:       StatusInFlight,
+		CreationDate: time.Unix(time.Now().Unix(), 0),
+	}
+
+	// Add the payment to the database, it should then be returned as
+	// in-flight when fetching all payments.
+	paymentID, err := db.AddPayment(payment)
+	if err != nil {
+		t.Fatalf("unable to add payment: %v", err)
+	}
+	payments, err := db.FetchAllPayments()
+	if err != nil {
+		t.Fatalf("unable to fetch payments: %v", err)
+	}
+	if len(payments) != 1 {
+		t.Fatalf("expected 1 payment, instead have %v", len(payments))
+	}
+	if !reflect.DeepEqual(payment, payments[0]) {
+		t.Fatalf("payments don't match: %v vs %v", payment, payments[0])
+	}
+
+	// Once settled, the payment should be marked as succeeded, and carry
+	// the revealed preimage.
+	preimage := rev
+	if err := db.SettlePayment(paymentID, preimage); err != nil {
+		t.Fatalf("unable to settle payment: %v", err)
+	}
+	payments, err = db.FetchAllPayments()
+	if err != nil {
+		t.Fatalf("unable to fetch payments: %v", err)
+	}
+	if payments[0].Status != StatusSucceeded {
+		t.Fatalf("expected payment status %v, instead have %v",
+			StatusSucceeded, payments[0].Status)
+	}
+	if payments[0].Preimage != preimage {
+		t.Fatalf("preimages don't match: %x vs %x", preimage,
+			payments[0].Preimage)
+	}
+
+	// A second payment which fails should be stored separately along with
+	// its failure reason.
+	secondID, err := db.AddPayment(payment)
+	if err != nil {
+		t.Fatalf("unable to add payment: %v", err)
+	}
+	if secondID == paymentID {
+		t.Fatalf("payment IDs should be unique")
+	}
+	reason := "payment cancelled by remote peer"
+	if err := db.FailPayment(secondID, reason); err != nil {
+		t.Fatalf("unable to fail payment: %v", err)
+	}
+	payments, err = db.FetchAllPayments()
+	if err != nil {
+		t.Fatalf("unable to fetch payments: %v", err)
+	}
+	if len(payments) != 2 {
+		t.Fatalf("expected 2 payments, instead have %v", len(payments))
+	}
+	if payments[1].Status != StatusFailed {
+		t.Fatalf("expected payment status %v, instead have %v",
+			StatusFailed, payments[1].Status)
+	}
+	if payments[1].FailureReason != reason {
+		t.Fatalf("failure reasons don't match: %v vs %v", reason,
+			payments[1].FailureReason)
+	}
+
+	// Updating a payment which doesn't exist should fail.
+	if err := db.SettlePayment(secondID+1, preimage); err != ErrPaymentNotFound {
+		t.Fatalf("expected ErrPaymentNotFound, instead got: %v", err)
+	}
+}
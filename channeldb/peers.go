@@ -0,0 +1,121 @@
+package channeldb
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+var (
+	// peerAddrBucket is the name of the top-level bucket which stores the
+	// last known reachable network address of each peer we have open
+	// channels with. Each entry is keyed by the serialized compressed
+	// identity public key of the peer.
+	peerAddrBucket = []byte("peer-addrs")
+)
+
+// PeerAddr pairs the long-term identity public key of a channel counterparty
+// with the network address we last reached them at. This information allows
+// the daemon to re-establish connections to all channel peers after a restart,
+// or after a connection has been dropped.
+type PeerAddr struct {
+	// IdentityPub is the identity public key of the remote peer.
+	IdentityPub *btcec.PublicKey
+
+	// Address is the host:port network address the peer was last
+	// reachable at, either a TCP address, or the address of a Tor hidden
+	// service. The address is stored as is, and is only resolved once
+	// the peer is dialed.
+	Address string
+}
+
+// PutPeerAddr writes the network address of the passed peer to disk. If an
+// address for the peer already exists, then it's overwritten.
+func (d *DB) PutPeerAddr(addr *PeerAddr) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		peerAddrs, err := tx.CreateBucketIfNotExists(peerAddrBucket)
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := serializePeerAddr(&b, addr); err != nil {
+			return err
+		}
+
+		pubKey := addr.IdentityPub.SerializeCompressed()
+		return peerAddrs.Put(pubKey, b.Bytes())
+	})
+}
+
+// DeletePeerAddr removes the stored network address of the peer identified by
+// the passed public key. No error is returned if the address doesn't exist.
+func (d *DB) DeletePeerAddr(identityPub *btcec.PublicKey) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		peerAddrs := tx.Bucket(peerAddrBucket)
+		if peerAddrs == nil {
+			return nil
+		}
+
+		return peerAddrs.Delete(identityPub.SerializeCompressed())
+	})
+}
+
+// FetchAllPeerAddrs returns the stored network addresses of all peers. In the
+// case that no addresses exist, a zero-length slice is returned.
+func (d *DB) FetchAllPeerAddrs() ([]*PeerAddr, error) {
+	var addrs []*PeerAddr
+	err := d.store.View(func(tx *bolt.Tx) error {
+		peerAddrs := tx.Bucket(peerAddrBucket)
+		if peerAddrs == nil {
+			return nil
+		}
+
+		return peerAddrs.ForEach(func(k, v []byte) error {
+			addr, err := deserializePeerAddr(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			addrs = append(addrs, addr)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return addrs, nil
+}
+
+func serializePeerAddr(w io.Writer, a *PeerAddr) error {
+	pubKey := a.IdentityPub.SerializeCompressed()
+	if err := wire.WriteVarBytes(w, 0, pubKey); err != nil {
+		return err
+	}
+
+	return wire.WriteVarString(w, 0, a.Address)
+}
+
+func deserializePeerAddr(r io.Reader) (*PeerAddr, error) {
+	a := &PeerAddr{}
+
+	pubKey, err := wire.ReadVarBytes(r, 0, 33, "pubkey")
+	if err != nil {
+		return nil, err
+	}
+	a.IdentityPub, err = btcec.ParsePubKey(pubKey, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+
+	a.Address, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
@@ -0,0 +1,109 @@
+package channeldb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPeerAddrWorkflow(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	const tcpAddr = "10.0.0.1:10011"
+	addr := &PeerAddr{
+		IdentityPub: pubKey,
+		Address:     tcpAddr,
+	}
+
+	// Add the address to the database, it should then be returned when
+	// fetching all peer addresses.
+	if err := db.PutPeerAddr(addr); err != nil {
+		t.Fatalf("unable to add peer addr: %v", err)
+	}
+	addrs, err := db.FetchAllPeerAddrs()
+	if err != nil {
+		t.Fatalf("unable to fetch peer addrs: %v", err)
+	}
+	if len(addrs) != 1 {
+		t.Fatalf("expected 1 addr, instead have %v", len(addrs))
+	}
+	if !bytes.Equal(addrs[0].IdentityPub.SerializeCompressed(),
+		pubKey.SerializeCompressed()) {
+		t.Fatalf("pubkeys don't match")
+	}
+	if addrs[0].Address != tcpAddr {
+		t.Fatalf("addresses don't match: %v vs %v", addrs[0].Address,
+			tcpAddr)
+	}
+
+	// Writing a new address for the same peer should overwrite the
+	// existing entry.
+	const newAddr = "10.0.0.2:10011"
+	addr.Address = newAddr
+	if err := db.PutPeerAddr(addr); err != nil {
+		t.Fatalf("unable to add peer addr: %v", err)
+	}
+	addrs, err = db.FetchAllPeerAddrs()
+	if err != nil {
+		t.Fatalf("unable to fetch peer addrs: %v", err)
+	}
+	if len(addrs) != 1 {
+		t.Fatalf("expected 1 addr, instead have %v", len(addrs))
+	}
+	if addrs[0].Address != newAddr {
+		t.Fatalf("addresses don't match: %v vs %v", addrs[0].Address,
+			newAddr)
+	}
+
+	// Once the address is deleted, no addresses should remain.
+	if err := db.DeletePeerAddr(pubKey); err != nil {
+		t.Fatalf("unable to delete peer addr: %v", err)
+	}
+	addrs, err = db.FetchAllPeerAddrs()
+	if err != nil {
+		t.Fatalf("unable to fetch peer addrs: %v", err)
+	}
+	if len(addrs) != 0 {
+		t.Fatalf("expected no addrs, instead have %v", len(addrs))
+	}
+}
+
+func TestPeerAddrUnresolved(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	// Neither the address of a Tor hidden service, nor a hostname should
+	// be resolved while reading the database, so each should be
+	// returned exactly as it was stored.
+	peerAddrs := []string{
+		"expyuzz4wqqyqhjn.onion:10011",
+		"node.example.com:10011",
+	}
+	for _, peerAddr := range peerAddrs {
+		addr := &PeerAddr{
+			IdentityPub: pubKey,
+			Address:     peerAddr,
+		}
+		if err := db.PutPeerAddr(addr); err != nil {
+			t.Fatalf("unable to add peer addr: %v", err)
+		}
+
+		addrs, err := db.FetchAllPeerAddrs()
+		if err != nil {
+			t.Fatalf("unable to fetch peer addrs: %v", err)
+		}
+		if len(addrs) != 1 {
+			t.Fatalf("expected 1 addr, instead have %v", len(addrs))
+		}
+		if addrs[0].Address != peerAddr {
+			t.Fatalf("addresses don't match: %v vs %v",
+				addrs[0].Address, peerAddr)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package channeldb
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+)
+
+var (
+	// pendingChanBucket is the name of the top-level bucket which tracks
+	// all channels whose funding transaction has been broadcast, but has
+	// not yet reached the required number of confirmations. Each entry is
+	// keyed by the channel point of the pending channel.
+	pendingChanBucket = []byte("pending-chans")
+)
+
+// PendingChannel describes a channel whose funding transaction has been
+// broadcast, but which isn't yet considered open as the funding transaction
+// hasn't reached a sufficient number of confirmations. The full state of a
+// pending channel is stored within the open channel bucket, however it MUST
+// NOT be used for any updates until the channel is marked as open by deleting
+// its pending entry.
+type PendingChannel struct {
+	// ChanPoint is the outpoint of the funding transaction of the pending
+	// channel.
+	ChanPoint wire.OutPoint
+
+	// TheirLNID is the identity of the remote node the channel is being
+	// opened with.
+	TheirLNID [wire.HashSize]byte
+
+	// NumConfs is the number of confirmations the funding transaction
+	// requires before the channel is considered open.
+	NumConfs uint16
+}
+
+// AddPendingChannel writes the passed pending channel to disk. If the
+// channel is already marked as pending, then the existing entry is
+// overwritten.
+func (d *DB) AddPendingChannel(c *PendingChannel) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		pendingChans, err := tx.CreateBucketIfNotExists(pendingChanBucket)
+		if err != nil {
+			return err
+		}
+
+		var k bytes.Buffer
+		if err := writeOutpoint(&k, &c.ChanPoint); err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := serializePendingChannel(&b, c); err != nil {
+			return err
+		}
+
+		return pendingChans.Put(k.Bytes(), b.Bytes())
+	})
+}
+
+// DeletePendingChannel removes the pending entry for the channel identified
+// by the passed channel point, marking the channel as open. No error is
+// returned if the channel isn't pending.
+func (d *DB) DeletePendingChannel(chanPoint *wire.OutPoint) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		pendingChans := tx.Bucket(pendingChanBucket)
+		if pendingChans == nil {
+			return nil
+		}
+
+		var k bytes.Buffer
+		if err := writeOutpoint(&k, chanPoint); err != nil {
+			return err
+		}
+
+		return pendingChans.Delete(k.Bytes())
+	})
+}
+
+// FetchPendingChannels returns all channels which are currently waiting for
+// their funding transaction to be confirmed. In the case that no channels are
+// pending, a zero-length slice is returned.
+func (d *DB) FetchPendingChannels() ([]*PendingChannel, error) {
+	var pending []*PendingChannel
+	err := d.store.View(func(tx *bolt.Tx) error {
+		pendingChans := tx.Bucket(pendingChanBucket)
+		if pendingChans == nil {
+			return nil
+		}
+
+		return pendingChans.ForEach(func(k, v []byte) error {
+			c, err := deserializePendingChannel(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			pending = append(pending, c)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+func serializePendingChannel(w io.Writer, c *PendingChannel) error {
+	if err := writeOutpoint(w, &c.ChanPoint); err != nil {
+		return err
+	}
+	if _, err := w.Write(c.TheirLNID[:]); err != nil {
+		return err
+	}
+
+	var scratch [2]byte
+	byteOrder.PutUint16(scratch[:], c.NumConfs)
+	_, err := w.Write(scratch[:])
+	return err
+}
+
+func deserializePendingChannel(r io.Reader) (*PendingChannel, error) {
+	c := &PendingChannel{}
+
+	if err := readOutpoint(r, &c.ChanPoint); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, c.TheirLNID[:]); err != nil {
+		return nil, err
+	}
+
+	var scratch [2]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	c.NumConfs = byteOrder.Uint16(scratch[:])
+
+	return c, nil
+}
@@ -0,0 +1,57 @@
+package channeldb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestPendingChannelWorkflow(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	pendingChan := &PendingChannel{
+		ChanPoint: wire.OutPoint{
+			Hash:  key,
+			Index: 1,
+		},
+		TheirLNID: key,
+		NumConfs:  6,
+	}
+
+	// Mark the channel as pending, it should then be returned when
+	// fetching all pending channels.
+	if err := db.AddPendingChannel(pendingChan); err != nil {
+		t.Fatalf("unable to add pending channel: %v", err)
+	}
+	pending, err := db.FetchPendingChannels()
+	if err != nil {
+		t.Fatalf("unable to fetch pending channels: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending channel, instead have %v",
+			len(pending))
+	}
+	if !reflect.DeepEqual(pending[0], pendingChan) {
+		t.Fatalf("pending channels don't match: %v vs %v",
+			pending[0], pendingChan)
+	}
+
+	// Once the channel is marked as open by deleting its pending entry,
+	// no pending channels should remain.
+	if err := db.DeletePendingChannel(&pendingChan.ChanPoint); err != nil {
+		t.Fatalf("unable to delete pending channel: %v", err)
+	}
+	pending, err = db.FetchPendingChannels()
+	if err != nil {
+		t.Fatalf("unable to fetch pending channels: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending channels, instead have %v",
+			len(pending))
+	}
+}
@@ -537,6 +537,80 @@ func listChannels(ctx *cli.Context) error {
 	return nil
 }
 
+var UpdateChannelPolicyCommand = cli.Command{
+	Name: "updatechanpolicy",
+	Description: "Update the routing policy enforced when forwarding " +
+		"payments over a channel. If no channel is specified, then the " +
+		"policy of all open channels is updated.",
+	Usage: "updatechanpolicy --base_fee=[in_satoshis] --fee_rate=[ppm] " +
+		"--min_htlc=[in_satoshis] --time_lock_delta=[blocks] " +
+		"[--funding_txid=[txid] --output_index=[index]]",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "base_fee",
+			Usage: "the flat fee in satoshis charged for each HTLC forwarded",
+		},
+		cli.IntFlag{
+			Name: "fee_rate",
+			Usage: "the proportional fee charged for each HTLC " +
+				"forwarded, in millionths of the amount forwarded",
+		},
+		cli.IntFlag{
+			Name:  "min_htlc",
+			Usage: "the smallest HTLC in satoshis which will be forwarded",
+		},
+		cli.IntFlag{
+			Name: "time_lock_delta",
+			Usage: "the number of blocks subtracted from the expiry of " +
+				"each HTLC forwarded",
+		},
+		cli.StringFlag{
+			Name: "funding_txid",
+			Usage: "the txid of the channel's funding transaction, if " +
+				"omitted all open channels are updated",
+		},
+		cli.IntFlag{
+			Name: "output_index",
+			Usage: "the output index for the funding output of the funding " +
+				"transaction",
+		},
+	},
+	Action: updateChannelPolicy,
+}
+
+func updateChannelPolicy(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	req := &lnrpc.UpdateChannelPolicyRequest{
+		BaseFee:       int64(ctx.Int("base_fee")),
+		FeeRate:       uint32(ctx.Int("fee_rate")),
+		MinHtlc:       int64(ctx.Int("min_htlc")),
+		TimeLockDelta: uint32(ctx.Int("time_lock_delta")),
+	}
+
+	if ctx.IsSet("funding_txid") {
+		txid, err := wire.NewShaHashFromStr(ctx.String("funding_txid"))
+		if err != nil {
+			return err
+		}
+
+		req.ChanPoint = &lnrpc.ChannelPoint{
+			FundingTxid: txid[:],
+			OutputIndex: uint32(ctx.Int("output_index")),
+		}
+	}
+
+	resp, err := client.UpdateChannelPolicy(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
 var SendPaymentCommand = cli.Command{
 	Name:        "sendpayment",
 	Description: "send a payment over lightning",
@@ -564,6 +638,11 @@ var SendPaymentCommand = cli.Command{
 			Usage: "skip the HTLC trickle logic, immediately creating a " +
 				"new commitment",
 		},
+		cli.StringFlag{
+			Name: "pay_req",
+			Usage: "an encoded payment request, if set the dest, amt " +
+				"and payment_hash flags are ignored",
+		},
 	},
 	Action: sendPaymentCommand,
 }
@@ -571,22 +650,33 @@ var SendPaymentCommand = cli.Command{
 func sendPaymentCommand(ctx *cli.Context) error {
 	client := getClient(ctx)
 
-	destNode, err := hex.DecodeString(ctx.String("dest"))
-	if err != nil {
-		return err
-	}
-	if len(destNode) != 33 {
-		return fmt.Errorf("dest node pubkey must be exactly 33 bytes, is "+
-			"instead: %v", len(destNode))
-	}
+	var req *lnrpc.SendRequest
+	if ctx.IsSet("pay_req") {
+		// If a payment request was provided, then all the details of
+		// the payment are encoded within it.
+		req = &lnrpc.SendRequest{
+			PaymentRequest: ctx.String("pay_req"),
+			Amt:            int64(ctx.Int("amt")),
+			FastSend:       ctx.Bool("fast"),
+		}
+	} else {
+		destNode, err := hex.DecodeString(ctx.String("dest"))
+		if err != nil {
+			return err
+		}
+		if len(destNode) != 33 {
+			return fmt.Errorf("dest node pubkey must be exactly 33 "+
+				"bytes, is instead: %v", len(destNode))
+		}
 
-	req := &lnrpc.SendRequest{
-		Dest:     destNode,
-		Amt:      int64(ctx.Int("amt")),
-		FastSend: ctx.Bool("fast"),
+		req = &lnrpc.SendRequest{
+			Dest:     destNode,
+			Amt:      int64(ctx.Int("amt")),
+			FastSend: ctx.Bool("fast"),
+		}
 	}
 
-	if !ctx.Bool("debug_send") {
+	if !ctx.Bool("debug_send") && !ctx.IsSet("pay_req") {
 		rHash, err := hex.DecodeString(ctx.String("payment_hash"))
 		if err != nil {
 			return err
@@ -619,6 +709,75 @@ func sendPaymentCommand(ctx *cli.Context) error {
 	return nil
 }
 
+var ListPaymentsCommand = cli.Command{
+	Name:        "listpayments",
+	Usage:       "listpayments",
+	Description: "list all outgoing payments along with their status",
+	Action:      listPayments,
+}
+
+func listPayments(ctx *cli.Context) error {
+	client := getClient(ctx)
+
+	req := &lnrpc.ListPaymentsRequest{}
+	payments, err := client.ListPayments(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(payments)
+
+	return nil
+}
+
+var ForwardingHistoryCommand = cli.Command{
+	Name: "fwdinghistory",
+	Usage: "fwdinghistory --start_time=[unix_time] --end_time=[unix_time] " +
+		"--index_offset=[offset] --max_events=[num]",
+	Description: "list the payments forwarded by the node within a time " +
+		"range, along with the fee earned by each",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "start_time",
+			Usage: "the unix time from which to list forwarded payments",
+		},
+		cli.IntFlag{
+			Name: "end_time",
+			Usage: "the unix time until which to list forwarded " +
+				"payments, defaults to the current time",
+		},
+		cli.IntFlag{
+			Name: "index_offset",
+			Usage: "the number of forwarded payments within the time " +
+				"range to skip, used to fetch the next page",
+		},
+		cli.IntFlag{
+			Name:  "max_events",
+			Usage: "the maximum number of forwarded payments to list",
+		},
+	},
+	Action: forwardingHistory,
+}
+
+func forwardingHistory(ctx *cli.Context) error {
+	client := getClient(ctx)
+
+	req := &lnrpc.ForwardingHistoryRequest{
+		StartTime:    uint64(ctx.Int("start_time")),
+		EndTime:      uint64(ctx.Int("end_time")),
+		IndexOffset:  uint32(ctx.Int("index_offset")),
+		NumMaxEvents: uint32(ctx.Int("max_events")),
+	}
+	resp, err := client.ForwardingHistory(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
 var AddInvoiceCommand = cli.Command{
 	Name:        "addinvoice",
 	Description: "add a new invoice, expressing intent for a future payment",
@@ -640,6 +799,11 @@ var AddInvoiceCommand = cli.Command{
 			Name:  "value",
 			Usage: "the value of this invoice in satoshis",
 		},
+		cli.IntFlag{
+			Name: "expiry",
+			Usage: "the number of seconds the invoice is valid " +
+				"for, defaults to one hour",
+		},
 	},
 	Action: addInvoice,
 }
@@ -662,6 +826,7 @@ func addInvoice(ctx *cli.Context) error {
 		Receipt:   receipt,
 		RPreimage: preimage,
 		Value:     int64(ctx.Int("value")),
+		Expiry:    int64(ctx.Int("expiry")),
 	}
 
 	resp, err := client.AddInvoice(context.Background(), invoice)
@@ -670,9 +835,11 @@ func addInvoice(ctx *cli.Context) error {
 	}
 
 	printRespJson(struct {
-		RHash string `json:"r_hash"`
+		RHash  string `json:"r_hash"`
+		PayReq string `json:"pay_req"`
 	}{
-		RHash: hex.EncodeToString(resp.RHash),
+		RHash:  hex.EncodeToString(resp.RHash),
+		PayReq: resp.PaymentRequest,
 	})
 
 	return nil
@@ -714,6 +881,42 @@ func lookupInvoice(ctx *cli.Context) error {
 	return nil
 }
 
+var CancelInvoiceCommand = cli.Command{
+	Name:        "cancelinvoice",
+	Description: "cancel an open invoice, any further payments to the invoice will be rejected",
+	Usage:       "cancelinvoice --rhash=[32_byte_hash]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "rhash",
+			Usage: "the payment hash of the invoice to cancel, the hash " +
+				"should be a hex-encoded string",
+		},
+	},
+	Action: cancelInvoice,
+}
+
+func cancelInvoice(ctx *cli.Context) error {
+	client := getClient(ctx)
+
+	rHash, err := hex.DecodeString(ctx.String("rhash"))
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.PaymentHash{
+		RHash: rHash,
+	}
+
+	resp, err := client.CancelInvoice(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
 var ListInvoicesCommand = cli.Command{
 	Name:        "listinvoices",
 	Usage:       "listinvoice --pending_only=[true|false]",
@@ -750,6 +953,82 @@ func listInvoices(ctx *cli.Context) error {
 	return nil
 }
 
+var SubscribeInvoicesCommand = cli.Command{
+	Name:        "subscribeinvoices",
+	Usage:       "subscribeinvoices --add_index=[index] --settle_index=[index]",
+	Description: "stream all newly added and settled invoices, optionally resuming from a prior add or settle index",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name: "add_index",
+			Usage: "if set, all invoices added after this index " +
+				"will be sent first",
+		},
+		cli.IntFlag{
+			Name: "settle_index",
+			Usage: "if set, all invoices settled after this index " +
+				"will be sent first",
+		},
+	},
+	Action: subscribeInvoices,
+}
+
+func subscribeInvoices(ctx *cli.Context) error {
+	client := getClient(ctx)
+
+	req := &lnrpc.InvoiceSubscription{
+		AddIndex:    uint64(ctx.Int("add_index")),
+		SettleIndex: uint64(ctx.Int("settle_index")),
+	}
+	stream, err := client.SubscribeInvoices(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		invoice, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		printRespJson(invoice)
+	}
+}
+
+var DecodePayReqCommand = cli.Command{
+	Name:        "decodepayreq",
+	Usage:       "decodepayreq --pay_req=[encoded_pay_req]",
+	Description: "decode the passed payment request revealing the destination, payment hash and value of the payment request",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "pay_req",
+			Usage: "the z-base-32 encoded payment request",
+		},
+	},
+	Action: decodePayReq,
+}
+
+func decodePayReq(ctx *cli.Context) error {
+	client := getClient(ctx)
+
+	if ctx.String("pay_req") == "" {
+		return fmt.Errorf("the --pay_req argument cannot be empty")
+	}
+
+	req := &lnrpc.PayReqString{
+		PayReq: ctx.String("pay_req"),
+	}
+	resp, err := client.DecodePayReq(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
 var ShowRoutingTableCommand = cli.Command{
 	Name:        "showroutingtable",
 	Description: "shows routing table for a node",
@@ -61,11 +61,17 @@ func main() {
 		GetInfoCommand,
 		PendingChannelsCommand,
 		SendPaymentCommand,
+		ListPaymentsCommand,
+		ForwardingHistoryCommand,
 		AddInvoiceCommand,
 		LookupInvoiceCommand,
+		CancelInvoiceCommand,
 		ListInvoicesCommand,
+		SubscribeInvoicesCommand,
+		DecodePayReqCommand,
 		ShowRoutingTableCommand,
 		ListChannelsCommand,
+		UpdateChannelPolicyCommand,
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	flags "github.com/btcsuite/go-flags"
+	"github.com/lightningnetwork/lnd/lndc"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/roasbeef/btcutil"
 )
 
@@ -25,6 +27,29 @@ const (
 	defaultRPCUser        = "user"
 	defaultRPCPass        = "passwd"
 	defaultSPVHostAdr     = "localhost:18333"
+	defaultFeePerByte     = 50
+	defaultFeeEstimator   = btcdFeeEstimator
+
+	defaultOverpaymentTolerance = 1.0
+
+	defaultBaseFee       = 1
+	defaultFeeRate       = 1
+	defaultMinHTLC       = 1
+	defaultTimeLockDelta = 10
+
+	defaultMaxAcceptedHTLCs = 30
+	defaultHtlcMinimum      = 1
+)
+
+const (
+	// btcdFeeEstimator selects fee estimates obtained from the btcd node
+	// backing the daemon, falling back to the configured fee rate if btcd
+	// is unable to produce an estimate.
+	btcdFeeEstimator = "btcd"
+
+	// staticFeeEstimator selects the configured fee rate for all fee
+	// calculations.
+	staticFeeEstimator = "static"
 )
 
 var (
@@ -51,6 +76,9 @@ type config struct {
 	Listeners   []string `long:"listen" description:"Add an interface/port to listen for connections (default all interfaces port: 10011)"`
 	ExternalIPs []string `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers"`
 
+	SOCKSProxy string `long:"socksproxy" description:"Connect to peers through the SOCKS5 proxy at the given host:port, such as a local Tor daemon (e.g. 127.0.0.1:9050). Required in order to connect to onion addresses"`
+	OnionAddr  string `long:"onionaddr" description:"The host:port of a Tor hidden service which forwards to our p2p listener. If set, the onion address is advertised as one of our URIs within getinfo"`
+
 	DebugLevel string `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
 
 	Profile string `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
@@ -69,6 +97,20 @@ type config struct {
 	SimNet     bool   `long:"simnet" description:"Use the simulation test network"`
 	SegNet     bool   `long:"segnet" description:"Use the segragated witness test network"`
 	DebugHTLC  bool   `long:"debughtlc" description:"Activate the debug htlc mode. With the debug HTLC mode, all payments sent use a pre-determined R-Hash. Additionally, all HTLC's sent to a node with the debug HTLC R-Hash are immediately settled in the next available state transition."`
+
+	OverpaymentTolerance float64 `long:"overpaymenttolerance" description:"The fraction of an invoice's value by which an incoming payment may exceed it and still be accepted. A tolerance of 1.0 accepts payments of up to twice the invoice's value."`
+
+	BaseFee       int64  `long:"basefee" description:"The default base fee in satoshis charged for each HTLC forwarded over a channel"`
+	FeeRate       uint32 `long:"feerate" description:"The default proportional fee charged for each HTLC forwarded over a channel, in millionths of the amount forwarded"`
+	MinHTLC       int64  `long:"minhtlc" description:"The default smallest HTLC in satoshis which will be forwarded over a channel"`
+	TimeLockDelta uint32 `long:"timelockdelta" description:"The default number of blocks subtracted from the expiry of each HTLC forwarded over a channel"`
+
+	FeeEstimator string `long:"feeestimator" description:"The source of on-chain fee estimates {btcd, static}. With btcd, estimates are obtained from the btcd node, falling back to the rate given by --feeperbyte. With static, the rate given by --feeperbyte is always used"`
+	FeePerByte   int64  `long:"feeperbyte" description:"The on-chain fee rate in satoshis per byte used by the static fee estimator, and as the fallback rate of the btcd fee estimator"`
+
+	MaxAcceptedHTLCs uint16 `long:"maxacceptedhtlcs" description:"The maximum number of HTLC's the remote peer may offer us at once within a newly opened channel"`
+	MaxValueInFlight int64  `long:"maxvalueinflight" description:"The maximum total value in satoshis of the HTLC's the remote peer may offer us at once within a newly opened channel. A value of 0 allows the full capacity of the channel"`
+	HtlcMinimum      int64  `long:"htlcminimum" description:"The smallest HTLC in satoshis we'll accept within a newly opened channel"`
 }
 
 // loadConfig initializes and parses the config using a config file and command
@@ -93,6 +135,19 @@ func loadConfig() (*config, error) {
 		RPCPass:    defaultRPCPass,
 		RPCCert:    defaultRPCCertFile,
 		SPVHostAdr: defaultSPVHostAdr,
+
+		OverpaymentTolerance: defaultOverpaymentTolerance,
+
+		BaseFee:       defaultBaseFee,
+		FeeRate:       defaultFeeRate,
+		MinHTLC:       defaultMinHTLC,
+		TimeLockDelta: defaultTimeLockDelta,
+
+		FeeEstimator: defaultFeeEstimator,
+		FeePerByte:   defaultFeePerByte,
+
+		MaxAcceptedHTLCs: defaultMaxAcceptedHTLCs,
+		HtlcMinimum:      defaultHtlcMinimum,
 	}
 
 	// Pre-parse the command line options to pick up an alternative config
@@ -173,6 +228,78 @@ func loadConfig() (*config, error) {
 		}
 	}
 
+	// A negative overpayment tolerance would reject payments which
+	// exactly match the value of an invoice.
+	if cfg.OverpaymentTolerance < 0 {
+		str := "%s: The overpayment tolerance must be non-negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// Fees and HTLC amounts are denominated in satoshis, so they can't be
+	// negative.
+	if cfg.BaseFee < 0 || cfg.MinHTLC < 0 {
+		str := "%s: The base fee and min HTLC must be non-negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// Ensure a known fee estimator was selected, and that the fee rate
+	// it's configured with is able to get transactions confirmed.
+	if cfg.FeeEstimator != btcdFeeEstimator &&
+		cfg.FeeEstimator != staticFeeEstimator {
+
+		str := "%s: The fee estimator must be one of {%v, %v}"
+		err := fmt.Errorf(str, funcName, btcdFeeEstimator,
+			staticFeeEstimator)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if cfg.FeePerByte <= 0 {
+		str := "%s: The fee per byte must be positive"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// Each side of a channel may have at most lnwire.MaxHTLCNumber HTLC's
+	// outstanding, in order to keep the commitment transaction standard.
+	if cfg.MaxAcceptedHTLCs == 0 || cfg.MaxAcceptedHTLCs > lnwire.MaxHTLCNumber {
+		str := "%s: The max accepted HTLC's must be in the range [1, %v]"
+		err := fmt.Errorf(str, funcName, lnwire.MaxHTLCNumber)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if cfg.MaxValueInFlight < 0 || cfg.HtlcMinimum < 0 {
+		str := "%s: The max value in flight and HTLC minimum must be " +
+			"non-negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// If we're advertising a hidden service, then ensure its address is a
+	// valid onion address.
+	if cfg.OnionAddr != "" {
+		addr, err := lndc.ResolveAddr(cfg.OnionAddr, true)
+		if _, ok := addr.(*lndc.OnionAddr); err != nil || !ok {
+			str := "%s: The onion address must be of the form " +
+				"<service>.onion:<port>"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
 	// Append the network type to the data directory so it is "namespaced"
 	// per network. In addition to the block database, there are other
 	// pieces of data that are saved to disk such as address manager state.
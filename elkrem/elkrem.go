@@ -124,7 +124,7 @@ func (e *ElkremReceiver) AddNext(sha *wire.ShaHash) error {
 
 // AtIndex returns the w'th hash in the receiver.
 func (e *ElkremReceiver) AtIndex(w uint64) (*wire.ShaHash, error) {
-	if e == nil || e.s == nil {
+	if e == nil || len(e.s) == 0 {
 		return nil, fmt.Errorf("nil elkrem receiver")
 	}
 	var out ElkremNode      // node we will eventually return
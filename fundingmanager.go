@@ -2,9 +2,12 @@ package main
 
 import (
 	"encoding/hex"
+	"fmt"
 	"sync"
 	"sync/atomic"
 
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwire"
@@ -20,6 +23,17 @@ import (
 const (
 	// TODO(roasbeef): tune
 	msgBufferSize = 50
+
+	// fundingConfTarget is the number of blocks within which we'd like
+	// the funding transaction of a new channel to confirm. It's used to
+	// query the fee estimator for the fee rate proposed to the remote
+	// peer.
+	fundingConfTarget = 6
+
+	// minFeeRateDivisor bounds how far below our own fee estimate the fee
+	// rate proposed by the initiator of a channel may be. Proposed fee
+	// rates below our estimate divided by this value are rejected.
+	minFeeRateDivisor = 2
 )
 
 // reservationWithCtx encapsulates a pending channel reservation. This wrapper
@@ -88,8 +102,40 @@ type fundingOpenMsg struct {
 	peer *peer
 }
 
+// dualFundingRequestMsg couples an lnwire.DualFundingRequest message with the
+// peer who sent the message. This allows the funding manager to queue a
+// response directly to the peer, progressing the funding workflow.
+type dualFundingRequestMsg struct {
+	msg  *lnwire.DualFundingRequest
+	peer *peer
+}
+
+// dualFundingResponseMsg couples an lnwire.DualFundingResponse message with
+// the peer who sent the message. This allows the funding manager to queue a
+// response directly to the peer, progressing the funding workflow.
+type dualFundingResponseMsg struct {
+	msg  *lnwire.DualFundingResponse
+	peer *peer
+}
+
+// dualFundingCompleteMsg couples an lnwire.DualFundingComplete message with
+// the peer who sent the message. This allows the funding manager to queue a
+// response directly to the peer, progressing the funding workflow.
+type dualFundingCompleteMsg struct {
+	msg  *lnwire.DualFundingComplete
+	peer *peer
+}
+
+// dualFundingSignCompleteMsg couples an lnwire.DualFundingSignComplete
+// message with the peer who sent the message. This allows the funding manager
+// to finalize the funding workflow with the source peer.
+type dualFundingSignCompleteMsg struct {
+	msg  *lnwire.DualFundingSignComplete
+	peer *peer
+}
+
 // pendingChannels is a map instantiated per-peer which tracks all active
-// pending single funded channels indexed by their pending channel identifier.
+// pending funded channels indexed by their pending channel identifier.
 type pendingChannels map[uint64]*reservationWithCtx
 
 // fundingManager acts as an orchestrator/bridge between the wallet's
@@ -110,9 +156,23 @@ type fundingManager struct {
 	resMtx             sync.RWMutex
 	activeReservations map[int32]pendingChannels
 
+	// restoredChans houses all the channels which were pending when the
+	// daemon was last shutdown. These channels are waiting for their
+	// funding transaction to reach a sufficient number of confirmations.
+	// This map is also guarded by resMtx.
+	restoredChans map[wire.OutPoint]*channeldb.OpenChannel
+
+	// server is the daemon's server, which is used to locate the peer a
+	// restored pending channel is to be handed off to once it's open.
+	server *server
+
 	// wallet is the daemon's internal Lightning enabled wallet.
 	wallet *lnwallet.LightningWallet
 
+	// feeEstimator is used to determine the fee rate we propose, and
+	// accept for newly created channels.
+	feeEstimator lnwallet.FeeEstimator
+
 	// fundingMsgs is a channel which receives wrapped wire messages
 	// related to funding workflow from outside peers.
 	fundingMsgs chan interface{}
@@ -131,10 +191,15 @@ type fundingManager struct {
 
 // newFundingManager creates and initializes a new instance of the
 // fundingManager.
-func newFundingManager(w *lnwallet.LightningWallet) *fundingManager {
+func newFundingManager(s *server, w *lnwallet.LightningWallet,
+	feeEstimator lnwallet.FeeEstimator) *fundingManager {
+
 	return &fundingManager{
 		activeReservations: make(map[int32]pendingChannels),
+		restoredChans:      make(map[wire.OutPoint]*channeldb.OpenChannel),
+		server:             s,
 		wallet:             w,
+		feeEstimator:       feeEstimator,
 		fundingMsgs:        make(chan interface{}, msgBufferSize),
 		fundingRequests:    make(chan *initFundingMsg, msgBufferSize),
 		queries:            make(chan interface{}, 1),
@@ -151,6 +216,19 @@ func (f *fundingManager) Start() error {
 
 	fndgLog.Infof("funding manager running")
 
+	// Before accepting any new funding requests, re-arm the confirmation
+	// notifications of all channels which were still pending when we last
+	// shutdown.
+	pendingChans, err := f.wallet.ChannelDB.FetchPendingChannels()
+	if err != nil {
+		return err
+	}
+	for _, pendingChan := range pendingChans {
+		if err := f.restorePendingChannel(pendingChan); err != nil {
+			return err
+		}
+	}
+
 	f.wg.Add(1) // TODO(roasbeef): tune
 	go f.reservationCoordinator()
 
@@ -173,6 +251,240 @@ func (f *fundingManager) Stop() error {
 	return nil
 }
 
+// restorePendingChannel resumes the tracking of a channel which was still
+// pending when the daemon was last shutdown. A confirmation notification is
+// registered for the channel's funding transaction, and once it's
+// sufficiently confirmed, the channel is handed off to the remote peer if
+// they're currently connected. Otherwise, the channel will be loaded as
+// active the next time the peer connects.
+func (f *fundingManager) restorePendingChannel(pendingChan *channeldb.PendingChannel) error {
+	chanPoint := pendingChan.ChanPoint
+	nodeID := wire.ShaHash(pendingChan.TheirLNID)
+
+	openChans, err := f.wallet.ChannelDB.FetchOpenChannels(&nodeID)
+	if err != nil {
+		return err
+	}
+
+	var dbChan *channeldb.OpenChannel
+	for _, openChan := range openChans {
+		if *openChan.ChanID == chanPoint {
+			dbChan = openChan
+			break
+		}
+	}
+
+	// If the state of the channel can't be found, then the pending entry
+	// is stale, so we remove it.
+	if dbChan == nil {
+		fndgLog.Warnf("Unable to find state for pending "+
+			"ChannelPoint(%v), removing", chanPoint)
+		return f.wallet.ChannelDB.DeletePendingChannel(&chanPoint)
+	}
+
+	confNtfn, err := f.server.chainNotifier.RegisterConfirmationsNtfn(
+		&chanPoint.Hash, uint32(pendingChan.NumConfs))
+	if err != nil {
+		return err
+	}
+
+	fndgLog.Infof("Restored pending ChannelPoint(%v), waiting for "+
+		"funding tx to reach %v confirmations", chanPoint,
+		pendingChan.NumConfs)
+
+	f.resMtx.Lock()
+	f.restoredChans[chanPoint] = dbChan
+	f.resMtx.Unlock()
+
+	f.wg.Add(1)
+	go f.waitForRestoredChannel(dbChan, confNtfn, pendingChan.NumConfs)
+
+	return nil
+}
+
+// waitForRestoredChannel waits for the funding transaction of a restored
+// pending channel to reach its required number of confirmations. Once the
+// channel is open, it's sent to the remote peer if they're currently
+// connected, and registered with the routing manager.
+//
+// NOTE: This MUST be run as a goroutine.
+func (f *fundingManager) waitForRestoredChannel(dbChan *channeldb.OpenChannel,
+	confNtfn *chainntnfs.ConfirmationEvent, numConfs uint16) {
+
+	defer f.wg.Done()
+
+	chanPoint := *dbChan.ChanID
+
+	// If the funding transaction is re-org'd out of the chain before it's
+	// sufficiently confirmed, then we re-register, and continue to wait.
+	for {
+		select {
+		case _, ok := <-confNtfn.Confirmed:
+			// A falsey value indicates the notifier is shutting
+			// down, so the channel remains pending.
+			if !ok {
+				return
+			}
+		case depth, ok := <-confNtfn.NegativeConf:
+			if !ok {
+				return
+			}
+
+			fndgLog.Warnf("Funding tx of pending ChannelPoint(%v) "+
+				"re-org'd out at depth %v", chanPoint, depth)
+
+			var err error
+			confNtfn, err = f.server.chainNotifier.RegisterConfirmationsNtfn(
+				&chanPoint.Hash, uint32(numConfs))
+			if err != nil {
+				fndgLog.Errorf("unable to register for "+
+					"confirmation of ChannelPoint(%v): %v",
+					chanPoint, err)
+				return
+			}
+			continue
+		case <-f.quit:
+			return
+		}
+
+		break
+	}
+
+	// The funding transaction has been sufficiently confirmed, so the
+	// channel is no longer pending.
+	err := f.wallet.ChannelDB.DeletePendingChannel(&chanPoint)
+	if err != nil {
+		fndgLog.Errorf("unable to mark ChannelPoint(%v) as open: %v",
+			chanPoint, err)
+		return
+	}
+
+	f.resMtx.Lock()
+	delete(f.restoredChans, chanPoint)
+	f.resMtx.Unlock()
+
+	fndgLog.Infof("Restored ChannelPoint(%v) is now open", chanPoint)
+
+	nodeID := wire.ShaHash(dbChan.TheirLNID)
+	go f.watchForFundingReorg(chanPoint, nodeID, numConfs)
+
+	// If the peer isn't currently connected, then there's nothing left to
+	// do, as the channel will be loaded from disk once they reconnect.
+	// TODO(roasbeef): if we're the initiator of a single funder channel,
+	// then the remote peer is still awaiting an open proof.
+	targetPeer := f.findPeer(&nodeID)
+	if targetPeer == nil {
+		return
+	}
+
+	openChan, err := lnwallet.NewLightningChannel(f.wallet.Signer,
+		f.server.bio, f.server.chainNotifier, dbChan)
+	if err != nil {
+		fndgLog.Errorf("unable to create channel for "+
+			"ChannelPoint(%v): %v", chanPoint, err)
+		return
+	}
+
+	select {
+	case targetPeer.newChannels <- openChan:
+	case <-targetPeer.quit:
+		return
+	case <-f.quit:
+		return
+	}
+
+	// Register the new link with the L3 routing manager so this new
+	// channel can be utilized during path finding.
+	vertex := hex.EncodeToString(targetPeer.identityPub.SerializeCompressed())
+	f.server.routingMgr.OpenChannel(
+		graph.NewID(vertex),
+		graph.NewEdgeID(chanPoint.String()),
+		&rt.ChannelInfo{
+			Cpt: int64(dbChan.Capacity),
+		},
+	)
+}
+
+// watchForFundingReorg watches for the funding transaction of a newly opened
+// channel to be re-org'd out of the main chain. In this case the channel is
+// rolled back to the pending state: it's marked as pending on disk, removed
+// from the remote peer if they're connected, and finally handed off to the
+// peer once again after its funding transaction has been re-confirmed.
+//
+// NOTE: This MUST be run as a goroutine.
+func (f *fundingManager) watchForFundingReorg(chanPoint wire.OutPoint,
+	nodeID wire.ShaHash, numConfs uint16) {
+
+	// The funding transaction has already been confirmed, so the
+	// confirmation itself is of no interest. We only care about a possible
+	// negative confirmation.
+	confNtfn, err := f.server.chainNotifier.RegisterConfirmationsNtfn(
+		&chanPoint.Hash, 1)
+	if err != nil {
+		fndgLog.Errorf("unable to watch ChannelPoint(%v) for "+
+			"re-orgs: %v", chanPoint, err)
+		return
+	}
+
+	var depth int32
+	select {
+	case reorgDepth, ok := <-confNtfn.NegativeConf:
+		if !ok {
+			return
+		}
+		depth = reorgDepth
+	case <-f.quit:
+		return
+	}
+
+	fndgLog.Warnf("Funding tx of ChannelPoint(%v) re-org'd out at depth "+
+		"%v, rolling back to pending", chanPoint, depth)
+
+	// First, mark the channel as pending on disk, this ensures the
+	// channel isn't loaded as active if the peer reconnects.
+	pendingChan := &channeldb.PendingChannel{
+		ChanPoint: chanPoint,
+		TheirLNID: nodeID,
+		NumConfs:  numConfs,
+	}
+	if err := f.wallet.ChannelDB.AddPendingChannel(pendingChan); err != nil {
+		fndgLog.Errorf("unable to mark ChannelPoint(%v) as "+
+			"pending: %v", chanPoint, err)
+		return
+	}
+
+	// Next, deactivate the channel if the peer is currently connected.
+	// TODO(roasbeef): also remove the channel from the routing manager.
+	if targetPeer := f.findPeer(&nodeID); targetPeer != nil {
+		select {
+		case targetPeer.reorgedChannels <- chanPoint:
+		case <-targetPeer.quit:
+		case <-f.quit:
+			return
+		}
+	}
+
+	// Finally, track the channel as any other pending channel restored
+	// from disk, so it's re-opened once the funding transaction is
+	// re-confirmed.
+	if err := f.restorePendingChannel(pendingChan); err != nil {
+		fndgLog.Errorf("unable to restore pending ChannelPoint(%v): %v",
+			chanPoint, err)
+	}
+}
+
+// findPeer returns the currently connected peer with the passed identity, or
+// nil if no such peer is connected.
+func (f *fundingManager) findPeer(nodeID *wire.ShaHash) *peer {
+	for _, p := range f.server.Peers() {
+		if p.lightningID == *nodeID {
+			return p
+		}
+	}
+
+	return nil
+}
+
 type numPendingReq struct {
 	resp chan uint32
 }
@@ -232,6 +544,14 @@ out:
 				f.handleFundingSignComplete(fmsg)
 			case *fundingOpenMsg:
 				f.handleFundingOpen(fmsg)
+			case *dualFundingRequestMsg:
+				f.handleDualFundingRequest(fmsg)
+			case *dualFundingResponseMsg:
+				f.handleDualFundingResponse(fmsg)
+			case *dualFundingCompleteMsg:
+				f.handleDualFundingComplete(fmsg)
+			case *dualFundingSignCompleteMsg:
+				f.handleDualFundingSignComplete(fmsg)
 			}
 		case req := <-f.fundingRequests:
 			f.handleInitFundingMsg(req)
@@ -253,9 +573,14 @@ out:
 // handleNumPending handles a request for the total number of pending channels.
 func (f *fundingManager) handleNumPending(msg *numPendingReq) {
 	var numPending uint32
+
+	f.resMtx.RLock()
 	for _, peerChannels := range f.activeReservations {
 		numPending += uint32(len(peerChannels))
 	}
+	numPending += uint32(len(f.restoredChans))
+	f.resMtx.RUnlock()
+
 	msg.resp <- numPending
 }
 
@@ -264,6 +589,8 @@ func (f *fundingManager) handleNumPending(msg *numPendingReq) {
 // workflow (funding txn confirmation).
 func (f *fundingManager) handlePendingChannels(msg *pendingChansReq) {
 	var pendingChannels []*pendingChannel
+
+	f.resMtx.RLock()
 	for peerID, peerChannels := range f.activeReservations {
 		for _, pendingChan := range peerChannels {
 			peer := pendingChan.peer
@@ -282,9 +609,60 @@ func (f *fundingManager) handlePendingChannels(msg *pendingChansReq) {
 			pendingChannels = append(pendingChannels, pendingChan)
 		}
 	}
+
+	// Channels restored from disk are no longer associated with an active
+	// peer, so they're reported without a peer ID.
+	for chanPoint, dbChan := range f.restoredChans {
+		chanPoint := chanPoint
+		pendingChan := &pendingChannel{
+			lightningID:   dbChan.TheirLNID,
+			channelPoint:  &chanPoint,
+			capacity:      dbChan.Capacity,
+			localBalance:  dbChan.OurBalance,
+			remoteBalance: dbChan.TheirBalance,
+		}
+		pendingChannels = append(pendingChannels, pendingChan)
+	}
+	f.resMtx.RUnlock()
+
 	msg.resp <- pendingChannels
 }
 
+// checkProposedFeeRate ensures that the fee rate proposed by the initiator of
+// a funding workflow isn't too far below our own estimate, as the proposed fee
+// rate is used for the funding transaction, and as the minimum fee rate for
+// the closure of the channel.
+func (f *fundingManager) checkProposedFeeRate(feePerKb btcutil.Amount) error {
+	feePerByte, err := f.feeEstimator.EstimateFeePerByte(fundingConfTarget)
+	if err != nil {
+		return fmt.Errorf("unable to estimate fee rate: %v", err)
+	}
+
+	minFeePerKb := (feePerByte * 1000) / minFeeRateDivisor
+	if feePerKb < minFeePerKb {
+		return fmt.Errorf("proposed fee rate of %v sat/kb is below our "+
+			"minimum of %v sat/kb", int64(feePerKb), int64(minFeePerKb))
+	}
+
+	return nil
+}
+
+// commitConstraints sets the limits on the HTLC's the remote peer may offer us
+// within the channel being created by the passed reservation, as specified
+// within the config. Unless configured otherwise, the remote peer may offer
+// HTLC's up to the full capacity of the channel.
+func commitConstraints(reservation *lnwallet.ChannelReservation,
+	capacity btcutil.Amount) error {
+
+	maxValueInFlight := btcutil.Amount(cfg.MaxValueInFlight)
+	if maxValueInFlight == 0 {
+		maxValueInFlight = capacity
+	}
+
+	return reservation.CommitConstraints(cfg.MaxAcceptedHTLCs,
+		maxValueInFlight, btcutil.Amount(cfg.HtlcMinimum))
+}
+
 // processFundingRequest sends a message to the fundingManager allowing it to
 // intiate the new funding workflow with the source peer.
 func (f *fundingManager) processFundingRequest(msg *lnwire.SingleFundingRequest, peer *peer) {
@@ -302,8 +680,19 @@ func (f *fundingManager) handleFundingRequest(fmsg *fundingRequestMsg) {
 	delay := msg.CsvDelay
 
 	// TODO(roasbeef): error if funding flow already ongoing
-	fndgLog.Infof("Recv'd fundingRequest(amt=%v, delay=%v, pendingId=%v) "+
-		"from peerID(%v)", amt, delay, msg.ChannelID, fmsg.peer.id)
+	fndgLog.Infof("Recv'd fundingRequest(amt=%v, delay=%v, pendingId=%v, "+
+		"feePerKb=%v) from peerID(%v)", amt, delay, msg.ChannelID,
+		int64(msg.FeePerKb), fmsg.peer.id)
+
+	// The fee rate proposed by the initiator becomes the minimum fee rate
+	// for the closure of the channel, so we reject proposals which are
+	// too far below our own estimate.
+	if err := f.checkProposedFeeRate(msg.FeePerKb); err != nil {
+		// TODO(roasbeef): push ErrorGeneric message
+		fndgLog.Errorf("Rejecting fundingRequest: %v", err)
+		fmsg.peer.Disconnect()
+		return
+	}
 
 	// Attempt to initialize a reservation within the wallet. If the wallet
 	// has insufficient resources to create the channel, then the reservation
@@ -311,13 +700,20 @@ func (f *fundingManager) handleFundingRequest(fmsg *fundingRequestMsg) {
 	// side of a single funder workflow, we don't commit any funds to the
 	// channel ourselves.
 	// TODO(roasbeef): passing num confs 1 is irrelevant here, make signed?
-	reservation, err := f.wallet.InitChannelReservation(amt, 0, fmsg.peer.lightningID, 1, delay)
+	reservation, err := f.wallet.InitChannelReservation(amt, 0,
+		fmsg.peer.lightningID, 1, delay, msg.FeePerKb)
 	if err != nil {
 		// TODO(roasbeef): push ErrorGeneric message
 		fndgLog.Errorf("Unable to initialize reservation: %v", err)
 		fmsg.peer.Disconnect()
 		return
 	}
+	if err := commitConstraints(reservation, amt); err != nil {
+		fndgLog.Errorf("Unable to set channel constraints: %v", err)
+		reservation.Cancel()
+		fmsg.peer.Disconnect()
+		return
+	}
 
 	// Once the reservation has been created succesfully, we add it to this
 	// peers map of pending reservations to track this particular reservation
@@ -345,6 +741,10 @@ func (f *fundingManager) handleFundingRequest(fmsg *fundingRequestMsg) {
 		CommitKey:       msg.CommitmentKey,
 		DeliveryAddress: addrs[0],
 		CsvDelay:        delay,
+
+		MaxAcceptedHTLCs: msg.MaxAcceptedHTLCs,
+		MaxValueInFlight: msg.MaxValueInFlight,
+		HtlcMinimum:      msg.HtlcMinimum,
 	}
 	if err := reservation.ProcessSingleContribution(contribution); err != nil {
 		fndgLog.Errorf("unable to add contribution reservation: %v", err)
@@ -365,6 +765,8 @@ func (f *fundingManager) handleFundingRequest(fmsg *fundingRequestMsg) {
 	fundingResp := lnwire.NewSingleFundingResponse(msg.ChannelID,
 		ourContribution.RevocationKey, ourContribution.CommitKey,
 		ourContribution.MultiSigKey, ourContribution.CsvDelay,
+		ourContribution.MaxAcceptedHTLCs,
+		ourContribution.MaxValueInFlight, ourContribution.HtlcMinimum,
 		deliveryScript)
 
 	fmsg.peer.queueMsg(fundingResp, nil)
@@ -406,6 +808,10 @@ func (f *fundingManager) handleFundingResponse(fmsg *fundingResponseMsg) {
 		DeliveryAddress: addrs[0],
 		RevocationKey:   msg.RevocationKey,
 		CsvDelay:        msg.CsvDelay,
+
+		MaxAcceptedHTLCs: msg.MaxAcceptedHTLCs,
+		MaxValueInFlight: msg.MaxValueInFlight,
+		HtlcMinimum:      msg.HtlcMinimum,
 	}
 	if err := resCtx.reservation.ProcessContribution(contribution); err != nil {
 		fndgLog.Errorf("Unable to process contribution from %v: %v",
@@ -551,8 +957,8 @@ func (f *fundingManager) handleFundingSignComplete(fmsg *fundingSignCompleteMsg)
 	// TODO(roasbeef): semaphore to limit active chan open goroutines
 	go func() {
 		select {
-		// TODO(roasbeef): need to persist pending broadcast channels,
-		// send chan open proof during scan of blocks mined while down.
+		// TODO(roasbeef): send chan open proof during scan of blocks
+		// mined while down.
 		case openChan := <-resCtx.reservation.DispatchChan():
 			// This reservation is no longer pending as the funding
 			// transaction has been fully confirmed.
@@ -563,6 +969,12 @@ func (f *fundingManager) handleFundingSignComplete(fmsg *fundingSignCompleteMsg)
 			fndgLog.Infof("ChannelPoint(%v) with peerID(%v) is now active",
 				fundingPoint, fmsg.peer.id)
 
+			// Roll the channel back to pending if its funding
+			// transaction is later re-org'd out of the chain.
+			go f.watchForFundingReorg(*fundingPoint,
+				fmsg.peer.lightningID,
+				resCtx.reservation.NumConfsToOpen())
+
 			// Now that the channel is open, we need to notify a
 			// number of parties of this event.
 
@@ -654,6 +1066,9 @@ func (f *fundingManager) handleFundingOpen(fmsg *fundingOpenMsg) {
 	fndgLog.Infof("FundingOpen: ChannelPoint(%v) with peerID(%v) is now open",
 		resCtx.reservation.FundingOutpoint, fmsg.peer.id)
 
+	go f.watchForFundingReorg(*resCtx.reservation.FundingOutpoint(),
+		fmsg.peer.lightningID, resCtx.reservation.NumConfsToOpen())
+
 	// Notify the L3 routing manager of the newly active channel link.
 	capacity := int64(resCtx.reservation.OurContribution().FundingAmount +
 		resCtx.reservation.TheirContribution().FundingAmount)
@@ -692,18 +1107,43 @@ func (f *fundingManager) handleInitFundingMsg(msg *initFundingMsg) {
 	numConfs := msg.numConfs
 	// TODO(roasbeef): add delay
 
+	// As the initiator pays the fee for the initial commitment
+	// transaction, we must contribute funds of our own in order to ask
+	// the remote peer to contribute to the channel.
+	if remoteAmt != 0 && localAmt == 0 {
+		msg.err <- fmt.Errorf("a dual funded channel requires a " +
+			"non-zero local funding amount")
+		return
+	}
+
+	// Query the fee estimator for the fee rate we'll propose to the
+	// remote peer. This fee rate is used for the funding transaction, and
+	// as the minimum fee rate for the eventual closure of the channel.
+	feePerByte, err := f.feeEstimator.EstimateFeePerByte(fundingConfTarget)
+	if err != nil {
+		msg.err <- err
+		return
+	}
+	feePerKb := feePerByte * 1000
+
 	fndgLog.Infof("Initiating fundingRequest(localAmt=%v, remoteAmt=%v, "+
-		"capacity=%v, numConfs=%v)", localAmt, remoteAmt, capacity, numConfs)
+		"capacity=%v, numConfs=%v, feePerKb=%v)", localAmt, remoteAmt,
+		capacity, numConfs, int64(feePerKb))
 
 	// Initialize a funding reservation with the local wallet. If the
 	// wallet doesn't have enough funds to commit to this channel, then
 	// the request will fail, and be aborted.
 	reservation, err := f.wallet.InitChannelReservation(capacity, localAmt,
-		nodeID, uint16(numConfs), 4)
+		nodeID, uint16(numConfs), 4, feePerKb)
 	if err != nil {
 		msg.err <- err
 		return
 	}
+	if err := commitConstraints(reservation, capacity); err != nil {
+		reservation.Cancel()
+		msg.err <- err
+		return
+	}
 
 	// Obtain a new pending channel ID which is used to track this
 	// reservation throughout its lifetime.
@@ -739,18 +1179,454 @@ func (f *fundingManager) handleInitFundingMsg(msg *initFundingMsg) {
 
 	fndgLog.Infof("Starting funding workflow with for pendingID(%v)", chanID)
 
+	// If the remote peer is to contribute funds to the channel as well,
+	// then we kick off a dual funder workflow, presenting our inputs to
+	// the funding transaction along with the amount we'd like the remote
+	// peer to contribute.
+	if remoteAmt != 0 {
+		fundingReq := lnwire.NewDualFundingRequest(
+			chanID,
+			msg.channelType,
+			msg.coinType,
+			feePerKb,
+			localAmt,
+			remoteAmt,
+			contribution.CsvDelay,
+			contribution.MaxAcceptedHTLCs,
+			contribution.MaxValueInFlight,
+			contribution.HtlcMinimum,
+			contribution.CommitKey,
+			contribution.MultiSigKey,
+			deliveryScript,
+			contribution.Inputs,
+			contribution.ChangeOutputs,
+		)
+		msg.peer.queueMsg(fundingReq, nil)
+		return
+	}
+
 	// TODO(roasbeef): add FundingRequestFromContribution func
-	// TODO(roasbeef): need to set fee/kb
 	fundingReq := lnwire.NewSingleFundingRequest(
 		chanID,
 		msg.channelType,
 		msg.coinType,
-		0, // TODO(roasbeef): grab from fee estimation model
+		feePerKb,
 		capacity,
 		contribution.CsvDelay,
+		contribution.MaxAcceptedHTLCs,
+		contribution.MaxValueInFlight,
+		contribution.HtlcMinimum,
 		contribution.CommitKey,
 		contribution.MultiSigKey,
 		deliveryScript,
 	)
 	msg.peer.queueMsg(fundingReq, nil)
 }
+
+// processDualFundingRequest sends a message to the fundingManager allowing it
+// to initiate a new dual funder workflow with the source peer.
+func (f *fundingManager) processDualFundingRequest(msg *lnwire.DualFundingRequest, peer *peer) {
+	f.fundingMsgs <- &dualFundingRequestMsg{msg, peer}
+}
+
+// handleDualFundingRequest creates an initial 'ChannelReservation' within the
+// wallet which commits the amount requested by the initiator to the channel,
+// then responds to the source peer with our contribution to the channel
+// including our inputs to the funding transaction.
+func (f *fundingManager) handleDualFundingRequest(fmsg *dualFundingRequestMsg) {
+	msg := fmsg.msg
+	theirAmt := msg.FundingAmount
+	ourAmt := msg.RemoteFundingAmount
+	capacity := theirAmt + ourAmt
+	delay := msg.CsvDelay
+
+	fndgLog.Infof("Recv'd dualFundingRequest(theirAmt=%v, ourAmt=%v, "+
+		"delay=%v, pendingId=%v, feePerKb=%v) from peerID(%v)", theirAmt,
+		ourAmt, delay, msg.ChannelID, int64(msg.FeePerKb), fmsg.peer.id)
+
+	// The fee rate proposed by the initiator becomes the minimum fee rate
+	// for the closure of the channel, so we reject proposals which are
+	// too far below our own estimate.
+	if err := f.checkProposedFeeRate(msg.FeePerKb); err != nil {
+		// TODO(roasbeef): push ErrorGeneric message
+		fndgLog.Errorf("Rejecting dualFundingRequest: %v", err)
+		fmsg.peer.Disconnect()
+		return
+	}
+
+	// Attempt to initialize a reservation within the wallet which commits
+	// the requested amount to the channel. If the wallet has insufficient
+	// funds to do so, then the reservation attempt will be rejected.
+	// TODO(roasbeef): policy for the amount we're willing to commit to
+	// channels initiated by remote peers.
+	reservation, err := f.wallet.InitDualFundResponderReservation(capacity,
+		ourAmt, fmsg.peer.lightningID, 1, delay, msg.FeePerKb)
+	if err != nil {
+		// TODO(roasbeef): push ErrorGeneric message
+		fndgLog.Errorf("Unable to initialize reservation: %v", err)
+		fmsg.peer.Disconnect()
+		return
+	}
+	if err := commitConstraints(reservation, capacity); err != nil {
+		fndgLog.Errorf("Unable to set channel constraints: %v", err)
+		reservation.Cancel()
+		fmsg.peer.Disconnect()
+		return
+	}
+
+	f.resMtx.Lock()
+	if _, ok := f.activeReservations[fmsg.peer.id]; !ok {
+		f.activeReservations[fmsg.peer.id] = make(pendingChannels)
+	}
+	f.activeReservations[fmsg.peer.id][msg.ChannelID] = &reservationWithCtx{
+		reservation: reservation,
+		peer:        fmsg.peer,
+	}
+	f.resMtx.Unlock()
+
+	// With our portion of the reservation initialized, record the
+	// initiator's contribution to the channel. At this point we don't yet
+	// know the initiator's revocation key, so their contribution is only
+	// recorded, which allows us to derive the revocation key for our
+	// version of the commitment transaction.
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(msg.DeliveryPkScript, activeNetParams.Params)
+	if err != nil {
+		fndgLog.Errorf("Unable to extract addresses from script: %v", err)
+		return
+	}
+	contribution := &lnwallet.ChannelContribution{
+		FundingAmount:   theirAmt,
+		Inputs:          msg.Inputs,
+		ChangeOutputs:   msg.ChangeOutputs,
+		MultiSigKey:     msg.ChannelDerivationPoint,
+		CommitKey:       msg.CommitmentKey,
+		DeliveryAddress: addrs[0],
+		CsvDelay:        delay,
+
+		MaxAcceptedHTLCs: msg.MaxAcceptedHTLCs,
+		MaxValueInFlight: msg.MaxValueInFlight,
+		HtlcMinimum:      msg.HtlcMinimum,
+	}
+	if err := reservation.ProcessSingleContribution(contribution); err != nil {
+		fndgLog.Errorf("unable to add contribution reservation: %v", err)
+		fmsg.peer.Disconnect()
+		return
+	}
+
+	fndgLog.Infof("Sending dualFundingResp for pendingID(%v)", msg.ChannelID)
+
+	// Respond with our contribution, including the inputs and change
+	// outputs we contribute to the funding transaction.
+	ourContribution := reservation.OurContribution()
+	deliveryScript, err := txscript.PayToAddrScript(ourContribution.DeliveryAddress)
+	if err != nil {
+		fndgLog.Errorf("unable to convert address to pkscript: %v", err)
+		return
+	}
+	fundingResp := lnwire.NewDualFundingResponse(msg.ChannelID,
+		ourContribution.FundingAmount, ourContribution.RevocationKey,
+		ourContribution.CommitKey, ourContribution.MultiSigKey,
+		ourContribution.CsvDelay, ourContribution.MaxAcceptedHTLCs,
+		ourContribution.MaxValueInFlight, ourContribution.HtlcMinimum,
+		deliveryScript, ourContribution.Inputs,
+		ourContribution.ChangeOutputs)
+
+	fmsg.peer.queueMsg(fundingResp, nil)
+}
+
+// processDualFundingResponse sends a message to the fundingManager allowing it
+// to continue the second phase of a dual funder workflow with the target
+// peer.
+func (f *fundingManager) processDualFundingResponse(msg *lnwire.DualFundingResponse, peer *peer) {
+	f.fundingMsgs <- &dualFundingResponseMsg{msg, peer}
+}
+
+// handleDualFundingResponse processes the responder's contribution to a dual
+// funded channel. Once processed, we're able to construct the funding
+// transaction, and both commitment transactions. Our signatures for our inputs
+// to the funding transaction, and for the responder's version of the
+// commitment transaction are then sent to the remote peer.
+func (f *fundingManager) handleDualFundingResponse(fmsg *dualFundingResponseMsg) {
+	msg := fmsg.msg
+	sourcePeer := fmsg.peer
+
+	f.resMtx.RLock()
+	resCtx := f.activeReservations[fmsg.peer.id][msg.ChannelID]
+	f.resMtx.RUnlock()
+
+	fndgLog.Infof("Recv'd dualFundingResponse for pendingID(%v)",
+		msg.ChannelID)
+
+	// The responder must commit exactly the amount we requested, otherwise
+	// our view of the channel's balances would diverge from theirs.
+	requestedAmt := resCtx.reservation.TheirContribution().FundingAmount
+	if msg.FundingAmount != requestedAmt {
+		err := fmt.Errorf("responder committed %v to the channel, "+
+			"requested %v", msg.FundingAmount, requestedAmt)
+		fndgLog.Errorf("Invalid dualFundingResponse from %v: %v",
+			sourcePeer, err)
+		fmsg.peer.Disconnect()
+		resCtx.err <- err
+		return
+	}
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(msg.DeliveryPkScript, activeNetParams.Params)
+	if err != nil {
+		fndgLog.Errorf("Unable to extract addresses from script: %v", err)
+		resCtx.err <- err
+		return
+	}
+	contribution := &lnwallet.ChannelContribution{
+		FundingAmount:   msg.FundingAmount,
+		Inputs:          msg.Inputs,
+		ChangeOutputs:   msg.ChangeOutputs,
+		MultiSigKey:     msg.ChannelDerivationPoint,
+		CommitKey:       msg.CommitmentKey,
+		DeliveryAddress: addrs[0],
+		RevocationKey:   msg.RevocationKey,
+		CsvDelay:        msg.CsvDelay,
+
+		MaxAcceptedHTLCs: msg.MaxAcceptedHTLCs,
+		MaxValueInFlight: msg.MaxValueInFlight,
+		HtlcMinimum:      msg.HtlcMinimum,
+	}
+	if err := resCtx.reservation.ProcessContribution(contribution); err != nil {
+		fndgLog.Errorf("Unable to process contribution from %v: %v",
+			sourcePeer, err)
+		fmsg.peer.Disconnect()
+		resCtx.err <- err
+		return
+	}
+
+	// With the funding transaction assembled, we can now send over the
+	// funding outpoint, the scripts for our inputs, and our signature for
+	// their version of the commitment transaction.
+	outPoint := resCtx.reservation.FundingOutpoint()
+	fundingScripts, sig := resCtx.reservation.OurSignatures()
+	commitSig, err := btcec.ParseSignature(sig, btcec.S256())
+	if err != nil {
+		fndgLog.Errorf("Unable to parse signature: %v", err)
+		resCtx.err <- err
+		return
+	}
+
+	// Register a new barrier for this channel to properly synchronize with
+	// the peer's readHandler once the channel is open.
+	fmsg.peer.barrierInits <- *outPoint
+
+	fndgLog.Infof("Generated ChannelPoint(%v) for pendingID(%v)",
+		outPoint, msg.ChannelID)
+
+	revocationKey := resCtx.reservation.OurContribution().RevocationKey
+	fundingComplete := lnwire.NewDualFundingComplete(msg.ChannelID,
+		outPoint, commitSig, revocationKey,
+		toWireInputScripts(fundingScripts))
+	sourcePeer.queueMsg(fundingComplete, nil)
+}
+
+// processDualFundingComplete queues a dual funding complete message coupled
+// with the source peer to the fundingManager.
+func (f *fundingManager) processDualFundingComplete(msg *lnwire.DualFundingComplete, peer *peer) {
+	f.fundingMsgs <- &dualFundingCompleteMsg{msg, peer}
+}
+
+// handleDualFundingComplete progresses the funding workflow when the daemon is
+// on the responding side of a dual funder workflow. Once the initiator's
+// signatures have been verified, the funding transaction is broadcast and our
+// own signatures are sent to the remote peer.
+func (f *fundingManager) handleDualFundingComplete(fmsg *dualFundingCompleteMsg) {
+	msg := fmsg.msg
+	chanID := msg.ChannelID
+
+	f.resMtx.RLock()
+	resCtx := f.activeReservations[fmsg.peer.id][chanID]
+	f.resMtx.RUnlock()
+
+	fndgLog.Infof("completing pendingID(%v) with ChannelPoint(%v)",
+		chanID, msg.FundingOutPoint)
+
+	// Now that we know the initiator's revocation key, we can process
+	// their full contribution, allowing us to construct the funding
+	// transaction, and both commitment transactions.
+	contribution := *resCtx.reservation.TheirContribution()
+	contribution.RevocationKey = msg.RevocationKey
+	if err := resCtx.reservation.ProcessContribution(&contribution); err != nil {
+		fndgLog.Errorf("unable to process contribution: %v", err)
+		fmsg.peer.Disconnect()
+		return
+	}
+
+	// Both sides construct the funding transaction independently, so we
+	// ensure we've arrived at the same funding outpoint as the initiator.
+	fundingOut := resCtx.reservation.FundingOutpoint()
+	if *fundingOut != *msg.FundingOutPoint {
+		fndgLog.Errorf("funding outpoint mismatch for pendingID(%v): "+
+			"expected %v, got %v", chanID, fundingOut,
+			msg.FundingOutPoint)
+		fmsg.peer.Disconnect()
+		return
+	}
+
+	// Verify the initiator's signatures for both their inputs to the
+	// funding transaction, and our version of the commitment transaction.
+	// Once verified, the funding transaction is broadcast.
+	theirScripts := fromWireInputScripts(msg.FundingScripts)
+	commitSig := msg.CommitSignature.Serialize()
+	if err := resCtx.reservation.CompleteReservation(theirScripts, commitSig); err != nil {
+		fndgLog.Errorf("unable to complete dual reservation: %v", err)
+		fmsg.peer.Disconnect()
+		return
+	}
+
+	ourScripts, sig := resCtx.reservation.OurSignatures()
+	ourCommitSig, err := btcec.ParseSignature(sig, btcec.S256())
+	if err != nil {
+		fndgLog.Errorf("unable to parse signature: %v", err)
+		return
+	}
+
+	// Register a new barrier for this channel to properly synchronize with
+	// the peer's readHandler once the channel is open.
+	fmsg.peer.barrierInits <- *fundingOut
+
+	fndgLog.Infof("sending dualSignComplete for pendingID(%v) over "+
+		"ChannelPoint(%v)", chanID, fundingOut)
+
+	signComplete := lnwire.NewDualFundingSignComplete(chanID, ourCommitSig,
+		toWireInputScripts(ourScripts))
+	fmsg.peer.queueMsg(signComplete, nil)
+
+	// As we've contributed funds to the channel, we watch the chain for
+	// the funding transaction ourselves rather than waiting for a proof
+	// from the initiator.
+	go f.waitForDualFundedChannel(resCtx, fmsg.peer, chanID)
+}
+
+// processDualFundingSignComplete sends a dual funding sign complete message
+// along with the source peer to the funding manager.
+func (f *fundingManager) processDualFundingSignComplete(msg *lnwire.DualFundingSignComplete, peer *peer) {
+	f.fundingMsgs <- &dualFundingSignCompleteMsg{msg, peer}
+}
+
+// handleDualFundingSignComplete processes the final message received by the
+// initiator of a dual funder workflow. Once the responder's signatures have
+// been verified, the funding transaction is broadcast.
+func (f *fundingManager) handleDualFundingSignComplete(fmsg *dualFundingSignCompleteMsg) {
+	chanID := fmsg.msg.ChannelID
+
+	f.resMtx.RLock()
+	resCtx := f.activeReservations[fmsg.peer.id][chanID]
+	f.resMtx.RUnlock()
+
+	theirScripts := fromWireInputScripts(fmsg.msg.FundingScripts)
+	commitSig := fmsg.msg.CommitSignature.Serialize()
+	if err := resCtx.reservation.CompleteReservation(theirScripts, commitSig); err != nil {
+		fndgLog.Errorf("unable to complete reservation sign complete: %v", err)
+		fmsg.peer.Disconnect()
+		resCtx.err <- err
+		return
+	}
+
+	fundingPoint := resCtx.reservation.FundingOutpoint()
+	fndgLog.Infof("Finalizing pendingID(%v) over ChannelPoint(%v), "+
+		"waiting for channel open on-chain", chanID, fundingPoint)
+
+	resCtx.updates <- &lnrpc.OpenStatusUpdate{
+		Update: &lnrpc.OpenStatusUpdate_ChanPending{
+			ChanPending: &lnrpc.PendingUpdate{
+				Txid: fundingPoint.Hash[:],
+			},
+		},
+	}
+
+	go f.waitForDualFundedChannel(resCtx, fmsg.peer, chanID)
+}
+
+// waitForDualFundedChannel blocks until the funding transaction of a dual
+// funded channel reaches a sufficient number of confirmations. Once it does,
+// the newly open channel is sent to the source peer, and registered with the
+// routing manager. If the workflow was initiated locally, then the caller is
+// notified of the channel's opening.
+//
+// NOTE: This MUST be run as a goroutine.
+func (f *fundingManager) waitForDualFundedChannel(resCtx *reservationWithCtx,
+	p *peer, chanID uint64) {
+
+	fundingPoint := resCtx.reservation.FundingOutpoint()
+
+	select {
+	case openChan := <-resCtx.reservation.DispatchChan():
+		// This reservation is no longer pending as the funding
+		// transaction has been fully confirmed.
+		f.resMtx.Lock()
+		delete(f.activeReservations[p.id], chanID)
+		f.resMtx.Unlock()
+
+		fndgLog.Infof("ChannelPoint(%v) with peerID(%v) is now active",
+			fundingPoint, p.id)
+
+		go f.watchForFundingReorg(*fundingPoint, p.lightningID,
+			resCtx.reservation.NumConfsToOpen())
+
+		p.newChannels <- openChan
+
+		// Register the new link with the L3 routing manager so this
+		// new channel can be utilized during path finding.
+		chanInfo := openChan.StateSnapshot()
+		capacity := int64(chanInfo.LocalBalance + chanInfo.RemoteBalance)
+		vertex := hex.EncodeToString(p.identityPub.SerializeCompressed())
+		p.server.routingMgr.OpenChannel(
+			graph.NewID(vertex),
+			graph.NewEdgeID(fundingPoint.String()),
+			&rt.ChannelInfo{
+				Cpt: capacity,
+			},
+		)
+
+		// Only locally initiated workflows have a caller awaiting
+		// updates.
+		if resCtx.updates == nil {
+			return
+		}
+		resCtx.updates <- &lnrpc.OpenStatusUpdate{
+			Update: &lnrpc.OpenStatusUpdate_ChanOpen{
+				ChanOpen: &lnrpc.ChannelOpenUpdate{
+					ChannelPoint: &lnrpc.ChannelPoint{
+						FundingTxid: fundingPoint.Hash[:],
+						OutputIndex: fundingPoint.Index,
+					},
+				},
+			},
+		}
+	case <-f.quit:
+		return
+	}
+}
+
+// toWireInputScripts converts the passed funding input scripts into their
+// wire representation.
+func toWireInputScripts(scripts []*lnwallet.InputScript) []*lnwire.InputScript {
+	wireScripts := make([]*lnwire.InputScript, len(scripts))
+	for i, script := range scripts {
+		wireScripts[i] = &lnwire.InputScript{
+			Witness:   script.Witness,
+			ScriptSig: script.ScriptSig,
+		}
+	}
+
+	return wireScripts
+}
+
+// fromWireInputScripts converts the passed funding input scripts received
+// over the wire into the form expected by the wallet.
+func fromWireInputScripts(wireScripts []*lnwire.InputScript) []*lnwallet.InputScript {
+	scripts := make([]*lnwallet.InputScript, len(wireScripts))
+	for i, script := range wireScripts {
+		scripts[i] = &lnwallet.InputScript{
+			Witness:   script.Witness,
+			ScriptSig: script.ScriptSig,
+		}
+	}
+
+	return scripts
+}
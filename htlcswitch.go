@@ -2,7 +2,9 @@ package main
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,6 +28,21 @@ const (
 	// htlcQueueSize...
 	// buffer bloat ;)
 	htlcQueueSize = 50
+
+	// htlcCancelDelta is the number of blocks before the expiry of an
+	// incoming HTLC at which we'll cancel the HTLC back to the upstream
+	// peer if we're unable to settle it.
+	htlcCancelDelta = 3
+
+	// finalHTLCExpiry is the number of blocks from the current height
+	// used as the expiry of the HTLC received by the final hop of a
+	// route.
+	finalHTLCExpiry = 20
+
+	// maxPaymentParts is the maximum number of parts an outgoing payment
+	// will be split into when none of the links to the first hop has
+	// enough available bandwidth to carry the entire payment.
+	maxPaymentParts = 8
 )
 
 // link represents a an active channel capable of forwarding HTLC's. Each
@@ -43,6 +60,50 @@ type link struct {
 	peer *peer
 
 	chanPoint *wire.OutPoint
+
+	// policy is the routing policy enforced when forwarding HTLC's over
+	// the link.
+	policyMtx sync.RWMutex
+	policy    *channeldb.ChannelPolicy
+}
+
+// forwardingPolicy returns the routing policy currently enforced when
+// forwarding HTLC's over the link.
+func (l *link) forwardingPolicy() *channeldb.ChannelPolicy {
+	l.policyMtx.RLock()
+	defer l.policyMtx.RUnlock()
+
+	return l.policy
+}
+
+var (
+	// errUnknownLink is returned when an outgoing payment can't be sent
+	// as the switch doesn't have an active link to the first hop.
+	errUnknownLink = errors.New("unable to locate link to first hop")
+
+	// errInsufficientCapacity is returned when none of the links to the
+	// first hop of an outgoing payment has enough available bandwidth to
+	// carry the payment.
+	errInsufficientCapacity = errors.New("insufficient capacity")
+
+	// errPaymentCancelled is returned when the HTLC of an outgoing
+	// payment is cancelled by the remote peer.
+	errPaymentCancelled = errors.New("payment cancelled by remote peer")
+)
+
+// remoteFailure is returned when the HTLC of an outgoing payment has been
+// cancelled by a node along the route. It carries the encrypted failure sent
+// back along the route, which only the sender of the payment is able to
+// decrypt.
+type remoteFailure struct {
+	reason lnwire.OpaqueReason
+}
+
+// Error returns a human readable version of the failure.
+//
+// NOTE: Part of the error interface.
+func (r *remoteFailure) Error() string {
+	return errPaymentCancelled.Error()
 }
 
 // htlcPacket is a wrapper around an lnwire message which adds, times out, or
@@ -53,6 +114,10 @@ type htlcPacket struct {
 
 	dest wire.ShaHash
 
+	// index is the log index of the incoming HTLC this packet refers to
+	// within the remote log of the link it arrived on. Adds forwarded by
+	// a link carry the index of the HTLC being forwarded, while settles
+	// and cancels sent to a link carry the index of the HTLC to remove.
 	index   uint32
 	srcLink wire.OutPoint
 	onion   *sphinx.ProcessedPacket
@@ -60,43 +125,74 @@ type htlcPacket struct {
 	msg lnwire.Message
 	amt btcutil.Amount
 
+	// payHash is the payment hash of the HTLC this packet cancels. As
+	// cancel messages don't carry the payment hash on the wire, it's
+	// used to locate the circuit the cancel should be forwarded over.
+	payHash [32]byte
+
+	// failCode is the reason this node cancelled the HTLC. As only the
+	// link which received the HTLC holds the secret shared with the
+	// sender, the code is encrypted by the link once it cancels the HTLC.
+	// Cancels propagated from downstream instead carry an encrypted
+	// reason within the wire message.
+	failCode lnwire.FailCode
+
+	// bestHeight is the height of the chain as seen by the link which
+	// received the HTLC this packet adds. It's used to ensure the HTLC
+	// forwarded to the next hop doesn't expire too soon.
+	bestHeight uint32
+
+	// preimage is sent upon with the payment preimage once an outgoing
+	// payment has been settled, just before a nil error is sent.
+	preimage chan [32]byte
+
 	err chan error
 }
 
-// circuitKey uniquely identifies an active Sphinx (onion routing) circuit
-// between two open channels. Currently, the rHash of the HTLC which created
-// the circuit is used to uniquely identify each circuit.
-// TODO(roasbeef): need to also add in the settle/clear channel points in order
-// to support fragmenting payments on the link layer: 1 to N, N to N, etc.
+// circuitKey identifies the set of active Sphinx (onion routing) circuits
+// created by HTLC's paying to the same rHash. As each part of a multi-part
+// payment creates its own circuit, several circuits may share a key, in which
+// case they're distinguished by their clear link and outgoing amount.
 type circuitKey [32]byte
 
 // paymentCircuit represents an active Sphinx (onion routing) circuit between
 // two active links within the htlcSwitch. A payment circuit is created once a
 // link forwards an HTLC add request which initites the creation of the ciruit.
 // The onion routing informtion contained within this message is used to
-// identify the settle/clear ends of the circuit. A circuit may be re-used (not
-// torndown) in the case that multiple HTLC's with the send RHash are sent.
+// identify the settle/clear ends of the circuit. The ends of the circuit are
+// identified by the channel points of the links, rather than the links
+// themselves, as circuits restored from disk may outlive the links which
+// created them. Once the HTLC is either settled or cancelled, the circuit is
+// torn down.
 type paymentCircuit struct {
-	// TODO(roasbeef): add reference count so know when to delete?
-	//  * atomic int re
-	//  * due to same r-value being re-used?
-
-	// NOTE: This integer must be used *atomically*.
-	refCount uint32
-
-	// clear is the link the htlcSwitch will forward the HTLC add message
-	// that initiated the circuit to. Once the message is forwarded, the
-	// payment circuit is considered "active" from the POV of the switch as
-	// both the incoming/outgoing channels have the cleared HTLC within
-	// their latest state.
-	clear *link
-
-	// settle is the link the htlcSwitch will forward the HTLC settle it
-	// receives from the outgoing peer to. Once the switch forwards the
-	// settle message to this link, the payment circuit is considered
-	// complete unless the reference count on the circuit is greater than
-	// 1.
-	settle *link
+	// id is the ID assigned to the circuit once persisted, used to remove
+	// the circuit from disk once it has been torn down.
+	id uint64
+
+	// clear is the channel point of the link the htlcSwitch will forward
+	// the HTLC add message that initiated the circuit to. Once the message
+	// is forwarded, the payment circuit is considered "active" from the
+	// POV of the switch as both the incoming/outgoing channels have the
+	// cleared HTLC within their latest state.
+	clear wire.OutPoint
+
+	// settle is the channel point of the link the htlcSwitch will forward
+	// the HTLC settle or cancel it receives from the outgoing peer to.
+	// Once the switch forwards the settle message to this link, the
+	// payment circuit is considered complete.
+	settle wire.OutPoint
+
+	// incomingIndex is the log index of the HTLC received over the settle
+	// link within the remote log of that link. A settle or cancel
+	// forwarded back over the settle link removes the HTLC at this index.
+	incomingIndex uint32
+
+	// incomingAmt is the amount of the HTLC received over the settle
+	// link, and outgoingAmt the amount forwarded over the clear link.
+	// Once the circuit is complete, the difference is recorded as the
+	// fee earned within the forwarding log.
+	incomingAmt btcutil.Amount
+	outgoingAmt btcutil.Amount
 }
 
 // HtlcSwitch is a central messaging bus for all incoming/outgoing HTLC's.
@@ -107,8 +203,9 @@ type paymentCircuit struct {
 // the channel is closed. The switch manages the hand-off process for multi-hop
 // HTLC's, forwarding HTLC's initiated from within the daemon, and additionally
 // splitting up incoming/outgoing HTLC's to a particular interface amongst many
-// links (payment fragmentation).
-// TODO(roasbeef): active sphinx circuits need to be synced to disk
+// links (payment fragmentation). All active payment circuits are written to
+// disk, allowing in-flight multi-hop HTLC's to be settled or cancelled after a
+// restart.
 type htlcSwitch struct {
 	started  int32 // atomic
 	shutdown int32 // atomic
@@ -119,6 +216,12 @@ type htlcSwitch struct {
 	chanIndexMtx sync.RWMutex
 	chanIndex    map[wire.OutPoint]*link
 
+	// pendingLinkPkts houses packets destined for links which aren't
+	// currently active, as may be the case for the settle end of a circuit
+	// restored from disk. Once the link is registered, the pending packets
+	// are delivered. This map is protected by the chanIndexMtx.
+	pendingLinkPkts map[wire.OutPoint][]*htlcPacket
+
 	// interfaces maps a node's ID to the set of links (active channels) we
 	// currently have open with that peer.
 	// TODO(roasbeef): combine w/ onionIndex?
@@ -126,14 +229,26 @@ type htlcSwitch struct {
 	interfaces   map[wire.ShaHash][]*link
 
 	// onionIndex is a secondary index used to properly forward a message
-	// to the next hop within a Sphinx circuit.
+	// to the next hop within a Sphinx circuit. As this index is derived
+	// from the set of active links, it's re-populated as links are
+	// registered.
 	onionMtx   sync.RWMutex
 	onionIndex map[[ripemd160.Size]byte][]*link
 
-	// paymentCircuits maps a circuit key to an active payment circuit
+	// paymentCircuits maps a circuit key to the active payment circuits
 	// amongst two oepn channels. This map is used to properly clear/settle
-	// onion routed payments within the network.
-	paymentCircuits map[circuitKey]*paymentCircuit
+	// onion routed payments within the network. The map mirrors the
+	// circuits stored within the database, and is restored from disk when
+	// the switch starts.
+	paymentCircuits map[circuitKey][]*paymentCircuit
+
+	// db is the database the active payment circuits, along with the
+	// routing policy of each link, are persisted to.
+	db *channeldb.DB
+
+	// defaultPolicy is the routing policy assigned to newly registered
+	// links which don't yet have a policy stored within the database.
+	defaultPolicy *channeldb.ChannelPolicy
 
 	// linkControl is a channel used by connected links to notify the
 	// switch of a non-multi-hop triggered link state update.
@@ -162,14 +277,19 @@ type htlcSwitch struct {
 }
 
 // newHtlcSwitch creates a new htlcSwitch.
-func newHtlcSwitch(gateway []byte, r *routing.RoutingManager) *htlcSwitch {
+func newHtlcSwitch(gateway []byte, r *routing.RoutingManager,
+	db *channeldb.DB, defaultPolicy *channeldb.ChannelPolicy) *htlcSwitch {
+
 	return &htlcSwitch{
 		router:           r,
 		gateway:          gateway,
+		db:               db,
+		defaultPolicy:    defaultPolicy,
 		chanIndex:        make(map[wire.OutPoint]*link),
+		pendingLinkPkts:  make(map[wire.OutPoint][]*htlcPacket),
 		interfaces:       make(map[wire.ShaHash][]*link),
 		onionIndex:       make(map[[ripemd160.Size]byte][]*link),
-		paymentCircuits:  make(map[circuitKey]*paymentCircuit),
+		paymentCircuits:  make(map[circuitKey][]*paymentCircuit),
 		linkControl:      make(chan interface{}),
 		htlcPlex:         make(chan *htlcPacket, htlcQueueSize),
 		outgoingPayments: make(chan *htlcPacket, htlcQueueSize),
@@ -178,11 +298,39 @@ func newHtlcSwitch(gateway []byte, r *routing.RoutingManager) *htlcSwitch {
 }
 
 // Start starts all helper goroutines required for the operation of the switch.
+// Before any links can be registered, all payment circuits which were active
+// when the switch was last stopped are restored from disk.
 func (h *htlcSwitch) Start() error {
 	if !atomic.CompareAndSwapInt32(&h.started, 0, 1) {
 		return nil
 	}
 
+	circuits, err := h.db.FetchAllPaymentCircuits()
+	if err != nil {
+		return err
+	}
+	for _, circuit := range circuits {
+		cKey := circuitKey(circuit.PaymentHash)
+		h.paymentCircuits[cKey] = append(h.paymentCircuits[cKey],
+			&paymentCircuit{
+				id:            circuit.ID,
+				clear:         circuit.ClearChanPoint,
+				settle:        circuit.SettleChanPoint,
+				incomingIndex: circuit.IncomingIndex,
+				incomingAmt:   circuit.IncomingAmt,
+				outgoingAmt:   circuit.OutgoingAmt,
+			})
+
+		hswcLog.Debugf("Restored onion circuit for %x: %v<->%v",
+			cKey[:], circuit.ClearChanPoint,
+			circuit.SettleChanPoint)
+	}
+
+	if len(circuits) > 0 {
+		hswcLog.Infof("Restored %v active payment circuits from disk",
+			len(circuits))
+	}
+
 	h.wg.Add(2)
 	go h.networkAdmin()
 	go h.htlcForwarder()
@@ -203,18 +351,97 @@ func (h *htlcSwitch) Stop() error {
 	return nil
 }
 
-// SendHTLC queues a HTLC packet for forwarding over the designated interface.
-// In the event that the interface has insufficient capacity for the payment,
-// an error is returned. Additionally, if the interface cannot be found, an
-// alternative error is returned.
-func (h *htlcSwitch) SendHTLC(htlcPkt *htlcPacket) error {
+// SendHTLC queues a HTLC packet for forwarding over the designated interface,
+// blocking until the HTLC has been either settled or cancelled. Once settled,
+// the payment preimage revealed by the destination is returned. In the event
+// that the interface has insufficient capacity for the payment, an error is
+// returned. Additionally, if the interface cannot be found, an alternative
+// error is returned.
+func (h *htlcSwitch) SendHTLC(htlcPkt *htlcPacket) ([32]byte, error) {
 	htlcPkt.err = make(chan error, 1)
+	htlcPkt.preimage = make(chan [32]byte, 1)
 
 	h.outgoingPayments <- htlcPkt
 
-	return <-htlcPkt.err
+	if err := <-htlcPkt.err; err != nil {
+		return [32]byte{}, err
+	}
+
+	return <-htlcPkt.preimage, nil
+}
+
+// SplitPayment determines how an outgoing payment of the passed amount should
+// be fragmented amongst the links to the interface identified by dest. If a
+// single link has enough available bandwidth to carry the entire payment,
+// then the payment isn't split. Otherwise, the payment is split into at most
+// maxPaymentParts parts, each sized to the available bandwidth of a distinct
+// link, largest first. Each part is to be sent within its own HTLC. The
+// amounts returned are those to be delivered to the destination, with the
+// bandwidth required by each part accounting for the fees charged along the
+// passed route.
+//
+// NOTE: Only the bandwidth of our own links is known to the switch, so every
+// part is sent along the same route once it leaves the first hop. Splitting
+// therefore doesn't help if the bottleneck lies further along the route.
+// TODO(roasbeef): select a distinct route for each part once the routing
+// table is able to provide several routes along with their capacity.
+func (h *htlcSwitch) SplitPayment(dest wire.ShaHash, amt btcutil.Amount,
+	route *paymentRoute) ([]btcutil.Amount, error) {
+
+	h.interfaceMtx.RLock()
+	links := h.interfaces[dest]
+	h.interfaceMtx.RUnlock()
+	if len(links) == 0 {
+		return nil, errUnknownLink
+	}
+
+	bandwidths := make([]btcutil.Amount, 0, len(links))
+	for _, link := range links {
+		bandwidth := btcutil.Amount(atomic.LoadInt64(&link.availableBandwidth))
+		if bandwidth >= route.sendAmount(amt) {
+			return []btcutil.Amount{amt}, nil
+		}
+		if bandwidth > 0 {
+			bandwidths = append(bandwidths, bandwidth)
+		}
+	}
+	sort.Sort(sort.Reverse(amountSlice(bandwidths)))
+
+	parts := make([]btcutil.Amount, 0, maxPaymentParts)
+	remaining := amt
+	for _, bandwidth := range bandwidths {
+		if len(parts) == maxPaymentParts {
+			break
+		}
+
+		// The part is sized to the largest amount which can be
+		// delivered to the destination once the fees of each hop have
+		// been paid from the link's bandwidth.
+		part := route.deliverAmount(bandwidth)
+		if part == 0 {
+			continue
+		}
+		if part > remaining {
+			part = remaining
+		}
+		parts = append(parts, part)
+
+		remaining -= part
+		if remaining == 0 {
+			return parts, nil
+		}
+	}
+
+	return nil, errInsufficientCapacity
 }
 
+// amountSlice implements sort.Interface, sorting amounts in ascending order.
+type amountSlice []btcutil.Amount
+
+func (a amountSlice) Len() int           { return len(a) }
+func (a amountSlice) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a amountSlice) Less(i, j int) bool { return a[i] < a[j] }
+
 // htlcForwarder is responsible for optimally forwarding (and possibly
 // fragmenting) incoming/outgoing HTLC's amongst all active interfaces and
 // their links. The duties of the forwarder are similar to that of a network
@@ -230,7 +457,7 @@ func (h *htlcSwitch) htlcForwarder() {
 
 	// TODO(roasbeef): cleared vs settled distinction
 	var numUpdates uint64
-	var satSent, satRecv btcutil.Amount
+	var satSent, satRecv, feesEarned btcutil.Amount
 	logTicker := time.NewTicker(10 * time.Second)
 out:
 	for {
@@ -241,10 +468,8 @@ out:
 			chanInterface, ok := h.interfaces[dest]
 			h.interfaceMtx.RUnlock()
 			if !ok {
-				err := fmt.Errorf("Unable to locate link %x",
-					dest[:])
-				hswcLog.Errorf(err.Error())
-				htlcPkt.err <- err
+				hswcLog.Errorf("Unable to locate link %x", dest[:])
+				htlcPkt.err <- errUnknownLink
 				continue
 			}
 
@@ -254,11 +479,13 @@ out:
 			// Handle this send request in a distinct goroutine in
 			// order to avoid a possible deadlock between the htlc
 			// switch and channel's htlc manager.
+			// Payments too large for any single link are split
+			// into parts by the sender via SplitPayment, so each
+			// packet is sent over a single link.
 			for _, link := range chanInterface {
-				// TODO(roasbeef): implement HTLC fragmentation
-				//  * avoid full channel depletion at higher
-				//    level (here) instead of within state
-				//    machine?
+				// TODO(roasbeef): avoid full channel depletion
+				// at higher level (here) instead of within
+				// state machine?
 				if link.availableBandwidth < int64(amt) {
 					continue
 				}
@@ -278,7 +505,7 @@ out:
 			}
 
 			hswcLog.Errorf("Unable to send payment, insufficient capacity")
-			htlcPkt.err <- fmt.Errorf("Insufficient capacity")
+			htlcPkt.err <- errInsufficientCapacity
 		case pkt := <-h.htlcPlex:
 			// TODO(roasbeef): properly account with cleared vs settled
 			numUpdates += 1
@@ -300,35 +527,154 @@ out:
 				h.onionMtx.RLock()
 				clearLink, ok := h.onionIndex[nextHop]
 				h.onionMtx.RUnlock()
+
+				h.chanIndexMtx.RLock()
+				settleLink := h.chanIndex[pkt.srcLink]
+				h.chanIndexMtx.RUnlock()
+
+				// If we're unable to locate the next hop
+				// within the route, then the HTLC can't be
+				// forwarded, so we cancel it back to the
+				// link which sent it to us.
 				if !ok {
 					hswcLog.Errorf("unable to find dest end of "+
 						"circuit: %x", nextHop)
+
+					if settleLink == nil {
+						continue
+					}
+
+					settleLink.linkChan <- &htlcPacket{
+						msg:      &lnwire.CancelHTLC{},
+						index:    pkt.index,
+						payHash:  wireMsg.RedemptionHashes[0],
+						failCode: lnwire.FailUnknownNextPeer,
+						err:      make(chan error, 1),
+					}
 					continue
 				}
 
-				h.chanIndexMtx.RLock()
-				settleLink := h.chanIndex[pkt.srcLink]
-				h.chanIndexMtx.RUnlock()
-
+				// Select a link to the next hop whose policy
+				// is satisfied by the HTLC, and which has
+				// enough available bandwidth to carry the
+				// amount left once our fee has been deducted.
+				// If there isn't one, then the HTLC is
+				// cancelled back to the link which sent it to
+				// us.
 				// TODO(roasbeef): examine per-hop info to decide on link?
-				//  * check clear has enough available sat
+				var (
+					nextLink  *link
+					fwdAmt    btcutil.Amount
+					fwdExpiry uint32
+				)
+				failCode := lnwire.FailInsufficientCapacity
+				for _, link := range clearLink {
+					policy := link.forwardingPolicy()
+
+					amt := policy.ForwardAmount(pkt.amt)
+					if amt == 0 || amt < policy.MinHTLC {
+						failCode = lnwire.FailFeeInsufficient
+						continue
+					}
+
+					// The HTLC we forward must expire
+					// before the incoming HTLC by at
+					// least the link's time-lock delta,
+					// while still leaving us time to
+					// cancel it back.
+					minExpiry := pkt.bestHeight + htlcCancelDelta +
+						policy.TimeLockDelta
+					if wireMsg.Expiry <= minExpiry {
+						failCode = lnwire.FailIncorrectExpiryDelta
+						continue
+					}
+
+					bandwidth := atomic.LoadInt64(&link.availableBandwidth)
+					if bandwidth < int64(amt) {
+						failCode = lnwire.FailInsufficientCapacity
+						continue
+					}
+
+					nextLink = link
+					fwdAmt = amt
+					fwdExpiry = wireMsg.Expiry - policy.TimeLockDelta
+					break
+				}
+				if nextLink == nil {
+					hswcLog.Errorf("unable to forward HTLC to "+
+						"%x: %v", nextHop, failCode)
+
+					if settleLink == nil {
+						continue
+					}
+
+					settleLink.linkChan <- &htlcPacket{
+						msg:      &lnwire.CancelHTLC{},
+						index:    pkt.index,
+						payHash:  wireMsg.RedemptionHashes[0],
+						failCode: failCode,
+						err:      make(chan error, 1),
+					}
+					continue
+				}
+
 				circuit := &paymentCircuit{
-					clear:  clearLink[0],
-					settle: settleLink,
+					clear:         *nextLink.chanPoint,
+					settle:        pkt.srcLink,
+					incomingIndex: pkt.index,
+					incomingAmt:   pkt.amt,
+					outgoingAmt:   fwdAmt,
 				}
 
+				// Before forwarding the HTLC, the circuit is
+				// written to disk. If we're unable to do so,
+				// then the HTLC is cancelled back, as we
+				// wouldn't be able to complete the circuit
+				// after a restart.
 				cKey := circuitKey(wireMsg.RedemptionHashes[0])
-				h.paymentCircuits[cKey] = circuit
+				dbCircuit := &channeldb.PaymentCircuit{
+					PaymentHash:     cKey,
+					ClearChanPoint:  circuit.clear,
+					SettleChanPoint: circuit.settle,
+					IncomingIndex:   circuit.incomingIndex,
+					IncomingAmt:     circuit.incomingAmt,
+					OutgoingAmt:     circuit.outgoingAmt,
+				}
+				if err := h.db.AddPaymentCircuit(dbCircuit); err != nil {
+					hswcLog.Errorf("unable to persist circuit "+
+						"for %x: %v", cKey[:], err)
+
+					if settleLink == nil {
+						continue
+					}
+
+					// The cause of the failure is internal
+					// to this node, so it isn't disclosed
+					// to the sender.
+					settleLink.linkChan <- &htlcPacket{
+						msg:      &lnwire.CancelHTLC{},
+						index:    pkt.index,
+						payHash:  wireMsg.RedemptionHashes[0],
+						failCode: lnwire.FailUnknown,
+						err:      make(chan error, 1),
+					}
+					continue
+				}
+				circuit.id = dbCircuit.ID
+				h.paymentCircuits[cKey] = append(h.paymentCircuits[cKey],
+					circuit)
 
 				hswcLog.Debugf("Creating onion circuit for %x: %v<->%v",
-					cKey[:], clearLink[0].chanPoint,
-					settleLink.chanPoint)
+					cKey[:], circuit.clear, circuit.settle)
 
 				// With the circuit initiated, send the htlcPkt
 				// to the clearing link within the circuit to
 				// continue propagating the HTLC accross the
-				// network.
-				circuit.clear.linkChan <- &htlcPacket{
+				// network. The difference between the incoming
+				// and forwarded amounts is our fee.
+				wireMsg.Amount = lnwire.CreditsAmount(fwdAmt)
+				wireMsg.Expiry = fwdExpiry
+				nextLink.linkChan <- &htlcPacket{
 					msg: wireMsg,
 					err: make(chan error, 1),
 				}
@@ -336,10 +682,10 @@ out:
 				// Reduce the available bandwidth for the link
 				// as it will clear the above HTLC, increasing
 				// the limbo balance within the channel.
-				n := atomic.AddInt64(&circuit.clear.availableBandwidth,
-					-int64(pkt.amt))
+				n := atomic.AddInt64(&nextLink.availableBandwidth,
+					-int64(fwdAmt))
 				hswcLog.Tracef("Decrementing link %v bandwidth to %v",
-					circuit.clear.chanPoint, n)
+					circuit.clear, n)
 
 				satRecv += pkt.amt
 
@@ -356,8 +702,8 @@ out:
 				// If we initiated the payment then there won't
 				// be an active circuit to continue propagating
 				// the settle over. Therefore, we exit early.
-				circuit, ok := h.paymentCircuits[cKey]
-				if !ok {
+				circuit := h.findCircuit(cKey, pkt.srcLink, pkt.amt)
+				if circuit == nil {
 					hswcLog.Debugf("No existing circuit "+
 						"for %x", rHash[:])
 					satSent += pkt.amt
@@ -366,37 +712,96 @@ out:
 
 				hswcLog.Debugf("Closing completed onion "+
 					"circuit for %x: %v<->%v", rHash[:],
-					circuit.clear.chanPoint,
-					circuit.settle.chanPoint)
-
-				circuit.settle.linkChan <- &htlcPacket{
-					msg: wireMsg,
-					err: make(chan error, 1),
+					circuit.clear, circuit.settle)
+
+				h.removeCircuit(cKey, circuit)
+
+				// With the forward complete, we record it
+				// within the forwarding log along with the fee
+				// we earned.
+				event := &channeldb.ForwardingEvent{
+					Timestamp:         time.Now(),
+					IncomingChanPoint: circuit.settle,
+					OutgoingChanPoint: circuit.clear,
+					AmtIn:             circuit.incomingAmt,
+					AmtOut:            circuit.outgoingAmt,
+				}
+				if err := h.db.AddForwardingEvent(event); err != nil {
+					hswcLog.Errorf("unable to record forwarding "+
+						"event for %x: %v", rHash[:], err)
 				}
+				feesEarned += event.Fee()
+
+				settleLink := h.forwardToLink(circuit.settle,
+					&htlcPacket{
+						msg:   wireMsg,
+						index: circuit.incomingIndex,
+						err:   make(chan error, 1),
+					})
 
 				// Increase the available bandwidth for the
 				// link as it will settle the above HTLC,
 				// subtracting from the limbo balacne and
-				// incrementing its local balance.
-				n := atomic.AddInt64(&circuit.settle.availableBandwidth,
-					int64(pkt.amt))
-				hswcLog.Tracef("Incrementing link %v bandwidth to %v",
-					circuit.settle.chanPoint, n)
+				// incrementing its local balance. The link
+				// gains the amount of the incoming HTLC,
+				// which includes the fee we earned.
+				if settleLink != nil {
+					n := atomic.AddInt64(&settleLink.availableBandwidth,
+						int64(circuit.incomingAmt))
+					hswcLog.Tracef("Incrementing link %v "+
+						"bandwidth to %v", circuit.settle, n)
+				}
+
+				satSent += circuit.incomingAmt
+
+			// An HTLC we forwarded has been cancelled by the
+			// downstream peer, or couldn't be added to the
+			// downstream channel, so we propagate the cancel back
+			// to the link which initially created the circuit.
+			case *lnwire.CancelHTLC:
+				var cKey circuitKey
+				copy(cKey[:], pkt.payHash[:])
+
+				// If we initiated the payment, then there won't
+				// be an active circuit, and the payment
+				// requester has already been notified of the
+				// failure.
+				circuit := h.findCircuit(cKey, pkt.srcLink, pkt.amt)
+				if circuit == nil {
+					hswcLog.Debugf("No existing circuit "+
+						"for %x", pkt.payHash[:])
+					continue
+				}
+
+				hswcLog.Debugf("Cancelling onion circuit for "+
+					"%x: %v<->%v", pkt.payHash[:],
+					circuit.clear, circuit.settle)
+
+				h.removeCircuit(cKey, circuit)
 
-				satSent += pkt.amt
+				h.forwardToLink(circuit.settle, &htlcPacket{
+					msg:      wireMsg,
+					index:    circuit.incomingIndex,
+					payHash:  pkt.payHash,
+					failCode: pkt.failCode,
+					err:      make(chan error, 1),
+				})
 			}
 		case <-logTicker.C:
 			if numUpdates == 0 {
 				continue
 			}
 
-			hswcLog.Infof("Sent %v satoshis, received %v satoshi in "+
-				"the last 10 seconds (%v tx/sec)",
+			hswcLog.Infof("Sent %v satoshis, received %v satoshi, "+
+				"earned %v satoshis in fees in the last 10 "+
+				"seconds (%v tx/sec)",
 				satSent.ToUnit(btcutil.AmountSatoshi),
 				satRecv.ToUnit(btcutil.AmountSatoshi),
+				feesEarned.ToUnit(btcutil.AmountSatoshi),
 				float64(numUpdates)/10)
 			satSent = 0
 			satRecv = 0
+			feesEarned = 0
 			numUpdates = 0
 		case <-h.quit:
 			break out
@@ -405,6 +810,73 @@ out:
 	h.wg.Done()
 }
 
+// findCircuit returns the active payment circuit identified by the passed key
+// whose clear end is the passed link, and which forwarded an HTLC of the
+// passed amount over it. If no such circuit exists, then nil is returned.
+func (h *htlcSwitch) findCircuit(cKey circuitKey, clearLink wire.OutPoint,
+	amt btcutil.Amount) *paymentCircuit {
+
+	for _, circuit := range h.paymentCircuits[cKey] {
+		if circuit.clear == clearLink && circuit.outgoingAmt == amt {
+			return circuit
+		}
+	}
+
+	return nil
+}
+
+// removeCircuit tears down the passed payment circuit, removing it from both
+// the in-memory circuit map, and the database.
+func (h *htlcSwitch) removeCircuit(cKey circuitKey, circuit *paymentCircuit) {
+	circuits := h.paymentCircuits[cKey]
+	for i, c := range circuits {
+		if c == circuit {
+			circuits = append(circuits[:i], circuits[i+1:]...)
+			break
+		}
+	}
+	if len(circuits) == 0 {
+		delete(h.paymentCircuits, cKey)
+	} else {
+		h.paymentCircuits[cKey] = circuits
+	}
+
+	err := h.db.DeletePaymentCircuit(&channeldb.PaymentCircuit{
+		PaymentHash: cKey,
+		ID:          circuit.id,
+	})
+	if err != nil {
+		hswcLog.Errorf("unable to delete circuit for %x: %v", cKey[:],
+			err)
+	}
+}
+
+// forwardToLink sends the passed packet to the link identified by the target
+// channel point, returning the link. If the link isn't currently active, as
+// may be the case after a restart before the remote peer has reconnected,
+// then the packet is queued until the link is registered, and nil is
+// returned.
+func (h *htlcSwitch) forwardToLink(chanPoint wire.OutPoint,
+	pkt *htlcPacket) *link {
+
+	h.chanIndexMtx.Lock()
+	targetLink, ok := h.chanIndex[chanPoint]
+	if !ok {
+		h.pendingLinkPkts[chanPoint] = append(h.pendingLinkPkts[chanPoint],
+			pkt)
+		h.chanIndexMtx.Unlock()
+
+		hswcLog.Infof("Link %v isn't active, queueing packet until "+
+			"it has been registered", chanPoint)
+		return nil
+	}
+	h.chanIndexMtx.Unlock()
+
+	targetLink.linkChan <- pkt
+
+	return targetLink
+}
+
 // networkAdmin is responsible for handline requests to register, unregister,
 // and close any link. In the event that a unregister requests leaves an
 // interface with no active links, that interface is garbage collected.
@@ -434,16 +906,38 @@ out:
 // adds the link to the existing set of links for the target interface.
 func (h *htlcSwitch) handleRegisterLink(req *registerLinkMsg) {
 	chanPoint := req.linkInfo.ChannelPoint
+
+	// Load the routing policy of the link from disk. If the link doesn't
+	// yet have a policy, then it's assigned the default policy.
+	policy, err := h.db.FetchChannelPolicy(chanPoint)
+	switch {
+	case err == channeldb.ErrChannelPolicyNotFound:
+		p := *h.defaultPolicy
+		policy = &p
+		if err := h.db.PutChannelPolicy(chanPoint, policy); err != nil {
+			hswcLog.Errorf("unable to store policy of link %v: %v",
+				chanPoint, err)
+		}
+	case err != nil:
+		hswcLog.Errorf("unable to fetch policy of link %v: %v",
+			chanPoint, err)
+		p := *h.defaultPolicy
+		policy = &p
+	}
+
 	newLink := &link{
 		capacity:           req.linkInfo.Capacity,
 		availableBandwidth: int64(req.linkInfo.LocalBalance),
 		linkChan:           req.linkChan,
 		peer:               req.peer,
 		chanPoint:          chanPoint,
+		policy:             policy,
 	}
 
 	h.chanIndexMtx.Lock()
 	h.chanIndex[*chanPoint] = newLink
+	pendingPkts := h.pendingLinkPkts[*chanPoint]
+	delete(h.pendingLinkPkts, *chanPoint)
 	h.chanIndexMtx.Unlock()
 
 	interfaceID := req.peer.lightningID
@@ -466,6 +960,24 @@ func (h *htlcSwitch) handleRegisterLink(req *registerLinkMsg) {
 	if req.done != nil {
 		req.done <- struct{}{}
 	}
+
+	// Finally, deliver any packets which were queued while the link was
+	// inactive. This is done within a distinct goroutine as the link's
+	// channel won't be serviced until the registration has completed.
+	if len(pendingPkts) > 0 {
+		hswcLog.Infof("Delivering %v queued packets to link %v",
+			len(pendingPkts), chanPoint)
+
+		go func() {
+			for _, pkt := range pendingPkts {
+				select {
+				case newLink.linkChan <- pkt:
+				case <-h.quit:
+					return
+				}
+			}
+		}()
+	}
 }
 
 // handleUnregisterLink unregisters a currently active link. If the deletion of
@@ -577,6 +1089,46 @@ func (h *htlcSwitch) handleLinkUpdate(req *linkInfoUpdateMsg) {
 		req.bandwidthDelta)
 }
 
+// LinkPolicy returns the routing policy enforced when forwarding HTLC's over
+// the link identified by the passed channel point. If the link isn't
+// currently active, then nil is returned.
+func (h *htlcSwitch) LinkPolicy(chanPoint *wire.OutPoint) *channeldb.ChannelPolicy {
+	h.chanIndexMtx.RLock()
+	targetLink, ok := h.chanIndex[*chanPoint]
+	h.chanIndexMtx.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	return targetLink.forwardingPolicy()
+}
+
+// UpdateLinkPolicy replaces the routing policy enforced when forwarding
+// HTLC's over the link identified by the passed channel point, returning the
+// link. The new policy applies to all HTLC's forwarded from this point on.
+// If the link isn't currently active, then nil is returned. In either case,
+// the caller is responsible for persisting the policy.
+func (h *htlcSwitch) UpdateLinkPolicy(chanPoint *wire.OutPoint,
+	policy *channeldb.ChannelPolicy) *link {
+
+	h.chanIndexMtx.RLock()
+	targetLink, ok := h.chanIndex[*chanPoint]
+	h.chanIndexMtx.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	targetLink.policyMtx.Lock()
+	targetLink.policy = policy
+	targetLink.policyMtx.Unlock()
+
+	hswcLog.Infof("updated policy of link %v: base_fee=%v, fee_rate=%v, "+
+		"min_htlc=%v, time_lock_delta=%v", chanPoint, policy.BaseFee,
+		policy.FeeRate, policy.MinHTLC, policy.TimeLockDelta)
+
+	return targetLink
+}
+
 // registerLinkMsg is message which requests a new link to be registered.
 type registerLinkMsg struct {
 	peer     *peer
@@ -632,31 +1184,54 @@ func (h *htlcSwitch) UnregisterLink(remotePub *btcec.PublicKey, chanPoint *wire.
 	<-done
 }
 
+// LinkCloseType is a enum which signals the type of channel closure the switch
+// should execute.
+type LinkCloseType uint8
+
+const (
+	// CloseRegular indicates a regular cooperative channel closure should
+	// be attempted.
+	CloseRegular LinkCloseType = iota
+
+	// CloseForce indicates that the channel should be forcefully closed.
+	// This entails the broadcast of the commitment transaction directly on
+	// chain unilaterally.
+	CloseForce
+
+	// CloseBreach indicates that a channel breach has been detected, and
+	// the link should immediately be marked as unavailable. The on-chain
+	// resolution of the channel is handled by the breachArbiter, so the
+	// channel's state is left intact.
+	CloseBreach
+)
+
 // closeChanReq represents a request to close a particular channel specified
 // by its outpoint.
 type closeLinkReq struct {
-	chanPoint  *wire.OutPoint
-	forceClose bool
+	chanPoint *wire.OutPoint
+	closeType LinkCloseType
 
 	updates chan *lnrpc.CloseStatusUpdate
 	err     chan error
 }
 
 // CloseLink closes an active link targetted by it's channel point. Closing the
-// link initiates a cooperative channel closure iff forceClose is false. If
-// forceClose is true, then a unilateral channel closure is executed.
+// link initiates a cooperative channel closure iff the closeType is
+// CloseRegular. If the closeType is CloseForce, then a unilateral channel
+// closure is executed. Finally, CloseBreach only tears down the link, leaving
+// the resolution of the channel to the breachArbiter.
 // TODO(roabeef): bool flag for timeout
 func (h *htlcSwitch) CloseLink(chanPoint *wire.OutPoint,
-	forceClose bool) (chan *lnrpc.CloseStatusUpdate, chan error) {
+	closeType LinkCloseType) (chan *lnrpc.CloseStatusUpdate, chan error) {
 
 	updateChan := make(chan *lnrpc.CloseStatusUpdate, 1)
 	errChan := make(chan error, 1)
 
 	h.linkControl <- &closeLinkReq{
-		chanPoint:  chanPoint,
-		forceClose: forceClose,
-		updates:    updateChan,
-		err:        errChan,
+		chanPoint: chanPoint,
+		closeType: closeType,
+		updates:   updateChan,
+		err:       errChan,
 	}
 
 	return updateChan, errChan
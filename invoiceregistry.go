@@ -2,12 +2,15 @@ package main
 
 import (
 	"bytes"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/btcsuite/fastsha256"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
 )
@@ -23,6 +26,17 @@ var (
 	debugHash = wire.ShaHash(fastsha256.Sum256(debugPre[:]))
 )
 
+const (
+	// invoiceExpiryInterval is the interval at which the invoice registry
+	// sweeps the database for open invoices which have expired.
+	invoiceExpiryInterval = time.Minute
+
+	// paymentPartTimeout is the duration the registry will hold the parts
+	// of a multi-part payment waiting for their sum to cover the invoice.
+	// Once elapsed, all held parts are cancelled.
+	paymentPartTimeout = time.Minute
+)
+
 // invoiceRegistry is a central registry of all the outstanding invoices
 // created by the daemon. The registry is a thin wrapper around a map in order
 // to ensure that all updates/reads are thread safe.
@@ -31,20 +45,124 @@ type invoiceRegistry struct {
 
 	cdb *channeldb.DB
 
+	// htlcSwitch is used to settle or cancel the held parts of a
+	// multi-part payment over the links they arrived on.
+	htlcSwitch *htlcSwitch
+
+	// overpaymentTolerance is the fraction of an invoice's value by which
+	// an incoming payment may exceed it and still be accepted.
+	overpaymentTolerance float64
+
 	// debugInvoices is a mp which stores special "debug" invoices which
 	// should be only created/used when manual tests require an invoice
 	// that *all* nodes are able to fully settle.
 	debugInvoices map[wire.ShaHash]*channeldb.Invoice
+
+	// heldPayments tracks the parts of all multi-part payments which
+	// don't yet cover the invoice they pay to, keyed by payment hash.
+	heldPayments map[wire.ShaHash]*heldPayment
+
+	// clientMtx guards the set of active invoice subscriptions. The mutex
+	// is held while an invoice event is dispatched, ensuring new clients
+	// observe a consistent view of their backlog.
+	clientMtx     sync.Mutex
+	nextClientID  uint32
+	notifyClients map[uint32]*invoiceSubscription
+
+	started uint32
+	stopped uint32
+	quit    chan struct{}
+	wg      sync.WaitGroup
 }
 
 // newInvoiceRegistry creates a new invoice registry. The invoice registry
 // wraps the persistent on-disk invoice storage with an additional in-memory
 // layer. The in-memory layer is in pace such that debug invoices can be added
 // which are volatile yet available system wide within the daemon.
-func newInvoiceRegistry(cdb *channeldb.DB) *invoiceRegistry {
+func newInvoiceRegistry(cdb *channeldb.DB, htlcSwitch *htlcSwitch,
+	overpaymentTolerance float64) *invoiceRegistry {
+
 	return &invoiceRegistry{
-		cdb:           cdb,
-		debugInvoices: make(map[wire.ShaHash]*channeldb.Invoice),
+		cdb:                  cdb,
+		htlcSwitch:           htlcSwitch,
+		overpaymentTolerance: overpaymentTolerance,
+		debugInvoices:        make(map[wire.ShaHash]*channeldb.Invoice),
+		heldPayments:         make(map[wire.ShaHash]*heldPayment),
+		notifyClients:        make(map[uint32]*invoiceSubscription),
+		quit:                 make(chan struct{}),
+	}
+}
+
+// Start launches the goroutine responsible for periodically expiring stale
+// invoices.
+func (i *invoiceRegistry) Start() error {
+	if !atomic.CompareAndSwapUint32(&i.started, 0, 1) {
+		return nil
+	}
+
+	i.wg.Add(1)
+	go i.invoiceExpirer()
+
+	return nil
+}
+
+// Stop signals the invoice registry to shutdown.
+func (i *invoiceRegistry) Stop() error {
+	if !atomic.CompareAndSwapUint32(&i.stopped, 0, 1) {
+		return nil
+	}
+
+	close(i.quit)
+	i.wg.Wait()
+
+	// Cancel all parts still held, as the timers which would otherwise
+	// cancel them are stopped along with the registry. Any parts handed
+	// to the registry from now on are cancelled immediately.
+	i.Lock()
+	heldPayments := i.heldPayments
+	i.heldPayments = make(map[wire.ShaHash]*heldPayment)
+	i.Unlock()
+
+	for rHash, held := range heldPayments {
+		held.timeout.Stop()
+
+		ltndLog.Infof("Cancelling %v held parts paying %v to invoice "+
+			"%x due to shutdown", len(held.parts), held.total,
+			rHash[:])
+
+		i.cancelParts(rHash, held.parts, lnwire.FailAmountTooLow)
+	}
+
+	return nil
+}
+
+// invoiceExpirer periodically transitions all open invoices whose expiry has
+// elapsed to the expired state, after which they'll no longer accept
+// payment.
+//
+// NOTE: This MUST be run as a goroutine.
+func (i *invoiceRegistry) invoiceExpirer() {
+	defer i.wg.Done()
+
+	ticker := time.NewTicker(invoiceExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		// Sweep immediately on start up so invoices which expired
+		// while we were down are promptly marked.
+		expired, err := i.cdb.ExpireInvoices(time.Now())
+		if err != nil {
+			ltndLog.Errorf("unable to expire invoices: %v", err)
+		}
+		for _, rHash := range expired {
+			ltndLog.Infof("Invoice %x has expired", rHash[:])
+		}
+
+		select {
+		case <-ticker.C:
+		case <-i.quit:
+			return
+		}
 	}
 }
 
@@ -83,12 +201,20 @@ func (i *invoiceRegistry) AddInvoice(invoice *channeldb.Invoice) error {
 	}))
 
 	// TODO(roasbeef): also check in memory for quick lookups/settles?
-	return i.cdb.AddInvoice(invoice)
+	if err := i.cdb.AddInvoice(invoice); err != nil {
+		return err
+	}
+
+	// Now that the invoice has been assigned its add index, notify all
+	// subscribers of the new invoice.
+	i.notifySubscribers(&invoiceEvent{invoice: invoice})
+
+	return nil
 }
 
 // lookupInvoice looks up an invoice by it's payment hash (R-Hash), if found
-// then we're able to pull the funds pending within an HTLC.
-// TODO(roasbeef): ignore if settled?
+// then we're able to pull the funds pending within an HTLC. Callers should
+// check the state of the returned invoice before accepting payment.
 func (i *invoiceRegistry) LookupInvoice(rHash wire.ShaHash) (*channeldb.Invoice, error) {
 	// First check the in-memory debug invoice index to see if this is an
 	// existing invoice added for debugging.
@@ -106,10 +232,316 @@ func (i *invoiceRegistry) LookupInvoice(rHash wire.ShaHash) (*channeldb.Invoice,
 	return i.cdb.LookupInvoice(rHash)
 }
 
-// SettleInvoice attempts to mark an invoice as settled. If the invoice is a
-// dbueg invoice, then this method is a nooop as debug invoices are never fully
-// settled.
-func (i *invoiceRegistry) SettleInvoice(rHash wire.ShaHash) error {
+// AcceptPayment determines if an incoming HTLC of the passed amount, paying to
+// the passed payment hash, may be settled. If so, the invoice which the HTLC
+// pays to is returned. Otherwise, the reason the HTLC should be cancelled is
+// returned along with a non-nil error. HTLC's exceeding the value of the
+// invoice by more than the configured tolerance are rejected and recorded
+// against the invoice. If the sender signalled that the HTLC is a part of a
+// multi-part payment, then an HTLC paying less than the value of an open
+// invoice is accepted as a part, denoted by the returned boolean. Rather than
+// being settled immediately, such parts must be handed to AddPaymentPart once
+// locked in. Otherwise, such HTLC's are rejected as underpayments.
+func (i *invoiceRegistry) AcceptPayment(rHash wire.ShaHash, amt btcutil.Amount,
+	multiPart bool) (*channeldb.Invoice, bool, lnwire.FailCode, error) {
+
+	// Debug invoices settle any HTLC paying to the debug hash, so no
+	// further checks are needed.
+	i.RLock()
+	invoice, ok := i.debugInvoices[rHash]
+	i.RUnlock()
+	if ok {
+		return invoice, false, 0, nil
+	}
+
+	invoice, err := i.cdb.LookupInvoice(rHash)
+	if err != nil {
+		return nil, false, lnwire.FailUnknownPaymentHash, err
+	}
+
+	// Invoices which have been cancelled or have expired no longer
+	// accept payment.
+	switch {
+	case invoice.Terms.State == channeldb.InvoiceCancelled,
+		invoice.Terms.State == channeldb.InvoiceExpired,
+		invoice.IsExpired(time.Now()):
+
+		return nil, false, lnwire.FailInvoiceNotPayable,
+			fmt.Errorf("invoice is no longer payable (state=%v)",
+				invoice.Terms.State)
+	}
+
+	// An invoice without a value accepts payments of any amount.
+	// Otherwise, the payment must cover the value of the invoice, without
+	// exceeding it by more than the overpayment tolerance.
+	value := invoice.Terms.Value
+	if value == 0 {
+		return invoice, false, 0, nil
+	}
+
+	var reason lnwire.FailCode
+	switch {
+	// An open invoice may be paid by a multi-part payment, so the HTLC is
+	// held until the remaining parts arrive. Once an invoice has been
+	// settled, HTLC's are expected to pay its full value.
+	case amt < value && multiPart &&
+		invoice.Terms.State == channeldb.InvoiceOpen:
+
+		return invoice, true, 0, nil
+
+	case amt < value:
+		reason = lnwire.FailAmountTooLow
+		err = fmt.Errorf("payment of %v is below invoice value of %v",
+			amt, value)
+
+	case amt > i.maxPayment(value):
+		reason = lnwire.FailAmountTooHigh
+		err = fmt.Errorf("payment of %v exceeds invoice value of %v "+
+			"by more than the accepted maximum of %v", amt, value,
+			i.maxPayment(value))
+
+	default:
+		return invoice, false, 0, nil
+	}
+
+	if err := i.cdb.RecordRejectedPayment(rHash, amt); err != nil {
+		ltndLog.Errorf("unable to record rejected payment for "+
+			"invoice %x: %v", rHash[:], err)
+	}
+
+	return nil, false, reason, err
+}
+
+// maxPayment returns the largest payment which will be accepted for an
+// invoice of the passed value.
+func (i *invoiceRegistry) maxPayment(value btcutil.Amount) btcutil.Amount {
+	return value + btcutil.Amount(float64(value)*i.overpaymentTolerance)
+}
+
+// paymentPart is a locked-in HTLC which pays part of an invoice.
+type paymentPart struct {
+	// chanPoint is the channel point of the link the HTLC arrived on.
+	chanPoint wire.OutPoint
+
+	// index is the log index of the HTLC within the remote log of the
+	// link it arrived on.
+	index uint32
+
+	amt btcutil.Amount
+}
+
+// heldPayment is the set of parts of a multi-part payment which have arrived
+// so far.
+type heldPayment struct {
+	parts []*paymentPart
+	total btcutil.Amount
+
+	// timeout fires once the parts have been held for
+	// paymentPartTimeout, cancelling all held parts.
+	timeout *time.Timer
+}
+
+// AddPaymentPart hands a locked-in HTLC which pays part of the invoice
+// identified by the passed payment hash to the registry. The part is held
+// until the sum of all held parts covers the invoice, at which point the
+// invoice is settled, and every part is settled over the link it arrived on.
+// If the parts don't cover the invoice within paymentPartTimeout, then all
+// held parts are cancelled.
+func (i *invoiceRegistry) AddPaymentPart(rHash wire.ShaHash,
+	chanPoint wire.OutPoint, index uint32, amt btcutil.Amount) {
+
+	part := &paymentPart{
+		chanPoint: chanPoint,
+		index:     index,
+		amt:       amt,
+	}
+
+	invoice, err := i.cdb.LookupInvoice(rHash)
+	if err != nil {
+		ltndLog.Errorf("unable to find invoice %x for payment part: %v",
+			rHash[:], err)
+		go i.cancelParts(rHash, []*paymentPart{part},
+			lnwire.FailUnknownPaymentHash)
+		return
+	}
+
+	// Parts can no longer be held once the registry has been stopped.
+	// As Stop collects the held parts under the mutex after marking the
+	// registry stopped, any part held before then is cancelled by Stop.
+	i.Lock()
+	if atomic.LoadUint32(&i.stopped) == 1 {
+		i.Unlock()
+		go i.cancelParts(rHash, []*paymentPart{part},
+			lnwire.FailAmountTooLow)
+		return
+	}
+	held, ok := i.heldPayments[rHash]
+	if !ok {
+		held = &heldPayment{}
+		held.timeout = time.AfterFunc(paymentPartTimeout, func() {
+			i.expireHeldPayment(rHash, held)
+		})
+		i.heldPayments[rHash] = held
+	}
+	held.parts = append(held.parts, part)
+	held.total += amt
+
+	ltndLog.Debugf("Holding part of %v for invoice %x, %v of %v received "+
+		"in %v parts", amt, rHash[:], held.total, invoice.Terms.Value,
+		len(held.parts))
+
+	// If the parts received so far don't yet cover the invoice, then we
+	// continue to wait for the remaining parts.
+	if held.total < invoice.Terms.Value {
+		i.Unlock()
+		return
+	}
+
+	held.timeout.Stop()
+	delete(i.heldPayments, rHash)
+	i.Unlock()
+
+	// As with single HTLC payments, the sum of all parts mustn't exceed
+	// the value of the invoice by more than the overpayment tolerance.
+	if held.total > i.maxPayment(invoice.Terms.Value) {
+		ltndLog.Errorf("rejecting %v parts paying %v to invoice %x "+
+			"of %v: overpayment", len(held.parts), held.total,
+			rHash[:], invoice.Terms.Value)
+
+		err := i.cdb.RecordRejectedPayment(rHash, held.total)
+		if err != nil {
+			ltndLog.Errorf("unable to record rejected payment "+
+				"for invoice %x: %v", rHash[:], err)
+		}
+
+		go i.cancelParts(rHash, held.parts, lnwire.FailAmountTooHigh)
+		return
+	}
+
+	if err := i.SettleInvoice(rHash, held.total); err != nil {
+		ltndLog.Errorf("unable to settle invoice %x: %v", rHash[:], err)
+		go i.cancelParts(rHash, held.parts,
+			lnwire.FailInvoiceNotPayable)
+		return
+	}
+
+	ltndLog.Infof("Settling %v parts paying %v to invoice %x",
+		len(held.parts), held.total, rHash[:])
+
+	go i.settleParts(invoice.Terms.PaymentPreimage, held.parts)
+}
+
+// CancelPaymentPart removes the held payment part received over the passed
+// link at the passed log index, as its HTLC is being cancelled by the link.
+// The part no longer counts towards the invoice it pays to. True is returned
+// if the part was removed, and false if it isn't held, as is the case once
+// the registry has already settled or cancelled it.
+func (i *invoiceRegistry) CancelPaymentPart(rHash wire.ShaHash,
+	chanPoint wire.OutPoint, index uint32) bool {
+
+	i.Lock()
+	defer i.Unlock()
+
+	held, ok := i.heldPayments[rHash]
+	if !ok {
+		return false
+	}
+
+	for j, part := range held.parts {
+		if part.chanPoint != chanPoint || part.index != index {
+			continue
+		}
+
+		held.parts = append(held.parts[:j], held.parts[j+1:]...)
+		held.total -= part.amt
+
+		ltndLog.Debugf("Cancelled part of %v for invoice %x, %v "+
+			"received in %v parts", part.amt, rHash[:], held.total,
+			len(held.parts))
+
+		// If no parts remain, then there's nothing left to hold.
+		if len(held.parts) == 0 {
+			held.timeout.Stop()
+			delete(i.heldPayments, rHash)
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// expireHeldPayment cancels all parts of the passed held payment if they
+// still don't cover the invoice they pay to.
+func (i *invoiceRegistry) expireHeldPayment(rHash wire.ShaHash,
+	held *heldPayment) {
+
+	i.Lock()
+	if i.heldPayments[rHash] != held {
+		i.Unlock()
+		return
+	}
+	delete(i.heldPayments, rHash)
+	i.Unlock()
+
+	ltndLog.Errorf("Cancelling %v parts paying %v to invoice %x, "+
+		"remaining parts weren't received in time", len(held.parts),
+		held.total, rHash[:])
+
+	if err := i.cdb.RecordRejectedPayment(rHash, held.total); err != nil {
+		ltndLog.Errorf("unable to record rejected payment for "+
+			"invoice %x: %v", rHash[:], err)
+	}
+
+	i.cancelParts(rHash, held.parts, lnwire.FailAmountTooLow)
+}
+
+// settleParts settles each of the passed payment parts over the link it
+// arrived on.
+func (i *invoiceRegistry) settleParts(preimage [32]byte,
+	parts []*paymentPart) {
+
+	for _, part := range parts {
+		settleLink := i.htlcSwitch.forwardToLink(part.chanPoint,
+			&htlcPacket{
+				msg: &lnwire.HTLCSettleRequest{
+					RedemptionProofs: [][32]byte{preimage},
+				},
+				index: part.index,
+				err:   make(chan error, 1),
+			})
+
+		// Settling the part increases the available bandwidth of the
+		// link it arrived on.
+		if settleLink != nil {
+			atomic.AddInt64(&settleLink.availableBandwidth,
+				int64(part.amt))
+		}
+	}
+}
+
+// cancelParts cancels each of the passed payment parts over the link it
+// arrived on with the passed failure code.
+func (i *invoiceRegistry) cancelParts(rHash wire.ShaHash,
+	parts []*paymentPart, code lnwire.FailCode) {
+
+	for _, part := range parts {
+		i.htlcSwitch.forwardToLink(part.chanPoint, &htlcPacket{
+			msg:      &lnwire.CancelHTLC{},
+			index:    part.index,
+			payHash:  rHash,
+			failCode: code,
+			err:      make(chan error, 1),
+		})
+	}
+}
+
+// SettleInvoice attempts to mark an invoice as settled, recording the amount
+// paid to it. If the invoice is a dbueg invoice, then this method is a nooop
+// as debug invoices are never fully settled.
+func (i *invoiceRegistry) SettleInvoice(rHash wire.ShaHash,
+	amtPaid btcutil.Amount) error {
+
 	ltndLog.Debugf("Settling invoice %x", rHash[:])
 
 	// First check the in-memory debug invoice index to see if this is an
@@ -125,6 +557,223 @@ func (i *invoiceRegistry) SettleInvoice(rHash wire.ShaHash) error {
 	i.RUnlock()
 
 	// If this isn't a debug invoice, then we'll attempt to settle an
-	// invoice matching this rHash on disk (if one exists).
-	return i.cdb.SettleInvoice(rHash)
+	// invoice matching this rHash on disk (if one exists). Invoices which
+	// have already been settled are skipped, so subscribers are only
+	// notified once.
+	invoice, err := i.cdb.LookupInvoice(rHash)
+	if err != nil {
+		return err
+	}
+	if invoice.Terms.State == channeldb.InvoiceSettled {
+		return nil
+	}
+	if err := i.cdb.SettleInvoice(rHash, amtPaid); err != nil {
+		return err
+	}
+
+	// Fetch the invoice once again in order to obtain its newly assigned
+	// settle index before notifying subscribers.
+	invoice, err = i.cdb.LookupInvoice(rHash)
+	if err != nil {
+		return err
+	}
+	i.notifySubscribers(&invoiceEvent{invoice: invoice, settled: true})
+
+	return nil
+}
+
+// CancelInvoice attempts to cancel the invoice identified by the passed
+// payment hash. Once cancelled, the invoice will no longer accept payment.
+// Debug invoices can't be cancelled.
+func (i *invoiceRegistry) CancelInvoice(rHash wire.ShaHash) error {
+	ltndLog.Debugf("Cancelling invoice %x", rHash[:])
+
+	i.RLock()
+	_, ok := i.debugInvoices[rHash]
+	i.RUnlock()
+	if ok {
+		return fmt.Errorf("debug invoices can't be cancelled")
+	}
+
+	return i.cdb.CancelInvoice(rHash)
+}
+
+// invoiceEvent is a notification dispatched to invoice subscribers each time
+// an invoice is either added or settled.
+type invoiceEvent struct {
+	invoice *channeldb.Invoice
+
+	// settled is true if the invoice has just been settled, and false if
+	// it has just been added.
+	settled bool
+}
+
+// invoiceSubscription represents an intent to receive notifications of all
+// invoices that are added or settled. Events are delivered in order over the
+// Updates channel, and are queued internally such that a slow client never
+// blocks the invoice registry.
+type invoiceSubscription struct {
+	// Updates is the channel over which all invoice events are sent.
+	Updates chan *invoiceEvent
+
+	id  uint32
+	inv *invoiceRegistry
+
+	// addIndex and settleIndex are the indexes of the last add and settle
+	// events delivered to the client. Events at or below these indexes
+	// are duplicates and are skipped.
+	addIndex    uint64
+	settleIndex uint64
+
+	queueMtx  sync.Mutex
+	ntfnQueue []*invoiceEvent
+	newEvent  chan struct{}
+
+	cancelled uint32
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// SubscribeInvoices returns an invoiceSubscription which delivers an event
+// each time an invoice is added or settled. If a non-zero add or settle index
+// is passed, then all invoices added or settled after the respective index
+// are delivered first, allowing clients to resume from the last event
+// they've seen.
+func (i *invoiceRegistry) SubscribeInvoices(addIndex,
+	settleIndex uint64) (*invoiceSubscription, error) {
+
+	client := &invoiceSubscription{
+		Updates:     make(chan *invoiceEvent),
+		inv:         i,
+		addIndex:    addIndex,
+		settleIndex: settleIndex,
+		newEvent:    make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+	}
+
+	// The client is registered while holding the client mutex, and
+	// before the backlog is fetched. As a result, any events dispatched
+	// after the backlog has been read are seen by the client, while
+	// duplicates are filtered out by their index.
+	i.clientMtx.Lock()
+	defer i.clientMtx.Unlock()
+
+	if addIndex != 0 {
+		added, err := i.cdb.InvoicesAddedSince(addIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, invoice := range added {
+			client.enqueue(&invoiceEvent{invoice: invoice})
+		}
+	}
+	if settleIndex != 0 {
+		settled, err := i.cdb.InvoicesSettledSince(settleIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, invoice := range settled {
+			client.enqueue(&invoiceEvent{
+				invoice: invoice,
+				settled: true,
+			})
+		}
+	}
+
+	client.id = i.nextClientID
+	i.nextClientID++
+	i.notifyClients[client.id] = client
+
+	client.wg.Add(1)
+	go client.eventDispatcher()
+
+	return client, nil
+}
+
+// notifySubscribers queues the passed event for delivery to all active
+// invoice subscribers.
+func (i *invoiceRegistry) notifySubscribers(event *invoiceEvent) {
+	i.clientMtx.Lock()
+	defer i.clientMtx.Unlock()
+
+	for _, client := range i.notifyClients {
+		client.enqueue(event)
+	}
+}
+
+// Cancel unregisters the invoiceSubscription, freeing any previously
+// allocated resources.
+func (s *invoiceSubscription) Cancel() {
+	if !atomic.CompareAndSwapUint32(&s.cancelled, 0, 1) {
+		return
+	}
+
+	s.inv.clientMtx.Lock()
+	delete(s.inv.notifyClients, s.id)
+	s.inv.clientMtx.Unlock()
+
+	close(s.quit)
+	s.wg.Wait()
+}
+
+// enqueue adds the event to the client's queue, waking up the dispatcher.
+func (s *invoiceSubscription) enqueue(event *invoiceEvent) {
+	s.queueMtx.Lock()
+	s.ntfnQueue = append(s.ntfnQueue, event)
+	s.queueMtx.Unlock()
+
+	select {
+	case s.newEvent <- struct{}{}:
+	default:
+	}
+}
+
+// eventDispatcher delivers queued events to the client in order, skipping
+// any events the client has already seen.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *invoiceSubscription) eventDispatcher() {
+	defer s.wg.Done()
+
+	for {
+		s.queueMtx.Lock()
+		if len(s.ntfnQueue) == 0 {
+			s.queueMtx.Unlock()
+
+			select {
+			case <-s.newEvent:
+				continue
+			case <-s.quit:
+				return
+			case <-s.inv.quit:
+				return
+			}
+		}
+		event := s.ntfnQueue[0]
+		s.ntfnQueue[0] = nil
+		s.ntfnQueue = s.ntfnQueue[1:]
+		s.queueMtx.Unlock()
+
+		// Skip any events at or below the last delivered index, these
+		// are duplicates of events already sent to the client.
+		if event.settled {
+			if event.invoice.SettleIndex <= s.settleIndex {
+				continue
+			}
+			s.settleIndex = event.invoice.SettleIndex
+		} else {
+			if event.invoice.AddIndex <= s.addIndex {
+				continue
+			}
+			s.addIndex = event.invoice.AddIndex
+		}
+
+		select {
+		case s.Updates <- event:
+		case <-s.quit:
+			return
+		case <-s.inv.quit:
+			return
+		}
+	}
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
 	"github.com/roasbeef/btcrpcclient"
+	"github.com/roasbeef/btcutil"
 )
 
 var (
@@ -117,6 +118,22 @@ func lndMain() error {
 		return err
 	}
 
+	// By default, fee estimates are obtained from the same btcd node
+	// backing the notifier. If btcd is unable to produce an estimate, then
+	// the configured fee rate is used as a fallback.
+	feePerByte := btcutil.Amount(loadedConfig.FeePerByte)
+	var feeEstimator lnwallet.FeeEstimator
+	switch loadedConfig.FeeEstimator {
+	case btcdFeeEstimator:
+		feeEstimator, err = lnwallet.NewBtcdFeeEstimator(*rpcConfig,
+			feePerByte)
+		if err != nil {
+			return err
+		}
+	case staticFeeEstimator:
+		feeEstimator = &lnwallet.StaticFeeEstimator{FeeRate: feePerByte}
+	}
+
 	// TODO(roasbeef): paarse config here select chosen WalletController
 	walletConfig := &btcwallet.Config{
 		PrivatePass: []byte("hello"),
@@ -154,7 +171,8 @@ func lndMain() error {
 	defaultListenAddrs := []string{
 		net.JoinHostPort("", strconv.Itoa(loadedConfig.PeerPort)),
 	}
-	server, err := newServer(defaultListenAddrs, notifier, bio, wallet, chanDB)
+	server, err := newServer(defaultListenAddrs, notifier, bio, wallet,
+		feeEstimator, chanDB)
 	if err != nil {
 		srvrLog.Errorf("unable to create server: %v\n", err)
 		return err
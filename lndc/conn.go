@@ -2,252 +2,169 @@ package lndc
 
 import (
 	"bytes"
-	"crypto/cipher"
-	"crypto/hmac"
-	"encoding/binary"
 	"fmt"
+	"io"
 	"net"
 	"time"
 
-	"github.com/btcsuite/fastsha256"
-	"github.com/codahale/chacha20poly1305"
 	"github.com/roasbeef/btcutil"
 
 	"github.com/roasbeef/btcd/btcec"
 )
 
-// Conn...
+// LNDConn is an encrypted, and authenticated connection to a remote lightning
+// node. All data sent over the connection is encrypted by the Noise protocol
+// state machine established during the handshake. LNDConn satisfies the
+// net.Conn interface.
 type LNDConn struct {
+	// RemotePub is the static public key of the remote node, which is
+	// authenticated by the handshake.
 	RemotePub  *btcec.PublicKey
 	RemoteLNId [16]byte
 
-	myNonceInt     uint64
-	remoteNonceInt uint64
+	noise *noiseMachine
 
-	// If Authed == false, the remotePub is the EPHEMERAL key.
-	// once authed == true, remotePub is who you're actually talking to.
-	Authed bool
-
-	// chachaStream saves some time as you don't have to init it with
-	// the session key every time.  Make SessionKey redundant; remove later.
-	chachaStream cipher.AEAD
-
-	// ViaPbx specifies whether this is a direct TCP connection or an
-	// encapsulated PBX connection.
-	// If going ViaPbx, Cn isn't used channels are used for Read() and
-	// Write(), which are filled by the PBXhandler.
-	ViaPbx      bool
-	PbxIncoming chan []byte
-	PbxOutgoing chan []byte
+	readBuf bytes.Buffer
 
-	version uint8
+	// dialer is used to open the underlying connection within Dial. If
+	// nil, then connections are opened directly.
+	dialer Dialer
 
-	readBuf bytes.Buffer
+	// remoteAddr is the address the connection was dialed to. When
+	// dialing through a proxy, this differs from the address of the
+	// underlying connection.
+	remoteAddr net.Addr
 
 	Conn net.Conn
 }
 
-// NewConn...
+// NewConn creates a new LNDConn wrapping the passed net.Conn. The connection
+// isn't usable until the handshake has been completed by either Dial, or a
+// Listener.
 func NewConn(conn net.Conn) *LNDConn {
 	return &LNDConn{Conn: conn}
 }
 
-// Dial...
+// NewConnWithDialer creates a new LNDConn which opens its underlying
+// connection within Dial using the passed dialer, for example one which
+// routes connections through a SOCKS5 proxy. A nil dialer opens connections
+// directly.
+func NewConnWithDialer(dialer Dialer) *LNDConn {
+	return &LNDConn{dialer: dialer}
+}
+
+// Dial opens a TCP connection to the node with the passed address, through
+// the connection's dialer if one is set, and executes the initiator's side of
+// the handshake. As the handshake requires
+// the initiator to know the static public key of the responder, remoteId must
+// be the remote node's 33-byte compressed public key. If the remote node
+// doesn't hold the private key corresponding to remoteId, then the handshake
+// fails.
 func (c *LNDConn) Dial(
 	myId *btcec.PrivateKey, address string, remoteId []byte) error {
 	var err error
 
-	if !c.ViaPbx {
-		if c.Conn != nil {
-			return fmt.Errorf("connection already established")
-		}
-
-		// First, open the TCP connection itself.
-		c.Conn, err = net.Dial("tcp", address)
-		if err != nil {
-			return err
-		}
-	}
-
-	// Before dialing out to the remote host, verify that `remoteId` is either
-	// a pubkey or a pubkey hash.
-	if len(remoteId) != 33 && len(remoteId) != 20 {
-		return fmt.Errorf("must supply either remote pubkey or " +
-			"pubkey hash")
+	if c.Conn != nil {
+		return fmt.Errorf("connection already established")
 	}
 
-	// Calc remote LNId; need this for creating pbx connections just because
-	// LNid is in the struct does not mean it's authed!
-	if len(remoteId) == 20 {
-		copy(c.RemoteLNId[:], remoteId[:16])
-	} else {
-		theirAdr := btcutil.Hash160(remoteId)
-		copy(c.RemoteLNId[:], theirAdr[:16])
+	// Before dialing out to the remote host, verify that `remoteId` is a
+	// valid public key.
+	if len(remoteId) != 33 {
+		return fmt.Errorf("must supply the remote node's compressed " +
+			"public key")
 	}
-
-	// Make up an ephemeral keypair for this session.
-	ourEphemeralPriv, err := btcec.NewPrivateKey(btcec.S256())
+	remotePub, err := btcec.ParsePubKey(remoteId, btcec.S256())
 	if err != nil {
 		return err
 	}
-	ourEphemeralPub := ourEphemeralPriv.PubKey()
-
-	// Send 1. Send my ephemeral pubkey. Can add version bits.
-	if _, err = writeClear(c.Conn, ourEphemeralPub.SerializeCompressed()); err != nil {
-		return err
-	}
 
-	// Read, then deserialize their ephemeral public key.
-	theirEphPubBytes, err := readClear(c.Conn)
+	// Onion addresses can only be reached through Tor, so we'll need a
+	// proxy in order to dial them. If we're dialing through a proxy, then
+	// hostnames are left for the proxy to resolve, so the lookup doesn't
+	// leak outside of it.
+	remoteAddr, err := ResolveAddr(address, c.dialer != nil)
 	if err != nil {
 		return err
 	}
-	theirEphPub, err := btcec.ParsePubKey(theirEphPubBytes, btcec.S256())
-	if err != nil {
-		return err
+	if _, ok := remoteAddr.(*OnionAddr); ok && c.dialer == nil {
+		return ErrOnionNoProxy
 	}
 
-	// Do non-interactive diffie with ephemeral pubkeys. Sha256 for good
-	// luck.
-	sessionKey := fastsha256.Sum256(
-		btcec.GenerateSharedSecret(ourEphemeralPriv, theirEphPub),
-	)
+	dialer := c.dialer
+	if dialer == nil {
+		dialer = &net.Dialer{Timeout: handshakeTimeout}
+	}
 
-	// Now that we've derive the session key, we can initialize the
-	// chacha20poly1305 AEAD instance which will be used for the remainder of
-	// the session.
-	c.chachaStream, err = chacha20poly1305.New(sessionKey[:])
+	// First, open the TCP connection itself.
+	c.Conn, err = dialer.Dial("tcp", address)
 	if err != nil {
 		return err
 	}
+	c.remoteAddr = remoteAddr
 
-	c.myNonceInt = 1 << 63
-	c.remoteNonceInt = 0
-
-	c.RemotePub = theirEphPub
-	c.Authed = false
-
-	// Session is now open and confidential but not yet authenticated...
-	// So auth!
-	if len(remoteId) == 20 {
-		// Only know pubkey hash (20 bytes).
-		err = c.authPKH(myId, remoteId, ourEphemeralPub.SerializeCompressed())
-	} else {
-		// Must be 33 byte pubkey.
-		err = c.authPubKey(myId, remoteId, ourEphemeralPub.SerializeCompressed())
+	// Bound the handshake by a deadline, so an unresponsive remote node
+	// is unable to stall us indefinitely.
+	if err := c.Conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		c.Conn.Close()
+		return err
 	}
-	if err != nil {
+	if err := c.initiateHandshake(myId, remotePub); err != nil {
+		c.Conn.Close()
 		return err
 	}
 
-	return nil
+	return c.Conn.SetDeadline(time.Time{})
 }
 
-// authPubKey...
-func (c *LNDConn) authPubKey(
-	myId *btcec.PrivateKey, remotePubBytes, localEphPubBytes []byte) error {
-	if c.Authed {
-		return fmt.Errorf("%s already authed", c.RemotePub)
-	}
+// initiateHandshake executes the initiator's side of the handshake over the
+// underlying connection.
+func (c *LNDConn) initiateHandshake(myId *btcec.PrivateKey,
+	remotePub *btcec.PublicKey) error {
 
-	// Since we already know their public key, we can immediately generate
-	// the DH proof without an additional round-trip.
-	theirPub, err := btcec.ParsePubKey(remotePubBytes, btcec.S256())
-	if err != nil {
-		return err
-	}
-	theirPKH := btcutil.Hash160(remotePubBytes)
-	idDH := fastsha256.Sum256(btcec.GenerateSharedSecret(myId, theirPub))
-	myDHproof := btcutil.Hash160(append(c.RemotePub.SerializeCompressed(), idDH[:]...))
-
-	// Send over the 73 byte authentication message: my pubkey, their
-	// pubkey hash, DH proof.
-	var authMsg [73]byte
-	copy(authMsg[:33], myId.PubKey().SerializeCompressed())
-	copy(authMsg[33:], theirPKH)
-	copy(authMsg[53:], myDHproof)
-	if _, err = c.Conn.Write(authMsg[:]); err != nil {
-		return nil
-	}
+	c.noise = newNoiseMachine(true, myId, remotePub)
 
-	// Await, their response. They should send only the 20-byte DH proof.
-	resp := make([]byte, 20)
-	_, err = c.Conn.Read(resp)
+	// Initiate the handshake by sending the first act to the responder.
+	actOne, err := c.noise.genActOne()
 	if err != nil {
 		return err
 	}
-
-	// Verify that their proof matches our locally computed version.
-	theirDHproof := btcutil.Hash160(append(localEphPubBytes, idDH[:]...))
-	if !hmac.Equal(resp, theirDHproof) {
-		return fmt.Errorf("invalid DH proof %x", theirDHproof)
-	}
-
-	// Proof checks out, auth complete.
-	c.RemotePub = theirPub
-	theirAdr := btcutil.Hash160(theirPub.SerializeCompressed())
-	copy(c.RemoteLNId[:], theirAdr[:16])
-	c.Authed = true
-
-	return nil
-}
-
-// authPKH...
-func (c *LNDConn) authPKH(
-	myId *btcec.PrivateKey, theirPKH, localEphPubBytes []byte) error {
-	if c.Authed {
-		return fmt.Errorf("%s already authed", c.RemotePub)
-	}
-	if len(theirPKH) != 20 {
-		return fmt.Errorf("remote PKH must be 20 bytes, got %d",
-			len(theirPKH))
+	if _, err := c.Conn.Write(actOne[:]); err != nil {
+		return err
 	}
 
-	// Send 53 bytes: our pubkey, and the remote's pubkey hash.
-	var greetingMsg [53]byte
-	copy(greetingMsg[:33], myId.PubKey().SerializeCompressed())
-	copy(greetingMsg[33:], theirPKH)
-	if _, err := c.Conn.Write(greetingMsg[:]); err != nil {
+	// If the responder holds the static key we expect, then they'll
+	// respond with the second act.
+	var actTwo [actTwoSize]byte
+	if _, err := io.ReadFull(c.Conn, actTwo[:]); err != nil {
 		return err
 	}
-
-	// Wait for their response.
-	// TODO(tadge): add timeout here
-	//  * NOTE(roasbeef): read timeout should be set on the underlying
-	//    net.Conn.
-	resp := make([]byte, 53)
-	if _, err := c.Conn.Read(resp); err != nil {
+	if err := c.noise.recvActTwo(actTwo); err != nil {
 		return err
 	}
 
-	// Parse their long-term public key, and generate the DH proof.
-	theirPub, err := btcec.ParsePubKey(resp[:33], btcec.S256())
+	// Finally, complete the handshake by sending our encrypted static
+	// key to the responder within the final act.
+	actThree, err := c.noise.genActThree()
 	if err != nil {
 		return err
 	}
-	idDH := fastsha256.Sum256(btcec.GenerateSharedSecret(myId, theirPub))
-	theirDHproof := btcutil.Hash160(append(localEphPubBytes, idDH[:]...))
-
-	// Verify that their DH proof matches the one we just generated.
-	if !hmac.Equal(resp[33:], theirDHproof) {
-		return fmt.Errorf("Invalid DH proof %x", theirDHproof)
-	}
-
-	// If their DH proof checks out, then send our own.
-	myDHproof := btcutil.Hash160(append(c.RemotePub.SerializeCompressed(), idDH[:]...))
-	if _, err = c.Conn.Write(myDHproof); err != nil {
+	if _, err := c.Conn.Write(actThree[:]); err != nil {
 		return err
 	}
 
-	// Proof sent, auth complete.
-	c.RemotePub = theirPub
-	theirAdr := btcutil.Hash160(theirPub.SerializeCompressed())
-	copy(c.RemoteLNId[:], theirAdr[:16])
-	c.Authed = true
+	c.setRemotePub(remotePub)
 
 	return nil
 }
 
+// setRemotePub records the authenticated static key of the remote node.
+func (c *LNDConn) setRemotePub(remotePub *btcec.PublicKey) {
+	c.RemotePub = remotePub
+	theirAdr := btcutil.Hash160(remotePub.SerializeCompressed())
+	copy(c.RemoteLNId[:], theirAdr[:16])
+}
+
 // Read reads data from the connection.
 // Read can be made to time out and return a Error with Timeout() == true
 // after a fixed time limit; see SetDeadline and SetReadDeadline.
@@ -259,20 +176,7 @@ func (c *LNDConn) Read(b []byte) (n int, err error) {
 	// we read the next record, and feed it into the buffer. Otherwise, we
 	// read directly from the buffer.
 	if c.readBuf.Len() == 0 {
-		// The buffer is empty, so read the next cipher text.
-		ctext, err := readClear(c.Conn)
-		if err != nil {
-			return 0, err
-		}
-
-		// Encode the current remote nonce, so we can use it to decrypt
-		// the cipher text.
-		var nonceBuf [8]byte
-		binary.BigEndian.PutUint64(nonceBuf[:], c.remoteNonceInt)
-
-		c.remoteNonceInt++ // increment remote nonce, no matter what...
-
-		msg, err := c.chachaStream.Open(nil, nonceBuf[:], ctext, nil)
+		msg, err := c.noise.readMessage(c.Conn)
 		if err != nil {
 			return 0, err
 		}
@@ -285,7 +189,8 @@ func (c *LNDConn) Read(b []byte) (n int, err error) {
 	return c.readBuf.Read(b)
 }
 
-// Write writes data to the connection.
+// Write writes data to the connection. Data larger than the maximum size of
+// a single record is split across several records.
 // Write can be made to time out and return a Error with Timeout() == true
 // after a fixed time limit; see SetDeadline and SetWriteDeadline.
 // Part of the net.Conn interface.
@@ -294,30 +199,27 @@ func (c *LNDConn) Write(b []byte) (n int, err error) {
 		return 0, fmt.Errorf("write to %x nil", c.RemoteLNId)
 	}
 
-	// first encrypt message with shared key
-	var nonceBuf [8]byte
-	binary.BigEndian.PutUint64(nonceBuf[:], c.myNonceInt)
-	c.myNonceInt++ // increment mine
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > maxMessageSize {
+			chunk = chunk[:maxMessageSize]
+		}
 
-	ctext := c.chachaStream.Seal(nil, nonceBuf[:], b, nil)
-	if err != nil {
-		return 0, err
-	}
-	if len(ctext) > 65530 {
-		return 0, fmt.Errorf("Write to %x too long, %d bytes",
-			c.RemoteLNId, len(ctext))
+		if err := c.noise.writeMessage(c.Conn, chunk); err != nil {
+			return n, err
+		}
+
+		n += len(chunk)
+		b = b[len(chunk):]
 	}
 
-	// use writeClear to prepend length / destination header
-	return writeClear(c.Conn, ctext)
+	return n, nil
 }
 
 // Close closes the connection.
 // Any blocked Read or Write operations will be unblocked and return errors.
 // Part of the net.Conn interface.
 func (c *LNDConn) Close() error {
-	c.myNonceInt = 0
-	c.remoteNonceInt = 0
 	c.RemotePub = nil
 
 	return c.Conn.Close()
@@ -325,14 +227,19 @@ func (c *LNDConn) Close() error {
 
 // LocalAddr returns the local network address.
 // Part of the net.Conn interface.
-// If PBX reports address of pbx host.
 func (c *LNDConn) LocalAddr() net.Addr {
 	return c.Conn.LocalAddr()
 }
 
-// RemoteAddr returns the remote network address.
+// RemoteAddr returns the remote network address. For outbound connections,
+// this is the address which was dialed, even if the connection was routed
+// through a proxy.
 // Part of the net.Conn interface.
 func (c *LNDConn) RemoteAddr() net.Addr {
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+
 	return c.Conn.RemoteAddr()
 }
 
@@ -1,26 +1,76 @@
 package lndc
 
 import (
-	"bytes"
-	"fmt"
+	"errors"
+	"io"
 	"net"
+	"sync"
+	"time"
 
-	"github.com/btcsuite/fastsha256"
-	"github.com/codahale/chacha20poly1305"
 	"github.com/roasbeef/btcd/btcec"
-	"github.com/roasbeef/btcutil"
 )
 
-// Listener...
+const (
+	// handshakeTimeout is the maximum amount of time a remote node may
+	// take to complete the handshake. Connections which fail to complete
+	// the handshake in time are dropped.
+	handshakeTimeout = 10 * time.Second
+
+	// maxPendingHandshakes is the maximum number of handshakes which may
+	// be in progress at once. Any inbound connections received while at
+	// this limit are dropped.
+	maxPendingHandshakes = 100
+
+	// maxHandshakesPerHost is the maximum number of handshakes a single
+	// host may initiate within each rateLimitInterval.
+	maxHandshakesPerHost = 10
+
+	// rateLimitInterval is the interval over which the number of
+	// handshakes initiated by each host is limited.
+	rateLimitInterval = time.Minute
+)
+
+var (
+	// ErrListenerClosed is returned by Accept once the listener has been
+	// closed.
+	ErrListenerClosed = errors.New("lndc listener closed")
+)
+
+// Listener is an implementation of a net.Listener which executes the
+// responder's side of the handshake for each inbound connection. Handshakes
+// are executed concurrently, off the accept loop, so a single slow, or
+// malicious host is unable to stall other inbound connections. Each
+// handshake is bounded by a deadline, the number of concurrent handshakes is
+// limited, and the rate at which each host may initiate handshakes is
+// limited. Loopback hosts are exempt from the rate limit, as all inbound
+// connections relayed by a local Tor daemon originate from loopback.
 type Listener struct {
 	longTermPriv *btcec.PrivateKey
 
 	tcp *net.TCPListener
+
+	// handshakeSema limits the number of handshakes in progress at once.
+	// A slot is held until the connection has either been delivered to
+	// Accept, or dropped.
+	handshakeSema chan struct{}
+
+	// limiter limits the rate at which each host may initiate
+	// handshakes.
+	limiter *rateLimiter
+
+	// conns houses the connections which have completed the handshake,
+	// and are awaiting a call to Accept.
+	conns chan *LNDConn
+
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+	quit      chan struct{}
 }
 
 var _ net.Listener = (*Listener)(nil)
 
-// NewListener...
+// NewListener creates a new Listener on the passed address, which
+// authenticates inbound connections using the passed static private key.
 func NewListener(localPriv *btcec.PrivateKey, listenAddr string) (*Listener, error) {
 	addr, err := net.ResolveTCPAddr("tcp", listenAddr)
 	if err != nil {
@@ -32,158 +82,159 @@ func NewListener(localPriv *btcec.PrivateKey, listenAddr string) (*Listener, err
 		return nil, err
 	}
 
-	return &Listener{localPriv, l}, nil
+	lis := &Listener{
+		longTermPriv:  localPriv,
+		tcp:           l,
+		handshakeSema: make(chan struct{}, maxPendingHandshakes),
+		limiter:       newRateLimiter(maxHandshakesPerHost, rateLimitInterval),
+		conns:         make(chan *LNDConn),
+		quit:          make(chan struct{}),
+	}
+
+	lis.wg.Add(1)
+	go lis.listen()
+
+	return lis, nil
 }
 
-// Accept waits for and returns the next connection to the listener.
-// Part of the net.Listener interface.
-func (l *Listener) Accept() (c net.Conn, err error) {
-	conn, err := l.tcp.Accept()
-	if err != nil {
-		return nil, err
-	}
+// listen accepts inbound TCP connections, dispatching a goroutine to execute
+// the handshake of each.
+//
+// NOTE: This MUST be run as a goroutine.
+func (l *Listener) listen() {
+	defer l.wg.Done()
+
+	for {
+		conn, err := l.tcp.Accept()
+		if err != nil {
+			select {
+			case <-l.quit:
+				return
+			default:
+			}
+
+			log.Errorf("unable to accept connection: %v", err)
+
+			// In the case of a persistent error, back off
+			// briefly so we don't spin.
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
 
-	lndc := NewConn(conn)
+		remoteAddr := conn.RemoteAddr()
+
+		// Drop the connection if the remote host has already initiated
+		// too many handshakes recently. Connections from loopback are
+		// only bounded by the limit on pending handshakes, as they
+		// can't be attributed to a single remote host.
+		host := hostFromAddr(remoteAddr)
+		if !isLoopback(host) && !l.limiter.allow(host) {
+			log.Warnf("dropping connection from %v: handshake rate "+
+				"limit exceeded", remoteAddr)
+			conn.Close()
+			continue
+		}
 
-	// Exchange an ephemeral public key with the remote connection in order
-	// to establish a confidential connection before we attempt to
-	// authenticated.
-	ephemeralKey, err := l.createCipherConn(lndc)
-	if err != nil {
-		return nil, err
-	}
+		// Similarly, drop the connection if we're already at our limit
+		// of pending handshakes.
+		select {
+		case l.handshakeSema <- struct{}{}:
+		default:
+			log.Warnf("dropping connection from %v: too many "+
+				"pending handshakes", remoteAddr)
+			conn.Close()
+			continue
+		}
 
-	// Now that we've established an encrypted connection, authenticate the
-	// identity of the remote host.
-	ephemeralPub := ephemeralKey.PubKey().SerializeCompressed()
-	if err := l.authenticateConnection(l.longTermPriv, lndc, ephemeralPub); err != nil {
-		lndc.Close()
-		return nil, err
+		go l.handshake(conn)
 	}
-
-	return lndc, nil
 }
 
-// createCipherConn....
-func (l *Listener) createCipherConn(lnConn *LNDConn) (*btcec.PrivateKey, error) {
-	var err error
-	var theirEphPubBytes []byte
+// handshake executes the handshake over the passed connection, delivering
+// the connection to Accept if it succeeds.
+//
+// NOTE: This MUST be run as a goroutine.
+func (l *Listener) handshake(conn net.Conn) {
+	// Release our slot only once the connection is no longer pending,
+	// so connections awaiting Accept also count towards the limit.
+	defer func() {
+		<-l.handshakeSema
+	}()
 
-	// First, read and deserialize their ephemeral public key.
-	theirEphPubBytes, err = readClear(lnConn.Conn)
-	if err != nil {
-		return nil, err
+	lndc := NewConn(conn)
+
+	// Bound the handshake by a deadline, so slow hosts are unable to
+	// occupy a handshake slot indefinitely.
+	err := conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err == nil {
+		err = l.completeHandshake(lndc)
 	}
-	if len(theirEphPubBytes) != 33 {
-		return nil, fmt.Errorf("Got invalid %d byte eph pubkey %x\n",
-			len(theirEphPubBytes), theirEphPubBytes)
+	if err == nil {
+		err = conn.SetDeadline(time.Time{})
 	}
-	theirEphPub, err := btcec.ParsePubKey(theirEphPubBytes, btcec.S256())
 	if err != nil {
-		return nil, err
+		log.Infof("unable to complete handshake with %v: %v",
+			conn.RemoteAddr(), err)
+		conn.Close()
+		return
 	}
 
-	// Once we've parsed and verified their key, generate, and send own
-	// ephemeral key pair for use within this session.
-	myEph, err := btcec.NewPrivateKey(btcec.S256())
-	if err != nil {
-		return nil, err
+	select {
+	case l.conns <- lndc:
+	case <-l.quit:
+		conn.Close()
 	}
-	if _, err := writeClear(lnConn.Conn, myEph.PubKey().SerializeCompressed()); err != nil {
-		return nil, err
-	}
-
-	// Now that we have both keys, do non-interactive diffie with ephemeral
-	// pubkeys, sha256 for good luck.
-	sessionKey := fastsha256.Sum256(
-		btcec.GenerateSharedSecret(myEph, theirEphPub),
-	)
-
-	lnConn.chachaStream, err = chacha20poly1305.New(sessionKey[:])
-
-	lnConn.remoteNonceInt = 1 << 63
-	lnConn.myNonceInt = 0
-
-	lnConn.RemotePub = theirEphPub
-	lnConn.Authed = false
+}
 
-	return myEph, nil
+// Accept waits for and returns the next connection to the listener. The
+// responder's side of the handshake is executed before the connection is
+// returned, so the static key of the remote node is authenticated.
+// Part of the net.Listener interface.
+func (l *Listener) Accept() (c net.Conn, err error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.quit:
+		return nil, ErrListenerClosed
+	}
 }
 
-// AuthListen...
-func (l *Listener) authenticateConnection(
-	myId *btcec.PrivateKey, lnConn *LNDConn, localEphPubBytes []byte) error {
-	var err error
+// completeHandshake executes the responder's side of the handshake over the
+// passed connection.
+func (l *Listener) completeHandshake(lndc *LNDConn) error {
+	noise := newNoiseMachine(false, l.longTermPriv, nil)
 
-	// TODO(roasbeef): should be using read/write clear here?
-	slice := make([]byte, 73)
-	n, err := lnConn.Conn.Read(slice)
-	if err != nil {
+	// The initiator begins the handshake with the first act, proving
+	// they know our static key.
+	var actOne [actOneSize]byte
+	if _, err := io.ReadFull(lndc.Conn, actOne[:]); err != nil {
 		return err
 	}
-
-	authmsg := slice[:n]
-	if len(authmsg) != 53 && len(authmsg) != 73 {
-		return fmt.Errorf("got auth message of %d bytes, "+
-			"expect 53 or 73", len(authmsg))
-	}
-
-	myPKH := btcutil.Hash160(l.longTermPriv.PubKey().SerializeCompressed())
-	if !bytes.Equal(myPKH, authmsg[33:53]) {
-		return fmt.Errorf(
-			"remote host asking for PKH %x, that's not me", authmsg[33:53])
+	if err := noise.recvActOne(actOne); err != nil {
+		return err
 	}
 
-	// do DH with id keys
-	theirPub, err := btcec.ParsePubKey(authmsg[:33], btcec.S256())
+	// Respond with the second act, committing to our own ephemeral key.
+	actTwo, err := noise.genActTwo()
 	if err != nil {
 		return err
 	}
-	idDH := fastsha256.Sum256(
-		btcec.GenerateSharedSecret(l.longTermPriv, theirPub),
-	)
-
-	myDHproof := btcutil.Hash160(
-		append(lnConn.RemotePub.SerializeCompressed(), idDH[:]...),
-	)
-	theirDHproof := btcutil.Hash160(append(localEphPubBytes, idDH[:]...))
-
-	// If they already know our public key, then execute the fast path.
-	// Verify their DH proof, and send our own.
-	if len(authmsg) == 73 {
-		// Verify their DH proof.
-		if !bytes.Equal(authmsg[53:], theirDHproof) {
-			return fmt.Errorf("invalid DH proof from %s",
-				lnConn.RemoteAddr().String())
-		}
-
-		// Their DH proof checks out, so send ours now.
-		if _, err = lnConn.Conn.Write(myDHproof); err != nil {
-			return err
-		}
-	} else {
-		// Otherwise, they don't yet know our public key. So we'll send
-		// it over to them, so we can both compute the DH proof.
-		msg := append(l.longTermPriv.PubKey().SerializeCompressed(), myDHproof...)
-		if _, err = lnConn.Conn.Write(msg); err != nil {
-			return err
-		}
-
-		resp := make([]byte, 20)
-		if _, err := lnConn.Conn.Read(resp); err != nil {
-			return err
-		}
+	if _, err := lndc.Conn.Write(actTwo[:]); err != nil {
+		return err
+	}
 
-		// Verify their DH proof.
-		if bytes.Equal(resp, theirDHproof) == false {
-			return fmt.Errorf("Invalid DH proof %x", theirDHproof)
-		}
+	// Finally, the initiator reveals, and proves ownership of their
+	// static key within the third act.
+	var actThree [actThreeSize]byte
+	if _, err := io.ReadFull(lndc.Conn, actThree[:]); err != nil {
+		return err
+	}
+	if err := noise.recvActThree(actThree); err != nil {
+		return err
 	}
 
-	theirAdr := btcutil.Hash160(theirPub.SerializeCompressed())
-	copy(lnConn.RemoteLNId[:], theirAdr[:16])
-	lnConn.RemotePub = theirPub
-	lnConn.Authed = true
+	lndc.noise = noise
+	lndc.setRemotePub(noise.remoteStatic)
 
 	return nil
 }
@@ -192,7 +243,14 @@ func (l *Listener) authenticateConnection(
 // Any blocked Accept operations will be unblocked and return errors.
 // Part of the net.Listener interface.
 func (l *Listener) Close() error {
-	return l.tcp.Close()
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.quit)
+		err = l.tcp.Close()
+		l.wg.Wait()
+	})
+
+	return err
 }
 
 // Addr returns the listener's network address.
@@ -200,3 +258,76 @@ func (l *Listener) Close() error {
 func (l *Listener) Addr() net.Addr {
 	return l.tcp.Addr()
 }
+
+// hostFromAddr returns the host portion of the passed address, such that all
+// connections from a single host are subject to the same rate limit.
+func hostFromAddr(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+
+	return host
+}
+
+// isLoopback returns true if the passed host is a loopback address.
+func isLoopback(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// rateLimiter limits the number of events attributed to each host within a
+// fixed interval.
+type rateLimiter struct {
+	maxEvents int
+	interval  time.Duration
+
+	windows   map[string]*rateWindow
+	lastPrune time.Time
+}
+
+// rateWindow tracks the number of events attributed to a host since the
+// start of its current interval.
+type rateWindow struct {
+	start     time.Time
+	numEvents int
+}
+
+// newRateLimiter creates a new rateLimiter which allows at most maxEvents per
+// host within each interval.
+func newRateLimiter(maxEvents int, interval time.Duration) *rateLimiter {
+	return &rateLimiter{
+		maxEvents: maxEvents,
+		interval:  interval,
+		windows:   make(map[string]*rateWindow),
+		lastPrune: time.Now(),
+	}
+}
+
+// allow records a new event for the passed host, returning false if the host
+// has exceeded its limit within the current interval.
+//
+// NOTE: This method isn't safe for concurrent use.
+func (r *rateLimiter) allow(host string) bool {
+	now := time.Now()
+
+	// Periodically prune the windows of hosts which haven't been seen
+	// within the last interval, so the map doesn't grow without bound.
+	if now.Sub(r.lastPrune) >= r.interval {
+		for h, window := range r.windows {
+			if now.Sub(window.start) >= r.interval {
+				delete(r.windows, h)
+			}
+		}
+		r.lastPrune = now
+	}
+
+	window, ok := r.windows[host]
+	if !ok || now.Sub(window.start) >= r.interval {
+		window = &rateWindow{start: now}
+		r.windows[host] = window
+	}
+
+	window.numEvents++
+	return window.numEvents <= r.maxEvents
+}
@@ -0,0 +1,142 @@
+package lndc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/roasbeef/btcd/btcec"
+)
+
+func TestListenerSlowHandshake(t *testing.T) {
+	localPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate local priv key: %v", err)
+	}
+	remotePriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate remote priv key: %v", err)
+	}
+
+	listener, err := NewListener(localPriv, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	// First, open a raw TCP connection to the listener, but never begin
+	// the handshake.
+	slowConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial listener: %v", err)
+	}
+	defer slowConn.Close()
+
+	// The stalled handshake shouldn't prevent another node from
+	// connecting to the listener.
+	conn := NewConn(nil)
+	dialErr := make(chan error, 1)
+	go func() {
+		dialErr <- conn.Dial(remotePriv, listener.Addr().String(),
+			localPriv.PubKey().SerializeCompressed())
+	}()
+
+	acceptChan := make(chan net.Conn, 1)
+	go func() {
+		c, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		acceptChan <- c
+	}()
+
+	select {
+	case c := <-acceptChan:
+		defer c.Close()
+		if !c.(*LNDConn).RemotePub.IsEqual(remotePriv.PubKey()) {
+			t.Fatalf("accepted connection from the wrong node")
+		}
+	case <-time.After(handshakeTimeout / 2):
+		t.Fatalf("connection stalled by slow handshake")
+	}
+
+	if err := <-dialErr; err != nil {
+		t.Fatalf("unable to establish connection: %v", err)
+	}
+	conn.Close()
+}
+
+func TestRateLimiter(t *testing.T) {
+	const (
+		maxEvents = 3
+		interval  = 100 * time.Millisecond
+	)
+	limiter := newRateLimiter(maxEvents, interval)
+
+	// The first maxEvents events of a host should be allowed, with any
+	// further events rejected.
+	for i := 0; i < maxEvents; i++ {
+		if !limiter.allow("10.0.0.1") {
+			t.Fatalf("event %v should be allowed", i)
+		}
+	}
+	if limiter.allow("10.0.0.1") {
+		t.Fatalf("event should exceed the rate limit")
+	}
+
+	// Other hosts should be unaffected.
+	if !limiter.allow("10.0.0.2") {
+		t.Fatalf("event from another host should be allowed")
+	}
+
+	// Once the interval has passed, the host should be allowed to
+	// initiate events once again, and the stale windows should be pruned.
+	time.Sleep(interval)
+	if !limiter.allow("10.0.0.1") {
+		t.Fatalf("event should be allowed after the interval")
+	}
+	if _, ok := limiter.windows["10.0.0.2"]; ok {
+		t.Fatalf("stale window should be pruned")
+	}
+}
+
+func TestListenerLoopbackNotRateLimited(t *testing.T) {
+	localPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate local priv key: %v", err)
+	}
+
+	listener, err := NewListener(localPriv, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	// All connections relayed by a local Tor daemon originate from
+	// loopback, so they shouldn't be subject to the per-host rate limit.
+	for i := 0; i < maxHandshakesPerHost+1; i++ {
+		remotePriv, err := btcec.NewPrivateKey(btcec.S256())
+		if err != nil {
+			t.Fatalf("unable to generate remote priv key: %v", err)
+		}
+
+		conn := NewConn(nil)
+		dialErr := make(chan error, 1)
+		go func() {
+			dialErr <- conn.Dial(remotePriv,
+				listener.Addr().String(),
+				localPriv.PubKey().SerializeCompressed())
+		}()
+
+		c, err := listener.Accept()
+		if err != nil {
+			t.Fatalf("unable to accept connection %v: %v", i, err)
+		}
+		if err := <-dialErr; err != nil {
+			t.Fatalf("unable to establish connection %v: %v", i, err)
+		}
+
+		c.Close()
+		conn.Close()
+	}
+}
@@ -20,7 +20,7 @@ type LNAdr struct {
 	PubKey *btcec.PublicKey
 
 	Base58Adr btcutil.Address // Base58 encoded address (1XXX...)
-	NetAddr   *net.TCPAddr    // IP address
+	NetAddr   net.Addr        // IP, or onion address
 
 	name        string // human readable name?  Not a thing yet.
 	host        string // internet host this ID is reachable at. also not a thing
@@ -30,7 +30,7 @@ type LNAdr struct {
 }
 
 // newLnAdr....
-func NewLnAdr(addr *net.TCPAddr, pubkey *btcec.PublicKey,
+func NewLnAdr(addr net.Addr, pubkey *btcec.PublicKey,
 	net *chaincfg.Params) (*LNAdr, error) {
 
 	hash160 := btcutil.Hash160(pubkey.SerializeCompressed())
@@ -48,21 +48,25 @@ func NewLnAdr(addr *net.TCPAddr, pubkey *btcec.PublicKey,
 }
 
 // newLnAddr...
-func LnAddrFromString(encodedAddr string, netParams *chaincfg.Params) (*LNAdr, error) {
+// If the address is to be dialed through a proxy, then proxied should be
+// true, leaving the resolution of its hostname to the proxy.
+func LnAddrFromString(encodedAddr string, netParams *chaincfg.Params,
+	proxied bool) (*LNAdr, error) {
+
 	// The format of an lnaddr is "<pubkey or pkh>@host"
 	idHost := strings.Split(encodedAddr, "@")
 	if len(idHost) != 2 {
 		return nil, fmt.Errorf("invalid format for lnaddr string: %v", encodedAddr)
 	}
 
-	// Attempt to resolve the IP address, this handles parsing IPv6 zones,
-	// and such.
-	ipAddr, err := net.ResolveTCPAddr("tcp", idHost[1])
+	// Attempt to resolve the address, this handles parsing IPv6 zones,
+	// and onion addresses which can only be reached through Tor.
+	netAddr, err := ResolveAddr(idHost[1], proxied)
 	if err != nil {
 		return nil, err
 	}
 
-	addr := &LNAdr{NetAddr: ipAddr, net: netParams}
+	addr := &LNAdr{NetAddr: netAddr, net: netParams}
 
 	idLen := len(idHost[0])
 	switch {
@@ -0,0 +1,523 @@
+package lndc
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/codahale/chacha20poly1305"
+	"github.com/roasbeef/btcd/btcec"
+)
+
+// The lndc transport is an implementation of the Noise protocol framework,
+// instantiated as Noise_XK_secp256k1_ChaChaPoly_SHA256. The XK handshake
+// pattern requires the initiator to know the static public key of the
+// responder ahead of time, while the initiator's own static key is
+// transmitted to the responder, encrypted, within the final act of the
+// handshake. As a result, both sides are mutually authenticated once the
+// handshake completes, and the identity of the initiator is hidden from
+// passive observers.
+//
+// The handshake consists of three acts:
+//
+//   <- s
+//   ...
+//   -> e, es
+//   <- e, ee
+//   -> s, se
+//
+// Act one and act two are each 50 bytes: a version byte, a compressed
+// ephemeral public key, and a 16-byte MAC. Act three is 66 bytes: a version
+// byte, the initiator's encrypted static public key (along with its MAC), and
+// a final 16-byte MAC.
+//
+// Once the handshake completes, each direction of the connection is encrypted
+// using a distinct key. Each message is sent as an encrypted 2-byte length
+// prefix followed by the encrypted message body, such that a passive observer
+// learns nothing of the boundaries of messages. Both the length prefix and
+// the body are authenticated with their own MAC. After every
+// keyRotationInterval encryptions or decryptions, the key for that direction
+// is rotated, providing forward secrecy within a long lived connection.
+
+const (
+	// protocolName is the precise instantiation of the Noise protocol
+	// framework used by lndc.
+	protocolName = "Noise_XK_secp256k1_ChaChaPoly_SHA256"
+
+	// handshakeVersion is the version of the handshake prepended to each
+	// act. Acts carrying an unknown version are rejected.
+	handshakeVersion = 0
+
+	// macSize is the size of the MAC appended to each ciphertext by
+	// chacha20poly1305.
+	macSize = 16
+
+	// lengthHeaderSize is the size of the length prefix of each message
+	// sent once the handshake has completed.
+	lengthHeaderSize = 2
+
+	// encHeaderSize is the size of the encrypted length prefix of each
+	// message, including its MAC.
+	encHeaderSize = lengthHeaderSize + macSize
+
+	// keyRotationInterval is the number of messages encrypted or
+	// decrypted with a key after which the key is rotated.
+	keyRotationInterval = 1000
+
+	// maxMessageSize is the largest message which may be encrypted within
+	// a single record, as the length prefix is only 2 bytes.
+	maxMessageSize = math.MaxUint16
+
+	// actOneSize is the size of the first act of the handshake:
+	// version (1) || ephemeral key (33) || MAC (16).
+	actOneSize = 1 + 33 + macSize
+
+	// actTwoSize is the size of the second act of the handshake, which
+	// mirrors the first.
+	actTwoSize = actOneSize
+
+	// actThreeSize is the size of the third act of the handshake:
+	// version (1) || encrypted static key (33 + 16) || MAC (16).
+	actThreeSize = 1 + 33 + 2*macSize
+)
+
+var (
+	// prologue is mixed into the handshake digest of both sides, binding
+	// the handshake to the lightning protocol.
+	prologue = []byte("lightning")
+
+	// ErrMaxMessageLengthExceeded is returned when a message to be
+	// encrypted exceeds the maximum size of a single record.
+	ErrMaxMessageLengthExceeded = errors.New("the message exceeds the " +
+		"maximum size of a single record")
+)
+
+// cipherState houses the key, and nonce used to encrypt or decrypt messages
+// in a single direction of a connection. Once the nonce reaches
+// keyRotationInterval, a new key is derived from the current key, and the
+// salt, which is the chaining key at the time of the rotation.
+type cipherState struct {
+	nonce     uint64
+	secretKey [32]byte
+	salt      [32]byte
+
+	cipher cipher.AEAD
+}
+
+// encrypt returns the ciphertext, along with a MAC, of the passed plaintext,
+// authenticating the associated data. The result is appended to dst.
+func (c *cipherState) encrypt(associatedData, dst, plainText []byte) []byte {
+	defer c.advance()
+
+	var nonce [8]byte
+	binary.LittleEndian.PutUint64(nonce[:], c.nonce)
+
+	return c.cipher.Seal(dst, nonce[:], plainText, associatedData)
+}
+
+// decrypt authenticates, then decrypts the passed ciphertext, appending the
+// resulting plaintext to dst.
+func (c *cipherState) decrypt(associatedData, dst, cipherText []byte) ([]byte, error) {
+	defer c.advance()
+
+	var nonce [8]byte
+	binary.LittleEndian.PutUint64(nonce[:], c.nonce)
+
+	return c.cipher.Open(dst, nonce[:], cipherText, associatedData)
+}
+
+// advance increments the nonce, rotating the key once the nonce reaches the
+// key rotation interval.
+func (c *cipherState) advance() {
+	c.nonce++
+	if c.nonce == keyRotationInterval {
+		c.rotateKey()
+	}
+}
+
+// initializeKey sets the key of the cipherState, resetting the nonce.
+func (c *cipherState) initializeKey(key [32]byte) {
+	c.secretKey = key
+	c.nonce = 0
+
+	// The key is always 32 bytes, so creating the cipher can't fail.
+	c.cipher, _ = chacha20poly1305.New(c.secretKey[:])
+}
+
+// initializeKeyWithSalt is identical to initializeKey, though it also sets
+// the salt used to derive the next key upon rotation.
+func (c *cipherState) initializeKeyWithSalt(salt, key [32]byte) {
+	c.salt = salt
+	c.initializeKey(key)
+}
+
+// rotateKey derives the next key, and salt, from the current key and salt,
+// discarding the current key.
+func (c *cipherState) rotateKey() {
+	newSalt, newKey := hkdfTwoKeys(c.salt[:], c.secretKey[:])
+	c.initializeKeyWithSalt(newSalt, newKey)
+}
+
+// symmetricState is the state of the handshake shared between both sides:
+// the chaining key from which the keys of each act are derived, and the
+// handshake digest which commits to every item sent or received.
+type symmetricState struct {
+	cipherState
+
+	chainingKey     [32]byte
+	tempKey         [32]byte
+	handshakeDigest [32]byte
+}
+
+// initializeSymmetric initializes the handshake digest, and chaining key
+// with the name of the protocol.
+func (s *symmetricState) initializeSymmetric(protocolName []byte) {
+	var empty [32]byte
+
+	s.handshakeDigest = sha256.Sum256(protocolName)
+	s.chainingKey = s.handshakeDigest
+	s.initializeKey(empty)
+}
+
+// mixKey derives a new chaining key, and temporary key from the current
+// chaining key and the passed input, typically the result of an ECDH
+// operation. The temporary key is used to encrypt the remainder of the act.
+func (s *symmetricState) mixKey(input []byte) {
+	s.chainingKey, s.tempKey = hkdfTwoKeys(s.chainingKey[:], input)
+	s.initializeKey(s.tempKey)
+}
+
+// mixHash commits the passed data to the handshake digest.
+func (s *symmetricState) mixHash(data []byte) {
+	h := sha256.New()
+	h.Write(s.handshakeDigest[:])
+	h.Write(data)
+
+	copy(s.handshakeDigest[:], h.Sum(nil))
+}
+
+// encryptAndHash encrypts the passed plaintext using the handshake digest as
+// associated data, then commits the ciphertext to the digest.
+func (s *symmetricState) encryptAndHash(plainText []byte) []byte {
+	cipherText := s.encrypt(s.handshakeDigest[:], nil, plainText)
+	s.mixHash(cipherText)
+
+	return cipherText
+}
+
+// decryptAndHash is the inverse of encryptAndHash.
+func (s *symmetricState) decryptAndHash(cipherText []byte) ([]byte, error) {
+	plainText, err := s.decrypt(s.handshakeDigest[:], nil, cipherText)
+	if err != nil {
+		return nil, err
+	}
+	s.mixHash(cipherText)
+
+	return plainText, nil
+}
+
+// handshakeState is the state of a single side of the handshake.
+type handshakeState struct {
+	symmetricState
+
+	initiator bool
+
+	localStatic    *btcec.PrivateKey
+	localEphemeral *btcec.PrivateKey
+
+	remoteStatic    *btcec.PublicKey
+	remoteEphemeral *btcec.PublicKey
+
+	// ephemeralGen generates the ephemeral key used within the
+	// handshake. It's only overridden within tests.
+	ephemeralGen func() (*btcec.PrivateKey, error)
+}
+
+// newHandshakeState creates a new handshakeState. The responder's static key
+// is committed to the handshake digest of both sides, so the handshake only
+// succeeds if the initiator dialed the responder it intended to.
+func newHandshakeState(initiator bool, localStatic *btcec.PrivateKey,
+	remoteStatic *btcec.PublicKey) handshakeState {
+
+	h := handshakeState{
+		initiator:    initiator,
+		localStatic:  localStatic,
+		remoteStatic: remoteStatic,
+		ephemeralGen: func() (*btcec.PrivateKey, error) {
+			return btcec.NewPrivateKey(btcec.S256())
+		},
+	}
+
+	h.initializeSymmetric([]byte(protocolName))
+	h.mixHash(prologue)
+
+	if initiator {
+		h.mixHash(remoteStatic.SerializeCompressed())
+	} else {
+		h.mixHash(localStatic.PubKey().SerializeCompressed())
+	}
+
+	return h
+}
+
+// noiseMachine is a state machine which executes the handshake, then
+// encrypts and decrypts the messages sent over the connection. Once the
+// handshake completes, the remote party's static key is available as
+// remoteStatic.
+type noiseMachine struct {
+	sendCipher cipherState
+	recvCipher cipherState
+
+	handshakeState
+}
+
+// newNoiseMachine creates a new noiseMachine for one side of a connection.
+// The initiator must know the static key of the responder. The responder
+// learns the initiator's static key within the final act of the handshake, so
+// it should pass a nil remote key.
+func newNoiseMachine(initiator bool, localStatic *btcec.PrivateKey,
+	remoteStatic *btcec.PublicKey) *noiseMachine {
+
+	return &noiseMachine{
+		handshakeState: newHandshakeState(initiator, localStatic,
+			remoteStatic),
+	}
+}
+
+// genActOne generates the first act of the handshake, sent by the initiator
+// to the responder: a fresh ephemeral key, and a MAC proving the initiator
+// knows the responder's static key.
+//
+//	-> e, es
+func (n *noiseMachine) genActOne() ([actOneSize]byte, error) {
+	var actOne [actOneSize]byte
+
+	var err error
+	n.localEphemeral, err = n.ephemeralGen()
+	if err != nil {
+		return actOne, err
+	}
+
+	ephemeral := n.localEphemeral.PubKey().SerializeCompressed()
+	n.mixHash(ephemeral)
+
+	es := ecdh(n.remoteStatic, n.localEphemeral)
+	n.mixKey(es)
+
+	authPayload := n.encryptAndHash(nil)
+
+	actOne[0] = handshakeVersion
+	copy(actOne[1:34], ephemeral)
+	copy(actOne[34:], authPayload)
+
+	return actOne, nil
+}
+
+// recvActOne processes the first act of the handshake on the responder's
+// side.
+func (n *noiseMachine) recvActOne(actOne [actOneSize]byte) error {
+	if actOne[0] != handshakeVersion {
+		return fmt.Errorf("act one: invalid handshake version: %v, "+
+			"only %v is valid", actOne[0], handshakeVersion)
+	}
+
+	var err error
+	n.remoteEphemeral, err = btcec.ParsePubKey(actOne[1:34], btcec.S256())
+	if err != nil {
+		return err
+	}
+	n.mixHash(n.remoteEphemeral.SerializeCompressed())
+
+	es := ecdh(n.remoteEphemeral, n.localStatic)
+	n.mixKey(es)
+
+	_, err = n.decryptAndHash(actOne[34:])
+	return err
+}
+
+// genActTwo generates the second act of the handshake, sent by the responder
+// to the initiator: a fresh ephemeral key, and a MAC over the ECDH of both
+// ephemeral keys.
+//
+//	<- e, ee
+func (n *noiseMachine) genActTwo() ([actTwoSize]byte, error) {
+	var actTwo [actTwoSize]byte
+
+	var err error
+	n.localEphemeral, err = n.ephemeralGen()
+	if err != nil {
+		return actTwo, err
+	}
+
+	ephemeral := n.localEphemeral.PubKey().SerializeCompressed()
+	n.mixHash(ephemeral)
+
+	ee := ecdh(n.remoteEphemeral, n.localEphemeral)
+	n.mixKey(ee)
+
+	authPayload := n.encryptAndHash(nil)
+
+	actTwo[0] = handshakeVersion
+	copy(actTwo[1:34], ephemeral)
+	copy(actTwo[34:], authPayload)
+
+	return actTwo, nil
+}
+
+// recvActTwo processes the second act of the handshake on the initiator's
+// side.
+func (n *noiseMachine) recvActTwo(actTwo [actTwoSize]byte) error {
+	if actTwo[0] != handshakeVersion {
+		return fmt.Errorf("act two: invalid handshake version: %v, "+
+			"only %v is valid", actTwo[0], handshakeVersion)
+	}
+
+	var err error
+	n.remoteEphemeral, err = btcec.ParsePubKey(actTwo[1:34], btcec.S256())
+	if err != nil {
+		return err
+	}
+	n.mixHash(n.remoteEphemeral.SerializeCompressed())
+
+	ee := ecdh(n.remoteEphemeral, n.localEphemeral)
+	n.mixKey(ee)
+
+	_, err = n.decryptAndHash(actTwo[34:])
+	return err
+}
+
+// genActThree generates the final act of the handshake, sent by the
+// initiator to the responder: the initiator's encrypted static key, and a MAC
+// over the ECDH of the initiator's static key and the responder's ephemeral
+// key. Once generated, the handshake is complete, and the initiator may begin
+// sending messages.
+//
+//	-> s, se
+func (n *noiseMachine) genActThree() ([actThreeSize]byte, error) {
+	var actThree [actThreeSize]byte
+
+	ourPubkey := n.localStatic.PubKey().SerializeCompressed()
+	cipherText := n.encryptAndHash(ourPubkey)
+
+	se := ecdh(n.remoteEphemeral, n.localStatic)
+	n.mixKey(se)
+
+	authPayload := n.encryptAndHash(nil)
+
+	actThree[0] = handshakeVersion
+	copy(actThree[1:50], cipherText)
+	copy(actThree[50:], authPayload)
+
+	n.split()
+
+	return actThree, nil
+}
+
+// recvActThree processes the final act of the handshake on the responder's
+// side, learning, and authenticating the static key of the initiator. Once
+// processed, the handshake is complete.
+func (n *noiseMachine) recvActThree(actThree [actThreeSize]byte) error {
+	if actThree[0] != handshakeVersion {
+		return fmt.Errorf("act three: invalid handshake version: %v, "+
+			"only %v is valid", actThree[0], handshakeVersion)
+	}
+
+	remotePub, err := n.decryptAndHash(actThree[1:50])
+	if err != nil {
+		return err
+	}
+	n.remoteStatic, err = btcec.ParsePubKey(remotePub, btcec.S256())
+	if err != nil {
+		return err
+	}
+
+	se := ecdh(n.remoteStatic, n.localEphemeral)
+	n.mixKey(se)
+
+	if _, err := n.decryptAndHash(actThree[50:]); err != nil {
+		return err
+	}
+
+	n.split()
+
+	return nil
+}
+
+// split derives the keys used to encrypt each direction of the connection
+// from the final chaining key of the handshake.
+func (n *noiseMachine) split() {
+	sendKey, recvKey := hkdfTwoKeys(n.chainingKey[:], nil)
+	if !n.initiator {
+		sendKey, recvKey = recvKey, sendKey
+	}
+
+	n.sendCipher.initializeKeyWithSalt(n.chainingKey, sendKey)
+	n.recvCipher.initializeKeyWithSalt(n.chainingKey, recvKey)
+}
+
+// writeMessage encrypts, then writes the passed message to w, prefixed with
+// its encrypted length.
+func (n *noiseMachine) writeMessage(w io.Writer, p []byte) error {
+	if len(p) > maxMessageSize {
+		return ErrMaxMessageLengthExceeded
+	}
+
+	var pktLen [lengthHeaderSize]byte
+	binary.BigEndian.PutUint16(pktLen[:], uint16(len(p)))
+
+	packet := make([]byte, 0, encHeaderSize+len(p)+macSize)
+	packet = n.sendCipher.encrypt(nil, packet, pktLen[:])
+	packet = n.sendCipher.encrypt(nil, packet, p)
+
+	_, err := w.Write(packet)
+	return err
+}
+
+// readMessage reads, then decrypts the next message from r.
+func (n *noiseMachine) readMessage(r io.Reader) ([]byte, error) {
+	var encLen [encHeaderSize]byte
+	if _, err := io.ReadFull(r, encLen[:]); err != nil {
+		return nil, err
+	}
+	pktLen, err := n.recvCipher.decrypt(nil, nil, encLen[:])
+	if err != nil {
+		return nil, err
+	}
+
+	cipherText := make([]byte, binary.BigEndian.Uint16(pktLen)+macSize)
+	if _, err := io.ReadFull(r, cipherText); err != nil {
+		return nil, err
+	}
+
+	return n.recvCipher.decrypt(nil, nil, cipherText)
+}
+
+// ecdh performs an ECDH operation between the passed public and private keys,
+// returning the SHA-256 of the resulting compressed point.
+func ecdh(pub *btcec.PublicKey, priv *btcec.PrivateKey) []byte {
+	s := &btcec.PublicKey{}
+	s.X, s.Y = btcec.S256().ScalarMult(pub.X, pub.Y, priv.D.Bytes())
+
+	h := sha256.Sum256(s.SerializeCompressed())
+	return h[:]
+}
+
+// hkdfTwoKeys derives two 32-byte keys from the passed salt and input keying
+// material using HKDF-SHA256.
+func hkdfTwoKeys(salt, ikm []byte) ([32]byte, [32]byte) {
+	var first, second [32]byte
+
+	// It's safe to ignore the errors here as we'll never read past the
+	// available entropy horizon of the HKDF.
+	r := hkdf.New(sha256.New, ikm, salt, nil)
+	r.Read(first[:])
+	r.Read(second[:])
+
+	return first, second
+}
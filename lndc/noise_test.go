@@ -0,0 +1,185 @@
+package lndc
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/roasbeef/btcd/btcec"
+)
+
+// establishTestConnection creates a connected pair of LNDConns over the
+// loopback interface, returning the dialer's side first.
+func establishTestConnection(t *testing.T) (*LNDConn, net.Conn,
+	*btcec.PrivateKey, *btcec.PrivateKey) {
+
+	localPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate local priv key: %v", err)
+	}
+	remotePriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate remote priv key: %v", err)
+	}
+
+	listener, err := NewListener(localPriv, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	conn := NewConn(nil)
+	dialErr := make(chan error, 1)
+	go func() {
+		dialErr <- conn.Dial(remotePriv, listener.Addr().String(),
+			localPriv.PubKey().SerializeCompressed())
+	}()
+
+	localConn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept connection: %v", err)
+	}
+	if err := <-dialErr; err != nil {
+		t.Fatalf("unable to establish connection: %v", err)
+	}
+
+	return conn, localConn, remotePriv, localPriv
+}
+
+func TestNoiseHandshake(t *testing.T) {
+	conn, localConn, remotePriv, localPriv := establishTestConnection(t)
+	defer conn.Close()
+	defer localConn.Close()
+
+	// Each side should have authenticated the static key of the other.
+	if !conn.RemotePub.IsEqual(localPriv.PubKey()) {
+		t.Fatalf("dialer learned the wrong remote key")
+	}
+	listenerPub := localConn.(*LNDConn).RemotePub
+	if !listenerPub.IsEqual(remotePriv.PubKey()) {
+		t.Fatalf("listener learned the wrong remote key")
+	}
+}
+
+func TestNoiseHandshakeWrongKey(t *testing.T) {
+	localPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate local priv key: %v", err)
+	}
+	remotePriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate remote priv key: %v", err)
+	}
+	wrongPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate priv key: %v", err)
+	}
+
+	listener, err := NewListener(localPriv, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	acceptErr := make(chan error, 1)
+	go func() {
+		_, err := listener.Accept()
+		acceptErr <- err
+	}()
+
+	// Dialing the listener with the wrong static key should cause the
+	// listener to reject the first act, failing the handshake on both
+	// sides.
+	conn := NewConn(nil)
+	err = conn.Dial(remotePriv, listener.Addr().String(),
+		wrongPriv.PubKey().SerializeCompressed())
+	if err == nil {
+		t.Fatalf("handshake should fail with the wrong remote key")
+	}
+
+	// As the handshake failed, the connection should never be returned
+	// by Accept.
+	listener.Close()
+	if err := <-acceptErr; err != ErrListenerClosed {
+		t.Fatalf("listener should reject the handshake, instead "+
+			"got: %v", err)
+	}
+}
+
+func TestNoiseKeyRotation(t *testing.T) {
+	conn, localConn, _, _ := establishTestConnection(t)
+	defer conn.Close()
+	defer localConn.Close()
+
+	initialKey := conn.noise.sendCipher.secretKey
+
+	// Each message consumes two nonces, one for the length prefix and one
+	// for the body, so sending this many messages forces the keys of
+	// both sides to be rotated several times.
+	const numMessages = keyRotationInterval * 2
+
+	errChan := make(chan error, 1)
+	go func() {
+		for i := 0; i < numMessages; i++ {
+			msg := []byte{byte(i), byte(i >> 8)}
+			if _, err := conn.Write(msg); err != nil {
+				errChan <- err
+				return
+			}
+		}
+		errChan <- nil
+	}()
+
+	for i := 0; i < numMessages; i++ {
+		msg := []byte{byte(i), byte(i >> 8)}
+
+		readBuf := make([]byte, len(msg))
+		if _, err := localConn.Read(readBuf); err != nil {
+			t.Fatalf("unable to read message %v: %v", i, err)
+		}
+		if !bytes.Equal(readBuf, msg) {
+			t.Fatalf("messages don't match, %x vs %x", readBuf, msg)
+		}
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatalf("unable to write message: %v", err)
+	}
+
+	if conn.noise.sendCipher.secretKey == initialKey {
+		t.Fatalf("send key wasn't rotated")
+	}
+}
+
+func TestNoiseLargeWrite(t *testing.T) {
+	conn, localConn, _, _ := establishTestConnection(t)
+	defer conn.Close()
+	defer localConn.Close()
+
+	// A write larger than a single record should be split across several
+	// records, and reassembled by the reader.
+	msg := bytes.Repeat([]byte("lightning"), maxMessageSize/4)
+
+	errChan := make(chan error, 1)
+	go func() {
+		_, err := conn.Write(msg)
+		errChan <- err
+	}()
+
+	readBuf := make([]byte, 0, len(msg))
+	for len(readBuf) < len(msg) {
+		b := make([]byte, len(msg)-len(readBuf))
+		n, err := localConn.Read(b)
+		if err != nil {
+			t.Fatalf("unable to read: %v", err)
+		}
+		readBuf = append(readBuf, b[:n]...)
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatalf("unable to write: %v", err)
+	}
+	if !bytes.Equal(readBuf, msg) {
+		t.Fatalf("messages don't match")
+	}
+}
@@ -0,0 +1,164 @@
+package lndc
+
+import (
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/proxy"
+)
+
+const (
+	// onionSuffix is the suffix of the hostname of each Tor hidden
+	// service.
+	onionSuffix = ".onion"
+
+	// v2OnionLen and v3OnionLen are the lengths of the base32 encoded
+	// service identifiers of version 2 and version 3 hidden services
+	// respectively.
+	v2OnionLen = 16
+	v3OnionLen = 56
+)
+
+var (
+	// ErrOnionNoProxy is returned when attempting to dial an onion
+	// address without a SOCKS5 proxy, as hidden services can only be
+	// reached through Tor.
+	ErrOnionNoProxy = errors.New("a SOCKS5 proxy is required to connect " +
+		"to onion addresses")
+)
+
+// Dialer opens the underlying connection to a remote node. Both net.Dialer,
+// and the dialers returned by NewSOCKS5Dialer satisfy this interface.
+type Dialer interface {
+	Dial(network, address string) (net.Conn, error)
+}
+
+// NewSOCKS5Dialer returns a Dialer which routes all connections through the
+// SOCKS5 proxy listening at proxyAddr, such as a local Tor daemon. Hostnames
+// are resolved by the proxy, which allows the dialer to reach onion
+// addresses.
+func NewSOCKS5Dialer(proxyAddr string) (Dialer, error) {
+	forward := &net.Dialer{Timeout: handshakeTimeout}
+	return proxy.SOCKS5("tcp", proxyAddr, nil, forward)
+}
+
+// OnionAddr is the address of a Tor hidden service. As the address can only
+// be resolved by Tor itself, it's kept in its string form.
+type OnionAddr struct {
+	// OnionService is the hostname of the hidden service, including the
+	// ".onion" suffix.
+	OnionService string
+
+	// Port is the virtual port of the hidden service.
+	Port int
+}
+
+// A compile-time check to ensure OnionAddr implements the net.Addr interface.
+var _ net.Addr = (*OnionAddr)(nil)
+
+// Network returns the name of the network of the address.
+// Part of the net.Addr interface.
+func (o *OnionAddr) Network() string {
+	return "tcp"
+}
+
+// String returns the hidden service address in host:port form.
+// Part of the net.Addr interface.
+func (o *OnionAddr) String() string {
+	return net.JoinHostPort(o.OnionService, strconv.Itoa(o.Port))
+}
+
+// UnresolvedAddr is a host:port address whose hostname is resolved by the
+// proxy it's dialed through, rather than locally, so no DNS lookups are made
+// outside of the proxy.
+type UnresolvedAddr struct {
+	// Host is the hostname of the address.
+	Host string
+
+	// Port is the port of the address.
+	Port int
+}
+
+// A compile-time check to ensure UnresolvedAddr implements the net.Addr
+// interface.
+var _ net.Addr = (*UnresolvedAddr)(nil)
+
+// Network returns the name of the network of the address.
+// Part of the net.Addr interface.
+func (u *UnresolvedAddr) Network() string {
+	return "tcp"
+}
+
+// String returns the address in host:port form.
+// Part of the net.Addr interface.
+func (u *UnresolvedAddr) String() string {
+	return net.JoinHostPort(u.Host, strconv.Itoa(u.Port))
+}
+
+// isOnionHost returns true if the passed hostname is that of a Tor hidden
+// service.
+func isOnionHost(host string) bool {
+	return strings.HasSuffix(strings.ToLower(host), onionSuffix)
+}
+
+// parsePort parses the passed port, ensuring it's within the valid range.
+func parsePort(portStr string) (int, error) {
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid port: %v", portStr)
+	}
+
+	return port, nil
+}
+
+// ResolveAddr parses the passed host:port address. Onion hosts are returned
+// as an OnionAddr, as they can only be resolved by Tor. If the address is to
+// be dialed through a proxy, then all other hostnames are returned as an
+// UnresolvedAddr, leaving their resolution to the proxy. Otherwise, they're
+// resolved as TCP addresses.
+func ResolveAddr(address string, proxied bool) (net.Addr, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isOnionHost(host) {
+		// IP addresses don't require a DNS lookup, so they can be
+		// safely parsed locally.
+		if !proxied || net.ParseIP(host) != nil {
+			return net.ResolveTCPAddr("tcp", address)
+		}
+
+		port, err := parsePort(portStr)
+		if err != nil {
+			return nil, err
+		}
+
+		return &UnresolvedAddr{Host: host, Port: port}, nil
+	}
+
+	// Ensure the service identifier of the hidden service is well
+	// formed before accepting the address.
+	service := strings.ToLower(host[:len(host)-len(onionSuffix)])
+	if len(service) != v2OnionLen && len(service) != v3OnionLen {
+		return nil, fmt.Errorf("invalid onion address: %v", host)
+	}
+	_, err = base32.StdEncoding.DecodeString(strings.ToUpper(service))
+	if err != nil {
+		return nil, fmt.Errorf("invalid onion address: %v", host)
+	}
+
+	port, err := parsePort(portStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OnionAddr{
+		OnionService: service + onionSuffix,
+		Port:         port,
+	}, nil
+}
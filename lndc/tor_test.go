@@ -0,0 +1,327 @@
+package lndc
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/roasbeef/btcd/btcec"
+)
+
+// socks5StandIn is a minimal SOCKS5 proxy which forwards every connection to
+// a fixed target, regardless of the address requested. The address requested
+// by each connection is sent over the requests channel.
+type socks5StandIn struct {
+	listener net.Listener
+	target   string
+	requests chan string
+}
+
+// newSOCKS5StandIn creates, and starts a new socks5StandIn which forwards
+// all connections to the passed target address.
+func newSOCKS5StandIn(target string) (*socks5StandIn, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &socks5StandIn{
+		listener: l,
+		target:   target,
+		requests: make(chan string, 1),
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.handleConn(conn)
+		}
+	}()
+
+	return s, nil
+}
+
+// handleConn executes the server's side of the SOCKS5 handshake, then
+// proxies the connection to the target.
+func (s *socks5StandIn) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	// First, read the version, and the authentication methods supported
+	// by the client, selecting no authentication.
+	var header [2]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return
+	}
+
+	// Next, read the connect request: version, command, reserved, and the
+	// type of the requested address.
+	var req [4]byte
+	if _, err := io.ReadFull(conn, req[:]); err != nil {
+		return
+	}
+
+	var host string
+	switch req[3] {
+	case 1:
+		var ip [net.IPv4len]byte
+		if _, err := io.ReadFull(conn, ip[:]); err != nil {
+			return
+		}
+		host = net.IP(ip[:]).String()
+	case 3:
+		var hostLen [1]byte
+		if _, err := io.ReadFull(conn, hostLen[:]); err != nil {
+			return
+		}
+		hostBytes := make([]byte, hostLen[0])
+		if _, err := io.ReadFull(conn, hostBytes); err != nil {
+			return
+		}
+		host = string(hostBytes)
+	case 4:
+		var ip [net.IPv6len]byte
+		if _, err := io.ReadFull(conn, ip[:]); err != nil {
+			return
+		}
+		host = net.IP(ip[:]).String()
+	default:
+		return
+	}
+
+	var port [2]byte
+	if _, err := io.ReadFull(conn, port[:]); err != nil {
+		return
+	}
+	portStr := strconv.Itoa(int(binary.BigEndian.Uint16(port[:])))
+	s.requests <- net.JoinHostPort(host, portStr)
+
+	target, err := net.Dial("tcp", s.target)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+
+	// Report success, along with a zero bound address.
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+func TestDialOnionThroughProxy(t *testing.T) {
+	localPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate local priv key: %v", err)
+	}
+	remotePriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate remote priv key: %v", err)
+	}
+
+	listener, err := NewListener(localPriv, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	// Our stand-in proxy will forward all connections to the listener,
+	// as Tor would for a hidden service.
+	socksProxy, err := newSOCKS5StandIn(listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to create proxy: %v", err)
+	}
+	defer socksProxy.listener.Close()
+
+	const onionAddr = "expyuzz4wqqyqhjn.onion:10011"
+
+	// Without a proxy, dialing an onion address should fail.
+	conn := NewConn(nil)
+	err = conn.Dial(remotePriv, onionAddr,
+		localPriv.PubKey().SerializeCompressed())
+	if err != ErrOnionNoProxy {
+		t.Fatalf("expected ErrOnionNoProxy, instead got: %v", err)
+	}
+
+	dialer, err := NewSOCKS5Dialer(socksProxy.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to create dialer: %v", err)
+	}
+	conn = NewConnWithDialer(dialer)
+
+	dialErr := make(chan error, 1)
+	go func() {
+		dialErr <- conn.Dial(remotePriv, onionAddr,
+			localPriv.PubKey().SerializeCompressed())
+	}()
+
+	localConn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept connection: %v", err)
+	}
+	defer localConn.Close()
+	if err := <-dialErr; err != nil {
+		t.Fatalf("unable to establish connection: %v", err)
+	}
+	defer conn.Close()
+
+	// The onion address should have been resolved by the proxy, rather
+	// than locally.
+	if req := <-socksProxy.requests; req != onionAddr {
+		t.Fatalf("proxy received request for %v, expected %v", req,
+			onionAddr)
+	}
+
+	// The remote address of the connection should be the onion address
+	// we dialed, rather than the address of the proxy.
+	if conn.RemoteAddr().String() != onionAddr {
+		t.Fatalf("remote addr should be %v, instead is %v", onionAddr,
+			conn.RemoteAddr())
+	}
+
+	// Finally, ensure the connection is usable.
+	msg := []byte("hello over tor")
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatalf("unable to write: %v", err)
+	}
+	readBuf := make([]byte, len(msg))
+	if _, err := io.ReadFull(localConn, readBuf); err != nil {
+		t.Fatalf("unable to read: %v", err)
+	}
+	if string(readBuf) != string(msg) {
+		t.Fatalf("messages don't match, %v vs %v", string(readBuf),
+			string(msg))
+	}
+}
+
+func TestDialHostnameThroughProxy(t *testing.T) {
+	localPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate local priv key: %v", err)
+	}
+	remotePriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate remote priv key: %v", err)
+	}
+
+	listener, err := NewListener(localPriv, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	socksProxy, err := newSOCKS5StandIn(listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to create proxy: %v", err)
+	}
+	defer socksProxy.listener.Close()
+
+	dialer, err := NewSOCKS5Dialer(socksProxy.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to create dialer: %v", err)
+	}
+	conn := NewConnWithDialer(dialer)
+
+	// The hostname doesn't exist, so dialing it would fail if it were
+	// resolved locally rather than by the proxy.
+	const hostAddr = "node.invalid:9735"
+	dialErr := make(chan error, 1)
+	go func() {
+		dialErr <- conn.Dial(remotePriv, hostAddr,
+			localPriv.PubKey().SerializeCompressed())
+	}()
+
+	localConn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept connection: %v", err)
+	}
+	defer localConn.Close()
+	if err := <-dialErr; err != nil {
+		t.Fatalf("unable to establish connection: %v", err)
+	}
+	defer conn.Close()
+
+	if req := <-socksProxy.requests; req != hostAddr {
+		t.Fatalf("proxy received request for %v, expected %v", req,
+			hostAddr)
+	}
+	if conn.RemoteAddr().String() != hostAddr {
+		t.Fatalf("remote addr should be %v, instead is %v", hostAddr,
+			conn.RemoteAddr())
+	}
+}
+
+func TestResolveAddr(t *testing.T) {
+	tests := []struct {
+		addr       string
+		proxied    bool
+		onion      bool
+		unresolved bool
+		invalid    bool
+	}{
+		{addr: "127.0.0.1:10011"},
+		{addr: "[::1]:10011"},
+		{addr: "expyuzz4wqqyqhjn.onion:10011", onion: true},
+		{
+			addr: "vww6ybal4bd7szmgncyruucpgfkqahzddi37ktceo3ah7" +
+				"ngmcopnpyyd.onion:9735",
+			onion: true,
+		},
+		{addr: "EXPYUZZ4WQQYQHJN.onion:10011", onion: true},
+		{addr: "expyuzz4wqqyqhj.onion:10011", invalid: true},
+		{addr: "expyuzz4wqqyqhj1.onion:10011", invalid: true},
+		{addr: "expyuzz4wqqyqhjn.onion:0", invalid: true},
+		{addr: "expyuzz4wqqyqhjn.onion", invalid: true},
+
+		// Hostnames dialed through a proxy should be left for the
+		// proxy to resolve, while IP addresses are still parsed.
+		{addr: "node.example.com:9735", proxied: true, unresolved: true},
+		{addr: "127.0.0.1:10011", proxied: true},
+		{addr: "[::1]:10011", proxied: true},
+		{
+			addr:    "expyuzz4wqqyqhjn.onion:10011",
+			proxied: true,
+			onion:   true,
+		},
+		{addr: "node.example.com:0", proxied: true, invalid: true},
+	}
+
+	for _, test := range tests {
+		addr, err := ResolveAddr(test.addr, test.proxied)
+		if test.invalid {
+			if err == nil {
+				t.Fatalf("address %v should be invalid", test.addr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unable to resolve %v: %v", test.addr, err)
+		}
+
+		_, isOnion := addr.(*OnionAddr)
+		if isOnion != test.onion {
+			t.Fatalf("address %v: onion=%v, expected %v", test.addr,
+				isOnion, test.onion)
+		}
+		_, isUnresolved := addr.(*UnresolvedAddr)
+		if isUnresolved != test.unresolved {
+			t.Fatalf("address %v: unresolved=%v, expected %v",
+				test.addr, isUnresolved, test.unresolved)
+		}
+		if addr.String() != test.addr && !isOnion {
+			t.Fatalf("address %v: resolved to %v", test.addr, addr)
+		}
+	}
+}
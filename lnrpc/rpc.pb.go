@@ -52,6 +52,20 @@ It has these top-level messages:
 	PaymentHash
 	ListInvoiceRequest
 	ListInvoiceResponse
+	PaymentFailure
+	Payment
+	ListPaymentsRequest
+	ListPaymentsResponse
+	PayReqString
+	PayReq
+	CancelInvoiceResponse
+	InvoiceSubscription
+	UpdateChannelPolicyRequest
+	UpdateChannelPolicyResponse
+	ForwardingHistoryRequest
+	ForwardingEvent
+	ForwardingHistoryResponse
+	Feature
 */
 package lnrpc
 
@@ -125,11 +139,119 @@ func (NewAddressRequest_AddressType) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor0, []int{8, 0}
 }
 
+type Invoice_InvoiceState int32
+
+const (
+	Invoice_OPEN      Invoice_InvoiceState = 0
+	Invoice_SETTLED   Invoice_InvoiceState = 1
+	Invoice_CANCELLED Invoice_InvoiceState = 2
+	Invoice_EXPIRED   Invoice_InvoiceState = 3
+)
+
+var Invoice_InvoiceState_name = map[int32]string{
+	0: "OPEN",
+	1: "SETTLED",
+	2: "CANCELLED",
+	3: "EXPIRED",
+}
+var Invoice_InvoiceState_value = map[string]int32{
+	"OPEN":      0,
+	"SETTLED":   1,
+	"CANCELLED": 2,
+	"EXPIRED":   3,
+}
+
+func (x Invoice_InvoiceState) String() string {
+	return proto.EnumName(Invoice_InvoiceState_name, int32(x))
+}
+func (Invoice_InvoiceState) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{38, 0} }
+
+type PaymentFailure_FailureCode int32
+
+const (
+	PaymentFailure_UNKNOWN                PaymentFailure_FailureCode = 0
+	PaymentFailure_NO_ROUTE               PaymentFailure_FailureCode = 1
+	PaymentFailure_UNKNOWN_NEXT_PEER      PaymentFailure_FailureCode = 2
+	PaymentFailure_INSUFFICIENT_CAPACITY  PaymentFailure_FailureCode = 3
+	PaymentFailure_CANCELLED              PaymentFailure_FailureCode = 4
+	PaymentFailure_UNKNOWN_PAYMENT_HASH   PaymentFailure_FailureCode = 5
+	PaymentFailure_INVOICE_NOT_PAYABLE    PaymentFailure_FailureCode = 6
+	PaymentFailure_AMOUNT_TOO_LOW         PaymentFailure_FailureCode = 7
+	PaymentFailure_AMOUNT_TOO_HIGH        PaymentFailure_FailureCode = 8
+	PaymentFailure_EXPIRY_TOO_SOON        PaymentFailure_FailureCode = 9
+	PaymentFailure_HTLC_TIMEOUT           PaymentFailure_FailureCode = 10
+	PaymentFailure_FEE_INSUFFICIENT       PaymentFailure_FailureCode = 11
+	PaymentFailure_INCORRECT_EXPIRY_DELTA PaymentFailure_FailureCode = 12
+)
+
+var PaymentFailure_FailureCode_name = map[int32]string{
+	0:  "UNKNOWN",
+	1:  "NO_ROUTE",
+	2:  "UNKNOWN_NEXT_PEER",
+	3:  "INSUFFICIENT_CAPACITY",
+	4:  "CANCELLED",
+	5:  "UNKNOWN_PAYMENT_HASH",
+	6:  "INVOICE_NOT_PAYABLE",
+	7:  "AMOUNT_TOO_LOW",
+	8:  "AMOUNT_TOO_HIGH",
+	9:  "EXPIRY_TOO_SOON",
+	10: "HTLC_TIMEOUT",
+	11: "FEE_INSUFFICIENT",
+	12: "INCORRECT_EXPIRY_DELTA",
+}
+var PaymentFailure_FailureCode_value = map[string]int32{
+	"UNKNOWN":                0,
+	"NO_ROUTE":               1,
+	"UNKNOWN_NEXT_PEER":      2,
+	"INSUFFICIENT_CAPACITY":  3,
+	"CANCELLED":              4,
+	"UNKNOWN_PAYMENT_HASH":   5,
+	"INVOICE_NOT_PAYABLE":    6,
+	"AMOUNT_TOO_LOW":         7,
+	"AMOUNT_TOO_HIGH":        8,
+	"EXPIRY_TOO_SOON":        9,
+	"HTLC_TIMEOUT":           10,
+	"FEE_INSUFFICIENT":       11,
+	"INCORRECT_EXPIRY_DELTA": 12,
+}
+
+func (x PaymentFailure_FailureCode) String() string {
+	return proto.EnumName(PaymentFailure_FailureCode_name, int32(x))
+}
+func (PaymentFailure_FailureCode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{43, 0}
+}
+
+type Payment_PaymentStatus int32
+
+const (
+	Payment_IN_FLIGHT Payment_PaymentStatus = 0
+	Payment_SUCCEEDED Payment_PaymentStatus = 1
+	Payment_FAILED    Payment_PaymentStatus = 2
+)
+
+var Payment_PaymentStatus_name = map[int32]string{
+	0: "IN_FLIGHT",
+	1: "SUCCEEDED",
+	2: "FAILED",
+}
+var Payment_PaymentStatus_value = map[string]int32{
+	"IN_FLIGHT": 0,
+	"SUCCEEDED": 1,
+	"FAILED":    2,
+}
+
+func (x Payment_PaymentStatus) String() string {
+	return proto.EnumName(Payment_PaymentStatus_name, int32(x))
+}
+func (Payment_PaymentStatus) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{44, 0} }
+
 type SendRequest struct {
-	Dest        []byte `protobuf:"bytes,1,opt,name=dest,proto3" json:"dest,omitempty"`
-	Amt         int64  `protobuf:"varint,2,opt,name=amt" json:"amt,omitempty"`
-	PaymentHash []byte `protobuf:"bytes,3,opt,name=payment_hash,proto3" json:"payment_hash,omitempty"`
-	FastSend    bool   `protobuf:"varint,4,opt,name=fast_send" json:"fast_send,omitempty"`
+	Dest           []byte `protobuf:"bytes,1,opt,name=dest,proto3" json:"dest,omitempty"`
+	Amt            int64  `protobuf:"varint,2,opt,name=amt" json:"amt,omitempty"`
+	PaymentHash    []byte `protobuf:"bytes,3,opt,name=payment_hash,proto3" json:"payment_hash,omitempty"`
+	FastSend       bool   `protobuf:"varint,4,opt,name=fast_send" json:"fast_send,omitempty"`
+	PaymentRequest string `protobuf:"bytes,5,opt,name=payment_request" json:"payment_request,omitempty"`
 }
 
 func (m *SendRequest) Reset()                    { *m = SendRequest{} }
@@ -138,6 +260,11 @@ func (*SendRequest) ProtoMessage()               {}
 func (*SendRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
 type SendResponse struct {
+	PaymentHash     []byte          `protobuf:"bytes,1,opt,name=payment_hash,proto3" json:"payment_hash,omitempty"`
+	PaymentPreimage []byte          `protobuf:"bytes,2,opt,name=payment_preimage,proto3" json:"payment_preimage,omitempty"`
+	PaymentRoute    []string        `protobuf:"bytes,3,rep,name=payment_route" json:"payment_route,omitempty"`
+	Fee             int64           `protobuf:"varint,4,opt,name=fee" json:"fee,omitempty"`
+	PaymentFailure  *PaymentFailure `protobuf:"bytes,5,opt,name=payment_failure" json:"payment_failure,omitempty"`
 }
 
 func (m *SendResponse) Reset()                    { *m = SendResponse{} }
@@ -145,6 +272,13 @@ func (m *SendResponse) String() string            { return proto.CompactTextStri
 func (*SendResponse) ProtoMessage()               {}
 func (*SendResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
 
+func (m *SendResponse) GetPaymentFailure() *PaymentFailure {
+	if m != nil {
+		return m.PaymentFailure
+	}
+	return nil
+}
+
 type ChannelPoint struct {
 	FundingTxid []byte `protobuf:"bytes,1,opt,name=funding_txid,proto3" json:"funding_txid,omitempty"`
 	OutputIndex uint32 `protobuf:"varint,2,opt,name=output_index" json:"output_index,omitempty"`
@@ -313,14 +447,17 @@ func (m *ListChannelsResponse) GetChannels() []*ActiveChannel {
 }
 
 type Peer struct {
-	LightningId string `protobuf:"bytes,1,opt,name=lightning_id" json:"lightning_id,omitempty"`
-	PeerId      int32  `protobuf:"varint,2,opt,name=peer_id" json:"peer_id,omitempty"`
-	Address     string `protobuf:"bytes,3,opt,name=address" json:"address,omitempty"`
-	BytesSent   uint64 `protobuf:"varint,4,opt,name=bytes_sent" json:"bytes_sent,omitempty"`
-	BytesRecv   uint64 `protobuf:"varint,5,opt,name=bytes_recv" json:"bytes_recv,omitempty"`
-	SatSent     int64  `protobuf:"varint,6,opt,name=sat_sent" json:"sat_sent,omitempty"`
-	SatRecv     int64  `protobuf:"varint,7,opt,name=sat_recv" json:"sat_recv,omitempty"`
-	Inbound     bool   `protobuf:"varint,8,opt,name=inbound" json:"inbound,omitempty"`
+	LightningId    string     `protobuf:"bytes,1,opt,name=lightning_id" json:"lightning_id,omitempty"`
+	PeerId         int32      `protobuf:"varint,2,opt,name=peer_id" json:"peer_id,omitempty"`
+	Address        string     `protobuf:"bytes,3,opt,name=address" json:"address,omitempty"`
+	BytesSent      uint64     `protobuf:"varint,4,opt,name=bytes_sent" json:"bytes_sent,omitempty"`
+	BytesRecv      uint64     `protobuf:"varint,5,opt,name=bytes_recv" json:"bytes_recv,omitempty"`
+	SatSent        int64      `protobuf:"varint,6,opt,name=sat_sent" json:"sat_sent,omitempty"`
+	SatRecv        int64      `protobuf:"varint,7,opt,name=sat_recv" json:"sat_recv,omitempty"`
+	Inbound        bool       `protobuf:"varint,8,opt,name=inbound" json:"inbound,omitempty"`
+	PingTime       int64      `protobuf:"varint,9,opt,name=ping_time" json:"ping_time,omitempty"`
+	GlobalFeatures []*Feature `protobuf:"bytes,10,rep,name=global_features" json:"global_features,omitempty"`
+	LocalFeatures  []*Feature `protobuf:"bytes,11,rep,name=local_features" json:"local_features,omitempty"`
 }
 
 func (m *Peer) Reset()                    { *m = Peer{} }
@@ -328,6 +465,20 @@ func (m *Peer) String() string            { return proto.CompactTextString(m) }
 func (*Peer) ProtoMessage()               {}
 func (*Peer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
+func (m *Peer) GetGlobalFeatures() []*Feature {
+	if m != nil {
+		return m.GlobalFeatures
+	}
+	return nil
+}
+
+func (m *Peer) GetLocalFeatures() []*Feature {
+	if m != nil {
+		return m.LocalFeatures
+	}
+	return nil
+}
+
 type ListPeersRequest struct {
 }
 
@@ -361,12 +512,13 @@ func (*GetInfoRequest) ProtoMessage()               {}
 func (*GetInfoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 type GetInfoResponse struct {
-	LightningId        string `protobuf:"bytes,1,opt,name=lightning_id" json:"lightning_id,omitempty"`
-	IdentityAddress    string `protobuf:"bytes,2,opt,name=identity_address" json:"identity_address,omitempty"`
-	IdentityPubkey     string `protobuf:"bytes,3,opt,name=identity_pubkey" json:"identity_pubkey,omitempty"`
-	NumPendingChannels uint32 `protobuf:"varint,4,opt,name=num_pending_channels" json:"num_pending_channels,omitempty"`
-	NumActiveChannels  uint32 `protobuf:"varint,5,opt,name=num_active_channels" json:"num_active_channels,omitempty"`
-	NumPeers           uint32 `protobuf:"varint,6,opt,name=num_peers" json:"num_peers,omitempty"`
+	LightningId        string   `protobuf:"bytes,1,opt,name=lightning_id" json:"lightning_id,omitempty"`
+	IdentityAddress    string   `protobuf:"bytes,2,opt,name=identity_address" json:"identity_address,omitempty"`
+	IdentityPubkey     string   `protobuf:"bytes,3,opt,name=identity_pubkey" json:"identity_pubkey,omitempty"`
+	NumPendingChannels uint32   `protobuf:"varint,4,opt,name=num_pending_channels" json:"num_pending_channels,omitempty"`
+	NumActiveChannels  uint32   `protobuf:"varint,5,opt,name=num_active_channels" json:"num_active_channels,omitempty"`
+	NumPeers           uint32   `protobuf:"varint,6,opt,name=num_peers" json:"num_peers,omitempty"`
+	Uris               []string `protobuf:"bytes,7,rep,name=uris" json:"uris,omitempty"`
 }
 
 func (m *GetInfoResponse) Reset()                    { *m = GetInfoResponse{} }
@@ -872,12 +1024,20 @@ func (m *ShowRoutingTableResponse) GetChannels() []*RoutingTableLink {
 }
 
 type Invoice struct {
-	Memo      string `protobuf:"bytes,1,opt,name=memo" json:"memo,omitempty"`
-	Receipt   []byte `protobuf:"bytes,2,opt,name=receipt,proto3" json:"receipt,omitempty"`
-	RPreimage []byte `protobuf:"bytes,3,opt,name=r_preimage,proto3" json:"r_preimage,omitempty"`
-	RHash     []byte `protobuf:"bytes,4,opt,name=r_hash,proto3" json:"r_hash,omitempty"`
-	Value     int64  `protobuf:"varint,5,opt,name=value" json:"value,omitempty"`
-	Settled   bool   `protobuf:"varint,6,opt,name=settled" json:"settled,omitempty"`
+	Memo           string               `protobuf:"bytes,1,opt,name=memo" json:"memo,omitempty"`
+	Receipt        []byte               `protobuf:"bytes,2,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	RPreimage      []byte               `protobuf:"bytes,3,opt,name=r_preimage,proto3" json:"r_preimage,omitempty"`
+	RHash          []byte               `protobuf:"bytes,4,opt,name=r_hash,proto3" json:"r_hash,omitempty"`
+	Value          int64                `protobuf:"varint,5,opt,name=value" json:"value,omitempty"`
+	Settled        bool                 `protobuf:"varint,6,opt,name=settled" json:"settled,omitempty"`
+	CreationDate   int64                `protobuf:"varint,7,opt,name=creation_date" json:"creation_date,omitempty"`
+	Expiry         int64                `protobuf:"varint,8,opt,name=expiry" json:"expiry,omitempty"`
+	PaymentRequest string               `protobuf:"bytes,9,opt,name=payment_request" json:"payment_request,omitempty"`
+	State          Invoice_InvoiceState `protobuf:"varint,10,opt,name=state,enum=lnrpc.Invoice_InvoiceState" json:"state,omitempty"`
+	AddIndex       uint64               `protobuf:"varint,11,opt,name=add_index" json:"add_index,omitempty"`
+	SettleIndex    uint64               `protobuf:"varint,12,opt,name=settle_index" json:"settle_index,omitempty"`
+	AmtPaid        int64                `protobuf:"varint,13,opt,name=amt_paid" json:"amt_paid,omitempty"`
+	AmtRejected    int64                `protobuf:"varint,14,opt,name=amt_rejected" json:"amt_rejected,omitempty"`
 }
 
 func (m *Invoice) Reset()                    { *m = Invoice{} }
@@ -886,7 +1046,8 @@ func (*Invoice) ProtoMessage()               {}
 func (*Invoice) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
 
 type AddInvoiceResponse struct {
-	RHash []byte `protobuf:"bytes,1,opt,name=r_hash,proto3" json:"r_hash,omitempty"`
+	RHash          []byte `protobuf:"bytes,1,opt,name=r_hash,proto3" json:"r_hash,omitempty"`
+	PaymentRequest string `protobuf:"bytes,2,opt,name=payment_request" json:"payment_request,omitempty"`
 }
 
 func (m *AddInvoiceResponse) Reset()                    { *m = AddInvoiceResponse{} }
@@ -928,6 +1089,184 @@ func (m *ListInvoiceResponse) GetInvoices() []*Invoice {
 	return nil
 }
 
+type PaymentFailure struct {
+	Code               PaymentFailure_FailureCode `protobuf:"varint,1,opt,name=code,enum=lnrpc.PaymentFailure_FailureCode" json:"code,omitempty"`
+	Message            string                     `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+	FailureSourceIndex uint32                     `protobuf:"varint,3,opt,name=failure_source_index" json:"failure_source_index,omitempty"`
+	FailureSource      string                     `protobuf:"bytes,4,opt,name=failure_source" json:"failure_source,omitempty"`
+}
+
+func (m *PaymentFailure) Reset()                    { *m = PaymentFailure{} }
+func (m *PaymentFailure) String() string            { return proto.CompactTextString(m) }
+func (*PaymentFailure) ProtoMessage()               {}
+func (*PaymentFailure) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+type Payment struct {
+	PaymentHash     []byte                `protobuf:"bytes,1,opt,name=payment_hash,proto3" json:"payment_hash,omitempty"`
+	Value           int64                 `protobuf:"varint,2,opt,name=value" json:"value,omitempty"`
+	Fee             int64                 `protobuf:"varint,3,opt,name=fee" json:"fee,omitempty"`
+	Path            []string              `protobuf:"bytes,4,rep,name=path" json:"path,omitempty"`
+	Status          Payment_PaymentStatus `protobuf:"varint,5,opt,name=status,enum=lnrpc.Payment_PaymentStatus" json:"status,omitempty"`
+	PaymentPreimage []byte                `protobuf:"bytes,6,opt,name=payment_preimage,proto3" json:"payment_preimage,omitempty"`
+	FailureReason   string                `protobuf:"bytes,7,opt,name=failure_reason" json:"failure_reason,omitempty"`
+	CreationDate    int64                 `protobuf:"varint,8,opt,name=creation_date" json:"creation_date,omitempty"`
+}
+
+func (m *Payment) Reset()                    { *m = Payment{} }
+func (m *Payment) String() string            { return proto.CompactTextString(m) }
+func (*Payment) ProtoMessage()               {}
+func (*Payment) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+type ListPaymentsRequest struct {
+}
+
+func (m *ListPaymentsRequest) Reset()                    { *m = ListPaymentsRequest{} }
+func (m *ListPaymentsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListPaymentsRequest) ProtoMessage()               {}
+func (*ListPaymentsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+type ListPaymentsResponse struct {
+	Payments []*Payment `protobuf:"bytes,1,rep,name=payments" json:"payments,omitempty"`
+}
+
+func (m *ListPaymentsResponse) Reset()                    { *m = ListPaymentsResponse{} }
+func (m *ListPaymentsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListPaymentsResponse) ProtoMessage()               {}
+func (*ListPaymentsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+func (m *ListPaymentsResponse) GetPayments() []*Payment {
+	if m != nil {
+		return m.Payments
+	}
+	return nil
+}
+
+type PayReqString struct {
+	PayReq string `protobuf:"bytes,1,opt,name=pay_req" json:"pay_req,omitempty"`
+}
+
+func (m *PayReqString) Reset()                    { *m = PayReqString{} }
+func (m *PayReqString) String() string            { return proto.CompactTextString(m) }
+func (*PayReqString) ProtoMessage()               {}
+func (*PayReqString) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+type PayReq struct {
+	Destination string `protobuf:"bytes,1,opt,name=destination" json:"destination,omitempty"`
+	PaymentHash string `protobuf:"bytes,2,opt,name=payment_hash" json:"payment_hash,omitempty"`
+	NumSatoshis int64  `protobuf:"varint,3,opt,name=num_satoshis" json:"num_satoshis,omitempty"`
+	Memo        string `protobuf:"bytes,4,opt,name=memo" json:"memo,omitempty"`
+	Timestamp   int64  `protobuf:"varint,5,opt,name=timestamp" json:"timestamp,omitempty"`
+	Expiry      int64  `protobuf:"varint,6,opt,name=expiry" json:"expiry,omitempty"`
+	Network     string `protobuf:"bytes,7,opt,name=network" json:"network,omitempty"`
+}
+
+func (m *PayReq) Reset()                    { *m = PayReq{} }
+func (m *PayReq) String() string            { return proto.CompactTextString(m) }
+func (*PayReq) ProtoMessage()               {}
+func (*PayReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+type CancelInvoiceResponse struct {
+}
+
+func (m *CancelInvoiceResponse) Reset()                    { *m = CancelInvoiceResponse{} }
+func (m *CancelInvoiceResponse) String() string            { return proto.CompactTextString(m) }
+func (*CancelInvoiceResponse) ProtoMessage()               {}
+func (*CancelInvoiceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
+
+type InvoiceSubscription struct {
+	AddIndex    uint64 `protobuf:"varint,1,opt,name=add_index" json:"add_index,omitempty"`
+	SettleIndex uint64 `protobuf:"varint,2,opt,name=settle_index" json:"settle_index,omitempty"`
+}
+
+func (m *InvoiceSubscription) Reset()                    { *m = InvoiceSubscription{} }
+func (m *InvoiceSubscription) String() string            { return proto.CompactTextString(m) }
+func (*InvoiceSubscription) ProtoMessage()               {}
+func (*InvoiceSubscription) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+
+type UpdateChannelPolicyRequest struct {
+	ChanPoint     *ChannelPoint `protobuf:"bytes,1,opt,name=chan_point" json:"chan_point,omitempty"`
+	BaseFee       int64         `protobuf:"varint,2,opt,name=base_fee" json:"base_fee,omitempty"`
+	FeeRate       uint32        `protobuf:"varint,3,opt,name=fee_rate" json:"fee_rate,omitempty"`
+	MinHtlc       int64         `protobuf:"varint,4,opt,name=min_htlc" json:"min_htlc,omitempty"`
+	TimeLockDelta uint32        `protobuf:"varint,5,opt,name=time_lock_delta" json:"time_lock_delta,omitempty"`
+}
+
+func (m *UpdateChannelPolicyRequest) Reset()                    { *m = UpdateChannelPolicyRequest{} }
+func (m *UpdateChannelPolicyRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateChannelPolicyRequest) ProtoMessage()               {}
+func (*UpdateChannelPolicyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
+
+func (m *UpdateChannelPolicyRequest) GetChanPoint() *ChannelPoint {
+	if m != nil {
+		return m.ChanPoint
+	}
+	return nil
+}
+
+type UpdateChannelPolicyResponse struct {
+}
+
+func (m *UpdateChannelPolicyResponse) Reset()                    { *m = UpdateChannelPolicyResponse{} }
+func (m *UpdateChannelPolicyResponse) String() string            { return proto.CompactTextString(m) }
+func (*UpdateChannelPolicyResponse) ProtoMessage()               {}
+func (*UpdateChannelPolicyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{52} }
+
+type ForwardingHistoryRequest struct {
+	StartTime    uint64 `protobuf:"varint,1,opt,name=start_time" json:"start_time,omitempty"`
+	EndTime      uint64 `protobuf:"varint,2,opt,name=end_time" json:"end_time,omitempty"`
+	IndexOffset  uint32 `protobuf:"varint,3,opt,name=index_offset" json:"index_offset,omitempty"`
+	NumMaxEvents uint32 `protobuf:"varint,4,opt,name=num_max_events" json:"num_max_events,omitempty"`
+}
+
+func (m *ForwardingHistoryRequest) Reset()                    { *m = ForwardingHistoryRequest{} }
+func (m *ForwardingHistoryRequest) String() string            { return proto.CompactTextString(m) }
+func (*ForwardingHistoryRequest) ProtoMessage()               {}
+func (*ForwardingHistoryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{53} }
+
+type ForwardingEvent struct {
+	Timestamp    uint64 `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	ChanPointIn  string `protobuf:"bytes,2,opt,name=chan_point_in" json:"chan_point_in,omitempty"`
+	ChanPointOut string `protobuf:"bytes,3,opt,name=chan_point_out" json:"chan_point_out,omitempty"`
+	AmtIn        int64  `protobuf:"varint,4,opt,name=amt_in" json:"amt_in,omitempty"`
+	AmtOut       int64  `protobuf:"varint,5,opt,name=amt_out" json:"amt_out,omitempty"`
+	Fee          int64  `protobuf:"varint,6,opt,name=fee" json:"fee,omitempty"`
+}
+
+func (m *ForwardingEvent) Reset()                    { *m = ForwardingEvent{} }
+func (m *ForwardingEvent) String() string            { return proto.CompactTextString(m) }
+func (*ForwardingEvent) ProtoMessage()               {}
+func (*ForwardingEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
+
+type ForwardingHistoryResponse struct {
+	ForwardingEvents []*ForwardingEvent `protobuf:"bytes,1,rep,name=forwarding_events" json:"forwarding_events,omitempty"`
+	LastOffsetIndex  uint32             `protobuf:"varint,2,opt,name=last_offset_index" json:"last_offset_index,omitempty"`
+	TotalFees        int64              `protobuf:"varint,3,opt,name=total_fees" json:"total_fees,omitempty"`
+}
+
+func (m *ForwardingHistoryResponse) Reset()                    { *m = ForwardingHistoryResponse{} }
+func (m *ForwardingHistoryResponse) String() string            { return proto.CompactTextString(m) }
+func (*ForwardingHistoryResponse) ProtoMessage()               {}
+func (*ForwardingHistoryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{55} }
+
+func (m *ForwardingHistoryResponse) GetForwardingEvents() []*ForwardingEvent {
+	if m != nil {
+		return m.ForwardingEvents
+	}
+	return nil
+}
+
+type Feature struct {
+	Bit        uint32 `protobuf:"varint,1,opt,name=bit" json:"bit,omitempty"`
+	Name       string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	IsRequired bool   `protobuf:"varint,3,opt,name=is_required" json:"is_required,omitempty"`
+	IsKnown    bool   `protobuf:"varint,4,opt,name=is_known" json:"is_known,omitempty"`
+}
+
+func (m *Feature) Reset()                    { *m = Feature{} }
+func (m *Feature) String() string            { return proto.CompactTextString(m) }
+func (*Feature) ProtoMessage()               {}
+func (*Feature) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{56} }
+
 func init() {
 	proto.RegisterType((*SendRequest)(nil), "lnrpc.SendRequest")
 	proto.RegisterType((*SendResponse)(nil), "lnrpc.SendResponse")
@@ -973,8 +1312,25 @@ func init() {
 	proto.RegisterType((*PaymentHash)(nil), "lnrpc.PaymentHash")
 	proto.RegisterType((*ListInvoiceRequest)(nil), "lnrpc.ListInvoiceRequest")
 	proto.RegisterType((*ListInvoiceResponse)(nil), "lnrpc.ListInvoiceResponse")
+	proto.RegisterType((*PaymentFailure)(nil), "lnrpc.PaymentFailure")
+	proto.RegisterType((*Payment)(nil), "lnrpc.Payment")
+	proto.RegisterType((*ListPaymentsRequest)(nil), "lnrpc.ListPaymentsRequest")
+	proto.RegisterType((*ListPaymentsResponse)(nil), "lnrpc.ListPaymentsResponse")
+	proto.RegisterType((*PayReqString)(nil), "lnrpc.PayReqString")
+	proto.RegisterType((*PayReq)(nil), "lnrpc.PayReq")
+	proto.RegisterType((*CancelInvoiceResponse)(nil), "lnrpc.CancelInvoiceResponse")
+	proto.RegisterType((*InvoiceSubscription)(nil), "lnrpc.InvoiceSubscription")
+	proto.RegisterType((*UpdateChannelPolicyRequest)(nil), "lnrpc.UpdateChannelPolicyRequest")
+	proto.RegisterType((*UpdateChannelPolicyResponse)(nil), "lnrpc.UpdateChannelPolicyResponse")
+	proto.RegisterType((*ForwardingHistoryRequest)(nil), "lnrpc.ForwardingHistoryRequest")
+	proto.RegisterType((*ForwardingEvent)(nil), "lnrpc.ForwardingEvent")
+	proto.RegisterType((*ForwardingHistoryResponse)(nil), "lnrpc.ForwardingHistoryResponse")
+	proto.RegisterType((*Feature)(nil), "lnrpc.Feature")
 	proto.RegisterEnum("lnrpc.ChannelStatus", ChannelStatus_name, ChannelStatus_value)
 	proto.RegisterEnum("lnrpc.NewAddressRequest_AddressType", NewAddressRequest_AddressType_name, NewAddressRequest_AddressType_value)
+	proto.RegisterEnum("lnrpc.Invoice_InvoiceState", Invoice_InvoiceState_name, Invoice_InvoiceState_value)
+	proto.RegisterEnum("lnrpc.PaymentFailure_FailureCode", PaymentFailure_FailureCode_name, PaymentFailure_FailureCode_value)
+	proto.RegisterEnum("lnrpc.Payment_PaymentStatus", Payment_PaymentStatus_name, Payment_PaymentStatus_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1000,10 +1356,16 @@ type LightningClient interface {
 	CloseChannel(ctx context.Context, in *CloseChannelRequest, opts ...grpc.CallOption) (Lightning_CloseChannelClient, error)
 	PendingChannels(ctx context.Context, in *PendingChannelRequest, opts ...grpc.CallOption) (*PendingChannelResponse, error)
 	ListChannels(ctx context.Context, in *ListChannelsRequest, opts ...grpc.CallOption) (*ListChannelsResponse, error)
+	UpdateChannelPolicy(ctx context.Context, in *UpdateChannelPolicyRequest, opts ...grpc.CallOption) (*UpdateChannelPolicyResponse, error)
+	ForwardingHistory(ctx context.Context, in *ForwardingHistoryRequest, opts ...grpc.CallOption) (*ForwardingHistoryResponse, error)
 	SendPayment(ctx context.Context, opts ...grpc.CallOption) (Lightning_SendPaymentClient, error)
+	ListPayments(ctx context.Context, in *ListPaymentsRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error)
 	AddInvoice(ctx context.Context, in *Invoice, opts ...grpc.CallOption) (*AddInvoiceResponse, error)
 	LookupInvoice(ctx context.Context, in *PaymentHash, opts ...grpc.CallOption) (*Invoice, error)
+	CancelInvoice(ctx context.Context, in *PaymentHash, opts ...grpc.CallOption) (*CancelInvoiceResponse, error)
 	ListInvoices(ctx context.Context, in *ListInvoiceRequest, opts ...grpc.CallOption) (*ListInvoiceResponse, error)
+	SubscribeInvoices(ctx context.Context, in *InvoiceSubscription, opts ...grpc.CallOption) (Lightning_SubscribeInvoicesClient, error)
+	DecodePayReq(ctx context.Context, in *PayReqString, opts ...grpc.CallOption) (*PayReq, error)
 	ShowRoutingTable(ctx context.Context, in *ShowRoutingTableRequest, opts ...grpc.CallOption) (*ShowRoutingTableResponse, error)
 }
 
@@ -1169,6 +1531,24 @@ func (c *lightningClient) ListChannels(ctx context.Context, in *ListChannelsRequ
 	return out, nil
 }
 
+func (c *lightningClient) UpdateChannelPolicy(ctx context.Context, in *UpdateChannelPolicyRequest, opts ...grpc.CallOption) (*UpdateChannelPolicyResponse, error) {
+	out := new(UpdateChannelPolicyResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/UpdateChannelPolicy", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ForwardingHistory(ctx context.Context, in *ForwardingHistoryRequest, opts ...grpc.CallOption) (*ForwardingHistoryResponse, error) {
+	out := new(ForwardingHistoryResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ForwardingHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) SendPayment(ctx context.Context, opts ...grpc.CallOption) (Lightning_SendPaymentClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_Lightning_serviceDesc.Streams[2], c.cc, "/lnrpc.Lightning/SendPayment", opts...)
 	if err != nil {
@@ -1200,6 +1580,15 @@ func (x *lightningSendPaymentClient) Recv() (*SendResponse, error) {
 	return m, nil
 }
 
+func (c *lightningClient) ListPayments(ctx context.Context, in *ListPaymentsRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error) {
+	out := new(ListPaymentsResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ListPayments", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) AddInvoice(ctx context.Context, in *Invoice, opts ...grpc.CallOption) (*AddInvoiceResponse, error) {
 	out := new(AddInvoiceResponse)
 	err := grpc.Invoke(ctx, "/lnrpc.Lightning/AddInvoice", in, out, c.cc, opts...)
@@ -1218,6 +1607,15 @@ func (c *lightningClient) LookupInvoice(ctx context.Context, in *PaymentHash, op
 	return out, nil
 }
 
+func (c *lightningClient) CancelInvoice(ctx context.Context, in *PaymentHash, opts ...grpc.CallOption) (*CancelInvoiceResponse, error) {
+	out := new(CancelInvoiceResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/CancelInvoice", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) ListInvoices(ctx context.Context, in *ListInvoiceRequest, opts ...grpc.CallOption) (*ListInvoiceResponse, error) {
 	out := new(ListInvoiceResponse)
 	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ListInvoices", in, out, c.cc, opts...)
@@ -1227,6 +1625,47 @@ func (c *lightningClient) ListInvoices(ctx context.Context, in *ListInvoiceReque
 	return out, nil
 }
 
+func (c *lightningClient) SubscribeInvoices(ctx context.Context, in *InvoiceSubscription, opts ...grpc.CallOption) (Lightning_SubscribeInvoicesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Lightning_serviceDesc.Streams[3], c.cc, "/lnrpc.Lightning/SubscribeInvoices", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lightningSubscribeInvoicesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Lightning_SubscribeInvoicesClient interface {
+	Recv() (*Invoice, error)
+	grpc.ClientStream
+}
+
+type lightningSubscribeInvoicesClient struct {
+	grpc.ClientStream
+}
+
+func (x *lightningSubscribeInvoicesClient) Recv() (*Invoice, error) {
+	m := new(Invoice)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lightningClient) DecodePayReq(ctx context.Context, in *PayReqString, opts ...grpc.CallOption) (*PayReq, error) {
+	out := new(PayReq)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/DecodePayReq", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) ShowRoutingTable(ctx context.Context, in *ShowRoutingTableRequest, opts ...grpc.CallOption) (*ShowRoutingTableResponse, error) {
 	out := new(ShowRoutingTableResponse)
 	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ShowRoutingTable", in, out, c.cc, opts...)
@@ -1251,10 +1690,16 @@ type LightningServer interface {
 	CloseChannel(*CloseChannelRequest, Lightning_CloseChannelServer) error
 	PendingChannels(context.Context, *PendingChannelRequest) (*PendingChannelResponse, error)
 	ListChannels(context.Context, *ListChannelsRequest) (*ListChannelsResponse, error)
+	UpdateChannelPolicy(context.Context, *UpdateChannelPolicyRequest) (*UpdateChannelPolicyResponse, error)
+	ForwardingHistory(context.Context, *ForwardingHistoryRequest) (*ForwardingHistoryResponse, error)
 	SendPayment(Lightning_SendPaymentServer) error
+	ListPayments(context.Context, *ListPaymentsRequest) (*ListPaymentsResponse, error)
 	AddInvoice(context.Context, *Invoice) (*AddInvoiceResponse, error)
 	LookupInvoice(context.Context, *PaymentHash) (*Invoice, error)
+	CancelInvoice(context.Context, *PaymentHash) (*CancelInvoiceResponse, error)
 	ListInvoices(context.Context, *ListInvoiceRequest) (*ListInvoiceResponse, error)
+	SubscribeInvoices(*InvoiceSubscription, Lightning_SubscribeInvoicesServer) error
+	DecodePayReq(context.Context, *PayReqString) (*PayReq, error)
 	ShowRoutingTable(context.Context, *ShowRoutingTableRequest) (*ShowRoutingTableResponse, error)
 }
 
@@ -1484,6 +1929,42 @@ func _Lightning_ListChannels_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_UpdateChannelPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateChannelPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).UpdateChannelPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/UpdateChannelPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).UpdateChannelPolicy(ctx, req.(*UpdateChannelPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ForwardingHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForwardingHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ForwardingHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ForwardingHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ForwardingHistory(ctx, req.(*ForwardingHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_SendPayment_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(LightningServer).SendPayment(&lightningSendPaymentServer{stream})
 }
@@ -1510,6 +1991,24 @@ func (x *lightningSendPaymentServer) Recv() (*SendRequest, error) {
 	return m, nil
 }
 
+func _Lightning_ListPayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPaymentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ListPayments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ListPayments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ListPayments(ctx, req.(*ListPaymentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_AddInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Invoice)
 	if err := dec(in); err != nil {
@@ -1546,6 +2045,24 @@ func _Lightning_LookupInvoice_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_CancelInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PaymentHash)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).CancelInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/CancelInvoice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).CancelInvoice(ctx, req.(*PaymentHash))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_ListInvoices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListInvoiceRequest)
 	if err := dec(in); err != nil {
@@ -1564,6 +2081,45 @@ func _Lightning_ListInvoices_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_SubscribeInvoices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InvoiceSubscription)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LightningServer).SubscribeInvoices(m, &lightningSubscribeInvoicesServer{stream})
+}
+
+type Lightning_SubscribeInvoicesServer interface {
+	Send(*Invoice) error
+	grpc.ServerStream
+}
+
+type lightningSubscribeInvoicesServer struct {
+	grpc.ServerStream
+}
+
+func (x *lightningSubscribeInvoicesServer) Send(m *Invoice) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Lightning_DecodePayReq_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayReqString)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).DecodePayReq(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/DecodePayReq",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).DecodePayReq(ctx, req.(*PayReqString))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_ShowRoutingTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ShowRoutingTableRequest)
 	if err := dec(in); err != nil {
@@ -1626,6 +2182,18 @@ var _Lightning_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListChannels",
 			Handler:    _Lightning_ListChannels_Handler,
 		},
+		{
+			MethodName: "UpdateChannelPolicy",
+			Handler:    _Lightning_UpdateChannelPolicy_Handler,
+		},
+		{
+			MethodName: "ForwardingHistory",
+			Handler:    _Lightning_ForwardingHistory_Handler,
+		},
+		{
+			MethodName: "ListPayments",
+			Handler:    _Lightning_ListPayments_Handler,
+		},
 		{
 			MethodName: "AddInvoice",
 			Handler:    _Lightning_AddInvoice_Handler,
@@ -1634,10 +2202,18 @@ var _Lightning_serviceDesc = grpc.ServiceDesc{
 			MethodName: "LookupInvoice",
 			Handler:    _Lightning_LookupInvoice_Handler,
 		},
+		{
+			MethodName: "CancelInvoice",
+			Handler:    _Lightning_CancelInvoice_Handler,
+		},
 		{
 			MethodName: "ListInvoices",
 			Handler:    _Lightning_ListInvoices_Handler,
 		},
+		{
+			MethodName: "DecodePayReq",
+			Handler:    _Lightning_DecodePayReq_Handler,
+		},
 		{
 			MethodName: "ShowRoutingTable",
 			Handler:    _Lightning_ShowRoutingTable_Handler,
@@ -1660,6 +2236,11 @@ var _Lightning_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "SubscribeInvoices",
+			Handler:       _Lightning_SubscribeInvoices_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: fileDescriptor0,
 }
@@ -1667,117 +2248,185 @@ var _Lightning_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("rpc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1781 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xb4, 0x58, 0xdd, 0x6e, 0x13, 0xd7,
-	0x16, 0xc6, 0xb1, 0x1d, 0xdb, 0xcb, 0x3f, 0x71, 0x76, 0xfe, 0x1c, 0x43, 0xce, 0xe1, 0x8c, 0x00,
-	0x05, 0x04, 0x01, 0xcc, 0x91, 0x40, 0xa0, 0xc3, 0x51, 0x08, 0x29, 0x49, 0x31, 0x49, 0x8a, 0x83,
-	0x50, 0xaf, 0xa6, 0x93, 0xf1, 0x4e, 0x3c, 0xca, 0x78, 0x66, 0xea, 0xd9, 0x4e, 0x70, 0x1f, 0xa0,
-	0x0f, 0xd0, 0xab, 0x4a, 0x7d, 0x81, 0xaa, 0xaa, 0xaa, 0xbe, 0x47, 0xef, 0xfa, 0x44, 0x5d, 0xfb,
-	0x67, 0xfe, 0x27, 0x48, 0xbd, 0xe8, 0x95, 0x35, 0x6b, 0xaf, 0xbd, 0xfe, 0xf6, 0xb7, 0xbf, 0xb5,
-	0xb6, 0xa1, 0x36, 0xf1, 0xcc, 0x2d, 0x6f, 0xe2, 0x32, 0x97, 0x94, 0x6d, 0x07, 0x3f, 0xb4, 0xaf,
-	0xa0, 0x3e, 0xa0, 0xce, 0xf0, 0x3d, 0xfd, 0x76, 0x4a, 0x7d, 0x46, 0x1a, 0x50, 0x1a, 0xe2, 0x6f,
-	0xa7, 0x70, 0xb3, 0xb0, 0xd9, 0x20, 0x75, 0x28, 0x1a, 0x63, 0xd6, 0x99, 0xc3, 0x8f, 0x22, 0x59,
-	0x86, 0x86, 0x67, 0xcc, 0xc6, 0xd4, 0x61, 0xfa, 0xc8, 0xf0, 0x47, 0x9d, 0xa2, 0x50, 0x59, 0x84,
-	0xda, 0xa9, 0xe1, 0x33, 0xdd, 0x47, 0x23, 0x9d, 0x12, 0x8a, 0xaa, 0x5a, 0x0b, 0x1a, 0xd2, 0xa4,
-	0xef, 0xb9, 0x8e, 0x4f, 0xb5, 0xe7, 0xd0, 0xd8, 0x19, 0x19, 0x8e, 0x43, 0xed, 0x23, 0xd7, 0x72,
-	0x18, 0x37, 0x74, 0x3a, 0x75, 0x86, 0x96, 0x73, 0xa6, 0xb3, 0x4f, 0xd6, 0x50, 0xf9, 0x42, 0xa9,
-	0x3b, 0x65, 0xde, 0x94, 0xe9, 0x96, 0x33, 0xa4, 0x9f, 0x84, 0xd3, 0xa6, 0xf6, 0x5f, 0x68, 0xf7,
-	0xad, 0xb3, 0x11, 0x73, 0x50, 0x7b, 0x7b, 0x38, 0x9c, 0x50, 0xdf, 0x27, 0x04, 0xc0, 0x9b, 0x9e,
-	0xbc, 0xa5, 0xb3, 0x3d, 0x1e, 0x06, 0xdf, 0x5d, 0xe3, 0x71, 0x8f, 0x5c, 0x5f, 0x86, 0x5a, 0xd3,
-	0xbe, 0x2f, 0xc0, 0x02, 0x0f, 0xe1, 0x9d, 0xe1, 0xcc, 0x82, 0xcc, 0x5e, 0x42, 0x83, 0x1b, 0x38,
-	0x76, 0xb7, 0xc7, 0xee, 0xd4, 0xe1, 0x19, 0x16, 0x37, 0xeb, 0xbd, 0xcd, 0x2d, 0x51, 0x86, 0xad,
-	0x94, 0xf6, 0x56, 0x5c, 0x75, 0xd7, 0x61, 0x93, 0x59, 0xf7, 0x09, 0x2c, 0x66, 0x84, 0xbc, 0x40,
-	0xe7, 0x74, 0xa6, 0x62, 0x68, 0x42, 0xf9, 0xc2, 0xb0, 0xa7, 0x54, 0xd6, 0xeb, 0xf9, 0xdc, 0xb3,
-	0x82, 0x76, 0x13, 0xda, 0x91, 0x65, 0x59, 0x0e, 0x1e, 0x6a, 0x98, 0x76, 0x4d, 0x7b, 0x24, 0x35,
-	0x76, 0xb0, 0x32, 0x7e, 0xec, 0x10, 0x0c, 0x74, 0xa5, 0xcc, 0xb6, 0x60, 0xde, 0x90, 0x21, 0x0b,
-	0xbb, 0xda, 0x7f, 0x60, 0x31, 0xb6, 0x23, 0xd7, 0xe8, 0x8f, 0x05, 0x58, 0x3c, 0xa0, 0x97, 0xaa,
-	0x60, 0x81, 0xd9, 0x1e, 0xea, 0xcc, 0x3c, 0x2a, 0x74, 0x5a, 0xbd, 0x5b, 0x2a, 0xf3, 0x8c, 0xde,
-	0x96, 0xfa, 0x3c, 0x46, 0x5d, 0xed, 0x10, 0xea, 0xb1, 0x4f, 0xb2, 0x06, 0x4b, 0x1f, 0xf7, 0x8f,
-	0x0f, 0x76, 0x07, 0x03, 0xfd, 0xe8, 0xc3, 0xab, 0xb7, 0xbb, 0x5f, 0xeb, 0x7b, 0xdb, 0x83, 0xbd,
-	0xf6, 0x35, 0xb2, 0x0a, 0x04, 0xa5, 0xc7, 0xbb, 0xaf, 0x13, 0xf2, 0x02, 0x59, 0x80, 0x7a, 0x5c,
-	0x30, 0xa7, 0xdd, 0x46, 0xc5, 0x98, 0x47, 0x15, 0xfe, 0x02, 0x54, 0x0c, 0x29, 0x52, 0x19, 0xbc,
-	0x00, 0xb2, 0xe3, 0x22, 0x64, 0x4c, 0x76, 0x44, 0xe9, 0x24, 0xc8, 0xe0, 0x76, 0xac, 0x30, 0xf5,
-	0xde, 0x9a, 0xca, 0x20, 0x0d, 0x10, 0xed, 0x0e, 0x2c, 0x25, 0x36, 0x47, 0x4e, 0x3c, 0xfc, 0xd6,
-	0x55, 0x99, 0xca, 0x9a, 0x07, 0xa5, 0xbd, 0xe3, 0xfe, 0x0e, 0x69, 0x43, 0xd5, 0x72, 0x4c, 0x77,
-	0x8c, 0x26, 0xc4, 0x4a, 0x35, 0x5d, 0x73, 0x8e, 0x72, 0x8e, 0x79, 0xdd, 0x76, 0xcd, 0x73, 0x05,
-	0xfc, 0x75, 0x58, 0xa4, 0x9f, 0x3c, 0x6b, 0x62, 0x30, 0xcb, 0x75, 0xf4, 0x11, 0xe5, 0x41, 0x88,
-	0x0b, 0xd0, 0x24, 0x1d, 0x68, 0x4f, 0xe8, 0x85, 0x6b, 0xca, 0xa5, 0x21, 0xb5, 0x8d, 0x59, 0xa7,
-	0x2c, 0xe0, 0xfc, 0x67, 0x01, 0x9a, 0xdb, 0x26, 0xb3, 0x2e, 0xa8, 0xba, 0x11, 0x64, 0x05, 0x9a,
-	0x13, 0x3a, 0x76, 0x19, 0xd5, 0x11, 0xd3, 0x11, 0x96, 0x50, 0x6c, 0x4a, 0x0d, 0xdd, 0xe3, 0x97,
-	0x46, 0x02, 0x9b, 0x47, 0x6a, 0x1a, 0x9e, 0x61, 0x5a, 0x6c, 0x26, 0xc2, 0x28, 0x72, 0x45, 0x0c,
-	0xca, 0xb0, 0xf5, 0x13, 0xc3, 0x36, 0x1c, 0x93, 0x8a, 0x10, 0x8a, 0x78, 0x1e, 0x2d, 0x65, 0x36,
-	0x90, 0x97, 0x85, 0x1c, 0xa3, 0x9e, 0x62, 0x2d, 0x18, 0xb3, 0xe9, 0x30, 0x5c, 0x9a, 0x17, 0x4b,
-	0x1a, 0x34, 0x3d, 0x2a, 0xaf, 0xe5, 0x88, 0xd9, 0xa6, 0xdf, 0xa9, 0x88, 0x1b, 0x52, 0x57, 0x55,
-	0x16, 0x95, 0x5a, 0x82, 0xba, 0x33, 0x1d, 0xeb, 0x53, 0x6f, 0x68, 0x30, 0xea, 0x77, 0xaa, 0xb8,
-	0xb1, 0xa4, 0xad, 0xc0, 0x52, 0xdf, 0xf2, 0x99, 0xca, 0x28, 0x80, 0x91, 0xf6, 0x12, 0x96, 0x93,
-	0x62, 0x75, 0x0c, 0x77, 0x30, 0x07, 0x25, 0xeb, 0xd4, 0x84, 0x8b, 0x65, 0xe5, 0x22, 0x51, 0x19,
-	0xed, 0xa7, 0x02, 0x94, 0xf8, 0xf9, 0x71, 0x66, 0xb0, 0x83, 0x23, 0x0e, 0x0e, 0xaf, 0x16, 0x3f,
-	0x4d, 0x5e, 0x9b, 0x72, 0x1c, 0x43, 0x45, 0xa1, 0x81, 0x3c, 0x71, 0x32, 0xc3, 0x30, 0x39, 0x37,
-	0xc9, 0xa3, 0x29, 0x45, 0xb2, 0x09, 0x35, 0x2f, 0x44, 0x4d, 0x4a, 0xbc, 0xa8, 0xbe, 0xc1, 0xa4,
-	0x96, 0x2c, 0x85, 0x92, 0x08, 0x9d, 0x8a, 0x90, 0xa0, 0x71, 0xcb, 0x39, 0x41, 0x44, 0x0c, 0x45,
-	0xd2, 0x55, 0x8d, 0x70, 0x62, 0xf2, 0x05, 0xc0, 0xc2, 0x8c, 0x1f, 0xc2, 0x62, 0x4c, 0xa6, 0xd2,
-	0xed, 0x42, 0x99, 0xc7, 0xe9, 0x2b, 0xc2, 0x09, 0xca, 0xc9, 0x95, 0xb4, 0x36, 0xb4, 0xde, 0x50,
-	0xb6, 0xef, 0x9c, 0xba, 0x81, 0x89, 0x9f, 0x91, 0xb9, 0x42, 0x91, 0xb2, 0x90, 0x9f, 0x3f, 0x82,
-	0xcc, 0x1a, 0x62, 0xc8, 0x08, 0x05, 0x3d, 0xc8, 0x5b, 0x82, 0x64, 0x0d, 0x16, 0xc2, 0x15, 0x05,
-	0x2a, 0x59, 0x90, 0x1b, 0xb0, 0xcc, 0x4f, 0x2f, 0x38, 0xe5, 0xf0, 0x14, 0x24, 0x6a, 0xaf, 0xc3,
-	0x12, 0x5f, 0x35, 0xc4, 0x21, 0x44, 0x8b, 0x02, 0xb8, 0xfc, 0x02, 0xc8, 0xad, 0x3c, 0x93, 0x79,
-	0x81, 0xe5, 0x0f, 0xe2, 0x8a, 0x9e, 0x5a, 0x93, 0xb1, 0xc0, 0xf9, 0x07, 0x81, 0x09, 0xae, 0x78,
-	0xc2, 0x6f, 0x89, 0xee, 0x8f, 0x8c, 0x88, 0xd9, 0xa5, 0x48, 0x5d, 0x12, 0x79, 0x5c, 0x88, 0x50,
-	0x6e, 0xd1, 0x44, 0x13, 0xbe, 0x6e, 0xd3, 0x53, 0x26, 0x82, 0x6c, 0x6a, 0xff, 0x87, 0x45, 0x85,
-	0x80, 0x43, 0x0c, 0x54, 0x59, 0xbd, 0x97, 0xbe, 0x0e, 0x92, 0x01, 0x96, 0x54, 0x31, 0xe3, 0xed,
-	0x45, 0x50, 0x87, 0xfc, 0xde, 0xb1, 0x5d, 0x9f, 0x2a, 0x0b, 0x18, 0x84, 0x89, 0x9f, 0xa9, 0xa6,
-	0x83, 0xc7, 0xea, 0x4f, 0x4d, 0x33, 0xa8, 0x5d, 0x55, 0x1b, 0x22, 0x75, 0xf0, 0x5d, 0xca, 0x42,
-	0x40, 0x3c, 0x7f, 0xc3, 0x3f, 0x87, 0x18, 0xb3, 0xc6, 0x54, 0xb7, 0xad, 0xb1, 0x15, 0xf0, 0x07,
-	0xb6, 0x86, 0x53, 0x77, 0x82, 0x57, 0xad, 0x28, 0xbc, 0xfc, 0x8e, 0xfc, 0x2c, 0xdc, 0x0c, 0x98,
-	0xc1, 0xa6, 0xbe, 0x0a, 0xf1, 0x01, 0x3a, 0xe1, 0xc2, 0xe0, 0x80, 0x94, 0x93, 0xe5, 0x10, 0x31,
-	0x42, 0x2a, 0x95, 0xf7, 0xae, 0x91, 0xc7, 0x98, 0x51, 0xac, 0xfe, 0xc2, 0x53, 0xbd, 0xb7, 0x1e,
-	0x84, 0x94, 0x39, 0x1a, 0xdc, 0xf2, 0x10, 0x80, 0xa7, 0xa1, 0x0b, 0x37, 0x22, 0x96, 0xd8, 0x86,
-	0x4c, 0xcd, 0xf6, 0xae, 0xbd, 0xaa, 0xc2, 0xbc, 0xbc, 0xeb, 0xda, 0x06, 0x34, 0x13, 0x01, 0x24,
-	0x3a, 0x4e, 0x43, 0xfb, 0xa5, 0x00, 0x84, 0x9f, 0x57, 0xaa, 0x6e, 0x78, 0xc8, 0xcc, 0x98, 0x9c,
-	0x51, 0xa6, 0x27, 0x98, 0x97, 0xf3, 0x88, 0x92, 0x3b, 0xee, 0x50, 0x36, 0xcc, 0x06, 0x87, 0xa7,
-	0xa4, 0xb2, 0x60, 0x3a, 0x50, 0x14, 0x2c, 0x89, 0x6e, 0x03, 0x56, 0x14, 0xa3, 0xa5, 0x96, 0x25,
-	0xe1, 0x21, 0xe8, 0x91, 0xc1, 0xc7, 0x96, 0xef, 0x73, 0xce, 0xf5, 0xad, 0xef, 0x02, 0xc6, 0x53,
-	0xc8, 0x15, 0x38, 0x53, 0xc8, 0xfd, 0xb5, 0x00, 0x6d, 0x1e, 0x6c, 0xa2, 0xfa, 0xf7, 0xb1, 0x9c,
-	0xbc, 0x36, 0xff, 0x58, 0xf1, 0x1f, 0x40, 0x4d, 0x38, 0x70, 0xd1, 0x83, 0xaa, 0x7d, 0x27, 0x59,
-	0xfb, 0x08, 0xf0, 0x89, 0xd2, 0xff, 0x0f, 0x56, 0x94, 0xfb, 0x54, 0x75, 0x6f, 0xc1, 0xbc, 0x2f,
-	0x52, 0x50, 0x2d, 0x7d, 0x39, 0x69, 0x4e, 0xa6, 0xa7, 0xfd, 0x36, 0x07, 0xab, 0xe9, 0xfd, 0x8a,
-	0x59, 0xbe, 0x80, 0x76, 0x86, 0x0c, 0x24, 0x4d, 0xdd, 0x4f, 0xe6, 0x9d, 0xda, 0x98, 0x12, 0x77,
-	0xff, 0x28, 0x40, 0x2b, 0x29, 0xca, 0x34, 0xdb, 0x0c, 0x8b, 0xcd, 0xe5, 0xf7, 0xb9, 0x62, 0xa6,
-	0xcf, 0x95, 0xf2, 0xfb, 0x5c, 0xf9, 0x8a, 0x3e, 0x37, 0x1f, 0x0c, 0xab, 0x89, 0xeb, 0x5e, 0x11,
-	0x66, 0xa3, 0x82, 0x55, 0x3f, 0x53, 0xb0, 0xfb, 0xb0, 0xfc, 0xd1, 0xb0, 0x6d, 0xca, 0x5e, 0x49,
-	0x93, 0x41, 0xb9, 0xd1, 0xe6, 0xa5, 0xc5, 0x1c, 0x24, 0x0b, 0xdd, 0x75, 0x6c, 0xd9, 0xa9, 0xab,
-	0xda, 0x26, 0xac, 0xa4, 0xb4, 0xa3, 0x71, 0x23, 0x88, 0x89, 0x6b, 0x16, 0xb4, 0x35, 0x58, 0x51,
-	0x8e, 0x92, 0x86, 0xb5, 0xbb, 0xb0, 0x9a, 0x5e, 0xc8, 0xb7, 0x51, 0xd4, 0xbe, 0x81, 0xf6, 0x7b,
-	0x1c, 0x93, 0x31, 0xaf, 0x63, 0xe3, 0xc4, 0xa6, 0x7d, 0xcb, 0x39, 0xe7, 0x43, 0xa8, 0x35, 0x7c,
-	0xac, 0xda, 0x82, 0xf8, 0xe8, 0x45, 0xe3, 0x02, 0x9f, 0xa9, 0x3f, 0x5b, 0x58, 0x1c, 0x75, 0x2e,
-	0x25, 0x2f, 0x97, 0x45, 0x94, 0xeb, 0xb0, 0x36, 0x18, 0xb9, 0x97, 0x71, 0x2f, 0x41, 0x9c, 0xbb,
-	0xd0, 0xc9, 0x2e, 0xa9, 0x48, 0xef, 0xc6, 0xba, 0xba, 0x84, 0x50, 0x30, 0x9e, 0xa5, 0xe3, 0xd5,
-	0x6c, 0xa8, 0xec, 0x3b, 0x17, 0xae, 0x65, 0x0a, 0x12, 0x19, 0xe3, 0xf1, 0x45, 0x2d, 0x1d, 0x5b,
-	0x2e, 0xb5, 0x3c, 0xa6, 0x18, 0x01, 0xa9, 0x74, 0xa2, 0x7b, 0x13, 0x6a, 0x8d, 0x8d, 0x33, 0xaa,
-	0xe6, 0x2e, 0x8c, 0x77, 0x22, 0x1f, 0x20, 0x25, 0xf1, 0x1d, 0x4e, 0xdd, 0xe5, 0xa0, 0x51, 0xab,
-	0xf1, 0x46, 0x20, 0xa1, 0xaa, 0xdd, 0x02, 0x82, 0x73, 0xa1, 0x72, 0x18, 0x86, 0x1b, 0x59, 0x91,
-	0xfc, 0xb5, 0x81, 0x73, 0xaa, 0x7c, 0xdc, 0xf0, 0x47, 0x45, 0x66, 0xf9, 0x1e, 0x10, 0xde, 0xd9,
-	0x43, 0x2b, 0x21, 0x20, 0x82, 0xeb, 0x13, 0x03, 0xc4, 0x53, 0x39, 0x0e, 0xa5, 0x3d, 0xde, 0xe4,
-	0x43, 0xa6, 0x10, 0x05, 0x05, 0x6a, 0xa9, 0x02, 0x29, 0xcd, 0x7b, 0x3d, 0x68, 0x26, 0x80, 0x48,
-	0x2a, 0x50, 0xdc, 0xee, 0xf7, 0x71, 0xba, 0xae, 0x43, 0xe5, 0xf0, 0x68, 0xf7, 0x60, 0xff, 0xe0,
-	0x0d, 0x8e, 0xd4, 0xf8, 0xb1, 0xd3, 0x3f, 0x1c, 0xf0, 0x8f, 0xb9, 0xde, 0x0f, 0x35, 0xa8, 0x85,
-	0xf3, 0x2f, 0xf9, 0x12, 0x9a, 0x09, 0x2c, 0x92, 0xeb, 0xca, 0x45, 0x1e, 0x9e, 0xbb, 0x37, 0xf2,
-	0x17, 0x55, 0xbc, 0xef, 0xa0, 0x95, 0x04, 0x25, 0xb9, 0x91, 0xbc, 0x2d, 0x29, 0x6b, 0x1b, 0x57,
-	0xac, 0x2a, 0x73, 0x2f, 0xa0, 0x1a, 0xbc, 0x84, 0xc8, 0x6a, 0xfe, 0xa3, 0xab, 0xbb, 0x96, 0x91,
-	0xab, 0xcd, 0x2f, 0xa1, 0x16, 0x3e, 0x79, 0x48, 0x5c, 0x2b, 0xfe, 0x6c, 0xea, 0x76, 0xb2, 0x0b,
-	0x6a, 0xff, 0x36, 0x40, 0xf4, 0xe8, 0x20, 0x9d, 0xab, 0x5e, 0x3e, 0xdd, 0xf5, 0x9c, 0x15, 0x65,
-	0xe2, 0x35, 0xd4, 0x63, 0x6f, 0x0a, 0x12, 0x63, 0xfa, 0xd4, 0x23, 0xa5, 0xdb, 0xcd, 0x5b, 0x8a,
-	0x12, 0x09, 0x27, 0x44, 0x12, 0xbd, 0x5f, 0x92, 0x73, 0x64, 0x98, 0x48, 0x76, 0x98, 0x7c, 0x06,
-	0x15, 0x35, 0x1d, 0x92, 0x15, 0xa5, 0x94, 0x1c, 0x20, 0xbb, 0xab, 0x69, 0xb1, 0xda, 0xb9, 0x03,
-	0xf5, 0x58, 0x7f, 0x0e, 0xe3, 0xcf, 0xf6, 0xec, 0xf0, 0x14, 0xd2, 0x1d, 0xf2, 0x51, 0x01, 0xfb,
-	0x45, 0x23, 0x3e, 0x1d, 0x91, 0x30, 0xd5, 0xec, 0xc8, 0x14, 0x26, 0x91, 0x99, 0x73, 0xd0, 0xce,
-	0x01, 0x2c, 0x24, 0xdb, 0x85, 0x1f, 0x82, 0x2b, 0xb7, 0xd3, 0x85, 0xe0, 0xba, 0xa2, 0x8f, 0xbd,
-	0x81, 0x46, 0xfc, 0xa9, 0x11, 0xc6, 0x95, 0xf3, 0x2c, 0xe9, 0x5e, 0xcf, 0x5d, 0x53, 0x86, 0x9e,
-	0xcb, 0x7f, 0x43, 0x14, 0x15, 0x10, 0x12, 0x43, 0x54, 0xb0, 0x7f, 0x29, 0x21, 0x93, 0xfb, 0x36,
-	0x0b, 0x98, 0xd4, 0x53, 0x80, 0x88, 0x68, 0x48, 0xea, 0x72, 0x87, 0xd0, 0xca, 0xe1, 0xa2, 0x27,
-	0xd0, 0xec, 0xbb, 0xee, 0xf9, 0xd4, 0x0b, 0xf6, 0x06, 0x6e, 0x63, 0x8c, 0xd4, 0x4d, 0xd9, 0x23,
-	0xbb, 0x32, 0x65, 0xf5, 0xe9, 0x87, 0x07, 0x9a, 0xa5, 0xa9, 0x6e, 0x37, 0x6f, 0x49, 0xf9, 0x1e,
-	0x40, 0x3b, 0x4d, 0xe9, 0xe4, 0x5f, 0x41, 0x86, 0xf9, 0x6d, 0xa0, 0xfb, 0xef, 0x2b, 0xd7, 0xa5,
-	0xd1, 0x93, 0x79, 0xf1, 0x0f, 0xd3, 0x93, 0xbf, 0x02, 0x00, 0x00, 0xff, 0xff, 0x24, 0x8f, 0x46,
-	0x46, 0x6e, 0x12, 0x00, 0x00,
+	// 2867 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x39, 0x4b, 0x6f, 0xe3, 0xd6,
+	0xd5, 0x43, 0xeb, 0x7d, 0xf4, 0x30, 0x75, 0x2d, 0xdb, 0xb4, 0x66, 0xe6, 0x8b, 0x43, 0xe4, 0xe1,
+	0x0c, 0x26, 0x93, 0x89, 0xf3, 0x01, 0x49, 0x13, 0x64, 0x0a, 0x8d, 0x4c, 0x8f, 0xd5, 0xd1, 0x48,
+	0x86, 0x25, 0x67, 0x32, 0x40, 0x01, 0x96, 0x26, 0xaf, 0x6c, 0x66, 0x24, 0x92, 0x25, 0xaf, 0xc6,
+	0xe3, 0x02, 0x5d, 0x74, 0xd3, 0x4d, 0x77, 0x45, 0x17, 0x5d, 0x75, 0xdf, 0xa2, 0x28, 0xba, 0xea,
+	0x9f, 0xc8, 0xae, 0xbf, 0xa3, 0xff, 0xa0, 0x8b, 0x16, 0xf7, 0x45, 0x91, 0x14, 0x9d, 0xa2, 0x8b,
+	0xae, 0x04, 0x9e, 0x73, 0xee, 0xb9, 0xe7, 0x7d, 0xcf, 0x39, 0x82, 0x5a, 0x18, 0xd8, 0x8f, 0x82,
+	0xd0, 0x27, 0x3e, 0x2a, 0xcd, 0xbd, 0x30, 0xb0, 0xf5, 0xef, 0xa0, 0x3e, 0xc1, 0x9e, 0x73, 0x86,
+	0x7f, 0xbe, 0xc4, 0x11, 0x41, 0x0d, 0x28, 0x3a, 0x38, 0x22, 0x9a, 0xb2, 0xaf, 0x1c, 0x34, 0x50,
+	0x1d, 0x0a, 0xd6, 0x82, 0x68, 0x1b, 0xfb, 0xca, 0x41, 0x01, 0x75, 0xa0, 0x11, 0x58, 0x37, 0x0b,
+	0xec, 0x11, 0xf3, 0xca, 0x8a, 0xae, 0xb4, 0x02, 0x23, 0x69, 0x43, 0x6d, 0x66, 0x45, 0xc4, 0x8c,
+	0xb0, 0xe7, 0x68, 0xc5, 0x7d, 0xe5, 0xa0, 0x8a, 0x76, 0x61, 0x53, 0x12, 0x86, 0x9c, 0xad, 0x56,
+	0xda, 0x57, 0x0e, 0x6a, 0xfa, 0x6f, 0x15, 0x68, 0xf0, 0xcb, 0xa2, 0xc0, 0xf7, 0x22, 0xbc, 0xc6,
+	0x92, 0xdf, 0xaa, 0x81, 0x2a, 0xa1, 0x41, 0x88, 0xdd, 0x85, 0x75, 0x89, 0x99, 0x08, 0x0d, 0xb4,
+	0x0d, 0xcd, 0x98, 0xb3, 0xbf, 0x24, 0x58, 0x2b, 0xec, 0x17, 0x0e, 0x6a, 0x54, 0xcc, 0x19, 0xc6,
+	0xec, 0xf6, 0x02, 0x7a, 0xb4, 0xba, 0x7d, 0x66, 0xb9, 0xf3, 0x65, 0x88, 0xd9, 0xed, 0xf5, 0xc3,
+	0xed, 0x47, 0x4c, 0xe3, 0x47, 0xa7, 0x1c, 0x7b, 0xcc, 0x91, 0xfa, 0x97, 0xd0, 0xe8, 0x5f, 0x59,
+	0x9e, 0x87, 0xe7, 0xa7, 0xbe, 0xeb, 0x11, 0x2a, 0xd3, 0x6c, 0xe9, 0x39, 0xae, 0x77, 0x69, 0x92,
+	0xb7, 0xae, 0x23, 0x64, 0xea, 0x40, 0xc3, 0x5f, 0x92, 0x60, 0x49, 0x4c, 0xd7, 0x73, 0xf0, 0x5b,
+	0x26, 0x4f, 0x53, 0xff, 0x7f, 0x50, 0x87, 0xee, 0xe5, 0x15, 0xf1, 0x5c, 0xef, 0xb2, 0xe7, 0x38,
+	0x21, 0x8e, 0x22, 0x84, 0x00, 0x82, 0xe5, 0xc5, 0x73, 0x7c, 0x73, 0x22, 0x35, 0xaa, 0x51, 0xab,
+	0x5e, 0xf9, 0x11, 0x37, 0x64, 0x4d, 0xff, 0xb5, 0x02, 0x9b, 0xd4, 0x0c, 0x2f, 0x2c, 0xef, 0x46,
+	0xda, 0xfd, 0x09, 0x34, 0x28, 0x83, 0xa9, 0xdf, 0x5b, 0xf8, 0x4b, 0x8f, 0xda, 0xbf, 0x70, 0x50,
+	0x3f, 0x3c, 0x10, 0x22, 0x67, 0xa8, 0x1f, 0x25, 0x49, 0x0d, 0x8f, 0x84, 0x37, 0xdd, 0xcf, 0xa0,
+	0xbd, 0x06, 0xa4, 0x76, 0x79, 0x8d, 0x6f, 0x84, 0x0c, 0x4d, 0x28, 0xbd, 0xb1, 0xe6, 0x4b, 0x6e,
+	0xca, 0xc2, 0x97, 0x1b, 0x5f, 0x28, 0xfa, 0x3e, 0xa8, 0x2b, 0xce, 0xc2, 0x25, 0x0d, 0x28, 0xc6,
+	0x6a, 0xd7, 0xf4, 0xc7, 0x9c, 0xa2, 0xef, 0xbb, 0x5e, 0x94, 0x08, 0x11, 0xcb, 0x71, 0x42, 0xc1,
+	0xb6, 0x05, 0x65, 0x8b, 0x8b, 0xcc, 0xf8, 0xea, 0xef, 0x42, 0x3b, 0x71, 0x22, 0x97, 0xe9, 0xef,
+	0x15, 0x68, 0x8f, 0xf0, 0xb5, 0x30, 0x98, 0x64, 0x7b, 0x08, 0x45, 0x72, 0x13, 0x60, 0x46, 0xd3,
+	0x3a, 0x7c, 0x4f, 0x68, 0xbe, 0x46, 0xf7, 0x48, 0x7c, 0x4e, 0x6f, 0x02, 0xac, 0x8f, 0xa1, 0x9e,
+	0xf8, 0x44, 0xbb, 0xb0, 0xf5, 0x72, 0x30, 0x1d, 0x19, 0x93, 0x89, 0x79, 0x7a, 0xfe, 0xf4, 0xb9,
+	0xf1, 0xca, 0x3c, 0xe9, 0x4d, 0x4e, 0xd4, 0x3b, 0x68, 0x07, 0xd0, 0xc8, 0x98, 0x4c, 0x8d, 0xa3,
+	0x14, 0x5c, 0x41, 0x9b, 0x50, 0x4f, 0x02, 0x36, 0xf4, 0xf7, 0x01, 0x25, 0x6f, 0x14, 0xe2, 0x6f,
+	0x42, 0xc5, 0xe2, 0x20, 0xa1, 0xc1, 0x57, 0x80, 0xfa, 0xbe, 0xe7, 0x61, 0x9b, 0x9c, 0x62, 0x1c,
+	0x4a, 0x0d, 0xde, 0x4f, 0x18, 0xa6, 0x7e, 0xb8, 0x2b, 0x34, 0xc8, 0x06, 0x88, 0xfe, 0x01, 0x6c,
+	0xa5, 0x0e, 0xaf, 0x2e, 0x09, 0x30, 0x0e, 0x4d, 0x61, 0xa6, 0x92, 0x1e, 0x40, 0xf1, 0x64, 0x3a,
+	0xec, 0x23, 0x15, 0xaa, 0xae, 0x67, 0xfb, 0x0b, 0xd7, 0xbb, 0x64, 0x98, 0x6a, 0xd6, 0xe6, 0x34,
+	0x07, 0x69, 0xfa, 0x98, 0x73, 0xdf, 0x7e, 0x2d, 0xd2, 0x72, 0x0f, 0xda, 0xf8, 0x6d, 0xe0, 0x86,
+	0x16, 0x71, 0x7d, 0xcf, 0xbc, 0xc2, 0x54, 0x08, 0x96, 0x20, 0x4d, 0x9a, 0x5e, 0x21, 0x7e, 0xe3,
+	0xdb, 0x1c, 0xe5, 0xe0, 0xb9, 0x75, 0xc3, 0x32, 0xa4, 0xa9, 0xff, 0x5d, 0x81, 0x66, 0xcf, 0x26,
+	0xee, 0x1b, 0x2c, 0x32, 0x82, 0x26, 0x5c, 0x88, 0x17, 0x3e, 0xc1, 0x66, 0xb0, 0xbc, 0x58, 0xc5,
+	0xd2, 0x36, 0x34, 0x6d, 0x4e, 0x61, 0x06, 0xbe, 0x2b, 0xe4, 0xa8, 0x51, 0x49, 0x6d, 0x2b, 0xb0,
+	0x6c, 0x97, 0xdc, 0x30, 0x31, 0x0a, 0x94, 0x70, 0xee, 0xdb, 0xd6, 0xdc, 0xbc, 0xb0, 0xe6, 0x96,
+	0x67, 0xcb, 0x1c, 0xdd, 0x81, 0x96, 0x60, 0x2b, 0xe1, 0x25, 0x06, 0xdf, 0x83, 0xf6, 0xd2, 0x8b,
+	0x30, 0x21, 0x73, 0xec, 0xc4, 0xa8, 0x32, 0x43, 0xe9, 0xd0, 0x0c, 0x30, 0x4f, 0xcb, 0x2b, 0x32,
+	0xb7, 0x23, 0xad, 0xc2, 0x32, 0xa4, 0x2e, 0xac, 0xcc, 0x2c, 0xb5, 0x05, 0x75, 0x6f, 0xb9, 0x30,
+	0x97, 0x81, 0x63, 0x11, 0x1c, 0x69, 0xd5, 0x7d, 0xe5, 0xa0, 0xa8, 0x6f, 0xc3, 0xd6, 0xd0, 0x8d,
+	0x88, 0xd0, 0x48, 0x86, 0x91, 0xfe, 0x04, 0x3a, 0x69, 0xb0, 0x70, 0xc3, 0x07, 0x50, 0x15, 0xaa,
+	0x45, 0x5a, 0x8d, 0x5d, 0xd1, 0x11, 0x57, 0xa4, 0x2c, 0xa3, 0xff, 0x4b, 0x81, 0x22, 0xf5, 0x1f,
+	0xad, 0x0c, 0x73, 0xe9, 0x62, 0xe9, 0xbc, 0x5a, 0xd2, 0x9b, 0xd4, 0x36, 0xa5, 0x64, 0x0c, 0x15,
+	0x18, 0x05, 0x02, 0xb8, 0xb8, 0x21, 0x38, 0xa2, 0x95, 0x93, 0xbb, 0xa6, 0xb8, 0x82, 0x85, 0xd8,
+	0x7e, 0xc3, 0x6c, 0x52, 0xa4, 0x46, 0x8d, 0x2c, 0xc2, 0xa9, 0xb8, 0x29, 0x04, 0x84, 0xd1, 0x54,
+	0x18, 0x64, 0x13, 0x2a, 0xae, 0x77, 0xe1, 0x2f, 0x3d, 0x87, 0x29, 0x5d, 0xa5, 0x11, 0x11, 0xb0,
+	0x0a, 0xe6, 0x2e, 0xb0, 0x56, 0x63, 0x34, 0x1f, 0xc2, 0xe6, 0xe5, 0xdc, 0xbf, 0xb0, 0xe6, 0xe6,
+	0x0c, 0x5b, 0x64, 0x19, 0xe2, 0x48, 0x03, 0xa6, 0x5f, 0x4b, 0xe8, 0x77, 0xcc, 0xc1, 0xe8, 0x03,
+	0x68, 0x71, 0x9f, 0xc5, 0x74, 0xf5, 0x3c, 0x3a, 0x1d, 0xd1, 0xe2, 0x17, 0xb1, 0x20, 0x8e, 0xad,
+	0xfa, 0x09, 0xb4, 0x13, 0x30, 0x61, 0xd2, 0x2e, 0x94, 0xa8, 0x2d, 0x22, 0x4d, 0x49, 0xb9, 0x8c,
+	0x12, 0xe9, 0x2a, 0xb4, 0x9e, 0x61, 0x32, 0xf0, 0x66, 0xbe, 0x64, 0xf1, 0x37, 0x05, 0x36, 0x63,
+	0xd0, 0xea, 0x9d, 0xc8, 0xb1, 0xb1, 0x06, 0xaa, 0xeb, 0x60, 0x8f, 0xb8, 0xe4, 0xc6, 0x94, 0xb6,
+	0xe5, 0x81, 0xb8, 0x0b, 0x9b, 0x31, 0x46, 0x04, 0x2e, 0x37, 0xfa, 0x3d, 0xe8, 0xd0, 0x08, 0x91,
+	0x91, 0x14, 0x7b, 0x9a, 0x67, 0xc6, 0x5d, 0xd8, 0xa2, 0x58, 0x8b, 0x39, 0x7a, 0x85, 0x64, 0xc9,
+	0x41, 0x4d, 0xca, 0x8f, 0x52, 0x4d, 0xca, 0x0c, 0xd4, 0x80, 0xe2, 0x32, 0x74, 0x79, 0x28, 0xd6,
+	0xf4, 0x73, 0x56, 0x14, 0x66, 0x6e, 0xb8, 0x60, 0x99, 0x75, 0xce, 0xa2, 0x90, 0x1e, 0xbb, 0xa0,
+	0x79, 0x69, 0x46, 0x57, 0xd6, 0xea, 0x2d, 0xe1, 0x20, 0x91, 0x96, 0x3c, 0x40, 0x76, 0xa0, 0x45,
+	0xf9, 0xdb, 0xbe, 0x37, 0x8b, 0xcc, 0x39, 0x9e, 0x11, 0x26, 0x72, 0x53, 0xff, 0x31, 0xb4, 0x45,
+	0xcc, 0x8d, 0x03, 0x2c, 0xb9, 0x3e, 0xc8, 0x26, 0x20, 0xaf, 0x39, 0x5b, 0xc2, 0xb4, 0xc9, 0x07,
+	0x8d, 0x15, 0x2b, 0xfe, 0xdd, 0x9f, 0xfb, 0x11, 0x16, 0x1c, 0x3a, 0xd0, 0xb0, 0xe7, 0x7e, 0x94,
+	0x79, 0xe6, 0x36, 0xa1, 0x12, 0x2d, 0x6d, 0x5b, 0x5a, 0xb2, 0xaa, 0x3b, 0xb0, 0xc5, 0x4e, 0x09,
+	0x0e, 0xb2, 0xd4, 0xfd, 0x17, 0xf7, 0xd3, 0xa0, 0xa6, 0x61, 0x68, 0xce, 0xdd, 0x85, 0x2b, 0x2b,
+	0x56, 0x13, 0x4a, 0x33, 0x3f, 0xb4, 0x31, 0xd3, 0xb1, 0xaa, 0xff, 0x55, 0x81, 0x36, 0xbb, 0x66,
+	0x42, 0x2c, 0xb2, 0x8c, 0x84, 0x88, 0x1f, 0x43, 0x93, 0x8a, 0x88, 0xa5, 0xbb, 0xc4, 0x25, 0x9d,
+	0x38, 0x7e, 0x18, 0x94, 0x13, 0x9f, 0xdc, 0x41, 0x9f, 0x42, 0xc3, 0x4e, 0xd8, 0x9f, 0xdd, 0x54,
+	0x3f, 0xdc, 0x93, 0x22, 0xad, 0xb9, 0xe6, 0xe4, 0x0e, 0xfa, 0x04, 0x80, 0xaa, 0x61, 0xb2, 0x6b,
+	0xb4, 0x42, 0xfa, 0xc0, 0x9a, 0xcd, 0x4e, 0xee, 0x3c, 0xad, 0x42, 0x99, 0x57, 0x17, 0xfd, 0x3e,
+	0x34, 0x53, 0x02, 0xa4, 0xde, 0xb8, 0x86, 0xfe, 0x27, 0x05, 0x10, 0xf5, 0x57, 0xc6, 0x6e, 0x3b,
+	0xd0, 0x22, 0x56, 0x78, 0x89, 0x89, 0x99, 0xaa, 0xf5, 0xb4, 0x72, 0x09, 0xb8, 0xe7, 0x3b, 0xb2,
+	0xdb, 0xb9, 0x07, 0x1d, 0x91, 0x88, 0xa2, 0x1f, 0x11, 0x45, 0x9f, 0x97, 0xd6, 0xfb, 0xb0, 0x2d,
+	0x6a, 0x68, 0x06, 0xcd, 0x4b, 0xec, 0x2e, 0x6c, 0xda, 0xfe, 0x62, 0xe1, 0x46, 0x11, 0xad, 0xf2,
+	0x91, 0xfb, 0x0b, 0x59, 0x63, 0x45, 0x1c, 0xb3, 0x38, 0xe3, 0x71, 0xac, 0xff, 0x59, 0x01, 0x95,
+	0x0a, 0x9b, 0xb2, 0xfe, 0x43, 0x68, 0x30, 0xdb, 0xfc, 0xcf, 0x8c, 0xff, 0x31, 0xd4, 0xd8, 0x05,
+	0x7e, 0x80, 0x3d, 0x61, 0x7b, 0x2d, 0x6d, 0xfb, 0x55, 0xc0, 0xa7, 0x4c, 0xff, 0x35, 0x6c, 0x8b,
+	0xeb, 0x33, 0xd6, 0x7d, 0x0f, 0xca, 0x11, 0x53, 0x41, 0x34, 0x11, 0x9d, 0x34, 0x3b, 0xae, 0x9e,
+	0xfe, 0x97, 0x0d, 0xd8, 0xc9, 0x9e, 0x17, 0x75, 0xe6, 0x18, 0xd4, 0xb5, 0xd2, 0xc0, 0x8b, 0xd6,
+	0xc3, 0xb4, 0xde, 0x99, 0x83, 0x19, 0x70, 0xf7, 0x7b, 0x05, 0x5a, 0x69, 0xd0, 0xda, 0xf3, 0xbe,
+	0x56, 0xd3, 0x36, 0xf2, 0x5f, 0xd6, 0xc2, 0xda, 0xcb, 0x5a, 0xcc, 0x7f, 0x59, 0x4b, 0xb7, 0xbc,
+	0xac, 0x65, 0xd9, 0xbc, 0xa7, 0xd2, 0xbd, 0xc2, 0xd8, 0xae, 0x0c, 0x56, 0xfd, 0x01, 0x83, 0x3d,
+	0x84, 0xce, 0x4b, 0x6b, 0x3e, 0xc7, 0xe4, 0x29, 0x67, 0x29, 0xcd, 0xdd, 0x81, 0xc6, 0xb5, 0x4b,
+	0x3c, 0x1c, 0x45, 0xa6, 0xef, 0xcd, 0x79, 0x6f, 0x50, 0xd5, 0x0f, 0x60, 0x3b, 0x43, 0xbd, 0x6a,
+	0x70, 0xa4, 0x4c, 0x94, 0x52, 0xd1, 0x77, 0x61, 0x5b, 0x5c, 0x94, 0x66, 0xac, 0x7f, 0x04, 0x3b,
+	0x59, 0x44, 0x3e, 0x8f, 0x82, 0xfe, 0x33, 0x50, 0xcf, 0xfc, 0x25, 0x71, 0xbd, 0xcb, 0xa9, 0x75,
+	0x31, 0xc7, 0x43, 0xd7, 0x7b, 0x4d, 0xdb, 0x5e, 0xd7, 0xf9, 0x54, 0x3c, 0x12, 0xec, 0xe3, 0x70,
+	0xd5, 0xa0, 0xd0, 0x2e, 0xfe, 0x07, 0x0d, 0xdb, 0x82, 0xf2, 0x35, 0xaf, 0xcb, 0x25, 0x26, 0xe5,
+	0x1e, 0xec, 0x4e, 0xae, 0xfc, 0xeb, 0xe4, 0x2d, 0x52, 0x4e, 0x03, 0xb4, 0x75, 0x94, 0x90, 0xf4,
+	0x23, 0xa8, 0x66, 0x42, 0x48, 0x36, 0x84, 0x59, 0x79, 0xf5, 0x7f, 0x6c, 0x40, 0x65, 0xe0, 0xbd,
+	0xf1, 0x5d, 0x9b, 0x55, 0x91, 0x05, 0x5e, 0xf8, 0xab, 0x2e, 0x22, 0xc4, 0x36, 0x76, 0x03, 0x22,
+	0x4a, 0x02, 0x02, 0x08, 0x57, 0x43, 0x11, 0x6f, 0xf5, 0x5a, 0x50, 0x0e, 0xf9, 0xf8, 0x54, 0x64,
+	0xdf, 0x71, 0xa3, 0x5f, 0x92, 0xbd, 0x81, 0xe8, 0xa8, 0x58, 0x28, 0x54, 0x59, 0x88, 0x85, 0x58,
+	0x74, 0x7f, 0x16, 0xc1, 0xa2, 0x87, 0x68, 0x41, 0x99, 0x75, 0x8c, 0x37, 0x5a, 0x55, 0x16, 0x90,
+	0xec, 0x14, 0x57, 0x63, 0x42, 0x3d, 0x80, 0x12, 0x0d, 0x1a, 0xac, 0x01, 0x8b, 0x99, 0xbb, 0x42,
+	0x2d, 0xa1, 0x81, 0xfc, 0x9d, 0x10, 0xf1, 0xfa, 0x59, 0x8e, 0x23, 0x66, 0xa6, 0x3a, 0xeb, 0x67,
+	0x3a, 0xd0, 0xe0, 0xf2, 0x08, 0x68, 0x43, 0x76, 0x39, 0xd6, 0x82, 0x98, 0x81, 0xe5, 0x3a, 0x5a,
+	0x53, 0x46, 0x2c, 0x85, 0x84, 0xf8, 0x3b, 0x6c, 0x13, 0xec, 0x68, 0x2d, 0xe6, 0xef, 0x1e, 0x34,
+	0x52, 0x17, 0x54, 0xa1, 0x38, 0x3e, 0x35, 0x46, 0xea, 0x1d, 0x54, 0x87, 0xca, 0xc4, 0x98, 0x4e,
+	0x87, 0xc6, 0x91, 0xaa, 0xa0, 0x26, 0xd4, 0xfa, 0xbd, 0x51, 0xdf, 0x18, 0xd2, 0xcf, 0x0d, 0x8a,
+	0x33, 0xbe, 0x3d, 0x1d, 0x9c, 0x19, 0x47, 0x6a, 0x41, 0xff, 0x1a, 0x50, 0xcf, 0x71, 0x04, 0x97,
+	0xd8, 0x5f, 0x2b, 0x2b, 0xf2, 0x97, 0x30, 0x47, 0x7d, 0x3e, 0xbd, 0xdd, 0x87, 0xba, 0x98, 0x20,
+	0xe9, 0x80, 0x97, 0x3d, 0xa7, 0x3f, 0x00, 0x44, 0x3b, 0xa0, 0x98, 0x7d, 0x9c, 0x2a, 0xb2, 0xb0,
+	0x24, 0x52, 0xe5, 0x73, 0xd8, 0x4a, 0xd1, 0x0a, 0x51, 0xf6, 0x69, 0xc3, 0xcf, 0x40, 0x32, 0x74,
+	0x5a, 0x69, 0x1b, 0xeb, 0x7f, 0x2c, 0x40, 0x2b, 0x3d, 0xc6, 0xa2, 0x4f, 0xa0, 0x68, 0xd3, 0xa7,
+	0x83, 0x57, 0xbe, 0x77, 0x73, 0x67, 0xdd, 0x47, 0xe2, 0xb7, 0xef, 0x3b, 0x2c, 0x95, 0x16, 0x38,
+	0x8a, 0xe4, 0x70, 0xcd, 0x7a, 0x23, 0x31, 0x30, 0x9b, 0x91, 0xbf, 0x0c, 0x6d, 0xe9, 0x20, 0xd6,
+	0x86, 0xd0, 0xc2, 0x92, 0xc6, 0xb2, 0x68, 0xab, 0xe9, 0x7f, 0xd8, 0x80, 0x7a, 0x92, 0x6d, 0x1d,
+	0x2a, 0xe7, 0xa3, 0xe7, 0xa3, 0xf1, 0x4b, 0xea, 0x93, 0x06, 0x54, 0x47, 0x63, 0xf3, 0x6c, 0x7c,
+	0x3e, 0x35, 0x54, 0x05, 0x6d, 0x43, 0x5b, 0xa0, 0xcc, 0x91, 0xf1, 0xed, 0xd4, 0x3c, 0x35, 0x8c,
+	0x33, 0x75, 0x03, 0xed, 0xc1, 0xf6, 0x60, 0x34, 0x39, 0x3f, 0x3e, 0x1e, 0xf4, 0x07, 0xc6, 0x68,
+	0x6a, 0xf6, 0x7b, 0xa7, 0xbd, 0xfe, 0x60, 0xfa, 0x4a, 0x2d, 0xa4, 0xdd, 0x58, 0x44, 0x1a, 0x74,
+	0x24, 0x83, 0xd3, 0xde, 0xab, 0x17, 0x94, 0x98, 0xcd, 0x6d, 0x25, 0x3a, 0xf9, 0x0d, 0x46, 0xdf,
+	0x8c, 0x07, 0x7d, 0xc3, 0x1c, 0x8d, 0xa7, 0x14, 0xdb, 0x7b, 0x3a, 0x34, 0xd4, 0x32, 0x42, 0xd0,
+	0xea, 0xbd, 0x18, 0x9f, 0x8f, 0xa6, 0xe6, 0x74, 0x3c, 0x36, 0x87, 0xe3, 0x97, 0x6a, 0x05, 0x6d,
+	0xc1, 0x66, 0x02, 0x76, 0x32, 0x78, 0x76, 0xa2, 0x56, 0x29, 0x90, 0x85, 0xc8, 0x2b, 0x06, 0x9c,
+	0x8c, 0xc7, 0x23, 0x95, 0x56, 0x87, 0x06, 0x1d, 0x2c, 0xcc, 0xe9, 0xe0, 0x85, 0x31, 0x3e, 0x9f,
+	0xaa, 0x80, 0x3a, 0xa0, 0x1e, 0x1b, 0x86, 0x99, 0x14, 0x58, 0xad, 0xa3, 0x2e, 0xec, 0x0c, 0x46,
+	0xfd, 0xf1, 0xd9, 0x99, 0xd1, 0x9f, 0x9a, 0x82, 0xcd, 0x91, 0x31, 0x9c, 0xf6, 0xd4, 0x86, 0xfe,
+	0x4f, 0x05, 0x2a, 0xc2, 0x0d, 0xb7, 0xec, 0x3b, 0xd2, 0x93, 0xb9, 0xdc, 0x66, 0xf0, 0x57, 0xbe,
+	0x01, 0xc5, 0xc0, 0x22, 0x34, 0xb5, 0xe9, 0xa2, 0xe3, 0x61, 0x5c, 0xaf, 0x4b, 0xcc, 0xcd, 0xf7,
+	0xd2, 0x6e, 0x96, 0xbf, 0xbc, 0x6e, 0xe7, 0xee, 0x51, 0xca, 0xec, 0xc6, 0x84, 0x33, 0x43, 0x6c,
+	0x45, 0xbe, 0x27, 0xde, 0x83, 0xb5, 0xd2, 0xc0, 0x4a, 0x81, 0xfe, 0x23, 0x68, 0xa6, 0x39, 0x37,
+	0xa1, 0x36, 0x18, 0x99, 0xc7, 0xc3, 0xc1, 0xb3, 0x93, 0xa9, 0x7a, 0x87, 0x7e, 0x4e, 0xce, 0xfb,
+	0x7d, 0xc3, 0x38, 0x62, 0xc9, 0x07, 0x50, 0x3e, 0xee, 0x0d, 0x58, 0xe6, 0xc9, 0xe9, 0x4b, 0x1c,
+	0x8f, 0xe7, 0x84, 0x2f, 0xa0, 0x93, 0x06, 0xaf, 0x42, 0x5f, 0x88, 0x9c, 0x0d, 0x7d, 0x41, 0xaa,
+	0xbf, 0x03, 0x8d, 0x53, 0x8b, 0x2e, 0x42, 0x26, 0x24, 0x74, 0xbd, 0x4b, 0xf6, 0xae, 0x5a, 0x37,
+	0x34, 0x47, 0xc5, 0x6c, 0xfe, 0x1b, 0x05, 0xca, 0x9c, 0x82, 0x76, 0x55, 0x74, 0x99, 0xe5, 0x7a,
+	0xbc, 0x27, 0x61, 0xf8, 0x35, 0x1f, 0x6c, 0x48, 0x28, 0xed, 0x8a, 0x22, 0x8b, 0xf8, 0xd1, 0x95,
+	0x1b, 0xad, 0xac, 0xcf, 0xaa, 0x31, 0x0b, 0x75, 0x5a, 0xcc, 0x68, 0x23, 0x1b, 0x11, 0x6b, 0x11,
+	0x68, 0xa5, 0x4c, 0xd1, 0x2c, 0xcb, 0x62, 0xeb, 0x61, 0x72, 0xed, 0x87, 0xaf, 0xb9, 0x45, 0xd9,
+	0x1b, 0x47, 0x9f, 0xab, 0x79, 0x26, 0xc9, 0xf5, 0x27, 0xb0, 0x25, 0x0b, 0xd9, 0xf2, 0x22, 0xb2,
+	0x43, 0x37, 0xa0, 0x32, 0xa6, 0x0b, 0xa6, 0x92, 0x5b, 0x30, 0xa9, 0xc0, 0x45, 0xfd, 0x77, 0x0a,
+	0x74, 0x79, 0x6f, 0x14, 0x37, 0xdb, 0x73, 0xd7, 0x8e, 0xf7, 0x49, 0x1f, 0x8a, 0xce, 0xf6, 0x3f,
+	0x76, 0xe7, 0x2a, 0x54, 0x2f, 0xac, 0x08, 0x9b, 0x34, 0xe4, 0x36, 0xe4, 0x78, 0x39, 0xc3, 0xd8,
+	0x0c, 0x2d, 0x82, 0x45, 0xee, 0xab, 0x50, 0x5d, 0xb8, 0x1e, 0x9b, 0xbb, 0x57, 0xdd, 0x25, 0xef,
+	0xe9, 0xe9, 0x18, 0xe3, 0xe0, 0x39, 0xb1, 0xc4, 0x0a, 0xe1, 0x3e, 0xdc, 0xcd, 0x95, 0x4a, 0x68,
+	0xed, 0x81, 0x76, 0xec, 0x87, 0xd7, 0x56, 0x48, 0x4b, 0xe1, 0x89, 0x1b, 0x11, 0x3f, 0x8c, 0x45,
+	0x46, 0x00, 0x11, 0xb1, 0x42, 0xc2, 0x87, 0x56, 0x45, 0x3e, 0x0b, 0xd8, 0x73, 0x38, 0x64, 0x43,
+	0x5a, 0x83, 0x99, 0xc1, 0xf4, 0x67, 0xb3, 0x08, 0x93, 0x55, 0x75, 0xa2, 0xee, 0x5b, 0x58, 0x6f,
+	0x4d, 0xfc, 0x86, 0x45, 0x0f, 0x9b, 0xe8, 0xf4, 0x5f, 0x29, 0xb0, 0xb9, 0xba, 0xd0, 0xa0, 0xa8,
+	0xb4, 0x1b, 0xf9, 0x35, 0xa2, 0xeb, 0xe2, 0xd6, 0x32, 0x5d, 0x4f, 0x04, 0xc5, 0x0e, 0xb4, 0x12,
+	0x60, 0x7f, 0x29, 0x9b, 0x06, 0xb6, 0x7f, 0x61, 0x74, 0x45, 0xe9, 0x75, 0x6b, 0xc1, 0x09, 0x4a,
+	0xc9, 0x14, 0x66, 0x31, 0xa1, 0xff, 0x12, 0xf6, 0x72, 0x74, 0x16, 0x01, 0xff, 0x29, 0xb4, 0x67,
+	0x31, 0x52, 0xca, 0xce, 0x23, 0x7f, 0x47, 0xce, 0xdb, 0x19, 0xf9, 0xf7, 0xa0, 0x3d, 0xa7, 0x1b,
+	0x57, 0x6e, 0x80, 0xe4, 0x3e, 0x92, 0x8d, 0x5a, 0x3e, 0x61, 0xa3, 0x3b, 0x16, 0x31, 0xac, 0x3f,
+	0x87, 0x8a, 0x9c, 0xec, 0xeb, 0x50, 0xb8, 0x70, 0x79, 0x34, 0xb0, 0xe1, 0xd5, 0xb3, 0x16, 0xb2,
+	0xf8, 0x6f, 0x41, 0xdd, 0x8d, 0xd8, 0x4b, 0xe7, 0x86, 0xd8, 0xe1, 0x63, 0x19, 0xdb, 0x3c, 0x45,
+	0xe6, 0x6b, 0xcf, 0xbf, 0xe6, 0x9a, 0x56, 0x1f, 0x1c, 0x42, 0x33, 0xd5, 0x1b, 0xa2, 0x0a, 0x14,
+	0x7a, 0xc3, 0x21, 0x7f, 0x7e, 0xe9, 0x43, 0x3c, 0x18, 0x3d, 0x53, 0x15, 0xfa, 0xd1, 0x1f, 0x8e,
+	0x27, 0xf4, 0x63, 0xe3, 0xf0, 0xfb, 0x06, 0xd4, 0xe2, 0x25, 0x18, 0xfa, 0x09, 0x34, 0x53, 0xed,
+	0x21, 0x92, 0xfd, 0x43, 0x5e, 0x8b, 0xd9, 0xbd, 0x97, 0x8f, 0x14, 0xc6, 0x7b, 0x01, 0xad, 0x74,
+	0x9f, 0x88, 0xee, 0xa5, 0x43, 0x3c, 0xc3, 0xed, 0xfe, 0x2d, 0x58, 0xc1, 0xee, 0x2b, 0xa8, 0xca,
+	0x75, 0x28, 0xda, 0xc9, 0xdf, 0xbc, 0x76, 0x77, 0xd7, 0xe0, 0xe2, 0xf0, 0x13, 0xa8, 0xc5, 0x7b,
+	0x4f, 0x94, 0xa4, 0x4a, 0xee, 0x4e, 0xbb, 0xda, 0x3a, 0x42, 0x9c, 0xef, 0x01, 0xac, 0x36, 0x8f,
+	0x48, 0xbb, 0x6d, 0xfd, 0xd9, 0xdd, 0xcb, 0xc1, 0x08, 0x16, 0x47, 0x50, 0x4f, 0x2c, 0x16, 0x51,
+	0x62, 0xf8, 0xca, 0x6c, 0x2a, 0xbb, 0xdd, 0x3c, 0xd4, 0x4a, 0x91, 0x78, 0x85, 0x83, 0x56, 0x4b,
+	0xcc, 0xf4, 0xa2, 0xa7, 0xab, 0xad, 0x23, 0xc4, 0xf9, 0x2f, 0xa0, 0x22, 0xd6, 0x37, 0x48, 0x6e,
+	0xdc, 0xd3, 0x1b, 0x9e, 0xee, 0x4e, 0x16, 0x2c, 0x4e, 0xf6, 0xa1, 0x9e, 0x18, 0x99, 0x63, 0xf9,
+	0xd7, 0xc7, 0xe8, 0xee, 0x6e, 0x02, 0x95, 0x1c, 0x5a, 0x1f, 0x2b, 0xe8, 0x18, 0x1a, 0xc9, 0x85,
+	0x05, 0x8a, 0x55, 0x5d, 0xdf, 0x62, 0x74, 0xb5, 0x24, 0x2e, 0xc3, 0x67, 0x04, 0x9b, 0xe9, 0x09,
+	0x2e, 0x8a, 0x83, 0x2b, 0x77, 0xf8, 0xec, 0xde, 0xbf, 0x05, 0x2b, 0x94, 0x7b, 0x06, 0x8d, 0xe4,
+	0xbe, 0x31, 0x96, 0x2b, 0x67, 0x37, 0xd9, 0xbd, 0x9b, 0x8b, 0x13, 0x8c, 0x7e, 0x0a, 0x5b, 0x39,
+	0x15, 0x16, 0xc9, 0x8e, 0xef, 0xf6, 0x37, 0xa1, 0xab, 0xff, 0x10, 0x89, 0xe0, 0xfe, 0x0d, 0xb4,
+	0xd7, 0x8a, 0x15, 0x7a, 0x67, 0xad, 0x12, 0xa5, 0x4b, 0x77, 0x77, 0xff, 0x76, 0x02, 0xc1, 0xf7,
+	0x4b, 0xfe, 0x37, 0x93, 0x6c, 0x84, 0x50, 0x22, 0x0f, 0x24, 0x93, 0xad, 0x14, 0x8c, 0x9f, 0x3b,
+	0x50, 0x1e, 0x2b, 0xd2, 0x74, 0xe2, 0x6c, 0xda, 0x74, 0x99, 0xc6, 0xa2, 0x7b, 0x37, 0x17, 0x27,
+	0x84, 0xf8, 0x1c, 0x60, 0xd5, 0xf9, 0xa3, 0x4c, 0x53, 0x1d, 0x67, 0x56, 0xce, 0x70, 0xf0, 0x19,
+	0x34, 0x87, 0xbe, 0xff, 0x7a, 0x19, 0xc8, 0xb3, 0x28, 0xdd, 0x95, 0xd0, 0x49, 0xa0, 0x9b, 0xe1,
+	0x87, 0x7a, 0xd0, 0x4c, 0x3d, 0xfd, 0xb9, 0x87, 0xe2, 0x82, 0x95, 0xd7, 0x24, 0x20, 0x83, 0x6b,
+	0x2e, 0xc0, 0x51, 0x9c, 0x12, 0xeb, 0x13, 0x46, 0xb7, 0x9b, 0x87, 0x8a, 0x6b, 0x4b, 0x5b, 0x34,
+	0x19, 0x17, 0x38, 0xe6, 0xd5, 0x4d, 0x8b, 0x9b, 0xec, 0x42, 0xb2, 0xaa, 0x3c, 0x56, 0xd0, 0x21,
+	0x34, 0x8e, 0x30, 0x1d, 0x30, 0x64, 0x6b, 0xb5, 0xd2, 0x25, 0xee, 0xc5, 0xba, 0xcd, 0x14, 0x10,
+	0x4d, 0x40, 0xcd, 0x8e, 0xc7, 0xe8, 0xff, 0xa4, 0x93, 0xf3, 0x47, 0xea, 0xee, 0x3b, 0xb7, 0xe2,
+	0xb9, 0x2e, 0x17, 0x65, 0xf6, 0xef, 0xe5, 0x67, 0xff, 0x1e, 0x00, 0x48, 0x0a, 0x4c, 0x4f, 0xca,
+	0x1c, 0x00, 0x00,
 }
@@ -104,8 +104,9 @@ func (b *BtcWallet) SignOutputRaw(tx *wire.MsgTx, signDesc *lnwallet.SignDescrip
 	}
 
 	amt := signDesc.Output.Value
-	sig, err := txscript.RawTxInWitnessSignature(tx, signDesc.SigHashes, 0,
-		amt, redeemScript, txscript.SigHashAll, privKey)
+	sig, err := txscript.RawTxInWitnessSignature(tx, signDesc.SigHashes,
+		signDesc.InputIndex, amt, redeemScript, txscript.SigHashAll,
+		privKey)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"container/list"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/btcsuite/fastsha256"
 	"github.com/davecgh/go-spew/spew"
@@ -25,21 +26,63 @@ var (
 	ErrChanClosing = fmt.Errorf("channel is being closed, operation disallowed")
 	ErrNoWindow    = fmt.Errorf("unable to sign new commitment, the current" +
 		" revocation window is exhausted")
+	ErrFeeTooLow = fmt.Errorf("fee rate is below the minimum fee rate " +
+		"negotiated for the channel")
+
+	// ErrLocalDataLoss is returned when the remote party reports having
+	// received revocations for commitment states we haven't yet created.
+	// This indicates that our local channel state is out of date, and
+	// broadcasting our current commitment would be a contract breach.
+	ErrLocalDataLoss = fmt.Errorf("remote party has a more recent view " +
+		"of our commitment chain, local channel state has been lost")
+
+	// ErrRemoteDataLoss is returned when the remote party reports a
+	// commitment height below that of a commitment they've already
+	// revoked. This indicates that the remote party has lost channel
+	// state.
+	ErrRemoteDataLoss = fmt.Errorf("remote commitment height is below " +
+		"a revoked state, remote channel state has been lost")
+
+	// ErrCannotSyncCommitChains is returned when the commitment heights
+	// reported by the remote party during channel reestablishment can't
+	// be reconciled with our local state by retransmitting a revocation.
+	ErrCannotSyncCommitChains = fmt.Errorf("unable to resync commitment " +
+		"chains with remote party")
+
+	// ErrMaxHTLCNumber is returned when adding an HTLC would exceed the
+	// maximum number of HTLC's the receiving party has agreed to accept
+	// within their commitment transaction.
+	ErrMaxHTLCNumber = fmt.Errorf("commitment transaction would exceed " +
+		"the max number of accepted HTLC's")
+
+	// ErrMaxPendingAmount is returned when adding an HTLC would exceed the
+	// maximum total value of outstanding HTLC's the receiving party has
+	// agreed to accept.
+	ErrMaxPendingAmount = fmt.Errorf("commitment transaction would " +
+		"exceed the max value of HTLC's in flight")
+
+	// ErrBelowHtlcMinimum is returned when the value of a proposed HTLC is
+	// below the smallest HTLC the receiving party has agreed to accept.
+	ErrBelowHtlcMinimum = fmt.Errorf("HTLC value is below the minimum " +
+		"accepted HTLC value")
 )
 
 const (
-	// MaxPendingPayments is the max number of pending HTLC's permitted on
-	// a channel.
-	// TODO(roasbeef): make not random value + enforce
-	//  * should be tuned to account for max tx "cost"
-	MaxPendingPayments = 100
-
 	// InitialRevocationWindow is the number of unrevoked commitment
 	// transactions allowed within the commitment chain. This value allows
 	// a greater degree of desynchronization by allowing either parties to
 	// extend the other's commitment chain non-interactively, and also
 	// serves as a flow control mechanism to a degree.
 	InitialRevocationWindow = 4
+
+	// coopCloseTxSize is the estimated size, in virtual bytes, of a fully
+	// signed cooperative closure transaction. The transaction spends the
+	// 2-of-2 multi-sig funding output, and creates at most two outputs.
+	//
+	// 4 (version) + 1 (num inputs) + 41 (funding input) + 1 (num outputs)
+	// + 2*43 (outputs) + 4 (lock time) + (2 (marker+flag) + 222 (multi-sig
+	// witness)) / 4
+	coopCloseTxSize = 4 + 1 + 41 + 1 + 2*43 + 4 + (2+222)/4
 )
 
 // channelState is an enum like type which represents the current state of a
@@ -146,7 +189,11 @@ type PaymentDescriptor struct {
 	// isForwarded denotes if an incoming HTLC has been forwarded to any
 	// possible upstream peers in the route.
 	isForwarded bool
-	settled     bool
+
+	// settled denotes if an add entry has already been removed from the
+	// log, either by a settle or a timeout. This prevents a single HTLC
+	// from being removed more than once.
+	settled bool
 }
 
 // commitment represents a commitment to a new state within an active channel.
@@ -356,6 +403,18 @@ type LightningChannel struct {
 	// commitment chain.
 	revocationWindow []*lnwire.CommitRevocation
 
+	// commitDiff is the latest commitment we've signed for the remote
+	// party which they're yet to acknowledge by revoking their prior
+	// commitment. The diff is retained on disk in order to retransmit
+	// the commitment, along with the updates it covers, in the case that
+	// it never reached the remote party.
+	commitDiff *channeldb.CommitDiff
+
+	// revocationRetransmitPending is true if, during channel
+	// reestablishment, the remote party indicated they'll retransmit the
+	// revocation for their prior commitment which we never received.
+	revocationRetransmitPending bool
+
 	// remoteCommitChain is the remote node's commitment chain. Any new
 	// commitments we initiate are added to the tip of this chain.
 	remoteCommitChain *commitmentChain
@@ -399,6 +458,18 @@ type LightningChannel struct {
 	// their version of the commitment transaction on-chain.
 	UnilateralCloseSignal chan struct{}
 
+	// ContractBreach is a channel that is used to communicate the data
+	// necessary to fully resolve the channel in the case that a contract
+	// breach arises. A contract breach occurs if the remote party
+	// broadcasts a previously revoked commitment transaction.
+	ContractBreach chan *BreachRetribution
+
+	// stateHintObfuscator is the value XOR'd against the state number
+	// encoded within each commitment transaction. It's derived from the
+	// commitment keys of both parties, so both sides arrive at the same
+	// value.
+	stateHintObfuscator [StateHintSize]byte
+
 	started  int32
 	shutdown int32
 
@@ -421,7 +492,6 @@ func NewLightningChannel(signer Signer, bio BlockChainIO,
 		bio:                   bio,
 		channelEvents:         events,
 		currentHeight:         state.NumUpdates,
-		remoteCommitChain:     newCommitmentChain(state.NumUpdates),
 		localCommitChain:      newCommitmentChain(state.NumUpdates),
 		channelState:          state,
 		revocationWindowEdge:  state.NumUpdates,
@@ -435,21 +505,38 @@ func NewLightningChannel(signer Signer, bio BlockChainIO,
 		FundingRedeemScript:   state.FundingRedeemScript,
 		ForceCloseSignal:      make(chan struct{}),
 		UnilateralCloseSignal: make(chan struct{}),
+		ContractBreach:        make(chan *BreachRetribution, 1),
+		quit:                  make(chan struct{}),
 	}
 
+	lc.stateHintObfuscator = deriveStateHintObfuscator(state.OurCommitKey,
+		state.TheirCommitKey)
+
 	// Initialize both of our chains the current un-revoked commitment for
-	// each side.
+	// each side. The height of the remote party's current commitment is
+	// derived from the number of revocations we've received from them, as
+	// it may differ from our own height if the last session ended in the
+	// middle of a state transition.
 	// TODO(roasbeef): add chnneldb.RevocationLogTail method, then init
 	// their commitment from that as we may be de-synced
-	initialCommitment := &commitment{
+	remoteHeight := remoteCommitHeight(state)
+	lc.remoteCommitChain = newCommitmentChain(remoteHeight)
+	localCommitment := &commitment{
 		height:            lc.currentHeight,
 		ourBalance:        state.OurBalance,
 		ourMessageIndex:   0,
 		theirBalance:      state.TheirBalance,
 		theirMessageIndex: 0,
 	}
-	lc.localCommitChain.addCommitment(initialCommitment)
-	lc.remoteCommitChain.addCommitment(initialCommitment)
+	remoteCommitment := &commitment{
+		height:            remoteHeight,
+		ourBalance:        state.OurBalance,
+		ourMessageIndex:   0,
+		theirBalance:      state.TheirBalance,
+		theirMessageIndex: 0,
+	}
+	lc.localCommitChain.addCommitment(localCommitment)
+	lc.remoteCommitChain.addCommitment(remoteCommitment)
 
 	// If we're restarting from a channel with history, then restore the
 	// update in-memory update logs to that of the prior state.
@@ -477,6 +564,13 @@ func NewLightningChannel(signer Signer, bio BlockChainIO,
 		InputIndex: 0,
 	}
 
+	// If the last session ended before the remote party acknowledged the
+	// latest commitment we signed for them, then re-create it so it can
+	// be retransmitted or revoked once the channel is reestablished.
+	if err := lc.restoreCommitDiff(); err != nil {
+		return nil, err
+	}
+
 	// Register for a notification to be dispatched if the funding outpoint
 	// has been spent. This indicates that either us or the remote party
 	// has broadcasted a commitment transaction on-chain.
@@ -486,30 +580,117 @@ func NewLightningChannel(signer Signer, bio BlockChainIO,
 		return nil, err
 	}
 
-	// TODO(roasbeef) move into the peer's htlcManager?
-	//  * if not, send the SpendDetail over the channel instead of just
-	//    closing it
-	go func() {
-		// If the daemon is shutting down, then this notification channel
-		// will be closed, so check the second read-value to avoid a false
-		// positive.
-		if _, ok := <-channelCloseNtfn.Spend; !ok {
+	lc.wg.Add(1)
+	go lc.closeObserver(channelCloseNtfn)
+
+	return lc, nil
+}
+
+// Stop gracefully shuts down any active goroutines spawned by the
+// LightningChannel during regular duties.
+func (lc *LightningChannel) Stop() {
+	if !atomic.CompareAndSwapInt32(&lc.shutdown, 0, 1) {
+		return
+	}
+
+	close(lc.quit)
+
+	lc.wg.Wait()
+}
+
+// closeObserver is a goroutine which watches the blockchain for any spends of
+// the multi-sig funding output. A spend from the multi-sig output may occur
+// under the following three scenarios: a cooperative close, a unilateral
+// close, and a uncooperative contract breaching close. In the case of the
+// last scenario a BreachRetribution struct is created and sent over the
+// ContractBreach channel notifying subscribers that the channel has been
+// breached.
+//
+// NOTE: This MUST be run as a goroutine.
+func (lc *LightningChannel) closeObserver(channelCloseNtfn *chainntnfs.SpendEvent) {
+	defer lc.wg.Done()
+
+	walletLog.Infof("Close observer for ChannelPoint(%v) active",
+		lc.channelState.ChanID)
+
+	var (
+		commitSpend *chainntnfs.SpendDetail
+		ok          bool
+	)
+
+	select {
+	// If the daemon is shutting down, then this notification channel will
+	// be closed, so check the second read-value to avoid a false positive.
+	case commitSpend, ok = <-channelCloseNtfn.Spend:
+		if !ok {
 			return
 		}
 
-		// If the channel's doesn't already indicate that a commitment
-		// transaction has been broadcast on-chain, then this means the
-		// remote party broadcasted their commitment transaction.
-		// TODO(roasbeef): wait for a conf?
-		lc.Lock()
-		if lc.status != channelDispute {
-			close(lc.UnilateralCloseSignal)
-			lc.status = channelDispute
-		}
-		lc.Unlock()
-	}()
+	// The channel has been stopped, so there's no need to watch for a
+	// spend any longer.
+	case <-lc.quit:
+		return
+	}
 
-	return lc, nil
+	lc.Lock()
+	defer lc.Unlock()
+
+	// If the channel's status already indicates that a commitment
+	// transaction has been broadcast on-chain (by us), or that the channel
+	// has been cooperatively closed, then there's nothing left to do.
+	if lc.status == channelDispute || lc.status == channelClosed ||
+		lc.status == channelClosing {
+		return
+	}
+
+	// Otherwise, the remote party might have broadcast a prior revoked
+	// state. Decode the state hint encoded within the spending
+	// transaction in order to determine which state was broadcast.
+	commitTxBroadcast := commitSpend.SpendingTx
+	broadcastStateNum := GetStateNumHint(commitTxBroadcast,
+		lc.stateHintObfuscator)
+
+	// If we're unable to recover the revocation pre-image for the
+	// broadcast state, then the remote party broadcast a non-revoked
+	// state, so this is a regular unilateral close.
+	remoteElkrem := lc.channelState.RemoteElkrem
+	if _, err := remoteElkrem.AtIndex(broadcastStateNum); err != nil {
+		walletLog.Warnf("Unprompted commitment broadcast for "+
+			"ChannelPoint(%v) ", lc.channelState.ChanID)
+
+		close(lc.UnilateralCloseSignal)
+		lc.status = channelDispute
+		return
+	}
+
+	// Otherwise, we have the revocation pre-image for the broadcast state,
+	// meaning the remote party has attempted to cheat us by broadcasting
+	// a revoked commitment transaction. Assemble all the information
+	// required to sweep the entirety of the channel's funds.
+	retribution, err := newBreachRetribution(lc.channelState,
+		broadcastStateNum, commitTxBroadcast)
+	if err != nil {
+		// If none of the outputs of the broadcast transaction match
+		// the revoked state, then the spend was a cooperative close
+		// which didn't carry a valid state hint.
+		walletLog.Errorf("unable to create breach retribution for "+
+			"ChannelPoint(%v): %v", lc.channelState.ChanID, err)
+
+		close(lc.UnilateralCloseSignal)
+		lc.status = channelDispute
+		return
+	}
+
+	walletLog.Warnf("Remote peer has breached the channel contract for "+
+		"ChannelPoint(%v). Revoked state #%v was broadcast!!!",
+		lc.channelState.ChanID, broadcastStateNum)
+
+	lc.status = channelDispute
+
+	// Finally, send the retribution struct over the contract breach channel
+	// to allow the observer to use the breach retribution to sweep ALL
+	// funds.
+	lc.ContractBreach <- retribution
 }
 
 // restoreStateLogs runs through the current locked-in HTLC's from the point of
@@ -560,6 +741,118 @@ func (lc *LightningChannel) restoreStateLogs() error {
 	return nil
 }
 
+// restoreCommitDiff re-creates the remote party's pending commitment from the
+// CommitDiff recorded when it was signed, if they had yet to revoke their
+// prior commitment when the last session ended. Any updates covered by the
+// commitment which aren't reflected within our local commitment are added
+// back to the update logs, as both commitment chains build upon them.
+func (lc *LightningChannel) restoreCommitDiff() error {
+	// A channel which hasn't yet been written to disk won't have a diff
+	// recorded either.
+	diff, err := lc.channelState.FetchCommitDiff()
+	switch {
+	case err == channeldb.ErrNoCommitDiff:
+		return nil
+	case err == channeldb.ErrNoActiveChannels:
+		return nil
+	case err != nil:
+		return err
+	}
+
+	// If the remote party already revoked their prior commitment, then
+	// the diff has been acknowledged and there's nothing to restore. We're
+	// only able to re-create a commitment which directly extends their
+	// current one.
+	// TODO(roasbeef): retain a diff for each unacked commitment
+	remoteHeight := lc.remoteCommitChain.tail().height
+	if diff.Height <= remoteHeight {
+		return nil
+	}
+	if diff.Height != remoteHeight+1 {
+		walletLog.Warnf("ChannelPoint(%v): unable to restore pending "+
+			"remote commitment at height %v, remote chain at "+
+			"height %v", lc.channelState.ChanID, diff.Height,
+			remoteHeight)
+		return nil
+	}
+
+	// Our outgoing HTLC's which have been locked into our local
+	// commitment were restored from disk, so any add matching one of them
+	// is already present within our update log.
+	numRestored := lc.ourLogCounter
+	matchedAdds := make(map[uint32]struct{})
+	isRestoredAdd := func(htlc *lnwire.HTLCAddRequest) bool {
+		for e := lc.ourUpdateLog.Front(); e != nil; e = e.Next() {
+			pd := e.Value.(*PaymentDescriptor)
+			if pd.Index >= numRestored {
+				break
+			}
+			if _, ok := matchedAdds[pd.Index]; ok {
+				continue
+			}
+
+			if pd.RHash == htlc.RedemptionHashes[0] &&
+				pd.Amount == btcutil.Amount(htlc.Amount) &&
+				pd.Timeout == htlc.Expiry {
+
+				matchedAdds[pd.Index] = struct{}{}
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, msg := range diff.Updates {
+		switch update := msg.(type) {
+		case *lnwire.HTLCAddRequest:
+			if isRestoredAdd(update) {
+				continue
+			}
+
+			pd := &PaymentDescriptor{
+				EntryType: Add,
+				RHash:     PaymentHash(update.RedemptionHashes[0]),
+				Timeout:   update.Expiry,
+				Amount:    btcutil.Amount(update.Amount),
+				Index:     lc.ourLogCounter,
+			}
+			lc.ourLogIndex[pd.Index] = lc.ourUpdateLog.PushBack(pd)
+			lc.ourLogCounter++
+
+		// If the HTLC being settled or cancelled is no longer within
+		// our local commitment, then its removal is already reflected
+		// within our restored state.
+		case *lnwire.HTLCSettleRequest:
+			lc.SettleHTLC(update.RedemptionProofs[0],
+				uint32(update.HTLCKey))
+
+		case *lnwire.CancelHTLC:
+			lc.TimeoutHTLC(uint32(update.HTLCKey))
+		}
+	}
+
+	// With the updates restored, re-create their pending commitment using
+	// the same revocation key+hash it was originally signed with.
+	pendingCommit, err := lc.fetchCommitmentView(true, lc.ourLogCounter,
+		lc.theirLogCounter, diff.RevocationKey, diff.RevocationHash)
+	if err != nil {
+		return err
+	}
+	lc.remoteCommitChain.addCommitment(pendingCommit)
+	lc.usedRevocations = append(lc.usedRevocations, &lnwire.CommitRevocation{
+		ChannelPoint:       lc.channelState.ChanID,
+		NextRevocationKey:  diff.RevocationKey,
+		NextRevocationHash: diff.RevocationHash,
+	})
+	lc.commitDiff = diff
+
+	walletLog.Debugf("ChannelPoint(%v): restored pending remote "+
+		"commitment at height %v", lc.channelState.ChanID, diff.Height)
+
+	return nil
+}
+
 type htlcView struct {
 	ourUpdates   []*PaymentDescriptor
 	theirUpdates []*PaymentDescriptor
@@ -662,6 +955,16 @@ func (lc *LightningChannel) fetchCommitmentView(remoteChain bool,
 	if err != nil {
 		return nil, err
 	}
+
+	// Encode the state number of this new commitment within the
+	// transaction itself. In the case of an uncooperative broadcast, this
+	// hint allows us to quickly locate the prior state required to punish
+	// the remote party.
+	err = SetStateNumHint(commitTx, nextHeight, lc.stateHintObfuscator)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, htlc := range filteredHTLCView.ourUpdates {
 		if err := lc.addHTLC(commitTx, ourCommitTx, htlc,
 			revocationHash, delay, false); err != nil {
@@ -908,6 +1211,39 @@ func (lc *LightningChannel) SignNextCommitment() ([]byte, uint32, error) {
 	return sig, lc.theirLogCounter, nil
 }
 
+// StoreCommitDiff persists the commitment at the tip of the remote party's
+// commitment chain, along with the message carrying our signature for it, and
+// the update messages sent to the remote party since our prior signature. This
+// method should be called directly after SignNextCommitment, before the
+// signature is sent. If the signature never reaches the remote party, then
+// both it and the updates are retransmitted during channel reestablishment.
+func (lc *LightningChannel) StoreCommitDiff(commitSig *lnwire.CommitSignature,
+	updates []lnwire.Message) error {
+
+	if len(lc.usedRevocations) == 0 {
+		return fmt.Errorf("no unacked remote commitment to store")
+	}
+
+	// The revocation key+hash used within the commitment at the tip of
+	// their chain are those of the latest revocation we've used.
+	// TODO(roasbeef): this overwrites the diff of any prior unacked
+	// commitment
+	lastRevocation := lc.usedRevocations[len(lc.usedRevocations)-1]
+	diff := &channeldb.CommitDiff{
+		Height:         lc.remoteCommitChain.tip().height,
+		RevocationKey:  lastRevocation.NextRevocationKey,
+		RevocationHash: lastRevocation.NextRevocationHash,
+		CommitSig:      commitSig,
+		Updates:        updates,
+	}
+	if err := lc.channelState.PutCommitDiff(diff); err != nil {
+		return err
+	}
+	lc.commitDiff = diff
+
+	return nil
+}
+
 // ReceiveNewCommitment processs a signature for a new commitment state sent by
 // the remote party. This method will should be called in response to the
 // remote party initiating a new change, or when the remote party sends a
@@ -1062,10 +1398,30 @@ func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.CommitRevocation) (
 	// The revocation has a nil (zero) pre-image, then this should simply be
 	// added to the end of the revocation window for the remote node.
 	if bytes.Equal(zeroHash[:], revMsg.Revocation[:]) {
+		// Upon reconnection, the remote node re-extends our window
+		// starting from its current commitment, so the window may
+		// include the revocation hash of a pending commitment we've
+		// already signed. Such an update is ignored.
+		for _, usedRevocation := range lc.usedRevocations {
+			if usedRevocation.NextRevocationHash == revMsg.NextRevocationHash {
+				return nil, nil
+			}
+		}
+
 		lc.revocationWindow = append(lc.revocationWindow, revMsg)
 		return nil, nil
 	}
 
+	// If the remote party indicated they'll retransmit the revocation we
+	// never received, then this is that revocation. If their pending
+	// commitment was lost along with the prior session, then it's handled
+	// separately.
+	retransmitted := lc.revocationRetransmitPending
+	lc.revocationRetransmitPending = false
+	if retransmitted && len(lc.usedRevocations) == 0 {
+		return nil, lc.receiveRetransmittedRevocation(revMsg)
+	}
+
 	ourCommitKey := lc.channelState.OurCommitKey
 	currentRevocationKey := lc.channelState.TheirCurrentRevocation
 	pendingRevocation := wire.ShaHash(revMsg.Revocation)
@@ -1098,13 +1454,18 @@ func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.CommitRevocation) (
 
 	// Advance the head of the revocation queue now that this revocation has
 	// been verified. Additionally, extend the end of our unused revocation
-	// queue with the newly extended revocation window update.
+	// queue with the newly extended revocation window update. A
+	// retransmitted revocation extends the window with the revocation
+	// key+hash of their pending commitment, which is already in use, so
+	// it isn't added to the window.
 	nextRevocation := lc.usedRevocations[0]
 	lc.channelState.TheirCurrentRevocation = nextRevocation.NextRevocationKey
 	lc.channelState.TheirCurrentRevocationHash = nextRevocation.NextRevocationHash
 	lc.usedRevocations[0] = nil // Prevent GC leak.
 	lc.usedRevocations = lc.usedRevocations[1:]
-	lc.revocationWindow = append(lc.revocationWindow, revMsg)
+	if !retransmitted {
+		lc.revocationWindow = append(lc.revocationWindow, revMsg)
+	}
 
 	walletLog.Tracef("ChannelPoint(%v): remote party accepted state transition, "+
 		"revoked height %v, now at %v", lc.channelState.ChanID,
@@ -1128,6 +1489,17 @@ func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.CommitRevocation) (
 	// chain, we can advance their chain by a single commitment.
 	lc.remoteCommitChain.advanceTail()
 
+	// If the commitment recorded within our commit diff is now their
+	// current commitment, then it no longer needs to be retransmitted.
+	if lc.commitDiff != nil &&
+		lc.commitDiff.Height <= lc.remoteCommitChain.tail().height {
+
+		if err := lc.channelState.DeleteCommitDiff(); err != nil {
+			return nil, err
+		}
+		lc.commitDiff = nil
+	}
+
 	remoteChainTail := lc.remoteCommitChain.tail().height
 	localChainTail := lc.localCommitChain.tail().height
 
@@ -1241,82 +1613,329 @@ func (lc *LightningChannel) ExtendRevocationWindow() (*lnwire.CommitRevocation,
 	return revMsg, nil
 }
 
+// remoteCommitHeight returns the height of the remote party's current
+// commitment transaction according to the passed channel state. As we hold
+// the revocation pre-images for all the remote party's prior states, this is
+// one beyond the index of the last revocation received.
+func remoteCommitHeight(state *channeldb.OpenChannel) uint64 {
+	// The elkrem receiver is unable to return the first pre-image if no
+	// revocations have been received yet, meaning the remote party is
+	// still at their initial commitment.
+	if _, err := state.RemoteElkrem.AtIndex(0); err != nil {
+		return 0
+	}
+
+	return state.RemoteElkrem.UpTo() + 1
+}
+
+// ChanSyncMsg returns the ChannelReestablish message which should be sent to
+// the remote party upon (re)connection, before any other commitment updates.
+// The message encodes the height of our current commitment, along with the
+// height of the remote party's commitment which we consider current.
+func (lc *LightningChannel) ChanSyncMsg() *lnwire.ChannelReestablish {
+	return &lnwire.ChannelReestablish{
+		ChannelPoint:       lc.channelState.ChanID,
+		LocalCommitHeight:  lc.currentHeight,
+		RemoteCommitHeight: lc.remoteCommitChain.tail().height,
+	}
+}
+
+// ProcessChanSyncMsg processes a ChannelReestablish message sent by the remote
+// party, comparing the heights within the message against our local view of
+// both commitment chains. The returned slice contains the messages to be
+// retransmitted to the remote party: the revocation for our prior commitment
+// if they never received it, followed by our latest signed commitment along
+// with the updates it covers if that never reached them. If the heights can't
+// be reconciled, then an error is returned indicating which side has lost
+// state. In that case, neither side should attempt to update the channel any
+// further.
+func (lc *LightningChannel) ProcessChanSyncMsg(msg *lnwire.ChannelReestablish) ([]lnwire.Message, error) {
+	localHeight := lc.currentHeight
+	remoteHeight := lc.remoteCommitChain.tail().height
+
+	walletLog.Debugf("ChannelPoint(%v): processing chan sync, "+
+		"local_height=%v, remote_height=%v, their_local_height=%v, "+
+		"their_remote_height=%v", lc.channelState.ChanID, localHeight,
+		remoteHeight, msg.LocalCommitHeight, msg.RemoteCommitHeight)
+
+	var updates []lnwire.Message
+
+	// First, we'll check the remote party's view of our commitment chain.
+	// If they're a single state behind, then they never received the
+	// revocation for our prior commitment, so we'll retransmit it.
+	switch {
+	case msg.RemoteCommitHeight == localHeight:
+
+	case msg.RemoteCommitHeight+1 == localHeight:
+		revMsg, err := lc.revocationForHeight(localHeight - 1)
+		if err != nil {
+			return nil, err
+		}
+
+		walletLog.Infof("ChannelPoint(%v): retransmitting revocation "+
+			"for height %v", lc.channelState.ChanID, localHeight-1)
+
+		updates = append(updates, revMsg)
+
+	// If they've received revocations for states we haven't yet reached,
+	// then our local state is out of date.
+	case msg.RemoteCommitHeight > localHeight:
+		return nil, ErrLocalDataLoss
+
+	default:
+		return nil, ErrCannotSyncCommitChains
+	}
+
+	// Next, we'll check their commitment height against our view of
+	// their chain.
+	switch {
+	// If they haven't received the latest commitment we signed for them,
+	// then we'll retransmit the updates it covers followed by our
+	// signature. The commitment directly extends their current one, so
+	// they're able to process it as usual and respond with a revocation.
+	case msg.LocalCommitHeight == remoteHeight:
+		if lc.commitDiff != nil {
+			walletLog.Infof("ChannelPoint(%v): retransmitting %v "+
+				"updates and signature for remote height %v",
+				lc.channelState.ChanID,
+				len(lc.commitDiff.Updates), lc.commitDiff.Height)
+
+			updates = append(updates, lc.commitDiff.Updates...)
+			updates = append(updates, lc.commitDiff.CommitSig)
+		}
+
+	// If they're a single state ahead of us, then we never received their
+	// last revocation, which they'll retransmit in response to our own
+	// ChannelReestablish message.
+	case msg.LocalCommitHeight == remoteHeight+1:
+		lc.revocationRetransmitPending = true
+
+	// If their current height is below a state they've already revoked,
+	// then they've lost state.
+	case msg.LocalCommitHeight < remoteHeight:
+		return nil, ErrRemoteDataLoss
+
+	default:
+		return nil, ErrCannotSyncCommitChains
+	}
+
+	return updates, nil
+}
+
+// revocationForHeight re-creates the revocation message for our commitment
+// at the specified height, to be retransmitted to the remote party during
+// channel reestablishment. Unlike a regular revocation, the next revocation
+// key+hash included are those of our commitment at the following height (our
+// current commitment), as the remote party will have lost the in-memory
+// state which tracked them.
+func (lc *LightningChannel) revocationForHeight(height uint64) (*lnwire.CommitRevocation, error) {
+	theirCommitKey := lc.channelState.TheirCommitKey
+
+	revocation, err := lc.channelState.LocalElkrem.AtIndex(height)
+	if err != nil {
+		return nil, err
+	}
+	nextRevocation, err := lc.channelState.LocalElkrem.AtIndex(height + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	revMsg := &lnwire.CommitRevocation{
+		ChannelPoint: lc.channelState.ChanID,
+		NextRevocationKey: DeriveRevocationPubkey(theirCommitKey,
+			nextRevocation[:]),
+		NextRevocationHash: fastsha256.Sum256(nextRevocation[:]),
+	}
+	copy(revMsg.Revocation[:], revocation[:])
+
+	return revMsg, nil
+}
+
+// receiveRetransmittedRevocation processes a revocation retransmitted by the
+// remote party during channel reestablishment, in the case that we hold no
+// record of the commitment it acknowledges. The revocation is for the
+// commitment we consider their current one, and the next revocation key+hash
+// included are those of their new current commitment. Once verified, the
+// revocation is recorded exactly as a regular revocation would be, advancing
+// our view of the remote commitment chain by a single state.
+func (lc *LightningChannel) receiveRetransmittedRevocation(revMsg *lnwire.CommitRevocation) error {
+	ourCommitKey := lc.channelState.OurCommitKey
+	currentRevocationKey := lc.channelState.TheirCurrentRevocation
+	pendingRevocation := wire.ShaHash(revMsg.Revocation)
+
+	// Verify that the pre-image matches the revocation key and hash used
+	// within their current commitment before adding it to the elkrem
+	// receiver.
+	revocationPub := DeriveRevocationPubkey(ourCommitKey, pendingRevocation[:])
+	if !revocationPub.IsEqual(currentRevocationKey) {
+		return fmt.Errorf("revocation key mismatch")
+	}
+	if !bytes.Equal(lc.channelState.TheirCurrentRevocationHash[:], zeroHash[:]) {
+		revokeHash := fastsha256.Sum256(pendingRevocation[:])
+		if !bytes.Equal(lc.channelState.TheirCurrentRevocationHash[:], revokeHash[:]) {
+			return fmt.Errorf("revocation hash mismatch")
+		}
+	}
+	if err := lc.channelState.RemoteElkrem.AddNext(&pendingRevocation); err != nil {
+		return err
+	}
+
+	lc.channelState.TheirCurrentRevocation = revMsg.NextRevocationKey
+	lc.channelState.TheirCurrentRevocationHash = revMsg.NextRevocationHash
+
+	walletLog.Infof("ChannelPoint(%v): received retransmitted revocation "+
+		"for height %v", lc.channelState.ChanID,
+		lc.remoteCommitChain.tail().height)
+
+	// Record the revoked state within the revocation log. As the
+	// commitment itself was lost along with the prior session, the delta
+	// is built from our restored view of the remote chain.
+	// TODO(roasbeef): persist the remote commitment to record the exact
+	// delta
+	tail := lc.remoteCommitChain.tail()
+	delta, err := tail.toChannelDelta()
+	if err != nil {
+		return err
+	}
+	if err := lc.channelState.AppendToRevocationLog(delta); err != nil {
+		return err
+	}
+
+	// With the revocation recorded, their current commitment is now the
+	// one at the next height.
+	tail.height++
+
+	return nil
+}
+
 // AddHTLC adds an HTLC to the state machine's local update log. This method
-// should be called when preparing to send an outgoing HTLC.
+// should be called when preparing to send an outgoing HTLC. If the HTLC would
+// violate any of the limits the remote party has placed on the HTLC's they'll
+// accept, then one of ErrBelowHtlcMinimum, ErrMaxHTLCNumber, or
+// ErrMaxPendingAmount is returned, and the HTLC isn't added.
 // TODO(roasbeef): check for duplicates below? edge case during restart w/ HTLC
 // persistence
-func (lc *LightningChannel) AddHTLC(htlc *lnwire.HTLCAddRequest) uint32 {
+func (lc *LightningChannel) AddHTLC(htlc *lnwire.HTLCAddRequest) (uint32, error) {
+	amt := btcutil.Amount(htlc.Amount)
+	err := validateHTLCLimits(lc.ourUpdateLog, amt,
+		lc.channelState.RemoteMaxAcceptedHTLCs,
+		lc.channelState.RemoteMaxValueInFlight,
+		lc.channelState.RemoteHtlcMinimum)
+	if err != nil {
+		return 0, err
+	}
+
 	pd := &PaymentDescriptor{
 		EntryType: Add,
 		RHash:     PaymentHash(htlc.RedemptionHashes[0]),
 		Timeout:   htlc.Expiry,
-		Amount:    btcutil.Amount(htlc.Amount),
+		Amount:    amt,
 		Index:     lc.ourLogCounter,
 	}
 
 	lc.ourLogIndex[pd.Index] = lc.ourUpdateLog.PushBack(pd)
 	lc.ourLogCounter++
 
-	return pd.Index
+	return pd.Index, nil
 }
 
 // ReceiveHTLC adds an HTLC to the state machine's remote update log. This
 // method should be called in response to receiving a new HTLC from the remote
-// party.
-func (lc *LightningChannel) ReceiveHTLC(htlc *lnwire.HTLCAddRequest) uint32 {
+// party. If the HTLC violates any of the limits we've placed on the HTLC's
+// we'll accept, then one of ErrBelowHtlcMinimum, ErrMaxHTLCNumber, or
+// ErrMaxPendingAmount is returned, and the HTLC isn't added.
+func (lc *LightningChannel) ReceiveHTLC(htlc *lnwire.HTLCAddRequest) (uint32, error) {
+	amt := btcutil.Amount(htlc.Amount)
+	err := validateHTLCLimits(lc.theirUpdateLog, amt,
+		lc.channelState.LocalMaxAcceptedHTLCs,
+		lc.channelState.LocalMaxValueInFlight,
+		lc.channelState.LocalHtlcMinimum)
+	if err != nil {
+		return 0, err
+	}
+
 	pd := &PaymentDescriptor{
 		EntryType: Add,
 		RHash:     PaymentHash(htlc.RedemptionHashes[0]),
 		Timeout:   htlc.Expiry,
-		Amount:    btcutil.Amount(htlc.Amount),
+		Amount:    amt,
 		Index:     lc.theirLogCounter,
 	}
 
 	lc.theirLogIndex[pd.Index] = lc.theirUpdateLog.PushBack(pd)
 	lc.theirLogCounter++
 
-	return pd.Index
+	return pd.Index, nil
 }
 
-// SettleHTLC attempst to settle an existing outstanding received HTLC. The
-// remote log index of the HTLC settled is returned in order to facilitate
-// creating the corresponding wire message. In the case the supplied pre-image
-// is invalid, an error is returned.
-func (lc *LightningChannel) SettleHTLC(preimage [32]byte) (uint32, error) {
-	var targetHTLC *list.Element
+// validateHTLCLimits ensures that adding an HTLC of the passed amount to the
+// target update log wouldn't violate the limits placed on the HTLC's accepted
+// by the receiving party. Any HTLC's within the log which are in the process
+// of being settled or timed out still count towards the limits, as they
+// remain within the commitment transaction until the removal is locked in.
+func validateHTLCLimits(updateLog *list.List, amt btcutil.Amount,
+	maxAcceptedHTLCs uint16, maxValueInFlight,
+	htlcMinimum btcutil.Amount) error {
 
-	// TODO(roasbeef): optimize
-	paymentHash := fastsha256.Sum256(preimage[:])
-	for e := lc.theirUpdateLog.Front(); e != nil; e = e.Next() {
+	if amt < htlcMinimum {
+		return ErrBelowHtlcMinimum
+	}
+
+	var (
+		numHTLCs      int
+		valueInFlight btcutil.Amount
+	)
+	for e := updateLog.Front(); e != nil; e = e.Next() {
 		htlc := e.Value.(*PaymentDescriptor)
 		if htlc.EntryType != Add {
 			continue
 		}
 
-		if !htlc.settled && bytes.Equal(htlc.RHash[:], paymentHash[:]) {
-			htlc.settled = true
-			targetHTLC = e
-			break
-		}
+		numHTLCs++
+		valueInFlight += htlc.Amount
 	}
-	if targetHTLC == nil {
-		return 0, fmt.Errorf("invalid payment hash")
+
+	if numHTLCs+1 > int(maxAcceptedHTLCs) {
+		return ErrMaxHTLCNumber
+	}
+	if valueInFlight+amt > maxValueInFlight {
+		return ErrMaxPendingAmount
+	}
+
+	return nil
+}
+
+// SettleHTLC attempst to settle an existing outstanding received HTLC
+// indexed by an index into the remote log. In the case the supplied pre-image
+// is invalid, or the HTLC has already been removed, an error is returned.
+func (lc *LightningChannel) SettleHTLC(preimage [32]byte, logIndex uint32) error {
+	paymentHash := fastsha256.Sum256(preimage[:])
+	addEntry, ok := lc.theirLogIndex[logIndex]
+	if !ok {
+		return fmt.Errorf("non existant log entry")
 	}
 
-	parentPd := targetHTLC.Value.(*PaymentDescriptor)
+	htlc := addEntry.Value.(*PaymentDescriptor)
+	if htlc.settled {
+		return fmt.Errorf("htlc %v has already been removed", logIndex)
+	}
+	if !bytes.Equal(htlc.RHash[:], paymentHash[:]) {
+		return fmt.Errorf("invalid payment hash")
+	}
+	htlc.settled = true
 
 	pd := &PaymentDescriptor{
-		Amount:      parentPd.Amount,
+		Amount:      htlc.Amount,
 		RPreimage:   preimage,
 		Index:       lc.ourLogCounter,
-		ParentIndex: parentPd.Index,
+		ParentIndex: htlc.Index,
 		EntryType:   Settle,
 	}
 
 	lc.ourUpdateLog.PushBack(pd)
 	lc.ourLogCounter++
 
-	return targetHTLC.Value.(*PaymentDescriptor).Index, nil
+	return nil
 }
 
 // ReceiveHTLCSettle attempts to settle an existing outgoing HTLC indexed by an
@@ -1334,6 +1953,7 @@ func (lc *LightningChannel) ReceiveHTLCSettle(preimage [32]byte, logIndex uint32
 	if !bytes.Equal(htlc.RHash[:], paymentHash[:]) {
 		return fmt.Errorf("invalid payment hash")
 	}
+	htlc.settled = true
 
 	pd := &PaymentDescriptor{
 		Amount:      htlc.Amount,
@@ -1349,11 +1969,105 @@ func (lc *LightningChannel) ReceiveHTLCSettle(preimage [32]byte, logIndex uint32
 	return nil
 }
 
-// TimeoutHTLC...
-func (lc *LightningChannel) TimeoutHTLC() error {
+// TimeoutHTLC attempts to time out (cancel) an existing outstanding received
+// HTLC indexed by an index into the remote log. The HTLC's value is returned
+// to the remote party once the timeout has been committed in both chains. If
+// the specified index doesn't exist within the log, or the HTLC has already
+// been removed, an error is returned.
+func (lc *LightningChannel) TimeoutHTLC(logIndex uint32) error {
+	addEntry, ok := lc.theirLogIndex[logIndex]
+	if !ok {
+		return fmt.Errorf("non existant log entry")
+	}
+
+	htlc := addEntry.Value.(*PaymentDescriptor)
+	if htlc.settled {
+		return fmt.Errorf("htlc %v has already been removed", logIndex)
+	}
+	htlc.settled = true
+
+	pd := &PaymentDescriptor{
+		Amount:      htlc.Amount,
+		RHash:       htlc.RHash,
+		Index:       lc.ourLogCounter,
+		ParentIndex: htlc.Index,
+		EntryType:   Timeout,
+	}
+
+	lc.ourUpdateLog.PushBack(pd)
+	lc.ourLogCounter++
+
 	return nil
 }
 
+// ReceiveTimeoutHTLC attempts to time out (cancel) an existing outgoing HTLC
+// indexed by an index into the local log. This method should be called in
+// response to the remote party cancelling one of our outgoing HTLC's. If the
+// specified index doesn't exist within the log, an error is returned.
+func (lc *LightningChannel) ReceiveTimeoutHTLC(logIndex uint32) error {
+	addEntry, ok := lc.ourLogIndex[logIndex]
+	if !ok {
+		return fmt.Errorf("non existant log entry")
+	}
+
+	htlc := addEntry.Value.(*PaymentDescriptor)
+	if htlc.settled {
+		return fmt.Errorf("htlc %v has already been removed", logIndex)
+	}
+	htlc.settled = true
+
+	pd := &PaymentDescriptor{
+		Amount:      htlc.Amount,
+		RHash:       htlc.RHash,
+		ParentIndex: htlc.Index,
+		Index:       lc.theirLogCounter,
+		EntryType:   Timeout,
+	}
+
+	lc.theirUpdateLog.PushBack(pd)
+	lc.theirLogCounter++
+
+	return nil
+}
+
+// ExpiringHTLCs returns all the active HTLC's which have been locked-in within
+// both commitment chains, and expire at or before the passed height. If
+// incoming is true, then HTLC's added by the remote party are examined,
+// otherwise our outgoing HTLC's are examined. HTLC's which are already in the
+// process of being settled or timed out are skipped.
+func (lc *LightningChannel) ExpiringHTLCs(height uint32,
+	incoming bool) []*PaymentDescriptor {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	updateLog := lc.ourUpdateLog
+	if incoming {
+		updateLog = lc.theirUpdateLog
+	}
+
+	var expiring []*PaymentDescriptor
+	for e := updateLog.Front(); e != nil; e = e.Next() {
+		htlc := e.Value.(*PaymentDescriptor)
+		if htlc.EntryType != Add || htlc.settled {
+			continue
+		}
+
+		// HTLC's which haven't yet been committed within both chains
+		// can't be removed yet.
+		if htlc.addCommitHeightRemote == 0 ||
+			htlc.addCommitHeightLocal == 0 {
+			continue
+		}
+
+		if htlc.Timeout <= height {
+			expiring = append(expiring, htlc)
+		}
+	}
+
+	return expiring
+}
+
 // ChannelPoint returns the outpoint of the original funding transaction which
 // created this active channel. This outpoint is used throughout various
 // sub-systems to uniquely identify an open channel.
@@ -1361,6 +2075,13 @@ func (lc *LightningChannel) ChannelPoint() *wire.OutPoint {
 	return lc.channelState.ChanID
 }
 
+// MinFeePerKb returns the minimum fee rate, in satoshis/KB, negotiated for the
+// channel during the funding workflow. The closure transaction of the channel
+// must pay at least this fee rate.
+func (lc *LightningChannel) MinFeePerKb() btcutil.Amount {
+	return lc.channelState.MinFeePerKb
+}
+
 // addHTLC adds a new HTLC to the passed commitment transaction. One of four
 // full scripts will be generated for the HTLC output depending on if the HTLC
 // is incoming and if it's being applied to our commitment transaction or that
@@ -1423,6 +2144,335 @@ func (lc *LightningChannel) addHTLC(commitTx *wire.MsgTx, ourCommit bool,
 	return nil
 }
 
+// BreachRetribution contains all the data necessary to bring a channel
+// counterparty to justice claiming ALL lingering funds within the channel in
+// the scenario that they broadcast a revoked commitment transaction. A
+// BreachRetribution is created by the closeObserver if it detects an
+// uncooperative close of the channel which uses a revoked commitment
+// transaction. The BreachRetribution is then sent over the ContractBreach
+// channel in order to allow the subscriber of the channel to dispatch justice.
+type BreachRetribution struct {
+	// BreachTransaction is the transaction which breached the channel
+	// contract by spending from the funding multi-sig with a revoked
+	// commitment transaction.
+	BreachTransaction *wire.MsgTx
+
+	// RevokedStateNum is the revoked state number which was broadcast.
+	RevokedStateNum uint64
+
+	// RevocationPreimage is the pre-image of the revocation hash which
+	// was used to construct the revoked commitment transaction.
+	RevocationPreimage wire.ShaHash
+
+	// LocalCommitKey is our commitment public key within the channel. The
+	// private key corresponding to this key is required to sweep the
+	// outputs of the breach transaction.
+	LocalCommitKey *btcec.PublicKey
+
+	// LocalOutpoint is the outpoint of the output paying to us (the
+	// non-delayed output) within the breach transaction. If our balance
+	// within the revoked state was zero, then this output won't be
+	// present.
+	LocalOutpoint wire.OutPoint
+
+	// LocalAmount is the value of the output paying to us within the
+	// breach transaction.
+	LocalAmount btcutil.Amount
+
+	// LocalScript is the p2wkh public key script of the output paying to
+	// us within the breach transaction.
+	LocalScript []byte
+
+	// RemoteOutpoint is the outpoint of the output paying to the remote
+	// party within the breach transaction. This output is encumbered by a
+	// relative delay, however we can sweep it immediately using the
+	// revocation clause.
+	RemoteOutpoint wire.OutPoint
+
+	// RemoteAmount is the value of the output paying to the remote party
+	// within the breach transaction.
+	RemoteAmount btcutil.Amount
+
+	// RemoteWitnessScript is the witness script of the delayed output
+	// paying to the remote party within the breach transaction.
+	RemoteWitnessScript []byte
+
+	// HtlcRetributions is a slice of HTLC retributions for each
+	// active HTLC output within the breached commitment transaction.
+	HtlcRetributions []*HtlcRetribution
+}
+
+// HtlcRetribution contains all the items necessary to sweep a revoked HTLC
+// output from a broadcast breach transaction.
+type HtlcRetribution struct {
+	// Outpoint is the target outpoint of this HTLC pointing to the
+	// breached commitment transaction.
+	Outpoint wire.OutPoint
+
+	// Amount is the value of the HTLC output.
+	Amount btcutil.Amount
+
+	// WitnessScript is the witness script of the HTLC output.
+	WitnessScript []byte
+
+	// IsIncoming is a boolean flag that indicates whether or not this HTLC
+	// was accepted from the counterparty. A false value indicates that
+	// this HTLC was offered by us.
+	IsIncoming bool
+}
+
+// newBreachRetribution creates a new fully populated BreachRetribution for
+// the passed channel, at a particular revoked state number, and one which
+// targets the passed commitment transaction. An error is returned if the
+// passed transaction doesn't contain any of the outputs expected within the
+// revoked state.
+func newBreachRetribution(chanState *channeldb.OpenChannel, stateNum uint64,
+	broadcastCommitment *wire.MsgTx) (*BreachRetribution, error) {
+
+	// Query the on-disk revocation log for the snapshot which was recorded
+	// at this particular state num.
+	revokedSnapshot, err := chanState.FindPreviousState(stateNum)
+	if err != nil {
+		return nil, err
+	}
+
+	// With the state number broadcast known, we can now derive the proper
+	// revocation pre-image, and also the revocation key and hash used
+	// within the revoked commitment transaction.
+	revocationPreimage, err := chanState.RemoteElkrem.AtIndex(stateNum)
+	if err != nil {
+		return nil, err
+	}
+	revocationKey := DeriveRevocationPubkey(chanState.OurCommitKey,
+		revocationPreimage[:])
+	revocationHash := fastsha256.Sum256(revocationPreimage[:])
+
+	// With the revocation key known, we can now re-create both the
+	// commitment output which pays to us, and the delayed output which
+	// pays to the remote party.
+	localPkScript, err := commitScriptUnencumbered(chanState.OurCommitKey)
+	if err != nil {
+		return nil, err
+	}
+	remoteWitnessScript, err := commitScriptToSelf(
+		chanState.RemoteCsvDelay, chanState.TheirCommitKey,
+		revocationKey)
+	if err != nil {
+		return nil, err
+	}
+	remotePkScript, err := witnessScriptHash(remoteWitnessScript)
+	if err != nil {
+		return nil, err
+	}
+
+	commitHash := broadcastCommitment.TxSha()
+	retribution := &BreachRetribution{
+		BreachTransaction:   broadcastCommitment,
+		RevokedStateNum:     stateNum,
+		RevocationPreimage:  *revocationPreimage,
+		LocalCommitKey:      chanState.OurCommitKey,
+		LocalScript:         localPkScript,
+		RemoteWitnessScript: remoteWitnessScript,
+	}
+
+	// In order to fully populate the breach retribution struct, we'll need
+	// to find the exact index of the commitment outputs within the breach
+	// transaction. As outputs are spent by the justice transaction, an
+	// output index may only be claimed once.
+	claimed := make(map[uint32]struct{})
+	findOutput := func(pkScript []byte) (uint32, bool) {
+		for i, txOut := range broadcastCommitment.TxOut {
+			if _, ok := claimed[uint32(i)]; ok {
+				continue
+			}
+			if bytes.Equal(txOut.PkScript, pkScript) {
+				claimed[uint32(i)] = struct{}{}
+				return uint32(i), true
+			}
+		}
+		return 0, false
+	}
+
+	var numOutputs int
+	if index, ok := findOutput(localPkScript); ok {
+		retribution.LocalOutpoint = wire.OutPoint{
+			Hash:  commitHash,
+			Index: index,
+		}
+		retribution.LocalAmount = btcutil.Amount(
+			broadcastCommitment.TxOut[index].Value,
+		)
+		numOutputs++
+	}
+	if index, ok := findOutput(remotePkScript); ok {
+		retribution.RemoteOutpoint = wire.OutPoint{
+			Hash:  commitHash,
+			Index: index,
+		}
+		retribution.RemoteAmount = btcutil.Amount(
+			broadcastCommitment.TxOut[index].Value,
+		)
+		numOutputs++
+	}
+
+	// Next, re-construct the witness script of each HTLC active within
+	// the revoked state. As this is the remote party's commitment
+	// transaction, HTLCs we accepted were offered by them and use the
+	// sender's version of the script, while HTLCs we offered use the
+	// receiver's version.
+	ourKey := chanState.OurCommitKey
+	theirKey := chanState.TheirCommitKey
+	delay := chanState.RemoteCsvDelay
+	for _, htlc := range revokedSnapshot.Htlcs {
+		var htlcScript []byte
+		if htlc.Incoming {
+			htlcScript, err = senderHTLCScript(htlc.RefundTimeout,
+				delay, theirKey, ourKey, revocationHash[:],
+				htlc.RHash[:])
+		} else {
+			htlcScript, err = receiverHTLCScript(htlc.RefundTimeout,
+				delay, ourKey, theirKey, revocationHash[:],
+				htlc.RHash[:])
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		htlcPkScript, err := witnessScriptHash(htlcScript)
+		if err != nil {
+			return nil, err
+		}
+
+		index, ok := findOutput(htlcPkScript)
+		if !ok {
+			return nil, fmt.Errorf("unable to find HTLC output "+
+				"%x within breach transaction %v",
+				htlc.RHash[:], commitHash)
+		}
+
+		retribution.HtlcRetributions = append(
+			retribution.HtlcRetributions, &HtlcRetribution{
+				Outpoint: wire.OutPoint{
+					Hash:  commitHash,
+					Index: index,
+				},
+				Amount:        btcutil.Amount(broadcastCommitment.TxOut[index].Value),
+				WitnessScript: htlcScript,
+				IsIncoming:    htlc.Incoming,
+			},
+		)
+		numOutputs++
+	}
+
+	// If we weren't able to locate a single output within the broadcast
+	// transaction, then it doesn't match the revoked state.
+	if numOutputs == 0 {
+		return nil, fmt.Errorf("transaction %v doesn't match revoked "+
+			"state #%v", commitHash, stateNum)
+	}
+
+	return retribution, nil
+}
+
+// CreateJusticeTx creates a transaction which sweeps ALL the outputs of a
+// breach transaction described by the passed BreachRetribution into a single
+// output paying to sweepPkScript. commitPriv should be the private key
+// corresponding to our commitment key within the channel, from which the
+// revocation private key is derived. The returned transaction is fully
+// signed, and ready for broadcast.
+func CreateJusticeTx(retribution *BreachRetribution,
+	commitPriv *btcec.PrivateKey, sweepPkScript []byte,
+	fee btcutil.Amount) (*wire.MsgTx, error) {
+
+	// First, derive the revocation private key using the revealed
+	// pre-image. With this key, we're able to satisfy the revocation
+	// clause of the remote party's delayed output, along with that of
+	// every HTLC output.
+	revocationPriv := DeriveRevocationPrivKey(commitPriv,
+		retribution.RevocationPreimage[:])
+
+	// Next, assemble the inputs of the justice transaction, keeping track
+	// of the witness generation closure for each.
+	type sweepInput struct {
+		outpoint wire.OutPoint
+		amount   btcutil.Amount
+		witness  func(*wire.MsgTx, int) (wire.TxWitness, error)
+	}
+
+	var inputs []*sweepInput
+	if retribution.LocalAmount != 0 {
+		inputs = append(inputs, &sweepInput{
+			outpoint: retribution.LocalOutpoint,
+			amount:   retribution.LocalAmount,
+			witness: func(tx *wire.MsgTx, idx int) (wire.TxWitness, error) {
+				return commitSpendNoDelay(retribution.LocalScript,
+					retribution.LocalAmount, commitPriv, tx, idx)
+			},
+		})
+	}
+	if retribution.RemoteAmount != 0 {
+		inputs = append(inputs, &sweepInput{
+			outpoint: retribution.RemoteOutpoint,
+			amount:   retribution.RemoteAmount,
+			witness: func(tx *wire.MsgTx, idx int) (wire.TxWitness, error) {
+				return commitSpendRevoke(retribution.RemoteWitnessScript,
+					retribution.RemoteAmount, revocationPriv, tx, idx)
+			},
+		})
+	}
+	preimage := retribution.RevocationPreimage[:]
+	for _, htlc := range retribution.HtlcRetributions {
+		htlc := htlc
+		inputs = append(inputs, &sweepInput{
+			outpoint: htlc.Outpoint,
+			amount:   htlc.Amount,
+			witness: func(tx *wire.MsgTx, idx int) (wire.TxWitness, error) {
+				if htlc.IsIncoming {
+					return senderHtlcSpendRevoke(htlc.WitnessScript,
+						htlc.Amount, commitPriv, tx, idx, preimage)
+				}
+				return receiverHtlcSpendRevoke(htlc.WitnessScript,
+					htlc.Amount, commitPriv, tx, idx, preimage)
+			},
+		})
+	}
+
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("breach transaction has no outputs " +
+			"to sweep")
+	}
+
+	var totalAmt btcutil.Amount
+	justiceTx := wire.NewMsgTx()
+	for _, input := range inputs {
+		totalAmt += input.amount
+		justiceTx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: input.outpoint,
+		})
+	}
+
+	if totalAmt <= fee {
+		return nil, fmt.Errorf("breach outputs total %v, unable to "+
+			"pay fee of %v", totalAmt, fee)
+	}
+	justiceTx.AddTxOut(&wire.TxOut{
+		PkScript: sweepPkScript,
+		Value:    int64(totalAmt - fee),
+	})
+
+	// Finally, with the transaction fully assembled, generate a valid
+	// witness for each input.
+	for i, input := range inputs {
+		witness, err := input.witness(justiceTx, i)
+		if err != nil {
+			return nil, err
+		}
+		justiceTx.TxIn[i].Witness = witness
+	}
+
+	return justiceTx, nil
+}
+
 // ForceCloseSummary describes the final commitment state before the channel is
 // locked-down to initiate a force closure by broadcasting the latest state
 // on-chain. The summary includes all the information required to claim all
@@ -1446,6 +2496,35 @@ type ForceCloseSummary struct {
 	// SelfOutputSignDesc is a fully populated sign descriptor capable of
 	// generating a valid signature to swee the self output.
 	SelfOutputSignDesc *SignDescriptor
+
+	// HtlcResolutions describes each of our outgoing HTLC outputs within
+	// the close transaction. Each of these outputs can be swept back
+	// into the wallet via the HTLC timeout clause once the HTLC has
+	// expired, and the relative delay has passed.
+	HtlcResolutions []*HtlcResolution
+}
+
+// HtlcResolution houses the information required to sweep an outgoing HTLC
+// output from our commitment transaction after it has been broadcast
+// on-chain. The output can only be swept after both the absolute expiry of
+// the HTLC, and the relative delay of the commitment transaction have passed.
+// TODO: add resolutions for incoming HTLC's we know the pre-image to
+type HtlcResolution struct {
+	// Outpoint is the outpoint of the HTLC output within the close
+	// transaction.
+	Outpoint wire.OutPoint
+
+	// Expiry is the absolute block height after which the HTLC output
+	// can be swept.
+	Expiry uint32
+
+	// Maturity is the relative delay (in blocks) after the confirmation
+	// of the close transaction before the HTLC output can be swept.
+	Maturity uint32
+
+	// SignDesc is a fully populated sign descriptor capable of generating
+	// a valid signature to sweep the HTLC output via the timeout clause.
+	SignDesc *SignDescriptor
 }
 
 // ForceClose executes a unilateral closure of the transaction at the current
@@ -1489,22 +2568,6 @@ func (lc *LightningChannel) ForceClose() (*ForceCloseSummary, error) {
 		theirKey, theirSig)
 	commitTx.TxIn[0].Witness = witness
 
-	// Locate the output index of the delayed commitment output back to us.
-	// We'll return the details of this output to the caller so they can
-	// sweep it once it's mature.
-	// TODO(roasbeef): also return HTLC info, assumes only p2wsh is commit
-	// tx
-	var delayIndex uint32
-	var delayScript []byte
-	for i, txOut := range commitTx.TxOut {
-		if !txscript.IsPayToWitnessScriptHash(txOut.PkScript) {
-			continue
-		}
-
-		delayIndex = uint32(i)
-		delayScript = txOut.PkScript
-	}
-
 	csvTimeout := lc.channelState.LocalCsvDelay
 	selfKey := lc.channelState.OurCommitKey
 
@@ -1523,6 +2586,15 @@ func (lc *LightningChannel) ForceClose() (*ForceCloseSummary, error) {
 		return nil, err
 	}
 
+	// Locate the output index of the delayed commitment output back to us.
+	// We'll return the details of this output to the caller so they can
+	// sweep it once it's mature.
+	delayScript, err := witnessScriptHash(selfScript)
+	if err != nil {
+		return nil, err
+	}
+	_, delayIndex := FindScriptOutputIndex(commitTx, delayScript)
+
 	// With the necessary information gatehred above, create a new sign
 	// descriptor which is capable of generating the signature the caller
 	// needs to sweep this output. The hash cache, and input index are not
@@ -1537,6 +2609,65 @@ func (lc *LightningChannel) ForceClose() (*ForceCloseSummary, error) {
 		HashType: txscript.SigHashAll,
 	}
 
+	// Next, re-derive the witness script of each of our outgoing HTLC's
+	// within the commitment transaction so they can be swept via the
+	// timeout clause once they expire.
+	commitHash := commitTx.TxSha()
+	revokeHash := fastsha256.Sum256(unusedRevocation[:])
+	claimedOutputs := make(map[uint32]struct{})
+	var htlcResolutions []*HtlcResolution
+	for _, htlc := range lc.channelState.Htlcs {
+		if htlc.Incoming {
+			continue
+		}
+
+		htlcScript, err := senderHTLCScript(htlc.RefundTimeout,
+			csvTimeout, selfKey, lc.channelState.TheirCommitKey,
+			revokeHash[:], htlc.RHash[:])
+		if err != nil {
+			return nil, err
+		}
+		htlcPkScript, err := witnessScriptHash(htlcScript)
+		if err != nil {
+			return nil, err
+		}
+
+		// Locate the output index of this HTLC, taking care to skip
+		// any outputs already claimed by an identical HTLC.
+		var htlcOutput *wire.TxOut
+		var htlcIndex uint32
+		for i, txOut := range commitTx.TxOut {
+			if _, ok := claimedOutputs[uint32(i)]; ok {
+				continue
+			}
+			if bytes.Equal(txOut.PkScript, htlcPkScript) {
+				htlcOutput = txOut
+				htlcIndex = uint32(i)
+				break
+			}
+		}
+		if htlcOutput == nil {
+			return nil, fmt.Errorf("unable to find htlc output "+
+				"for %x", htlc.RHash[:])
+		}
+		claimedOutputs[htlcIndex] = struct{}{}
+
+		htlcResolutions = append(htlcResolutions, &HtlcResolution{
+			Outpoint: wire.OutPoint{
+				Hash:  commitHash,
+				Index: htlcIndex,
+			},
+			Expiry:   htlc.RefundTimeout,
+			Maturity: csvTimeout,
+			SignDesc: &SignDescriptor{
+				PubKey:       selfKey,
+				RedeemScript: htlcScript,
+				Output:       htlcOutput,
+				HashType:     txscript.SigHashAll,
+			},
+		})
+	}
+
 	// Finally, close the channel force close signal which notifies any
 	// subscribers that the channel has now been forcibly closed. This
 	// allows callers to begin to carry out any post channel closure
@@ -1546,11 +2677,12 @@ func (lc *LightningChannel) ForceClose() (*ForceCloseSummary, error) {
 	return &ForceCloseSummary{
 		CloseTx: commitTx,
 		SelfOutpoint: wire.OutPoint{
-			Hash:  commitTx.TxSha(),
+			Hash:  commitHash,
 			Index: delayIndex,
 		},
 		SelfOutputMaturity: csvTimeout,
 		SelfOutputSignDesc: selfSignDesc,
+		HtlcResolutions:    htlcResolutions,
 	}, nil
 }
 
@@ -1565,9 +2697,13 @@ func (lc *LightningChannel) ForceClose() (*ForceCloseSummary, error) {
 // of an unresponsive remote party, the initiator can either choose to execute
 // a force closure, or backoff for a period of time, and retry the cooperative
 // closure.
+//
+// The closing transaction pays the passed fee rate, expressed in
+// satoshis/KB, which must be at least the minimum fee rate negotiated for the
+// channel.
 // TODO(roasbeef): caller should initiate signal to reject all incoming HTLCs,
 // settle any inflight.
-func (lc *LightningChannel) InitCooperativeClose() ([]byte, *wire.ShaHash, error) {
+func (lc *LightningChannel) InitCooperativeClose(feePerKb btcutil.Amount) ([]byte, *wire.ShaHash, error) {
 	lc.Lock()
 	defer lc.Unlock()
 
@@ -1577,6 +2713,10 @@ func (lc *LightningChannel) InitCooperativeClose() ([]byte, *wire.ShaHash, error
 		return nil, nil, ErrChanClosing
 	}
 
+	if feePerKb < lc.channelState.MinFeePerKb {
+		return nil, nil, ErrFeeTooLow
+	}
+
 	// Otherwise, indicate in the channel status that a channel closure has
 	// been initiated.
 	lc.status = channelClosing
@@ -1585,7 +2725,7 @@ func (lc *LightningChannel) InitCooperativeClose() ([]byte, *wire.ShaHash, error
 	closeTx := CreateCooperativeCloseTx(lc.fundingTxIn,
 		lc.channelState.OurBalance, lc.channelState.TheirBalance,
 		lc.channelState.OurDeliveryScript, lc.channelState.TheirDeliveryScript,
-		true)
+		true, CooperativeCloseFee(feePerKb))
 	closeTxSha := closeTx.TxSha()
 
 	// Finally, sign the completed cooperative closure transaction. As the
@@ -1608,8 +2748,12 @@ func (lc *LightningChannel) InitCooperativeClose() ([]byte, *wire.ShaHash, error
 // a signed+valid closure transaction to the network.
 //
 // NOTE: The passed remote sig is expected to the a fully complete signature
-// including the proper sighash byte.
-func (lc *LightningChannel) CompleteCooperativeClose(remoteSig []byte) (*wire.MsgTx, error) {
+// including the proper sighash byte. The passed fee rate is the fee rate
+// proposed by the initiator of the closure, which must be at least the
+// minimum fee rate negotiated for the channel.
+func (lc *LightningChannel) CompleteCooperativeClose(remoteSig []byte,
+	feePerKb btcutil.Amount) (*wire.MsgTx, error) {
+
 	lc.Lock()
 	defer lc.Unlock()
 
@@ -1619,6 +2763,10 @@ func (lc *LightningChannel) CompleteCooperativeClose(remoteSig []byte) (*wire.Ms
 		return nil, ErrChanClosing
 	}
 
+	if feePerKb < lc.channelState.MinFeePerKb {
+		return nil, ErrFeeTooLow
+	}
+
 	lc.status = channelClosed
 
 	// Create the transaction used to return the current settled balance
@@ -1627,7 +2775,7 @@ func (lc *LightningChannel) CompleteCooperativeClose(remoteSig []byte) (*wire.Ms
 	closeTx := CreateCooperativeCloseTx(lc.fundingTxIn,
 		lc.channelState.OurBalance, lc.channelState.TheirBalance,
 		lc.channelState.OurDeliveryScript, lc.channelState.TheirDeliveryScript,
-		false)
+		false, CooperativeCloseFee(feePerKb))
 
 	// With the transaction created, we can finally generate our half of
 	// the 2-of-2 multi-sig needed to redeem the funding output.
@@ -1712,9 +2860,15 @@ func CreateCommitTx(fundingOutput *wire.TxIn, selfKey, theirKey *btcec.PublicKey
 	// Now that both output scripts have been created, we can finally create
 	// the transaction itself. We use a transaction version of 2 since CSV
 	// will fail unless the tx version is >= 2.
+	// The funding input is copied as the sequence number of each
+	// commitment's input is later modified in order to encode the state
+	// hint of the commitment.
 	commitTx := wire.NewMsgTx()
 	commitTx.Version = 2
-	commitTx.AddTxIn(fundingOutput)
+	commitTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: fundingOutput.PreviousOutPoint,
+		Sequence:         fundingOutput.Sequence,
+	})
 
 	// Avoid creating zero value outputs within the commitment transaction.
 	if amountToSelf != 0 {
@@ -1727,6 +2881,14 @@ func CreateCommitTx(fundingOutput *wire.TxIn, selfKey, theirKey *btcec.PublicKey
 	return commitTx, nil
 }
 
+// CooperativeCloseFee returns the absolute fee paid by a cooperative closure
+// transaction at the passed fee rate, expressed in satoshis/KB. As both sides
+// derive the fee from the same fee rate, they arrive at an identical closure
+// transaction.
+func CooperativeCloseFee(feePerKb btcutil.Amount) btcutil.Amount {
+	return feePerKb * coopCloseTxSize / 1000
+}
+
 // CreateCooperativeCloseTx creates a transaction which if signed by both
 // parties, then broadcast cooperatively closes an active channel. The creation
 // of the closure transaction is modified by a boolean indicating if the party
@@ -1736,7 +2898,7 @@ func CreateCommitTx(fundingOutput *wire.TxIn, selfKey, theirKey *btcec.PublicKey
 func CreateCooperativeCloseTx(fundingTxIn *wire.TxIn,
 	ourBalance, theirBalance btcutil.Amount,
 	ourDeliveryScript, theirDeliveryScript []byte,
-	initiator bool) *wire.MsgTx {
+	initiator bool, fee btcutil.Amount) *wire.MsgTx {
 
 	// Construct the transaction to perform a cooperative closure of the
 	// channel. In the event that one side doesn't have any settled funds
@@ -1748,10 +2910,9 @@ func CreateCooperativeCloseTx(fundingTxIn *wire.TxIn,
 	// The initiator the a cooperative closure pays the fee in entirety.
 	// Determine if we're the initiator so we can compute fees properly.
 	if initiator {
-		// TODO(roasbeef): take sat/byte here instead of properly calc
-		ourBalance -= 5000
+		ourBalance -= fee
 	} else {
-		theirBalance -= 5000
+		theirBalance -= fee
 	}
 
 	// TODO(roasbeef): dust check...
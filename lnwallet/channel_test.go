@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/btcsuite/fastsha256"
 	"github.com/davecgh/go-spew/spew"
@@ -166,6 +167,42 @@ func forceStateTransition(chanA, chanB *LightningChannel) error {
 	return nil
 }
 
+// storeCommitDiff persists the commitment most recently signed by the passed
+// channel along with the updates it covers, mirroring the behavior of the
+// peer directly after signing a new commitment. The generated CommitSignature
+// message is returned.
+func storeCommitDiff(channel *LightningChannel, sig []byte, logIndex uint32,
+	updates []lnwire.Message) (*lnwire.CommitSignature, error) {
+
+	parsedSig, err := btcec.ParseSignature(sig, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	commitSig := &lnwire.CommitSignature{
+		ChannelPoint: channel.ChannelPoint(),
+		LogIndex:     uint64(logIndex),
+		CommitSig:    parsedSig,
+	}
+	if err := channel.StoreCommitDiff(commitSig, updates); err != nil {
+		return nil, err
+	}
+
+	return commitSig, nil
+}
+
+// reloadChannel creates a new instance of the passed channel from the state
+// stored on disk in order to simulate a restart.
+func reloadChannel(channel *LightningChannel) (*LightningChannel, error) {
+	id := wire.ShaHash(testHdSeed)
+	channels, err := channel.channelState.Db.FetchOpenChannels(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLightningChannel(channel.signer, nil, channel.channelEvents,
+		channels[0])
+}
+
 // createTestChannels creates two test channels funded witr 10 BTC, with 5 BTC
 // allocated to each side.
 func createTestChannels(revocationWindow int) (*LightningChannel, *LightningChannel, func(), error) {
@@ -243,6 +280,10 @@ func createTestChannels(revocationWindow int) (*LightningChannel, *LightningChan
 		FundingRedeemScript:    redeemScript,
 		LocalCsvDelay:          csvTimeoutAlice,
 		RemoteCsvDelay:         csvTimeoutBob,
+		LocalMaxAcceptedHTLCs:  lnwire.MaxHTLCNumber,
+		RemoteMaxAcceptedHTLCs: lnwire.MaxHTLCNumber,
+		LocalMaxValueInFlight:  channelCapacity,
+		RemoteMaxValueInFlight: channelCapacity,
 		TheirCurrentRevocation: bobRevokeKey,
 		LocalElkrem:            aliceElkrem,
 		RemoteElkrem:           &elkrem.ElkremReceiver{},
@@ -263,6 +304,10 @@ func createTestChannels(revocationWindow int) (*LightningChannel, *LightningChan
 		FundingRedeemScript:    redeemScript,
 		LocalCsvDelay:          csvTimeoutBob,
 		RemoteCsvDelay:         csvTimeoutAlice,
+		LocalMaxAcceptedHTLCs:  lnwire.MaxHTLCNumber,
+		RemoteMaxAcceptedHTLCs: lnwire.MaxHTLCNumber,
+		LocalMaxValueInFlight:  channelCapacity,
+		RemoteMaxValueInFlight: channelCapacity,
 		TheirCurrentRevocation: aliceRevokeKey,
 		LocalElkrem:            bobElkrem,
 		RemoteElkrem:           &elkrem.ElkremReceiver{},
@@ -436,11 +481,10 @@ func TestSimpleAddSettleWorkflow(t *testing.T) {
 	// HTLC once he learns of the preimage.
 	var preimage [32]byte
 	copy(preimage[:], paymentPreimage)
-	settleIndex, err := bobChannel.SettleHTLC(preimage)
-	if err != nil {
+	if err := bobChannel.SettleHTLC(preimage, 0); err != nil {
 		t.Fatalf("bob unable to settle inbound htlc: %v", err)
 	}
-	if err := aliceChannel.ReceiveHTLCSettle(preimage, settleIndex); err != nil {
+	if err := aliceChannel.ReceiveHTLCSettle(preimage, 0); err != nil {
 		t.Fatalf("alice unable to accept settle of outbound htlc: %v", err)
 	}
 	bobSig2, aliceIndex2, err := bobChannel.SignNextCommitment()
@@ -565,13 +609,16 @@ func TestCooperativeChannelClosure(t *testing.T) {
 	}
 	defer cleanUp()
 
+	// Both sides will use the same fee rate for the closure transaction.
+	feePerKb := btcutil.Amount(10000)
+
 	// First we test the channel initiator requesting a cooperative close.
-	sig, txid, err := aliceChannel.InitCooperativeClose()
+	sig, txid, err := aliceChannel.InitCooperativeClose(feePerKb)
 	if err != nil {
 		t.Fatalf("unable to initiate alice cooperative close: %v", err)
 	}
 	finalSig := append(sig, byte(txscript.SigHashAll))
-	closeTx, err := bobChannel.CompleteCooperativeClose(finalSig)
+	closeTx, err := bobChannel.CompleteCooperativeClose(finalSig, feePerKb)
 	if err != nil {
 		t.Fatalf("unable to complete alice cooperative close: %v", err)
 	}
@@ -586,12 +633,12 @@ func TestCooperativeChannelClosure(t *testing.T) {
 
 	// Next we test the channel recipient requesting a cooperative closure.
 	// First we test the channel initiator requesting a cooperative close.
-	sig, txid, err = bobChannel.InitCooperativeClose()
+	sig, txid, err = bobChannel.InitCooperativeClose(feePerKb)
 	if err != nil {
 		t.Fatalf("unable to initiate bob cooperative close: %v", err)
 	}
 	finalSig = append(sig, byte(txscript.SigHashAll))
-	closeTx, err = aliceChannel.CompleteCooperativeClose(finalSig)
+	closeTx, err = aliceChannel.CompleteCooperativeClose(finalSig, feePerKb)
 	if err != nil {
 		t.Fatalf("unable to complete bob cooperative close: %v", err)
 	}
@@ -749,21 +796,21 @@ func TestStateUpdatePersistence(t *testing.T) {
 
 	// Now settle all the HTLC's, then force a state update. The state
 	// update should suceed as both sides have identical.
-	for i := 0; i < 3; i++ {
-		settleIndex, err := bobChannelNew.SettleHTLC(alicePreimage)
+	for i := uint32(0); i < 3; i++ {
+		err := bobChannelNew.SettleHTLC(alicePreimage, i)
 		if err != nil {
 			t.Fatalf("unable to settle htlc: %v", err)
 		}
-		err = aliceChannelNew.ReceiveHTLCSettle(alicePreimage, settleIndex)
+		err = aliceChannelNew.ReceiveHTLCSettle(alicePreimage, i)
 		if err != nil {
 			t.Fatalf("unable to settle htlc: %v", err)
 		}
 	}
-	settleIndex, err := aliceChannelNew.SettleHTLC(bobPreimage)
+	err = aliceChannelNew.SettleHTLC(bobPreimage, 0)
 	if err != nil {
 		t.Fatalf("unable to settle htlc: %v", err)
 	}
-	err = bobChannelNew.ReceiveHTLCSettle(bobPreimage, settleIndex)
+	err = bobChannelNew.ReceiveHTLCSettle(bobPreimage, 0)
 	if err != nil {
 		t.Fatalf("unable to settle htlc: %v", err)
 	}
@@ -785,3 +832,712 @@ func TestStateUpdatePersistence(t *testing.T) {
 			bobBalance)
 	}
 }
+
+func TestHTLCCancelWorkflow(t *testing.T) {
+	// Create a test channel which will be used for the duration of this
+	// unittest. The channel will be funded evenly with Alice having 5 BTC,
+	// and Bob having 5 BTC.
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	paymentPreimage := bytes.Repeat([]byte{2}, 32)
+	paymentHash := fastsha256.Sum256(paymentPreimage)
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{paymentHash},
+		Amount:           lnwire.CreditsAmount(1e8),
+		Expiry:           uint32(5),
+	}
+
+	// Alice adds an HTLC to Bob, then both sides lock in the new HTLC.
+	aliceChannel.AddHTLC(htlc)
+	bobChannel.ReceiveHTLC(htlc)
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+
+	// Before the expiry height is reached, neither side should report the
+	// HTLC as expiring.
+	if htlcs := bobChannel.ExpiringHTLCs(4, true); len(htlcs) != 0 {
+		t.Fatalf("bob shouldn't have any expiring htlcs, has %v",
+			len(htlcs))
+	}
+	if htlcs := aliceChannel.ExpiringHTLCs(4, false); len(htlcs) != 0 {
+		t.Fatalf("alice shouldn't have any expiring htlcs, has %v",
+			len(htlcs))
+	}
+
+	// Once the expiry height is reached, the HTLC should be reported as
+	// an expiring incoming HTLC for Bob, and an expiring outgoing HTLC for
+	// Alice.
+	expiring := bobChannel.ExpiringHTLCs(5, true)
+	if len(expiring) != 1 {
+		t.Fatalf("bob should have 1 expiring htlc, has %v", len(expiring))
+	}
+	if htlcs := aliceChannel.ExpiringHTLCs(5, false); len(htlcs) != 1 {
+		t.Fatalf("alice should have 1 expiring htlc, has %v", len(htlcs))
+	}
+
+	// Bob is unable to settle the HTLC, so he cancels it back to Alice.
+	logIndex := expiring[0].Index
+	if err := bobChannel.TimeoutHTLC(logIndex); err != nil {
+		t.Fatalf("bob unable to cancel htlc: %v", err)
+	}
+	if err := aliceChannel.ReceiveTimeoutHTLC(logIndex); err != nil {
+		t.Fatalf("alice unable to receive cancel: %v", err)
+	}
+
+	// A second attempt to cancel the same HTLC should fail.
+	if err := bobChannel.TimeoutHTLC(logIndex); err == nil {
+		t.Fatalf("bob was able to cancel htlc twice")
+	}
+
+	// Bob now locks in the removal of the HTLC.
+	if err := forceStateTransition(bobChannel, aliceChannel); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+
+	// With the HTLC cancelled, the balances of both sides should be
+	// restored to their original values, and no HTLC's should remain
+	// within either commitment.
+	expectedBalance := btcutil.Amount(5 * 1e8)
+	if aliceChannel.channelState.OurBalance != expectedBalance {
+		t.Fatalf("alice has incorrect local balance %v vs %v",
+			aliceChannel.channelState.OurBalance, expectedBalance)
+	}
+	if bobChannel.channelState.OurBalance != expectedBalance {
+		t.Fatalf("bob has incorrect local balance %v vs %v",
+			bobChannel.channelState.OurBalance, expectedBalance)
+	}
+	if len(aliceChannel.channelState.Htlcs) != 0 {
+		t.Fatalf("alice still has %v htlcs",
+			len(aliceChannel.channelState.Htlcs))
+	}
+	if len(bobChannel.channelState.Htlcs) != 0 {
+		t.Fatalf("bob still has %v htlcs",
+			len(bobChannel.channelState.Htlcs))
+	}
+	if htlcs := aliceChannel.ExpiringHTLCs(5, false); len(htlcs) != 0 {
+		t.Fatalf("alice shouldn't have any expiring htlcs, has %v",
+			len(htlcs))
+	}
+}
+
+func TestHTLCCancelSharedHash(t *testing.T) {
+	// Create a test channel which will be used for the duration of this
+	// unittest. The channel will be funded evenly with Alice having 5 BTC,
+	// and Bob having 5 BTC.
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	// Alice sends Bob two HTLC's paying to the same payment hash, as the
+	// parts of a multi-part payment would. The HTLC's differ in both
+	// their amount and their expiry.
+	var preimage [32]byte
+	copy(preimage[:], bytes.Repeat([]byte{3}, 32))
+	paymentHash := fastsha256.Sum256(preimage[:])
+	htlcs := []*lnwire.HTLCAddRequest{
+		{
+			RedemptionHashes: [][32]byte{paymentHash},
+			Amount:           lnwire.CreditsAmount(1e8),
+			Expiry:           uint32(5),
+		},
+		{
+			RedemptionHashes: [][32]byte{paymentHash},
+			Amount:           lnwire.CreditsAmount(2e8),
+			Expiry:           uint32(10),
+		},
+	}
+	var indexes []uint32
+	for _, htlc := range htlcs {
+		if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+			t.Fatalf("unable to add htlc: %v", err)
+		}
+		index, err := bobChannel.ReceiveHTLC(htlc)
+		if err != nil {
+			t.Fatalf("unable to recv htlc: %v", err)
+		}
+		indexes = append(indexes, index)
+	}
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+
+	// Only the first HTLC should be reported as expiring at height 5.
+	expiring := bobChannel.ExpiringHTLCs(5, true)
+	if len(expiring) != 1 {
+		t.Fatalf("bob should have 1 expiring htlc, has %v", len(expiring))
+	}
+	if expiring[0].Index != indexes[0] {
+		t.Fatalf("expected htlc %v to expire, instead htlc %v expired",
+			indexes[0], expiring[0].Index)
+	}
+
+	// Bob cancels the expiring HTLC, and settles the other one. Each
+	// should be removed by its own index, rather than the first HTLC
+	// matching the payment hash.
+	if err := bobChannel.TimeoutHTLC(indexes[0]); err != nil {
+		t.Fatalf("bob unable to cancel htlc: %v", err)
+	}
+	if err := aliceChannel.ReceiveTimeoutHTLC(indexes[0]); err != nil {
+		t.Fatalf("alice unable to receive cancel: %v", err)
+	}
+	if err := bobChannel.SettleHTLC(preimage, indexes[1]); err != nil {
+		t.Fatalf("bob unable to settle htlc: %v", err)
+	}
+	if err := aliceChannel.ReceiveHTLCSettle(preimage, indexes[1]); err != nil {
+		t.Fatalf("alice unable to receive settle: %v", err)
+	}
+
+	// Neither HTLC can be removed a second time.
+	if err := bobChannel.TimeoutHTLC(indexes[1]); err == nil {
+		t.Fatalf("bob was able to cancel a settled htlc")
+	}
+	if err := bobChannel.SettleHTLC(preimage, indexes[0]); err == nil {
+		t.Fatalf("bob was able to settle a cancelled htlc")
+	}
+
+	if err := forceStateTransition(bobChannel, aliceChannel); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+
+	// Only the value of the settled HTLC should have been transferred to
+	// Bob.
+	expectedAlice := btcutil.Amount(3 * 1e8)
+	if aliceChannel.channelState.OurBalance != expectedAlice {
+		t.Fatalf("alice has incorrect local balance %v vs %v",
+			aliceChannel.channelState.OurBalance, expectedAlice)
+	}
+	expectedBob := btcutil.Amount(7 * 1e8)
+	if bobChannel.channelState.OurBalance != expectedBob {
+		t.Fatalf("bob has incorrect local balance %v vs %v",
+			bobChannel.channelState.OurBalance, expectedBob)
+	}
+	if len(bobChannel.channelState.Htlcs) != 0 {
+		t.Fatalf("bob still has %v htlcs",
+			len(bobChannel.channelState.Htlcs))
+	}
+}
+
+func TestChanSyncRevocationRetransmit(t *testing.T) {
+	// Create a test channel which will be used for the duration of this
+	// unittest. The channel will be funded evenly with Alice having 5 BTC,
+	// and Bob having 5 BTC.
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	if err := aliceChannel.channelState.FullSync(); err != nil {
+		t.Fatalf("unable to sync alice's channel: %v", err)
+	}
+	if err := bobChannel.channelState.FullSync(); err != nil {
+		t.Fatalf("unable to sync bob's channel: %v", err)
+	}
+
+	var paymentPreimage [32]byte
+	copy(paymentPreimage[:], bytes.Repeat([]byte{3}, 32))
+	paymentHash := fastsha256.Sum256(paymentPreimage[:])
+	htlc := &lnwire.HTLCAddRequest{
+		ChannelPoint:     aliceChannel.ChannelPoint(),
+		RedemptionHashes: [][32]byte{paymentHash},
+		Amount:           lnwire.CreditsAmount(1e8),
+		Expiry:           uint32(5),
+	}
+	aliceChannel.AddHTLC(htlc)
+	bobChannel.ReceiveHTLC(htlc)
+
+	// Alice and Bob execute a state transition to lock in the HTLC, but
+	// Bob's revocation never reaches Alice as the connection is dropped.
+	aliceSig, bobIndex, err := aliceChannel.SignNextCommitment()
+	if err != nil {
+		t.Fatalf("unable to sign commitment: %v", err)
+	}
+	_, err = storeCommitDiff(aliceChannel, aliceSig, bobIndex,
+		[]lnwire.Message{htlc})
+	if err != nil {
+		t.Fatalf("unable to store commit diff: %v", err)
+	}
+	if err := bobChannel.ReceiveNewCommitment(aliceSig, bobIndex); err != nil {
+		t.Fatalf("bob unable to process alice's commitment: %v", err)
+	}
+	bobSig, aliceIndex, err := bobChannel.SignNextCommitment()
+	if err != nil {
+		t.Fatalf("unable to sign commitment: %v", err)
+	}
+	if _, err := bobChannel.RevokeCurrentCommitment(); err != nil {
+		t.Fatalf("unable to revoke commitment: %v", err)
+	}
+	if err := aliceChannel.ReceiveNewCommitment(bobSig, aliceIndex); err != nil {
+		t.Fatalf("alice unable to process bob's commitment: %v", err)
+	}
+	aliceRevocation, err := aliceChannel.RevokeCurrentCommitment()
+	if err != nil {
+		t.Fatalf("unable to revoke commitment: %v", err)
+	}
+	if _, err := bobChannel.ReceiveRevocation(aliceRevocation); err != nil {
+		t.Fatalf("bob unable to process alice's revocation: %v", err)
+	}
+
+	// Now fetch both of the channels from disk to simulate a
+	// reconnection.
+	id := wire.ShaHash(testHdSeed)
+	aliceChannels, err := aliceChannel.channelState.Db.FetchOpenChannels(&id)
+	if err != nil {
+		t.Fatalf("unable to fetch channel: %v", err)
+	}
+	bobChannels, err := bobChannel.channelState.Db.FetchOpenChannels(&id)
+	if err != nil {
+		t.Fatalf("unable to fetch channel: %v", err)
+	}
+	notifier := aliceChannel.channelEvents
+	aliceChannelNew, err := NewLightningChannel(aliceChannel.signer, nil, notifier, aliceChannels[0])
+	if err != nil {
+		t.Fatalf("unable to create new channel: %v", err)
+	}
+	bobChannelNew, err := NewLightningChannel(bobChannel.signer, nil, notifier, bobChannels[0])
+	if err != nil {
+		t.Fatalf("unable to create new channel: %v", err)
+	}
+
+	// Alice still considers Bob's initial commitment current, while Bob
+	// has moved on to the next height.
+	aliceSyncMsg := aliceChannelNew.ChanSyncMsg()
+	bobSyncMsg := bobChannelNew.ChanSyncMsg()
+	if aliceSyncMsg.LocalCommitHeight != 1 ||
+		aliceSyncMsg.RemoteCommitHeight != 0 {
+		t.Fatalf("alice has incorrect sync heights: %v",
+			spew.Sdump(aliceSyncMsg))
+	}
+	if bobSyncMsg.LocalCommitHeight != 1 ||
+		bobSyncMsg.RemoteCommitHeight != 1 {
+		t.Fatalf("bob has incorrect sync heights: %v",
+			spew.Sdump(bobSyncMsg))
+	}
+
+	// Upon processing Alice's sync message, Bob should retransmit his
+	// lost revocation. Alice has nothing to retransmit.
+	bobRetransmit, err := bobChannelNew.ProcessChanSyncMsg(aliceSyncMsg)
+	if err != nil {
+		t.Fatalf("bob unable to process chan sync msg: %v", err)
+	}
+	if len(bobRetransmit) != 1 {
+		t.Fatalf("expected bob to retransmit 1 msg, instead has %v",
+			len(bobRetransmit))
+	}
+	aliceRetransmit, err := aliceChannelNew.ProcessChanSyncMsg(bobSyncMsg)
+	if err != nil {
+		t.Fatalf("alice unable to process chan sync msg: %v", err)
+	}
+	if len(aliceRetransmit) != 0 {
+		t.Fatalf("expected alice to retransmit no msgs, instead has %v",
+			len(aliceRetransmit))
+	}
+
+	revMsg, ok := bobRetransmit[0].(*lnwire.CommitRevocation)
+	if !ok {
+		t.Fatalf("expected revocation, instead got %T", bobRetransmit[0])
+	}
+	if _, err := aliceChannelNew.ReceiveRevocation(revMsg); err != nil {
+		t.Fatalf("alice unable to process retransmitted "+
+			"revocation: %v", err)
+	}
+
+	// Both sides should now be fully synced.
+	aliceSyncMsg = aliceChannelNew.ChanSyncMsg()
+	if aliceSyncMsg.RemoteCommitHeight != bobSyncMsg.LocalCommitHeight {
+		t.Fatalf("alice has incorrect remote height: expected %v, "+
+			"got %v", bobSyncMsg.LocalCommitHeight,
+			aliceSyncMsg.RemoteCommitHeight)
+	}
+
+	// As Bob's revocation acked Alice's pending commitment, the stored
+	// commit diff should have been removed.
+	_, err = aliceChannelNew.channelState.FetchCommitDiff()
+	if err != channeldb.ErrNoCommitDiff {
+		t.Fatalf("expected ErrNoCommitDiff, instead got %v", err)
+	}
+
+	// With the channels synced, a regular state transition settling the
+	// HTLC should succeed.
+	if err := initRevocationWindows(aliceChannelNew, bobChannelNew, 3); err != nil {
+		t.Fatalf("unable to init revocation windows: %v", err)
+	}
+	if err := bobChannelNew.SettleHTLC(paymentPreimage, 0); err != nil {
+		t.Fatalf("unable to settle htlc: %v", err)
+	}
+	err = aliceChannelNew.ReceiveHTLCSettle(paymentPreimage, 0)
+	if err != nil {
+		t.Fatalf("unable to settle htlc: %v", err)
+	}
+	if err := forceStateTransition(bobChannelNew, aliceChannelNew); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+
+	// Finally, a sync message claiming revocations for states Bob hasn't
+	// yet reached should be detected as local data loss.
+	bogusSyncMsg := &lnwire.ChannelReestablish{
+		ChannelPoint:       bobChannelNew.ChannelPoint(),
+		LocalCommitHeight:  bobChannelNew.remoteCommitChain.tail().height,
+		RemoteCommitHeight: bobChannelNew.currentHeight + 5,
+	}
+	if _, err := bobChannelNew.ProcessChanSyncMsg(bogusSyncMsg); err != ErrLocalDataLoss {
+		t.Fatalf("expected ErrLocalDataLoss, instead got %v", err)
+	}
+}
+
+// TestChanSyncCommitRetransmit tests that if a commitment signature, along
+// with the updates it covers, never reaches the remote party, then both are
+// retransmitted upon reconnection.
+func TestChanSyncCommitRetransmit(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	if err := aliceChannel.channelState.FullSync(); err != nil {
+		t.Fatalf("unable to sync alice's channel: %v", err)
+	}
+	if err := bobChannel.channelState.FullSync(); err != nil {
+		t.Fatalf("unable to sync bob's channel: %v", err)
+	}
+
+	// Alice adds an HTLC and signs a new commitment for Bob, but neither
+	// the HTLC nor the signature reach Bob as the connection is dropped.
+	var paymentPreimage [32]byte
+	copy(paymentPreimage[:], bytes.Repeat([]byte{4}, 32))
+	htlc := &lnwire.HTLCAddRequest{
+		ChannelPoint:     aliceChannel.ChannelPoint(),
+		RedemptionHashes: [][32]byte{fastsha256.Sum256(paymentPreimage[:])},
+		Amount:           lnwire.CreditsAmount(1e8),
+		Expiry:           uint32(5),
+	}
+	if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	aliceSig, bobIndex, err := aliceChannel.SignNextCommitment()
+	if err != nil {
+		t.Fatalf("unable to sign commitment: %v", err)
+	}
+	_, err = storeCommitDiff(aliceChannel, aliceSig, bobIndex,
+		[]lnwire.Message{htlc})
+	if err != nil {
+		t.Fatalf("unable to store commit diff: %v", err)
+	}
+
+	// Now fetch both of the channels from disk to simulate a
+	// reconnection.
+	aliceChannelNew, err := reloadChannel(aliceChannel)
+	if err != nil {
+		t.Fatalf("unable to create new channel: %v", err)
+	}
+	bobChannelNew, err := reloadChannel(bobChannel)
+	if err != nil {
+		t.Fatalf("unable to create new channel: %v", err)
+	}
+
+	// Bob has nothing to retransmit, while Alice should retransmit the
+	// HTLC followed by her signature as Bob reports he's still at his
+	// initial commitment.
+	bobRetransmit, err := bobChannelNew.ProcessChanSyncMsg(
+		aliceChannelNew.ChanSyncMsg())
+	if err != nil {
+		t.Fatalf("bob unable to process chan sync msg: %v", err)
+	}
+	if len(bobRetransmit) != 0 {
+		t.Fatalf("expected bob to retransmit no msgs, instead has %v",
+			len(bobRetransmit))
+	}
+	aliceRetransmit, err := aliceChannelNew.ProcessChanSyncMsg(
+		bobChannelNew.ChanSyncMsg())
+	if err != nil {
+		t.Fatalf("alice unable to process chan sync msg: %v", err)
+	}
+	if len(aliceRetransmit) != 2 {
+		t.Fatalf("expected alice to retransmit 2 msgs, instead has %v",
+			len(aliceRetransmit))
+	}
+	htlcMsg, ok := aliceRetransmit[0].(*lnwire.HTLCAddRequest)
+	if !ok {
+		t.Fatalf("expected htlc add, instead got %T", aliceRetransmit[0])
+	}
+	commitSig, ok := aliceRetransmit[1].(*lnwire.CommitSignature)
+	if !ok {
+		t.Fatalf("expected commit sig, instead got %T",
+			aliceRetransmit[1])
+	}
+
+	// As with a regular reconnection, both sides re-extend each other's
+	// revocation windows.
+	if err := initRevocationWindows(aliceChannelNew, bobChannelNew, 3); err != nil {
+		t.Fatalf("unable to init revocation windows: %v", err)
+	}
+
+	// Bob should accept the retransmitted HTLC and signature, and Alice
+	// should accept his revocation for his prior commitment.
+	if _, err := bobChannelNew.ReceiveHTLC(htlcMsg); err != nil {
+		t.Fatalf("bob unable to receive htlc: %v", err)
+	}
+	err = bobChannelNew.ReceiveNewCommitment(commitSig.CommitSig.Serialize(),
+		uint32(commitSig.LogIndex))
+	if err != nil {
+		t.Fatalf("bob unable to process alice's commitment: %v", err)
+	}
+	bobRevocation, err := bobChannelNew.RevokeCurrentCommitment()
+	if err != nil {
+		t.Fatalf("unable to revoke commitment: %v", err)
+	}
+	if _, err := aliceChannelNew.ReceiveRevocation(bobRevocation); err != nil {
+		t.Fatalf("alice unable to process bob's revocation: %v", err)
+	}
+	_, err = aliceChannelNew.channelState.FetchCommitDiff()
+	if err != channeldb.ErrNoCommitDiff {
+		t.Fatalf("expected ErrNoCommitDiff, instead got %v", err)
+	}
+
+	// Finally, Bob should be able to lock in the HTLC on Alice's
+	// commitment, after which both sides should have it in their state.
+	if err := forceStateTransition(bobChannelNew, aliceChannelNew); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+	if len(aliceChannelNew.channelState.Htlcs) != 1 {
+		t.Fatalf("alice should have 1 htlc, instead has %v",
+			len(aliceChannelNew.channelState.Htlcs))
+	}
+	if len(bobChannelNew.channelState.Htlcs) != 1 {
+		t.Fatalf("bob should have 1 htlc, instead has %v",
+			len(bobChannelNew.channelState.Htlcs))
+	}
+}
+
+// TestHTLCLimits tests that both AddHTLC and ReceiveHTLC enforce the limits
+// placed on the HTLC's accepted by the receiving party of each HTLC.
+func TestHTLCLimits(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	// Bob will accept at most two HTLC's, with a total value of 3000
+	// satoshis, each being at least 100 satoshis.
+	const (
+		maxHTLCs      = 2
+		maxInFlight   = btcutil.Amount(3000)
+		minHTLCAmount = btcutil.Amount(100)
+	)
+	aliceChannel.channelState.RemoteMaxAcceptedHTLCs = maxHTLCs
+	aliceChannel.channelState.RemoteMaxValueInFlight = maxInFlight
+	aliceChannel.channelState.RemoteHtlcMinimum = minHTLCAmount
+	bobChannel.channelState.LocalMaxAcceptedHTLCs = maxHTLCs
+	bobChannel.channelState.LocalMaxValueInFlight = maxInFlight
+	bobChannel.channelState.LocalHtlcMinimum = minHTLCAmount
+
+	newHTLC := func(i byte, amt btcutil.Amount) *lnwire.HTLCAddRequest {
+		preimage := bytes.Repeat([]byte{i}, 32)
+		return &lnwire.HTLCAddRequest{
+			RedemptionHashes: [][32]byte{fastsha256.Sum256(preimage)},
+			Amount:           lnwire.CreditsAmount(amt),
+			Expiry:           uint32(5),
+		}
+	}
+
+	// An HTLC below Bob's minimum should be rejected by both sides.
+	htlc := newHTLC(1, minHTLCAmount-1)
+	if _, err := aliceChannel.AddHTLC(htlc); err != ErrBelowHtlcMinimum {
+		t.Fatalf("expected ErrBelowHtlcMinimum, instead got %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != ErrBelowHtlcMinimum {
+		t.Fatalf("expected ErrBelowHtlcMinimum, instead got %v", err)
+	}
+
+	// Alice should be able to add two HTLC's within the limits.
+	for i := byte(2); i < 4; i++ {
+		htlc := newHTLC(i, 1000)
+		if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+			t.Fatalf("unable to add htlc: %v", err)
+		}
+		if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+			t.Fatalf("unable to receive htlc: %v", err)
+		}
+	}
+
+	// A third HTLC would exceed the max number of HTLC's Bob accepts,
+	// even though it's within the max value in flight.
+	htlc = newHTLC(4, 500)
+	if _, err := aliceChannel.AddHTLC(htlc); err != ErrMaxHTLCNumber {
+		t.Fatalf("expected ErrMaxHTLCNumber, instead got %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != ErrMaxHTLCNumber {
+		t.Fatalf("expected ErrMaxHTLCNumber, instead got %v", err)
+	}
+
+	// If Bob accepted more HTLC's, then the third HTLC would instead
+	// exceed the max value in flight.
+	aliceChannel.channelState.RemoteMaxAcceptedHTLCs = maxHTLCs + 1
+	bobChannel.channelState.LocalMaxAcceptedHTLCs = maxHTLCs + 1
+	htlc = newHTLC(5, 1001)
+	if _, err := aliceChannel.AddHTLC(htlc); err != ErrMaxPendingAmount {
+		t.Fatalf("expected ErrMaxPendingAmount, instead got %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != ErrMaxPendingAmount {
+		t.Fatalf("expected ErrMaxPendingAmount, instead got %v", err)
+	}
+
+	// The limits only apply to HTLC's offered to Bob, so Bob should still
+	// be able to add HTLC's to Alice.
+	htlc = newHTLC(6, minHTLCAmount-1)
+	if _, err := bobChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	if _, err := aliceChannel.ReceiveHTLC(htlc); err != nil {
+		t.Fatalf("unable to receive htlc: %v", err)
+	}
+}
+
+// mockSpendNotifier is a mock chain notifier which dispatches the spend of
+// any registered outpoint over a single channel controlled by the test.
+type mockSpendNotifier struct {
+	mockNotfier
+
+	spendChan chan *chainntnfs.SpendDetail
+}
+
+func (m *mockSpendNotifier) RegisterSpendNtfn(outpoint *wire.OutPoint) (*chainntnfs.SpendEvent, error) {
+	return &chainntnfs.SpendEvent{
+		Spend: m.spendChan,
+	}, nil
+}
+
+// TestBreachJusticeTx tests that the broadcast of a revoked commitment
+// transaction by the remote party is detected, and that the resulting justice
+// transaction validly sweeps every output of the revoked commitment.
+func TestBreachJusticeTx(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	paymentPreimage := bytes.Repeat([]byte{3}, 32)
+	paymentHash := fastsha256.Sum256(paymentPreimage)
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{paymentHash},
+		Amount:           lnwire.CreditsAmount(1e8),
+		Expiry:           uint32(5),
+	}
+
+	// Alice adds an HTLC to Bob, then both sides lock in the new HTLC.
+	if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	htlcIndex, err := bobChannel.ReceiveHTLC(htlc)
+	if err != nil {
+		t.Fatalf("unable to recv htlc: %v", err)
+	}
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+
+	// Bob's current commitment transaction, which includes the HTLC, is
+	// the state he'll later broadcast.
+	revokedCommit := bobChannel.channelState.OurCommitTx.Copy()
+
+	// Bob settles the HTLC, then both sides lock in the settle, revoking
+	// Bob's prior commitment.
+	var preimage [32]byte
+	copy(preimage[:], paymentPreimage)
+	if err := bobChannel.SettleHTLC(preimage, htlcIndex); err != nil {
+		t.Fatalf("bob unable to settle htlc: %v", err)
+	}
+	if err := aliceChannel.ReceiveHTLCSettle(preimage, htlcIndex); err != nil {
+		t.Fatalf("alice unable to accept settle: %v", err)
+	}
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+
+	// Load a new instance of Alice's channel from its persisted state,
+	// using a notifier which allows us to dispatch the spend of the
+	// funding output.
+	aliceKeyPriv, _ := btcec.PrivKeyFromBytes(btcec.S256(),
+		testWalletPrivKey)
+	notifier := &mockSpendNotifier{
+		spendChan: make(chan *chainntnfs.SpendDetail, 1),
+	}
+	aliceChannelNew, err := NewLightningChannel(&mockSigner{aliceKeyPriv},
+		nil, notifier, aliceChannel.channelState)
+	if err != nil {
+		t.Fatalf("unable to create new channel: %v", err)
+	}
+	defer aliceChannelNew.Stop()
+
+	// Bob now broadcasts his revoked commitment transaction, which should
+	// be detected by Alice as a breach of the channel contract.
+	notifier.spendChan <- &chainntnfs.SpendDetail{
+		SpendingTx: revokedCommit,
+	}
+
+	var retribution *BreachRetribution
+	select {
+	case retribution = <-aliceChannelNew.ContractBreach:
+	case <-time.After(time.Second * 5):
+		t.Fatalf("breach of revoked state not detected")
+	}
+
+	if retribution.RevokedStateNum != 1 {
+		t.Fatalf("revoked state num should be 1, instead is %v",
+			retribution.RevokedStateNum)
+	}
+	if len(retribution.HtlcRetributions) != 1 {
+		t.Fatalf("expected 1 htlc retribution, instead have %v",
+			len(retribution.HtlcRetributions))
+	}
+
+	// Using the retribution, Alice creates the justice transaction, which
+	// should spend every output of the revoked commitment.
+	const justiceFee = btcutil.Amount(10000)
+	sweepScript := bytes.Repeat([]byte{0}, 22)
+	justiceTx, err := CreateJusticeTx(retribution, aliceKeyPriv,
+		sweepScript, justiceFee)
+	if err != nil {
+		t.Fatalf("unable to create justice tx: %v", err)
+	}
+	if len(justiceTx.TxIn) != len(revokedCommit.TxOut) {
+		t.Fatalf("justice tx spends %v outputs, revoked commitment "+
+			"has %v", len(justiceTx.TxIn), len(revokedCommit.TxOut))
+	}
+
+	revokedHash := revokedCommit.TxSha()
+	var sweptAmt btcutil.Amount
+	for i, txIn := range justiceTx.TxIn {
+		prevOut := txIn.PreviousOutPoint
+		if !prevOut.Hash.IsEqual(&revokedHash) {
+			t.Fatalf("justice tx input %v doesn't spend the revoked "+
+				"commitment", i)
+		}
+		output := revokedCommit.TxOut[prevOut.Index]
+		sweptAmt += btcutil.Amount(output.Value)
+
+		vm, err := txscript.NewEngine(output.PkScript, justiceTx, i,
+			txscript.StandardVerifyFlags, nil, nil, output.Value)
+		if err != nil {
+			t.Fatalf("unable to create engine: %v", err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Fatalf("justice tx input %v invalid: %v", i, err)
+		}
+	}
+
+	if justiceTx.TxOut[0].Value != int64(sweptAmt-justiceFee) {
+		t.Fatalf("justice tx should sweep %v, instead sweeps %v",
+			sweptAmt-justiceFee, justiceTx.TxOut[0].Value)
+	}
+}
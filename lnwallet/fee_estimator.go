@@ -0,0 +1,167 @@
+package lnwallet
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/roasbeef/btcrpcclient"
+	"github.com/roasbeef/btcutil"
+)
+
+// FeeEstimator provides the ability to estimate on-chain transaction fees for
+// various combinations of transaction sizes and desired confirmation time
+// (measured by number of blocks).
+type FeeEstimator interface {
+	// EstimateFeePerByte takes in a target for the number of blocks until
+	// an initial confirmation and returns the estimated fee expressed in
+	// satoshis/byte.
+	EstimateFeePerByte(numBlocks uint32) (btcutil.Amount, error)
+
+	// Start signals the FeeEstimator to start any processes or goroutines
+	// it needs to perform its duty.
+	Start() error
+
+	// Stop stops any spawned goroutines and cleans up the resources used
+	// by the fee estimator.
+	Stop() error
+}
+
+// StaticFeeEstimator will return a static value for all fee calculation
+// requests. It is designed to be replaced by a proper fee calculation
+// implementation, and is useful within tests, or in the case that a dynamic
+// source of fee estimates isn't available.
+type StaticFeeEstimator struct {
+	// FeeRate is the static fee rate in satoshis-per-byte that will be
+	// returned by this fee estimator.
+	FeeRate btcutil.Amount
+}
+
+// EstimateFeePerByte will return a static value for fee calculations.
+//
+// NOTE: This method is part of the FeeEstimator interface.
+func (e *StaticFeeEstimator) EstimateFeePerByte(numBlocks uint32) (btcutil.Amount, error) {
+	return e.FeeRate, nil
+}
+
+// Start signals the FeeEstimator to start any processes or goroutines
+// it needs to perform its duty.
+//
+// NOTE: This method is part of the FeeEstimator interface.
+func (e *StaticFeeEstimator) Start() error {
+	return nil
+}
+
+// Stop stops any spawned goroutines and cleans up the resources used
+// by the fee estimator.
+//
+// NOTE: This method is part of the FeeEstimator interface.
+func (e *StaticFeeEstimator) Stop() error {
+	return nil
+}
+
+// A compile-time assertion to ensure that StaticFeeEstimator implements the
+// FeeEstimator interface.
+var _ FeeEstimator = (*StaticFeeEstimator)(nil)
+
+// BtcdFeeEstimator is an implementation of the FeeEstimator interface backed
+// by the RPC interface of an active btcd node. This implementation will proxy
+// any fee estimation requests to btcd's `estimatefee` RPC call. If btcd is
+// unable to produce an estimate, for instance due to an insufficient amount
+// of data, then the fallback fee rate is returned.
+type BtcdFeeEstimator struct {
+	// fallBackFeeRate is the fall back fee rate in satoshis per byte that
+	// is returned if the fee estimator does not yet have enough data to
+	// actually produce fee estimates.
+	fallBackFeeRate btcutil.Amount
+
+	btcdConn *btcrpcclient.Client
+}
+
+// NewBtcdFeeEstimator creates a new BtcdFeeEstimator given a fully populated
+// rpc config that is able to successfully connect and authenticate with the
+// btcd node, and also a fall back fee rate. The fallback fee rate is used in
+// the occasion that the estimator has insufficient data, or returns zero for a
+// fee estimate.
+func NewBtcdFeeEstimator(rpcConfig btcrpcclient.ConnConfig,
+	fallBackFeeRate btcutil.Amount) (*BtcdFeeEstimator, error) {
+
+	rpcConfig.DisableConnectOnNew = true
+	rpcConfig.DisableAutoReconnect = false
+	chainConn, err := btcrpcclient.New(&rpcConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BtcdFeeEstimator{
+		fallBackFeeRate: fallBackFeeRate,
+		btcdConn:        chainConn,
+	}, nil
+}
+
+// Start signals the FeeEstimator to start any processes or goroutines
+// it needs to perform its duty.
+//
+// NOTE: This method is part of the FeeEstimator interface.
+func (b *BtcdFeeEstimator) Start() error {
+	return b.btcdConn.Connect(20)
+}
+
+// Stop stops any spawned goroutines and cleans up the resources used
+// by the fee estimator.
+//
+// NOTE: This method is part of the FeeEstimator interface.
+func (b *BtcdFeeEstimator) Stop() error {
+	b.btcdConn.Shutdown()
+
+	return nil
+}
+
+// EstimateFeePerByte takes in a target for the number of blocks until an
+// initial confirmation and returns the estimated fee expressed in
+// satoshis/byte.
+//
+// NOTE: This method is part of the FeeEstimator interface.
+func (b *BtcdFeeEstimator) EstimateFeePerByte(numBlocks uint32) (btcutil.Amount, error) {
+	// The estimatefee call returns the estimated fee rate in BTC/kB, or
+	// -1 if btcd doesn't yet have enough data to produce an estimate.
+	params := []json.RawMessage{
+		json.RawMessage(strconv.FormatUint(uint64(numBlocks), 10)),
+	}
+	resp, err := b.btcdConn.RawRequest("estimatefee", params)
+	if err != nil {
+		return 0, err
+	}
+
+	var btcPerKB float64
+	if err := json.Unmarshal(resp, &btcPerKB); err != nil {
+		return 0, err
+	}
+
+	if btcPerKB <= 0 {
+		walletLog.Debugf("Unable to estimate fee for %v blocks, using "+
+			"fall back fee rate of %v sat/byte", numBlocks,
+			int64(b.fallBackFeeRate))
+		return b.fallBackFeeRate, nil
+	}
+
+	satPerKB, err := btcutil.NewAmount(btcPerKB)
+	if err != nil {
+		return 0, err
+	}
+
+	// Convert the rate to sat/byte, ensuring that we never return a zero
+	// fee rate.
+	satPerByte := satPerKB / 1000
+	if satPerByte == 0 {
+		satPerByte = 1
+	}
+
+	walletLog.Debugf("Returning %v sat/byte for conf target of %v",
+		int64(satPerByte), numBlocks)
+
+	return satPerByte, nil
+}
+
+// A compile-time assertion to ensure that BtcdFeeEstimator implements the
+// FeeEstimator interface.
+var _ FeeEstimator = (*BtcdFeeEstimator)(nil)
@@ -16,6 +16,7 @@ import (
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/roasbeef/btcd/chaincfg"
 	"github.com/roasbeef/btcutil/txsort"
 	_ "github.com/roasbeef/btcwallet/walletdb/bdb"
@@ -57,6 +58,10 @@ var (
 	// The number of confirmations required to consider any created channel
 	// open.
 	numReqConfs = uint16(1)
+
+	// testFeePerKb is the fee rate, in satoshis/KB, negotiated for all
+	// channels created within the tests.
+	testFeePerKb = btcutil.Amount(10000)
 )
 
 // assertProperBalance asserts than the total value of the unspent outputs
@@ -121,6 +126,9 @@ func (b *bobNode) Contribution(aliceCommitKey *btcec.PublicKey) *lnwallet.Channe
 		DeliveryAddress: b.deliveryAddress,
 		RevocationKey:   revokeKey,
 		CsvDelay:        b.delay,
+
+		MaxAcceptedHTLCs: lnwire.MaxHTLCNumber,
+		MaxValueInFlight: b.fundingAmt,
 	}
 }
 
@@ -135,6 +143,9 @@ func (b *bobNode) SingleContribution(aliceCommitKey *btcec.PublicKey) *lnwallet.
 		DeliveryAddress: b.deliveryAddress,
 		RevocationKey:   revokeKey,
 		CsvDelay:        b.delay,
+
+		MaxAcceptedHTLCs: lnwire.MaxHTLCNumber,
+		MaxValueInFlight: b.fundingAmt,
 	}
 }
 
@@ -351,7 +362,7 @@ func testDualFundingReservationWorkflow(miner *rpctest.Harness, wallet *lnwallet
 	// Bob initiates a channel funded with 5 BTC for each side, so 10
 	// BTC total. He also generates 2 BTC in change.
 	chanReservation, err := wallet.InitChannelReservation(fundingAmount*2,
-		fundingAmount, bobNode.id, numReqConfs, 4)
+		fundingAmount, bobNode.id, numReqConfs, 4, testFeePerKb)
 	if err != nil {
 		t.Fatalf("unable to initialize funding reservation: %v", err)
 	}
@@ -470,7 +481,7 @@ func testDualFundingReservationWorkflow(miner *rpctest.Harness, wallet *lnwallet
 
 	// Now that the channel is open, execute a cooperative closure of the
 	// now open channel.
-	aliceCloseSig, _, err := lnc.InitCooperativeClose()
+	aliceCloseSig, _, err := lnc.InitCooperativeClose(testFeePerKb)
 	if err != nil {
 		t.Fatalf("unable to init cooperative closure: %v", err)
 	}
@@ -485,7 +496,7 @@ func testDualFundingReservationWorkflow(miner *rpctest.Harness, wallet *lnwallet
 	bobCloseTx := lnwallet.CreateCooperativeCloseTx(fundingTxIn,
 		chanInfo.RemoteBalance, chanInfo.LocalBalance,
 		lnc.RemoteDeliveryScript, lnc.LocalDeliveryScript,
-		false)
+		false, lnwallet.CooperativeCloseFee(testFeePerKb))
 	bobSig, err := bobNode.signCommitTx(bobCloseTx, redeemScript, int64(lnc.Capacity))
 	if err != nil {
 		t.Fatalf("unable to generate bob's signature for closing tx: %v", err)
@@ -503,13 +514,50 @@ func testDualFundingReservationWorkflow(miner *rpctest.Harness, wallet *lnwallet
 	}
 }
 
+func testDualFundingResponderReservation(miner *rpctest.Harness,
+	wallet *lnwallet.LightningWallet, t *testing.T) {
+
+	// Bob initiates a dual funded channel with a capacity of 10 BTC,
+	// requesting that we contribute 4 BTC of it.
+	capacity := btcutil.Amount(10 * 1e8)
+	ourAmt := btcutil.Amount(4 * 1e8)
+	chanReservation, err := wallet.InitDualFundResponderReservation(
+		capacity, ourAmt, testHdSeed, numReqConfs, 4, testFeePerKb)
+	if err != nil {
+		t.Fatalf("unable to initialize funding reservation: %v", err)
+	}
+
+	// As the initiator pays the commitment fee, our balance should be
+	// exactly the amount we contribute, and the initiator's balance the
+	// remainder of the capacity.
+	ourContribution := chanReservation.OurContribution()
+	if ourContribution.FundingAmount != ourAmt {
+		t.Fatalf("our balance should be %v, is instead %v", ourAmt,
+			ourContribution.FundingAmount)
+	}
+	theirContribution := chanReservation.TheirContribution()
+	if theirContribution.FundingAmount != capacity-ourAmt {
+		t.Fatalf("their balance should be %v, is instead %v",
+			capacity-ourAmt, theirContribution.FundingAmount)
+	}
+	if len(ourContribution.Inputs) == 0 {
+		t.Fatalf("outputs for funding tx not properly selected")
+	}
+
+	// Cancel the reservation in order to free the selected outputs for
+	// the remaining tests.
+	if err := chanReservation.Cancel(); err != nil {
+		t.Fatalf("unable to cancel reservation: %v", err)
+	}
+}
+
 func testFundingTransactionLockedOutputs(miner *rpctest.Harness,
 	wallet *lnwallet.LightningWallet, t *testing.T) {
 
 	// Create a single channel asking for 16 BTC total.
 	fundingAmount := btcutil.Amount(8 * 1e8)
 	_, err := wallet.InitChannelReservation(fundingAmount, fundingAmount,
-		testHdSeed, numReqConfs, 4)
+		testHdSeed, numReqConfs, 4, testFeePerKb)
 	if err != nil {
 		t.Fatalf("unable to initialize funding reservation 1: %v", err)
 	}
@@ -519,7 +567,7 @@ func testFundingTransactionLockedOutputs(miner *rpctest.Harness,
 	// that aren't locked, so this should fail.
 	amt := btcutil.Amount(900 * 1e8)
 	failedReservation, err := wallet.InitChannelReservation(amt, amt,
-		testHdSeed, numReqConfs, 4)
+		testHdSeed, numReqConfs, 4, testFeePerKb)
 	if err == nil {
 		t.Fatalf("not error returned, should fail on coin selection")
 	}
@@ -537,14 +585,14 @@ func testFundingCancellationNotEnoughFunds(miner *rpctest.Harness,
 	// Create a reservation for 44 BTC.
 	fundingAmount := btcutil.Amount(44 * 1e8)
 	chanReservation, err := wallet.InitChannelReservation(fundingAmount,
-		fundingAmount, testHdSeed, numReqConfs, 4)
+		fundingAmount, testHdSeed, numReqConfs, 4, testFeePerKb)
 	if err != nil {
 		t.Fatalf("unable to initialize funding reservation: %v", err)
 	}
 
 	// Attempt to create another channel with 44 BTC, this should fail.
 	_, err = wallet.InitChannelReservation(fundingAmount,
-		fundingAmount, testHdSeed, numReqConfs, 4)
+		fundingAmount, testHdSeed, numReqConfs, 4, testFeePerKb)
 	if _, ok := err.(*lnwallet.ErrInsufficientFunds); !ok {
 		t.Fatalf("coin selection succeded should have insufficient funds: %v",
 			err)
@@ -574,7 +622,7 @@ func testFundingCancellationNotEnoughFunds(miner *rpctest.Harness,
 
 	// Request to fund a new channel should now succeeed.
 	_, err = wallet.InitChannelReservation(fundingAmount, fundingAmount,
-		testHdSeed, numReqConfs, 4)
+		testHdSeed, numReqConfs, 4, testFeePerKb)
 	if err != nil {
 		t.Fatalf("unable to initialize funding reservation: %v", err)
 	}
@@ -609,7 +657,7 @@ func testSingleFunderReservationWorkflowInitiator(miner *rpctest.Harness,
 	// Initialize a reservation for a channel with 4 BTC funded solely by us.
 	fundingAmt := btcutil.Amount(4 * 1e8)
 	chanReservation, err := lnwallet.InitChannelReservation(fundingAmt,
-		fundingAmt, bobNode.id, numReqConfs, 4)
+		fundingAmt, bobNode.id, numReqConfs, 4, testFeePerKb)
 	if err != nil {
 		t.Fatalf("unable to init channel reservation: %v", err)
 	}
@@ -742,7 +790,7 @@ func testSingleFunderReservationWorkflowResponder(miner *rpctest.Harness,
 	// contribution and the necessary resources.
 	fundingAmt := btcutil.Amount(0)
 	chanReservation, err := wallet.InitChannelReservation(capacity,
-		fundingAmt, bobNode.id, numReqConfs, 4)
+		fundingAmt, bobNode.id, numReqConfs, 4, testFeePerKb)
 	if err != nil {
 		t.Fatalf("unable to init channel reservation: %v", err)
 	}
@@ -880,6 +928,7 @@ func testFundingTransactionTxFees(miner *rpctest.Harness, lnwallet *lnwallet.Lig
 
 var walletTests = []func(miner *rpctest.Harness, w *lnwallet.LightningWallet, test *testing.T){
 	testDualFundingReservationWorkflow,
+	testDualFundingResponderReservation,
 	testSingleFunderReservationWorkflowInitiator,
 	testSingleFunderReservationWorkflowResponder,
 	testFundingTransactionLockedOutputs,
@@ -1,9 +1,11 @@
 package lnwallet
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
@@ -48,6 +50,18 @@ type ChannelContribution struct {
 	// CsvDelay The delay (in blocks) to be used for the pay-to-self output
 	// in this party's version of the commitment transaction.
 	CsvDelay uint32
+
+	// MaxAcceptedHTLCs is the maximum number of HTLC's this party will
+	// accept within their version of the commitment transaction at once.
+	MaxAcceptedHTLCs uint16
+
+	// MaxValueInFlight is the maximum total value of outstanding HTLC's
+	// this party will accept within their version of the commitment
+	// transaction at once.
+	MaxValueInFlight btcutil.Amount
+
+	// HtlcMinimum is the smallest HTLC this party will accept.
+	HtlcMinimum btcutil.Amount
 }
 
 // InputScripts represents any script inputs required to redeem a previous
@@ -136,36 +150,35 @@ type ChannelReservation struct {
 // lnwallet.InitChannelReservation interface.
 func NewChannelReservation(capacity, fundingAmt btcutil.Amount, minFeeRate btcutil.Amount,
 	wallet *LightningWallet, id uint64, numConfs uint16) *ChannelReservation {
-	var ourBalance btcutil.Amount
-	var theirBalance btcutil.Amount
-
-	if fundingAmt == 0 {
-		ourBalance = 0
-		theirBalance = capacity - commitFee
-	} else {
-		// TODO(roasbeef): need to rework fee structure in general and
-		// also when we "unlock" dual funder within the daemon
-		if capacity == fundingAmt+commitFee { // Single funder
-			ourBalance = capacity - commitFee
-		} else {
-			ourBalance = fundingAmt - commitFee
-		}
-		theirBalance = capacity - fundingAmt - commitFee
-	}
-
+	// The fee for the initial commitment transaction is paid by the
+	// initiator of the channel, and is already accounted for within the
+	// passed capacity. As a result, our balance is exactly the amount we
+	// contribute, with the remainder, minus the commitment fee, belonging
+	// to the remote party. This yields a consistent view of the balances
+	// from both sides of a single or dual funded channel.
+	// TODO(roasbeef): need to rework fee structure in general
+	ourBalance := fundingAmt
+	theirBalance := capacity - fundingAmt - commitFee
+
+	// Until CommitConstraints is called, we'll accept the maximum number
+	// of HTLC's permitted, up to the full capacity of the channel.
 	return &ChannelReservation{
 		ourContribution: &ChannelContribution{
-			FundingAmount: ourBalance,
+			FundingAmount:    ourBalance,
+			MaxAcceptedHTLCs: lnwire.MaxHTLCNumber,
+			MaxValueInFlight: capacity,
 		},
 		theirContribution: &ChannelContribution{
 			FundingAmount: theirBalance,
 		},
 		partialState: &channeldb.OpenChannel{
-			Capacity:     capacity,
-			OurBalance:   ourBalance,
-			TheirBalance: theirBalance,
-			MinFeePerKb:  minFeeRate,
-			Db:           wallet.ChannelDB,
+			Capacity:              capacity,
+			OurBalance:            ourBalance,
+			TheirBalance:          theirBalance,
+			MinFeePerKb:           minFeeRate,
+			LocalMaxAcceptedHTLCs: lnwire.MaxHTLCNumber,
+			LocalMaxValueInFlight: capacity,
+			Db:                    wallet.ChannelDB,
 		},
 		numConfsToOpen: numConfs,
 		reservationID:  id,
@@ -174,6 +187,36 @@ func NewChannelReservation(capacity, fundingAmt btcutil.Amount, minFeeRate btcut
 	}
 }
 
+// CommitConstraints sets the limits we'll place on the HTLC's the remote party
+// may offer us within the channel: the maximum number of outstanding HTLC's,
+// their maximum total value, and the smallest HTLC we'll accept. These limits
+// are included within our contribution, so this method should be called
+// before our contribution is sent to the remote party.
+func (r *ChannelReservation) CommitConstraints(maxAcceptedHTLCs uint16,
+	maxValueInFlight, htlcMinimum btcutil.Amount) error {
+
+	r.Lock()
+	defer r.Unlock()
+
+	if maxAcceptedHTLCs == 0 || maxAcceptedHTLCs > lnwire.MaxHTLCNumber {
+		return fmt.Errorf("max accepted HTLC's must be in the range "+
+			"[1, %v]", lnwire.MaxHTLCNumber)
+	}
+	if maxValueInFlight < 0 || htlcMinimum < 0 {
+		return fmt.Errorf("HTLC limits must be non-negative")
+	}
+
+	r.ourContribution.MaxAcceptedHTLCs = maxAcceptedHTLCs
+	r.ourContribution.MaxValueInFlight = maxValueInFlight
+	r.ourContribution.HtlcMinimum = htlcMinimum
+
+	r.partialState.LocalMaxAcceptedHTLCs = maxAcceptedHTLCs
+	r.partialState.LocalMaxValueInFlight = maxValueInFlight
+	r.partialState.LocalHtlcMinimum = htlcMinimum
+
+	return nil
+}
+
 // OurContribution returns the wallet's fully populated contribution to the
 // pending payment channel. See 'ChannelContribution' for further details
 // regarding the contents of a contribution.
@@ -207,7 +250,10 @@ func (r *ChannelReservation) ProcessContribution(theirContribution *ChannelContr
 // ProcessSingleContribution verifies, and records the initiator's contribution
 // to this pending single funder channel. Internally, no further action is
 // taken other than recording the initiator's contribution to the single funder
-// channel.
+// channel. The responder to a dual funder workflow also uses this method in
+// order to derive the revocation key for its initial commitment transaction
+// before the initiator's full contribution, including its revocation key, is
+// known.
 func (r *ChannelReservation) ProcessSingleContribution(theirContribution *ChannelContribution) error {
 	errChan := make(chan error, 1)
 
@@ -352,6 +398,14 @@ func (r *ChannelReservation) FundingOutpoint() *wire.OutPoint {
 	return r.partialState.FundingOutpoint
 }
 
+// NumConfsToOpen returns the number of confirmations the funding transaction
+// requires before the channel is considered open.
+func (r *ChannelReservation) NumConfsToOpen() uint16 {
+	r.RLock()
+	defer r.RUnlock()
+	return r.numConfsToOpen
+}
+
 // Cancel abandons this channel reservation. This method should be called in
 // the scenario that communications with the counterparty break down. Upon
 // cancellation, all resources previously reserved for this pending payment
@@ -3,6 +3,7 @@ package lnwallet
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"math/big"
 
@@ -18,9 +19,29 @@ import (
 var (
 	// TODO(roasbeef): remove these and use the one's defined in txscript
 	// within testnet-L.
-	SequenceLockTimeSeconds      = uint32(1 << 22)
-	SequenceLockTimeMask         = uint32(0x0000ffff)
-	OP_CHECKSEQUENCEVERIFY  byte = txscript.OP_NOP3
+	SequenceLockTimeSeconds       = uint32(1 << 22)
+	SequenceLockTimeMask          = uint32(0x0000ffff)
+	SequenceLockTimeDisabled      = uint32(1 << 31)
+	OP_CHECKSEQUENCEVERIFY   byte = txscript.OP_NOP3
+)
+
+const (
+	// StateHintSize is the total number of bytes used between the sequence
+	// number and locktime of the commitment transaction used to encode a
+	// hint to the state number of a particular commitment transaction.
+	StateHintSize = 6
+
+	// maxStateHint is the maximum state number we're able to encode using
+	// StateHintSize bytes amongst the sequence number and locktime fields
+	// of the commitment transaction.
+	maxStateHint uint64 = (1 << 48) - 1
+
+	// timelockShift is a value which is OR'd into the lock time of each
+	// commitment transaction carrying a state hint. Setting this bit
+	// ensures the lock time is interpreted as a (long since passed)
+	// timestamp, so the hint never prevents the commitment transaction
+	// from being included within a block.
+	timelockShift = uint32(1 << 29)
 )
 
 // witnessScriptHash generates a pay-to-witness-script-hash public key script
@@ -227,12 +248,12 @@ func senderHTLCScript(absoluteTimeout, relativeTimeout uint32, senderKey,
 // the commitment transaction's revocation hash, and a valid signature under
 // the receiver's public key.
 func senderHtlcSpendRevoke(commitScript []byte, outputAmt btcutil.Amount,
-	reciverKey *btcec.PrivateKey, sweepTx *wire.MsgTx,
+	reciverKey *btcec.PrivateKey, sweepTx *wire.MsgTx, inputIndex int,
 	revokePreimage []byte) (wire.TxWitness, error) {
 
 	hashCache := txscript.NewTxSigHashes(sweepTx)
 	sweepSig, err := txscript.RawTxInWitnessSignature(
-		sweepTx, hashCache, 0, int64(outputAmt), commitScript,
+		sweepTx, hashCache, inputIndex, int64(outputAmt), commitScript,
 		txscript.SigHashAll, reciverKey)
 	if err != nil {
 		return nil, err
@@ -462,13 +483,13 @@ func receiverHtlcSpendRedeem(commitScript []byte, outputAmt btcutil.Amount,
 // pending funds in the case that the receiver broadcasts this revoked
 // commitment transaction.
 func receiverHtlcSpendRevoke(commitScript []byte, outputAmt btcutil.Amount,
-	senderKey *btcec.PrivateKey, sweepTx *wire.MsgTx,
+	senderKey *btcec.PrivateKey, sweepTx *wire.MsgTx, inputIndex int,
 	revokePreimage []byte) (wire.TxWitness, error) {
 
 	// TODO(roasbeef): move sig generate outside func, or just factor out?
 	hashCache := txscript.NewTxSigHashes(sweepTx)
 	sweepSig, err := txscript.RawTxInWitnessSignature(
-		sweepTx, hashCache, 0, int64(outputAmt), commitScript,
+		sweepTx, hashCache, inputIndex, int64(outputAmt), commitScript,
 		txscript.SigHashAll, senderKey)
 	if err != nil {
 		return nil, err
@@ -626,15 +647,49 @@ func CommitSpendTimeout(signer Signer, signDesc *SignDescriptor,
 	return witnessStack, nil
 }
 
+// HtlcSpendTimeout constructs a valid witness allowing the sender of an HTLC
+// to recover the pending funds from an HTLC output on their own commitment
+// transaction after the absolute, then relative locktime period. In order to
+// properly spend the output, the lock time of the sweeping transaction MUST
+// be set to at least the absolute timeout of the HTLC, and the sequence
+// number of the target input MUST be set according to the relative timeout
+// within the redeem script. Additionally, OP_CSV requires that the version of
+// the transaction spending a pkscript with OP_CSV within it *must* be >= 2.
+func HtlcSpendTimeout(signer Signer, signDesc *SignDescriptor,
+	sweepTx *wire.MsgTx) (wire.TxWitness, error) {
+
+	// Ensure the transaction version supports the validation of sequence
+	// locks and CSV semantics.
+	if sweepTx.Version < 2 {
+		return nil, fmt.Errorf("version of passed transaction MUST "+
+			"be >= 2, not %v", sweepTx.Version)
+	}
+
+	sweepSig, err := signer.SignOutputRaw(sweepTx, signDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	// We place a zero as the first item of the evaluated witness stack in
+	// order to force Script execution to the HTLC timeout clause.
+	witnessStack := wire.TxWitness(make([][]byte, 3))
+	witnessStack[0] = append(sweepSig, byte(txscript.SigHashAll))
+	witnessStack[1] = []byte{0}
+	witnessStack[2] = signDesc.RedeemScript
+
+	return witnessStack, nil
+}
+
 // commitSpendRevoke constructs a valid witness allowing a node to sweep the
 // settled output of a malicious counter-party who broadcasts a revoked
 // commitment trransaction.
 func commitSpendRevoke(commitScript []byte, outputAmt btcutil.Amount,
-	revocationPriv *btcec.PrivateKey, sweepTx *wire.MsgTx) (wire.TxWitness, error) {
+	revocationPriv *btcec.PrivateKey, sweepTx *wire.MsgTx,
+	inputIndex int) (wire.TxWitness, error) {
 
 	hashCache := txscript.NewTxSigHashes(sweepTx)
 	sweepSig, err := txscript.RawTxInWitnessSignature(
-		sweepTx, hashCache, 0, int64(outputAmt), commitScript,
+		sweepTx, hashCache, inputIndex, int64(outputAmt), commitScript,
 		txscript.SigHashAll, revocationPriv)
 	if err != nil {
 		return nil, err
@@ -653,12 +708,13 @@ func commitSpendRevoke(commitScript []byte, outputAmt btcutil.Amount,
 // commitSpendNoDelay constructs a valid witness allowing a node to spend their
 // settled no-delay output on the counter-party's commitment transaction.
 func commitSpendNoDelay(commitScript []byte, outputAmt btcutil.Amount,
-	commitPriv *btcec.PrivateKey, sweepTx *wire.MsgTx) (wire.TxWitness, error) {
+	commitPriv *btcec.PrivateKey, sweepTx *wire.MsgTx,
+	inputIndex int) (wire.TxWitness, error) {
 
 	// This is just a regular p2wkh spend which looks something like:
 	//  * witness: <sig> <pubkey>
 	hashCache := txscript.NewTxSigHashes(sweepTx)
-	witness, err := txscript.WitnessScript(sweepTx, hashCache, 0,
+	witness, err := txscript.WitnessScript(sweepTx, hashCache, inputIndex,
 		int64(outputAmt), commitScript, txscript.SigHashAll,
 		commitPriv, true)
 	if err != nil {
@@ -758,3 +814,94 @@ func deriveElkremRoot(elkremDerivationRoot *btcec.PrivateKey,
 
 	return elkremRoot
 }
+
+// deriveStateHintObfuscator derives the bytes to be used for obfuscating the
+// state hints encoded within the commitment transactions of a channel. The
+// obfuscator is the first StateHintSize bytes of the sha256 of both parties'
+// commitment keys, concatenated in lexicographical order. As the ordering is
+// canonical, both sides of the channel arrive at the same obfuscator, while
+// outside observers are unable to recover the state number.
+func deriveStateHintObfuscator(key1, key2 *btcec.PublicKey) [StateHintSize]byte {
+	k1 := key1.SerializeCompressed()
+	k2 := key2.SerializeCompressed()
+	if bytes.Compare(k1, k2) > 0 {
+		k1, k2 = k2, k1
+	}
+
+	h := sha256.New()
+	h.Write(k1)
+	h.Write(k2)
+	sha := h.Sum(nil)
+
+	var obfuscator [StateHintSize]byte
+	copy(obfuscator[:], sha[:])
+
+	return obfuscator
+}
+
+// SetStateNumHint encodes the current state number within the passed
+// commitment transaction by re-purposing the sequence and lock time fields of
+// the commitment transaction. The state number is encoded using 48 bits: the
+// lower 24 bits of the lock time hold the lower 24 bits of the obfuscated
+// state number, and the lower 24 bits of the sequence field hold the upper 24
+// bits. Before encoding, the obfuscator is XOR'd against the state number in
+// order to hide the exact state number from the PoV of outside parties.
+//
+// NOTE: The commitment transaction MUST have exactly one input, and the
+// input MUST NOT be shared with any other transaction as the sequence number
+// is mutated in place.
+func SetStateNumHint(commitTx *wire.MsgTx, stateNum uint64,
+	obfuscator [StateHintSize]byte) error {
+
+	// With the current schema we are only able able to encode state num
+	// hints up to 2^48. Therefore if the passed height is greater than our
+	// state hint ceiling, then exit early.
+	if stateNum > maxStateHint {
+		return fmt.Errorf("unable to encode state, %v is greater "+
+			"than the max state num of %v", stateNum, maxStateHint)
+	}
+
+	if len(commitTx.TxIn) != 1 {
+		return fmt.Errorf("commitment tx must have exactly 1 input, "+
+			"instead has %v", len(commitTx.TxIn))
+	}
+
+	// Convert the obfuscator into a uint64, then XOR that against the
+	// target state number in order to obfuscate the state number of the
+	// commitment transaction in the case that it's broadcast on-chain.
+	var obfs [8]byte
+	copy(obfs[2:], obfuscator[:])
+	xorInt := binary.BigEndian.Uint64(obfs[:])
+
+	stateNum = stateNum ^ xorInt
+
+	// Set the highest bit of the sequence number in order to disable any
+	// sequence lock semantics, and shift the lock time into the range of
+	// past timestamps.
+	commitTx.TxIn[0].Sequence = uint32(stateNum>>24) | SequenceLockTimeDisabled
+	commitTx.LockTime = uint32(stateNum&0xFFFFFF) | timelockShift
+
+	return nil
+}
+
+// GetStateNumHint recovers the current state number given a commitment
+// transaction which has the state number encoded within its sequence number
+// and lock time fields. The obfuscator passed in MUST be the same as the
+// obfuscator used to encode the state hint via SetStateNumHint.
+func GetStateNumHint(commitTx *wire.MsgTx, obfuscator [StateHintSize]byte) uint64 {
+	// Convert the obfuscator into a uint64, this will be used to
+	// de-obfuscate the final recovered state number.
+	var obfs [8]byte
+	copy(obfs[2:], obfuscator[:])
+	xorInt := binary.BigEndian.Uint64(obfs[:])
+
+	// Retrieve the state hint from the sequence number and locktime of
+	// the transaction, masking off the bits used to disable sequence
+	// locks and to shift the lock time.
+	stateNumXor := uint64(commitTx.TxIn[0].Sequence&0xFFFFFF) << 24
+	stateNumXor |= uint64(commitTx.LockTime & 0xFFFFFF)
+
+	// Finally, to obtain the final state number, we XOR by the obfuscator
+	// value to de-obfuscate the state number.
+	return stateNumXor ^ xorInt
+}
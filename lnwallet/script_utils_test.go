@@ -111,7 +111,7 @@ func TestCommitmentSpendValidation(t *testing.T) {
 	// transaction after it's been revoked.
 	revokePrivKey := DeriveRevocationPrivKey(bobKeyPriv, revocationPreimage)
 	bobWitnessSpend, err := commitSpendRevoke(delayScript, channelBalance,
-		revokePrivKey, sweepTx)
+		revokePrivKey, sweepTx, 0)
 	if err != nil {
 		t.Fatalf("unable to generate revocation witness: %v", err)
 	}
@@ -133,7 +133,7 @@ func TestCommitmentSpendValidation(t *testing.T) {
 		t.Fatalf("unable to create bob p2wkh script: %v", err)
 	}
 	bobRegularSpend, err := commitSpendNoDelay(bobScriptp2wkh,
-		channelBalance, bobKeyPriv, sweepTx)
+		channelBalance, bobKeyPriv, sweepTx, 0)
 	if err != nil {
 		t.Fatalf("unable to create bob regular spend: %v", err)
 	}
@@ -276,7 +276,7 @@ func TestHTLCSenderSpendValidation(t *testing.T) {
 			// TODO(roasbeef): test invalid revoke
 			makeWitnessTestCase(t, func() (wire.TxWitness, error) {
 				return senderHtlcSpendRevoke(htlcScript, paymentAmt,
-					bobKeyPriv, sweepTx,
+					bobKeyPriv, sweepTx, 0,
 					revokePreimage)
 			}),
 			true,
@@ -477,7 +477,7 @@ func TestHTLCReceiverSpendValidation(t *testing.T) {
 			// revoke w/ sig
 			makeWitnessTestCase(t, func() (wire.TxWitness, error) {
 				return receiverHtlcSpendRevoke(htlcScript, paymentAmt,
-					aliceKeyPriv, sweepTx, revokePreimage[:],
+					aliceKeyPriv, sweepTx, 0, revokePreimage[:],
 				)
 			}),
 			true,
@@ -536,3 +536,53 @@ func TestHTLCReceiverSpendValidation(t *testing.T) {
 		}
 	}
 }
+
+// TestCommitTxStateHint tests that the state number of a commitment
+// transaction can be properly encoded within, and recovered from the
+// commitment transaction's sequence number and lock time.
+func TestCommitTxStateHint(t *testing.T) {
+	_, alicePub := btcec.PrivKeyFromBytes(btcec.S256(), testWalletPrivKey)
+	_, bobPub := btcec.PrivKeyFromBytes(btcec.S256(), bobsPrivKey)
+
+	// The obfuscator should be identical regardless of the order in which
+	// the keys are passed in.
+	obfuscator := deriveStateHintObfuscator(alicePub, bobPub)
+	if obfuscator != deriveStateHintObfuscator(bobPub, alicePub) {
+		t.Fatalf("state hint obfuscators don't match")
+	}
+
+	stateNums := []uint64{0, 1, 1000, 1 << 24, maxStateHint}
+	for _, stateNum := range stateNums {
+		commitTx := wire.NewMsgTx()
+		commitTx.AddTxIn(&wire.TxIn{})
+
+		if err := SetStateNumHint(commitTx, stateNum, obfuscator); err != nil {
+			t.Fatalf("unable to set state num %v: %v", stateNum, err)
+		}
+
+		// The lock time should always be interpreted as a timestamp,
+		// and the sequence number should never signal a relative
+		// lock-time.
+		if commitTx.LockTime < txscript.LockTimeThreshold {
+			t.Fatalf("lock time %v should be a timestamp",
+				commitTx.LockTime)
+		}
+		if commitTx.TxIn[0].Sequence&SequenceLockTimeDisabled == 0 {
+			t.Fatalf("sequence %v should disable relative lock-time",
+				commitTx.TxIn[0].Sequence)
+		}
+
+		extractedNum := GetStateNumHint(commitTx, obfuscator)
+		if extractedNum != stateNum {
+			t.Fatalf("state hint mismatch: expected %v, got %v",
+				stateNum, extractedNum)
+		}
+	}
+
+	// State numbers which can't fit within the hint should be rejected.
+	commitTx := wire.NewMsgTx()
+	commitTx.AddTxIn(&wire.TxIn{})
+	if err := SetStateNumHint(commitTx, maxStateHint+1, obfuscator); err == nil {
+		t.Fatalf("state num larger than max hint should be rejected")
+	}
+}
@@ -0,0 +1,80 @@
+package lnwallet
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// WriteSignDescriptor serializes a SignDescriptor struct into the passed
+// io.Writer stream. The sighash midstate, and input index aren't serialized
+// as they're only known once the spending transaction has been constructed.
+func WriteSignDescriptor(w io.Writer, sd *SignDescriptor) error {
+	serializedPubKey := sd.PubKey.SerializeCompressed()
+	if err := wire.WriteVarBytes(w, 0, serializedPubKey); err != nil {
+		return err
+	}
+
+	if err := wire.WriteVarBytes(w, 0, sd.RedeemScript); err != nil {
+		return err
+	}
+
+	var scratch [8]byte
+	binary.BigEndian.PutUint64(scratch[:], uint64(sd.Output.Value))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, sd.Output.PkScript); err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint32(scratch[:4], uint32(sd.HashType))
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReadSignDescriptor deserializes a SignDescriptor struct from the passed
+// io.Reader stream.
+func ReadSignDescriptor(r io.Reader, sd *SignDescriptor) error {
+	pubKeyBytes, err := wire.ReadVarBytes(r, 0, 34, "pubkey")
+	if err != nil {
+		return err
+	}
+	sd.PubKey, err = btcec.ParsePubKey(pubKeyBytes, btcec.S256())
+	if err != nil {
+		return err
+	}
+
+	sd.RedeemScript, err = wire.ReadVarBytes(r, 0, 500, "redeemScript")
+	if err != nil {
+		return err
+	}
+
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return err
+	}
+	value := int64(binary.BigEndian.Uint64(scratch[:]))
+
+	pkScript, err := wire.ReadVarBytes(r, 0, 34, "pkScript")
+	if err != nil {
+		return err
+	}
+	sd.Output = &wire.TxOut{
+		Value:    value,
+		PkScript: pkScript,
+	}
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return err
+	}
+	sd.HashType = txscript.SigHashType(binary.BigEndian.Uint32(scratch[:4]))
+
+	return nil
+}
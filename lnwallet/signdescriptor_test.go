@@ -0,0 +1,50 @@
+package lnwallet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestSignDescriptorSerialization(t *testing.T) {
+	_, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), testWalletPrivKey)
+
+	redeemScript, err := commitScriptToSelf(144, pubKey, pubKey)
+	if err != nil {
+		t.Fatalf("unable to create redeem script: %v", err)
+	}
+	pkScript, err := witnessScriptHash(redeemScript)
+	if err != nil {
+		t.Fatalf("unable to create pkScript: %v", err)
+	}
+
+	signDesc := &SignDescriptor{
+		PubKey:       pubKey,
+		RedeemScript: redeemScript,
+		Output: &wire.TxOut{
+			Value:    5e8,
+			PkScript: pkScript,
+		},
+		HashType: txscript.SigHashAll,
+	}
+
+	var b bytes.Buffer
+	if err := WriteSignDescriptor(&b, signDesc); err != nil {
+		t.Fatalf("unable to serialize sign descriptor: %v", err)
+	}
+
+	newSignDesc := &SignDescriptor{}
+	if err := ReadSignDescriptor(&b, newSignDesc); err != nil {
+		t.Fatalf("unable to deserialize sign descriptor: %v", err)
+	}
+
+	if !reflect.DeepEqual(signDesc, newSignDesc) {
+		t.Fatalf("sign descriptors don't match: %v vs %v",
+			spew.Sdump(signDesc), spew.Sdump(newSignDesc))
+	}
+}
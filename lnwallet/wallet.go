@@ -87,8 +87,8 @@ type initFundingReserveMsg struct {
 	// The minimum accepted satoshis/KB fee for the funding transaction. In
 	// order to ensure timely confirmation, it is recomened that this fee
 	// should be generous, paying some multiple of the accepted base fee
-	// rate of the network.
-	// TODO(roasbeef): integrate fee estimation project...
+	// rate of the network. This fee rate is also used as the minimum fee
+	// rate for the cooperative closure of the channel.
 	minFeeRate btcutil.Amount
 
 	// The ID of the remote node we would like to open a channel with.
@@ -98,6 +98,11 @@ type initFundingReserveMsg struct {
 	// The delay on the "pay-to-self" output(s) of the commitment transaction.
 	csvDelay uint32
 
+	// Whether or not we pay the fee of the initial commitment transaction.
+	// This is true for the initiator of a channel, and false for the
+	// responder of a dual funder workflow.
+	payCommitFee bool
+
 	// A channel in which all errors will be sent accross. Will be nil if
 	// this initial set is succesful.
 	// NOTE: In order to avoid deadlocks, this channel MUST be buffered.
@@ -472,9 +477,41 @@ out:
 // and final step verifies all signatures for the inputs of the funding
 // transaction, and that the signature we records for our version of the
 // commitment transaction is valid.
+//
+// The passed minFeeRate, expressed in satoshis/KB, is the fee rate negotiated
+// with the remote party. It's used during coin selection for the funding
+// transaction, and recorded within the channel as the minimum fee rate for
+// its cooperative closure.
 func (l *LightningWallet) InitChannelReservation(capacity,
 	ourFundAmt btcutil.Amount, theirID [32]byte, numConfs uint16,
-	csvDelay uint32) (*ChannelReservation, error) {
+	csvDelay uint32, minFeeRate btcutil.Amount) (*ChannelReservation, error) {
+
+	errChan := make(chan error, 1)
+	respChan := make(chan *ChannelReservation, 1)
+
+	l.msgChan <- &initFundingReserveMsg{
+		capacity:      capacity,
+		numConfs:      numConfs,
+		fundingAmount: ourFundAmt,
+		csvDelay:      csvDelay,
+		minFeeRate:    minFeeRate,
+		nodeID:        theirID,
+		payCommitFee:  true,
+		err:           errChan,
+		resp:          respChan,
+	}
+
+	return <-respChan, <-errChan
+}
+
+// InitDualFundResponderReservation is identical to InitChannelReservation,
+// but is to be used when we're the responder of a dual funder workflow. As
+// the fee for the initial commitment transaction is paid by the initiator of
+// the channel, coin selection only needs to cover our contribution,
+// ourFundAmt, to the passed channel capacity.
+func (l *LightningWallet) InitDualFundResponderReservation(capacity,
+	ourFundAmt btcutil.Amount, theirID [32]byte, numConfs uint16,
+	csvDelay uint32, minFeeRate btcutil.Amount) (*ChannelReservation, error) {
 
 	errChan := make(chan error, 1)
 	respChan := make(chan *ChannelReservation, 1)
@@ -484,7 +521,9 @@ func (l *LightningWallet) InitChannelReservation(capacity,
 		numConfs:      numConfs,
 		fundingAmount: ourFundAmt,
 		csvDelay:      csvDelay,
+		minFeeRate:    minFeeRate,
 		nodeID:        theirID,
+		payCommitFee:  false,
 		err:           errChan,
 		resp:          respChan,
 	}
@@ -513,10 +552,17 @@ func (l *LightningWallet) handleFundingReserveRequest(req *initFundingReserveMsg
 	// don't need to perform any coin selection. Otherwise, attempt to
 	// obtain enough coins to meet the required funding amount.
 	if req.fundingAmount != 0 {
-		// TODO(roasbeef): consult model for proper fee rate on funding
-		// tx
-		feeRate := uint64(10)
-		amt := req.fundingAmount + commitFee
+		// Coin selection expects a fee rate in satoshis/byte, so we
+		// convert the negotiated fee rate, ensuring that a non-zero fee
+		// is always paid.
+		feeRate := uint64(req.minFeeRate / 1000)
+		if feeRate == 0 {
+			feeRate = 1
+		}
+		amt := req.fundingAmount
+		if req.payCommitFee {
+			amt += commitFee
+		}
 		err := l.selectCoinsAndChange(feeRate, amt, ourContribution)
 		if err != nil {
 			req.err <- err
@@ -768,6 +814,18 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 		return
 	}
 
+	// Encode the initial state number within both commitment transactions
+	// so that a later broadcast of either can be mapped back to its state.
+	obfuscator := deriveStateHintObfuscator(ourCommitKey, theirCommitKey)
+	if err := SetStateNumHint(ourCommitTx, 0, obfuscator); err != nil {
+		req.err <- err
+		return
+	}
+	if err := SetStateNumHint(theirCommitTx, 0, obfuscator); err != nil {
+		req.err <- err
+		return
+	}
+
 	// Sort both transactions according to the agreed upon cannonical
 	// ordering. This lets us skip sending the entire transaction over,
 	// instead we'll just send signatures.
@@ -782,6 +840,9 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 
 	// Record newly available information witin the open channel state.
 	pendingReservation.partialState.RemoteCsvDelay = theirContribution.CsvDelay
+	pendingReservation.partialState.RemoteMaxAcceptedHTLCs = theirContribution.MaxAcceptedHTLCs
+	pendingReservation.partialState.RemoteMaxValueInFlight = theirContribution.MaxValueInFlight
+	pendingReservation.partialState.RemoteHtlcMinimum = theirContribution.HtlcMinimum
 	pendingReservation.partialState.TheirDeliveryScript = deliveryScript
 	pendingReservation.partialState.ChanID = fundingOutpoint
 	pendingReservation.partialState.TheirCommitKey = theirCommitKey
@@ -878,6 +939,9 @@ func (l *LightningWallet) handleSingleContribution(req *addSingleContributionMsg
 		return
 	}
 	pendingReservation.partialState.RemoteCsvDelay = theirContribution.CsvDelay
+	pendingReservation.partialState.RemoteMaxAcceptedHTLCs = theirContribution.MaxAcceptedHTLCs
+	pendingReservation.partialState.RemoteMaxValueInFlight = theirContribution.MaxValueInFlight
+	pendingReservation.partialState.RemoteHtlcMinimum = theirContribution.HtlcMinimum
 	pendingReservation.partialState.TheirDeliveryScript = deliveryScript
 	pendingReservation.partialState.TheirCommitKey = theirContribution.CommitKey
 	pendingReservation.partialState.TheirMultiSigKey = theirContribution.MultiSigKey
@@ -1000,6 +1064,20 @@ func (l *LightningWallet) handleFundingCounterPartySigs(msg *addCounterPartySigs
 		return
 	}
 
+	// Mark the channel as pending within the database before writing its
+	// full state. This ensures the channel isn't treated as open if we
+	// restart before the funding transaction is sufficiently confirmed.
+	chanPoint := pendingReservation.partialState.FundingOutpoint
+	pendingChan := &channeldb.PendingChannel{
+		ChanPoint: *chanPoint,
+		TheirLNID: pendingReservation.partialState.TheirLNID,
+		NumConfs:  pendingReservation.numConfsToOpen,
+	}
+	if err := l.ChannelDB.AddPendingChannel(pendingChan); err != nil {
+		msg.err <- err
+		return
+	}
+
 	// Add the complete funding transaction to the DB, in it's open bucket
 	// which will be used for the lifetime of this channel.
 	if err := pendingReservation.partialState.FullSync(); err != nil {
@@ -1059,6 +1137,18 @@ func (l *LightningWallet) handleSingleFunderSigs(req *addSingleFunderSigsMsg) {
 		return
 	}
 
+	// Encode the initial state number within both commitment transactions
+	// so that a later broadcast of either can be mapped back to its state.
+	obfuscator := deriveStateHintObfuscator(ourCommitKey, theirCommitKey)
+	if err := SetStateNumHint(ourCommitTx, 0, obfuscator); err != nil {
+		req.err <- err
+		return
+	}
+	if err := SetStateNumHint(theirCommitTx, 0, obfuscator); err != nil {
+		req.err <- err
+		return
+	}
+
 	// Sort both transactions according to the agreed upon cannonical
 	// ordering. This ensures that both parties sign the same sighash
 	// without further synchronization.
@@ -1163,31 +1253,62 @@ func (l *LightningWallet) handleChannelOpen(req *channelOpenMsg) {
 // the funding transaction created within the passed channel reservation
 // obtains the specified number of confirmations.
 func (l *LightningWallet) openChannelAfterConfirmations(res *ChannelReservation) {
-	// Register with the ChainNotifier for a notification once the funding
-	// transaction reaches `numConfs` confirmations.
 	txid := res.fundingTx.TxSha()
 	numConfs := uint32(res.numConfsToOpen)
-	confNtfn, _ := l.chainNotifier.RegisterConfirmationsNtfn(&txid, numConfs)
-
-	walletLog.Infof("Waiting for funding tx (txid: %v) to reach %v confirmations",
-		txid, numConfs)
 
 	// Wait until the specified number of confirmations has been reached,
-	// or the wallet signals a shutdown.
-out:
-	select {
-	case _, ok := <-confNtfn.Confirmed:
-		// Reading a falsey value for the second parameter indicates that
-		// the notifier is in the process of shutting down. Therefore, we
-		// don't count this as the signal that the funding transaction has
-		// been confirmed.
-		if !ok {
+	// or the wallet signals a shutdown. If the funding transaction is
+	// re-org'd out of the chain in the meantime, then the channel remains
+	// pending, and we wait for the transaction to be confirmed once again.
+	for {
+		// Register with the ChainNotifier for a notification once the
+		// funding transaction reaches `numConfs` confirmations.
+		confNtfn, err := l.chainNotifier.RegisterConfirmationsNtfn(&txid,
+			numConfs)
+		if err != nil {
+			walletLog.Errorf("unable to register for confirmation of "+
+				"funding tx (txid: %v): %v", txid, err)
 			res.chanOpen <- nil
 			return
 		}
 
-		break out
-	case <-l.quit:
+		walletLog.Infof("Waiting for funding tx (txid: %v) to reach %v "+
+			"confirmations", txid, numConfs)
+
+		select {
+		case _, ok := <-confNtfn.Confirmed:
+			// Reading a falsey value for the second parameter
+			// indicates that the notifier is in the process of
+			// shutting down. Therefore, we don't count this as the
+			// signal that the funding transaction has been
+			// confirmed.
+			if !ok {
+				res.chanOpen <- nil
+				return
+			}
+		case depth, ok := <-confNtfn.NegativeConf:
+			if !ok {
+				res.chanOpen <- nil
+				return
+			}
+
+			walletLog.Warnf("Funding tx (txid: %v) re-org'd out of "+
+				"the chain at depth %v, waiting for it to be "+
+				"re-confirmed", txid, depth)
+			continue
+		case <-l.quit:
+			res.chanOpen <- nil
+			return
+		}
+
+		break
+	}
+
+	// The funding transaction is now sufficiently confirmed, so the channel
+	// is no longer pending.
+	if err := l.ChannelDB.DeletePendingChannel(res.partialState.ChanID); err != nil {
+		walletLog.Errorf("unable to mark ChannelPoint(%v) as open: %v",
+			res.partialState.ChanID, err)
 		res.chanOpen <- nil
 		return
 	}
@@ -0,0 +1,70 @@
+package lnwallet
+
+import (
+	"fmt"
+
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// WitnessType determines how an output's witness will be generated. The
+// default commitment witness type is CommitmentTimeLock, which is used to
+// sweep our own delayed output from a commitment transaction. Unlike a
+// closure, a WitnessType can be persisted to disk along with the output's
+// SignDescriptor, allowing the witness generator to be re-created after a
+// restart.
+type WitnessType uint16
+
+const (
+	// CommitmentTimeLock is a witness that allows us to spend the output of
+	// a commitment transaction after a relative lock-time lockout.
+	CommitmentTimeLock WitnessType = 0
+
+	// HtlcOfferedTimeout is a witness that allows us to sweep an HTLC
+	// output that we offered within our commitment transaction once both
+	// the absolute expiry of the HTLC, and the relative delay have passed.
+	HtlcOfferedTimeout WitnessType = 1
+)
+
+// String returns a human readable version of the target WitnessType.
+func (wt WitnessType) String() string {
+	switch wt {
+	case CommitmentTimeLock:
+		return "CommitmentTimeLock"
+	case HtlcOfferedTimeout:
+		return "HtlcOfferedTimeout"
+	default:
+		return fmt.Sprintf("Unknown WitnessType(%d)", uint16(wt))
+	}
+}
+
+// WitnessGenerator represents a function which is able to generate the final
+// witness for a particular public key script. This function acts as an
+// abstraction layer, hiding the details of the underlying script.
+type WitnessGenerator func(tx *wire.MsgTx, hc *txscript.TxSigHashes,
+	inputIndex int) ([][]byte, error)
+
+// GenWitnessFunc creates a WitnessGenerator function capable of generating
+// the witness required to spend an output of the target WitnessType using the
+// passed Signer and SignDescriptor. The sighash midstate, and input index of
+// the passed SignDescriptor are filled in once the witness is generated.
+func (wt WitnessType) GenWitnessFunc(signer Signer,
+	descriptor *SignDescriptor) WitnessGenerator {
+
+	return func(tx *wire.MsgTx, hc *txscript.TxSigHashes,
+		inputIndex int) ([][]byte, error) {
+
+		desc := *descriptor
+		desc.SigHashes = hc
+		desc.InputIndex = inputIndex
+
+		switch wt {
+		case CommitmentTimeLock:
+			return CommitSpendTimeout(signer, &desc, tx)
+		case HtlcOfferedTimeout:
+			return HtlcSpendTimeout(signer, &desc, tx)
+		default:
+			return nil, fmt.Errorf("unknown witness type: %v", wt)
+		}
+	}
+}
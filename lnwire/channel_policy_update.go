@@ -0,0 +1,174 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// ChannelPolicyUpdate is broadcast throughout the network in order to
+// advertise the routing policy a node enforces when forwarding HTLC's over one
+// of its channels. Senders use the latest policy of each channel within a
+// route in order to determine the fees and time-lock deltas each hop requires
+// to forward a payment. Each policy applies only to the direction of the
+// channel from NodeID towards PeerID.
+//
+// TODO(roasbeef): updates should be signed by NodeID, and reference an
+// authenticated channel
+type ChannelPolicyUpdate struct {
+	// ChannelPoint is the outpoint of the funding transaction of the
+	// channel this policy applies to.
+	ChannelPoint *wire.OutPoint
+
+	// NodeID is the identity public key of the node which enforces the
+	// policy when forwarding HTLC's over the channel.
+	NodeID *btcec.PublicKey
+
+	// PeerID is the identity public key of the node at the other end of
+	// the channel.
+	PeerID *btcec.PublicKey
+
+	// UpdateIndex is a monotonically increasing index used to order
+	// successive updates to the same policy. Updates with an index less
+	// than, or equal to, the index of the last accepted update are
+	// ignored.
+	UpdateIndex uint32
+
+	// BaseFee is the flat fee charged for each HTLC forwarded over the
+	// channel.
+	BaseFee btcutil.Amount
+
+	// FeeRate is the proportional fee charged for each HTLC forwarded
+	// over the channel, expressed in millionths of the amount forwarded.
+	FeeRate uint32
+
+	// MinHTLC is the smallest HTLC which will be forwarded over the
+	// channel.
+	MinHTLC btcutil.Amount
+
+	// TimeLockDelta is the number of blocks the expiry of an HTLC
+	// forwarded over the channel is reduced by.
+	TimeLockDelta uint32
+}
+
+// A compile time check to ensure ChannelPolicyUpdate implements the
+// lnwire.Message interface.
+var _ Message = (*ChannelPolicyUpdate)(nil)
+
+// Decode deserializes a serialized ChannelPolicyUpdate message stored in the
+// passed io.Reader observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelPolicyUpdate) Decode(r io.Reader, pver uint32) error {
+	// ChannelPoint (36)
+	// NodeID (33)
+	// PeerID (33)
+	// UpdateIndex (4)
+	// BaseFee (8)
+	// FeeRate (4)
+	// MinHTLC (8)
+	// TimeLockDelta (4)
+	err := readElements(r,
+		&c.ChannelPoint,
+		&c.NodeID,
+		&c.PeerID,
+		&c.UpdateIndex,
+		&c.BaseFee,
+		&c.FeeRate,
+		&c.MinHTLC,
+		&c.TimeLockDelta,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target ChannelPolicyUpdate into the passed io.Writer
+// observing the protocol version specified.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelPolicyUpdate) Encode(w io.Writer, pver uint32) error {
+	err := writeElements(w,
+		c.ChannelPoint,
+		c.NodeID,
+		c.PeerID,
+		c.UpdateIndex,
+		c.BaseFee,
+		c.FeeRate,
+		c.MinHTLC,
+		c.TimeLockDelta,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the integer uniquely identifying this message type on the
+// wire.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelPolicyUpdate) Command() uint32 {
+	return CmdChannelPolicyUpdate
+}
+
+// MaxPayloadLength returns the maximum allowed payload size for a
+// ChannelPolicyUpdate message observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelPolicyUpdate) MaxPayloadLength(uint32) uint32 {
+	// 36 + 33 + 33 + 4 + 8 + 4 + 8 + 4
+	return 130
+}
+
+// Validate performs any necessary sanity checks to ensure all fields present
+// on the ChannelPolicyUpdate are valid.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelPolicyUpdate) Validate() error {
+	if c.ChannelPoint == nil {
+		return fmt.Errorf("channel point must be set")
+	}
+	if c.NodeID == nil || c.PeerID == nil {
+		return fmt.Errorf("node and peer ID's must be set")
+	}
+	if c.BaseFee < 0 {
+		return fmt.Errorf("base fee must be non-negative")
+	}
+	if c.MinHTLC < 0 {
+		return fmt.Errorf("min HTLC must be non-negative")
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the target ChannelPolicyUpdate.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelPolicyUpdate) String() string {
+	var nodeID, peerID []byte
+	if c.NodeID != nil {
+		nodeID = c.NodeID.SerializeCompressed()
+	}
+	if c.PeerID != nil {
+		peerID = c.PeerID.SerializeCompressed()
+	}
+
+	return fmt.Sprintf("\n--- Begin ChannelPolicyUpdate ---\n") +
+		fmt.Sprintf("ChannelPoint:\t%v\n", c.ChannelPoint) +
+		fmt.Sprintf("NodeID:\t\t%x\n", nodeID) +
+		fmt.Sprintf("PeerID:\t\t%x\n", peerID) +
+		fmt.Sprintf("UpdateIndex:\t%d\n", c.UpdateIndex) +
+		fmt.Sprintf("BaseFee:\t%d\n", c.BaseFee) +
+		fmt.Sprintf("FeeRate:\t%d\n", c.FeeRate) +
+		fmt.Sprintf("MinHTLC:\t%d\n", c.MinHTLC) +
+		fmt.Sprintf("TimeLockDelta:\t%d\n", c.TimeLockDelta) +
+		fmt.Sprintf("--- End ChannelPolicyUpdate ---\n")
+}
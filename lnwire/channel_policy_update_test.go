@@ -0,0 +1,38 @@
+package lnwire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestChannelPolicyUpdateEncodeDecode(t *testing.T) {
+	cpu := &ChannelPolicyUpdate{
+		ChannelPoint:  outpoint1,
+		NodeID:        pubKey,
+		PeerID:        pubKey,
+		UpdateIndex:   22,
+		BaseFee:       1000,
+		FeeRate:       250,
+		MinHTLC:       10,
+		TimeLockDelta: 144,
+	}
+
+	// Next encode the CPU message into an empty bytes buffer.
+	var b bytes.Buffer
+	if err := cpu.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode ChannelPolicyUpdate: %v", err)
+	}
+
+	// Deserialize the encoded CPU message into a new empty struct.
+	cpu2 := &ChannelPolicyUpdate{}
+	if err := cpu2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode ChannelPolicyUpdate: %v", err)
+	}
+
+	// Assert equality of the two instances.
+	if !reflect.DeepEqual(cpu, cpu2) {
+		t.Fatalf("encode/decode error messages don't match %#v vs %#v",
+			cpu, cpu2)
+	}
+}
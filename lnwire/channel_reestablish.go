@@ -0,0 +1,117 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// ChannelReestablish is sent by both sides for each active channel upon
+// (re)connection, before any other commitment update messages. The message
+// allows each side to detect if the prior connection was dropped in the
+// middle of a state transition, leaving the two commitment chains out of
+// sync. If the remote party never received our revocation for our prior
+// commitment, then the revocation is retransmitted. If the heights reported
+// by the remote party are impossible given our local state, then one side
+// has lost data, and the channel can't be safely resumed.
+type ChannelReestablish struct {
+	// ChannelPoint uniquely identifies to which currently active channel
+	// this ChannelReestablish applies to.
+	ChannelPoint *wire.OutPoint
+
+	// LocalCommitHeight is the height of the sender's current, unrevoked
+	// commitment transaction. This is also the number of commitments the
+	// sender has revoked.
+	LocalCommitHeight uint64
+
+	// RemoteCommitHeight is the height of the receiver's commitment
+	// transaction which the sender believes to be current. This is also
+	// the number of revocations the sender has received from the
+	// receiver, so the index of the last revocation received is
+	// RemoteCommitHeight - 1.
+	RemoteCommitHeight uint64
+}
+
+// NewChannelReestablish creates a new ChannelReestablish message.
+func NewChannelReestablish() *ChannelReestablish {
+	return &ChannelReestablish{}
+}
+
+// A compile time check to ensure ChannelReestablish implements the
+// lnwire.Message interface.
+var _ Message = (*ChannelReestablish)(nil)
+
+// Decode deserializes a serialized ChannelReestablish message stored in the
+// passed io.Reader observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelReestablish) Decode(r io.Reader, pver uint32) error {
+	// ChannelPoint (36)
+	// LocalCommitHeight (8)
+	// RemoteCommitHeight (8)
+	err := readElements(r,
+		&c.ChannelPoint,
+		&c.LocalCommitHeight,
+		&c.RemoteCommitHeight,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target ChannelReestablish into the passed io.Writer
+// observing the protocol version specified.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelReestablish) Encode(w io.Writer, pver uint32) error {
+	err := writeElements(w,
+		c.ChannelPoint,
+		c.LocalCommitHeight,
+		c.RemoteCommitHeight,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the integer uniquely identifying this message type on the
+// wire.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelReestablish) Command() uint32 {
+	return CmdChannelReestablish
+}
+
+// MaxPayloadLength returns the maximum allowed payload size for a
+// ChannelReestablish message observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelReestablish) MaxPayloadLength(uint32) uint32 {
+	// 36 + 8 + 8
+	return 52
+}
+
+// Validate performs any necessary sanity checks to ensure all fields present
+// on the ChannelReestablish are valid.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelReestablish) Validate() error {
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the target ChannelReestablish.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelReestablish) String() string {
+	return fmt.Sprintf("\n--- Begin ChannelReestablish ---\n") +
+		fmt.Sprintf("ChannelPoint:\t%v\n", c.ChannelPoint) +
+		fmt.Sprintf("LocalCommitHeight:\t%d\n", c.LocalCommitHeight) +
+		fmt.Sprintf("RemoteCommitHeight:\t%d\n", c.RemoteCommitHeight) +
+		fmt.Sprintf("--- End ChannelReestablish ---\n")
+}
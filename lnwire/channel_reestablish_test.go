@@ -0,0 +1,33 @@
+package lnwire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestChannelReestablishEncodeDecode(t *testing.T) {
+	cr := &ChannelReestablish{
+		ChannelPoint:       outpoint1,
+		LocalCommitHeight:  1337,
+		RemoteCommitHeight: 1336,
+	}
+
+	// Next encode the CR message into an empty bytes buffer.
+	var b bytes.Buffer
+	if err := cr.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode ChannelReestablish: %v", err)
+	}
+
+	// Deserialize the encoded CR message into a new empty struct.
+	cr2 := &ChannelReestablish{}
+	if err := cr2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode ChannelReestablish: %v", err)
+	}
+
+	// Assert equality of the two instances.
+	if !reflect.DeepEqual(cr, cr2) {
+		t.Fatalf("encode/decode error messages don't match %#v vs %#v",
+			cr, cr2)
+	}
+}
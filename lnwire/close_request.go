@@ -36,11 +36,13 @@ type CloseRequest struct {
 }
 
 // NewCloseRequest creates a new CloseRequest.
-func NewCloseRequest(cp *wire.OutPoint, sig *btcec.Signature) *CloseRequest {
-	// TODO(roasbeef): update once fees aren't hardcoded
+func NewCloseRequest(cp *wire.OutPoint, sig *btcec.Signature,
+	fee btcutil.Amount) *CloseRequest {
+
 	return &CloseRequest{
 		ChannelPoint:      cp,
 		RequesterCloseSig: sig,
+		Fee:               fee,
 	}
 }
 
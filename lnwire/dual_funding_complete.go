@@ -0,0 +1,174 @@
+package lnwire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// DualFundingComplete is the message Alice sends to Bob once she is able to
+// fully assemble the funding transaction, and both versions of the commitment
+// transaction of a dual funded channel. Along with a signature for Bob's
+// version of the commitment transaction, Alice presents the scripts which
+// redeem her inputs to the funding transaction.
+type DualFundingComplete struct {
+	// ChannelID serves to uniquely identify the future channel created by
+	// the initiated dual funder workflow.
+	ChannelID uint64
+
+	// FundingOutPoint is the outpoint (txid:index) of the funding
+	// transaction. Bob independently constructs the funding transaction,
+	// so this value allows him to ensure both sides agree on its final
+	// form.
+	FundingOutPoint *wire.OutPoint
+
+	// CommitSignature is Alice's signature for Bob's version of the
+	// commitment transaction.
+	CommitSignature *btcec.Signature
+
+	// RevocationKey is the initial key to be used for the revocation
+	// clause within the self-output of the initiators's commitment
+	// transaction. Once an initial new state is created, the initiator
+	// will send a pre-image which will allow the initiator to sweep the
+	// initiator's funds if the violate the contract.
+	RevocationKey *btcec.PublicKey
+
+	// FundingScripts are the scripts which redeem each of Alice's inputs
+	// to the funding transaction. The scripts are ordered according to
+	// the position of the inputs within the BIP-69 sorted funding
+	// transaction.
+	FundingScripts []*InputScript
+}
+
+// NewDualFundingComplete creates, and returns a new empty DualFundingComplete.
+func NewDualFundingComplete(chanID uint64, fundingPoint *wire.OutPoint,
+	commitSig *btcec.Signature, revokeKey *btcec.PublicKey,
+	fundingScripts []*InputScript) *DualFundingComplete {
+
+	return &DualFundingComplete{
+		ChannelID:       chanID,
+		FundingOutPoint: fundingPoint,
+		CommitSignature: commitSig,
+		RevocationKey:   revokeKey,
+		FundingScripts:  fundingScripts,
+	}
+}
+
+// A compile time check to ensure DualFundingComplete implements the
+// lnwire.Message interface.
+var _ Message = (*DualFundingComplete)(nil)
+
+// Decode deserializes the serialized DualFundingComplete stored in the passed
+// io.Reader into the target DualFundingComplete using the deserialization
+// rules defined by the passed protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (s *DualFundingComplete) Decode(r io.Reader, pver uint32) error {
+	// ChannelID (8)
+	// FundingOutPoint (36)
+	// CommitmentSignature (73)
+	// RevocationKey (33)
+	// FundingScripts (var)
+	err := readElements(r,
+		&s.ChannelID,
+		&s.FundingOutPoint,
+		&s.CommitSignature,
+		&s.RevocationKey,
+		&s.FundingScripts)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target DualFundingComplete into the passed io.Writer
+// implementation. Serialization will observe the rules defined by the passed
+// protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (s *DualFundingComplete) Encode(w io.Writer, pver uint32) error {
+	// ChannelID (8)
+	// FundingOutPoint (36)
+	// CommitmentSignature (73)
+	// RevocationKey (33)
+	// FundingScripts (var)
+	err := writeElements(w,
+		s.ChannelID,
+		s.FundingOutPoint,
+		s.CommitSignature,
+		s.RevocationKey,
+		s.FundingScripts)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the uint32 code which uniquely identifies this message as a
+// DualFundingComplete on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (s *DualFundingComplete) Command() uint32 {
+	return CmdDualFundingComplete
+}
+
+// MaxPayloadLength returns the maximum allowed payload length for a
+// DualFundingComplete. This is calculated by summing the max length of all
+// the fields within a DualFundingComplete. Each of the (at most 127) input
+// scripts may carry 4 witness items, and a sigScript, each of at most 520
+// bytes. Therefore, the final breakdown is:
+// 8 + 36 + 74 + 33 + (1 + 127*(1 + 4*523 + 523)) = 332384.
+//
+// This is part of the lnwire.Message interface.
+func (s *DualFundingComplete) MaxPayloadLength(uint32) uint32 {
+	return 332384
+}
+
+// Validate examines each populated field within the DualFundingComplete for
+// field sanity.
+//
+// This is part of the lnwire.Message interface.
+func (s *DualFundingComplete) Validate() error {
+	var zeroHash [32]byte
+	if bytes.Equal(zeroHash[:], s.FundingOutPoint.Hash[:]) {
+		return fmt.Errorf("funding outpoint hash must be non-zero")
+	}
+
+	if s.CommitSignature == nil {
+		return fmt.Errorf("commitment signature must be non-nil")
+	}
+
+	if s.RevocationKey == nil {
+		return fmt.Errorf("revocation key must be non-nil")
+	}
+
+	if len(s.FundingScripts) == 0 {
+		return fmt.Errorf("initiator must present funding input scripts")
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the DualFundingComplete.
+//
+// This is part of the lnwire.Message interface.
+func (s *DualFundingComplete) String() string {
+	var rk []byte
+	if s.RevocationKey != nil {
+		rk = s.RevocationKey.SerializeCompressed()
+	}
+
+	return fmt.Sprintf("\n--- Begin DualFundingComplete ---\n") +
+		fmt.Sprintf("ChannelID:\t\t\t%d\n", s.ChannelID) +
+		fmt.Sprintf("FundingOutPoint:\t\t\t%x\n", s.FundingOutPoint) +
+		fmt.Sprintf("CommitSignature\t\t\t\t%x\n", s.CommitSignature) +
+		fmt.Sprintf("RevocationKey\t\t\t\t%x\n", rk) +
+		fmt.Sprintf("FundingScripts:\t\t\t%d\n", len(s.FundingScripts)) +
+		fmt.Sprintf("--- End DualFundingComplete ---\n")
+}
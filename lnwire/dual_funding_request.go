@@ -0,0 +1,318 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// DualFundingRequest is the message Alice sends to Bob if she would like to
+// create a channel with Bob where both parties contribute funds to the
+// channel. In addition to the items sent within a SingleFundingRequest, Alice
+// also presents the inputs and change outputs she'll contribute to the
+// funding transaction, and the amount she'd like Bob to contribute.
+//
+// NOTE: As the initiator of the workflow, Alice pays the fee for the initial
+// commitment transaction. Bob only needs to contribute enough funds to cover
+// his portion of the channel's capacity.
+type DualFundingRequest struct {
+	// ChannelID serves to uniquely identify the future channel created by
+	// the initiated dual funder workflow.
+	ChannelID uint64
+
+	// ChannelType represents the type of channel this request would like
+	// to open. At this point, the only supported channels are type 0
+	// channels, which are channels with regular commitment transactions
+	// utilizing HTLC's for payments.
+	ChannelType uint8
+
+	// CoinType represents which blockchain the channel will be opened
+	// using. By default, this field should be set to 0, indicating usage
+	// of the Bitcoin blockchain.
+	CoinType uint64
+
+	// FeePerKb is the required number of satoshis per KB that the
+	// requester will pay at all timers, for both the funding transaction
+	// and commitment transaction. This value can later be updated once the
+	// channel is open.
+	FeePerKb btcutil.Amount
+
+	// FundingAmount is the number of satoshis the the initiator would like
+	// to commit to the channel.
+	FundingAmount btcutil.Amount
+
+	// RemoteFundingAmount is the number of satoshis the initiator would
+	// like the responder to commit to the channel.
+	RemoteFundingAmount btcutil.Amount
+
+	// CsvDelay is the number of blocks to use for the relative time lock
+	// in the pay-to-self output of both commitment transactions.
+	CsvDelay uint32
+
+	// MaxAcceptedHTLCs is the maximum number of HTLC's the initiator will
+	// accept within their commitment transaction at once. This value MUST
+	// NOT exceed MaxHTLCNumber.
+	MaxAcceptedHTLCs uint16
+
+	// MaxValueInFlight is the maximum total value of outstanding HTLC's
+	// the initiator will accept within their commitment transaction at
+	// once.
+	MaxValueInFlight btcutil.Amount
+
+	// HtlcMinimum is the smallest HTLC the initiator will accept.
+	HtlcMinimum btcutil.Amount
+
+	// CommitmentKey is key the initiator of the funding workflow wishes to
+	// use within their versino of the commitment transaction for any
+	// delayed (CSV) or immediate outputs to them.
+	CommitmentKey *btcec.PublicKey
+
+	// ChannelDerivationPoint is an secp256k1 point which will be used to
+	// derive the public key the initiator will use for the half of the
+	// 2-of-2 multi-sig. Using the channel derivation point (CDP), and the
+	// initiators identity public key (A), the channel public key is
+	// computed as: C = A + CDP. In order to be valid all CDP's MUST have
+	// an odd y-coordinate.
+	ChannelDerivationPoint *btcec.PublicKey
+
+	// DeliveryPkScript defines the public key script that the initiator
+	// would like to use to receive their balance in the case of a
+	// cooperative close. Only the following script templates are
+	// supported: P2PKH, P2WKH, P2SH, and P2WSH.
+	DeliveryPkScript PkScript
+
+	// Inputs are the outpoints the initiator contributes to the funding
+	// transaction.
+	Inputs []*wire.TxIn
+
+	// ChangeOutputs are the outputs returning any excess funds from the
+	// initiator's inputs back to the initiator.
+	ChangeOutputs []*wire.TxOut
+}
+
+// NewDualFundingRequest creates, and returns a new empty DualFundingRequest.
+func NewDualFundingRequest(chanID uint64, chanType uint8, coinType uint64,
+	fee, amt, remoteAmt btcutil.Amount, delay uint32, maxHtlcs uint16,
+	maxValue, htlcMin btcutil.Amount, ck, cdp *btcec.PublicKey,
+	deliveryScript PkScript, inputs []*wire.TxIn,
+	changeOutputs []*wire.TxOut) *DualFundingRequest {
+
+	return &DualFundingRequest{
+		ChannelID:              chanID,
+		ChannelType:            chanType,
+		CoinType:               coinType,
+		FeePerKb:               fee,
+		FundingAmount:          amt,
+		RemoteFundingAmount:    remoteAmt,
+		CsvDelay:               delay,
+		MaxAcceptedHTLCs:       maxHtlcs,
+		MaxValueInFlight:       maxValue,
+		HtlcMinimum:            htlcMin,
+		CommitmentKey:          ck,
+		ChannelDerivationPoint: cdp,
+		DeliveryPkScript:       deliveryScript,
+		Inputs:                 inputs,
+		ChangeOutputs:          changeOutputs,
+	}
+}
+
+// A compile time check to ensure DualFundingRequest implements the
+// lnwire.Message interface.
+var _ Message = (*DualFundingRequest)(nil)
+
+// Decode deserializes the serialized DualFundingRequest stored in the passed
+// io.Reader into the target DualFundingRequest using the deserialization
+// rules defined by the passed protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingRequest) Decode(r io.Reader, pver uint32) error {
+	// ChannelID (8)
+	// ChannelType (1)
+	// CoinType	(8)
+	// FeePerKb (8)
+	// FundingAmount (8)
+	// RemoteFundingAmount (8)
+	// Delay (4)
+	// MaxAcceptedHTLCs (2)
+	// MaxValueInFlight (8)
+	// HtlcMinimum (8)
+	// Pubkey (33)
+	// Pubkey (33)
+	// DeliveryPkScript (final delivery)
+	// Inputs (var)
+	// ChangeOutputs (var)
+	err := readElements(r,
+		&c.ChannelID,
+		&c.ChannelType,
+		&c.CoinType,
+		&c.FeePerKb,
+		&c.FundingAmount,
+		&c.RemoteFundingAmount,
+		&c.CsvDelay,
+		&c.MaxAcceptedHTLCs,
+		&c.MaxValueInFlight,
+		&c.HtlcMinimum,
+		&c.CommitmentKey,
+		&c.ChannelDerivationPoint,
+		&c.DeliveryPkScript,
+		&c.Inputs,
+		&c.ChangeOutputs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target DualFundingRequest into the passed io.Writer
+// implementation. Serialization will observe the rules defined by the passed
+// protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingRequest) Encode(w io.Writer, pver uint32) error {
+	// ChannelID (8)
+	// ChannelType (1)
+	// CoinType	(8)
+	// FeePerKb (8)
+	// FundingAmount (8)
+	// RemoteFundingAmount (8)
+	// Delay (4)
+	// MaxAcceptedHTLCs (2)
+	// MaxValueInFlight (8)
+	// HtlcMinimum (8)
+	// Pubkey (33)
+	// Pubkey (33)
+	// DeliveryPkScript (final delivery)
+	// Inputs (var)
+	// ChangeOutputs (var)
+	err := writeElements(w,
+		c.ChannelID,
+		c.ChannelType,
+		c.CoinType,
+		c.FeePerKb,
+		c.FundingAmount,
+		c.RemoteFundingAmount,
+		c.CsvDelay,
+		c.MaxAcceptedHTLCs,
+		c.MaxValueInFlight,
+		c.HtlcMinimum,
+		c.CommitmentKey,
+		c.ChannelDerivationPoint,
+		c.DeliveryPkScript,
+		c.Inputs,
+		c.ChangeOutputs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the uint32 code which uniquely identifies this message as a
+// DualFundingRequest on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingRequest) Command() uint32 {
+	return CmdDualFundingRequest
+}
+
+// MaxPayloadLength returns the maximum allowed payload length for a
+// DualFundingRequest. This is calculated by summing the max length of all the
+// fields within a DualFundingRequest. To enforce a maximum DeliveryPkScript
+// size, the size of a P2PKH public key script is used. At most 127 inputs,
+// and 127 change outputs may be present. Therefore, the final breakdown is:
+// 8 + 1 + 8 + 8 + 8 + 8 + 4 + 2 + 8 + 8 + 33 + 33 + 26 + (1 + 127*36) +
+// (1 + 127*43) = 10190.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingRequest) MaxPayloadLength(uint32) uint32 {
+	return 10190
+}
+
+// Validate examines each populated field within the DualFundingRequest for
+// field sanity. For example, all fields MUST NOT be negative, and all pkScripts
+// must belong to the allowed set of public key scripts.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingRequest) Validate() error {
+	// Negative values is are allowed.
+	if c.FeePerKb < 0 {
+		return fmt.Errorf("MinFeePerKb cannot be negative")
+	}
+	if c.FundingAmount < 0 {
+		return fmt.Errorf("FundingAmount cannot be negative")
+	}
+	if c.RemoteFundingAmount < 0 {
+		return fmt.Errorf("RemoteFundingAmount cannot be negative")
+	}
+
+	// The CSV delay MUST be non-zero.
+	if c.CsvDelay == 0 {
+		return fmt.Errorf("Commitment transaction must have non-zero " +
+			"CSV delay")
+	}
+
+	// The initiator must accept at least a single HTLC, but no more than
+	// the maximum number permitted within a commitment transaction.
+	if c.MaxAcceptedHTLCs == 0 || c.MaxAcceptedHTLCs > MaxHTLCNumber {
+		return fmt.Errorf("MaxAcceptedHTLCs must be in the range "+
+			"[1, %v]", MaxHTLCNumber)
+	}
+	if c.MaxValueInFlight < 0 {
+		return fmt.Errorf("MaxValueInFlight cannot be negative")
+	}
+	if c.HtlcMinimum < 0 {
+		return fmt.Errorf("HtlcMinimum cannot be negative")
+	}
+
+	// The channel derivation point must be non-nil.
+	if c.ChannelDerivationPoint == nil {
+		return fmt.Errorf("The channel derivation point must be non-nil")
+	}
+
+	// The initiator must contribute at least a single input in order to
+	// fund their portion of the channel.
+	if len(c.Inputs) == 0 {
+		return fmt.Errorf("Initiator must contribute at least one input")
+	}
+
+	// The delivery pkScript must be amongst the supported script
+	// templates.
+	if !isValidPkScript(c.DeliveryPkScript) {
+		return fmt.Errorf("Valid delivery public key scripts MUST be: " +
+			"P2PKH, P2WKH, P2SH, or P2WSH.")
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the DualFundingRequest.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingRequest) String() string {
+	var serializedPubkey []byte
+	if c.ChannelDerivationPoint != nil {
+		serializedPubkey = c.ChannelDerivationPoint.SerializeCompressed()
+	}
+
+	return fmt.Sprintf("\n--- Begin DualFundingRequest ---\n") +
+		fmt.Sprintf("ChannelID:\t\t\t%d\n", c.ChannelID) +
+		fmt.Sprintf("ChannelType:\t\t\t%x\n", c.ChannelType) +
+		fmt.Sprintf("CoinType:\t\t\t%d\n", c.CoinType) +
+		fmt.Sprintf("FeePerKb:\t\t\t%s\n", c.FeePerKb.String()) +
+		fmt.Sprintf("FundingAmount:\t\t\t%s\n", c.FundingAmount.String()) +
+		fmt.Sprintf("RemoteFundingAmount:\t\t%s\n", c.RemoteFundingAmount.String()) +
+		fmt.Sprintf("CsvDelay\t\t\t%d\n", c.CsvDelay) +
+		fmt.Sprintf("MaxAcceptedHTLCs\t\t%d\n", c.MaxAcceptedHTLCs) +
+		fmt.Sprintf("MaxValueInFlight\t\t%s\n", c.MaxValueInFlight.String()) +
+		fmt.Sprintf("HtlcMinimum\t\t\t%s\n", c.HtlcMinimum.String()) +
+		fmt.Sprintf("ChannelDerivationPoint\t\t\t\t%x\n", serializedPubkey) +
+		fmt.Sprintf("DeliveryPkScript\t\t%x\n", c.DeliveryPkScript) +
+		fmt.Sprintf("Inputs:\t\t\t%d\n", len(c.Inputs)) +
+		fmt.Sprintf("ChangeOutputs:\t\t\t%d\n", len(c.ChangeOutputs)) +
+		fmt.Sprintf("--- End DualFundingRequest ---\n")
+}
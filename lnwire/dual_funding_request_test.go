@@ -0,0 +1,36 @@
+package lnwire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestDualFundingRequestWire(t *testing.T) {
+	// First create a new DFR message.
+	cdp := pubKey
+	delivery := PkScript(bytes.Repeat([]byte{0x02}, 25))
+	changeOutputs := []*wire.TxOut{wire.NewTxOut(2e8, changePkScript)}
+	dfr := NewDualFundingRequest(20, 21, 22, 23, 5e8, 3e8, 5, 30, 1000, 10,
+		cdp, cdp, delivery, inputs, changeOutputs)
+
+	// Next encode the DFR message into an empty bytes buffer.
+	var b bytes.Buffer
+	if err := dfr.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode DualFundingRequest: %v", err)
+	}
+
+	// Deserialize the encoded DFR message into a new empty struct.
+	dfr2 := &DualFundingRequest{}
+	if err := dfr2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode DualFundingRequest: %v", err)
+	}
+
+	// Assert equality of the two instances.
+	if !reflect.DeepEqual(dfr, dfr2) {
+		t.Fatalf("encode/decode error messages don't match %#v vs %#v",
+			dfr, dfr2)
+	}
+}
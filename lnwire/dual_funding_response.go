@@ -0,0 +1,273 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// DualFundingResponse is the message Bob sends to Alice after she initiates
+// the dual funder channel workflow via a DualFundingRequest message. Bob's
+// response carries the inputs and change outputs he contributes to the
+// funding transaction. Once Alice receives Bob's response, she has all the
+// items neccessary to construct the funding transaction, and both commitment
+// transactions.
+type DualFundingResponse struct {
+	// ChannelID serves to uniquely identify the future channel created by
+	// the initiated dual funder workflow.
+	ChannelID uint64
+
+	// FundingAmount is the number of satoshis the responder commits to the
+	// channel. This MUST match the amount requested by the initiator.
+	FundingAmount btcutil.Amount
+
+	// ChannelDerivationPoint is an secp256k1 point which will be used to
+	// derive the public key the responder will use for the half of the
+	// 2-of-2 multi-sig. Using the channel derivation point (CDP), and the
+	// responder's identity public key (A), the channel public key is
+	// computed as: C = A + CDP. In order to be valid all CDP's MUST have
+	// an odd y-coordinate.
+	ChannelDerivationPoint *btcec.PublicKey
+
+	// CommitmentKey is key the responder to the funding workflow wishes to
+	// use within their versino of the commitment transaction for any
+	// delayed (CSV) or immediate outputs to them.
+	CommitmentKey *btcec.PublicKey
+
+	// RevocationKey is the initial key to be used for the revocation
+	// clause within the self-output of the responder's commitment
+	// transaction. Once an initial new state is created, the responder
+	// will send a pre-image which will allow the initiator to sweep the
+	// responder's funds if the violate the contract.
+	RevocationKey *btcec.PublicKey
+
+	// CsvDelay is the number of blocks to use for the relative time lock
+	// in the pay-to-self output of both commitment transactions.
+	CsvDelay uint32
+
+	// MaxAcceptedHTLCs is the maximum number of HTLC's the responder will
+	// accept within their commitment transaction at once. This value MUST
+	// NOT exceed MaxHTLCNumber.
+	MaxAcceptedHTLCs uint16
+
+	// MaxValueInFlight is the maximum total value of outstanding HTLC's
+	// the responder will accept within their commitment transaction at
+	// once.
+	MaxValueInFlight btcutil.Amount
+
+	// HtlcMinimum is the smallest HTLC the responder will accept.
+	HtlcMinimum btcutil.Amount
+
+	// DeliveryPkScript defines the public key script that the responder
+	// would like to use to receive their balance in the case of a
+	// cooperative close. Only the following script templates are
+	// supported: P2PKH, P2WKH, P2SH, and P2WSH.
+	DeliveryPkScript PkScript
+
+	// Inputs are the outpoints the responder contributes to the funding
+	// transaction.
+	Inputs []*wire.TxIn
+
+	// ChangeOutputs are the outputs returning any excess funds from the
+	// responder's inputs back to the responder.
+	ChangeOutputs []*wire.TxOut
+}
+
+// NewDualFundingResponse creates, and returns a new empty
+// DualFundingResponse.
+func NewDualFundingResponse(chanID uint64, amt btcutil.Amount, rk, ck,
+	cdp *btcec.PublicKey, delay uint32, maxHtlcs uint16, maxValue,
+	htlcMin btcutil.Amount, deliveryScript PkScript, inputs []*wire.TxIn,
+	changeOutputs []*wire.TxOut) *DualFundingResponse {
+
+	return &DualFundingResponse{
+		ChannelID:              chanID,
+		FundingAmount:          amt,
+		ChannelDerivationPoint: cdp,
+		CommitmentKey:          ck,
+		RevocationKey:          rk,
+		CsvDelay:               delay,
+		MaxAcceptedHTLCs:       maxHtlcs,
+		MaxValueInFlight:       maxValue,
+		HtlcMinimum:            htlcMin,
+		DeliveryPkScript:       deliveryScript,
+		Inputs:                 inputs,
+		ChangeOutputs:          changeOutputs,
+	}
+}
+
+// A compile time check to ensure DualFundingResponse implements the
+// lnwire.Message interface.
+var _ Message = (*DualFundingResponse)(nil)
+
+// Decode deserializes the serialized DualFundingResponse stored in the passed
+// io.Reader into the target DualFundingResponse using the deserialization
+// rules defined by the passed protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingResponse) Decode(r io.Reader, pver uint32) error {
+	// ChannelID (8)
+	// FundingAmount (8)
+	// ChannelDerivationPoint (33)
+	// CommitmentKey (33)
+	// RevocationKey (33)
+	// CsvDelay (4)
+	// MaxAcceptedHTLCs (2)
+	// MaxValueInFlight (8)
+	// HtlcMinimum (8)
+	// DeliveryPkScript (final delivery)
+	// Inputs (var)
+	// ChangeOutputs (var)
+	err := readElements(r,
+		&c.ChannelID,
+		&c.FundingAmount,
+		&c.ChannelDerivationPoint,
+		&c.CommitmentKey,
+		&c.RevocationKey,
+		&c.CsvDelay,
+		&c.MaxAcceptedHTLCs,
+		&c.MaxValueInFlight,
+		&c.HtlcMinimum,
+		&c.DeliveryPkScript,
+		&c.Inputs,
+		&c.ChangeOutputs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target DualFundingResponse into the passed io.Writer
+// implementation. Serialization will observe the rules defined by the passed
+// protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingResponse) Encode(w io.Writer, pver uint32) error {
+	// ChannelID (8)
+	// FundingAmount (8)
+	// ChannelDerivationPoint (33)
+	// CommitmentKey (33)
+	// RevocationKey (33)
+	// CsvDelay (4)
+	// MaxAcceptedHTLCs (2)
+	// MaxValueInFlight (8)
+	// HtlcMinimum (8)
+	// DeliveryPkScript (final delivery)
+	// Inputs (var)
+	// ChangeOutputs (var)
+	err := writeElements(w,
+		c.ChannelID,
+		c.FundingAmount,
+		c.ChannelDerivationPoint,
+		c.CommitmentKey,
+		c.RevocationKey,
+		c.CsvDelay,
+		c.MaxAcceptedHTLCs,
+		c.MaxValueInFlight,
+		c.HtlcMinimum,
+		c.DeliveryPkScript,
+		c.Inputs,
+		c.ChangeOutputs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the uint32 code which uniquely identifies this message as a
+// DualFundingResponse on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingResponse) Command() uint32 {
+	return CmdDualFundingResponse
+}
+
+// MaxPayloadLength returns the maximum allowed payload length for a
+// DualFundingResponse. This is calculated by summing the max length of all
+// the fields within a DualFundingResponse. To enforce a maximum
+// DeliveryPkScript size, the size of a P2PKH public key script is used. At
+// most 127 inputs, and 127 change outputs may be present. Therefore, the
+// final breakdown is: 8 + 8 + (33 * 3) + 4 + 2 + 8 + 8 + 26 + (1 + 127*36) +
+// (1 + 127*43) = 10198.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingResponse) MaxPayloadLength(uint32) uint32 {
+	return 10198
+}
+
+// Validate examines each populated field within the DualFundingResponse for
+// field sanity. For example, all fields MUST NOT be negative, and all pkScripts
+// must belong to the allowed set of public key scripts.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingResponse) Validate() error {
+	if c.FundingAmount < 0 {
+		return fmt.Errorf("FundingAmount cannot be negative")
+	}
+
+	// The channel derivation point must be non-nil.
+	if c.ChannelDerivationPoint == nil {
+		return fmt.Errorf("The channel derivation point must be non-nil")
+	}
+
+	// The responder must accept at least a single HTLC, but no more than
+	// the maximum number permitted within a commitment transaction.
+	if c.MaxAcceptedHTLCs == 0 || c.MaxAcceptedHTLCs > MaxHTLCNumber {
+		return fmt.Errorf("MaxAcceptedHTLCs must be in the range "+
+			"[1, %v]", MaxHTLCNumber)
+	}
+	if c.MaxValueInFlight < 0 {
+		return fmt.Errorf("MaxValueInFlight cannot be negative")
+	}
+	if c.HtlcMinimum < 0 {
+		return fmt.Errorf("HtlcMinimum cannot be negative")
+	}
+
+	// The delivery pkScript must be amongst the supported script
+	// templates.
+	if !isValidPkScript(c.DeliveryPkScript) {
+		return fmt.Errorf("Valid delivery public key scripts MUST be: " +
+			"P2PKH, P2WKH, P2SH, or P2WSH.")
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the DualFundingResponse.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingResponse) String() string {
+	var cdp []byte
+	var ck []byte
+	var rk []byte
+	if c.ChannelDerivationPoint != nil {
+		cdp = c.ChannelDerivationPoint.SerializeCompressed()
+	}
+	if c.CommitmentKey != nil {
+		ck = c.CommitmentKey.SerializeCompressed()
+	}
+	if c.RevocationKey != nil {
+		rk = c.RevocationKey.SerializeCompressed()
+	}
+
+	return fmt.Sprintf("\n--- Begin DualFundingResponse ---\n") +
+		fmt.Sprintf("ChannelID:\t\t\t%d\n", c.ChannelID) +
+		fmt.Sprintf("FundingAmount:\t\t\t%s\n", c.FundingAmount.String()) +
+		fmt.Sprintf("ChannelDerivationPoint\t\t\t\t%x\n", cdp) +
+		fmt.Sprintf("CommitmentKey\t\t\t\t%x\n", ck) +
+		fmt.Sprintf("RevocationKey\t\t\t\t%x\n", rk) +
+		fmt.Sprintf("CsvDelay\t\t%d\n", c.CsvDelay) +
+		fmt.Sprintf("MaxAcceptedHTLCs\t%d\n", c.MaxAcceptedHTLCs) +
+		fmt.Sprintf("MaxValueInFlight\t%s\n", c.MaxValueInFlight.String()) +
+		fmt.Sprintf("HtlcMinimum\t\t%s\n", c.HtlcMinimum.String()) +
+		fmt.Sprintf("DeliveryPkScript\t\t%x\n", c.DeliveryPkScript) +
+		fmt.Sprintf("Inputs:\t\t\t%d\n", len(c.Inputs)) +
+		fmt.Sprintf("ChangeOutputs:\t\t\t%d\n", len(c.ChangeOutputs)) +
+		fmt.Sprintf("--- End DualFundingResponse ---\n")
+}
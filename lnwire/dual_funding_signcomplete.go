@@ -0,0 +1,133 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/btcec"
+)
+
+// DualFundingSignComplete is the message Bob sends to Alice which delivers a
+// signature for Alice's version of the commitment transaction, along with the
+// scripts redeeming Bob's inputs to the funding transaction. After this
+// message is received and processed by Alice, the funding transaction is
+// fully signed, and may be broadcast.
+type DualFundingSignComplete struct {
+	// ChannelID serves to uniquely identify the future channel created by
+	// the initiated dual funder workflow.
+	ChannelID uint64
+
+	// CommitSignature is Bobs's signature for Alice's version of the
+	// commitment transaction.
+	CommitSignature *btcec.Signature
+
+	// FundingScripts are the scripts which redeem each of Bob's inputs to
+	// the funding transaction. The scripts are ordered according to the
+	// position of the inputs within the BIP-69 sorted funding
+	// transaction.
+	FundingScripts []*InputScript
+}
+
+// NewDualFundingSignComplete creates a new empty DualFundingSignComplete
+// message.
+func NewDualFundingSignComplete(chanID uint64, sig *btcec.Signature,
+	fundingScripts []*InputScript) *DualFundingSignComplete {
+
+	return &DualFundingSignComplete{
+		ChannelID:       chanID,
+		CommitSignature: sig,
+		FundingScripts:  fundingScripts,
+	}
+}
+
+// A compile time check to ensure DualFundingSignComplete implements the
+// lnwire.Message interface.
+var _ Message = (*DualFundingSignComplete)(nil)
+
+// Decode deserializes the serialized DualFundingSignComplete stored in the
+// passed io.Reader into the target DualFundingSignComplete using the
+// deserialization rules defined by the passed protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingSignComplete) Decode(r io.Reader, pver uint32) error {
+	// ChannelID (8)
+	// CommitmentSignature (73)
+	// FundingScripts (var)
+	err := readElements(r,
+		&c.ChannelID,
+		&c.CommitSignature,
+		&c.FundingScripts)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target DualFundingSignComplete into the passed
+// io.Writer implementation. Serialization will observe the rules defined by
+// the passed protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingSignComplete) Encode(w io.Writer, pver uint32) error {
+	// ChannelID (8)
+	// CommitmentSignature (73)
+	// FundingScripts (var)
+	err := writeElements(w,
+		c.ChannelID,
+		c.CommitSignature,
+		c.FundingScripts)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the uint32 code which uniquely identifies this message as a
+// DualFundingSignComplete on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingSignComplete) Command() uint32 {
+	return CmdDualFundingSignComplete
+}
+
+// MaxPayloadLength returns the maximum allowed payload length for a
+// DualFundingSignComplete. This is calculated by summing the max length of
+// all the fields within a DualFundingSignComplete. Each of the (at most 127)
+// input scripts may carry 4 witness items, and a sigScript, each of at most
+// 520 bytes. Therefore, the final breakdown is:
+// 8 + 74 + (1 + 127*(1 + 4*523 + 523)) = 332315.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingSignComplete) MaxPayloadLength(uint32) uint32 {
+	return 332315
+}
+
+// Validate examines each populated field within the DualFundingSignComplete
+// for field sanity.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingSignComplete) Validate() error {
+	if c.CommitSignature == nil {
+		return fmt.Errorf("commitment signature must be non-nil")
+	}
+
+	if len(c.FundingScripts) == 0 {
+		return fmt.Errorf("responder must present funding input scripts")
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the DualFundingSignComplete.
+//
+// This is part of the lnwire.Message interface.
+func (c *DualFundingSignComplete) String() string {
+	return fmt.Sprintf("\n--- Begin DualFundingSignComplete ---\n") +
+		fmt.Sprintf("ChannelID:\t\t\t%d\n", c.ChannelID) +
+		fmt.Sprintf("CommitSignature\t\t\t\t%x\n", c.CommitSignature) +
+		fmt.Sprintf("FundingScripts:\t\t\t%d\n", len(c.FundingScripts)) +
+		fmt.Sprintf("--- End DualFundingSignComplete ---\n")
+}
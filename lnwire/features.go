@@ -0,0 +1,167 @@
+package lnwire
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FeatureBit is the index of a single bit within a feature vector. Features
+// are allocated in pairs: the even bit of a pair signals that the feature is
+// required, while the odd bit signals that the feature is optional. A node
+// must disconnect from any peer which requires a feature it doesn't
+// understand, but may safely ignore unknown optional features. In other
+// words, "it's OK to be odd".
+type FeatureBit uint16
+
+const (
+	// InitialRoutingSyncOptional is a local feature which signals that
+	// the sender would like to receive every routing policy known to the
+	// receiver upon connection.
+	InitialRoutingSyncOptional FeatureBit = 3
+)
+
+// maxFeatureVectorSize is the maximum size of a serialized feature vector,
+// large enough to accommodate every possible FeatureBit.
+const maxFeatureVectorSize = 8192
+
+// featureNames houses the human readable name of each feature known to this
+// node, keyed by the optional bit of the feature. A feature is known if
+// either of its bits is present within this map.
+var featureNames = map[FeatureBit]string{
+	InitialRoutingSyncOptional: "initial-routing-sync",
+}
+
+// IsRequired returns true if the bit signals that its feature is required.
+func (b FeatureBit) IsRequired() bool {
+	return b%2 == 0
+}
+
+// IsKnown returns true if the feature signalled by the bit is understood by
+// this node.
+func (b FeatureBit) IsKnown() bool {
+	_, ok := featureNames[b|1]
+	return ok
+}
+
+// Name returns the human readable name of the feature signalled by the bit.
+func (b FeatureBit) Name() string {
+	if name, ok := featureNames[b|1]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("unknown-%d", b)
+}
+
+// FeatureVector is the set of feature bits signalled by a node. On the wire,
+// the vector is serialized as a big-endian byte slice, such that bit 0 is the
+// least significant bit of the final byte.
+type FeatureVector map[FeatureBit]struct{}
+
+// NewFeatureVector creates a new FeatureVector with the passed bits set.
+func NewFeatureVector(bits ...FeatureBit) FeatureVector {
+	f := make(FeatureVector, len(bits))
+	for _, bit := range bits {
+		f.Set(bit)
+	}
+
+	return f
+}
+
+// Set sets the passed bit within the feature vector.
+func (f FeatureVector) Set(bit FeatureBit) {
+	f[bit] = struct{}{}
+}
+
+// IsSet returns true if the passed bit is set within the feature vector.
+func (f FeatureVector) IsSet(bit FeatureBit) bool {
+	_, ok := f[bit]
+	return ok
+}
+
+// HasFeature returns true if the feature of the passed bit is signalled
+// within the feature vector, either as required or optional.
+func (f FeatureVector) HasFeature(bit FeatureBit) bool {
+	return f.IsSet(bit&^1) || f.IsSet(bit|1)
+}
+
+// Bits returns the bits set within the feature vector in ascending order.
+func (f FeatureVector) Bits() []FeatureBit {
+	bits := make([]FeatureBit, 0, len(f))
+	for bit := range f {
+		bits = append(bits, bit)
+	}
+	sort.Sort(featureBits(bits))
+
+	return bits
+}
+
+// UnknownRequiredFeatures returns the bits of all features which are
+// required by the feature vector, yet unknown to this node. If any such bits
+// exist, then the connection to the node which signalled them must be
+// dropped.
+func (f FeatureVector) UnknownRequiredFeatures() []FeatureBit {
+	var unknown []FeatureBit
+	for _, bit := range f.Bits() {
+		if bit.IsRequired() && !bit.IsKnown() {
+			unknown = append(unknown, bit)
+		}
+	}
+
+	return unknown
+}
+
+// serialize encodes the feature vector into its wire format.
+func (f FeatureVector) serialize() []byte {
+	if len(f) == 0 {
+		return []byte{}
+	}
+
+	var maxBit FeatureBit
+	for bit := range f {
+		if bit > maxBit {
+			maxBit = bit
+		}
+	}
+
+	b := make([]byte, maxBit/8+1)
+	for bit := range f {
+		b[len(b)-1-int(bit/8)] |= 1 << (bit % 8)
+	}
+
+	return b
+}
+
+// deserializeFeatureVector decodes a feature vector from its wire format.
+func deserializeFeatureVector(b []byte) FeatureVector {
+	f := make(FeatureVector)
+	for i := 0; i < len(b)*8; i++ {
+		if b[len(b)-1-i/8]&(1<<uint(i%8)) != 0 {
+			f.Set(FeatureBit(i))
+		}
+	}
+
+	return f
+}
+
+// String returns a human readable representation of the feature vector.
+func (f FeatureVector) String() string {
+	names := make([]string, 0, len(f))
+	for _, bit := range f.Bits() {
+		name := bit.Name()
+		if bit.IsRequired() {
+			name += "(required)"
+		}
+		names = append(names, name)
+	}
+
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// featureBits implements sort.Interface, sorting a slice of FeatureBits in
+// ascending order.
+type featureBits []FeatureBit
+
+func (b featureBits) Len() int           { return len(b) }
+func (b featureBits) Less(i, j int) bool { return b[i] < b[j] }
+func (b featureBits) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
@@ -20,6 +20,11 @@ type HTLCAddReject struct {
 	// HTLCKey is used to identify which HTLC previously attempted to be
 	// added via an HTLCAddRequest message is being declined.
 	HTLCKey HTLCKey
+
+	// Reason is an encrypted failure describing why the HTLC was
+	// rejected. Only the sender of the HTLC is able to decrypt the
+	// reason.
+	Reason OpaqueReason
 }
 
 // Decode deserializes a serialized HTLCAddReject message stored in the passed
@@ -29,13 +34,17 @@ type HTLCAddReject struct {
 func (c *HTLCAddReject) Decode(r io.Reader, pver uint32) error {
 	// ChannelPoint (8)
 	// HTLCKey   (8)
+	// Reason    (var)
+	var reason []byte
 	err := readElements(r,
 		&c.ChannelPoint,
 		&c.HTLCKey,
+		&reason,
 	)
 	if err != nil {
 		return err
 	}
+	c.Reason = OpaqueReason(reason)
 
 	return nil
 }
@@ -57,6 +66,7 @@ func (c *HTLCAddReject) Encode(w io.Writer, pver uint32) error {
 	err := writeElements(w,
 		c.ChannelPoint,
 		c.HTLCKey,
+		[]byte(c.Reason),
 	)
 
 	if err != nil {
@@ -79,8 +89,8 @@ func (c *HTLCAddReject) Command() uint32 {
 //
 // This is part of the lnwire.Message interface.
 func (c *HTLCAddReject) MaxPayloadLength(uint32) uint32 {
-	// 36 + 8
-	return 44
+	// 36 + 8 + (1 + 64)
+	return 109
 }
 
 // Validate performs any necessary sanity checks to ensure all fields present
@@ -99,5 +109,6 @@ func (c *HTLCAddReject) String() string {
 	return fmt.Sprintf("\n--- Begin HTLCAddReject ---\n") +
 		fmt.Sprintf("ChannelPoint:\t\t%d\n", c.ChannelPoint) +
 		fmt.Sprintf("HTLCKey:\t\t%d\n", c.HTLCKey) +
+		fmt.Sprintf("Reason:\t\t%x\n", []byte(c.Reason)) +
 		fmt.Sprintf("--- End HTLCAddReject ---\n")
 }
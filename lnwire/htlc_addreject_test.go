@@ -7,10 +7,13 @@ import (
 )
 
 func TestHTLCAddRejectEncodeDecode(t *testing.T) {
+	reason := OpaqueReason(bytes.Repeat([]byte{0x2e}, OpaqueReasonSize))
+
 	// First create a new HTLCAR message.
 	rejectReq := &HTLCAddReject{
 		ChannelPoint: outpoint1,
 		HTLCKey:      22,
+		Reason:       reason,
 	}
 
 	// Next encode the HTLCAR message into an empty bytes buffer.
@@ -58,6 +58,13 @@ type HTLCAddRequest struct {
 	// of encryption, exposing the next hop to be used in the subsequent
 	// HTLCAddRequest message.
 	OnionBlob []byte
+
+	// TotalAmount is the total amount of the payment this HTLC is a part
+	// of, if the payment has been split across several HTLC's. A total
+	// amount of zero indicates the HTLC carries the entire payment. The
+	// destination of a multi-part payment holds each part until the
+	// parts received cover the invoice.
+	TotalAmount CreditsAmount
 }
 
 // NewHTLCAddRequest returns a new empty HTLCAddRequest message.
@@ -80,6 +87,7 @@ func (c *HTLCAddRequest) Decode(r io.Reader, pver uint32) error {
 	// ContractType(1)
 	// RedemptionHashes (numOfHashes * 32 + numOfHashes)
 	// OnionBlog
+	// TotalAmount(8)
 	err := readElements(r,
 		&c.ChannelPoint,
 		&c.Expiry,
@@ -87,6 +95,7 @@ func (c *HTLCAddRequest) Decode(r io.Reader, pver uint32) error {
 		&c.ContractType,
 		&c.RedemptionHashes,
 		&c.OnionBlob,
+		&c.TotalAmount,
 	)
 	if err != nil {
 		return err
@@ -107,6 +116,7 @@ func (c *HTLCAddRequest) Encode(w io.Writer, pver uint32) error {
 		c.ContractType,
 		c.RedemptionHashes,
 		c.OnionBlob,
+		c.TotalAmount,
 	)
 	if err != nil {
 		return err
@@ -165,5 +175,6 @@ func (c *HTLCAddRequest) String() string {
 		fmt.Sprintf("RedemptionHashes:") +
 		redemptionHashes +
 		fmt.Sprintf("OnionBlob:\t\t\t\t%x\n", c.OnionBlob) +
+		fmt.Sprintf("TotalAmount\t\t%d\n", c.TotalAmount) +
 		fmt.Sprintf("--- End HTLCAddRequest ---\n")
 }
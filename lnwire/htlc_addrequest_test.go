@@ -18,6 +18,7 @@ func TestHTLCAddRequestEncodeDecode(t *testing.T) {
 		ContractType:     uint8(17),
 		RedemptionHashes: redemptionHashes,
 		OnionBlob:        []byte{255, 0, 255, 0, 255, 0, 255, 0},
+		TotalAmount:      CreditsAmount(234567000),
 	}
 
 	// Next encode the HTLCAR message into an empty bytes buffer.
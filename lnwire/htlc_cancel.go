@@ -19,6 +19,11 @@ type CancelHTLC struct {
 	// HTLCKey references which HTLC on the remote node's commitment
 	// transaction has timed out.
 	HTLCKey HTLCKey
+
+	// Reason is an encrypted failure describing why, and where, the HTLC
+	// was cancelled. Only the sender of the HTLC is able to decrypt the
+	// reason.
+	Reason OpaqueReason
 }
 
 // Decode deserializes a serialized CancelHTLC message stored in the passed
@@ -28,13 +33,17 @@ type CancelHTLC struct {
 func (c *CancelHTLC) Decode(r io.Reader, pver uint32) error {
 	// ChannelPoint(8)
 	// HTLCKey(8)
+	// Reason(var)
+	var reason []byte
 	err := readElements(r,
 		&c.ChannelPoint,
 		&c.HTLCKey,
+		&reason,
 	)
 	if err != nil {
 		return err
 	}
+	c.Reason = OpaqueReason(reason)
 
 	return nil
 }
@@ -56,6 +65,7 @@ func (c *CancelHTLC) Encode(w io.Writer, pver uint32) error {
 	err := writeElements(w,
 		c.ChannelPoint,
 		c.HTLCKey,
+		[]byte(c.Reason),
 	)
 	if err != nil {
 		return err
@@ -77,8 +87,8 @@ func (c *CancelHTLC) Command() uint32 {
 //
 // This is part of the lnwire.Message interface.
 func (c *CancelHTLC) MaxPayloadLength(uint32) uint32 {
-	// 36 + 8
-	return 44
+	// 36 + 8 + (1 + 64)
+	return 109
 }
 
 // Validate performs any necessary sanity checks to ensure all fields present
@@ -96,5 +106,6 @@ func (c *CancelHTLC) String() string {
 	return fmt.Sprintf("\n--- Begin CancelHTLC ---\n") +
 		fmt.Sprintf("ChannelPoint:\t%d\n", c.ChannelPoint) +
 		fmt.Sprintf("HTLCKey:\t%d\n", c.HTLCKey) +
+		fmt.Sprintf("Reason:\t\t%x\n", []byte(c.Reason)) +
 		fmt.Sprintf("--- End CancelHTLC ---\n")
 }
@@ -7,10 +7,13 @@ import (
 )
 
 func TestCancelHTLCEncodeDecode(t *testing.T) {
+	reason := OpaqueReason(bytes.Repeat([]byte{0x1f}, OpaqueReasonSize))
+
 	// First create a new HTLCTR message.
 	cancelMsg := &CancelHTLC{
 		ChannelPoint: outpoint1,
 		HTLCKey:      22,
+		Reason:       reason,
 	}
 
 	// Next encode the HTLCTR message into an empty bytes buffer.
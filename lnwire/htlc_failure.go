@@ -0,0 +1,273 @@
+package lnwire
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/btcec"
+)
+
+// FailCode describes why an HTLC was cancelled. The code is encrypted by the
+// node which cancelled the HTLC, then propagated backwards along the route
+// such that the sender of the payment learns both why, and where, the
+// payment failed.
+type FailCode uint16
+
+const (
+	// FailUnknown is used when the reason for the failure is unknown, or
+	// can't be disclosed.
+	FailUnknown FailCode = 0
+
+	// FailUnknownPaymentHash indicates the final node of the route
+	// doesn't have an invoice matching the payment hash of the HTLC.
+	FailUnknownPaymentHash FailCode = 1
+
+	// FailInvoiceNotPayable indicates the invoice paid by the HTLC has
+	// either been cancelled or has expired.
+	FailInvoiceNotPayable FailCode = 2
+
+	// FailAmountTooLow indicates the HTLC pays less than the value of the
+	// invoice it pays to.
+	FailAmountTooLow FailCode = 3
+
+	// FailAmountTooHigh indicates the HTLC exceeds the value of the
+	// invoice it pays to by more than the payee is willing to accept.
+	FailAmountTooHigh FailCode = 4
+
+	// FailExpiryTooSoon indicates the HTLC's expiry was too close to the
+	// current height to safely settle or forward it.
+	FailExpiryTooSoon FailCode = 5
+
+	// FailUnknownNextPeer indicates the next hop within the route of the
+	// HTLC is unknown to the forwarding node.
+	FailUnknownNextPeer FailCode = 6
+
+	// FailHTLCTimeout indicates the HTLC was cancelled back as it was
+	// about to expire.
+	FailHTLCTimeout FailCode = 7
+
+	// FailInsufficientCapacity indicates none of the forwarding node's
+	// links to the next hop had enough available bandwidth to carry the
+	// HTLC.
+	FailInsufficientCapacity FailCode = 8
+
+	// FailFeeInsufficient indicates the amount of the incoming HTLC
+	// doesn't cover the fee charged by the forwarding node, or that the
+	// amount left to forward is below the minimum HTLC accepted by the
+	// outgoing link.
+	FailFeeInsufficient FailCode = 9
+
+	// FailIncorrectExpiryDelta indicates the expiry of the incoming HTLC
+	// doesn't leave room for the time-lock delta required by the
+	// outgoing link.
+	FailIncorrectExpiryDelta FailCode = 10
+)
+
+// String returns a human readable version of the FailCode.
+func (c FailCode) String() string {
+	switch c {
+	case FailUnknown:
+		return "Unknown"
+	case FailUnknownPaymentHash:
+		return "UnknownPaymentHash"
+	case FailInvoiceNotPayable:
+		return "InvoiceNotPayable"
+	case FailAmountTooLow:
+		return "AmountTooLow"
+	case FailAmountTooHigh:
+		return "AmountTooHigh"
+	case FailExpiryTooSoon:
+		return "ExpiryTooSoon"
+	case FailUnknownNextPeer:
+		return "UnknownNextPeer"
+	case FailHTLCTimeout:
+		return "HTLCTimeout"
+	case FailInsufficientCapacity:
+		return "InsufficientCapacity"
+	case FailFeeInsufficient:
+		return "FeeInsufficient"
+	case FailIncorrectExpiryDelta:
+		return "IncorrectExpiryDelta"
+	default:
+		return "<unknown>"
+	}
+}
+
+const (
+	// failurePayloadSize is the size of the padded failure payload. The
+	// payload is padded to a fixed size so all failures are
+	// indistinguishable to the nodes relaying them.
+	failurePayloadSize = 32
+
+	// OpaqueReasonSize is the size of an encrypted failure: a MAC over
+	// the failure payload, followed by the payload itself.
+	OpaqueReasonSize = fastsha256.Size + failurePayloadSize
+)
+
+var (
+	// ErrUndecryptableFailure is returned when an encrypted failure can't
+	// be attributed to any hop within the route of the HTLC.
+	ErrUndecryptableFailure = errors.New("unable to decrypt failure")
+
+	// errMalformedFailure is returned when attempting to decrypt an
+	// encrypted failure of an unexpected size.
+	errMalformedFailure = errors.New("malformed failure")
+)
+
+// OpaqueReason is an encrypted failure. Only the sender of the HTLC is able to
+// decrypt the failure, which reveals the FailCode along with the hop which
+// originated the failure. Each node along the route adds an additional layer
+// of encryption as the failure is propagated backwards.
+type OpaqueReason []byte
+
+// FailureObfuscator is used by a node along the route of an HTLC to encrypt
+// the failures it originates, and to add a layer of encryption to the
+// failures it relays. Each obfuscator is keyed by the secret shared with the
+// sender of the HTLC, which is derived by sphinx when processing the onion
+// packet carried within the HTLC.
+type FailureObfuscator struct {
+	sharedSecret [32]byte
+}
+
+// NewFailureObfuscator creates a new failure obfuscator from the secret
+// shared with the sender of the HTLC at this hop within the route.
+func NewFailureObfuscator(sharedSecret [32]byte) *FailureObfuscator {
+	return &FailureObfuscator{sharedSecret: sharedSecret}
+}
+
+// EncryptFailure creates a new encrypted failure carrying the passed code.
+// This should be used by the node which originates the failure.
+func (o *FailureObfuscator) EncryptFailure(code FailCode) OpaqueReason {
+	var payload [failurePayloadSize]byte
+	binary.BigEndian.PutUint16(payload[:2], uint16(code))
+
+	mac := hmac.New(fastsha256.New, generateKey("um", o.sharedSecret))
+	mac.Write(payload[:])
+
+	failure := make([]byte, 0, OpaqueReasonSize)
+	failure = append(failure, mac.Sum(nil)...)
+	failure = append(failure, payload[:]...)
+
+	return o.ObfuscateFailure(failure)
+}
+
+// ObfuscateFailure adds a layer of encryption to an encrypted failure
+// received from the next hop within the route. This should be used by nodes
+// relaying a failure back towards the sender.
+func (o *FailureObfuscator) ObfuscateFailure(reason OpaqueReason) OpaqueReason {
+	return xorStream(generateKey("ammag", o.sharedSecret), reason)
+}
+
+// FailureCircuit is used by the sender of an HTLC to decrypt any failure
+// propagated back along the route. The circuit holds the session key used to
+// construct the sphinx packet of the HTLC, from which the secret shared with
+// each hop is derived.
+type FailureCircuit struct {
+	sessionKey *btcec.PrivateKey
+	route      []*btcec.PublicKey
+}
+
+// NewFailureCircuit creates a new failure circuit for an HTLC sent along the
+// passed route, whose sphinx packet was constructed using the passed session
+// key.
+func NewFailureCircuit(sessionKey *btcec.PrivateKey,
+	route []*btcec.PublicKey) *FailureCircuit {
+
+	return &FailureCircuit{
+		sessionKey: sessionKey,
+		route:      route,
+	}
+}
+
+// Route returns the route of the HTLC the failure circuit was created for.
+func (c *FailureCircuit) Route() []*btcec.PublicKey {
+	return c.route
+}
+
+// DecryptFailure attempts to decrypt a failure propagated back along the
+// route, returning the index of the hop which originated the failure within
+// the route, along with the failure code.
+func (c *FailureCircuit) DecryptFailure(reason OpaqueReason) (int, FailCode,
+	error) {
+
+	if len(reason) != OpaqueReasonSize {
+		return 0, 0, errMalformedFailure
+	}
+
+	// Sphinx blinds the ephemeral key of the packet at each hop along the
+	// route, so we mirror the blinding in order to derive the secret
+	// shared with each hop in turn.
+	ephemeralPriv := new(big.Int).Set(c.sessionKey.D)
+	for i, hopKey := range c.route {
+		priv, ephemeralKey := btcec.PrivKeyFromBytes(btcec.S256(),
+			ephemeralPriv.Bytes())
+		sharedSecret := fastsha256.Sum256(btcec.GenerateSharedSecret(priv,
+			hopKey))
+
+		// Strip off this hop's layer of encryption. If the MAC is
+		// valid under this hop's key, then the hop originated the
+		// failure.
+		reason = xorStream(generateKey("ammag", sharedSecret), reason)
+
+		mac := hmac.New(fastsha256.New, generateKey("um", sharedSecret))
+		mac.Write(reason[fastsha256.Size:])
+		if hmac.Equal(mac.Sum(nil), reason[:fastsha256.Size]) {
+			payload := reason[fastsha256.Size:]
+			code := FailCode(binary.BigEndian.Uint16(payload[:2]))
+			return i, code, nil
+		}
+
+		blindingFactor := computeBlindingFactor(ephemeralKey,
+			sharedSecret)
+		ephemeralPriv.Mul(ephemeralPriv,
+			new(big.Int).SetBytes(blindingFactor[:]))
+		ephemeralPriv.Mod(ephemeralPriv, btcec.S256().N)
+	}
+
+	return 0, 0, ErrUndecryptableFailure
+}
+
+// computeBlindingFactor computes the factor sphinx uses to blind the
+// ephemeral key of the packet passed to the next hop, ensuring successive hops
+// are unable to link the packets they receive.
+func computeBlindingFactor(ephemeralKey *btcec.PublicKey,
+	sharedSecret [32]byte) [32]byte {
+
+	var b bytes.Buffer
+	b.Write(ephemeralKey.SerializeCompressed())
+	b.Write(sharedSecret[:])
+
+	return fastsha256.Sum256(b.Bytes())
+}
+
+// generateKey derives a key for the passed purpose from a shared secret.
+func generateKey(keyType string, sharedSecret [32]byte) []byte {
+	mac := hmac.New(fastsha256.New, []byte(keyType))
+	mac.Write(sharedSecret[:])
+	return mac.Sum(nil)
+}
+
+// xorStream returns the passed data XOR'd with a pseudo-random stream
+// generated from the passed key.
+func xorStream(key []byte, data []byte) []byte {
+	out := make([]byte, len(data))
+
+	var counter [4]byte
+	for i := 0; i < len(data); i += fastsha256.Size {
+		binary.BigEndian.PutUint32(counter[:], uint32(i/fastsha256.Size))
+
+		mac := hmac.New(fastsha256.New, key)
+		mac.Write(counter[:])
+		block := mac.Sum(nil)
+
+		for j := 0; j < fastsha256.Size && i+j < len(data); j++ {
+			out[i+j] = data[i+j] ^ block[j]
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,89 @@
+package lnwire
+
+import (
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/btcec"
+)
+
+func TestFailureCircuitDecrypt(t *testing.T) {
+	const numHops = 4
+
+	// Create a route of several hops, each with their own identity key.
+	hopPrivs := make([]*btcec.PrivateKey, numHops)
+	route := make([]*btcec.PublicKey, numHops)
+	for i := 0; i < numHops; i++ {
+		priv, err := btcec.NewPrivateKey(btcec.S256())
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		hopPrivs[i] = priv
+		route[i] = priv.PubKey()
+	}
+
+	sessionKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate session key: %v", err)
+	}
+	circuit := NewFailureCircuit(sessionKey, route)
+
+	// Each hop derives its obfuscator from the secret it shares with the
+	// sender, mirroring the way sphinx derives the shared secret from the
+	// ephemeral key of the packet, then blinds the key for the next hop.
+	obfuscators := make([]*FailureObfuscator, numHops)
+	ephemeralKey := sessionKey.PubKey()
+	for i := 0; i < numHops; i++ {
+		sharedSecret := fastsha256.Sum256(btcec.GenerateSharedSecret(
+			hopPrivs[i], ephemeralKey))
+		obfuscators[i] = NewFailureObfuscator(sharedSecret)
+
+		blindingFactor := computeBlindingFactor(ephemeralKey,
+			sharedSecret)
+		nextX, nextY := btcec.S256().ScalarMult(ephemeralKey.X,
+			ephemeralKey.Y, blindingFactor[:])
+		ephemeralKey = &btcec.PublicKey{
+			Curve: btcec.S256(),
+			X:     nextX,
+			Y:     nextY,
+		}
+	}
+
+	// A failure originated at each hop should be attributed to that hop
+	// once each prior hop has added its own layer of encryption.
+	for failingHop := 0; failingHop < numHops; failingHop++ {
+		reason := obfuscators[failingHop].EncryptFailure(
+			FailInsufficientCapacity)
+		if len(reason) != OpaqueReasonSize {
+			t.Fatalf("encrypted failure has wrong size: expected %v, "+
+				"got %v", OpaqueReasonSize, len(reason))
+		}
+		for i := failingHop - 1; i >= 0; i-- {
+			reason = obfuscators[i].ObfuscateFailure(reason)
+		}
+
+		hopIndex, code, err := circuit.DecryptFailure(reason)
+		if err != nil {
+			t.Fatalf("unable to decrypt failure: %v", err)
+		}
+		if hopIndex != failingHop {
+			t.Fatalf("failure attributed to wrong hop: expected %v, "+
+				"got %v", failingHop, hopIndex)
+		}
+		if code != FailInsufficientCapacity {
+			t.Fatalf("wrong failure code: expected %v, got %v",
+				FailInsufficientCapacity, code)
+		}
+	}
+
+	// A failure which has been tampered with shouldn't be attributed to
+	// any hop.
+	reason := obfuscators[numHops-1].EncryptFailure(FailUnknownPaymentHash)
+	reason[len(reason)-1] ^= 0x01
+	for i := numHops - 2; i >= 0; i-- {
+		reason = obfuscators[i].ObfuscateFailure(reason)
+	}
+	if _, _, err := circuit.DecryptFailure(reason); err != ErrUndecryptableFailure {
+		t.Fatalf("expected undecryptable failure, instead got: %v", err)
+	}
+}
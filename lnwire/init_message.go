@@ -0,0 +1,105 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+)
+
+// Init is the first message sent by each side of a newly established
+// connection, before any other messages. The message advertises the features
+// supported, or required by the sender. Global features are those which
+// affect the network as a whole, such as the routing protocol, while local
+// features only affect the direct connection between the two peers. Upon
+// receipt, the connection must be dropped if the sender requires any feature
+// unknown to the receiver.
+type Init struct {
+	// GlobalFeatures is the set of features supported by the sender
+	// which are relevant to the network as a whole.
+	GlobalFeatures FeatureVector
+
+	// LocalFeatures is the set of features supported by the sender which
+	// are relevant only to the direct connection with the receiver.
+	LocalFeatures FeatureVector
+}
+
+// NewInitMessage creates a new Init message advertising the passed features.
+func NewInitMessage(globalFeatures, localFeatures FeatureVector) *Init {
+	return &Init{
+		GlobalFeatures: globalFeatures,
+		LocalFeatures:  localFeatures,
+	}
+}
+
+// A compile time check to ensure Init implements the lnwire.Message
+// interface.
+var _ Message = (*Init)(nil)
+
+// Decode deserializes a serialized Init message stored in the passed
+// io.Reader observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (i *Init) Decode(r io.Reader, pver uint32) error {
+	// GlobalFeatures (3 + len)
+	// LocalFeatures (3 + len)
+	err := readElements(r,
+		&i.GlobalFeatures,
+		&i.LocalFeatures,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target Init into the passed io.Writer observing the
+// protocol version specified.
+//
+// This is part of the lnwire.Message interface.
+func (i *Init) Encode(w io.Writer, pver uint32) error {
+	err := writeElements(w,
+		i.GlobalFeatures,
+		i.LocalFeatures,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the integer uniquely identifying this message type on the
+// wire.
+//
+// This is part of the lnwire.Message interface.
+func (i *Init) Command() uint32 {
+	return CmdInit
+}
+
+// MaxPayloadLength returns the maximum allowed payload size for an Init
+// message observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (i *Init) MaxPayloadLength(uint32) uint32 {
+	// 2 * (3 + 8192)
+	return 16390
+}
+
+// Validate performs any necessary sanity checks to ensure all fields present
+// on the Init are valid.
+//
+// This is part of the lnwire.Message interface.
+func (i *Init) Validate() error {
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the target Init.
+//
+// This is part of the lnwire.Message interface.
+func (i *Init) String() string {
+	return fmt.Sprintf("\n--- Begin Init ---\n") +
+		fmt.Sprintf("GlobalFeatures:\t%v\n", i.GlobalFeatures) +
+		fmt.Sprintf("LocalFeatures:\t%v\n", i.LocalFeatures) +
+		fmt.Sprintf("--- End Init ---\n")
+}
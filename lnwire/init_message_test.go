@@ -0,0 +1,69 @@
+package lnwire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestInitEncodeDecode(t *testing.T) {
+	initMsg := NewInitMessage(
+		NewFeatureVector(),
+		NewFeatureVector(InitialRoutingSyncOptional, 12, 101),
+	)
+
+	// Next encode the init message into an empty bytes buffer.
+	var b bytes.Buffer
+	if err := initMsg.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode Init: %v", err)
+	}
+
+	// Deserialize the encoded init message into a new empty struct.
+	initMsg2 := &Init{}
+	if err := initMsg2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode Init: %v", err)
+	}
+
+	// Assert equality of the two instances.
+	if !reflect.DeepEqual(initMsg, initMsg2) {
+		t.Fatalf("encode/decode error messages don't match %#v vs %#v",
+			initMsg, initMsg2)
+	}
+}
+
+func TestFeatureVectorSerialization(t *testing.T) {
+	// Bit 0 should be the least significant bit of the final byte, with
+	// the vector being only as long as required by the highest bit.
+	f := NewFeatureVector(0, 3, 9)
+	expected := []byte{0x02, 0x09}
+	if !bytes.Equal(f.serialize(), expected) {
+		t.Fatalf("expected serialization %x, instead got %x",
+			expected, f.serialize())
+	}
+
+	if !reflect.DeepEqual(deserializeFeatureVector(expected), f) {
+		t.Fatalf("feature vectors don't match")
+	}
+}
+
+func TestFeatureVectorUnknownRequired(t *testing.T) {
+	// Unknown optional features should be ignored, as should known
+	// features regardless of whether they're required.
+	f := NewFeatureVector(InitialRoutingSyncOptional,
+		InitialRoutingSyncOptional-1, 101)
+	if unknown := f.UnknownRequiredFeatures(); len(unknown) != 0 {
+		t.Fatalf("expected no unknown required features, instead "+
+			"got %v", unknown)
+	}
+	if !f.HasFeature(InitialRoutingSyncOptional) {
+		t.Fatalf("initial routing sync should be signalled")
+	}
+
+	// However, unknown required features must be detected.
+	f.Set(100)
+	unknown := f.UnknownRequiredFeatures()
+	if len(unknown) != 1 || unknown[0] != 100 {
+		t.Fatalf("expected unknown required feature 100, instead "+
+			"got %v", unknown)
+	}
+}
@@ -16,10 +16,36 @@ import (
 // the wire protocol.
 const MaxSliceLength = 65535
 
+// MaxHTLCNumber is the maximum number of HTLC's either side of a channel may
+// have outstanding at once. Each HTLC adds an output to the commitment
+// transaction, so this bound ensures that a commitment transaction carrying
+// the maximum number of HTLC's on both sides still falls under the maximum
+// standard transaction weight.
+const MaxHTLCNumber = 483
+
 // PkScript is simple type definition which represents a raw serialized public
 // key script.
 type PkScript []byte
 
+const (
+	// maxChangeScriptLength is the maximum allowed length of the public key
+	// script of a change output. This is the size of a P2WSH public key
+	// script, the largest of the supported script templates.
+	maxChangeScriptLength = 34
+
+	// maxWitnessItems is the maximum number of items allowed within the
+	// witness of a funding transaction input.
+	maxWitnessItems = 4
+)
+
+// InputScript houses the witness and sigScript required to redeem an input to
+// a funding transaction. Both fields are transmitted in order to accommodate
+// inputs which spend nested p2sh outputs.
+type InputScript struct {
+	Witness   [][]byte
+	ScriptSig []byte
+}
+
 // HTLCKey is an identifier used to uniquely identify any HTLC's transmitted
 // between Alice and Bob. In order to cancel, timeout, or settle HTLC's this
 // identifier should be used to allow either side to easily locate and modify
@@ -104,6 +130,10 @@ func writeElement(w io.Writer, element interface{}) error {
 		if err := binary.Write(w, binary.BigEndian, int64(e)); err != nil {
 			return err
 		}
+	case FeatureVector:
+		if err := wire.WriteVarBytes(w, 0, e.serialize()); err != nil {
+			return err
+		}
 	case btcutil.Amount:
 		if err := binary.Write(w, binary.BigEndian, int64(e)); err != nil {
 			return err
@@ -256,6 +286,75 @@ func writeElement(w io.Writer, element interface{}) error {
 		if _, err := w.Write(idx[:]); err != nil {
 			return err
 		}
+	case []*wire.TxOut:
+		if len(e) > 127 {
+			return fmt.Errorf("Too many txouts")
+		}
+
+		// Write out the number of txouts, followed by each txout in
+		// series.
+		if err := writeElement(w, uint8(len(e))); err != nil {
+			return err
+		}
+		for _, out := range e {
+			if err := writeElement(w, out); err != nil {
+				return err
+			}
+		}
+	case *wire.TxOut:
+		// The value of the output is written first, followed by its
+		// public key script.
+		if err := binary.Write(w, binary.BigEndian, e.Value); err != nil {
+			return err
+		}
+
+		if len(e.PkScript) > maxChangeScriptLength {
+			return fmt.Errorf("PkScript too long!")
+		}
+		if err := wire.WriteVarBytes(w, 0, e.PkScript); err != nil {
+			return err
+		}
+	case []*InputScript:
+		if len(e) > 127 {
+			return fmt.Errorf("Too many input scripts")
+		}
+
+		// Write out the number of input scripts, followed by each
+		// input script in series.
+		if err := writeElement(w, uint8(len(e))); err != nil {
+			return err
+		}
+		for _, script := range e {
+			if err := writeElement(w, script); err != nil {
+				return err
+			}
+		}
+	case *InputScript:
+		// First write out the witness stack, prefixed by the number of
+		// items within it.
+		if len(e.Witness) > maxWitnessItems {
+			return fmt.Errorf("Too many witness items")
+		}
+		if err := writeElement(w, uint8(len(e.Witness))); err != nil {
+			return err
+		}
+		for _, item := range e.Witness {
+			if len(item) > txscript.MaxScriptElementSize {
+				return fmt.Errorf("Witness item too long!")
+			}
+			if err := wire.WriteVarBytes(w, 0, item); err != nil {
+				return err
+			}
+		}
+
+		// Finally the sigScript, which will be empty unless the input
+		// spends a nested p2sh output.
+		if len(e.ScriptSig) > txscript.MaxScriptElementSize {
+			return fmt.Errorf("ScriptSig too long!")
+		}
+		if err := wire.WriteVarBytes(w, 0, e.ScriptSig); err != nil {
+			return err
+		}
 	case *wire.OutPoint:
 		// TODO(roasbeef): consolidate with above
 		// First write out the previous txid.
@@ -332,6 +431,13 @@ func readElement(r io.Reader, element interface{}) error {
 			return err
 		}
 		*e = HTLCKey(int64(binary.BigEndian.Uint64(b[:])))
+	case *FeatureVector:
+		b, err := wire.ReadVarBytes(r, 0, maxFeatureVectorSize,
+			"feature vector")
+		if err != nil {
+			return err
+		}
+		*e = deserializeFeatureVector(b)
 	case *btcutil.Amount:
 		var b [8]byte
 		if _, err := io.ReadFull(r, b[:]); err != nil {
@@ -497,6 +603,81 @@ func readElement(r io.Reader, element interface{}) error {
 		}
 		(*e).PreviousOutPoint.Index = binary.BigEndian.Uint32(idxBytes[:])
 		return nil
+	case *[]*wire.TxOut:
+		var numOuts uint8
+		if err := readElement(r, &numOuts); err != nil {
+			return err
+		}
+		if numOuts > 127 {
+			return fmt.Errorf("Too many txouts")
+		}
+
+		txouts := make([]*wire.TxOut, 0, numOuts)
+		for i := uint8(0); i < numOuts; i++ {
+			txout := &wire.TxOut{}
+			if err := readElement(r, &txout); err != nil {
+				return err
+			}
+			txouts = append(txouts, txout)
+		}
+		*e = txouts
+	case **wire.TxOut:
+		var valueBytes [8]byte
+		if _, err := io.ReadFull(r, valueBytes[:]); err != nil {
+			return err
+		}
+		(*e).Value = int64(binary.BigEndian.Uint64(valueBytes[:]))
+
+		pkScript, err := wire.ReadVarBytes(r, 0, maxChangeScriptLength,
+			"pkscript")
+		if err != nil {
+			return err
+		}
+		(*e).PkScript = pkScript
+	case *[]*InputScript:
+		var numScripts uint8
+		if err := readElement(r, &numScripts); err != nil {
+			return err
+		}
+		if numScripts > 127 {
+			return fmt.Errorf("Too many input scripts")
+		}
+
+		scripts := make([]*InputScript, 0, numScripts)
+		for i := uint8(0); i < numScripts; i++ {
+			script := &InputScript{}
+			if err := readElement(r, &script); err != nil {
+				return err
+			}
+			scripts = append(scripts, script)
+		}
+		*e = scripts
+	case **InputScript:
+		var numItems uint8
+		if err := readElement(r, &numItems); err != nil {
+			return err
+		}
+		if numItems > maxWitnessItems {
+			return fmt.Errorf("Too many witness items")
+		}
+
+		var witness [][]byte
+		for i := uint8(0); i < numItems; i++ {
+			item, err := wire.ReadVarBytes(r, 0,
+				txscript.MaxScriptElementSize, "witness item")
+			if err != nil {
+				return err
+			}
+			witness = append(witness, item)
+		}
+		(*e).Witness = witness
+
+		sigScript, err := wire.ReadVarBytes(r, 0,
+			txscript.MaxScriptElementSize, "sigScript")
+		if err != nil {
+			return err
+		}
+		(*e).ScriptSig = sigScript
 	case **wire.OutPoint:
 		// TODO(roasbeef): consolidate with above
 		var h [32]byte
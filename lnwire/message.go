@@ -22,6 +22,9 @@ const MaxMessagePayload = 1024 * 1024 * 32 //  32MB
 
 // Commands used in lightning message headers which detail the type of message.
 const (
+	// Commands for the initial exchange of features between peers.
+	CmdInit = uint32(10)
+
 	// Commands for opening a channel funded by one party (single funder).
 	CmdSingleFundingRequest      = uint32(100)
 	CmdSingleFundingResponse     = uint32(110)
@@ -29,6 +32,12 @@ const (
 	CmdSingleFundingSignComplete = uint32(130)
 	CmdSingleFundingOpenProof    = uint32(140)
 
+	// Commands for opening a channel funded by both parties (dual funder).
+	CmdDualFundingRequest      = uint32(200)
+	CmdDualFundingResponse     = uint32(210)
+	CmdDualFundingComplete     = uint32(220)
+	CmdDualFundingSignComplete = uint32(230)
+
 	// Commands for the workflow of cooperatively closing an active channel.
 	CmdCloseRequest  = uint32(300)
 	CmdCloseComplete = uint32(310)
@@ -44,6 +53,9 @@ const (
 	CmdCommitSignature  = uint32(2000)
 	CmdCommitRevocation = uint32(2010)
 
+	// Commands for resynchronizing channel state upon reconnection.
+	CmdChannelReestablish = uint32(2020)
+
 	// Commands for routing
 	CmdNeighborHelloMessage        = uint32(3000)
 	CmdNeighborUpdMessage          = uint32(3010)
@@ -51,9 +63,14 @@ const (
 	CmdNeighborRstMessage          = uint32(3030)
 	CmdRoutingTableRequestMessage  = uint32(3040)
 	CmdRoutingTableTransferMessage = uint32(3050)
+	CmdChannelPolicyUpdate         = uint32(3060)
 
 	// Commands for reporting protocol errors.
 	CmdErrorGeneric = uint32(4000)
+
+	// Commands for detecting unresponsive peers.
+	CmdPing = uint32(5000)
+	CmdPong = uint32(5010)
 )
 
 // Message is an interface that defines a lightning wire protocol message. The
@@ -74,6 +91,8 @@ func makeEmptyMessage(command uint32) (Message, error) {
 	var msg Message
 
 	switch command {
+	case CmdInit:
+		msg = &Init{}
 	case CmdSingleFundingRequest:
 		msg = &SingleFundingRequest{}
 	case CmdSingleFundingResponse:
@@ -84,6 +103,14 @@ func makeEmptyMessage(command uint32) (Message, error) {
 		msg = &SingleFundingSignComplete{}
 	case CmdSingleFundingOpenProof:
 		msg = &SingleFundingOpenProof{}
+	case CmdDualFundingRequest:
+		msg = &DualFundingRequest{}
+	case CmdDualFundingResponse:
+		msg = &DualFundingResponse{}
+	case CmdDualFundingComplete:
+		msg = &DualFundingComplete{}
+	case CmdDualFundingSignComplete:
+		msg = &DualFundingSignComplete{}
 	case CmdCloseRequest:
 		msg = &CloseRequest{}
 	case CmdCloseComplete:
@@ -100,6 +127,8 @@ func makeEmptyMessage(command uint32) (Message, error) {
 		msg = &CommitSignature{}
 	case CmdCommitRevocation:
 		msg = &CommitRevocation{}
+	case CmdChannelReestablish:
+		msg = &ChannelReestablish{}
 	case CmdErrorGeneric:
 		msg = &ErrorGeneric{}
 	case CmdNeighborHelloMessage:
@@ -114,6 +143,12 @@ func makeEmptyMessage(command uint32) (Message, error) {
 		msg = &RoutingTableRequestMessage{}
 	case CmdRoutingTableTransferMessage:
 		msg = &RoutingTableTransferMessage{}
+	case CmdChannelPolicyUpdate:
+		msg = &ChannelPolicyUpdate{}
+	case CmdPing:
+		msg = &Ping{}
+	case CmdPong:
+		msg = &Pong{}
 	default:
 		return nil, fmt.Errorf("unhandled command [%d]", command)
 	}
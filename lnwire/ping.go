@@ -0,0 +1,113 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+)
+
+// MaxPongBytes is the largest number of bytes which may be requested within
+// the pong response to a ping. Pings requesting more bytes than this are used
+// purely to pad the connection, and shouldn't be responded to.
+const MaxPongBytes = 65531
+
+// Ping is sent periodically by each side of a connection in order to detect
+// unresponsive peers, and to measure the round trip latency of the
+// connection. The receiver responds with a Pong message carrying
+// NumPongBytes bytes. Additionally, the padding of both messages allows
+// either side to obscure the traffic of the connection.
+type Ping struct {
+	// NumPongBytes is the number of bytes the responding Pong message
+	// should carry.
+	NumPongBytes uint16
+
+	// PaddingBytes is a set of opaque bytes used to pad the message. The
+	// contents of the padding should be ignored by the receiver.
+	PaddingBytes []byte
+}
+
+// NewPing creates a new Ping message requesting a Pong of the passed size.
+func NewPing(numPongBytes uint16, padding []byte) *Ping {
+	return &Ping{
+		NumPongBytes: numPongBytes,
+		PaddingBytes: padding,
+	}
+}
+
+// A compile time check to ensure Ping implements the lnwire.Message
+// interface.
+var _ Message = (*Ping)(nil)
+
+// Decode deserializes a serialized Ping message stored in the passed
+// io.Reader observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (p *Ping) Decode(r io.Reader, pver uint32) error {
+	// NumPongBytes (2)
+	// PaddingBytes (3 + len)
+	err := readElements(r,
+		&p.NumPongBytes,
+		&p.PaddingBytes,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target Ping into the passed io.Writer observing the
+// protocol version specified.
+//
+// This is part of the lnwire.Message interface.
+func (p *Ping) Encode(w io.Writer, pver uint32) error {
+	err := writeElements(w,
+		p.NumPongBytes,
+		p.PaddingBytes,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the integer uniquely identifying this message type on the
+// wire.
+//
+// This is part of the lnwire.Message interface.
+func (p *Ping) Command() uint32 {
+	return CmdPing
+}
+
+// MaxPayloadLength returns the maximum allowed payload size for a Ping
+// message observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (p *Ping) MaxPayloadLength(uint32) uint32 {
+	// 2 + 3 + 65535
+	return 65540
+}
+
+// Validate performs any necessary sanity checks to ensure all fields present
+// on the Ping are valid.
+//
+// This is part of the lnwire.Message interface.
+func (p *Ping) Validate() error {
+	if len(p.PaddingBytes) > MaxSliceLength {
+		return fmt.Errorf("padding too long: %v bytes",
+			len(p.PaddingBytes))
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the target Ping.
+//
+// This is part of the lnwire.Message interface.
+func (p *Ping) String() string {
+	return fmt.Sprintf("\n--- Begin Ping ---\n") +
+		fmt.Sprintf("NumPongBytes:\t%d\n", p.NumPongBytes) +
+		fmt.Sprintf("PaddingBytes:\t%d bytes\n", len(p.PaddingBytes)) +
+		fmt.Sprintf("--- End Ping ---\n")
+}
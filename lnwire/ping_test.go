@@ -0,0 +1,58 @@
+package lnwire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestPingEncodeDecode(t *testing.T) {
+	ping := NewPing(100, bytes.Repeat([]byte{0}, 42))
+
+	// Next encode the ping message into an empty bytes buffer.
+	var b bytes.Buffer
+	if err := ping.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode Ping: %v", err)
+	}
+
+	// Deserialize the encoded ping message into a new empty struct.
+	ping2 := &Ping{}
+	if err := ping2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode Ping: %v", err)
+	}
+
+	// Assert equality of the two instances.
+	if !reflect.DeepEqual(ping, ping2) {
+		t.Fatalf("encode/decode error messages don't match %#v vs %#v",
+			ping, ping2)
+	}
+}
+
+func TestPongEncodeDecode(t *testing.T) {
+	pong := NewPong(bytes.Repeat([]byte{0}, 100))
+
+	// Next encode the pong message into an empty bytes buffer.
+	var b bytes.Buffer
+	if err := pong.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode Pong: %v", err)
+	}
+
+	// Deserialize the encoded pong message into a new empty struct.
+	pong2 := &Pong{}
+	if err := pong2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode Pong: %v", err)
+	}
+
+	// Assert equality of the two instances.
+	if !reflect.DeepEqual(pong, pong2) {
+		t.Fatalf("encode/decode error messages don't match %#v vs %#v",
+			pong, pong2)
+	}
+
+	// A pong carrying more than the maximum number of bytes should be
+	// rejected.
+	pong.PongBytes = make([]byte, MaxPongBytes+1)
+	if err := pong.Validate(); err == nil {
+		t.Fatalf("oversized pong should be invalid")
+	}
+}
@@ -0,0 +1,96 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+)
+
+// Pong is sent in response to a Ping message. The Pong carries the number of
+// bytes requested within the Ping, allowing the sender of the Ping to verify
+// the response, and measure the round trip latency of the connection.
+type Pong struct {
+	// PongBytes is a set of opaque bytes whose length is dictated by the
+	// NumPongBytes field of the Ping being responded to.
+	PongBytes []byte
+}
+
+// NewPong creates a new Pong message carrying the passed bytes.
+func NewPong(pongBytes []byte) *Pong {
+	return &Pong{
+		PongBytes: pongBytes,
+	}
+}
+
+// A compile time check to ensure Pong implements the lnwire.Message
+// interface.
+var _ Message = (*Pong)(nil)
+
+// Decode deserializes a serialized Pong message stored in the passed
+// io.Reader observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (p *Pong) Decode(r io.Reader, pver uint32) error {
+	// PongBytes (3 + len)
+	err := readElements(r,
+		&p.PongBytes,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target Pong into the passed io.Writer observing the
+// protocol version specified.
+//
+// This is part of the lnwire.Message interface.
+func (p *Pong) Encode(w io.Writer, pver uint32) error {
+	err := writeElements(w,
+		p.PongBytes,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the integer uniquely identifying this message type on the
+// wire.
+//
+// This is part of the lnwire.Message interface.
+func (p *Pong) Command() uint32 {
+	return CmdPong
+}
+
+// MaxPayloadLength returns the maximum allowed payload size for a Pong
+// message observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (p *Pong) MaxPayloadLength(uint32) uint32 {
+	// 3 + 65531
+	return 65534
+}
+
+// Validate performs any necessary sanity checks to ensure all fields present
+// on the Pong are valid.
+//
+// This is part of the lnwire.Message interface.
+func (p *Pong) Validate() error {
+	if len(p.PongBytes) > MaxPongBytes {
+		return fmt.Errorf("pong too long: %v bytes", len(p.PongBytes))
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the target Pong.
+//
+// This is part of the lnwire.Message interface.
+func (p *Pong) String() string {
+	return fmt.Sprintf("\n--- Begin Pong ---\n") +
+		fmt.Sprintf("PongBytes:\t%d bytes\n", len(p.PongBytes)) +
+		fmt.Sprintf("--- End Pong ---\n")
+}
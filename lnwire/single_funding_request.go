@@ -49,6 +49,21 @@ type SingleFundingRequest struct {
 	// in the pay-to-self output of both commitment transactions.
 	CsvDelay uint32
 
+	// MaxAcceptedHTLCs is the maximum number of HTLC's the initiator will
+	// accept within their commitment transaction at once. This value MUST
+	// NOT exceed MaxHTLCNumber.
+	MaxAcceptedHTLCs uint16
+
+	// MaxValueInFlight is the maximum total value of outstanding HTLC's
+	// the initiator will accept within their commitment transaction at
+	// once. This limits the funds the initiator may have locked up within
+	// HTLC's offered by the responder.
+	MaxValueInFlight btcutil.Amount
+
+	// HtlcMinimum is the smallest HTLC the initiator will accept. HTLC's
+	// below this amount would be uneconomical to sweep on-chain.
+	HtlcMinimum btcutil.Amount
+
 	// CommitmentKey is key the initiator of the funding workflow wishes to
 	// use within their versino of the commitment transaction for any
 	// delayed (CSV) or immediate outputs to them.
@@ -73,8 +88,9 @@ type SingleFundingRequest struct {
 
 // NewSingleFundingRequest creates, and returns a new empty SingleFundingRequest.
 func NewSingleFundingRequest(chanID uint64, chanType uint8, coinType uint64,
-	fee btcutil.Amount, amt btcutil.Amount, delay uint32, ck,
-	cdp *btcec.PublicKey, deliveryScript PkScript) *SingleFundingRequest {
+	fee btcutil.Amount, amt btcutil.Amount, delay uint32, maxHtlcs uint16,
+	maxValue, htlcMin btcutil.Amount, ck, cdp *btcec.PublicKey,
+	deliveryScript PkScript) *SingleFundingRequest {
 
 	return &SingleFundingRequest{
 		ChannelID:              chanID,
@@ -83,6 +99,9 @@ func NewSingleFundingRequest(chanID uint64, chanType uint8, coinType uint64,
 		FeePerKb:               fee,
 		FundingAmount:          amt,
 		CsvDelay:               delay,
+		MaxAcceptedHTLCs:       maxHtlcs,
+		MaxValueInFlight:       maxValue,
+		HtlcMinimum:            htlcMin,
 		CommitmentKey:          ck,
 		ChannelDerivationPoint: cdp,
 		DeliveryPkScript:       deliveryScript,
@@ -101,6 +120,9 @@ func (c *SingleFundingRequest) Decode(r io.Reader, pver uint32) error {
 	// FeePerKb (8)
 	// PaymentAmount (8)
 	// Delay (4)
+	// MaxAcceptedHTLCs (2)
+	// MaxValueInFlight (8)
+	// HtlcMinimum (8)
 	// Pubkey (33)
 	// Pubkey (33)
 	// DeliveryPkScript (final delivery)
@@ -111,6 +133,9 @@ func (c *SingleFundingRequest) Decode(r io.Reader, pver uint32) error {
 		&c.FeePerKb,
 		&c.FundingAmount,
 		&c.CsvDelay,
+		&c.MaxAcceptedHTLCs,
+		&c.MaxValueInFlight,
+		&c.HtlcMinimum,
 		&c.CommitmentKey,
 		&c.ChannelDerivationPoint,
 		&c.DeliveryPkScript)
@@ -133,6 +158,9 @@ func (c *SingleFundingRequest) Encode(w io.Writer, pver uint32) error {
 	// FeePerKb (8)
 	// PaymentAmount (8)
 	// Delay (4)
+	// MaxAcceptedHTLCs (2)
+	// MaxValueInFlight (8)
+	// HtlcMinimum (8)
 	// Pubkey (33)
 	// Pubkey (33)
 	// DeliveryPkScript (final delivery)
@@ -143,6 +171,9 @@ func (c *SingleFundingRequest) Encode(w io.Writer, pver uint32) error {
 		c.FeePerKb,
 		c.FundingAmount,
 		c.CsvDelay,
+		c.MaxAcceptedHTLCs,
+		c.MaxValueInFlight,
+		c.HtlcMinimum,
 		c.CommitmentKey,
 		c.ChannelDerivationPoint,
 		c.DeliveryPkScript)
@@ -165,11 +196,12 @@ func (c *SingleFundingRequest) Command() uint32 {
 // SingleFundingRequest. This is calculated by summing the max length of all
 // the fields within a SingleFundingRequest. To enforce a maximum
 // DeliveryPkScript size, the size of a P2PKH public key script is used.
-// Therefore, the final breakdown is: 8 + 1 + 8 + 8 + 8 + 4 + 33 + 33 + 25 = 158.
+// Therefore, the final breakdown is:
+// 8 + 1 + 8 + 8 + 8 + 4 + 2 + 8 + 8 + 33 + 33 + 25 = 176.
 //
 // This is part of the lnwire.Message interface.
 func (c *SingleFundingRequest) MaxPayloadLength(uint32) uint32 {
-	return 158
+	return 176
 }
 
 // Validate examines each populated field within the SingleFundingRequest for
@@ -192,6 +224,19 @@ func (c *SingleFundingRequest) Validate() error {
 			"CSV delay")
 	}
 
+	// The initiator must accept at least a single HTLC, but no more than
+	// the maximum number permitted within a commitment transaction.
+	if c.MaxAcceptedHTLCs == 0 || c.MaxAcceptedHTLCs > MaxHTLCNumber {
+		return fmt.Errorf("MaxAcceptedHTLCs must be in the range "+
+			"[1, %v]", MaxHTLCNumber)
+	}
+	if c.MaxValueInFlight < 0 {
+		return fmt.Errorf("MaxValueInFlight cannot be negative")
+	}
+	if c.HtlcMinimum < 0 {
+		return fmt.Errorf("HtlcMinimum cannot be negative")
+	}
+
 	// The channel derivation point must be non-nil, and have an odd
 	// y-coordinate.
 	if c.ChannelDerivationPoint == nil {
@@ -232,6 +277,9 @@ func (c *SingleFundingRequest) String() string {
 		fmt.Sprintf("FeePerKb:\t\t\t%s\n", c.FeePerKb.String()) +
 		fmt.Sprintf("FundingAmount:\t\t\t%s\n", c.FundingAmount.String()) +
 		fmt.Sprintf("CsvDelay\t\t\t%d\n", c.CsvDelay) +
+		fmt.Sprintf("MaxAcceptedHTLCs\t\t%d\n", c.MaxAcceptedHTLCs) +
+		fmt.Sprintf("MaxValueInFlight\t\t%s\n", c.MaxValueInFlight.String()) +
+		fmt.Sprintf("HtlcMinimum\t\t\t%s\n", c.HtlcMinimum.String()) +
 		fmt.Sprintf("ChannelDerivationPoint\t\t\t\t%x\n", serializedPubkey) +
 		fmt.Sprintf("DeliveryPkScript\t\t%x\n", c.DeliveryPkScript) +
 		fmt.Sprintf("--- End SingleFundingRequest ---\n")
@@ -5,6 +5,7 @@ import (
 	"io"
 
 	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcutil"
 )
 
 // SingleFundingResponse is the message Bob sends to Alice after she initiates
@@ -40,6 +41,19 @@ type SingleFundingResponse struct {
 	// in the pay-to-self output of both commitment transactions.
 	CsvDelay uint32
 
+	// MaxAcceptedHTLCs is the maximum number of HTLC's the responder will
+	// accept within their commitment transaction at once. This value MUST
+	// NOT exceed MaxHTLCNumber.
+	MaxAcceptedHTLCs uint16
+
+	// MaxValueInFlight is the maximum total value of outstanding HTLC's
+	// the responder will accept within their commitment transaction at
+	// once.
+	MaxValueInFlight btcutil.Amount
+
+	// HtlcMinimum is the smallest HTLC the responder will accept.
+	HtlcMinimum btcutil.Amount
+
 	// DeliveryPkScript defines the public key script that the initiator
 	// would like to use to receive their balance in the case of a
 	// cooperative close. Only the following script templates are
@@ -50,7 +64,8 @@ type SingleFundingResponse struct {
 // NewSingleFundingResponse creates, and returns a new empty
 // SingleFundingResponse.
 func NewSingleFundingResponse(chanID uint64, rk, ck, cdp *btcec.PublicKey,
-	delay uint32, deliveryScript PkScript) *SingleFundingResponse {
+	delay uint32, maxHtlcs uint16, maxValue, htlcMin btcutil.Amount,
+	deliveryScript PkScript) *SingleFundingResponse {
 
 	return &SingleFundingResponse{
 		ChannelID:              chanID,
@@ -58,6 +73,9 @@ func NewSingleFundingResponse(chanID uint64, rk, ck, cdp *btcec.PublicKey,
 		CommitmentKey:          ck,
 		RevocationKey:          rk,
 		CsvDelay:               delay,
+		MaxAcceptedHTLCs:       maxHtlcs,
+		MaxValueInFlight:       maxValue,
+		HtlcMinimum:            htlcMin,
 		DeliveryPkScript:       deliveryScript,
 	}
 }
@@ -77,6 +95,9 @@ func (c *SingleFundingResponse) Decode(r io.Reader, pver uint32) error {
 	// CommitmentKey (33)
 	// RevocationKey (33)
 	// CsvDelay (4)
+	// MaxAcceptedHTLCs (2)
+	// MaxValueInFlight (8)
+	// HtlcMinimum (8)
 	// DeliveryPkScript (final delivery)
 	err := readElements(r,
 		&c.ChannelID,
@@ -84,6 +105,9 @@ func (c *SingleFundingResponse) Decode(r io.Reader, pver uint32) error {
 		&c.CommitmentKey,
 		&c.RevocationKey,
 		&c.CsvDelay,
+		&c.MaxAcceptedHTLCs,
+		&c.MaxValueInFlight,
+		&c.HtlcMinimum,
 		&c.DeliveryPkScript)
 	if err != nil {
 		return err
@@ -103,6 +127,9 @@ func (c *SingleFundingResponse) Encode(w io.Writer, pver uint32) error {
 	// CommitmentKey (33)
 	// RevocationKey (33)
 	// CsvDelay (4)
+	// MaxAcceptedHTLCs (2)
+	// MaxValueInFlight (8)
+	// HtlcMinimum (8)
 	// DeliveryPkScript (final delivery)
 	err := writeElements(w,
 		c.ChannelID,
@@ -110,6 +137,9 @@ func (c *SingleFundingResponse) Encode(w io.Writer, pver uint32) error {
 		c.CommitmentKey,
 		c.RevocationKey,
 		c.CsvDelay,
+		c.MaxAcceptedHTLCs,
+		c.MaxValueInFlight,
+		c.HtlcMinimum,
 		c.DeliveryPkScript)
 	if err != nil {
 		return err
@@ -130,11 +160,11 @@ func (c *SingleFundingResponse) Command() uint32 {
 // SingleFundingResponse. This is calculated by summing the max length of all
 // the fields within a SingleFundingResponse. To enforce a maximum
 // DeliveryPkScript size, the size of a P2PKH public key script is used.
-// Therefore, the final breakdown is: 8 + (33 * 3) + 8 + 25
+// Therefore, the final breakdown is: 8 + (33 * 3) + 8 + 2 + 8 + 8 + 25
 //
 // This is part of the lnwire.Message interface.
 func (c *SingleFundingResponse) MaxPayloadLength(uint32) uint32 {
-	return 140
+	return 158
 }
 
 // Validate examines each populated field within the SingleFundingResponse for
@@ -153,6 +183,19 @@ func (c *SingleFundingResponse) Validate() error {
 	//		"y-coordinate")
 	//}
 
+	// The responder must accept at least a single HTLC, but no more than
+	// the maximum number permitted within a commitment transaction.
+	if c.MaxAcceptedHTLCs == 0 || c.MaxAcceptedHTLCs > MaxHTLCNumber {
+		return fmt.Errorf("MaxAcceptedHTLCs must be in the range "+
+			"[1, %v]", MaxHTLCNumber)
+	}
+	if c.MaxValueInFlight < 0 {
+		return fmt.Errorf("MaxValueInFlight cannot be negative")
+	}
+	if c.HtlcMinimum < 0 {
+		return fmt.Errorf("HtlcMinimum cannot be negative")
+	}
+
 	// The delivery pkScript must be amongst the supported script
 	// templates.
 	if !isValidPkScript(c.DeliveryPkScript) {
@@ -187,6 +230,9 @@ func (c *SingleFundingResponse) String() string {
 		fmt.Sprintf("CommitmentKey\t\t\t\t%x\n", ck) +
 		fmt.Sprintf("RevocationKey\t\t\t\t%x\n", rk) +
 		fmt.Sprintf("CsvDelay\t\t%d\n", c.CsvDelay) +
+		fmt.Sprintf("MaxAcceptedHTLCs\t%d\n", c.MaxAcceptedHTLCs) +
+		fmt.Sprintf("MaxValueInFlight\t%s\n", c.MaxValueInFlight.String()) +
+		fmt.Sprintf("HtlcMinimum\t\t%s\n", c.HtlcMinimum.String()) +
 		fmt.Sprintf("DeliveryPkScript\t\t%x\n", c.DeliveryPkScript) +
 		fmt.Sprintf("--- End SingleFundingResponse ---\n")
 }
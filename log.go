@@ -8,6 +8,7 @@ import (
 	"github.com/btcsuite/seelog"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndc"
 	"github.com/lightningnetwork/lnd/lnwallet"
 )
 
@@ -27,6 +28,8 @@ var (
 	chdbLog    = btclog.Disabled
 	hswcLog    = btclog.Disabled
 	utxnLog    = btclog.Disabled
+	brarLog    = btclog.Disabled
+	lndcLog    = btclog.Disabled
 )
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
@@ -41,6 +44,8 @@ var subsystemLoggers = map[string]btclog.Logger{
 	"FNDG": fndgLog,
 	"HSWC": hswcLog,
 	"UTXN": utxnLog,
+	"BRAR": brarLog,
+	"LNDC": lndcLog,
 }
 
 // useLogger updates the logger references for subsystemID to logger.  Invalid
@@ -83,6 +88,11 @@ func useLogger(subsystemID string, logger btclog.Logger) {
 		hswcLog = logger
 	case "UTXN":
 		utxnLog = logger
+	case "BRAR":
+		brarLog = logger
+	case "LNDC":
+		lndcLog = logger
+		lndc.UseLogger(logger)
 	}
 }
 
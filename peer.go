@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"container/list"
 	"fmt"
+	"math/rand"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -26,16 +27,40 @@ import (
 
 var (
 	numNodes int32
+
+	// globalFeatures is the set of global features advertised within the
+	// Init message sent to each peer.
+	globalFeatures = lnwire.NewFeatureVector()
+
+	// localFeatures is the set of local features advertised within the
+	// Init message sent to each peer.
+	localFeatures = lnwire.NewFeatureVector(
+		lnwire.InitialRoutingSyncOptional,
+	)
 )
 
 const (
-	// pingInterval is the interval at which ping messages are sent.
+	// pingInterval is the interval at which ping messages are sent. If the
+	// remote peer fails to respond to a ping before the next is due, then
+	// they're considered unresponsive, and we disconnect.
 	pingInterval = 30 * time.Second
 
+	// maxPingPadding is the maximum number of bytes of random padding
+	// included within each ping, and requested within each pong.
+	maxPingPadding = 256
+
+	// initTimeout is the maximum amount of time we'll wait for the remote
+	// peer's Init message once the connection has been established.
+	initTimeout = 15 * time.Second
+
 	// outgoingQueueLen is the buffer size of the channel which houses
 	// messages to be sent across the wire, requested by objects outside
 	// this struct.
 	outgoingQueueLen = 50
+
+	// closeConfTarget is the number of blocks within which we'd like a
+	// cooperative closure transaction to confirm.
+	closeConfTarget = 6
 )
 
 // outgoinMsg packages an lnwire.Message to be sent out on the wire, along with
@@ -56,7 +81,6 @@ type chanSnapshotReq struct {
 // for managing any channel state related to this peer. To do so, it has several
 // helper goroutines to handle events such as HTLC timeouts, new funding
 // workflow, and detecting an uncooperative closure of any active channels.
-// TODO(roasbeef): proper reconnection logic
 type peer struct {
 	// MUST be used atomically.
 	started    int32
@@ -87,6 +111,23 @@ type peer struct {
 	satoshisSent     uint64
 	satoshisReceived uint64
 
+	// pingTime is the round trip time of the most recent ping answered
+	// by the remote peer, in microseconds. pingLastSend is the time the
+	// most recent ping was written to the wire, in nanoseconds since the
+	// unix epoch. Both MUST be used atomically.
+	pingTime     int64
+	pingLastSend int64
+
+	// pongs is used by the readHandler to pass each pong received to the
+	// pingHandler.
+	pongs chan *lnwire.Pong
+
+	// remoteGlobalFeatures and remoteLocalFeatures are the features
+	// advertised by the remote peer within its Init message. Both are
+	// populated by Start, and aren't modified afterwards.
+	remoteGlobalFeatures lnwire.FeatureVector
+	remoteLocalFeatures  lnwire.FeatureVector
+
 	// chainNet is the Bitcoin network to which this peer is anchored to.
 	chainNet wire.BitcoinNet
 
@@ -122,6 +163,12 @@ type peer struct {
 	// channels to the source peer which handled the funding workflow.
 	newChannels chan *lnwallet.LightningChannel
 
+	// reorgedChannels is used by the fundingManager to signal that the
+	// funding transaction of an active channel has been re-org'd out of
+	// the main chain. Such channels are deactivated until the funding
+	// transaction is re-confirmed.
+	reorgedChannels chan wire.OutPoint
+
 	// localCloseChanReqs is a channel in which any local requests to
 	// close a particular channel are sent over.
 	localCloseChanReqs chan *closeLinkReq
@@ -165,6 +212,8 @@ func newPeer(conn net.Conn, server *server, btcNet wire.BitcoinNet, inbound bool
 
 		lastNMessages: make(map[lnwire.Message]struct{}),
 
+		pongs: make(chan *lnwire.Pong),
+
 		sendQueueSync: make(chan struct{}, 1),
 		sendQueue:     make(chan outgoinMsg, 1),
 		outgoingQueue: make(chan outgoinMsg, outgoingQueueLen),
@@ -175,6 +224,7 @@ func newPeer(conn net.Conn, server *server, btcNet wire.BitcoinNet, inbound bool
 		htlcManagers:     make(map[wire.OutPoint]chan lnwire.Message),
 		chanSnapshotReqs: make(chan *chanSnapshotReq),
 		newChannels:      make(chan *lnwallet.LightningChannel, 1),
+		reorgedChannels:  make(chan wire.OutPoint),
 
 		localCloseChanReqs:  make(chan *closeLinkReq),
 		remoteCloseChanReqs: make(chan *lnwire.CloseRequest),
@@ -183,11 +233,15 @@ func newPeer(conn net.Conn, server *server, btcNet wire.BitcoinNet, inbound bool
 		quit:      make(chan struct{}),
 	}
 
-	// TODO(roasbeef): re-write after lnaddr revamp, shouldn't need to use
-	// type assertions
+	// The connection has already been established, so it should be
+	// closed once the peer disconnects.
+	atomic.StoreInt32(&p.connected, 1)
+
+	// The remote address of the connection is the address we dialed,
+	// even if the connection was routed through a proxy.
 	var err error
-	tcpAddr := lndcConn.Conn.(*net.TCPConn).RemoteAddr().(*net.TCPAddr)
-	p.lightningAddr, err = lndc.NewLnAdr(tcpAddr, nodePub, activeNetParams.Params)
+	p.lightningAddr, err = lndc.NewLnAdr(lndcConn.RemoteAddr(), nodePub,
+		activeNetParams.Params)
 	if err != nil {
 		return nil, err
 	}
@@ -201,21 +255,28 @@ func newPeer(conn net.Conn, server *server, btcNet wire.BitcoinNet, inbound bool
 		p.nextPendingChannelID = 0
 	}
 
-	// Fetch and then load all the active channels we have with this
-	// remote peer from the database.
-	activeChans, err := server.chanDB.FetchOpenChannels(&p.lightningID)
-	if err != nil {
-		peerLog.Errorf("unable to fetch active chans "+
-			"for peer %v: %v", p, err)
-		return nil, err
+	return p, nil
+}
+
+// filterPendingChannels returns the subset of the passed open channels whose
+// funding outpoint doesn't belong to one of the passed pending channels.
+func filterPendingChannels(chans []*channeldb.OpenChannel,
+	pending []*channeldb.PendingChannel) []*channeldb.OpenChannel {
+
+	pendingSet := make(map[wire.OutPoint]struct{}, len(pending))
+	for _, pendingChan := range pending {
+		pendingSet[pendingChan.ChanPoint] = struct{}{}
 	}
-	peerLog.Debugf("Loaded %v active channels from database with peerID(%v)",
-		len(activeChans), p.id)
-	if err := p.loadActiveChannels(activeChans); err != nil {
-		return nil, err
+
+	filtered := make([]*channeldb.OpenChannel, 0, len(chans))
+	for _, dbChan := range chans {
+		if _, ok := pendingSet[*dbChan.ChanID]; ok {
+			continue
+		}
+		filtered = append(filtered, dbChan)
 	}
 
-	return p, nil
+	return filtered
 }
 
 // loadActiveChannels creates indexes within the peer for tracking all active
@@ -236,6 +297,15 @@ func (p *peer) loadActiveChannels(chans []*channeldb.OpenChannel) error {
 		p.activeChannels[chanPoint] = lnChan
 		peerLog.Infof("peerID(%v) loaded ChannelPoint(%v)", p.id, chanPoint)
 
+		// Hand the channel off to the breachArbiter so it can watch
+		// for any attempts by the remote peer to broadcast a revoked
+		// commitment state.
+		select {
+		case p.server.breachArbiter.newContracts <- lnChan:
+		case <-p.server.breachArbiter.quit:
+			return fmt.Errorf("breach arbiter shutting down")
+		}
+
 		// Register this new channel link with the HTLC Switch. This is
 		// necessary to properly route multi-hop payments, and forward
 		// new payments triggered by RPC clients.
@@ -243,6 +313,11 @@ func (p *peer) loadActiveChannels(chans []*channeldb.OpenChannel) error {
 		plexChan := p.server.htlcSwitch.RegisterLink(p,
 			dbChan.Snapshot(), downstreamLink)
 
+		// Advertise the routing policy of the link so other nodes
+		// are able to route payments through it.
+		p.server.announceChannelPolicy(&chanPoint, p.identityPub,
+			p.server.htlcSwitch.LinkPolicy(&chanPoint))
+
 		upstreamLink := make(chan lnwire.Message, 10)
 		p.htlcManagers[chanPoint] = upstreamLink
 		p.wg.Add(1)
@@ -253,8 +328,10 @@ func (p *peer) loadActiveChannels(chans []*channeldb.OpenChannel) error {
 }
 
 // Start starts all helper goroutines the peer needs for normal operations.
-// In the case this peer has already beeen started, then this function is a
-// noop.
+// Before doing so, Init messages are exchanged with the remote peer. If the
+// remote peer requires a feature we don't understand, then an error is
+// returned, and the caller should disconnect. In the case this peer has
+// already beeen started, then this function is a noop.
 func (p *peer) Start() error {
 	if atomic.AddInt32(&p.started, 1) != 1 {
 		return nil
@@ -262,15 +339,98 @@ func (p *peer) Start() error {
 
 	peerLog.Tracef("peer %v starting", p)
 
-	p.wg.Add(4)
+	// The Init message must be the first message sent by either side,
+	// so we exchange them before launching any helper goroutines.
+	if err := p.exchangeInitMsgs(); err != nil {
+		return err
+	}
+
+	// Fetch and then load all the active channels we have with this
+	// remote peer from the database. This is done only once the Init
+	// exchange succeeds, so channels aren't registered with the switch
+	// for a peer we're about to disconnect from.
+	activeChans, err := p.server.chanDB.FetchOpenChannels(&p.lightningID)
+	if err != nil {
+		peerLog.Errorf("unable to fetch active chans "+
+			"for peer %v: %v", p, err)
+		return err
+	}
+
+	// Channels whose funding transaction has yet to reach the required
+	// number of confirmations are also stored as open channels. These are
+	// tracked by the fundingManager, which will hand them off to the peer
+	// once they're fully open, so we filter them out here.
+	pendingChans, err := p.server.chanDB.FetchPendingChannels()
+	if err != nil {
+		peerLog.Errorf("unable to fetch pending chans: %v", err)
+		return err
+	}
+	activeChans = filterPendingChannels(activeChans, pendingChans)
+
+	peerLog.Debugf("Loaded %v active channels from database with peerID(%v)",
+		len(activeChans), p.id)
+	if err := p.loadActiveChannels(activeChans); err != nil {
+		// Any channels loaded before the failure have already been
+		// registered with the switch, so their links are torn down.
+		p.server.htlcSwitch.UnregisterLink(p.identityPub, nil)
+		return err
+	}
+
+	p.wg.Add(5)
 	go p.readHandler()
 	go p.queueHandler()
 	go p.writeHandler()
+	go p.pingHandler()
 	go p.channelManager()
 
 	return nil
 }
 
+// exchangeInitMsgs sends our Init message to the remote peer, then waits for
+// theirs. Following the "it's OK to be odd" rule, an error is returned if the
+// remote peer requires any feature unknown to us, while unknown optional
+// features are ignored.
+func (p *peer) exchangeInitMsgs() error {
+	initMsg := lnwire.NewInitMessage(globalFeatures, localFeatures)
+	if err := p.writeMessage(initMsg); err != nil {
+		return fmt.Errorf("unable to send init message: %v", err)
+	}
+
+	// Bound the time we'll wait for the remote peer's Init message, so
+	// an unresponsive peer is unable to stall us indefinitely.
+	if err := p.conn.SetReadDeadline(time.Now().Add(initTimeout)); err != nil {
+		return err
+	}
+	msg, _, err := p.readNextMessage()
+	if err != nil {
+		return fmt.Errorf("unable to read init message: %v", err)
+	}
+	if err := p.conn.SetReadDeadline(time.Time{}); err != nil {
+		return err
+	}
+
+	remoteInit, ok := msg.(*lnwire.Init)
+	if !ok {
+		return fmt.Errorf("expected init message, instead received "+
+			"%T", msg)
+	}
+
+	unknownGlobal := remoteInit.GlobalFeatures.UnknownRequiredFeatures()
+	unknownLocal := remoteInit.LocalFeatures.UnknownRequiredFeatures()
+	if len(unknownGlobal) != 0 || len(unknownLocal) != 0 {
+		return fmt.Errorf("peer requires unknown features: global=%v, "+
+			"local=%v", unknownGlobal, unknownLocal)
+	}
+
+	p.remoteGlobalFeatures = remoteInit.GlobalFeatures
+	p.remoteLocalFeatures = remoteInit.LocalFeatures
+
+	peerLog.Debugf("Peer %v advertised global features %v, local "+
+		"features %v", p, p.remoteGlobalFeatures, p.remoteLocalFeatures)
+
+	return nil
+}
+
 // Stop signals the peer for a graceful shutdown. All active goroutines will be
 // signaled to wrap up any final actions. This function will also block until
 // all goroutines have exited.
@@ -369,6 +529,14 @@ out:
 			p.server.fundingMgr.processFundingSignComplete(msg, p)
 		case *lnwire.SingleFundingOpenProof:
 			p.server.fundingMgr.processFundingOpenProof(msg, p)
+		case *lnwire.DualFundingRequest:
+			p.server.fundingMgr.processDualFundingRequest(msg, p)
+		case *lnwire.DualFundingResponse:
+			p.server.fundingMgr.processDualFundingResponse(msg, p)
+		case *lnwire.DualFundingComplete:
+			p.server.fundingMgr.processDualFundingComplete(msg, p)
+		case *lnwire.DualFundingSignComplete:
+			p.server.fundingMgr.processDualFundingSignComplete(msg, p)
 		case *lnwire.CloseRequest:
 			p.remoteCloseChanReqs <- msg
 		// TODO(roasbeef): interface for htlc update msgs
@@ -379,12 +547,33 @@ out:
 		case *lnwire.HTLCSettleRequest:
 			isChanUpate = true
 			targetChan = msg.ChannelPoint
+		case *lnwire.CancelHTLC:
+			isChanUpate = true
+			targetChan = msg.ChannelPoint
 		case *lnwire.CommitRevocation:
 			isChanUpate = true
 			targetChan = msg.ChannelPoint
 		case *lnwire.CommitSignature:
 			isChanUpate = true
 			targetChan = msg.ChannelPoint
+		case *lnwire.ChannelReestablish:
+			isChanUpate = true
+			targetChan = msg.ChannelPoint
+		case *lnwire.ChannelPolicyUpdate:
+			p.server.processPolicyUpdate(msg, p)
+		case *lnwire.Ping:
+			// Respond with the number of bytes requested, unless
+			// the ping is purely padding.
+			if msg.NumPongBytes <= lnwire.MaxPongBytes {
+				pongBytes := make([]byte, msg.NumPongBytes)
+				p.queueMsg(lnwire.NewPong(pongBytes), nil)
+			}
+		case *lnwire.Pong:
+			select {
+			case p.pongs <- msg:
+			case <-p.quit:
+				break out
+			}
 		case *lnwire.NeighborAckMessage,
 			*lnwire.NeighborHelloMessage,
 			*lnwire.NeighborRstMessage,
@@ -457,16 +646,16 @@ func (p *peer) writeMessage(msg lnwire.Message) error {
 //
 // NOTE: This method MUST be run as a goroutine.
 func (p *peer) writeHandler() {
-	// pingTicker is used to periodically send pings to the remote peer.
-	pingTicker := time.NewTicker(pingInterval)
-	defer pingTicker.Stop()
-
 out:
 	for {
 		select {
 		case outMsg := <-p.sendQueue:
-			switch m := outMsg.msg.(type) {
-			// TODO(roasbeef): handle special write cases
+			switch outMsg.msg.(type) {
+			// Record the time each ping is written to the wire, so
+			// the round trip time excludes any time spent queued.
+			case *lnwire.Ping:
+				now := time.Now().UnixNano()
+				atomic.StoreInt64(&p.pingLastSend, now)
 			}
 
 			if err := p.writeMessage(outMsg.msg); err != nil {
@@ -477,8 +666,6 @@ out:
 
 			// Synchronize with the writeHandler.
 			p.sendQueueSync <- struct{}{}
-		case <-pingTicker.C:
-			// TODO(roasbeef): move ping to time.AfterFunc
 		case <-p.quit:
 			break out
 		}
@@ -505,6 +692,68 @@ fin:
 	peerLog.Tracef("writeHandler for peer %v done", p)
 }
 
+// pingHandler periodically sends pings to the remote peer, measuring the
+// round trip time of each. If the remote peer fails to respond to a ping
+// before the next is due, then the connection is considered dead, and we
+// disconnect. This allows us to detect half-open connections, which would
+// otherwise leave us waiting on the remote peer indefinitely.
+//
+// NOTE: This method MUST be run as a goroutine.
+func (p *peer) pingHandler() {
+	pingTicker := time.NewTicker(pingInterval)
+	defer pingTicker.Stop()
+
+	var (
+		// awaitingPong is true if the most recent ping hasn't yet
+		// been answered.
+		awaitingPong bool
+
+		// numPongBytes is the size of the pong requested by the
+		// most recent ping.
+		numPongBytes uint16
+	)
+
+out:
+	for {
+		select {
+		case <-pingTicker.C:
+			if awaitingPong {
+				peerLog.Warnf("Peer %v failed to respond to "+
+					"ping within %v, disconnecting", p,
+					pingInterval)
+				p.Disconnect()
+				break out
+			}
+
+			numPongBytes = uint16(rand.Intn(maxPingPadding))
+			padding := make([]byte, rand.Intn(maxPingPadding))
+			awaitingPong = true
+
+			p.queueMsg(lnwire.NewPing(numPongBytes, padding), nil)
+
+		case pong := <-p.pongs:
+			// Ignore any unsolicited pongs, or pongs which don't
+			// match the size we requested.
+			if !awaitingPong || len(pong.PongBytes) != int(numPongBytes) {
+				peerLog.Warnf("Received unexpected pong from "+
+					"peer %v", p)
+				continue
+			}
+			awaitingPong = false
+
+			lastSend := atomic.LoadInt64(&p.pingLastSend)
+			rtt := time.Now().UnixNano() - lastSend
+			atomic.StoreInt64(&p.pingTime, rtt/int64(time.Microsecond))
+
+		case <-p.quit:
+			break out
+		}
+	}
+
+	p.wg.Done()
+	peerLog.Tracef("pingHandler for peer %v done", p)
+}
+
 // queueHandler is responsible for accepting messages from outside sub-systems
 // to be eventually sent out on the wire by the writeHandler.
 //
@@ -556,7 +805,12 @@ func (p *peer) queueMsg(msg lnwire.Message, doneChan chan struct{}) {
 // active channels maintained with the remote peer.
 func (p *peer) ChannelSnapshots() []*channeldb.ChannelSnapshot {
 	resp := make(chan []*channeldb.ChannelSnapshot, 1)
-	p.chanSnapshotReqs <- &chanSnapshotReq{resp}
+	select {
+	case p.chanSnapshotReqs <- &chanSnapshotReq{resp}:
+	case <-p.quit:
+		return nil
+	}
+
 	return <-resp
 }
 
@@ -586,17 +840,51 @@ out:
 
 		case newChan := <-p.newChannels:
 			chanPoint := *newChan.ChannelPoint()
+
+			// If the channel is already active, then it was loaded
+			// from disk when this peer connected, just after its
+			// funding transaction was confirmed. In this case there
+			// is nothing left to do.
+			if _, ok := p.activeChannels[chanPoint]; ok {
+				peerLog.Debugf("ChannelPoint(%v) already active "+
+					"with peerId(%v)", chanPoint, p.id)
+				continue
+			}
+
 			p.activeChannels[chanPoint] = newChan
 
 			peerLog.Infof("New channel active ChannelPoint(%v) "+
 				"with peerId(%v)", chanPoint, p.id)
 
+			// If we dialed this peer, then record its address so
+			// the server can automatically reconnect if the
+			// connection is lost. The remote address of an inbound
+			// peer is ephemeral, so in that case we rely on the
+			// remote node to re-establish the connection.
+			if !p.inbound {
+				err := p.server.addPersistentPeer(p.lightningAddr)
+				if err != nil {
+					peerLog.Errorf("unable to persist address "+
+						"of peer %v: %v", p, err)
+				}
+			}
+
+			// Notify the breachArbiter of the new channel so it
+			// can watch for any contract breaches.
+			select {
+			case p.server.breachArbiter.newContracts <- newChan:
+			case <-p.server.breachArbiter.quit:
+				break out
+			}
+
 			// Now that the channel is open, notify the Htlc
 			// Switch of a new active link.
 			chanSnapShot := newChan.StateSnapshot()
 			downstreamLink := make(chan *htlcPacket, 10)
 			plexChan := p.server.htlcSwitch.RegisterLink(p,
 				chanSnapShot, downstreamLink)
+			p.server.announceChannelPolicy(&chanPoint, p.identityPub,
+				p.server.htlcSwitch.LinkPolicy(&chanPoint))
 
 			// With the channel registered to the HtlcSwitch spawn
 			// a goroutine to handle commitment updates for this
@@ -608,13 +896,38 @@ out:
 
 			// Close the active channel barrier signalling the
 			// readHandler that commitment related modifications to
-			// this channel can now proceed.
+			// this channel can now proceed. Channels restored by the
+			// fundingManager after a restart won't have a barrier.
 			p.barrierMtx.Lock()
-			peerLog.Tracef("Closing chan barrier for ChannelPoint(%v)", chanPoint)
-			close(p.newChanBarriers[chanPoint])
-			delete(p.newChanBarriers, chanPoint)
+			if barrier, ok := p.newChanBarriers[chanPoint]; ok {
+				peerLog.Tracef("Closing chan barrier for "+
+					"ChannelPoint(%v)", chanPoint)
+				close(barrier)
+				delete(p.newChanBarriers, chanPoint)
+			}
 			p.barrierMtx.Unlock()
 
+		case chanPoint := <-p.reorgedChannels:
+			channel, ok := p.activeChannels[chanPoint]
+			if !ok {
+				continue
+			}
+
+			peerLog.Warnf("Funding tx of ChannelPoint(%v) with "+
+				"peerId(%v) re-org'd out, deactivating channel",
+				chanPoint, p.id)
+
+			// The on-disk state of the channel is left intact, as
+			// the channel will be re-activated once its funding
+			// transaction is re-confirmed.
+			unlinkChannel(p, channel)
+			select {
+			case p.server.breachArbiter.settledContracts <- &chanPoint:
+			case <-p.server.breachArbiter.quit:
+			case <-p.quit:
+				break out
+			}
+
 		case req := <-p.localCloseChanReqs:
 			p.handleLocalClose(req)
 
@@ -671,8 +984,19 @@ func (p *peer) executeCooperativeClose(channel *lnwallet.LightningChannel) (*wir
 	// generates a signature for the closing tx, as well as a txid of the
 	// closing tx itself, allowing us to watch the network to determine
 	// when the remote node broadcasts the fully signed closing
-	// transaction.
-	sig, txid, err := channel.InitCooperativeClose()
+	// transaction. The closing transaction pays the current estimated fee
+	// rate, or the minimum fee rate negotiated for the channel, whichever
+	// is greater.
+	feePerByte, err := p.server.feeEstimator.EstimateFeePerByte(closeConfTarget)
+	if err != nil {
+		return nil, err
+	}
+	feePerKb := feePerByte * 1000
+	if feePerKb < channel.MinFeePerKb() {
+		feePerKb = channel.MinFeePerKb()
+	}
+
+	sig, txid, err := channel.InitCooperativeClose(feePerKb)
 	if err != nil {
 		return nil, err
 	}
@@ -689,7 +1013,7 @@ func (p *peer) executeCooperativeClose(channel *lnwallet.LightningChannel) (*wir
 	if err != nil {
 		return nil, err
 	}
-	closeReq := lnwire.NewCloseRequest(chanPoint, closeSig)
+	closeReq := lnwire.NewCloseRequest(chanPoint, closeSig, feePerKb)
 	p.queueMsg(closeReq, nil)
 
 	return txid, nil
@@ -705,15 +1029,36 @@ func (p *peer) handleLocalClose(req *closeLinkReq) {
 
 	channel := p.activeChannels[*req.chanPoint]
 
-	if req.forceClose {
-		closingTxid, err = p.executeForceClose(channel)
-		peerLog.Infof("Force closing ChannelPoint(%v) with txid: %v",
-			req.chanPoint, closingTxid)
-	} else {
+	switch req.closeType {
+	// A type of CloseRegular indicates that the user has opted to close
+	// out this channel on-chain, so we execute the cooperative channel
+	// closre workflow.
+	case CloseRegular:
 		closingTxid, err = p.executeCooperativeClose(channel)
 		peerLog.Infof("Attempting cooperative close of "+
 			"ChannelPoint(%v) with txid: %v", req.chanPoint,
 			closingTxid)
+
+	// A type of CloseForce indicates that the user has opted for
+	// unilaterally close the channel on-chain.
+	case CloseForce:
+		closingTxid, err = p.executeForceClose(channel)
+		peerLog.Infof("Force closing ChannelPoint(%v) with txid: %v",
+			req.chanPoint, closingTxid)
+
+	// A type of CloseBreach indicates that the counterparty has breached
+	// the channel therefore we need to clean up our local state. The
+	// breachArbiter is responsible for resolving the channel on-chain, so
+	// the channel's on-disk state is left untouched.
+	case CloseBreach:
+		peerLog.Infof("ChannelPoint(%v) has been breached, wiping "+
+			"channel", req.chanPoint)
+		unlinkChannel(p, channel)
+
+		// Signal the requester that the link has been torn down by
+		// sending a nil error.
+		req.err <- nil
+		return
 	}
 	if err != nil {
 		req.err <- err
@@ -791,7 +1136,7 @@ func (p *peer) handleRemoteClose(req *lnwire.CloseRequest) {
 	// signature.
 	sig := req.RequesterCloseSig
 	closeSig := append(sig.Serialize(), byte(txscript.SigHashAll))
-	closeTx, err := channel.CompleteCooperativeClose(closeSig)
+	closeTx, err := channel.CompleteCooperativeClose(closeSig, req.Fee)
 	if err != nil {
 		peerLog.Errorf("unable to complete cooperative "+
 			"close for ChannelPoint(%v): %v",
@@ -820,9 +1165,11 @@ func (p *peer) handleRemoteClose(req *lnwire.CloseRequest) {
 	}
 }
 
-// wipeChannel removes the passed channel from all indexes associated with the
-// peer, and deletes the channel from the database.
-func wipeChannel(p *peer, channel *lnwallet.LightningChannel) error {
+// unlinkChannel removes the passed channel from all indexes associated with
+// the peer, unregisters its link from the htlcSwitch, and signals the
+// channel's htlcManager to exit. The channel's on-disk state is left intact.
+// The return value indicates whether the channel had an active htlcManager.
+func unlinkChannel(p *peer, channel *lnwallet.LightningChannel) bool {
 	chanID := channel.ChannelPoint()
 
 	delete(p.activeChannels, *chanID)
@@ -832,12 +1179,31 @@ func wipeChannel(p *peer, channel *lnwallet.LightningChannel) error {
 	p.server.htlcSwitch.UnregisterLink(p.identityPub, chanID)
 	htlcWireLink, ok := p.htlcManagers[*chanID]
 	if !ok {
-		return nil
+		return false
 	}
 
 	delete(p.htlcManagers, *chanID)
 	close(htlcWireLink)
 
+	return true
+}
+
+// wipeChannel removes the passed channel from all indexes associated with the
+// peer, and deletes the channel from the database.
+func wipeChannel(p *peer, channel *lnwallet.LightningChannel) error {
+	chanID := channel.ChannelPoint()
+
+	if !unlinkChannel(p, channel) {
+		return nil
+	}
+
+	// The channel has been fully resolved, so the breachArbiter no longer
+	// needs to watch over it.
+	select {
+	case p.server.breachArbiter.settledContracts <- chanID:
+	case <-p.server.breachArbiter.quit:
+	}
+
 	if err := channel.DeleteState(); err != nil {
 		peerLog.Errorf("Unable to delete ChannelPoint(%v) "+
 			"from db %v", chanID, err)
@@ -856,7 +1222,15 @@ type pendingPayment struct {
 	htlc  *lnwire.HTLCAddRequest
 	index uint32
 
-	err chan error
+	preimage chan [32]byte
+	err      chan error
+}
+
+// pendingCancel is an incoming HTLC which we're unable to settle or forward,
+// along with the failure code it'll be cancelled back to the upstream peer
+// with.
+type pendingCancel struct {
+	reason lnwire.FailCode
 }
 
 // commitmentState is the volatile+persistent state of an active channel's
@@ -867,9 +1241,34 @@ type commitmentState struct {
 	// many of the pending HTLC's we've received from the upstream peer.
 	htlcsToSettle map[uint32]*channeldb.Invoice
 
+	// htlcsToCancel is a set of HTLC's identified by their log index in
+	// the remote log which we're unable to settle or forward. Once locked
+	// in within both commitment chains, each of these HTLC's will be
+	// cancelled back to the upstream peer.
+	htlcsToCancel map[uint32]*pendingCancel
+
+	// htlcsToHold is a set of HTLC's identified by their log index in the
+	// remote log which each pay part of an invoice. Once locked in within
+	// both commitment chains, each of these HTLC's is handed to the
+	// invoiceRegistry, which settles all parts at once when their sum
+	// covers the invoice.
+	htlcsToHold map[uint32]wire.ShaHash
+
+	// heldParts is the set of HTLC's from htlcsToHold, identified by their
+	// log index in the remote log, which have been handed to the
+	// invoiceRegistry. Once the registry settles or cancels one of these
+	// HTLC's, it's removed from the set.
+	heldParts map[uint32]struct{}
+
 	// TODO(roasbeef): use once trickle+batch logic is in
 	pendingBatch []*pendingPayment
 
+	// pendingUpdates is the set of update messages sent to the remote
+	// peer since our last commitment signature. The updates are stored
+	// along with our next signature, allowing both to be retransmitted if
+	// they're lost along with the connection.
+	pendingUpdates []lnwire.Message
+
 	// clearedHTCLs is a map of outgoing HTLC's we've committed to in our
 	// chain which have not yet been settled by the upstream peer.
 	clearedHTCLs map[uint32]*pendingPayment
@@ -894,6 +1293,15 @@ type commitmentState struct {
 	// within HTLC add messages.
 	sphinx *sphinx.Router
 
+	// bestHeight is the height of the current best block within the main
+	// chain. It's used to determine if the expiry of an incoming HTLC is
+	// acceptable.
+	bestHeight uint32
+
+	// forceClosing is true if we've requested a unilateral closure of the
+	// channel due to an expired outgoing HTLC.
+	forceClosing bool
+
 	// pendingCircuits tracks the remote log index of the incoming HTLC's,
 	// mapped to the processed Sphinx packet contained within the HTLC.
 	// This map is used as a staging area between when an HTLC is added to
@@ -902,10 +1310,60 @@ type commitmentState struct {
 	// along with the HTLC to forward the packet to the next hop.
 	pendingCircuits map[uint32]*sphinx.ProcessedPacket
 
+	// circuitTotals tracks the remote log index of incoming HTLC's within
+	// pendingCircuits which are a part of a multi-part payment, mapped to
+	// the total amount of the payment. The total is passed on to the next
+	// hop along with the HTLC.
+	circuitTotals map[uint32]lnwire.CreditsAmount
+
+	// failureObfuscators tracks the remote log index of the incoming
+	// HTLC's, mapped to the obfuscator used to encrypt any failure sent
+	// back to the sender of the HTLC. An HTLC's obfuscator is discarded
+	// once the HTLC has been settled or cancelled.
+	failureObfuscators map[uint32]*lnwire.FailureObfuscator
+
+	// cancelReasons tracks the local log index of our outgoing HTLC's
+	// which have been cancelled by the remote peer, mapped to the
+	// encrypted failure sent along with the cancel. Once the cancel has
+	// been locked in, the failure is propagated back towards the sender
+	// of the HTLC.
+	cancelReasons map[uint32]lnwire.OpaqueReason
+
 	channel   *lnwallet.LightningChannel
 	chanPoint *wire.OutPoint
 }
 
+// encryptFailure encrypts a new failure with the passed code for the incoming
+// HTLC at the passed index within the remote log. This should be used when
+// this node is the one cancelling the HTLC. The HTLC's obfuscator is
+// discarded, as the HTLC is being cancelled.
+func (s *commitmentState) encryptFailure(index uint32,
+	code lnwire.FailCode) lnwire.OpaqueReason {
+
+	obfuscator, ok := s.failureObfuscators[index]
+	if !ok {
+		return nil
+	}
+	delete(s.failureObfuscators, index)
+
+	return obfuscator.EncryptFailure(code)
+}
+
+// obfuscateFailure adds our layer of encryption to a failure received from
+// downstream, before it's sent back upstream along with the cancellation of
+// the incoming HTLC at the passed index within the remote log.
+func (s *commitmentState) obfuscateFailure(index uint32,
+	reason lnwire.OpaqueReason) lnwire.OpaqueReason {
+
+	obfuscator, ok := s.failureObfuscators[index]
+	if !ok {
+		return reason
+	}
+	delete(s.failureObfuscators, index)
+
+	return obfuscator.ObfuscateFailure(reason)
+}
+
 // htlcManager is the primary goroutine which drives a channel's commitment
 // update state-machine in response to messages received via several channels.
 // The htlcManager reads messages from the upstream (remote) peer, and also
@@ -926,34 +1384,68 @@ func (p *peer) htlcManager(channel *lnwallet.LightningChannel,
 		chanStats.RemoteBalance, chanStats.NumUpdates)
 
 	// A new session for this active channel has just started, therefore we
-	// need to send our initial revocation window to the remote peer.
-	for i := 0; i < lnwallet.InitialRevocationWindow; i++ {
-		rev, err := channel.ExtendRevocationWindow()
-		if err != nil {
-			peerLog.Errorf("unable to expand revocation window: %v", err)
-			continue
-		}
-		p.queueMsg(rev, nil)
-	}
+	// first send a ChannelReestablish message to the remote peer allowing
+	// both sides to recover from a state transition interrupted by the
+	// prior disconnection. Our initial revocation window is sent only once
+	// we've received the remote peer's ChannelReestablish message, so any
+	// retransmitted revocation is always processed before the remote peer
+	// is able to extend our commitment chain.
+	p.queueMsg(channel.ChanSyncMsg(), nil)
 
 	state := &commitmentState{
-		channel:         channel,
-		chanPoint:       channel.ChannelPoint(),
-		clearedHTCLs:    make(map[uint32]*pendingPayment),
-		htlcsToSettle:   make(map[uint32]*channeldb.Invoice),
-		pendingCircuits: make(map[uint32]*sphinx.ProcessedPacket),
-		sphinx:          p.server.sphinx,
-		switchChan:      htlcPlex,
+		channel:            channel,
+		chanPoint:          channel.ChannelPoint(),
+		clearedHTCLs:       make(map[uint32]*pendingPayment),
+		htlcsToSettle:      make(map[uint32]*channeldb.Invoice),
+		htlcsToCancel:      make(map[uint32]*pendingCancel),
+		htlcsToHold:        make(map[uint32]wire.ShaHash),
+		heldParts:          make(map[uint32]struct{}),
+		pendingCircuits:    make(map[uint32]*sphinx.ProcessedPacket),
+		circuitTotals:      make(map[uint32]lnwire.CreditsAmount),
+		failureObfuscators: make(map[uint32]*lnwire.FailureObfuscator),
+		cancelReasons:      make(map[uint32]lnwire.OpaqueReason),
+		sphinx:             p.server.sphinx,
+		switchChan:         htlcPlex,
 	}
 
 	// TODO(roasbeef): check to see if able to settle any currently pending
 	// HTLC's
 	//   * also need signals when new invoices are added by the invoiceRegistry
 
+	// Register for a notification of each newly connected block, on each
+	// new block we'll check if any of the HTLC's within the channel are
+	// close to expiring.
+	blockEpochs, err := p.server.chainNotifier.RegisterBlockEpochNtfn()
+	if err != nil {
+		peerLog.Errorf("unable to register for block epochs: %v", err)
+		p.wg.Done()
+		return
+	}
+	bestHeight, err := p.server.bio.GetCurrentHeight()
+	if err != nil {
+		peerLog.Errorf("unable to fetch current height: %v", err)
+		p.wg.Done()
+		return
+	}
+	state.bestHeight = uint32(bestHeight)
+
 	batchTimer := time.Tick(10 * time.Millisecond)
 out:
 	for {
 		select {
+		case epoch, ok := <-blockEpochs.Epochs:
+			// If the notifier is shutting down, then the epoch
+			// channel will be closed.
+			if !ok {
+				break out
+			}
+
+			if err := p.handleBlockEpoch(state, uint32(epoch.Height)); err != nil {
+				peerLog.Errorf("unable to handle new block for "+
+					"ChannelPoint(%v): %v", state.chanPoint, err)
+				p.Disconnect()
+				break out
+			}
 		case <-channel.UnilateralCloseSignal:
 			// TODO(roasbeef): eliminate false positive via local close
 			peerLog.Warnf("Remote peer has closed ChannelPoint(%v) on-chain",
@@ -1031,6 +1523,14 @@ out:
 	peerLog.Tracef("htlcManager for peer %v done", p)
 }
 
+// queueUpdate queues an update to the state of the passed channel to be sent to
+// the remote peer. The update is additionally recorded in order to be stored
+// along with our next commitment signature.
+func (p *peer) queueUpdate(state *commitmentState, update lnwire.Message) {
+	state.pendingUpdates = append(state.pendingUpdates, update)
+	p.queueMsg(update, nil)
+}
+
 // handleDownStreamPkt processes an HTLC packet sent from the downstream HTLC
 // Switch. Possible messages sent by the switch include requests to forward new
 // HTLC's, timeout previously cleared HTLC's, and finally to settle currently
@@ -1044,19 +1544,24 @@ func (p *peer) handleDownStreamPkt(state *commitmentState, pkt *htlcPacket) {
 		// to our local log, then update the commitment
 		// chains.
 		htlc.ChannelPoint = state.chanPoint
-		index := state.channel.AddHTLC(htlc)
-		p.queueMsg(htlc, nil)
+		index, err := state.channel.AddHTLC(htlc)
+		if err != nil {
+			p.failDownStreamAdd(state, pkt, htlc, err)
+			return
+		}
+		p.queueUpdate(state, htlc)
 
 		state.pendingBatch = append(state.pendingBatch, &pendingPayment{
-			htlc:  htlc,
-			index: index,
-			err:   pkt.err,
+			htlc:     htlc,
+			index:    index,
+			preimage: pkt.preimage,
+			err:      pkt.err,
 		})
 
 	case *lnwire.HTLCSettleRequest:
 		pre := htlc.RedemptionProofs[0]
-		logIndex, err := state.channel.SettleHTLC(pre)
-		if err != nil {
+		logIndex := pkt.index
+		if err := state.channel.SettleHTLC(pre, logIndex); err != nil {
 			// TODO(roasbeef): broadcast on-chain
 			peerLog.Errorf("settle for incoming HTLC rejected: %v", err)
 			p.Disconnect()
@@ -1065,14 +1570,42 @@ func (p *peer) handleDownStreamPkt(state *commitmentState, pkt *htlcPacket) {
 
 		htlc.ChannelPoint = state.chanPoint
 		htlc.HTLCKey = lnwire.HTLCKey(logIndex)
+		delete(state.failureObfuscators, logIndex)
+		delete(state.heldParts, logIndex)
 
-		p.queueMsg(htlc, nil)
+		p.queueUpdate(state, htlc)
+		isSettle = true
+
+	case *lnwire.CancelHTLC:
+		// An HTLC we forwarded has been cancelled downstream, or
+		// couldn't be forwarded at all. So we cancel the matching
+		// incoming HTLC back to the upstream peer.
+		logIndex := pkt.index
+		if err := state.channel.TimeoutHTLC(logIndex); err != nil {
+			peerLog.Errorf("unable to cancel incoming HTLC: %v", err)
+			return
+		}
+		delete(state.heldParts, logIndex)
+
+		// If the HTLC was cancelled downstream, then we add our own
+		// layer of encryption to the failure before sending it
+		// upstream. Otherwise, the HTLC was cancelled by this node, so
+		// we encrypt a new failure with the code given to us.
+		if len(htlc.Reason) != 0 {
+			htlc.Reason = state.obfuscateFailure(logIndex, htlc.Reason)
+		} else {
+			htlc.Reason = state.encryptFailure(logIndex, pkt.failCode)
+		}
+
+		htlc.ChannelPoint = state.chanPoint
+		htlc.HTLCKey = lnwire.HTLCKey(logIndex)
+
+		p.queueUpdate(state, htlc)
 		isSettle = true
 	}
 
 	// If this newly added update exceeds the max batch size for adds, or
-	// this is a settle request, then initiate an update.
-	// TODO(roasbeef): enforce max HTLC's in flight limit
+	// this is a settle or cancel request, then initiate an update.
 	if len(state.pendingBatch) >= 10 || isSettle {
 		if sent, err := p.updateCommitTx(state); err != nil {
 			peerLog.Errorf("unable to update "+
@@ -1087,11 +1620,191 @@ func (p *peer) handleDownStreamPkt(state *commitmentState, pkt *htlcPacket) {
 	}
 }
 
+// failDownStreamAdd fails an HTLC sent to us by the htlc switch which we were
+// unable to add to the channel, as it would violate the limits the remote peer
+// places on the HTLC's they'll accept. The bandwidth reserved for the HTLC by
+// the switch is returned to the link. If we initiated the payment, then the
+// error is returned to the sender directly. Otherwise, the HTLC was forwarded
+// to us, so the switch cancels it back along the circuit it created.
+func (p *peer) failDownStreamAdd(state *commitmentState, pkt *htlcPacket,
+	htlc *lnwire.HTLCAddRequest, err error) {
+
+	rHash := htlc.RedemptionHashes[0]
+	peerLog.Errorf("unable to add HTLC %x to ChannelPoint(%v): %v",
+		rHash[:], state.chanPoint, err)
+
+	amt := btcutil.Amount(htlc.Amount)
+	p.server.htlcSwitch.UpdateLink(state.chanPoint, amt)
+
+	pkt.err <- err
+
+	if pkt.preimage != nil {
+		return
+	}
+
+	cancelPkt := &htlcPacket{
+		msg:      &lnwire.CancelHTLC{},
+		srcLink:  *state.chanPoint,
+		amt:      amt,
+		payHash:  rHash,
+		failCode: lnwire.FailInsufficientCapacity,
+		err:      make(chan error, 1),
+	}
+	go func() {
+		state.switchChan <- cancelPkt
+	}()
+}
+
+// handleBlockEpoch examines the state of all active HTLC's within the channel
+// upon the arrival of a new block. Incoming HTLC's which are close to expiring
+// are cancelled back to the remote peer, as we may be unable to settle them
+// in time. If any of our outgoing HTLC's have expired without being settled
+// or cancelled by the remote peer, then the channel is force closed in order
+// to reclaim the funds on-chain.
+func (p *peer) handleBlockEpoch(state *commitmentState, height uint32) error {
+	state.bestHeight = height
+
+	// Once a force close has been requested, there's nothing left to be
+	// done with the channel's HTLC's off-chain.
+	if state.forceClosing {
+		return nil
+	}
+
+	// First, cancel back any incoming HTLC's which expire within the next
+	// few blocks.
+	// TODO(roasbeef): if the HTLC was forwarded, the preimage may still be
+	// revealed on-chain by the downstream peer.
+	numCancelled := 0
+	expiringHTLCs := state.channel.ExpiringHTLCs(height+htlcCancelDelta, true)
+	for _, htlc := range expiringHTLCs {
+		logIndex := htlc.Index
+
+		// If the HTLC is a payment part held by the invoiceRegistry,
+		// then it must stop counting the part towards the invoice
+		// before we cancel it. If the registry has already released
+		// the part, then its settle or cancel is on the way to us, so
+		// we leave the HTLC to be removed by it.
+		if _, ok := state.heldParts[logIndex]; ok {
+			removed := p.server.invoices.CancelPaymentPart(
+				wire.ShaHash(htlc.RHash), *state.chanPoint,
+				logIndex)
+			if !removed {
+				continue
+			}
+			delete(state.heldParts, logIndex)
+		}
+
+		if err := state.channel.TimeoutHTLC(logIndex); err != nil {
+			return err
+		}
+
+		peerLog.Warnf("Cancelling HTLC %x within ChannelPoint(%v), "+
+			"expiry=%v, height=%v", htlc.RHash[:], state.chanPoint,
+			htlc.Timeout, height)
+
+		delete(state.htlcsToSettle, logIndex)
+		delete(state.htlcsToCancel, logIndex)
+		delete(state.htlcsToHold, logIndex)
+		delete(state.pendingCircuits, logIndex)
+		delete(state.circuitTotals, logIndex)
+
+		p.queueUpdate(state, &lnwire.CancelHTLC{
+			ChannelPoint: state.chanPoint,
+			HTLCKey:      lnwire.HTLCKey(logIndex),
+			Reason: state.encryptFailure(logIndex,
+				lnwire.FailHTLCTimeout),
+		})
+		numCancelled++
+	}
+
+	// If any of our outgoing HTLC's have expired, then the remote peer is
+	// unable or unwilling to cancel them. In order to reclaim the funds,
+	// we're forced to close the channel on-chain, at which point the
+	// utxoNursery sweeps the expired HTLC's once their timeouts mature.
+	expiredHTLCs := state.channel.ExpiringHTLCs(height, false)
+	if len(expiredHTLCs) != 0 {
+		peerLog.Warnf("ChannelPoint(%v) has %v expired outgoing "+
+			"HTLC's at height %v, force closing", state.chanPoint,
+			len(expiredHTLCs), height)
+
+		state.forceClosing = true
+
+		chanPoint := state.chanPoint
+		go func() {
+			updates, errChan := p.server.htlcSwitch.CloseLink(chanPoint,
+				CloseForce)
+			for {
+				select {
+				case update := <-updates:
+					_, ok := update.Update.(*lnrpc.CloseStatusUpdate_ChanClose)
+					if ok {
+						return
+					}
+				case err := <-errChan:
+					if err != nil {
+						peerLog.Errorf("unable to force close "+
+							"ChannelPoint(%v): %v",
+							chanPoint, err)
+					}
+					return
+				case <-p.quit:
+					return
+				}
+			}
+		}()
+
+		return nil
+	}
+
+	if numCancelled == 0 {
+		return nil
+	}
+
+	sent, err := p.updateCommitTx(state)
+	if err != nil {
+		return err
+	}
+	if sent {
+		state.numUnAcked += 1
+	}
+
+	return nil
+}
+
 // handleUpstreamMsg processes wire messages related to commitment state
 // updates from the upstream peer. The upstream peer is the peer whom we have a
 // direct channel with, updating our respective commitment chains.
 func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 	switch htlcPkt := msg.(type) {
+	case *lnwire.ChannelReestablish:
+		// The remote peer has sent its view of both commitment chains.
+		// If it never received our last revocation, or our last
+		// commitment signature along with the updates it covers, then
+		// we retransmit them now.
+		// TODO(roasbeef): on data loss, request the remote peer to
+		// force close rather than broadcasting our stale state
+		updates, err := state.channel.ProcessChanSyncMsg(htlcPkt)
+		if err != nil {
+			peerLog.Errorf("unable to resync ChannelPoint(%v): %v",
+				state.chanPoint, err)
+			p.Disconnect()
+			return
+		}
+		for _, update := range updates {
+			p.queueMsg(update, nil)
+		}
+
+		// With the channel state in sync, we can now send our initial
+		// revocation window to the remote peer.
+		for i := 0; i < lnwallet.InitialRevocationWindow; i++ {
+			rev, err := state.channel.ExtendRevocationWindow()
+			if err != nil {
+				peerLog.Errorf("unable to expand revocation "+
+					"window: %v", err)
+				continue
+			}
+			p.queueMsg(rev, nil)
+		}
 	// TODO(roasbeef): timeouts
 	//  * fail if can't parse sphinx mix-header
 	case *lnwire.HTLCAddRequest:
@@ -1113,8 +1826,21 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 
 		// We just received an add request from an upstream peer, so we
 		// add it to our state machine, then add the HTLC to our
-		// "settle" list in the event that we know the pre-image
-		index := state.channel.ReceiveHTLC(htlcPkt)
+		// "settle" list in the event that we know the pre-image. If the
+		// HTLC violates the limits we negotiated with the remote peer,
+		// then they've broken the protocol, so we disconnect.
+		index, err := state.channel.ReceiveHTLC(htlcPkt)
+		if err != nil {
+			peerLog.Errorf("unable to receive HTLC: %v", err)
+			p.Disconnect()
+			return
+		}
+
+		// Any failure sent back along the route is encrypted using the
+		// secret we share with the sender of the HTLC, which sphinx
+		// derived while processing the onion packet.
+		state.failureObfuscators[index] = lnwire.NewFailureObfuscator(
+			sphinxPacket.SharedSecret)
 
 		switch sphinxPacket.Action {
 		// We're the designated payment destination. Therefore we
@@ -1122,15 +1848,46 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 		// us to settle this HTLC.
 		case sphinx.ExitNode:
 			rHash := htlcPkt.RedemptionHashes[0]
-			invoice, err := p.server.invoices.LookupInvoice(rHash)
+
+			// If the HTLC expires too soon for us to safely
+			// settle it, then we'll cancel it back once it's
+			// locked in.
+			if htlcPkt.Expiry <= state.bestHeight+htlcCancelDelta {
+				peerLog.Errorf("HTLC %x expires too soon "+
+					"(expiry=%v, height=%v), cancelling",
+					rHash[:], htlcPkt.Expiry, state.bestHeight)
+				state.htlcsToCancel[index] = &pendingCancel{
+					reason: lnwire.FailExpiryTooSoon,
+				}
+				return
+			}
+
+			// If there's no invoice for this HTLC, the invoice no
+			// longer accepts payment, or the HTLC doesn't pay the
+			// amount requested, then we're unable to settle it.
+			// So we'll cancel it back to the upstream peer once
+			// it's locked in. An HTLC signalling the total amount
+			// of its payment may pay only part of the invoice.
+			amt := btcutil.Amount(htlcPkt.Amount)
+			multiPart := htlcPkt.TotalAmount != 0
+			invoice, partial, reason, err := p.server.invoices.AcceptPayment(
+				rHash, amt, multiPart)
 			if err != nil {
-				// TODO(roasbeef): send a canceHTLC message if we can't settle.
-				peerLog.Errorf("unable to query to locate: %v", err)
-				p.Disconnect()
+				peerLog.Errorf("rejecting HTLC %x: %v", rHash[:],
+					err)
+				state.htlcsToCancel[index] = &pendingCancel{
+					reason: reason,
+				}
+				return
+			}
+
+			// If this HTLC only pays part of the invoice, then
+			// it's held until the remaining parts arrive.
+			if partial {
+				state.htlcsToHold[index] = rHash
 				return
 			}
 
-			// TODO(roasbeef): check values accept if >=
 			state.htlcsToSettle[index] = invoice
 
 		// There are additional hops left within this route, so we
@@ -1139,8 +1896,27 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 		// switch, we'll attach the routing information so the switch
 		// can finalize the circuit.
 		case sphinx.MoreHops:
-			// TODO(roasbeef): send cancel + error if not in rounting table
+			// If the HTLC doesn't leave us enough time to safely
+			// forward it to the next hop, then we'll cancel it
+			// back once it's locked in. The switch additionally
+			// ensures the expiry leaves room for the time-lock
+			// delta of the outgoing link.
+			minExpiry := state.bestHeight + htlcCancelDelta
+			if htlcPkt.Expiry <= minExpiry {
+				peerLog.Errorf("HTLC %x expires too soon to "+
+					"forward (expiry=%v, height=%v), cancelling",
+					htlcPkt.RedemptionHashes[0][:],
+					htlcPkt.Expiry, state.bestHeight)
+				state.htlcsToCancel[index] = &pendingCancel{
+					reason: lnwire.FailExpiryTooSoon,
+				}
+				return
+			}
+
 			state.pendingCircuits[index] = sphinxPacket
+			if htlcPkt.TotalAmount != 0 {
+				state.circuitTotals[index] = htlcPkt.TotalAmount
+			}
 		default:
 			peerLog.Errorf("mal formed onion packet")
 			p.Disconnect()
@@ -1155,6 +1931,18 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 			p.Disconnect()
 			return
 		}
+	case *lnwire.CancelHTLC:
+		// The remote peer has cancelled one of our outgoing HTLC's,
+		// so we remove it from our state machine. Once the removal
+		// has been locked in, the cancel will be propagated back to
+		// the origin of the HTLC.
+		idx := uint32(htlcPkt.HTLCKey)
+		if err := state.channel.ReceiveTimeoutHTLC(idx); err != nil {
+			peerLog.Errorf("cancel for outgoing HTLC rejected: %v", err)
+			p.Disconnect()
+			return
+		}
+		state.cancelReasons[idx] = htlcPkt.Reason
 	case *lnwire.CommitSignature:
 		// We just received a new update to our local commitment chain,
 		// validate this new commitment, closing the link if invalid.
@@ -1204,17 +1992,87 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 		// can them from the pending set, and signal the requster (if
 		// existing) that the payment has been fully fulfilled.
 		var bandwidthUpdate btcutil.Amount
-		settledPayments := make(map[lnwallet.PaymentHash]struct{})
+		settledPayments := make(map[lnwallet.PaymentHash]btcutil.Amount)
+		cancelledHTLCs := make(map[uint32]struct{})
+		cancelReasons := make(map[uint32]lnwire.OpaqueReason)
+		heldHTLCs := make(map[uint32]struct{})
 		numSettled := 0
 		for _, htlc := range htlcsToForward {
-			if p, ok := state.clearedHTCLs[htlc.ParentIndex]; ok {
-				p.err <- nil
-				delete(state.clearedHTCLs, htlc.ParentIndex)
-			}
-
 			// TODO(roasbeef): rework log entries to a shared
 			// interface.
 			if htlc.EntryType != lnwallet.Add {
+				// If one of our outgoing HTLC's was cancelled,
+				// then its value is once again available for
+				// sending.
+				isCancel := htlc.EntryType == lnwallet.Timeout
+				if isCancel {
+					bandwidthUpdate += htlc.Amount
+
+					reason := state.cancelReasons[htlc.ParentIndex]
+					cancelReasons[htlc.ParentIndex] = reason
+					delete(state.cancelReasons, htlc.ParentIndex)
+				}
+
+				if p, ok := state.clearedHTCLs[htlc.ParentIndex]; ok {
+					if isCancel {
+						peerLog.Infof("Payment %x "+
+							"cancelled by remote "+
+							"peer", htlc.RHash[:])
+						p.err <- &remoteFailure{
+							reason: cancelReasons[htlc.ParentIndex],
+						}
+					} else {
+						// Only locally initiated
+						// payments await the
+						// preimage.
+						if p.preimage != nil {
+							p.preimage <- [32]byte(htlc.RPreimage)
+						}
+						p.err <- nil
+					}
+					delete(state.clearedHTCLs, htlc.ParentIndex)
+				}
+
+				continue
+			}
+
+			// If this HTLC can't be settled or forwarded, then we
+			// cancel it back to the remote party now that it has
+			// been locked in.
+			if cancel, ok := state.htlcsToCancel[htlc.Index]; ok {
+				logIndex := htlc.Index
+				if err := state.channel.TimeoutHTLC(logIndex); err != nil {
+					peerLog.Errorf("unable to cancel htlc: %v", err)
+					p.Disconnect()
+					return
+				}
+
+				cancelMsg := &lnwire.CancelHTLC{
+					ChannelPoint: state.chanPoint,
+					HTLCKey:      lnwire.HTLCKey(logIndex),
+					Reason: state.encryptFailure(logIndex,
+						cancel.reason),
+				}
+				p.queueUpdate(state, cancelMsg)
+				delete(state.htlcsToCancel, htlc.Index)
+
+				cancelledHTLCs[htlc.Index] = struct{}{}
+
+				numSettled++
+				continue
+			}
+
+			// If this HTLC pays part of an invoice, then we hand
+			// it to the invoice registry now that it has been
+			// locked in. The registry settles it along with the
+			// remaining parts, or cancels it if they don't arrive.
+			if rHash, ok := state.htlcsToHold[htlc.Index]; ok {
+				p.server.invoices.AddPaymentPart(rHash,
+					*state.chanPoint, htlc.Index, htlc.Amount)
+				delete(state.htlcsToHold, htlc.Index)
+				state.heldParts[htlc.Index] = struct{}{}
+
+				heldHTLCs[htlc.Index] = struct{}{}
 				continue
 			}
 
@@ -1229,7 +2087,8 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 			// state update log, then send the update entry to the
 			// remote party.
 			preimage := invoice.Terms.PaymentPreimage
-			logIndex, err := state.channel.SettleHTLC(preimage)
+			logIndex := htlc.Index
+			err := state.channel.SettleHTLC(preimage, logIndex)
 			if err != nil {
 				peerLog.Errorf("unable to settle htlc: %v", err)
 				p.Disconnect()
@@ -1241,44 +2100,66 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 				HTLCKey:          lnwire.HTLCKey(logIndex),
 				RedemptionProofs: [][32]byte{preimage},
 			}
-			p.queueMsg(settleMsg, nil)
+			p.queueUpdate(state, settleMsg)
 			delete(state.htlcsToSettle, htlc.Index)
+			delete(state.failureObfuscators, logIndex)
 
-			bandwidthUpdate += invoice.Terms.Value
-			settledPayments[htlc.RHash] = struct{}{}
+			bandwidthUpdate += htlc.Amount
+			settledPayments[htlc.RHash] = htlc.Amount
 
 			numSettled++
 		}
 
-		go func() {
-			for _, htlc := range htlcsToForward {
-				// We don't need to forward any HTLC's that we
-				// just settled above.
-				if _, ok := settledPayments[htlc.RHash]; ok {
-					continue
-				}
+		// The packets forwarded to the switch are created here, as the
+		// state consulted while doing so is owned by the htlcManager.
+		var fwdPkts []*htlcPacket
+		for _, htlc := range htlcsToForward {
+			// We don't need to forward any HTLC's that we just
+			// settled, cancelled, or held above.
+			if _, ok := settledPayments[htlc.RHash]; ok {
+				continue
+			}
+			if _, ok := cancelledHTLCs[htlc.Index]; ok {
+				continue
+			}
+			if _, ok := heldHTLCs[htlc.Index]; ok {
+				continue
+			}
 
-				onionPkt := state.pendingCircuits[htlc.Index]
-				delete(state.pendingCircuits, htlc.Index)
+			onionPkt := state.pendingCircuits[htlc.Index]
+			totalAmt := state.circuitTotals[htlc.Index]
+			delete(state.pendingCircuits, htlc.Index)
+			delete(state.circuitTotals, htlc.Index)
 
-				// Send this fully activated HTLC to the htlc
-				// switch to continue the chained clear/settle.
-				pkt, err := logEntryToHtlcPkt(*state.chanPoint,
-					htlc, onionPkt)
-				if err != nil {
-					peerLog.Errorf("unable to make htlc pkt: %v",
-						err)
-					continue
-				}
+			// Send this fully activated HTLC to the htlc switch to
+			// continue the chained clear/settle.
+			pkt, err := logEntryToHtlcPkt(*state.chanPoint, htlc,
+				onionPkt)
+			if err != nil {
+				peerLog.Errorf("unable to make htlc pkt: %v", err)
+				continue
+			}
 
-				state.switchChan <- pkt
+			// Cancels carry the encrypted failure sent by the
+			// remote peer back towards the sender.
+			if cancel, ok := pkt.msg.(*lnwire.CancelHTLC); ok {
+				cancel.Reason = cancelReasons[htlc.ParentIndex]
 			}
 
-		}()
+			// Parts of a multi-part payment carry the total amount
+			// of the payment on to the next hop.
+			if add, ok := pkt.msg.(*lnwire.HTLCAddRequest); ok {
+				add.TotalAmount = totalAmt
+			}
+			pkt.bestHeight = state.bestHeight
 
-		if numSettled == 0 {
-			return
+			fwdPkts = append(fwdPkts, pkt)
 		}
+		go func() {
+			for _, pkt := range fwdPkts {
+				state.switchChan <- pkt
+			}
+		}()
 
 		// Send an update to the htlc switch of our newly available
 		// payment bandwidth.
@@ -1288,6 +2169,10 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 				bandwidthUpdate)
 		}
 
+		if numSettled == 0 {
+			return
+		}
+
 		// With all the settle updates added to the local and remote
 		// HTLC logs, initiate a state transition by updating the
 		// remote commitment chain.
@@ -1302,8 +2187,9 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 
 		// Notify the invoiceRegistry of the invoices we just settled
 		// with this latest commitment update.
-		for invoice, _ := range settledPayments {
-			err := p.server.invoices.SettleInvoice(wire.ShaHash(invoice))
+		for invoice, amtPaid := range settledPayments {
+			err := p.server.invoices.SettleInvoice(wire.ShaHash(invoice),
+				amtPaid)
 			if err != nil {
 				peerLog.Errorf("unable to settle invoice: %v", err)
 			}
@@ -1334,6 +2220,16 @@ func (p *peer) updateCommitTx(state *commitmentState) (bool, error) {
 		CommitSig:    parsedSig,
 		LogIndex:     uint64(logIndexTheirs),
 	}
+
+	// Before sending the signature, we store it along with the updates it
+	// covers, so both can be retransmitted during channel reestablishment
+	// if they never reach the remote peer.
+	err = state.channel.StoreCommitDiff(commitSig, state.pendingUpdates)
+	if err != nil {
+		return false, err
+	}
+	state.pendingUpdates = nil
+
 	p.queueMsg(commitSig, nil)
 
 	// Move all pending updates to the map of cleared HTLC's, clearing out
@@ -1369,15 +2265,23 @@ func logEntryToHtlcPkt(chanPoint wire.OutPoint,
 			return nil, err
 		}
 
+		// The amount and expiry are those of the incoming HTLC. The
+		// switch deducts the fee and time-lock delta of the outgoing
+		// link once it has been selected.
 		msg = &lnwire.HTLCAddRequest{
 			Amount:           lnwire.CreditsAmount(pd.Amount),
+			Expiry:           pd.Timeout,
 			RedemptionHashes: [][32]byte{pd.RHash},
 			OnionBlob:        b.Bytes(),
 		}
+		pkt.index = pd.Index
 	case lnwallet.Settle:
 		msg = &lnwire.HTLCSettleRequest{
 			RedemptionProofs: [][32]byte{pd.RPreimage},
 		}
+	case lnwallet.Timeout:
+		msg = &lnwire.CancelHTLC{}
+		pkt.payHash = pd.RHash
 	}
 
 	pkt.amt = pd.Amount
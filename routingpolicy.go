@@ -0,0 +1,216 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// policyKey identifies a single direction of a channel within the network:
+// the channel itself, along with the node enforcing the policy when
+// forwarding HTLC's over it.
+type policyKey struct {
+	chanPoint wire.OutPoint
+	node      [33]byte
+}
+
+// newPolicyKey creates the policyKey for the policy enforced by the passed
+// node over the target channel.
+func newPolicyKey(chanPoint *wire.OutPoint, node *btcec.PublicKey) policyKey {
+	k := policyKey{chanPoint: *chanPoint}
+	copy(k.node[:], node.SerializeCompressed())
+	return k
+}
+
+// policyTable houses the latest routing policy advertised for each channel
+// within the network we're aware of, including our own. The table is
+// populated by the ChannelPolicyUpdate messages gossiped amongst peers, and
+// consulted when selecting the amount and expiry of outgoing payments.
+//
+// TODO(roasbeef): prune the policies of closed channels
+type policyTable struct {
+	sync.RWMutex
+
+	policies map[policyKey]*lnwire.ChannelPolicyUpdate
+}
+
+// newPolicyTable creates a new, empty policyTable.
+func newPolicyTable() *policyTable {
+	return &policyTable{
+		policies: make(map[policyKey]*lnwire.ChannelPolicyUpdate),
+	}
+}
+
+// processUpdate adds the passed update to the table. True is returned if the
+// update supersedes the policy previously known for the channel, indicating
+// the update should be relayed to our other peers. Stale updates, or those
+// which fail validation, are ignored.
+func (t *policyTable) processUpdate(update *lnwire.ChannelPolicyUpdate) bool {
+	if err := update.Validate(); err != nil {
+		return false
+	}
+
+	key := newPolicyKey(update.ChannelPoint, update.NodeID)
+
+	t.Lock()
+	defer t.Unlock()
+
+	if prior, ok := t.policies[key]; ok && prior.UpdateIndex >= update.UpdateIndex {
+		return false
+	}
+	t.policies[key] = update
+
+	return true
+}
+
+// nextUpdateIndex returns the update index to be used when advertising a new
+// policy enforced by the passed node over the target channel. The index is
+// derived from the current time, though it's always greater than the index of
+// the prior update.
+func (t *policyTable) nextUpdateIndex(chanPoint *wire.OutPoint,
+	node *btcec.PublicKey) uint32 {
+
+	updateIndex := uint32(time.Now().Unix())
+
+	t.RLock()
+	prior, ok := t.policies[newPolicyKey(chanPoint, node)]
+	t.RUnlock()
+	if ok && prior.UpdateIndex >= updateIndex {
+		updateIndex = prior.UpdateIndex + 1
+	}
+
+	return updateIndex
+}
+
+// allUpdates returns the latest update known for each channel within the
+// table. This is used to synchronize the table of newly connected peers.
+func (t *policyTable) allUpdates() []*lnwire.ChannelPolicyUpdate {
+	t.RLock()
+	defer t.RUnlock()
+
+	updates := make([]*lnwire.ChannelPolicyUpdate, 0, len(t.policies))
+	for _, update := range t.policies {
+		updates = append(updates, update)
+	}
+
+	return updates
+}
+
+// hopPolicy returns the policy enforced by the passed node when forwarding
+// HTLC's to the next node. As the forwarding node may select any of its
+// channels to the next node, the returned policy is the most demanding amongst
+// them. If no policy is known for any channel between the two nodes, then nil
+// is returned.
+func (t *policyTable) hopPolicy(node, next *btcec.PublicKey) *channeldb.ChannelPolicy {
+	var nodeKey, nextKey [33]byte
+	copy(nodeKey[:], node.SerializeCompressed())
+	copy(nextKey[:], next.SerializeCompressed())
+
+	t.RLock()
+	defer t.RUnlock()
+
+	var policy *channeldb.ChannelPolicy
+	for key, update := range t.policies {
+		if key.node != nodeKey {
+			continue
+		}
+
+		var peerKey [33]byte
+		copy(peerKey[:], update.PeerID.SerializeCompressed())
+		if peerKey != nextKey {
+			continue
+		}
+
+		if policy == nil {
+			policy = &channeldb.ChannelPolicy{}
+		}
+		if update.BaseFee > policy.BaseFee {
+			policy.BaseFee = update.BaseFee
+		}
+		if update.FeeRate > policy.FeeRate {
+			policy.FeeRate = update.FeeRate
+		}
+		if update.MinHTLC > policy.MinHTLC {
+			policy.MinHTLC = update.MinHTLC
+		}
+		if update.TimeLockDelta > policy.TimeLockDelta {
+			policy.TimeLockDelta = update.TimeLockDelta
+		}
+	}
+
+	return policy
+}
+
+// paymentRoute is a route selected for an outgoing payment, along with the
+// policy enforced by each node which forwards the payment.
+type paymentRoute struct {
+	// hops is the identity public key of each node within the route,
+	// starting from the first hop, and ending with the destination.
+	hops []*btcec.PublicKey
+
+	// policies is the policy enforced by each forwarding node within the
+	// route, such that policies[i] is the policy hops[i] enforces when
+	// forwarding to hops[i+1].
+	policies []*channeldb.ChannelPolicy
+}
+
+// newPaymentRoute creates a paymentRoute for the passed hops, looking up the
+// policy of each forwarding node within the table. If the policy of a node
+// is unknown, then we assume it forwards free of charge, with the default
+// time-lock delta.
+func (t *policyTable) newPaymentRoute(hops []*btcec.PublicKey) *paymentRoute {
+	route := &paymentRoute{
+		hops:     hops,
+		policies: make([]*channeldb.ChannelPolicy, 0, len(hops)),
+	}
+	for i := 0; i < len(hops)-1; i++ {
+		policy := t.hopPolicy(hops[i], hops[i+1])
+		if policy == nil {
+			policy = &channeldb.ChannelPolicy{
+				TimeLockDelta: defaultTimeLockDelta,
+			}
+		}
+
+		route.policies = append(route.policies, policy)
+	}
+
+	return route
+}
+
+// sendAmount returns the amount which must be sent to the first hop in order
+// to deliver the passed amount to the destination, accounting for the fee
+// charged by each forwarding node.
+func (r *paymentRoute) sendAmount(amt btcutil.Amount) btcutil.Amount {
+	for i := len(r.policies) - 1; i >= 0; i-- {
+		amt += r.policies[i].Fee(amt)
+	}
+
+	return amt
+}
+
+// deliverAmount returns the amount delivered to the destination when sending
+// the passed amount to the first hop. This is the inverse of sendAmount.
+func (r *paymentRoute) deliverAmount(sent btcutil.Amount) btcutil.Amount {
+	for _, policy := range r.policies {
+		sent = policy.ForwardAmount(sent)
+	}
+
+	return sent
+}
+
+// timeLockDelta returns the sum of the time-lock deltas of each forwarding
+// node, which is the number of blocks the expiry of the HTLC sent to the
+// first hop must exceed the expiry of the HTLC received by the destination.
+func (r *paymentRoute) timeLockDelta() uint32 {
+	var delta uint32
+	for _, policy := range r.policies {
+		delta += policy.TimeLockDelta
+	}
+
+	return delta
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/zpay32"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcd/wire"
@@ -178,7 +179,10 @@ func (r *rpcServer) ConnectPeer(ctx context.Context,
 	idAtHost := fmt.Sprintf("%v@%v", in.Addr.PubKeyHash, in.Addr.Host)
 	rpcsLog.Debugf("[connectpeer] peer=%v", idAtHost)
 
-	peerAddr, err := lndc.LnAddrFromString(idAtHost, activeNetParams.Params)
+	// If we're connecting through a proxy, then the peer's hostname is
+	// resolved by the proxy.
+	peerAddr, err := lndc.LnAddrFromString(idAtHost, activeNetParams.Params,
+		cfg.SOCKSProxy != "")
 	if err != nil {
 		rpcsLog.Errorf("(connectpeer): error parsing ln addr: %v", err)
 		return nil, err
@@ -194,8 +198,9 @@ func (r *rpcServer) ConnectPeer(ctx context.Context,
 	return &lnrpc.ConnectPeerResponse{peerID}, nil
 }
 
-// OpenChannel attempts to open a singly funded channel specified in the
-// request to a remote peer.
+// OpenChannel attempts to open a channel specified in the request to a remote
+// peer. If the request asks the remote peer to contribute funds, then a dual
+// funded channel is opened, otherwise the channel is singly funded.
 func (r *rpcServer) OpenChannel(in *lnrpc.OpenChannelRequest,
 	updateStream lnrpc.Lightning_OpenChannelServer) error {
 
@@ -254,7 +259,6 @@ out:
 func (r *rpcServer) CloseChannel(in *lnrpc.CloseChannelRequest,
 	updateStream lnrpc.Lightning_CloseChannelServer) error {
 
-	force := in.Force
 	index := in.ChannelPoint.OutputIndex
 	txid, err := wire.NewShaHash(in.ChannelPoint.FundingTxid)
 	if err != nil {
@@ -266,7 +270,16 @@ func (r *rpcServer) CloseChannel(in *lnrpc.CloseChannelRequest,
 	rpcsLog.Tracef("[closechannel] request for ChannelPoint(%v)",
 		targetChannelPoint)
 
-	updateChan, errChan := r.server.htlcSwitch.CloseLink(targetChannelPoint, force)
+	// If the user has requested a force close, then we'll execute a
+	// unilateral closure of the channel, otherwise a cooperative close is
+	// attempted.
+	closeType := CloseRegular
+	if in.Force {
+		closeType = CloseForce
+	}
+
+	updateChan, errChan := r.server.htlcSwitch.CloseLink(targetChannelPoint,
+		closeType)
 
 out:
 	for {
@@ -321,6 +334,13 @@ func (r *rpcServer) GetInfo(ctx context.Context,
 		return nil, err
 	}
 
+	// If we're reachable through a Tor hidden service, then advertise
+	// the URI peers may use to connect to us over Tor.
+	var uris []string
+	if cfg.OnionAddr != "" {
+		uris = append(uris, fmt.Sprintf("%x@%v", idPub, cfg.OnionAddr))
+	}
+
 	return &lnrpc.GetInfoResponse{
 		LightningId:        hex.EncodeToString(r.server.lightningID[:]),
 		IdentityPubkey:     hex.EncodeToString(idPub),
@@ -328,6 +348,7 @@ func (r *rpcServer) GetInfo(ctx context.Context,
 		NumPendingChannels: pendingChannels,
 		NumActiveChannels:  activeChannels,
 		NumPeers:           uint32(len(serverPeers)),
+		Uris:               uris,
 	}, nil
 }
 
@@ -353,6 +374,13 @@ func (r *rpcServer) ListPeers(ctx context.Context,
 			Inbound:     serverPeer.inbound,
 			BytesRecv:   atomic.LoadUint64(&serverPeer.bytesReceived),
 			BytesSent:   atomic.LoadUint64(&serverPeer.bytesSent),
+			PingTime:    atomic.LoadInt64(&serverPeer.pingTime),
+			GlobalFeatures: marshallFeatures(
+				serverPeer.remoteGlobalFeatures,
+			),
+			LocalFeatures: marshallFeatures(
+				serverPeer.remoteLocalFeatures,
+			),
 		}
 
 		resp.Peers = append(resp.Peers, peer)
@@ -363,6 +391,22 @@ func (r *rpcServer) ListPeers(ctx context.Context,
 	return resp, nil
 }
 
+// marshallFeatures converts the passed feature vector into its RPC
+// representation, ordered by feature bit.
+func marshallFeatures(features lnwire.FeatureVector) []*lnrpc.Feature {
+	rpcFeatures := make([]*lnrpc.Feature, 0, len(features))
+	for _, bit := range features.Bits() {
+		rpcFeatures = append(rpcFeatures, &lnrpc.Feature{
+			Bit:        uint32(bit),
+			Name:       bit.Name(),
+			IsRequired: bit.IsRequired(),
+			IsKnown:    bit.IsKnown(),
+		})
+	}
+
+	return rpcFeatures
+}
+
 // WalletBalance returns the sum of all confirmed unspent outputs under control
 // by the wallet. This method can be modified by having the request specify
 // only witness outputs should be factored into the final output sum.
@@ -475,14 +519,97 @@ func (r *rpcServer) ListChannels(ctx context.Context,
 	return resp, nil
 }
 
+// UpdateChannelPolicy updates the routing policy enforced when forwarding
+// HTLC's over the target channel, or over all open channels if no channel is
+// specified. The new policy is written to disk, enforced from this point on,
+// and advertised to the network.
+func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
+	in *lnrpc.UpdateChannelPolicyRequest) (*lnrpc.UpdateChannelPolicyResponse, error) {
+
+	if in.BaseFee < 0 || in.MinHtlc < 0 {
+		return nil, fmt.Errorf("base fee and min htlc must be " +
+			"non-negative")
+	}
+
+	policy := &channeldb.ChannelPolicy{
+		BaseFee:       btcutil.Amount(in.BaseFee),
+		FeeRate:       in.FeeRate,
+		MinHTLC:       btcutil.Amount(in.MinHtlc),
+		TimeLockDelta: in.TimeLockDelta,
+	}
+
+	openChans, err := r.server.chanDB.FetchAllChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	// If a channel point was specified, then only the matching channel
+	// is updated.
+	var targetChanPoint *wire.OutPoint
+	if in.ChanPoint != nil {
+		txid, err := wire.NewShaHash(in.ChanPoint.FundingTxid)
+		if err != nil {
+			return nil, err
+		}
+		targetChanPoint = wire.NewOutPoint(txid, in.ChanPoint.OutputIndex)
+	}
+
+	numUpdated := 0
+	for _, channel := range openChans {
+		chanPoint := channel.ChanID
+		if targetChanPoint != nil && *chanPoint != *targetChanPoint {
+			continue
+		}
+
+		if err := r.server.chanDB.PutChannelPolicy(chanPoint, policy); err != nil {
+			return nil, err
+		}
+		numUpdated++
+
+		// If the channel is currently active, then the new policy is
+		// enforced immediately and advertised to the network.
+		// Otherwise, it's advertised once the link is registered.
+		link := r.server.htlcSwitch.UpdateLinkPolicy(chanPoint, policy)
+		if link == nil {
+			continue
+		}
+		r.server.announceChannelPolicy(chanPoint, link.peer.identityPub,
+			policy)
+	}
+
+	if targetChanPoint != nil && numUpdated == 0 {
+		return nil, fmt.Errorf("channel point %v not found",
+			targetChanPoint)
+	}
+
+	rpcsLog.Infof("[updatechannelpolicy] updated policy of %v channels: "+
+		"base_fee=%v, fee_rate=%v, min_htlc=%v, time_lock_delta=%v",
+		numUpdated, policy.BaseFee, policy.FeeRate, policy.MinHTLC,
+		policy.TimeLockDelta)
+
+	return &lnrpc.UpdateChannelPolicyResponse{}, nil
+}
+
 // SendPayment dispatches a bi-directional streaming RPC for sending payments
 // through the Lightning Network. A single RPC invocation creates a persistent
 // bi-directional stream allowing clients to rapidly send payments through the
-// Lightning Network with a single persistent connection.
+// Lightning Network with a single persistent connection. Each payment is
+// recorded within the database, and a response carrying either the payment
+// preimage or the reason for failure is sent once the payment has been
+// resolved.
 func (r *rpcServer) SendPayment(paymentStream lnrpc.Lightning_SendPaymentServer) error {
 	queryTimeout := time.Duration(time.Minute)
 	errChan := make(chan error, 1)
 
+	// As responses are sent from the goroutine tracking each individual
+	// payment, writes to the stream need to be serialized.
+	var sendMtx sync.Mutex
+	sendResponse := func(resp *lnrpc.SendResponse) error {
+		sendMtx.Lock()
+		defer sendMtx.Unlock()
+		return paymentStream.Send(resp)
+	}
+
 	for {
 		select {
 		case err := <-errChan:
@@ -498,26 +625,32 @@ func (r *rpcServer) SendPayment(paymentStream lnrpc.Lightning_SendPaymentServer)
 				return err
 			}
 
-			// Query the routing table for a potential path to the
-			// destination node. If a path is ultimately
-			// unavailable, then an error will be returned.
-			destNode := hex.EncodeToString(nextPayment.Dest)
-			targetVertex := graph.NewID(destNode)
-			path, err := r.server.routingMgr.FindPath(targetVertex,
-				queryTimeout)
-			if err != nil {
-				return err
-			}
-			rpcsLog.Tracef("[sendpayment] selected route: %v", path)
+			// If the payment was specified using an encoded
+			// payment request, then the destination, payment hash
+			// and amount are all taken from the decoded request.
+			if nextPayment.PaymentRequest != "" {
+				payReq, err := zpay32.Decode(nextPayment.PaymentRequest)
+				if err != nil {
+					return err
+				}
+				if payReq.Net.Net != activeNetParams.Net {
+					return fmt.Errorf("payment request is for "+
+						"%v, we're on %v", payReq.Net.Name,
+						activeNetParams.Name)
+				}
+				if payReq.IsExpired() {
+					return fmt.Errorf("payment request "+
+						"expired at %v", payReq.ExpiresAt())
+				}
 
-			// Generate the raw encoded sphinx packet to be
-			// included along with the HTLC add message.
-			// We snip off the first hop from the path as within
-			// the routing table's star graph, we're always the
-			// first hop.
-			sphinxPacket, err := generateSphinxPacket(path[1:])
-			if err != nil {
-				return err
+				nextPayment.Dest = payReq.Destination.SerializeCompressed()
+				nextPayment.PaymentHash = payReq.PaymentHash[:]
+
+				// A zero amount within the payment request
+				// allows the payer to choose the amount sent.
+				if payReq.Amount != 0 {
+					nextPayment.Amt = int64(payReq.Amount)
+				}
 			}
 
 			// If we're in debug HTLC mode, then all outgoing
@@ -531,39 +664,154 @@ func (r *rpcServer) SendPayment(paymentStream lnrpc.Lightning_SendPaymentServer)
 				copy(rHash[:], nextPayment.PaymentHash)
 			}
 
-			// Craft an HTLC packet to send to the routing
-			// sub-system. The meta-data within this packet will be
-			// used to route the payment through the network.
-			htlcAdd := &lnwire.HTLCAddRequest{
-				Amount:           lnwire.CreditsAmount(nextPayment.Amt),
-				RedemptionHashes: [][32]byte{rHash},
-				OnionBlob:        sphinxPacket,
+			// Query the routing table for a potential path to the
+			// destination node. If a path is ultimately
+			// unavailable, then we report the failure back to the
+			// client rather than tearing down the entire stream.
+			destNode := hex.EncodeToString(nextPayment.Dest)
+			targetVertex := graph.NewID(destNode)
+			path, err := r.server.routingMgr.FindPath(targetVertex,
+				queryTimeout)
+			if err != nil {
+				rpcsLog.Errorf("unable to find route to %v: %v",
+					destNode, err)
+
+				resp := &lnrpc.SendResponse{
+					PaymentHash: rHash[:],
+					PaymentFailure: &lnrpc.PaymentFailure{
+						Code:    lnrpc.PaymentFailure_NO_ROUTE,
+						Message: err.Error(),
+					},
+				}
+				if err := sendResponse(resp); err != nil {
+					return err
+				}
+				continue
+			}
+			rpcsLog.Tracef("[sendpayment] selected route: %v", path)
+
+			// Look up the policy of each node which will forward
+			// the payment. We snip off the first vertex of the
+			// path as within the routing table's star graph,
+			// we're always the first hop.
+			hopKeys, err := parseRoute(path[1:])
+			if err != nil {
+				return err
 			}
-			firstHopPub, err := hex.DecodeString(path[1].String())
+			paymentRoute := r.server.policies.newPaymentRoute(hopKeys)
+
+			// Compute the absolute expiry of the HTLC. Each
+			// forwarding node decrements the expiry by its
+			// time-lock delta, so the HTLC reaching the
+			// destination expires finalHTLCExpiry blocks from now.
+			currentHeight, err := r.server.bio.GetCurrentHeight()
 			if err != nil {
 				return err
 			}
+			expiry := uint32(currentHeight) + finalHTLCExpiry +
+				paymentRoute.timeLockDelta()
+
+			firstHopPub := hopKeys[0].SerializeCompressed()
 			destAddr := wire.ShaHash(fastsha256.Sum256(firstHopPub))
-			htlcPkt := &htlcPacket{
-				dest: destAddr,
-				msg:  htlcAdd,
+
+			// Determine how the payment is to be split amongst our
+			// links to the first hop. Each part pays the fees of
+			// the forwarding nodes in addition to the amount
+			// delivered to the destination. All parts then follow
+			// the same route beyond the first hop.
+			amt := btcutil.Amount(nextPayment.Amt)
+			parts, err := r.server.htlcSwitch.SplitPayment(destAddr,
+				amt, paymentRoute)
+			if err != nil {
+				rpcsLog.Errorf("unable to send payment to %v: %v",
+					destNode, err)
+
+				resp := &lnrpc.SendResponse{
+					PaymentHash:    rHash[:],
+					PaymentFailure: newPaymentFailure(err),
+				}
+				if err := sendResponse(resp); err != nil {
+					return err
+				}
+				continue
+			}
+			if len(parts) > 1 {
+				rpcsLog.Infof("[sendpayment] splitting payment "+
+					"%x of %v into %v parts: %v", rHash[:],
+					amt, len(parts), parts)
+			}
+
+			// Before dispatching the HTLC, record the payment as
+			// in-flight within the database along with the route
+			// selected. The record is updated once the HTLC has
+			// been either settled or cancelled.
+			route := make([]string, 0, len(path)-1)
+			payment := &channeldb.OutgoingPayment{
+				PaymentHash:  rHash,
+				Amount:       amt,
+				Status:       channeldb.StatusInFlight,
+				CreationDate: time.Now(),
+			}
+			for _, part := range parts {
+				payment.Fee += paymentRoute.sendAmount(part) - part
+			}
+			for _, hop := range path[1:] {
+				hopPub, err := hex.DecodeString(hop.String())
+				if err != nil {
+					return err
+				}
+
+				var hopKey [33]byte
+				copy(hopKey[:], hopPub)
+				payment.Path = append(payment.Path, hopKey)
+
+				route = append(route, hop.String())
+			}
+			paymentID, err := r.server.chanDB.AddPayment(payment)
+			if err != nil {
+				return err
 			}
 
 			// TODO(roasbeef): semaphore to limit num outstanding
 			// goroutines.
 			go func() {
-				// Finally, send this next packet to the routing layer in order
-				// to complete the next payment.
-				// TODO(roasbeef): this should go through the L3 router once
-				// multi-hop is in place.
-				if err := r.server.htlcSwitch.SendHTLC(htlcPkt); err != nil {
-					errChan <- err
-					return
+				resp := &lnrpc.SendResponse{
+					PaymentHash:  rHash[:],
+					PaymentRoute: route,
+					Fee:          int64(payment.Fee),
 				}
 
-				// TODO(roasbeef): proper responses
-				resp := &lnrpc.SendResponse{}
-				if err := paymentStream.Send(resp); err != nil {
+				// Finally, send the payment to the routing
+				// layer, blocking until the payment has been
+				// resolved.
+				// TODO(roasbeef): this should go through the L3
+				// router once multi-hop is in place.
+				preimage, err := r.sendPaymentParts(destAddr,
+					paymentRoute, rHash, expiry, parts)
+				if err != nil {
+					rpcsLog.Errorf("payment %x failed: %v",
+						rHash[:], err)
+
+					resp.PaymentFailure = newPaymentFailure(err)
+
+					err := r.server.chanDB.FailPayment(paymentID,
+						err.Error())
+					if err != nil {
+						errChan <- err
+						return
+					}
+				} else {
+					err := r.server.chanDB.SettlePayment(paymentID,
+						preimage)
+					if err != nil {
+						errChan <- err
+						return
+					}
+
+					resp.PaymentPreimage = preimage[:]
+				}
+
+				if err := sendResponse(resp); err != nil {
 					errChan <- err
 					return
 				}
@@ -574,14 +822,203 @@ func (r *rpcServer) SendPayment(paymentStream lnrpc.Lightning_SendPaymentServer)
 	return nil
 }
 
-// generateSphinxPacket generates then encodes a sphinx packet which encodes
-// the onion route specified by the passed list of graph vertexes. The blob
-// returned from this function can immediately be included within an HTLC add
-// packet to be sent to the first hop within the route.
-func generateSphinxPacket(vertexes []graph.ID) ([]byte, error) {
-	var dest sphinx.LightningAddress
-	e2eMessage := []byte("test")
+// sendPaymentParts sends each of the passed parts of a payment over the route
+// starting at the interface identified by firstHop, blocking until the
+// payment has been either settled or cancelled. Each part is sent within its
+// own HTLC, carrying the amount to be delivered to the destination along with
+// the fees charged by the forwarding nodes. The destination holds each part
+// until their sum covers the invoice, at which point all parts are settled at
+// once. As a result, the payment has succeeded if any part has been settled.
+func (r *rpcServer) sendPaymentParts(firstHop wire.ShaHash, route *paymentRoute,
+	rHash [32]byte, expiry uint32, parts []btcutil.Amount) ([32]byte, error) {
+
+	hopKeys := route.hops
+
+	var totalAmt btcutil.Amount
+	for _, partAmt := range parts {
+		totalAmt += partAmt
+	}
+
+	// Craft an HTLC packet for each part to send to the routing
+	// sub-system. Each part carries its own freshly generated sphinx
+	// packet, as the hops along the route reject replayed packets.
+	// Additionally, each part has its own failure circuit, derived from
+	// the session key of its sphinx packet, used to decrypt the failure
+	// sent back if the part is cancelled.
+	htlcPkts := make([]*htlcPacket, len(parts))
+	circuits := make([]*lnwire.FailureCircuit, len(parts))
+	for i, partAmt := range parts {
+		sessionKey, err := btcec.NewPrivateKey(btcec.S256())
+		if err != nil {
+			return [32]byte{}, err
+		}
+
+		sphinxPacket, err := generateSphinxPacket(hopKeys, sessionKey)
+		if err != nil {
+			return [32]byte{}, err
+		}
+
+		circuits[i] = lnwire.NewFailureCircuit(sessionKey, hopKeys)
+
+		htlcAdd := &lnwire.HTLCAddRequest{
+			Amount:           lnwire.CreditsAmount(route.sendAmount(partAmt)),
+			Expiry:           expiry,
+			RedemptionHashes: [][32]byte{rHash},
+			OnionBlob:        sphinxPacket,
+		}
+
+		// If the payment has been split, then each part signals the
+		// total amount of the payment, so the destination holds the
+		// parts until their sum covers the invoice.
+		if len(parts) > 1 {
+			htlcAdd.TotalAmount = lnwire.CreditsAmount(totalAmt)
+		}
+
+		htlcPkts[i] = &htlcPacket{
+			dest: firstHop,
+			msg:  htlcAdd,
+		}
+	}
+
+	type partResult struct {
+		preimage [32]byte
+		err      error
+	}
+	results := make(chan *partResult, len(htlcPkts))
+	for i, htlcPkt := range htlcPkts {
+		go func(htlcPkt *htlcPacket, circuit *lnwire.FailureCircuit) {
+			preimage, err := r.server.htlcSwitch.SendHTLC(htlcPkt)
+
+			// If the part was cancelled by a node along the
+			// route, then we decrypt the failure in order to
+			// learn why, and where, the part failed.
+			if failure, ok := err.(*remoteFailure); ok {
+				err = decryptFailure(circuit, failure)
+			}
+
+			results <- &partResult{preimage, err}
+		}(htlcPkt, circuits[i])
+	}
+
+	// Wait for all parts to be resolved. The error of the first failed
+	// part is returned only if none of the parts were settled.
+	var (
+		preimage [32]byte
+		settled  bool
+		firstErr error
+	)
+	for i := 0; i < len(htlcPkts); i++ {
+		result := <-results
+		switch {
+		case result.err == nil:
+			preimage = result.preimage
+			settled = true
+		case firstErr == nil:
+			firstErr = result.err
+		}
+	}
+	if !settled {
+		return [32]byte{}, firstErr
+	}
+
+	return preimage, nil
+}
+
+// forwardingError is returned when a payment has been cancelled by a node
+// along the route, and the failure sent back has been decrypted.
+type forwardingError struct {
+	// hopIndex is the index of the node which cancelled the payment
+	// within the route, starting from the first hop.
+	hopIndex int
 
+	// source is the identity public key of the node which cancelled the
+	// payment.
+	source *btcec.PublicKey
+
+	// code is the reason the payment was cancelled.
+	code lnwire.FailCode
+}
+
+// Error returns a human readable version of the forwarding error.
+//
+// NOTE: Part of the error interface.
+func (f *forwardingError) Error() string {
+	return fmt.Sprintf("payment cancelled at hop %v (%x): %v", f.hopIndex,
+		f.source.SerializeCompressed(), f.code)
+}
+
+// decryptFailure attempts to decrypt the failure sent back along the route of
+// a cancelled payment using the payment's failure circuit. If the failure
+// can't be decrypted, then the original failure is returned.
+func decryptFailure(circuit *lnwire.FailureCircuit,
+	failure *remoteFailure) error {
+
+	hopIndex, code, err := circuit.DecryptFailure(failure.reason)
+	if err != nil {
+		rpcsLog.Errorf("unable to decrypt payment failure: %v", err)
+		return failure
+	}
+
+	return &forwardingError{
+		hopIndex: hopIndex,
+		source:   circuit.Route()[hopIndex],
+		code:     code,
+	}
+}
+
+// failCodeToRPC maps the failure codes sent back by nodes along the route of
+// a payment to the codes reported to RPC clients.
+var failCodeToRPC = map[lnwire.FailCode]lnrpc.PaymentFailure_FailureCode{
+	lnwire.FailUnknownPaymentHash:   lnrpc.PaymentFailure_UNKNOWN_PAYMENT_HASH,
+	lnwire.FailInvoiceNotPayable:    lnrpc.PaymentFailure_INVOICE_NOT_PAYABLE,
+	lnwire.FailAmountTooLow:         lnrpc.PaymentFailure_AMOUNT_TOO_LOW,
+	lnwire.FailAmountTooHigh:        lnrpc.PaymentFailure_AMOUNT_TOO_HIGH,
+	lnwire.FailExpiryTooSoon:        lnrpc.PaymentFailure_EXPIRY_TOO_SOON,
+	lnwire.FailUnknownNextPeer:      lnrpc.PaymentFailure_UNKNOWN_NEXT_PEER,
+	lnwire.FailHTLCTimeout:          lnrpc.PaymentFailure_HTLC_TIMEOUT,
+	lnwire.FailInsufficientCapacity: lnrpc.PaymentFailure_INSUFFICIENT_CAPACITY,
+	lnwire.FailFeeInsufficient:      lnrpc.PaymentFailure_FEE_INSUFFICIENT,
+	lnwire.FailIncorrectExpiryDelta: lnrpc.PaymentFailure_INCORRECT_EXPIRY_DELTA,
+}
+
+// newPaymentFailure maps an error returned by the htlcSwitch when attempting
+// to send a payment to the structured failure reported to RPC clients.
+func newPaymentFailure(err error) *lnrpc.PaymentFailure {
+	failure := &lnrpc.PaymentFailure{
+		Message: err.Error(),
+	}
+
+	// If the payment was cancelled by a node along the route, then we
+	// also report which node cancelled it.
+	switch e := err.(type) {
+	case *forwardingError:
+		failure.Code = failCodeToRPC[e.code]
+		failure.FailureSourceIndex = uint32(e.hopIndex)
+		failure.FailureSource = hex.EncodeToString(
+			e.source.SerializeCompressed())
+		return failure
+	case *remoteFailure:
+		failure.Code = lnrpc.PaymentFailure_CANCELLED
+		return failure
+	}
+
+	switch err {
+	case errUnknownLink:
+		failure.Code = lnrpc.PaymentFailure_UNKNOWN_NEXT_PEER
+	case errInsufficientCapacity:
+		failure.Code = lnrpc.PaymentFailure_INSUFFICIENT_CAPACITY
+	case errPaymentCancelled:
+		failure.Code = lnrpc.PaymentFailure_CANCELLED
+	default:
+		failure.Code = lnrpc.PaymentFailure_UNKNOWN
+	}
+
+	return failure
+}
+
+// parseRoute parses the identity public key of each node within the route
+// specified by the passed list of graph vertexes.
+func parseRoute(vertexes []graph.ID) ([]*btcec.PublicKey, error) {
 	route := make([]*btcec.PublicKey, len(vertexes))
 	for i, vertex := range vertexes {
 		vertexBytes, err := hex.DecodeString(vertex.String())
@@ -597,11 +1034,25 @@ func generateSphinxPacket(vertexes []graph.ID) ([]byte, error) {
 		route[i] = pub
 	}
 
+	return route, nil
+}
+
+// generateSphinxPacket generates then encodes a sphinx packet which encodes
+// the onion route specified by the passed list of public keys. The packet is
+// constructed using the passed session key, from which the secret shared with
+// each hop is derived. The blob returned from this function can immediately be
+// included within an HTLC add packet to be sent to the first hop within the
+// route.
+func generateSphinxPacket(route []*btcec.PublicKey,
+	sessionKey *btcec.PrivateKey) ([]byte, error) {
+	var dest sphinx.LightningAddress
+	e2eMessage := []byte("test")
+
 	// Next generate the onion routing packet which allows
 	// us to perform privacy preserving source routing
 	// across the network.
 	var onionBlob bytes.Buffer
-	sphinxPacket, err := sphinx.NewOnionPacket(route, dest,
+	sphinxPacket, err := sphinx.NewOnionPacket(route, sessionKey, dest,
 		e2eMessage)
 	if err != nil {
 		return nil, err
@@ -621,6 +1072,110 @@ func generateSphinxPacket(vertexes []graph.ID) ([]byte, error) {
 	return onionBlob.Bytes(), nil
 }
 
+// ListPayments returns a list of all outgoing payments initiated by this
+// node, along with their current status.
+func (r *rpcServer) ListPayments(ctx context.Context,
+	req *lnrpc.ListPaymentsRequest) (*lnrpc.ListPaymentsResponse, error) {
+
+	dbPayments, err := r.server.chanDB.FetchAllPayments()
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*lnrpc.Payment, len(dbPayments))
+	for i, dbPayment := range dbPayments {
+		path := make([]string, len(dbPayment.Path))
+		for j, hop := range dbPayment.Path {
+			path[j] = hex.EncodeToString(hop[:])
+		}
+
+		payment := &lnrpc.Payment{
+			PaymentHash:   dbPayment.PaymentHash[:],
+			Value:         int64(dbPayment.Amount),
+			Fee:           int64(dbPayment.Fee),
+			Path:          path,
+			FailureReason: dbPayment.FailureReason,
+			CreationDate:  dbPayment.CreationDate.Unix(),
+		}
+
+		switch dbPayment.Status {
+		case channeldb.StatusInFlight:
+			payment.Status = lnrpc.Payment_IN_FLIGHT
+		case channeldb.StatusSucceeded:
+			payment.Status = lnrpc.Payment_SUCCEEDED
+			payment.PaymentPreimage = dbPayment.Preimage[:]
+		case channeldb.StatusFailed:
+			payment.Status = lnrpc.Payment_FAILED
+		}
+
+		payments[i] = payment
+	}
+
+	return &lnrpc.ListPaymentsResponse{
+		Payments: payments,
+	}, nil
+}
+
+// defaultMaxForwardingEvents is the maximum number of forwarding events
+// returned by a single ForwardingHistory call if the client doesn't specify a
+// limit.
+const defaultMaxForwardingEvents = 100
+
+// ForwardingHistory returns a page of the HTLC's forwarded by the daemon
+// within the requested time range, along with the fee earned by each forward.
+// If no end time is specified, then the range ends at the current time. The
+// last_offset_index within the response should be used as the index_offset
+// of the request for the next page.
+func (r *rpcServer) ForwardingHistory(ctx context.Context,
+	req *lnrpc.ForwardingHistoryRequest) (*lnrpc.ForwardingHistoryResponse, error) {
+
+	endTime := time.Now()
+	if req.EndTime != 0 {
+		endTime = time.Unix(int64(req.EndTime), 0)
+	}
+	startTime := time.Unix(int64(req.StartTime), 0)
+	if startTime.After(endTime) {
+		return nil, fmt.Errorf("start time %v is after end time %v",
+			startTime, endTime)
+	}
+
+	numMaxEvents := req.NumMaxEvents
+	if numMaxEvents == 0 {
+		numMaxEvents = defaultMaxForwardingEvents
+	}
+
+	timeSlice, err := r.server.chanDB.QueryForwardingLog(
+		channeldb.ForwardingEventQuery{
+			StartTime:    startTime,
+			EndTime:      endTime,
+			IndexOffset:  req.IndexOffset,
+			NumMaxEvents: numMaxEvents,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &lnrpc.ForwardingHistoryResponse{
+		ForwardingEvents: make([]*lnrpc.ForwardingEvent, 0,
+			len(timeSlice.ForwardingEvents)),
+		LastOffsetIndex: timeSlice.LastIndexOffset,
+	}
+	for _, event := range timeSlice.ForwardingEvents {
+		resp.ForwardingEvents = append(resp.ForwardingEvents,
+			&lnrpc.ForwardingEvent{
+				Timestamp:    uint64(event.Timestamp.Unix()),
+				ChanPointIn:  event.IncomingChanPoint.String(),
+				ChanPointOut: event.OutgoingChanPoint.String(),
+				AmtIn:        int64(event.AmtIn),
+				AmtOut:       int64(event.AmtOut),
+				Fee:          int64(event.Fee()),
+			})
+		resp.TotalFees += int64(event.Fee())
+	}
+
+	return resp, nil
+}
+
 // AddInvoice attempts to add a new invoice to the invoice database. Any
 // duplicated invoices are rejected, therefore all invoices *must* have a
 // unique payment preimage.
@@ -643,8 +1198,16 @@ func (r *rpcServer) AddInvoice(ctx context.Context,
 			"(maxsize=%v)", len(invoice.Receipt), channeldb.MaxReceiptSize)
 	}
 
+	// If an expiry wasn't specified for the invoice, then fall back to
+	// the default.
+	expiry := time.Duration(invoice.Expiry) * time.Second
+	if expiry == 0 {
+		expiry = zpay32.DefaultExpiry
+	}
+
 	i := &channeldb.Invoice{
 		CreationDate: time.Now(),
+		Expiry:       expiry,
 		Memo:         []byte(invoice.Memo),
 		Receipt:      invoice.Receipt,
 		Terms: channeldb.ContractTerm{
@@ -662,12 +1225,34 @@ func (r *rpcServer) AddInvoice(ctx context.Context,
 		return nil, err
 	}
 
+	// With the invoice added, generate the encoded payment request which
+	// can be handed to the payer.
+	payReq, err := r.encodePayReq(i)
+	if err != nil {
+		return nil, err
+	}
+
 	rHash := fastsha256.Sum256(preImage)
 	return &lnrpc.AddInvoiceResponse{
-		RHash: rHash[:],
+		RHash:          rHash[:],
+		PaymentRequest: payReq,
 	}, nil
 }
 
+// encodePayReq encodes a payment request for the passed invoice, payable to
+// our identity public key over the currently active network.
+func (r *rpcServer) encodePayReq(invoice *channeldb.Invoice) (string, error) {
+	return zpay32.Encode(&zpay32.PaymentRequest{
+		Destination:  r.server.identityPriv.PubKey(),
+		PaymentHash:  fastsha256.Sum256(invoice.Terms.PaymentPreimage[:]),
+		Amount:       invoice.Terms.Value,
+		Memo:         string(invoice.Memo),
+		CreationDate: invoice.CreationDate,
+		Expiry:       invoice.Expiry,
+		Net:          activeNetParams.Params,
+	})
+}
+
 // LookupInvoice attemps to look up an invoice according to its payment hash.
 // The passed payment hash *must* be exactly 32 bytes, if not an error is
 // returned.
@@ -694,15 +1279,59 @@ func (r *rpcServer) LookupInvoice(ctx context.Context,
 			return spew.Sdump(invoice)
 		}))
 
+	return r.createRPCInvoice(invoice)
+}
+
+// createRPCInvoice converts the passed invoice into its RPC representation,
+// along with the encoded payment request for the invoice.
+func (r *rpcServer) createRPCInvoice(invoice *channeldb.Invoice) (*lnrpc.Invoice, error) {
+	payReq, err := r.encodePayReq(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	rHash := fastsha256.Sum256(invoice.Terms.PaymentPreimage[:])
 	return &lnrpc.Invoice{
-		Memo:      string(invoice.Memo[:]),
-		Receipt:   invoice.Receipt[:],
-		RPreimage: invoice.Terms.PaymentPreimage[:],
-		Value:     int64(invoice.Terms.Value),
-		Settled:   invoice.Terms.Settled,
+		Memo:           string(invoice.Memo[:]),
+		Receipt:        invoice.Receipt[:],
+		RPreimage:      invoice.Terms.PaymentPreimage[:],
+		RHash:          rHash[:],
+		Value:          int64(invoice.Terms.Value),
+		Settled:        invoice.Terms.State == channeldb.InvoiceSettled,
+		CreationDate:   invoice.CreationDate.Unix(),
+		Expiry:         int64(invoice.Expiry / time.Second),
+		PaymentRequest: payReq,
+		State:          lnrpc.Invoice_InvoiceState(invoice.Terms.State),
+		AddIndex:       invoice.AddIndex,
+		SettleIndex:    invoice.SettleIndex,
+		AmtPaid:        int64(invoice.AmtPaid),
+		AmtRejected:    int64(invoice.AmtRejected),
 	}, nil
 }
 
+// CancelInvoice cancels the invoice identified by the passed payment hash.
+// Once cancelled, any HTLC's paying to the invoice will be rejected. Invoices
+// which have already been settled can't be cancelled.
+func (r *rpcServer) CancelInvoice(ctx context.Context,
+	req *lnrpc.PaymentHash) (*lnrpc.CancelInvoiceResponse, error) {
+
+	if len(req.RHash) != 32 {
+		return nil, fmt.Errorf("payment hash must be exactly "+
+			"32 bytes, is instead %v", len(req.RHash))
+	}
+
+	var payHash wire.ShaHash
+	copy(payHash[:], req.RHash)
+
+	rpcsLog.Debugf("[cancelinvoice] cancelling invoice %x", payHash[:])
+
+	if err := r.server.invoices.CancelInvoice(payHash); err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.CancelInvoiceResponse{}, nil
+}
+
 // ListInvoices returns a list of all the invoices currently stored within the
 // database. Any active debug invoices are ignored.
 func (r *rpcServer) ListInvoices(ctx context.Context,
@@ -715,12 +1344,9 @@ func (r *rpcServer) ListInvoices(ctx context.Context,
 
 	invoices := make([]*lnrpc.Invoice, len(dbInvoices))
 	for i, dbInvoice := range dbInvoices {
-		invoice := &lnrpc.Invoice{
-			Memo:      string(dbInvoice.Memo[:]),
-			Receipt:   dbInvoice.Receipt[:],
-			RPreimage: dbInvoice.Terms.PaymentPreimage[:],
-			Value:     int64(dbInvoice.Terms.Value),
-			Settled:   dbInvoice.Terms.Settled,
+		invoice, err := r.createRPCInvoice(dbInvoice)
+		if err != nil {
+			return nil, err
 		}
 
 		invoices[i] = invoice
@@ -731,6 +1357,65 @@ func (r *rpcServer) ListInvoices(ctx context.Context,
 	}, nil
 }
 
+// SubscribeInvoices returns a uni-directional stream (server -> client) for
+// notifying the client of newly added and settled invoices. If a non-zero add
+// or settle index is specified, then all invoices added or settled after the
+// respective index are sent first, allowing clients to resume from the last
+// invoice event they've seen.
+func (r *rpcServer) SubscribeInvoices(req *lnrpc.InvoiceSubscription,
+	updateStream lnrpc.Lightning_SubscribeInvoicesServer) error {
+
+	invoiceClient, err := r.server.invoices.SubscribeInvoices(req.AddIndex,
+		req.SettleIndex)
+	if err != nil {
+		return err
+	}
+	defer invoiceClient.Cancel()
+
+	for {
+		select {
+		case event := <-invoiceClient.Updates:
+			invoice, err := r.createRPCInvoice(event.invoice)
+			if err != nil {
+				return err
+			}
+
+			if err := updateStream.Send(invoice); err != nil {
+				return err
+			}
+		case <-updateStream.Context().Done():
+			return nil
+		case <-r.quit:
+			return nil
+		}
+	}
+}
+
+// DecodePayReq takes an encoded payment request string and attempts to decode
+// it, returning a full description of the conditions encoded within the
+// payment request.
+func (r *rpcServer) DecodePayReq(ctx context.Context,
+	req *lnrpc.PayReqString) (*lnrpc.PayReq, error) {
+
+	rpcsLog.Tracef("[decodepayreq] decoding: %v", req.PayReq)
+
+	payReq, err := zpay32.Decode(req.PayReq)
+	if err != nil {
+		return nil, err
+	}
+
+	dest := payReq.Destination.SerializeCompressed()
+	return &lnrpc.PayReq{
+		Destination: hex.EncodeToString(dest),
+		PaymentHash: hex.EncodeToString(payReq.PaymentHash[:]),
+		NumSatoshis: int64(payReq.Amount),
+		Memo:        payReq.Memo,
+		Timestamp:   payReq.CreationDate.Unix(),
+		Expiry:      int64(payReq.Expiry / time.Second),
+		Network:     payReq.Net.Name,
+	}, nil
+}
+
 func (r *rpcServer) ShowRoutingTable(ctx context.Context,
 	in *lnrpc.ShowRoutingTableRequest) (*lnrpc.ShowRoutingTableResponse, error) {
 
@@ -7,6 +7,7 @@ import (
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/btcsuite/fastsha256"
 	"github.com/lightningnetwork/lightning-onion"
@@ -15,13 +16,26 @@ import (
 	"github.com/lightningnetwork/lnd/lndc"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
 
 	"github.com/BitfuryLightning/tools/routing"
 	"github.com/BitfuryLightning/tools/rt/graph"
 )
 
+const (
+	// initialReconnectBackoff is the amount of time the server waits
+	// before attempting to re-establish a dropped connection to a peer we
+	// have open channels with.
+	initialReconnectBackoff = time.Second
+
+	// maxReconnectBackoff is the maximum amount of time the server waits
+	// between successive reconnection attempts to a persistent peer.
+	maxReconnectBackoff = time.Hour
+)
+
 // server is the main server of the Lightning Network Daemon. The server
 // houses global state pertianing to the wallet, database, and the rpcserver.
 // Additionally, the server is also used as a central messaging bus to interact
@@ -41,6 +55,22 @@ type server struct {
 	listeners []net.Listener
 	peers     map[int32]*peer
 
+	// dialer is used to open all outbound peer connections. If nil, then
+	// connections are opened directly, otherwise they're routed through
+	// a SOCKS5 proxy.
+	dialer lndc.Dialer
+
+	// persistentPeers is a map of all peers we have open channels with,
+	// indexed by the hex-encoded identity public key of the peer. The
+	// server will attempt to keep a connection to each of these peers
+	// open at all times, redialing the stored address with an
+	// exponential backoff whenever the connection is dropped.
+	// persistentConnReqs tracks the set of persistent peers which
+	// currently have an outstanding reconnection attempt.
+	persistentMtx      sync.Mutex
+	persistentPeers    map[string]*lndc.LNAdr
+	persistentConnReqs map[string]struct{}
+
 	rpcServer *rpcServer
 
 	chainNotifier chainntnfs.ChainNotifier
@@ -48,6 +78,10 @@ type server struct {
 	bio      lnwallet.BlockChainIO
 	lnwallet *lnwallet.LightningWallet
 
+	// feeEstimator is used by all sub-systems which need to determine the
+	// fee to pay for an on-chain transaction.
+	feeEstimator lnwallet.FeeEstimator
+
 	// TODO(roasbeef): add to constructor
 	fundingMgr *fundingManager
 	chanDB     *channeldb.DB
@@ -57,8 +91,15 @@ type server struct {
 
 	routingMgr *routing.RoutingManager
 
+	// policies houses the latest routing policy advertised for each
+	// channel within the network, used to determine the fees and expiry
+	// of outgoing payments.
+	policies *policyTable
+
 	utxoNursery *utxoNursery
 
+	breachArbiter *breachArbiter
+
 	sphinx *sphinx.Router
 
 	newPeers  chan *peer
@@ -73,7 +114,7 @@ type server struct {
 // passed listener address.
 func newServer(listenAddrs []string, notifier chainntnfs.ChainNotifier,
 	bio lnwallet.BlockChainIO, wallet *lnwallet.LightningWallet,
-	chanDB *channeldb.DB) (*server, error) {
+	feeEstimator lnwallet.FeeEstimator, chanDB *channeldb.DB) (*server, error) {
 
 	privKey, err := wallet.GetIdentitykey()
 	if err != nil {
@@ -88,13 +129,22 @@ func newServer(listenAddrs []string, notifier chainntnfs.ChainNotifier,
 		}
 	}
 
+	// If a SOCKS5 proxy has been specified, then all outbound connections
+	// will be routed through it, allowing us to reach onion addresses.
+	var dialer lndc.Dialer
+	if cfg.SOCKSProxy != "" {
+		dialer, err = lndc.NewSOCKS5Dialer(cfg.SOCKSProxy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	serializedPubKey := privKey.PubKey().SerializeCompressed()
 	s := &server{
 		bio:           bio,
 		chainNotifier: notifier,
 		chanDB:        chanDB,
-		fundingMgr:    newFundingManager(wallet),
-		invoices:      newInvoiceRegistry(chanDB),
+		feeEstimator:  feeEstimator,
 		lnwallet:      wallet,
 		identityPriv:  privKey,
 		// TODO(roasbeef): derive proper onion key based on rotation
@@ -102,12 +152,39 @@ func newServer(listenAddrs []string, notifier chainntnfs.ChainNotifier,
 		sphinx:      sphinx.NewRouter(privKey, activeNetParams.Params),
 		lightningID: fastsha256.Sum256(serializedPubKey),
 		listeners:   listeners,
+		dialer:      dialer,
 		peers:       make(map[int32]*peer),
-		newPeers:    make(chan *peer, 100),
-		donePeers:   make(chan *peer, 100),
-		queries:     make(chan interface{}),
-		quit:        make(chan struct{}),
+
+		persistentPeers:    make(map[string]*lndc.LNAdr),
+		persistentConnReqs: make(map[string]struct{}),
+
+		policies: newPolicyTable(),
+
+		newPeers:  make(chan *peer, 100),
+		donePeers: make(chan *peer, 100),
+		queries:   make(chan interface{}),
+		quit:      make(chan struct{}),
+	}
+
+	s.fundingMgr = newFundingManager(s, wallet, feeEstimator)
+
+	s.utxoNursery = newUtxoNursery(chanDB, notifier, wallet, feeEstimator)
+
+	// Create a new routing manager with ourself as the sole node within
+	// the graph.
+	selfVertex := hex.EncodeToString(serializedPubKey)
+	s.routingMgr = routing.NewRoutingManager(graph.NewID(selfVertex), nil)
+	defaultPolicy := &channeldb.ChannelPolicy{
+		BaseFee:       btcutil.Amount(cfg.BaseFee),
+		FeeRate:       cfg.FeeRate,
+		MinHTLC:       btcutil.Amount(cfg.MinHTLC),
+		TimeLockDelta: cfg.TimeLockDelta,
 	}
+	s.htlcSwitch = newHtlcSwitch(serializedPubKey, s.routingMgr, chanDB,
+		defaultPolicy)
+
+	s.invoices = newInvoiceRegistry(chanDB, s.htlcSwitch,
+		cfg.OverpaymentTolerance)
 
 	// If the debug HTLC flag is on, then we invoice a "master debug"
 	// invoice which all outgoing payments will be sent and all incoming
@@ -119,13 +196,8 @@ func newServer(listenAddrs []string, notifier chainntnfs.ChainNotifier,
 			debugPre[:], debugHash[:])
 	}
 
-	s.utxoNursery = newUtxoNursery(notifier, wallet)
-
-	// Create a new routing manager with ourself as the sole node within
-	// the graph.
-	selfVertex := hex.EncodeToString(serializedPubKey)
-	s.routingMgr = routing.NewRoutingManager(graph.NewID(selfVertex), nil)
-	s.htlcSwitch = newHtlcSwitch(serializedPubKey, s.routingMgr)
+	s.breachArbiter = newBreachArbiter(wallet, bio, chanDB, notifier,
+		s.htlcSwitch, s.feeEstimator)
 
 	s.rpcServer = newRpcServer(s)
 
@@ -154,6 +226,9 @@ func (s *server) Start() error {
 	if err := s.chainNotifier.Start(); err != nil {
 		return err
 	}
+	if err := s.feeEstimator.Start(); err != nil {
+		return err
+	}
 
 	if err := s.rpcServer.Start(); err != nil {
 		return err
@@ -167,11 +242,24 @@ func (s *server) Start() error {
 	if err := s.utxoNursery.Start(); err != nil {
 		return err
 	}
+	if err := s.breachArbiter.Start(); err != nil {
+		return err
+	}
+	if err := s.invoices.Start(); err != nil {
+		return err
+	}
 	s.routingMgr.Start()
 
 	s.wg.Add(1)
 	go s.queryHandler()
 
+	// With all sub-systems running, attempt to re-establish a connection
+	// to each peer we have open channels with so the channels can resume
+	// normal operation.
+	if err := s.establishPersistentConnections(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -196,8 +284,14 @@ func (s *server) Stop() error {
 	s.rpcServer.Stop()
 	s.fundingMgr.Stop()
 	s.routingMgr.Stop()
+
+	// The invoice registry is stopped before the htlcSwitch, as it
+	// cancels any held payment parts over their links.
+	s.invoices.Stop()
 	s.htlcSwitch.Stop()
 	s.utxoNursery.Stop()
+	s.breachArbiter.Stop()
+	s.feeEstimator.Stop()
 
 	s.lnwallet.Shutdown()
 
@@ -227,6 +321,21 @@ func (s *server) addPeer(p *peer) {
 	}
 
 	s.peers[p.id] = p
+
+	// If the peer requested an initial routing sync within its Init
+	// message, then synchronize its view of the routing policies within
+	// the network by sending it every policy we know of.
+	if !p.remoteLocalFeatures.HasFeature(lnwire.InitialRoutingSyncOptional) {
+		return
+	}
+	updates := s.policies.allUpdates()
+	if len(updates) > 0 {
+		go func() {
+			for _, update := range updates {
+				p.queueMsg(update, nil)
+			}
+		}()
+	}
 }
 
 // removePeer removes the passed peer from the server's state of all active
@@ -245,6 +354,188 @@ func (s *server) removePeer(p *peer) {
 	}
 
 	delete(s.peers, p.id)
+
+	// If this peer is one which we have open channels with, then launch a
+	// goroutine to re-establish the connection so the channels don't go
+	// dark.
+	pubStr := hex.EncodeToString(p.identityPub.SerializeCompressed())
+	s.persistentMtx.Lock()
+	addr, ok := s.persistentPeers[pubStr]
+	s.persistentMtx.Unlock()
+	if ok {
+		srvrLog.Infof("Lost connection to persistent peer %v, "+
+			"reconnecting", addr)
+		s.connectToPersistentPeer(addr, initialReconnectBackoff)
+	}
+}
+
+// establishPersistentConnections reads the stored addresses of all peers we
+// have open channels with from the database, then launches a goroutine to
+// connect to each of them.
+func (s *server) establishPersistentConnections() error {
+	peerAddrs, err := s.chanDB.FetchAllPeerAddrs()
+	if err != nil {
+		return err
+	}
+
+	for _, peerAddr := range peerAddrs {
+		// The stored address is only resolved now, and left for the
+		// proxy to resolve if we're connecting through one.
+		netAddr, err := lndc.ResolveAddr(peerAddr.Address,
+			s.dialer != nil)
+		if err != nil {
+			srvrLog.Errorf("unable to resolve address %v of "+
+				"persistent peer: %v", peerAddr.Address, err)
+			continue
+		}
+		lnAddr, err := lndc.NewLnAdr(netAddr, peerAddr.IdentityPub,
+			activeNetParams.Params)
+		if err != nil {
+			return err
+		}
+
+		pubStr := hex.EncodeToString(lnAddr.PubKey.SerializeCompressed())
+		s.persistentMtx.Lock()
+		s.persistentPeers[pubStr] = lnAddr
+		s.persistentMtx.Unlock()
+
+		srvrLog.Debugf("Connecting to persistent peer %v", lnAddr)
+		s.connectToPersistentPeer(lnAddr, 0)
+	}
+
+	return nil
+}
+
+// addPersistentPeer marks the peer reachable at the passed address as a
+// persistent peer, writing the address to disk so the connection can be
+// re-established after a restart or a dropped connection.
+func (s *server) addPersistentPeer(addr *lndc.LNAdr) error {
+	peerAddr := &channeldb.PeerAddr{
+		IdentityPub: addr.PubKey,
+		Address:     addr.NetAddr.String(),
+	}
+	if err := s.chanDB.PutPeerAddr(peerAddr); err != nil {
+		return err
+	}
+
+	pubStr := hex.EncodeToString(addr.PubKey.SerializeCompressed())
+	s.persistentMtx.Lock()
+	s.persistentPeers[pubStr] = addr
+	s.persistentMtx.Unlock()
+
+	return nil
+}
+
+// removePersistentPeer removes the peer identified by the passed public key
+// from the set of persistent peers, and deletes its stored address.
+func (s *server) removePersistentPeer(pub *btcec.PublicKey) error {
+	pubStr := hex.EncodeToString(pub.SerializeCompressed())
+	s.persistentMtx.Lock()
+	delete(s.persistentPeers, pubStr)
+	s.persistentMtx.Unlock()
+
+	return s.chanDB.DeletePeerAddr(pub)
+}
+
+// connectToPersistentPeer launches a goroutine which attempts to connect to
+// the persistent peer at the passed address after the specified delay. If a
+// reconnection attempt is already outstanding for the peer, then this
+// function is a noop.
+func (s *server) connectToPersistentPeer(addr *lndc.LNAdr, delay time.Duration) {
+	pubStr := hex.EncodeToString(addr.PubKey.SerializeCompressed())
+
+	s.persistentMtx.Lock()
+	if _, ok := s.persistentConnReqs[pubStr]; ok {
+		s.persistentMtx.Unlock()
+		return
+	}
+	s.persistentConnReqs[pubStr] = struct{}{}
+	s.persistentMtx.Unlock()
+
+	s.wg.Add(1)
+	go s.persistentConnHandler(addr, delay)
+}
+
+// persistentConnHandler repeatedly attempts to connect to the peer at the
+// passed address until either a connection is established, we no longer have
+// any open channels with the peer, or the server is shutting down. After each
+// failed attempt, the delay before the next attempt is doubled, up to a
+// maximum of maxReconnectBackoff.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *server) persistentConnHandler(addr *lndc.LNAdr, delay time.Duration) {
+	pubBytes := addr.PubKey.SerializeCompressed()
+	pubStr := hex.EncodeToString(pubBytes)
+	nodeID := wire.ShaHash(fastsha256.Sum256(pubBytes))
+
+	defer func() {
+		s.persistentMtx.Lock()
+		delete(s.persistentConnReqs, pubStr)
+		s.persistentMtx.Unlock()
+
+		s.wg.Done()
+	}()
+
+	backoff := initialReconnectBackoff
+	for {
+		select {
+		case <-time.After(delay):
+		case <-s.quit:
+			return
+		}
+
+		// If all our channels with this peer have been closed since
+		// the connection was lost, then there's no need to reconnect.
+		openChans, err := s.chanDB.FetchOpenChannels(&nodeID)
+		if err != nil {
+			srvrLog.Errorf("unable to fetch channels for peer %v: %v",
+				addr, err)
+		} else if len(openChans) == 0 {
+			srvrLog.Infof("No open channels remain with peer %v, "+
+				"no longer reconnecting", addr)
+			if err := s.removePersistentPeer(addr.PubKey); err != nil {
+				srvrLog.Errorf("unable to remove persistent "+
+					"peer %v: %v", addr, err)
+			}
+			return
+		}
+
+		// Send the connection request to the queryHandler, marking
+		// it as persistent so an already established connection to
+		// this peer is treated as a success.
+		respChan := make(chan int32, 1)
+		errChan := make(chan error, 1)
+		req := &connectPeerMsg{
+			addr:       addr,
+			persistent: true,
+			resp:       respChan,
+			err:        errChan,
+		}
+		select {
+		case s.queries <- req:
+		case <-s.quit:
+			return
+		}
+
+		select {
+		case err = <-errChan:
+		case <-s.quit:
+			return
+		}
+		if err == nil {
+			srvrLog.Infof("Connected to persistent peer %v", addr)
+			return
+		}
+
+		srvrLog.Errorf("unable to connect to persistent peer %v: %v, "+
+			"retrying in %v", addr, err, backoff)
+
+		delay = backoff
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
 }
 
 // connectPeerMsg is a message requesting the server to open a connection to a
@@ -252,6 +543,12 @@ func (s *server) removePeer(p *peer) {
 // used to report success/failure.
 type connectPeerMsg struct {
 	addr *lndc.LNAdr
+
+	// persistent indicates that this request was issued in order to
+	// re-establish a connection to a peer we have open channels with. In
+	// this case, an existing connection to the peer isn't an error.
+	persistent bool
+
 	resp chan int32
 	err  chan error
 }
@@ -282,6 +579,14 @@ type openChanReq struct {
 	err     chan error
 }
 
+// broadcastReq is a message sent to the server in order to send a message to
+// all connected peers, with the exception of the peer the message was
+// received from, if any.
+type broadcastReq struct {
+	msg  lnwire.Message
+	skip *peer
+}
+
 // queryHandler handles any requests to modify the server's internal state of
 // all active peers, or query/mutate the server's global state. Additionally,
 // any queries directed at peers will be handled by this goroutine.
@@ -308,6 +613,8 @@ out:
 				s.handleListPeers(msg)
 			case *openChanReq:
 				s.handleOpenChanReq(msg)
+			case *broadcastReq:
+				s.handleBroadcast(msg)
 			}
 		case msg := <-s.routingMgr.ChOut:
 			msg1 := msg.(*routing.RoutingMessage)
@@ -343,6 +650,68 @@ out:
 	s.wg.Done()
 }
 
+// handleBroadcast sends the message enclosed within the passed broadcastReq to
+// all connected peers, skipping the peer the message was received from.
+func (s *server) handleBroadcast(req *broadcastReq) {
+	for _, peer := range s.peers {
+		if peer == req.skip {
+			continue
+		}
+
+		peer.queueMsg(req.msg, nil)
+	}
+}
+
+// announceChannelPolicy advertises the passed routing policy, enforced by us
+// when forwarding HTLC's over the target channel to the remote peer, to all
+// connected peers.
+func (s *server) announceChannelPolicy(chanPoint *wire.OutPoint,
+	remotePub *btcec.PublicKey, policy *channeldb.ChannelPolicy) {
+
+	selfPub := s.identityPriv.PubKey()
+	update := &lnwire.ChannelPolicyUpdate{
+		ChannelPoint:  chanPoint,
+		NodeID:        selfPub,
+		PeerID:        remotePub,
+		UpdateIndex:   s.policies.nextUpdateIndex(chanPoint, selfPub),
+		BaseFee:       policy.BaseFee,
+		FeeRate:       policy.FeeRate,
+		MinHTLC:       policy.MinHTLC,
+		TimeLockDelta: policy.TimeLockDelta,
+	}
+	s.policies.processUpdate(update)
+
+	// The broadcast is handed to the queryHandler from within a distinct
+	// goroutine, as policies may be announced while the queryHandler is
+	// itself busy adding the peer which owns the channel.
+	go s.broadcastMessage(update, nil)
+}
+
+// processPolicyUpdate handles a ChannelPolicyUpdate received from the passed
+// peer. If the update supersedes the policy we previously knew of for the
+// channel, then it's relayed to all our other peers.
+func (s *server) processPolicyUpdate(update *lnwire.ChannelPolicyUpdate,
+	p *peer) {
+
+	if !s.policies.processUpdate(update) {
+		return
+	}
+
+	srvrLog.Debugf("Received policy of ChannelPoint(%v) from %v, "+
+		"relaying", update.ChannelPoint, p)
+
+	s.broadcastMessage(update, p)
+}
+
+// broadcastMessage sends the passed message to all connected peers, with the
+// exception of the skip peer, if non-nil.
+func (s *server) broadcastMessage(msg lnwire.Message, skip *peer) {
+	select {
+	case s.queries <- &broadcastReq{msg: msg, skip: skip}:
+	case <-s.quit:
+	}
+}
+
 // handleListPeers sends a lice of all currently active peers to the original
 // caller.
 func (s *server) handleListPeers(msg *listPeersMsg) {
@@ -363,6 +732,16 @@ func (s *server) handleConnectPeer(msg *connectPeerMsg) {
 	// Ensure we're not already connected to this
 	// peer.
 	for _, peer := range s.peers {
+		// If this is a reconnection attempt to a persistent peer,
+		// then the peer may have already connected to us, either at
+		// the same address or from a different one. In this case, the
+		// request has been satisfied.
+		if msg.persistent && peer.identityPub.IsEqual(addr.PubKey) {
+			msg.err <- nil
+			msg.resp <- peer.id
+			return
+		}
+
 		if peer.lightningAddr.String() == addr.String() {
 			msg.err <- fmt.Errorf(
 				"already connected to peer: %v",
@@ -378,15 +757,16 @@ func (s *server) handleConnectPeer(msg *connectPeerMsg) {
 	// TODO(roasbeef): semaphore to limit the number of goroutines for
 	// async requests.
 	go func() {
-		// For the lndc crypto handshake, we
-		// either need a compressed pubkey, or a
-		// 20-byte pkh.
-		var remoteId []byte
+		// The lndc handshake requires that we know the static
+		// public key of the remote node ahead of time, so
+		// connecting to a bare pkh isn't possible.
 		if addr.PubKey == nil {
-			remoteId = addr.Base58Adr.ScriptAddress()
-		} else {
-			remoteId = addr.PubKey.SerializeCompressed()
+			msg.err <- fmt.Errorf("the public key of the remote " +
+				"node is required to connect")
+			msg.resp <- -1
+			return
 		}
+		remoteId := addr.PubKey.SerializeCompressed()
 
 		srvrLog.Debugf("connecting to %v", hex.EncodeToString(remoteId))
 		// Attempt to connect to the remote
@@ -395,7 +775,7 @@ func (s *server) handleConnectPeer(msg *connectPeerMsg) {
 		// breaks down, then return an error to the
 		// caller.
 		ipAddr := addr.NetAddr.String()
-		conn := lndc.NewConn(nil)
+		conn := lndc.NewConnWithDialer(s.dialer)
 		if err := conn.Dial(
 			s.identityPriv, ipAddr, remoteId); err != nil {
 			msg.err <- err
@@ -415,7 +795,30 @@ func (s *server) handleConnectPeer(msg *connectPeerMsg) {
 			return
 		}
 
-		peer.Start()
+		// Exchange Init messages with the peer, disconnecting if
+		// they require a feature we don't understand. As the peer was
+		// never added to the set of active peers, it's stopped
+		// directly rather than being handed back to the server.
+		if err := peer.Start(); err != nil {
+			srvrLog.Errorf("unable to start peer %v: %v", peer, err)
+			peer.Stop()
+			msg.resp <- -1
+			msg.err <- err
+			return
+		}
+
+		// If we have any open channels with this peer, then record
+		// the address we reached them at so the connection can be
+		// re-established automatically in the future. The peer's
+		// channels are owned by its channelManager, so we query them
+		// through it rather than reading the map directly.
+		if len(peer.ChannelSnapshots()) > 0 {
+			if err := s.addPersistentPeer(peer.lightningAddr); err != nil {
+				srvrLog.Errorf("unable to persist address of "+
+					"peer %v: %v", peer, err)
+			}
+		}
+
 		s.newPeers <- peer
 
 		msg.resp <- peer.id
@@ -460,7 +863,7 @@ func (s *server) ConnectToPeer(addr *lndc.LNAdr) (int32, error) {
 	reply := make(chan int32, 1)
 	errChan := make(chan error, 1)
 
-	s.queries <- &connectPeerMsg{addr, reply, errChan}
+	s.queries <- &connectPeerMsg{addr: addr, resp: reply, err: errChan}
 
 	return <-reply, <-errChan
 }
@@ -522,8 +925,23 @@ func (s *server) listener(l net.Listener) {
 			continue
 		}
 
-		peer.Start()
-		s.newPeers <- peer
+		// Exchanging Init messages may take some time, so the peer is
+		// started within a goroutine to avoid stalling other inbound
+		// connections. A peer which fails to start was never added to
+		// the set of active peers, so it's stopped directly.
+		go func() {
+			if err := peer.Start(); err != nil {
+				srvrLog.Errorf("unable to start peer %v: %v",
+					peer, err)
+				peer.Stop()
+				return
+			}
+
+			select {
+			case s.newPeers <- peer:
+			case <-s.quit:
+			}
+		}()
 	}
 
 	s.wg.Done()
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
 	"sync"
+	"sync/atomic"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/chainntnfs"
@@ -12,6 +17,23 @@ import (
 	"github.com/roasbeef/btcutil"
 )
 
+const (
+	// sweepConfTarget is the number of blocks within which we'd like a
+	// sweep transaction to confirm.
+	sweepConfTarget = 6
+
+	// sweepTxBaseSize is the estimated size, in virtual bytes, of a sweep
+	// transaction without any inputs. This includes the version, lock
+	// time, input and output counts, and the single p2wkh output.
+	sweepTxBaseSize = 4 + 4 + 1 + 1 + 31
+
+	// sweepInputSize is the estimated size, in virtual bytes, of each
+	// time-locked input of a sweep transaction. This includes the
+	// outpoint, sequence, and empty sigScript, along with the discounted
+	// witness: a signature, a selector, and the witness script.
+	sweepInputSize = 32 + 4 + 4 + 1 + (1+73+1+1+140)/4
+)
+
 // utxoNursery is a system dedicated to incubating time-locked outputs created
 // by the broadcast of a commitment transaction either by us, or the remote
 // peer. The nursery accepts outputs and "incubates" them until they've reached
@@ -20,17 +42,23 @@ import (
 // passed. As outputs reach their maturity age, they're sweeped in batches into
 // the source wallet, returning the outputs so they can be used within future
 // channels, or regular Bitcoin transactions.
+//
+// All outputs are persisted within the database until they've been swept, so
+// the incubation of outputs resumes where it left off after a restart.
 type utxoNursery struct {
 	sync.RWMutex
 
 	notifier chainntnfs.ChainNotifier
 	wallet   *lnwallet.LightningWallet
 
-	db channeldb.DB
+	// feeEstimator is used to determine the fee paid by each sweep
+	// transaction.
+	feeEstimator lnwallet.FeeEstimator
+
+	db *channeldb.DB
 
 	requests chan *incubationRequest
 
-	// TODO(roasbeef): persist to disk afterwards
 	unstagedOutputs map[wire.OutPoint]*immatureOutput
 	stagedOutputs   map[uint32][]*immatureOutput
 
@@ -42,12 +70,15 @@ type utxoNursery struct {
 
 // newUtxoNursery creates a new instance of the utxoNursery from a
 // ChainNotifier and LightningWallet instance.
-func newUtxoNursery(notifier chainntnfs.ChainNotifier,
-	wallet *lnwallet.LightningWallet) *utxoNursery {
+func newUtxoNursery(db *channeldb.DB, notifier chainntnfs.ChainNotifier,
+	wallet *lnwallet.LightningWallet,
+	feeEstimator lnwallet.FeeEstimator) *utxoNursery {
 
 	return &utxoNursery{
 		notifier:        notifier,
 		wallet:          wallet,
+		feeEstimator:    feeEstimator,
+		db:              db,
 		requests:        make(chan *incubationRequest),
 		unstagedOutputs: make(map[wire.OutPoint]*immatureOutput),
 		stagedOutputs:   make(map[uint32][]*immatureOutput),
@@ -56,10 +87,53 @@ func newUtxoNursery(notifier chainntnfs.ChainNotifier,
 }
 
 // Start launches all goroutines the utxoNursery needs to properly carry out
-// its duties.
+// its duties. Any outputs which were being incubated before the daemon was
+// last shutdown are read from disk, and their incubation resumed.
 func (u *utxoNursery) Start() error {
+	if !atomic.CompareAndSwapUint32(&u.started, 0, 1) {
+		return nil
+	}
+
+	utxnLog.Tracef("Starting UTXO nursery")
+
+	outputBlobs, err := u.db.FetchNurseryOutputs()
+	if err != nil {
+		return err
+	}
+
+	// Outputs whose generating transaction has already been confirmed are
+	// placed directly into the mid-stage, while the remaining outputs need
+	// to wait for the confirmation of their generating transaction once
+	// again.
+	var earlyStagers []*immatureOutput
+	for _, outputBlob := range outputBlobs {
+		output, err := deserializeImmatureOutput(bytes.NewReader(outputBlob))
+		if err != nil {
+			return err
+		}
+		output.witnessFunc = output.witnessType.GenWitnessFunc(
+			u.wallet.Signer, output.signDesc)
+
+		if output.confHeight == 0 {
+			earlyStagers = append(earlyStagers, output)
+			continue
+		}
+
+		maturityHeight := output.maturityHeight()
+		u.stagedOutputs[maturityHeight] = append(
+			u.stagedOutputs[maturityHeight], output)
+
+		utxnLog.Infof("Outpoint %v restored as mid-stage, will mature "+
+			"at height %v", output.outPoint, maturityHeight)
+	}
+
+	if len(outputBlobs) > 0 {
+		utxnLog.Infof("Restored %v incubating outputs from disk "+
+			"(%v early stage)", len(outputBlobs), len(earlyStagers))
+	}
+
 	u.wg.Add(1)
-	go u.incubator()
+	go u.incubator(earlyStagers)
 
 	return nil
 }
@@ -67,6 +141,12 @@ func (u *utxoNursery) Start() error {
 // Stop gracefully shutsdown any lingering goroutines launched during normal
 // operation of the utxoNursery.
 func (u *utxoNursery) Stop() error {
+	if !atomic.CompareAndSwapUint32(&u.stopped, 0, 1) {
+		return nil
+	}
+
+	utxnLog.Infof("UTXO nursery shutting down")
+
 	close(u.quit)
 	u.wg.Wait()
 	return nil
@@ -81,18 +161,32 @@ func (u *utxoNursery) Stop() error {
 // to the mid stage wherein a dedicated goroutine waits until it has reached
 // "maturity". Once an output is mature, it will be sweeped into the wallet at
 // the earlier possible height.
-func (u *utxoNursery) incubator() {
+//
+// The passed set of early stage outputs are those which were restored from
+// disk, and are still awaiting the confirmation of their generating
+// transaction.
+func (u *utxoNursery) incubator(restoredOutputs []*immatureOutput) {
+	defer u.wg.Done()
+
 	// Register with the notifier to receive notifications for each newly
 	// connected block.
 	newBlocks, err := u.notifier.RegisterBlockEpochNtfn()
 	if err != nil {
 		utxnLog.Errorf("unable to register for block epoch "+
 			"notifications: %v", err)
+		return
 	}
 
 	// Outputs that are transitioning from early to mid-stage are sent over
 	// this channel by each output's dedicated watcher goroutine.
 	midStageOutputs := make(chan *immatureOutput)
+
+	// Resume watching for the confirmation of any early stage outputs
+	// restored from disk.
+	for _, immatureUtxo := range restoredOutputs {
+		u.watchConfirmation(immatureUtxo, midStageOutputs)
+	}
+
 out:
 	for {
 		select {
@@ -101,38 +195,7 @@ out:
 				len(earlyStagers.outputs))
 
 			for _, immatureUtxo := range earlyStagers.outputs {
-				outpoint := immatureUtxo.outPoint
-				sourceTXID := outpoint.Hash
-
-				// Register for a confirmation once the
-				// generating txn has been confirmed.
-				confChan, err := u.notifier.RegisterConfirmationsNtfn(&sourceTXID, 1)
-				if err != nil {
-					utxnLog.Errorf("unable to register for confirmations "+
-						"for txid: %v", sourceTXID)
-					continue
-				}
-
-				// TODO(roasbeef): should be an on-disk
-				// at-least-once task queue
-				u.unstagedOutputs[outpoint] = immatureUtxo
-
-				// Launch a dedicated goroutine which will send
-				// the output back to the incubator once the
-				// source txn has been confirmed.
-				go func() {
-					confHeight, ok := <-confChan.Confirmed
-					if !ok {
-						utxnLog.Errorf("notification chan "+
-							"closed, can't advance output %v", outpoint)
-					}
-
-					utxnLog.Infof("Outpoint %v confirmed in "+
-						"block %v moving to mid-stage",
-						outpoint, confHeight)
-					immatureUtxo.confHeight = uint32(confHeight)
-					midStageOutputs <- immatureUtxo
-				}()
+				u.watchConfirmation(immatureUtxo, midStageOutputs)
 			}
 		case midUtxo := <-midStageOutputs:
 			// The transaction creating the output has been
@@ -140,20 +203,46 @@ out:
 			// mid-stage.
 			delete(u.unstagedOutputs, midUtxo.outPoint)
 
-			// TODO(roasbeef): your off-by-one sense are tingling...
-			maturityHeight := midUtxo.confHeight + midUtxo.blocksToMaturity
+			// Record the confirmation height of the output on
+			// disk, so the output can be placed directly into the
+			// mid-stage after a restart.
+			if err := u.persistOutput(midUtxo); err != nil {
+				utxnLog.Errorf("unable to persist output %v: %v",
+					midUtxo.outPoint, err)
+			}
+
+			maturityHeight := midUtxo.maturityHeight()
 			u.stagedOutputs[maturityHeight] = append(u.stagedOutputs[maturityHeight], midUtxo)
 
 			utxnLog.Infof("Outpoint %v now mid-stage, will mature "+
 				"at height %v (delay of %v)", midUtxo.outPoint,
 				maturityHeight, midUtxo.blocksToMaturity)
-		case epoch := <-newBlocks.Epochs:
+		case epoch, ok := <-newBlocks.Epochs:
+			// If the notifier is shutting down, then the epoch
+			// channel will be closed.
+			if !ok {
+				break out
+			}
+
 			// A new block has just been connected, check to see if
 			// we have any new outputs that can be swept into the
-			// wallet.
+			// wallet. Outputs which matured while the daemon was
+			// offline are swept along side those maturing at this
+			// height.
 			newHeight := uint32(epoch.Height)
-			matureOutputs, ok := u.stagedOutputs[newHeight]
-			if !ok {
+			var (
+				matureOutputs []*immatureOutput
+				matureHeights []uint32
+			)
+			for height, outputs := range u.stagedOutputs {
+				if height > newHeight {
+					continue
+				}
+
+				matureOutputs = append(matureOutputs, outputs...)
+				matureHeights = append(matureHeights, height)
+			}
+			if len(matureOutputs) == 0 {
 				continue
 			}
 
@@ -189,13 +278,75 @@ out:
 					err, spew.Sdump(sweepTx))
 				continue
 			}
-			delete(u.stagedOutputs, newHeight)
+
+			sweptOutpoints := make([]*wire.OutPoint, len(matureOutputs))
+			for i, output := range matureOutputs {
+				sweptOutpoints[i] = &output.outPoint
+			}
+			if err := u.db.DeleteNurseryOutputs(sweptOutpoints); err != nil {
+				utxnLog.Errorf("unable to remove swept outputs "+
+					"from disk: %v", err)
+			}
+
+			for _, height := range matureHeights {
+				delete(u.stagedOutputs, height)
+			}
 		case <-u.quit:
 			break out
 		}
 	}
+}
+
+// watchConfirmation registers for a notification once the transaction which
+// created the passed output has been confirmed. Once confirmed, the output is
+// sent over the passed channel in order to be moved to the mid-stage.
+func (u *utxoNursery) watchConfirmation(immatureUtxo *immatureOutput,
+	midStageOutputs chan<- *immatureOutput) {
+
+	outpoint := immatureUtxo.outPoint
+	sourceTXID := outpoint.Hash
 
-	u.wg.Done()
+	// Register for a confirmation once the generating txn has been
+	// confirmed.
+	confChan, err := u.notifier.RegisterConfirmationsNtfn(&sourceTXID, 1)
+	if err != nil {
+		utxnLog.Errorf("unable to register for confirmations "+
+			"for txid: %v", sourceTXID)
+		return
+	}
+
+	u.unstagedOutputs[outpoint] = immatureUtxo
+
+	// Launch a dedicated goroutine which will send the output back to the
+	// incubator once the source txn has been confirmed.
+	go func() {
+		confHeight, ok := <-confChan.Confirmed
+		if !ok {
+			utxnLog.Errorf("notification chan "+
+				"closed, can't advance output %v", outpoint)
+			return
+		}
+
+		utxnLog.Infof("Outpoint %v confirmed in "+
+			"block %v moving to mid-stage",
+			outpoint, confHeight)
+		immatureUtxo.confHeight = uint32(confHeight)
+
+		select {
+		case midStageOutputs <- immatureUtxo:
+		case <-u.quit:
+		}
+	}()
+}
+
+// persistOutput writes the current state of the passed output to disk.
+func (u *utxoNursery) persistOutput(output *immatureOutput) error {
+	var b bytes.Buffer
+	if err := serializeImmatureOutput(&b, output); err != nil {
+		return err
+	}
+
+	return u.db.PutNurseryOutput(&output.outPoint, b.Bytes())
 }
 
 // createSweepTx creates a final sweeping transaction with all witnesses
@@ -216,11 +367,24 @@ func (u *utxoNursery) createSweepTx(matureOutputs []*immatureOutput) (*wire.MsgT
 		totalSum += o.amt
 	}
 
+	// Using the estimated size of the sweep transaction, compute the fee
+	// required for the transaction to confirm in a timely manner.
+	feePerByte, err := u.feeEstimator.EstimateFeePerByte(sweepConfTarget)
+	if err != nil {
+		return nil, err
+	}
+	txSize := sweepTxBaseSize + sweepInputSize*len(matureOutputs)
+	txFee := feePerByte * btcutil.Amount(txSize)
+	if txFee >= totalSum {
+		return nil, fmt.Errorf("sweep fee of %v exceeds the total value "+
+			"of the swept outputs (%v)", txFee, totalSum)
+	}
+
 	sweepTx := wire.NewMsgTx()
 	sweepTx.Version = 2
 	sweepTx.AddTxOut(&wire.TxOut{
 		PkScript: pkScript,
-		Value:    int64(totalSum - 1000),
+		Value:    int64(totalSum - txFee),
 	})
 	for _, utxo := range matureOutputs {
 		sweepTx.AddTxIn(&wire.TxIn{
@@ -228,10 +392,14 @@ func (u *utxoNursery) createSweepTx(matureOutputs []*immatureOutput) (*wire.MsgT
 			// TODO(roasbeef): assumes pure block delays
 			Sequence: utxo.blocksToMaturity,
 		})
-	}
 
-	// TODO(roasbeef): insert fee calculation
-	//  * remove hardcoded fee above
+		// If any of the outputs are encumbered by an absolute
+		// time-lock, then the lock time of the sweep transaction must
+		// be at least the largest of those time-locks.
+		if utxo.absoluteMaturity > sweepTx.LockTime {
+			sweepTx.LockTime = utxo.absoluteMaturity
+		}
+	}
 
 	// With all the inputs in place, use each output's unique witness
 	// function to generate the final witness required for spending.
@@ -248,26 +416,128 @@ func (u *utxoNursery) createSweepTx(matureOutputs []*immatureOutput) (*wire.MsgT
 	return sweepTx, nil
 }
 
-// witnessFunc represents a function which is able to generate the final
-// witness for a particular public key script. This function acts as an
-// abstraction layer, hidiing the details of the underlying script from the
-// utxoNursery.
-type witnessGenerator func(tx *wire.MsgTx, hc *txscript.TxSigHashes, inputIndex int) ([][]byte, error)
-
 // immatureOutput encapsulates an immature output. The struct includes a
-// witnessGenerator closure which will be used to generate the witness required
-// to sweep the output once it's mature.
-// TODO(roasbeef): make into interface?  can't gob functions
+// WitnessGenerator closure which will be used to generate the witness required
+// to sweep the output once it's mature. As the closure itself can't be written
+// to disk, the witness type and sign descriptor used to re-create the closure
+// are persisted instead.
 type immatureOutput struct {
 	amt      btcutil.Amount
 	outPoint wire.OutPoint
 
-	witnessFunc witnessGenerator
+	witnessType lnwallet.WitnessType
+	signDesc    *lnwallet.SignDescriptor
+
+	witnessFunc lnwallet.WitnessGenerator
 
 	// TODO(roasbeef): using block timeouts everywhere currently, will need
 	// to modify logic later to account for MTP based timeouts.
 	blocksToMaturity uint32
 	confHeight       uint32
+
+	// absoluteMaturity is the absolute block height before which the
+	// output can't be swept. A value of zero indicates that the output is
+	// only encumbered by a relative time-lock.
+	absoluteMaturity uint32
+}
+
+// maturityHeight returns the height at which the output can be swept. This
+// value is only meaningful once the transaction creating the output has been
+// confirmed.
+func (o *immatureOutput) maturityHeight() uint32 {
+	// TODO(roasbeef): your off-by-one sense are tingling...
+	maturityHeight := o.confHeight + o.blocksToMaturity
+
+	// If the output is also encumbered by an absolute time-lock (such as
+	// an HTLC), then it can't be swept until that height has also been
+	// reached.
+	if o.absoluteMaturity > maturityHeight {
+		maturityHeight = o.absoluteMaturity
+	}
+
+	return maturityHeight
+}
+
+// serializeImmatureOutput writes the passed immatureOutput to the target
+// io.Writer. The witness generation closure is not serialized, and should be
+// re-created from the output's witness type and sign descriptor.
+func serializeImmatureOutput(w io.Writer, o *immatureOutput) error {
+	var scratch [8]byte
+
+	binary.BigEndian.PutUint64(scratch[:], uint64(o.amt))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(o.outPoint.Hash[:]); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(scratch[:4], o.outPoint.Index)
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint16(scratch[:2], uint16(o.witnessType))
+	if _, err := w.Write(scratch[:2]); err != nil {
+		return err
+	}
+	if err := lnwallet.WriteSignDescriptor(w, o.signDesc); err != nil {
+		return err
+	}
+
+	for _, height := range []uint32{o.blocksToMaturity, o.confHeight,
+		o.absoluteMaturity} {
+
+		binary.BigEndian.PutUint32(scratch[:4], height)
+		if _, err := w.Write(scratch[:4]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deserializeImmatureOutput reads an immatureOutput previously written with
+// serializeImmatureOutput from the passed io.Reader.
+func deserializeImmatureOutput(r io.Reader) (*immatureOutput, error) {
+	var (
+		scratch [8]byte
+		o       = &immatureOutput{}
+	)
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	o.amt = btcutil.Amount(binary.BigEndian.Uint64(scratch[:]))
+
+	if _, err := io.ReadFull(r, o.outPoint.Hash[:]); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return nil, err
+	}
+	o.outPoint.Index = binary.BigEndian.Uint32(scratch[:4])
+
+	if _, err := io.ReadFull(r, scratch[:2]); err != nil {
+		return nil, err
+	}
+	o.witnessType = lnwallet.WitnessType(binary.BigEndian.Uint16(scratch[:2]))
+
+	o.signDesc = &lnwallet.SignDescriptor{}
+	if err := lnwallet.ReadSignDescriptor(r, o.signDesc); err != nil {
+		return nil, err
+	}
+
+	for _, height := range []*uint32{&o.blocksToMaturity, &o.confHeight,
+		&o.absoluteMaturity} {
+
+		if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+			return nil, err
+		}
+		*height = binary.BigEndian.Uint32(scratch[:4])
+	}
+
+	return o, nil
 }
 
 // incubationRequest is a request to the utxoNursery to incubate a set of
@@ -279,27 +549,48 @@ type incubationRequest struct {
 
 // incubateOutputs sends a request to utxoNursery to incubate the outputs
 // defined within the summary of a closed channel. Induvidually, as all outputs
-// reach maturity they'll be sweeped back into the wallet.
+// reach maturity they'll be sweeped back into the wallet. The outputs are
+// written to disk before being handed to the nursery, so they'll continue to
+// be incubated across restarts.
 func (u *utxoNursery) incubateOutputs(closeSummary *lnwallet.ForceCloseSummary) {
-	// TODO(roasbeef): should use factory func here based on an interface
-	//  * spend here also assumes delay is blocked bsaed, and in range
-	witnessFunc := func(tx *wire.MsgTx, hc *txscript.TxSigHashes, inputIndex int) ([][]byte, error) {
-		desc := closeSummary.SelfOutputSignDesc
-		desc.SigHashes = hc
-		desc.InputIndex = inputIndex
-
-		return lnwallet.CommitSpendTimeout(u.wallet.Signer, desc, tx)
-	}
-
-	outputAmt := btcutil.Amount(closeSummary.SelfOutputSignDesc.Output.Value)
+	// TODO(roasbeef): spend here also assumes delay is blocked bsaed, and
+	// in range
 	selfOutput := &immatureOutput{
-		amt:              outputAmt,
+		amt:              btcutil.Amount(closeSummary.SelfOutputSignDesc.Output.Value),
 		outPoint:         closeSummary.SelfOutpoint,
-		witnessFunc:      witnessFunc,
+		witnessType:      lnwallet.CommitmentTimeLock,
+		signDesc:         closeSummary.SelfOutputSignDesc,
 		blocksToMaturity: closeSummary.SelfOutputMaturity,
 	}
 
-	u.requests <- &incubationRequest{
-		outputs: []*immatureOutput{selfOutput},
+	outputs := []*immatureOutput{selfOutput}
+
+	// Additionally, each of our outgoing HTLC's within the commitment
+	// transaction can be swept via the timeout clause once both the
+	// absolute expiry of the HTLC, and the relative delay have passed.
+	for _, htlcRes := range closeSummary.HtlcResolutions {
+		outputs = append(outputs, &immatureOutput{
+			amt:              btcutil.Amount(htlcRes.SignDesc.Output.Value),
+			outPoint:         htlcRes.Outpoint,
+			witnessType:      lnwallet.HtlcOfferedTimeout,
+			signDesc:         htlcRes.SignDesc,
+			blocksToMaturity: htlcRes.Maturity,
+			absoluteMaturity: htlcRes.Expiry,
+		})
+	}
+
+	for _, output := range outputs {
+		output.witnessFunc = output.witnessType.GenWitnessFunc(
+			u.wallet.Signer, output.signDesc)
+
+		if err := u.persistOutput(output); err != nil {
+			utxnLog.Errorf("unable to persist output %v: %v",
+				output.outPoint, err)
+		}
+	}
+
+	select {
+	case u.requests <- &incubationRequest{outputs: outputs}:
+	case <-u.quit:
 	}
 }
@@ -0,0 +1,279 @@
+package zpay32
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/chaincfg"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// invoiceVersion is the current version of the payment request encoding.
+// Payment requests with an unknown version are rejected when decoding.
+const invoiceVersion = 0
+
+const (
+	// MaxMemoSize is the maximum size of the memo which can be embedded
+	// within a payment request.
+	MaxMemoSize = 1024
+
+	// DefaultExpiry is the expiry applied to payment requests which don't
+	// explicitly specify one.
+	DefaultExpiry = time.Hour
+)
+
+var (
+	// zbase32 is the base32 encoding used to encode the raw payment
+	// request. The z-base-32 alphabet is used as it's designed to be
+	// easily read, transcribed, and typed by humans.
+	zbase32 = base32.NewEncoding("ybndrfg8ejkmcpqxot1uwisza345h769")
+
+	// byteOrder is the byte order used to serialize all integers within a
+	// payment request.
+	byteOrder = binary.BigEndian
+
+	// knownNets are the networks a payment request may be generated for.
+	knownNets = []*chaincfg.Params{
+		&chaincfg.MainNetParams,
+		&chaincfg.TestNet3Params,
+		&chaincfg.RegressionNetParams,
+		&chaincfg.SegNet4Params,
+		&chaincfg.SimNetParams,
+	}
+)
+
+var (
+	// ErrChecksumMismatch is returned when the checksum included within a
+	// payment request doesn't match the checksum computed over the
+	// decoded payload. This indicates the payment request has been
+	// corrupted or mistyped.
+	ErrChecksumMismatch = errors.New("payment request checksum mismatch")
+
+	// ErrUnknownVersion is returned when attempting to decode a payment
+	// request with an unknown version.
+	ErrUnknownVersion = errors.New("unknown payment request version")
+
+	// ErrUnknownNet is returned when a payment request is encoded for a
+	// network we don't know of.
+	ErrUnknownNet = errors.New("payment request for unknown network")
+)
+
+// PaymentRequest is a bare-bones invoice for a payment within the Lightning
+// Network. With the details within the payment request, the sender has
+// enough information to find a route to the destination node, craft a
+// payment to the payee, and verify once the payment has been settled. Encoded
+// payment requests are self-describing, and carry a checksum which allows
+// detecting accidental corruption.
+type PaymentRequest struct {
+	// Destination is the identity public key of the node the payment is
+	// to be sent to.
+	Destination *btcec.PublicKey
+
+	// PaymentHash is the hash of the preimage which will be revealed by
+	// the payee once the payment has been settled.
+	PaymentHash [32]byte
+
+	// Amount is the number of satoshis requested by the payee. An amount
+	// of zero indicates that the payer is free to choose the amount.
+	Amount btcutil.Amount
+
+	// Memo is an optional human readable description of the payment.
+	Memo string
+
+	// CreationDate is the time the payment request was created.
+	CreationDate time.Time
+
+	// Expiry is the duration after the creation date, after which the
+	// payee will no longer accept payment.
+	Expiry time.Duration
+
+	// Net is the network the payment is to be sent over.
+	Net *chaincfg.Params
+}
+
+// ExpiresAt returns the time after which the payment request should no
+// longer be paid.
+func (p *PaymentRequest) ExpiresAt() time.Time {
+	return p.CreationDate.Add(p.Expiry)
+}
+
+// IsExpired returns true if the payment request has expired.
+func (p *PaymentRequest) IsExpired() bool {
+	return time.Now().After(p.ExpiresAt())
+}
+
+// Encode encodes the passed payment request into a checksummed, z-base-32
+// encoded string.
+func Encode(payReq *PaymentRequest) (string, error) {
+	if len(payReq.Memo) > MaxMemoSize {
+		return "", fmt.Errorf("memo too large: %v bytes (maxsize=%v)",
+			len(payReq.Memo), MaxMemoSize)
+	}
+	if payReq.Net == nil {
+		return "", ErrUnknownNet
+	}
+
+	var b bytes.Buffer
+	if err := serializePaymentRequest(&b, payReq); err != nil {
+		return "", err
+	}
+
+	// Append the checksum of the serialized payment request, such that
+	// any corruption can be detected upon decoding.
+	var checksum [4]byte
+	byteOrder.PutUint32(checksum[:], crc32.ChecksumIEEE(b.Bytes()))
+	b.Write(checksum[:])
+
+	// The padding is stripped as the length of the payload is implied by
+	// the length of the encoded string.
+	return strings.TrimRight(zbase32.EncodeToString(b.Bytes()), "="), nil
+}
+
+// Decode attempts to decode the passed z-base-32 encoded payment request. If
+// the payment request has been corrupted, or is otherwise malformed, then an
+// error is returned.
+func Decode(payData string) (*PaymentRequest, error) {
+	// Re-apply the padding stripped during encoding so the payload can
+	// be decoded.
+	if rem := len(payData) % 8; rem != 0 {
+		payData += strings.Repeat("=", 8-rem)
+	}
+	payReqBytes, err := zbase32.DecodeString(payData)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payReqBytes) < 4 {
+		return nil, fmt.Errorf("payment request too short")
+	}
+
+	// Before attempting to parse the payment request, ensure the
+	// checksum matches the payload.
+	payload := payReqBytes[:len(payReqBytes)-4]
+	checksum := byteOrder.Uint32(payReqBytes[len(payReqBytes)-4:])
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, ErrChecksumMismatch
+	}
+
+	r := bytes.NewReader(payload)
+	payReq, err := deserializePaymentRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("payment request has %v trailing bytes",
+			r.Len())
+	}
+
+	return payReq, nil
+}
+
+func serializePaymentRequest(w io.Writer, p *PaymentRequest) error {
+	var scratch [8]byte
+
+	scratch[0] = invoiceVersion
+	if _, err := w.Write(scratch[:1]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint32(scratch[:4], uint32(p.Net.Net))
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(p.Destination.SerializeCompressed()); err != nil {
+		return err
+	}
+	if _, err := w.Write(p.PaymentHash[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(p.Amount))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint64(scratch[:], uint64(p.CreationDate.Unix()))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint32(scratch[:4], uint32(p.Expiry/time.Second))
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	return wire.WriteVarString(w, 0, p.Memo)
+}
+
+func deserializePaymentRequest(r io.Reader) (*PaymentRequest, error) {
+	var scratch [8]byte
+	p := &PaymentRequest{}
+
+	if _, err := io.ReadFull(r, scratch[:1]); err != nil {
+		return nil, err
+	}
+	if scratch[0] != invoiceVersion {
+		return nil, ErrUnknownVersion
+	}
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return nil, err
+	}
+	net := wire.BitcoinNet(byteOrder.Uint32(scratch[:4]))
+	for _, params := range knownNets {
+		if params.Net == net {
+			p.Net = params
+			break
+		}
+	}
+	if p.Net == nil {
+		return nil, ErrUnknownNet
+	}
+
+	var pubKey [33]byte
+	if _, err := io.ReadFull(r, pubKey[:]); err != nil {
+		return nil, err
+	}
+	var err error
+	p.Destination, err = btcec.ParsePubKey(pubKey[:], btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.ReadFull(r, p.PaymentHash[:]); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	p.Amount = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	p.CreationDate = time.Unix(int64(byteOrder.Uint64(scratch[:])), 0)
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return nil, err
+	}
+	p.Expiry = time.Duration(byteOrder.Uint32(scratch[:4])) * time.Second
+
+	memo, err := wire.ReadVarBytes(r, 0, MaxMemoSize, "memo")
+	if err != nil {
+		return nil, err
+	}
+	p.Memo = string(memo)
+
+	return p, nil
+}
@@ -0,0 +1,114 @@
+package zpay32
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/chaincfg"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	testPrivKey = []byte{
+		0x2b, 0xd8, 0x06, 0xc9, 0x7f, 0x0e, 0x00, 0xaf,
+		0x1a, 0x1f, 0xc3, 0x32, 0x8f, 0xa7, 0x63, 0xa9,
+		0x26, 0x97, 0x23, 0xc8, 0xdb, 0x8f, 0xac, 0x4f,
+		0x93, 0xaf, 0x71, 0xdb, 0x18, 0x6d, 0x6e, 0x90,
+	}
+
+	_, testPubKey = btcec.PrivKeyFromBytes(btcec.S256(), testPrivKey)
+
+	testPayHash = [32]byte{
+		0xb7, 0x94, 0x38, 0x5f, 0x2d, 0x1e, 0xf7, 0xab,
+		0x4d, 0x92, 0x73, 0xd1, 0x90, 0x63, 0x81, 0xb4,
+		0x4f, 0x2f, 0x6f, 0x25, 0x88, 0xa3, 0xef, 0xb9,
+		0x6a, 0x49, 0x18, 0x83, 0x31, 0x98, 0x47, 0x53,
+	}
+)
+
+func TestEncodeDecode(t *testing.T) {
+	payReq := &PaymentRequest{
+		Destination:  testPubKey,
+		PaymentHash:  testPayHash,
+		Amount:       btcutil.Amount(50000),
+		Memo:         "one cup of coffee",
+		CreationDate: time.Unix(1479000000, 0),
+		Expiry:       DefaultExpiry,
+		Net:          &chaincfg.TestNet3Params,
+	}
+
+	encoded, err := Encode(payReq)
+	if err != nil {
+		t.Fatalf("unable to encode payment request: %v", err)
+	}
+
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Fatalf("unable to decode payment request: %v", err)
+	}
+
+	if !bytes.Equal(decoded.Destination.SerializeCompressed(),
+		payReq.Destination.SerializeCompressed()) {
+		t.Fatalf("destinations don't match")
+	}
+	if decoded.PaymentHash != payReq.PaymentHash {
+		t.Fatalf("payment hashes don't match: %x vs %x",
+			decoded.PaymentHash[:], payReq.PaymentHash[:])
+	}
+	if decoded.Amount != payReq.Amount {
+		t.Fatalf("amounts don't match: %v vs %v", decoded.Amount,
+			payReq.Amount)
+	}
+	if decoded.Memo != payReq.Memo {
+		t.Fatalf("memos don't match: %v vs %v", decoded.Memo,
+			payReq.Memo)
+	}
+	if !decoded.CreationDate.Equal(payReq.CreationDate) {
+		t.Fatalf("creation dates don't match: %v vs %v",
+			decoded.CreationDate, payReq.CreationDate)
+	}
+	if decoded.Expiry != payReq.Expiry {
+		t.Fatalf("expiries don't match: %v vs %v", decoded.Expiry,
+			payReq.Expiry)
+	}
+	if decoded.Net != payReq.Net {
+		t.Fatalf("networks don't match: %v vs %v", decoded.Net.Name,
+			payReq.Net.Name)
+	}
+}
+
+func TestDecodeCorrupted(t *testing.T) {
+	payReq := &PaymentRequest{
+		Destination:  testPubKey,
+		PaymentHash:  testPayHash,
+		Amount:       btcutil.Amount(1000),
+		CreationDate: time.Unix(1479000000, 0),
+		Expiry:       DefaultExpiry,
+		Net:          &chaincfg.SimNetParams,
+	}
+
+	encoded, err := Encode(payReq)
+	if err != nil {
+		t.Fatalf("unable to encode payment request: %v", err)
+	}
+
+	// Flip a single character in the middle of the encoded payment
+	// request, the checksum should no longer match.
+	corrupted := []byte(encoded)
+	i := len(corrupted) / 2
+	if corrupted[i] == 'y' {
+		corrupted[i] = 'b'
+	} else {
+		corrupted[i] = 'y'
+	}
+	if _, err := Decode(string(corrupted)); err != ErrChecksumMismatch {
+		t.Fatalf("expected checksum mismatch, instead got: %v", err)
+	}
+
+	// A truncated payment request should also be rejected.
+	if _, err := Decode(encoded[:len(encoded)-8]); err == nil {
+		t.Fatalf("truncated payment request was decoded")
+	}
+}